@@ -0,0 +1,128 @@
+package horizon
+
+import (
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+)
+
+// Graph may contain parallel edges: several edges between the same pair of vertices (e.g. two sides of a boulevard or
+// a road and its service lane). So edges are keyed by their identifiers and adjacency lists keep every edge of the vertex.
+
+// addEdge Registers the edge in engine's indices. Edge with the same identifier is replaced
+func (engine *MapEngine) addEdge(edge *spatial.Edge) {
+	if previous, ok := engine.edges[edge.ID]; ok {
+		engine.outgoing[previous.Source] = removeEdge(engine.outgoing[previous.Source], previous.ID)
+		engine.incoming[previous.Target] = removeEdge(engine.incoming[previous.Target], previous.ID)
+	}
+	engine.edges[edge.ID] = edge
+	engine.outgoing[edge.Source] = append(engine.outgoing[edge.Source], edge)
+	engine.incoming[edge.Target] = append(engine.incoming[edge.Target], edge)
+}
+
+// removeEdge Returns adjacency list without the edge with given identifier
+func removeEdge(adjacent []*spatial.Edge, edgeID int64) []*spatial.Edge {
+	for i, edge := range adjacent {
+		if edge.ID == edgeID {
+			return append(adjacent[:i:i], adjacent[i+1:]...)
+		}
+	}
+	return adjacent
+}
+
+// outgoingEdges Returns edges going out of the vertex
+func (engine *MapEngine) outgoingEdges(vertex int64) []*spatial.Edge {
+	return engine.outgoing[vertex]
+}
+
+// incomingEdges Returns edges coming into the vertex
+func (engine *MapEngine) incomingEdges(vertex int64) []*spatial.Edge {
+	return engine.incoming[vertex]
+}
+
+// edgesBetween Returns every edge going from the source vertex to the target one
+func (engine *MapEngine) edgesBetween(source, target int64) []*spatial.Edge {
+	ans := []*spatial.Edge{}
+	for _, edge := range engine.outgoing[source] {
+		if edge.Target == target {
+			ans = append(ans, edge)
+		}
+	}
+	return ans
+}
+
+// twinEdge Returns opposite edge of two-way road: the edge going backwards with reversed geometry.
+// When there is no such edge, but there is the only edge going backwards, then it is returned (geometries could be digitized separately).
+// Returns nil if opposite edge can't be picked
+func (engine *MapEngine) twinEdge(edge *spatial.Edge) *spatial.Edge {
+	var single *spatial.Edge
+	candidates := 0
+	for _, candidate := range engine.edgesBetween(edge.Target, edge.Source) {
+		if candidate.ID == edge.ID {
+			continue
+		}
+		if edge.Polyline != nil && candidate.Polyline != nil && isReversedPolyline(*edge.Polyline, *candidate.Polyline) {
+			return candidate
+		}
+		single = candidate
+		candidates++
+	}
+	if candidates == 1 {
+		return single
+	}
+	return nil
+}
+
+// isReversedPolyline Checks if the second polyline has the same points as the first one in reversed order
+func isReversedPolyline(a, b s2.Polyline) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[len(b)-1-i] {
+			return false
+		}
+	}
+	return true
+}
+
+// pathEdge Returns edge which is used by the query to go from the source vertex to the target one (vertices are neighbors in the path)
+/*
+	source - source vertex
+	target - target vertex
+	preferred - edges which are picked if they connect vertices, e.g. matched edges of candidates (so parallel edge is not swapped with the matched one)
+
+	Otherwise edge with minimum weight for the query's profile is picked among edges which are traversable and not excluded.
+	Returns nil if vertices are not connected
+*/
+func (query *routingQuery) pathEdge(source, target int64, preferred ...*spatial.Edge) *spatial.Edge {
+	for _, edge := range preferred {
+		if edge != nil && edge.Source == source && edge.Target == target {
+			return edge
+		}
+	}
+	var best *spatial.Edge
+	bestWeight := 0.0
+	var fallback *spatial.Edge
+	for _, edge := range query.engine.outgoing[source] {
+		if edge.Target != target {
+			continue
+		}
+		if fallback == nil {
+			fallback = edge
+		}
+		if query.isExcluded(edge.ID) {
+			continue
+		}
+		weight, ok := query.profile.weight(edge)
+		if !ok {
+			continue
+		}
+		if best == nil || weight < bestWeight {
+			best, bestWeight = edge, weight
+		}
+	}
+	if best == nil {
+		return fallback
+	}
+	return best
+}
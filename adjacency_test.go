@@ -0,0 +1,222 @@
+package horizon
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LdDl/ch"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// prepareParallelMatcher Returns matcher for graph with two parallel edges between vertices 1 and 2:
+// straight edge 2 and detour edge 3 (which goes through point (5, 4) and hence is longer)
+func prepareParallelMatcher(t *testing.T) *MapMatcher {
+	points := map[int64][2]float64{
+		0: {-5, 0},
+		1: {0, 0},
+		2: {10, 0},
+		3: {15, 0},
+	}
+	edgeDefs := []struct {
+		id     int64
+		source int64
+		target int64
+		via    [][2]float64
+	}{
+		{1, 0, 1, nil}, {2, 1, 2, nil}, {3, 1, 2, [][2]float64{{5, 4}}}, {4, 2, 3, nil},
+	}
+	graph := ch.Graph{}
+	verticesSpatial := []*spatial.Vertex{}
+	for vertexID, coords := range points {
+		err := graph.CreateVertex(vertexID)
+		if err != nil {
+			t.Fatalf("Can't add vertex with id = '%d' to the graph: %v", vertexID, err)
+		}
+		s2Point := spatial.NewEuclideanS2Point(coords[0], coords[1])
+		verticesSpatial = append(verticesSpatial, &spatial.Vertex{
+			Point: &s2Point,
+			ID:    vertexID,
+		})
+	}
+	edgesSpatial := []*spatial.Edge{}
+	for _, edge := range edgeDefs {
+		coords := append([][2]float64{points[edge.source]}, edge.via...)
+		coords = append(coords, points[edge.target])
+		s2Polyline := s2.Polyline{}
+		weight := 0.0
+		for i, pt := range coords {
+			s2Polyline = append(s2Polyline, spatial.NewEuclideanS2Point(pt[0], pt[1]))
+			if i > 0 {
+				weight += math.Hypot(pt[0]-coords[i-1][0], pt[1]-coords[i-1][1])
+			}
+		}
+		err := graph.AddEdge(edge.source, edge.target, weight)
+		if err != nil {
+			t.Fatalf("Can't add edge from '%d' to '%d' to the graph: %v", edge.source, edge.target, err)
+		}
+		edgesSpatial = append(edgesSpatial, &spatial.Edge{
+			ID:       edge.id,
+			Source:   edge.source,
+			Target:   edge.target,
+			Weight:   weight,
+			Polyline: &s2Polyline,
+		})
+	}
+	engine := NewMapEngine(
+		WithGraph(graph),
+		WithStorage(spatial.NewStorage(spatial.StorageTypeEuclidean)),
+		WithEdges(edgesSpatial),
+		WithVertices(verticesSpatial),
+	)
+	return NewMapMatcher(WithMapEngine(engine))
+}
+
+func TestParallelEdgesKept(t *testing.T) {
+	matcher := prepareParallelMatcher(t)
+	if len(matcher.engine.edges) != 4 {
+		t.Errorf("Engine should keep 4 edges, but got %d", len(matcher.engine.edges))
+	}
+	parallel := matcher.engine.edgesBetween(1, 2)
+	if len(parallel) != 2 || parallel[0].ID != 2 || parallel[1].ID != 3 {
+		t.Errorf("Edges 2 and 3 should go from vertex 1 to vertex 2, but got %v", parallel)
+	}
+	if incoming := matcher.engine.incomingEdges(2); len(incoming) != 2 {
+		t.Errorf("Vertex 2 should have 2 incoming edges, but got %d", len(incoming))
+	}
+
+	// Edge with the same identifier replaces the previous one
+	replaced := *matcher.engine.edges[3]
+	replaced.Weight = 100
+	matcher.engine.addEdge(&replaced)
+	parallel = matcher.engine.edgesBetween(1, 2)
+	if len(parallel) != 2 || parallel[1].Weight != 100 {
+		t.Errorf("Edge 3 should be replaced, but got %v", parallel)
+	}
+}
+
+func TestParallelEdgesShortestPath(t *testing.T) {
+	matcher := prepareParallelMatcher(t)
+	source := NewGPSMeasurementFromID(1, -4.9, 0.1, 0)
+	target := NewGPSMeasurementFromID(2, 14.9, 0.1, 0)
+	// Source is snapped to vertex 1 and target is snapped to vertex 2
+	cases := []struct {
+		name   string
+		opts   []QueryOption
+		edgeID int64
+	}{
+		// The shortest one of parallel edges is picked
+		{"default", nil, 2},
+		// Otherwise the one which is not excluded
+		{"excluded", []QueryOption{WithExcludedEdges(2)}, 3},
+	}
+	for _, c := range cases {
+		result, err := matcher.FindShortestPath(source, target, -1, c.opts...)
+		if err != nil {
+			t.Fatalf("Case '%s': %v", c.name, err)
+		}
+		for i, observation := range result.SubMatches[0].Observations {
+			if observation.MatchedEdge.ID != c.edgeID {
+				t.Errorf("Case '%s': observation #%d should be matched to edge %d, but got %d", c.name, i, c.edgeID, observation.MatchedEdge.ID)
+			}
+		}
+	}
+
+	// Target is on the detour edge: matched edge is preferred over the shorter parallel one
+	detour := NewGPSMeasurementFromID(3, 2.5, 2.1, 0)
+	result, err := matcher.FindShortestPath(source, detour, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edgeID := result.SubMatches[0].Observations[1].MatchedEdge.ID; edgeID != 3 {
+		t.Errorf("Path should end with edge 3, but got %d", edgeID)
+	}
+}
+
+func TestParallelEdgesMapMatching(t *testing.T) {
+	matcher := NewMapMatcher(
+		WithHmmParameters(NewHmmProbabilities(1.0, 2.0)),
+		WithMapEngine(prepareParallelMatcher(t).engine),
+	)
+	gpsMeasurements := GPSMeasurements{
+		NewGPSMeasurementFromID(1, -4.0, 0.1, 0),
+		NewGPSMeasurementFromID(2, 5.0, 3.9, 0),
+		NewGPSMeasurementFromID(3, 14.0, 0.1, 0),
+	}
+	result, err := matcher.Run(gpsMeasurements, 3.0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.SubMatches) != 1 {
+		t.Fatalf("Expected 1 sub-match, but got %d", len(result.SubMatches))
+	}
+	observations := result.SubMatches[0].Observations
+	expectedEdges := []int64{1, 3, 4}
+	for i, observation := range observations {
+		if observation.MatchedEdge.ID != expectedEdges[i] {
+			t.Errorf("Observation #%d should be matched to edge %d, but got %d", i, expectedEdges[i], observation.MatchedEdge.ID)
+		}
+		// Route follows the detour, so the parallel straight edge must not appear
+		for _, edge := range observation.NextEdges {
+			if edge.ID == 2 {
+				t.Errorf("Observation #%d: route should go via edge 3, but edge 2 found", i)
+			}
+		}
+	}
+}
+
+func TestIncomingEdgesAfterUpdate(t *testing.T) {
+	engine, err := NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(gridEdges(3)...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Edge 1 goes from 0 to 1. Incoming edges are requested before the edge is moved
+	if len(edgesWithID(engine.incomingEdges(1), 1)) != 1 {
+		t.Fatal("Edge 1 should come into vertex 1")
+	}
+	moved := *engine.edges[1]
+	moved.Target = 3
+	engine.addEdge(&moved)
+	if len(edgesWithID(engine.incomingEdges(1), 1)) != 0 {
+		t.Error("Replaced edge should not come into its previous target")
+	}
+	if found := edgesWithID(engine.incomingEdges(3), 1); len(found) != 1 || found[0] != &moved {
+		t.Errorf("Replaced edge should come into its new target, but got %v", found)
+	}
+}
+
+// edgesWithID Returns edges with the given identifier
+func edgesWithID(edges []*spatial.Edge, edgeID int64) []*spatial.Edge {
+	ans := []*spatial.Edge{}
+	for _, edge := range edges {
+		if edge.ID == edgeID {
+			ans = append(ans, edge)
+		}
+	}
+	return ans
+}
+
+func TestDuplicateEdgeID(t *testing.T) {
+	content, err := os.ReadFile("./test_data/matcher_4326_test.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	// The second edge gets identifier of the first one
+	fields := strings.Split(lines[2], ";")
+	fields[len(fields)-1] = strings.Split(lines[1], ";")[len(fields)-1]
+	lines[2] = strings.Join(fields, ";")
+	edgesFilename := filepath.Join(t.TempDir(), "graph.csv")
+	err = os.WriteFile(edgesFilename, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewMapMatcherFromFiles(HmmProbabilitiesDefault(), edgesFilename)
+	if !errors.Is(err, ErrInconsistentGraph) {
+		t.Errorf("Duplicate edge_id should give ErrInconsistentGraph, but got %v", err)
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"log"
 	"net"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/LdDl/horizon"
@@ -22,7 +25,7 @@ import (
 var (
 	addrFlag   = flag.String("h", "0.0.0.0", "Bind address")
 	portFlag   = flag.Int("p", 32800, "Port")
-	fileFlag   = flag.String("f", "graph.csv", "Filename of *.csv file (you can get one using https://github.com/LdDl/osm2ch#osm2ch)")
+	fileFlag   = flag.String("f", "graph.csv", "Filename of *.csv file (you can get one using https://github.com/LdDl/osm2ch#osm2ch) ), *.geojson file with FeatureCollection of LineStrings or *.osm.pbf extract of OpenStreetMap")
 	sigmaFlag  = flag.Float64("sigma", 50.0, "σ-parameter for evaluating emission probabilities")
 	betaFlag   = flag.Float64("beta", 30.0, "β-parameter for evaluating transition probabilities")
 	lonFlag    = flag.Float64("maplon", 0.0, "initial longitude of front-end map")
@@ -36,10 +39,34 @@ var (
 	grpcPortFlag = flag.Int("gp", 32801, "gRPC port")
 	grpcReflect  = flag.Bool("gr", false, "Enable gRPC reflection")
 
+	profilesFlag = flag.String("profiles", "", "Comma-separated names of additional weight columns in edges file, e.g. 'length,travel_time'. Each profile gets its own contraction hierarchy. 'length' is derived from geometry if there is no such column")
+
+	sridFlag = flag.Int("srid", 4326, "SRID of geometries in *.csv files: 4326, 0 (raw Cartesian coordinates), 3857 or UTM zone (32601-32660, 32701-32760)")
+
+	ellipsoidalFlag = flag.Bool("ellipsoidal", false, "Evaluate lengths and distances on WGS84 ellipsoid instead of sphere (more accurate, but slower)")
+
+	geojsonPropsFlag = flag.String("geojson-props", "", "Comma-separated names of properties of *.geojson features in form 'key=name', where key is one of 'source', 'target', 'weight', 'oneway', 'id', e.g. 'source=u,target=v,weight=cost'. Omitted keys use default names (the same as keys)")
+	snapFlag         = flag.Float64("snap", 0.0, "Endpoints of *.geojson lines closer than this distance (meters, or units of coordinates for SRID = 0) are snapped to the same vertex. Used when features have no source and target properties")
+
+	osmWeightFlag   = flag.String("osm-weight", horizon.OSM_WEIGHT_TRAVEL_TIME, "Weight of edges imported from *.osm.pbf file: 'travel_time' (seconds) or 'length' (meters)")
+	osmHighwaysFlag = flag.String("osm-highways", "", "Comma-separated values of 'highway' tag to be imported from *.osm.pbf file with optional default speed (km/h) in form 'value=speed', e.g. 'primary=60,secondary,residential=20'. Roads without speed get 50 km/h. Empty value means default car profile")
+	exportCSVFlag   = flag.String("export-csv", "", "Filename of edges *.csv file to be written after loading graph (vertices and shortcuts files are written next to it). Use it to convert *.osm.pbf or *.geojson file to input of -f")
+
+	snapshotFlag     = flag.String("snapshot", "", "Filename of binary snapshot to start from (see -save-snapshot). If set then -f is ignored. SRID, ellipsoidal distances and weight profiles are taken from snapshot: -srid, -ellipsoidal and -profiles could be omitted, otherwise they must match the snapshot")
+	saveSnapshotFlag = flag.String("save-snapshot", "", "Filename of binary snapshot to be written after loading *.csv files. Use it with -snapshot for fast startup later")
+
+	adminTokenFlag = flag.String("admin-token", "", "Token for admin endpoints (POST /graph/reload with 'Authorization: Bearer <token>' header). Admin endpoints are disabled if empty. Graph is reloaded on SIGHUP anyway")
+
 	//go:embed index.html
 	webPage string
 )
 
+// commands Subcommands which are run instead of the server, e.g. 'horizon prepare -f graph.csv'
+var commands = map[string]func(args []string) error{
+	"prepare":  runPrepare,
+	"validate": runValidate,
+}
+
 // @title API for working with Horizon
 // @version 0.1.0
 
@@ -51,6 +78,16 @@ var (
 
 // @schemes http https
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			err := command(os.Args[2:])
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
 	flag.Parse()
 
 	// Init web page
@@ -58,11 +95,44 @@ func main() {
 
 	// Init map matcher engine
 	hmmParams := horizon.NewHmmProbabilities(*sigmaFlag, *betaFlag)
-	matcher, err := horizon.NewMapMatcherFromFiles(hmmParams, *fileFlag)
+	engineOpts := []func(*horizon.MapEngine){}
+	if *profilesFlag != "" {
+		profiles := strings.Split(*profilesFlag, ",")
+		for i := range profiles {
+			profiles[i] = strings.TrimSpace(profiles[i])
+		}
+		engineOpts = append(engineOpts, horizon.WithWeightProfiles(profiles...))
+	}
+	if *sridFlag != 4326 {
+		engineOpts = append(engineOpts, horizon.WithGraphSRID(*sridFlag))
+	}
+	if *ellipsoidalFlag {
+		engineOpts = append(engineOpts, horizon.WithEllipsoidalDistances())
+	}
+	loader, err := newGraphLoader(hmmParams, engineOpts)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	matcher, err := loader.load()
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
+	if *exportCSVFlag != "" {
+		err = matcher.ExportCSV(*exportCSVFlag)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if *saveSnapshotFlag != "" {
+		err = matcher.SaveSnapshot(*saveSnapshotFlag)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
 
 	config := fiber.Config{
 		DisableStartupMessage: false,
@@ -90,6 +160,18 @@ func main() {
 	apiVersionGroup.Post("/mapmatch", rest.MapMatch(matcher))
 	apiVersionGroup.Post("/shortest", rest.FindSP(matcher))
 	apiVersionGroup.Post("/isochrones", rest.FindIsochrones(matcher))
+	apiVersionGroup.Post("/service_areas", rest.FindServiceAreas(matcher))
+	apiVersionGroup.Post("/optimize", rest.OptimizeRoute(matcher))
+	apiVersionGroup.Post("/nearest", rest.Nearest(matcher))
+	apiVersionGroup.Post("/snap", rest.Snap(matcher))
+	apiVersionGroup.Post("/edges", rest.EdgesInRegion(matcher))
+	apiVersionGroup.Post("/facilities", rest.SetFacilities(matcher))
+	apiVersionGroup.Post("/facilities/nearest", rest.NearestFacilities(matcher))
+	apiVersionGroup.Get("/graph", rest.GraphInfo(matcher))
+	if *adminTokenFlag != "" {
+		apiVersionGroup.Post("/graph/reload", rest.ReloadGraph(matcher, *adminTokenFlag, func() { loader.reload(matcher) }))
+	}
+	loader.watchReloadSignal(matcher)
 
 	docsStaticGroup := apiVersionGroup.Group("/docs")
 	docsStaticGroup.Use("/", docs.PrepareStaticAssets())
@@ -135,3 +217,65 @@ func main() {
 		return
 	}
 }
+
+// isGeoJSON Checks if road graph should be loaded from GeoJSON file
+func isGeoJSON(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".geojson" || ext == ".json"
+}
+
+// parseGeoJSONOptions Returns options of GeoJSON loader for value of 'geojson-props' flag
+func parseGeoJSONOptions(props string, snapTolerance float64) (horizon.GeoJSONOptions, error) {
+	options := horizon.DefaultGeoJSONOptions()
+	options.SnapTolerance = snapTolerance
+	if strings.TrimSpace(props) == "" {
+		return options, nil
+	}
+	fields := map[string]*string{
+		"source": &options.SourceProperty,
+		"target": &options.TargetProperty,
+		"weight": &options.WeightProperty,
+		"oneway": &options.OneWayProperty,
+		"id":     &options.EdgeIDProperty,
+	}
+	for _, pair := range strings.Split(props, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return options, fmt.Errorf("can't parse GeoJSON property '%s': expected 'key=name'", pair)
+		}
+		field, ok := fields[strings.TrimSpace(kv[0])]
+		if !ok {
+			return options, fmt.Errorf("unknown key '%s' of GeoJSON property", strings.TrimSpace(kv[0]))
+		}
+		*field = strings.TrimSpace(kv[1])
+	}
+	return options, nil
+}
+
+// isOSM Checks if road graph should be imported from OSM PBF file
+func isOSM(filename string) bool {
+	return strings.HasSuffix(strings.ToLower(filename), ".pbf")
+}
+
+// parseOSMOptions Returns options of OSM importer for values of 'osm-highways' and 'osm-weight' flags
+func parseOSMOptions(highways string, weight string) (horizon.OSMOptions, error) {
+	options := horizon.DefaultOSMOptions()
+	options.Weight = weight
+	if strings.TrimSpace(highways) == "" {
+		return options, nil
+	}
+	options.Highways = make(map[string]float64)
+	for _, pair := range strings.Split(highways, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		speed := 50.0
+		if len(kv) == 2 {
+			var err error
+			speed, err = strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+			if err != nil {
+				return options, fmt.Errorf("can't parse default speed of highway '%s': %s", pair, err)
+			}
+		}
+		options.Highways[strings.TrimSpace(kv[0])] = speed
+	}
+	return options, nil
+}
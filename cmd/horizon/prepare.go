@@ -0,0 +1,28 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/LdDl/horizon"
+)
+
+// runPrepare Runs 'prepare' command: contracts graph of edges file and writes vertices and shortcuts files next to it
+func runPrepare(args []string) error {
+	flags := flag.NewFlagSet("prepare", flag.ExitOnError)
+	fileFlag := flags.String("f", "graph.csv", "Filename of edges *.csv file. Files *_vertices.csv and *_shortcuts.csv are written next to it")
+	sridFlag := flags.Int("srid", 4326, "SRID of geometries in *.csv file: 4326, 0 (raw Cartesian coordinates), 3857 or UTM zone (32601-32660, 32701-32760)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: horizon prepare -f graph.csv\n\nPrepares contraction hierarchies for edges file, so the graph is loaded without contraction on start.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	fmt.Printf("Preparing contraction hierarchies for '%s' file...\n", *fileFlag)
+	stats, err := horizon.PrepareShortcuts(*fileFlag, horizon.WithGraphSRID(*sridFlag))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Done: %s\n", stats)
+	return nil
+}
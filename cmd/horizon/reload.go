@@ -0,0 +1,90 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/LdDl/horizon"
+)
+
+// graphLoader Loads road graph in the same way on start and on reload: from snapshot, *.geojson, *.osm.pbf or *.csv files
+type graphLoader struct {
+	hmmParams      *horizon.HmmProbabilities
+	filename       string
+	snapshot       string
+	geojsonOptions horizon.GeoJSONOptions
+	osmOptions     horizon.OSMOptions
+	engineOpts     []func(*horizon.MapEngine)
+}
+
+// newGraphLoader Returns loader configured by command line flags
+func newGraphLoader(hmmParams *horizon.HmmProbabilities, engineOpts []func(*horizon.MapEngine)) (*graphLoader, error) {
+	loader := &graphLoader{
+		hmmParams:  hmmParams,
+		filename:   *fileFlag,
+		snapshot:   *snapshotFlag,
+		engineOpts: engineOpts,
+	}
+	var err error
+	if loader.snapshot == "" && isGeoJSON(loader.filename) {
+		loader.geojsonOptions, err = parseGeoJSONOptions(*geojsonPropsFlag, *snapFlag)
+	} else if loader.snapshot == "" && isOSM(loader.filename) {
+		loader.osmOptions, err = parseOSMOptions(*osmHighwaysFlag, *osmWeightFlag)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return loader, nil
+}
+
+// source Returns file which graph is loaded from
+func (loader *graphLoader) source() string {
+	if loader.snapshot != "" {
+		return loader.snapshot
+	}
+	return loader.filename
+}
+
+// load Loads road graph and returns matcher for it
+func (loader *graphLoader) load() (*horizon.MapMatcher, error) {
+	switch {
+	case loader.snapshot != "":
+		return horizon.NewMapMatcherFromSnapshot(loader.hmmParams, loader.snapshot, loader.engineOpts...)
+	case isGeoJSON(loader.filename):
+		return horizon.NewMapMatcherFromGeoJSON(loader.hmmParams, loader.filename, loader.geojsonOptions, loader.engineOpts...)
+	case isOSM(loader.filename):
+		return horizon.NewMapMatcherFromOSM(loader.hmmParams, loader.filename, loader.osmOptions, loader.engineOpts...)
+	default:
+		return horizon.NewMapMatcherFromFiles(loader.hmmParams, loader.filename, loader.engineOpts...)
+	}
+}
+
+// reload Loads road graph from the same source again and replaces active graph of the matcher. Requests are served by the active graph meanwhile
+func (loader *graphLoader) reload(matcher *horizon.MapMatcher) {
+	log.Printf("Reloading graph from '%s'...\n", loader.source())
+	info, err := matcher.Reload(loader.source(), func() (*horizon.MapEngine, error) {
+		loaded, err := loader.load()
+		if err != nil {
+			return nil, err
+		}
+		return loaded.Engine(), nil
+	})
+	if err != nil {
+		log.Println("Can't reload graph:", err)
+		return
+	}
+	log.Printf("Graph version %d is active: %d edges, %d vertices, loaded in %v\n", info.Version, info.Edges, info.Vertices, info.LoadDuration)
+}
+
+// watchReloadSignal Reloads graph of the matcher on every SIGHUP
+func (loader *graphLoader) watchReloadSignal(matcher *horizon.MapMatcher) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			loader.reload(matcher)
+		}
+	}()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/LdDl/horizon"
+)
+
+// runValidate Runs 'validate' command: reports problems of graph files and optionally writes repaired graph
+func runValidate(args []string) error {
+	defaults := horizon.DefaultValidationOptions()
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	fileFlag := flags.String("f", "graph.csv", "Filename of edges *.csv file. Files *_vertices.csv and *_shortcuts.csv are checked if they exist")
+	sridFlag := flags.Int("srid", 4326, "SRID of geometries in *.csv file: 4326, 0 (raw Cartesian coordinates), 3857 or UTM zone (32601-32660, 32701-32760)")
+	profilesFlag := flags.String("profiles", "", "Comma-separated names of additional weight columns in edges file, e.g. 'length,travel_time'")
+	toleranceFlag := flags.Float64("tolerance", defaults.Tolerance, "Maximum distance between endpoint of edge geometry and its vertex (meters, or units of coordinates for SRID = 0)")
+	tinyFlag := flags.Int("tiny", defaults.TinyComponentSize, "Strongly connected components with at most this number of vertices are reported as tiny ones")
+	repairFlag := flags.String("repair", "", "Filename of edges *.csv file for repaired graph. Vertices and shortcuts files are written next to it")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: horizon validate -f graph.csv [-repair fixed.csv]\n\nReports problems of graph files with line numbers and statistics of connected components.\nExits with code 1 if there are problems.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	engineOpts := []func(*horizon.MapEngine){horizon.WithGraphSRID(*sridFlag)}
+	if *profilesFlag != "" {
+		profiles := strings.Split(*profilesFlag, ",")
+		for i := range profiles {
+			profiles[i] = strings.TrimSpace(profiles[i])
+		}
+		engineOpts = append(engineOpts, horizon.WithWeightProfiles(profiles...))
+	}
+	options := horizon.ValidationOptions{
+		Tolerance:         *toleranceFlag,
+		TinyComponentSize: *tinyFlag,
+		RepairFilename:    *repairFlag,
+	}
+	report, err := horizon.ValidateGraph(*fileFlag, options, engineOpts...)
+	if err != nil {
+		return err
+	}
+	for _, issue := range report.Issues {
+		fmt.Println(issue)
+	}
+	fmt.Printf("Done: %s\n", report)
+	if report.Repaired {
+		fmt.Printf("Repaired graph has been written to '%s'\n", *repairFlag)
+	}
+	if len(report.Issues) > 0 {
+		return fmt.Errorf("graph has %d problems", len(report.Issues))
+	}
+	return nil
+}
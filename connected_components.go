@@ -38,9 +38,9 @@ func (engine *MapEngine) bfsMarkWeakComponent(start int64, componentID int64, vi
 		size++
 
 		// Add neighbors via outgoing edges (v -> neighbor)
-		for neighbor := range engine.edges[v] {
-			if !visited[neighbor] {
-				queue = append(queue, neighbor)
+		for _, edge := range engine.outgoing[v] {
+			if !visited[edge.Target] {
+				queue = append(queue, edge.Target)
 			}
 		}
 
@@ -72,12 +72,10 @@ func (engine *MapEngine) computeWeakConnectedComponents() WeakComponentsResult {
 	// This allows O(degree) lookup for incoming edges instead of O(E)
 	reverseEdges := make(map[int64][]int64)
 	vertices := make(map[int64]bool)
-	for src, targets := range engine.edges {
-		vertices[src] = true
-		for dst := range targets {
-			vertices[dst] = true
-			reverseEdges[dst] = append(reverseEdges[dst], src)
-		}
+	for _, edge := range engine.edges {
+		vertices[edge.Source] = true
+		vertices[edge.Target] = true
+		reverseEdges[edge.Target] = append(reverseEdges[edge.Target], edge.Source)
 	}
 
 	// Process each vertex
@@ -8,22 +8,22 @@ import (
 
 func TestBfsMarkWeakComponent(t *testing.T) {
 	engine := &MapEngine{
-		edges: make(map[int64]map[int64]*spatial.Edge),
+		edges:    make(map[int64]*spatial.Edge),
+		outgoing: make(map[int64][]*spatial.Edge),
+		incoming: make(map[int64][]*spatial.Edge),
 	}
 
 	// Create a simple graph:
 	// Component 1: 1 -> 2 -> 3
 	// Component 2: 4 -> 5 (isolated from component 1)
-	engine.edges[1] = map[int64]*spatial.Edge{2: {}}
-	engine.edges[2] = map[int64]*spatial.Edge{3: {}}
-	engine.edges[4] = map[int64]*spatial.Edge{5: {}}
+	engine.addEdge(&spatial.Edge{ID: 102, Source: 1, Target: 2})
+	engine.addEdge(&spatial.Edge{ID: 203, Source: 2, Target: 3})
+	engine.addEdge(&spatial.Edge{ID: 405, Source: 4, Target: 5})
 
 	// Build reverse edges index
 	reverseEdges := make(map[int64][]int64)
-	for src, targets := range engine.edges {
-		for dst := range targets {
-			reverseEdges[dst] = append(reverseEdges[dst], src)
-		}
+	for _, edge := range engine.edges {
+		reverseEdges[edge.Target] = append(reverseEdges[edge.Target], edge.Source)
 	}
 
 	visited := make(map[int64]bool)
@@ -58,21 +58,21 @@ func TestBfsMarkWeakComponent(t *testing.T) {
 
 func TestBfsMarkWeakComponentUndirected(t *testing.T) {
 	engine := &MapEngine{
-		edges: make(map[int64]map[int64]*spatial.Edge),
+		edges:    make(map[int64]*spatial.Edge),
+		outgoing: make(map[int64][]*spatial.Edge),
+		incoming: make(map[int64][]*spatial.Edge),
 	}
 
 	// Create a graph where vertices are connected only via incoming edges:
 	// 1 -> 2, 3 -> 2
 	// Starting from vertex 3, we should still reach 1 and 2 (undirected traversal)
-	engine.edges[1] = map[int64]*spatial.Edge{2: {}}
-	engine.edges[3] = map[int64]*spatial.Edge{2: {}}
+	engine.addEdge(&spatial.Edge{ID: 102, Source: 1, Target: 2})
+	engine.addEdge(&spatial.Edge{ID: 302, Source: 3, Target: 2})
 
 	// Build reverse edges index
 	reverseEdges := make(map[int64][]int64)
-	for src, targets := range engine.edges {
-		for dst := range targets {
-			reverseEdges[dst] = append(reverseEdges[dst], src)
-		}
+	for _, edge := range engine.edges {
+		reverseEdges[edge.Target] = append(reverseEdges[edge.Target], edge.Source)
 	}
 
 	visited := make(map[int64]bool)
@@ -97,17 +97,17 @@ func TestBfsMarkWeakComponentUndirected(t *testing.T) {
 
 func TestBfsMarkWeakComponentAlreadyVisited(t *testing.T) {
 	engine := &MapEngine{
-		edges: make(map[int64]map[int64]*spatial.Edge),
+		edges:    make(map[int64]*spatial.Edge),
+		outgoing: make(map[int64][]*spatial.Edge),
+		incoming: make(map[int64][]*spatial.Edge),
 	}
 
-	engine.edges[1] = map[int64]*spatial.Edge{2: {}}
+	engine.addEdge(&spatial.Edge{ID: 102, Source: 1, Target: 2})
 
 	// Build reverse edges index
 	reverseEdges := make(map[int64][]int64)
-	for src, targets := range engine.edges {
-		for dst := range targets {
-			reverseEdges[dst] = append(reverseEdges[dst], src)
-		}
+	for _, edge := range engine.edges {
+		reverseEdges[edge.Target] = append(reverseEdges[edge.Target], edge.Source)
 	}
 
 	visited := make(map[int64]bool)
@@ -125,18 +125,20 @@ func TestBfsMarkWeakComponentAlreadyVisited(t *testing.T) {
 
 func TestComputeWeakConnectedComponents(t *testing.T) {
 	engine := &MapEngine{
-		edges: make(map[int64]map[int64]*spatial.Edge),
+		edges:    make(map[int64]*spatial.Edge),
+		outgoing: make(map[int64][]*spatial.Edge),
+		incoming: make(map[int64][]*spatial.Edge),
 	}
 
 	// Create graph with 3 components:
 	// Component A: 1 -> 2 -> 3 -> 4 (size 4, biggest)
 	// Component B: 10 -> 11 (size 2)
 	// Component C: 20 (isolated vertex with self-loop or outgoing edge to nowhere)
-	engine.edges[1] = map[int64]*spatial.Edge{2: {}}
-	engine.edges[2] = map[int64]*spatial.Edge{3: {}}
-	engine.edges[3] = map[int64]*spatial.Edge{4: {}}
-	engine.edges[10] = map[int64]*spatial.Edge{11: {}}
-	engine.edges[20] = map[int64]*spatial.Edge{21: {}}
+	engine.addEdge(&spatial.Edge{ID: 102, Source: 1, Target: 2})
+	engine.addEdge(&spatial.Edge{ID: 203, Source: 2, Target: 3})
+	engine.addEdge(&spatial.Edge{ID: 304, Source: 3, Target: 4})
+	engine.addEdge(&spatial.Edge{ID: 1011, Source: 10, Target: 11})
+	engine.addEdge(&spatial.Edge{ID: 2021, Source: 20, Target: 21})
 
 	result := engine.computeWeakConnectedComponents()
 
@@ -0,0 +1,207 @@
+package horizon
+
+import (
+	"encoding/csv"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/pkg/errors"
+)
+
+// csvFilenames Returns names of edges, vertices and shortcuts files for the name of edges file (e.g. 'graph.csv', 'graph_vertices.csv' and 'graph_shortcuts.csv')
+func csvFilenames(edgesFilename string) (string, string, string) {
+	fnamePart := strings.Split(edgesFilename, ".csv")
+	return fnamePart[0] + ".csv", fnamePart[0] + "_vertices.csv", fnamePart[0] + "_shortcuts.csv"
+}
+
+// ExportCSV Writes road graph of the engine to three CSV files which could be loaded by NewMapMatcherFromFiles
+/*
+	edgesFilename - name of edges file. Vertices and shortcuts are written next to it with '_vertices.csv' and '_shortcuts.csv' suffixes
+
+	Edges file has required columns, then one column per additional weight profile (empty value means that edge is not traversable for the profile)
+	and one column per edge attribute with its type in header (e.g. 'maxspeed:int'). Profiles should be listed in WithWeightProfiles when the files are loaded back,
+	otherwise their columns are loaded as attributes. Geometries are written in SRID of the engine
+*/
+func (engine *MapEngine) ExportCSV(edgesFilename string) error {
+	edgesFilename, verticesFilename, shortcutsFilename := csvFilenames(edgesFilename)
+	err := engine.exportEdgesCSV(edgesFilename)
+	if err != nil {
+		return err
+	}
+	err = engine.exportVerticesCSV(verticesFilename)
+	if err != nil {
+		return err
+	}
+	err = engine.graph.ExportShortcutsToFile(shortcutsFilename)
+	if err != nil {
+		return errors.Wrapf(err, "Can't write shortcuts file '%s'", shortcutsFilename)
+	}
+	return nil
+}
+
+// ExportCSV Writes road graph of the matcher's engine to CSV files (see MapEngine.ExportCSV)
+func (matcher *MapMatcher) ExportCSV(edgesFilename string) error {
+	return matcher.Engine().ExportCSV(edgesFilename)
+}
+
+// exportEdgesCSV Writes edges file
+func (engine *MapEngine) exportEdgesCSV(filename string) error {
+	profiles := engine.Profiles()[1:]
+	attributeNames, attributeTypes := engine.attributeColumns()
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return errors.Wrapf(err, "Can't create edges file '%s'", filename)
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	header := []string{COLUMN_SOURCE, COLUMN_TARGET, COLUMN_WEIGHT, COLUMN_GEOM, COLUMN_ONE_WAY, COLUMN_EDGE_ID}
+	header = append(header, profiles...)
+	for i, name := range attributeNames {
+		header = append(header, name+":"+attributeTypes[i].String())
+	}
+	err = writer.Write(header)
+	if err != nil {
+		return errors.Wrapf(err, "Can't write header of edges file '%s'", filename)
+	}
+
+	edgeIDs := make([]int64, 0, len(engine.edges))
+	for edgeID := range engine.edges {
+		edgeIDs = append(edgeIDs, edgeID)
+	}
+	sort.Slice(edgeIDs, func(i, j int) bool { return edgeIDs[i] < edgeIDs[j] })
+	for _, edgeID := range edgeIDs {
+		edge := engine.edges[edgeID]
+		geom, err := spatial.S2PolylineToWKTSRID(*edge.Polyline, engine.graphSRID)
+		if err != nil {
+			return errors.Wrapf(err, "Can't format geometry of edge %d", edgeID)
+		}
+		record := []string{
+			strconv.FormatInt(edge.Source, 10),
+			strconv.FormatInt(edge.Target, 10),
+			strconv.FormatFloat(edge.Weight, 'f', -1, 64),
+			geom,
+			strconv.FormatBool(!engine.hasOppositeEdge(edge)),
+			strconv.FormatInt(edge.ID, 10),
+		}
+		for _, name := range profiles {
+			value := ""
+			if weight, ok := engine.profiles[name].weight(edge); ok {
+				value = strconv.FormatFloat(weight, 'f', -1, 64)
+			}
+			record = append(record, value)
+		}
+		attributes := engine.attributes[edgeID]
+		for _, name := range attributeNames {
+			value := ""
+			if attribute, ok := attributes[name]; ok {
+				value = spatial.FormatAttributeValue(attribute)
+			}
+			record = append(record, value)
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return errors.Wrapf(err, "Can't write edge %d", edgeID)
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return errors.Wrapf(err, "Can't write edges file '%s'", filename)
+	}
+	return nil
+}
+
+// exportVerticesCSV Writes vertices file with order positions and importance of vertices in contraction hierarchy
+func (engine *MapEngine) exportVerticesCSV(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return errors.Wrapf(err, "Can't create vertices file '%s'", filename)
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+	err = writer.Write([]string{"vertex_id", "order_pos", "importance", "geom"})
+	if err != nil {
+		return errors.Wrapf(err, "Can't write header of vertices file '%s'", filename)
+	}
+	for i := range engine.graph.Vertices {
+		vertexID := engine.graph.Vertices[i].Label
+		vertex, ok := engine.vertices[vertexID]
+		if !ok || vertex.Point == nil {
+			return errors.Wrapf(ErrInconsistentGraph, "vertex %d has no geometry", vertexID)
+		}
+		geom, err := spatial.S2PointToWKTSRID(*vertex.Point, engine.graphSRID)
+		if err != nil {
+			return errors.Wrapf(err, "Can't format geometry of vertex %d", vertexID)
+		}
+		err = writer.Write([]string{
+			strconv.FormatInt(vertexID, 10),
+			strconv.FormatInt(engine.graph.Vertices[i].OrderPos(), 10),
+			strconv.Itoa(engine.graph.Vertices[i].Importance()),
+			geom,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Can't write vertex %d", vertexID)
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return errors.Wrapf(err, "Can't write vertices file '%s'", filename)
+	}
+	return nil
+}
+
+// attributeColumns Returns sorted names of edge attributes and types of their values.
+// Attribute with values of different types is written as string one (int and float values give float one)
+func (engine *MapEngine) attributeColumns() ([]string, []spatial.AttributeType) {
+	types := make(map[string]spatial.AttributeType)
+	for _, attributes := range engine.attributes {
+		for name, value := range attributes {
+			var valueType spatial.AttributeType
+			switch value.(type) {
+			case int64:
+				valueType = spatial.AttributeTypeInt
+			case float64:
+				valueType = spatial.AttributeTypeFloat
+			case bool:
+				valueType = spatial.AttributeTypeBool
+			default:
+				valueType = spatial.AttributeTypeString
+			}
+			previous, ok := types[name]
+			switch {
+			case !ok || previous == valueType:
+				types[name] = valueType
+			case (previous == spatial.AttributeTypeInt && valueType == spatial.AttributeTypeFloat) || (previous == spatial.AttributeTypeFloat && valueType == spatial.AttributeTypeInt):
+				types[name] = spatial.AttributeTypeFloat
+			default:
+				types[name] = spatial.AttributeTypeString
+			}
+		}
+	}
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ans := make([]spatial.AttributeType, len(names))
+	for i, name := range names {
+		ans[i] = types[name]
+	}
+	return names, ans
+}
+
+// hasOppositeEdge Checks if there is an edge going from target of the edge to its source
+func (engine *MapEngine) hasOppositeEdge(edge *spatial.Edge) bool {
+	for _, opposite := range engine.outgoing[edge.Target] {
+		if opposite.Target == edge.Source {
+			return true
+		}
+	}
+	return false
+}
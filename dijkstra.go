@@ -0,0 +1,148 @@
+package horizon
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/LdDl/horizon/spatial"
+)
+
+// Plain Dijkstra's algorithm over engine's edges (weights are taken from the query's profile).
+// Contraction hierarchies can't skip edges at query time since shortcuts hide the original edges,
+// so this is used as a fallback for requests which exclude some of edges (road closures, avoid-areas and etc.).
+
+// dijkstraLabel Settled state of a vertex
+/*
+	cost - cost of reaching the vertex
+	prev - previous vertex on the shortest path (-1 for the source vertex)
+	origin - seed vertex which the shortest path starts from (matters for multi-source searches)
+	edge - edge between previous vertex and the vertex (nil for seed vertices). There could be several parallel edges between them
+*/
+type dijkstraLabel struct {
+	cost   float64
+	prev   int64
+	origin int64
+	edge   *spatial.Edge
+}
+
+// dijkstraItem Element of priority queue
+type dijkstraItem struct {
+	vertex int64
+	prev   int64
+	origin int64
+	cost   float64
+	edge   *spatial.Edge
+}
+
+// dijkstraHeap implements heap.Interface for dijkstraItem
+type dijkstraHeap []dijkstraItem
+
+func (h dijkstraHeap) Less(i, j int) bool { return h[i].cost < h[j].cost }
+func (h dijkstraHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h dijkstraHeap) Len() int           { return len(h) }
+
+func (h *dijkstraHeap) Push(x interface{}) {
+	*h = append(*h, x.(dijkstraItem))
+}
+
+func (h *dijkstraHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[0 : n-1]
+	return x
+}
+
+// dijkstra Runs Dijkstra's algorithm from the source vertex
+/*
+	source - source vertex
+	target - search stops when this vertex is settled (use -1 to disable)
+	maxCost - search does not settle vertices with greater cost (use math.MaxFloat64 to disable)
+	reverse - search on reversed graph: incoming edges are traversed, so costs are the ones of reaching the source from settled vertices
+
+	Edges excluded by the query and edges which are not traversable for the query's profile are skipped
+*/
+func (query *routingQuery) dijkstra(source, target int64, maxCost float64, reverse bool) map[int64]dijkstraLabel {
+	return query.dijkstraSeeds(map[int64]float64{source: 0}, target, maxCost, reverse)
+}
+
+// dijkstraSeeds Runs Dijkstra's algorithm from several vertices with initial costs (e.g. both ends of the edge which the source point is projected onto)
+/*
+	seeds - initial costs of vertices which search starts from (previous vertex is -1 for each of them)
+	target - search stops when this vertex is settled (use -1 to disable)
+	maxCost - search does not settle vertices with greater cost (use math.MaxFloat64 to disable)
+	reverse - search on reversed graph (see dijkstra)
+*/
+func (query *routingQuery) dijkstraSeeds(seeds map[int64]float64, target int64, maxCost float64, reverse bool) map[int64]dijkstraLabel {
+	settled := make(map[int64]dijkstraLabel)
+	queue := &dijkstraHeap{}
+	for vertex, cost := range seeds {
+		heap.Push(queue, dijkstraItem{vertex: vertex, prev: -1, origin: vertex, cost: cost})
+	}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(dijkstraItem)
+		if _, ok := settled[item.vertex]; ok {
+			continue
+		}
+		if item.cost > maxCost {
+			break
+		}
+		settled[item.vertex] = dijkstraLabel{cost: item.cost, prev: item.prev, origin: item.origin, edge: item.edge}
+		if item.vertex == target {
+			break
+		}
+		adjacent := query.engine.outgoingEdges(item.vertex)
+		if reverse {
+			adjacent = query.engine.incomingEdges(item.vertex)
+		}
+		for _, edge := range adjacent {
+			neighbor := edge.Target
+			if reverse {
+				neighbor = edge.Source
+			}
+			if _, ok := settled[neighbor]; ok {
+				continue
+			}
+			if query.isExcluded(edge.ID) {
+				continue
+			}
+			weight, ok := query.profile.weight(edge)
+			if !ok {
+				continue
+			}
+			heap.Push(queue, dijkstraItem{vertex: neighbor, prev: item.vertex, origin: item.origin, cost: item.cost + weight, edge: edge})
+		}
+	}
+	return settled
+}
+
+// dijkstraShortestPath Returns cost and vertices of the shortest path between two vertices skipping excluded edges.
+// Cost is -1 when path does not exist (the same convention as in ch.QueryPool)
+func (query *routingQuery) dijkstraShortestPath(source, target int64) (float64, []int64) {
+	settled := query.dijkstra(source, target, math.MaxFloat64, false)
+	label, ok := settled[target]
+	if !ok {
+		return -1, nil
+	}
+	path := []int64{}
+	for vertex := target; vertex != -1; vertex = settled[vertex].prev {
+		path = append(path, vertex)
+		if vertex == source {
+			break
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return label.cost, path
+}
+
+// dijkstraIsochrones Returns costs of reaching vertices within maxCost skipping excluded edges (costs of reaching the source for reverse requests)
+func (query *routingQuery) dijkstraIsochrones(source int64, maxCost float64) map[int64]float64 {
+	settled := query.dijkstra(source, -1, maxCost, query.reverse)
+	ans := make(map[int64]float64, len(settled))
+	for vertex, label := range settled {
+		ans[vertex] = label.cost
+	}
+	return ans
+}
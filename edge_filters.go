@@ -0,0 +1,80 @@
+package horizon
+
+import (
+	"github.com/LdDl/horizon/spatial"
+)
+
+// EdgeFilter Returns true if edge passes the filter (see WithEmissionFilter and WithRoutingFilter)
+/*
+	edge - edge to check
+	attributes - attributes of the edge (nil if edge has no attributes)
+*/
+type EdgeFilter func(edge *spatial.Edge, attributes spatial.EdgeAttributes) bool
+
+// And Returns filter which is passed by edge when it passes both filters. Nil filter passes every edge
+func (filter EdgeFilter) And(other EdgeFilter) EdgeFilter {
+	if filter == nil {
+		return other
+	}
+	if other == nil {
+		return filter
+	}
+	return func(edge *spatial.Edge, attributes spatial.EdgeAttributes) bool {
+		return filter(edge, attributes) && other(edge, attributes)
+	}
+}
+
+// AttributeIn Returns filter which is passed by edges having the attribute equal to any of values
+/*
+	name - name of attribute
+	values - allowed values. Values are compared in string form, so 60 and "60" are equal (see spatial.FormatAttributeValue)
+
+	Edges without the attribute do not pass the filter
+*/
+func AttributeIn(name string, values ...interface{}) EdgeFilter {
+	allowed := attributeValuesSet(values)
+	return func(edge *spatial.Edge, attributes spatial.EdgeAttributes) bool {
+		value, ok := attributes.String(name)
+		if !ok {
+			return false
+		}
+		_, ok = allowed[value]
+		return ok
+	}
+}
+
+// AttributeNotIn Returns filter which is passed by edges having the attribute not equal to any of values (see AttributeIn).
+// Edges without the attribute pass the filter
+func AttributeNotIn(name string, values ...interface{}) EdgeFilter {
+	forbidden := attributeValuesSet(values)
+	return func(edge *spatial.Edge, attributes spatial.EdgeAttributes) bool {
+		value, ok := attributes.String(name)
+		if !ok {
+			return true
+		}
+		_, ok = forbidden[value]
+		return !ok
+	}
+}
+
+// attributeValuesSet Returns set of values in string form
+func attributeValuesSet(values []interface{}) map[string]struct{} {
+	ans := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		ans[spatial.FormatAttributeValue(value)] = struct{}{}
+	}
+	return ans
+}
+
+// EdgeAttributes Returns attributes of the edge (nil if edge has no attributes) and whether edge exists
+func (engine *MapEngine) EdgeAttributes(edgeID int64) (spatial.EdgeAttributes, bool) {
+	if _, ok := engine.edges[edgeID]; !ok {
+		return nil, false
+	}
+	return engine.attributes[edgeID], true
+}
+
+// AttributeNames Returns names of attributes loaded from edges file in order of columns
+func (engine *MapEngine) AttributeNames() []string {
+	return engine.attributeNames
+}
@@ -0,0 +1,201 @@
+package horizon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LdDl/horizon/spatial"
+)
+
+func TestEdgeAttributesFromCSV(t *testing.T) {
+	dir := t.TempDir()
+	content, err := os.ReadFile("./test_data/matcher_4326_test.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	lines[0] += ";highway;maxspeed:int;name:string;lit"
+	extra := []string{";primary;60;Main street;true", ";service;20;;false", ";primary;60;100;", ";residential;;Side street;yes", ";primary;60;Main street;true"}
+	for i := range extra {
+		lines[i+1] += extra[i]
+	}
+	edgesFilename := filepath.Join(dir, "graph.csv")
+	err = os.WriteFile(edgesFilename, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, suffix := range []string{"_vertices.csv", "_shortcuts.csv"} {
+		content, err := os.ReadFile("./test_data/matcher_4326_test" + suffix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(dir, "graph"+suffix), content, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	matcher, err := NewMapMatcherFromFiles(HmmProbabilitiesDefault(), edgesFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedNames := []string{"highway", "maxspeed", "name", "lit"}
+	names := matcher.engine.AttributeNames()
+	if strings.Join(names, ",") != strings.Join(expectedNames, ",") {
+		t.Errorf("Attribute names should be %v, but got %v", expectedNames, names)
+	}
+	attributes, ok := matcher.engine.EdgeAttributes(1)
+	if !ok {
+		t.Fatal("Edge 1 should exist")
+	}
+	if highway, _ := attributes.String("highway"); highway != "primary" {
+		t.Errorf("Attribute 'highway' of edge 1 should be 'primary', but got '%s'", highway)
+	}
+	if maxspeed, ok := attributes.Int("maxspeed"); !ok || maxspeed != 60 {
+		t.Errorf("Attribute 'maxspeed' of edge 1 should be 60, but got %d", maxspeed)
+	}
+	if lit, ok := attributes.Bool("lit"); !ok || !lit {
+		t.Errorf("Attribute 'lit' of edge 1 should be true, but got %v", attributes["lit"])
+	}
+	// Type is forced by header: number is kept as string
+	attributes, _ = matcher.engine.EdgeAttributes(3)
+	if name, ok := attributes.Get("name"); !ok || name != "100" {
+		t.Errorf("Attribute 'name' of edge 3 should be string '100', but got %v", name)
+	}
+	// Empty values are skipped, values of untyped columns are guessed
+	attributes, _ = matcher.engine.EdgeAttributes(4)
+	if _, ok := attributes.Get("maxspeed"); ok {
+		t.Errorf("Edge 4 should not have attribute 'maxspeed'")
+	}
+	if lit, _ := attributes.Get("lit"); lit != "yes" {
+		t.Errorf("Attribute 'lit' of edge 4 should be string 'yes', but got %v", lit)
+	}
+	if _, ok := matcher.engine.EdgeAttributes(100); ok {
+		t.Errorf("Edge 100 should not exist")
+	}
+
+	// Value which does not fit the type from header
+	lines[2] = strings.Replace(lines[2], ";20;", ";twenty;", 1)
+	err = os.WriteFile(edgesFilename, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewMapMatcherFromFiles(HmmProbabilitiesDefault(), edgesFilename)
+	if err == nil {
+		t.Errorf("Expected error for non-integer value of 'maxspeed:int' column")
+	}
+}
+
+func TestParseEdgesHeader(t *testing.T) {
+	// Headers written by osm2ch do not have attributes
+	columns, err := parseEdgesHeader([]string{"from_vertex_id", "to_vertex_id", "weight", "geom", "was_one_way", "edge_id"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if columns.source != 0 || columns.target != 1 || columns.weight != 2 || columns.geom != 3 || columns.edgeID != 5 || len(columns.attributes) != 0 {
+		t.Errorf("Unexpected columns %+v", columns)
+	}
+	// Columns could go in any order; profile columns are not attributes
+	columns, err = parseEdgesHeader([]string{"\ufeffedge_id", "highway:string", "geom", "travel_time", "weight", "to_vertex_id", "from_vertex_id"}, []string{"travel_time"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if columns.source != 6 || columns.target != 5 || columns.weight != 4 || columns.geom != 2 || columns.edgeID != 0 {
+		t.Errorf("Unexpected columns %+v", columns)
+	}
+	if len(columns.attributes) != 1 || columns.attributes[0].name != "highway" || columns.attributes[0].attributeType != spatial.AttributeTypeString {
+		t.Errorf("Unexpected attributes %+v", columns.attributes)
+	}
+	// Legacy header: columns are taken by positions
+	columns, err = parseEdgesHeader([]string{"source", "target", "cost", "wkt", "oneway", "id"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if columns.source != 0 || columns.edgeID != 5 || len(columns.attributes) != 0 {
+		t.Errorf("Unexpected columns %+v", columns)
+	}
+
+	badHeaders := [][]string{
+		{"from_vertex_id", "to_vertex_id", "weight", "geom"},
+		{"from_vertex_id", "to_vertex_id", "weight", "geom", "edge_id", "weight"},
+		{"from_vertex_id", "to_vertex_id", "weight", "geom", "edge_id", "maxspeed:integer"},
+	}
+	for _, header := range badHeaders {
+		_, err = parseEdgesHeader(header, nil)
+		if err == nil {
+			t.Errorf("Expected error for header %v", header)
+		}
+	}
+}
+
+func TestEdgeFilters(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	WithEdgeAttributes(map[int64]spatial.EdgeAttributes{
+		1: {"highway": "primary", "maxspeed": int64(60)},
+		2: {"highway": "service", "maxspeed": int64(20)},
+		3: {"highway": "primary", "maxspeed": int64(60)},
+		4: {"highway": "residential"},
+		5: {"highway": "residential"},
+		6: {"highway": "primary", "maxspeed": int64(60)},
+	})(matcher.engine)
+
+	if !AttributeIn("maxspeed", 20, 60)(nil, matcher.engine.attributes[2]) {
+		t.Errorf("Edge 2 should pass AttributeIn filter")
+	}
+	if AttributeIn("maxspeed", "60")(nil, matcher.engine.attributes[4]) {
+		t.Errorf("Edge 4 without attribute should not pass AttributeIn filter")
+	}
+	if !AttributeNotIn("maxspeed", "60")(nil, matcher.engine.attributes[4]) {
+		t.Errorf("Edge 4 without attribute should pass AttributeNotIn filter")
+	}
+
+	// Edge 2 is the nearest one, but service roads are not used for snapping
+	point := NewGPSMeasurementFromID(1, 3, -1, 0)
+	nearest, err := matcher.Nearest(point, 1, -1, WithEmissionFilter(AttributeNotIn("highway", "service")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nearest) != 1 || nearest[0].Edge.ID != 3 {
+		t.Fatalf("Nearest edge should be 3, but got %v", nearest)
+	}
+	if highway, _ := nearest[0].Attributes.String("highway"); highway != "primary" {
+		t.Errorf("Nearest edge should have attribute 'highway' equal to 'primary', but got '%s'", highway)
+	}
+
+	// Route avoids service road
+	source := NewGPSMeasurementFromID(1, -4.9, 0.1, 0)
+	target := NewGPSMeasurementFromID(2, 14.9, 0.1, 0)
+	cases := []struct {
+		name  string
+		opts  []QueryOption
+		edges []int64
+	}{
+		{"default", nil, []int64{2, 3}},
+		{"no service roads", []QueryOption{WithRoutingFilter(AttributeNotIn("highway", "service"))}, []int64{4, 5}},
+		{"combined filters", []QueryOption{WithRoutingFilter(AttributeIn("highway", "primary", "service")), WithRoutingFilter(AttributeIn("maxspeed", 60, 20))}, []int64{2, 3}},
+	}
+	for _, c := range cases {
+		result, err := matcher.FindShortestPath(source, target, -1, c.opts...)
+		if err != nil {
+			t.Fatalf("Case '%s': %v", c.name, err)
+		}
+		observations := result.SubMatches[0].Observations
+		edges := []EdgeResult{{ID: observations[0].MatchedEdge.ID, Attributes: observations[0].MatchedEdgeAttributes}}
+		edges = append(edges, observations[0].NextEdges...)
+		edges = append(edges, EdgeResult{ID: observations[1].MatchedEdge.ID, Attributes: observations[1].MatchedEdgeAttributes})
+		if len(edges) != len(c.edges) {
+			t.Errorf("Case '%s': expected %d edges, but got %d", c.name, len(c.edges), len(edges))
+			continue
+		}
+		for i := range edges {
+			if edges[i].ID != c.edges[i] {
+				t.Errorf("Case '%s': edge #%d should be %d, but got %d", c.name, i, c.edges[i], edges[i].ID)
+			}
+			if len(edges[i].Attributes) == 0 {
+				t.Errorf("Case '%s': edge #%d should have attributes", c.name, i)
+			}
+		}
+	}
+}
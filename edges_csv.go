@@ -0,0 +1,191 @@
+package horizon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// Names of required columns of edges file (see https://github.com/LdDl/osm2ch#osm2ch)
+const (
+	COLUMN_SOURCE  = "from_vertex_id"
+	COLUMN_TARGET  = "to_vertex_id"
+	COLUMN_WEIGHT  = "weight"
+	COLUMN_GEOM    = "geom"
+	COLUMN_EDGE_ID = "edge_id"
+	// COLUMN_ONE_WAY Is written by osm2ch, but it is not an attribute: opposite edges are already in the file
+	COLUMN_ONE_WAY = "was_one_way"
+)
+
+// edgesColumns Positions of columns in edges file
+/*
+	source, target, weight, geom, edgeID - positions of required columns
+	attributes - columns which are kept as edge attributes
+	profiles - positions of columns of weight profiles found in header
+*/
+type edgesColumns struct {
+	source     int
+	target     int
+	weight     int
+	geom       int
+	edgeID     int
+	attributes []attributeColumn
+	profiles   map[string]int
+}
+
+// attributeColumn Column of edges file which is kept as edge attribute
+type attributeColumn struct {
+	idx           int
+	name          string
+	attributeType spatial.AttributeType
+}
+
+// parseEdgesHeader Returns positions of columns in edges file
+/*
+	header - header of edges file
+	profiles - names of columns which are loaded as weight profiles (they are not attributes)
+
+	Required columns are found by their names, every other column becomes an attribute of edges (type of attribute could be set in header, see spatial.ParseAttributeColumn).
+	Header without any of required names is treated as legacy one: columns are taken by their positions (source;target;weight;geom;was_one_way;edge_id) and there are no attributes
+*/
+func parseEdgesHeader(header []string, profiles []string) (edgesColumns, error) {
+	columns := edgesColumns{source: -1, target: -1, weight: -1, geom: -1, edgeID: -1, profiles: make(map[string]int)}
+	required := map[string]*int{
+		COLUMN_SOURCE:  &columns.source,
+		COLUMN_TARGET:  &columns.target,
+		COLUMN_WEIGHT:  &columns.weight,
+		COLUMN_GEOM:    &columns.geom,
+		COLUMN_EDGE_ID: &columns.edgeID,
+	}
+	isProfile := make(map[string]bool, len(profiles))
+	for _, name := range profiles {
+		isProfile[name] = true
+	}
+	found := 0
+	for i := range header {
+		name := strings.TrimSpace(header[i])
+		if i == 0 {
+			// Byte order mark could be written by spreadsheet editors
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		if idx, ok := required[name]; ok {
+			if *idx != -1 {
+				return edgesColumns{}, fmt.Errorf("duplicate column '%s'", name)
+			}
+			*idx = i
+			found++
+			continue
+		}
+		if isProfile[name] {
+			if _, ok := columns.profiles[name]; !ok {
+				columns.profiles[name] = i
+			}
+			continue
+		}
+		if name == COLUMN_ONE_WAY || name == "" {
+			continue
+		}
+		attributeName, attributeType, err := spatial.ParseAttributeColumn(name)
+		if err != nil {
+			return edgesColumns{}, err
+		}
+		columns.attributes = append(columns.attributes, attributeColumn{idx: i, name: attributeName, attributeType: attributeType})
+	}
+	if found == 0 {
+		return edgesColumns{source: 0, target: 1, weight: 2, geom: 3, edgeID: 5, profiles: columns.profiles}, nil
+	}
+	for _, name := range []string{COLUMN_SOURCE, COLUMN_TARGET, COLUMN_WEIGHT, COLUMN_GEOM, COLUMN_EDGE_ID} {
+		if *required[name] == -1 {
+			return edgesColumns{}, fmt.Errorf("there is no required column '%s'", name)
+		}
+	}
+	return columns, nil
+}
+
+// maxIdx Returns max position of required column: shorter records are malformed
+func (columns edgesColumns) maxIdx() int {
+	ans := columns.source
+	for _, idx := range []int{columns.target, columns.weight, columns.geom, columns.edgeID} {
+		if idx > ans {
+			ans = idx
+		}
+	}
+	return ans
+}
+
+// parseAttributes Returns attributes of edge from the record. Empty values are skipped. Returns nil if there are no attributes
+func (columns edgesColumns) parseAttributes(record []string) (spatial.EdgeAttributes, error) {
+	var attributes spatial.EdgeAttributes
+	for _, column := range columns.attributes {
+		if column.idx >= len(record) || strings.TrimSpace(record[column.idx]) == "" {
+			continue
+		}
+		value, err := spatial.ParseAttributeValue(record[column.idx], column.attributeType)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't parse attribute '%s' of type '%s'. The value is '%s'", column.name, column.attributeType, record[column.idx])
+		}
+		if attributes == nil {
+			attributes = make(spatial.EdgeAttributes, len(columns.attributes))
+		}
+		attributes[column.name] = value
+	}
+	return attributes, nil
+}
+
+// parseEdge Returns edge without geometry (see parseGeometry) and its attributes described by the record
+func (columns edgesColumns) parseEdge(record []string) (*spatial.Edge, spatial.EdgeAttributes, error) {
+	if len(record) <= columns.maxIdx() {
+		return nil, nil, fmt.Errorf("not enough columns in edges file: expected at least %d, got %d", columns.maxIdx()+1, len(record))
+	}
+	sourceVertex, err := strconv.ParseInt(record[columns.source], 10, 64)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, fmt.Sprintf("Can't parse source vertex in edges file. The vertex is '%s'", record[columns.source]))
+	}
+	targetVertex, err := strconv.ParseInt(record[columns.target], 10, 64)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, fmt.Sprintf("Can't parse target vertex in edges file. The vertex is '%s'", record[columns.target]))
+	}
+	weight, err := strconv.ParseFloat(record[columns.weight], 64)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, fmt.Sprintf("Can't parse weight of an edge in edges file. The weight is '%s'", record[columns.weight]))
+	}
+	edgeID, err := strconv.ParseInt(record[columns.edgeID], 10, 64)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, fmt.Sprintf("Can't parse edge identifier in edges file. The edge is '%s'", record[columns.edgeID]))
+	}
+	attributes, err := columns.parseAttributes(record)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, fmt.Sprintf("Can't parse attributes of edge %d in edges file", edgeID))
+	}
+	return &spatial.Edge{ID: edgeID, Source: sourceVertex, Target: targetVertex, Weight: weight}, attributes, nil
+}
+
+// parseGeometry Returns geometry of edge described by the record. Geometry is in the SRID
+func (columns edgesColumns) parseGeometry(record []string, srid int) (*s2.Polyline, error) {
+	coordinates := record[columns.geom]
+	s2Polyline, err := spatial.WKTToS2PolylineFeatureSRID(coordinates, srid)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Can't parse WKT geometry of the edge: from_vertex_id = '%s' | to_vertex_id = '%s' | geom = '%s'", record[columns.source], record[columns.target], coordinates))
+	}
+	return s2Polyline, nil
+}
+
+// parseProfiles Returns weights of the edge for profiles found in header. Empty value means that the edge is not traversable for the profile
+func (columns edgesColumns) parseProfiles(record []string) (map[string]float64, error) {
+	weights := make(map[string]float64, len(columns.profiles))
+	for name, idx := range columns.profiles {
+		if idx >= len(record) || strings.TrimSpace(record[idx]) == "" {
+			continue
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(record[idx]), 64)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Can't parse weight of an edge for profile '%s' in edges file. The weight is '%s'", name, record[idx]))
+		}
+		weights[name] = weight
+	}
+	return weights, nil
+}
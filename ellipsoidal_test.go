@@ -0,0 +1,84 @@
+package horizon
+
+import (
+	"math"
+	"testing"
+
+	"github.com/LdDl/horizon/spatial"
+)
+
+func TestMapEngineEllipsoidalDistances(t *testing.T) {
+	hmmParams := NewHmmProbabilities(50.0, 2.0)
+	sphericalMatcher, err := NewMapMatcherFromFiles(hmmParams, "./test_data/matcher_4326_test.csv", WithWeightProfiles(LENGTH_PROFILE))
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher, err := NewMapMatcherFromFiles(hmmParams, "./test_data/matcher_4326_test.csv", WithWeightProfiles(LENGTH_PROFILE), WithEllipsoidalDistances())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !matcher.engine.IsEllipsoidal() || sphericalMatcher.engine.IsEllipsoidal() {
+		t.Fatalf("Only second engine should be ellipsoidal")
+	}
+
+	point := NewGPSMeasurementFromID(1, 37.66373679411533, 55.77352528537278, 4326)
+	nearest, err := matcher.Nearest(point, 1, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sphericalNearest, err := sphericalMatcher.Nearest(point, 1, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nearest) != 1 || len(sphericalNearest) != 1 {
+		t.Fatalf("Expected single nearest edge, but got %d and %d", len(nearest), len(sphericalNearest))
+	}
+	edge := nearest[0].Edge
+	if edge.ID != sphericalNearest[0].Edge.ID {
+		t.Errorf("Nearest edge should not depend on distance model, but got %d and %d", edge.ID, sphericalNearest[0].Edge.ID)
+	}
+	eps := 1e-6
+	expectedLength := spatial.PolylineLengthGeodesic(*edge.Polyline)
+	if math.Abs(nearest[0].Length-expectedLength) > eps {
+		t.Errorf("Length of edge should be %f, but got %f", expectedLength, nearest[0].Length)
+	}
+	if math.Abs(nearest[0].Length-sphericalNearest[0].Length) < 1e-3 {
+		t.Errorf("Ellipsoidal length %f should differ from spherical one %f", nearest[0].Length, sphericalNearest[0].Length)
+	}
+	expectedDistance := spatial.GeodesicDistance(point.Point, nearest[0].ProjectedPoint)
+	if math.Abs(nearest[0].Distance-expectedDistance) > eps {
+		t.Errorf("Distance to edge should be %f, but got %f", expectedDistance, nearest[0].Distance)
+	}
+	if weight := matcher.engine.profiles[LENGTH_PROFILE].weights[edge.ID]; math.Abs(weight-expectedLength) > eps {
+		t.Errorf("Weight of edge for '%s' profile should be %f, but got %f", LENGTH_PROFILE, expectedLength, weight)
+	}
+
+	gpsMeasurements := GPSMeasurements{
+		NewGPSMeasurementFromID(1, 37.662745994981435, 55.77323867786974, 4326),
+		NewGPSMeasurementFromID(2, 37.66373679411533, 55.77352528537278, 4326),
+		NewGPSMeasurementFromID(3, 37.6634658408828, 55.77408712095024, 4326),
+		NewGPSMeasurementFromID(4, 37.66271768643477, 55.77491052526131, 4326),
+	}
+	result, err := matcher.Run(gpsMeasurements, 7.0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sphericalResult, err := sphericalMatcher.Run(gpsMeasurements, 7.0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.SubMatches) != len(sphericalResult.SubMatches) {
+		t.Fatalf("Expected %d sub-matches, but got %d", len(sphericalResult.SubMatches), len(result.SubMatches))
+	}
+	for s := range result.SubMatches {
+		for i, observation := range result.SubMatches[s].Observations {
+			expected := sphericalResult.SubMatches[s].Observations[i]
+			if observation.MatchedEdge.ID != expected.MatchedEdge.ID {
+				t.Errorf("Observation #%d of sub-match #%d should be matched to edge %d, but got %d", i, s, expected.MatchedEdge.ID, observation.MatchedEdge.ID)
+			}
+			if math.Abs(observation.MatchedEdgeLength-matcher.engine.edgeLength(&observation.MatchedEdge)) > eps {
+				t.Errorf("Length of matched edge of observation #%d of sub-match #%d should be ellipsoidal one", i, s)
+			}
+		}
+	}
+}
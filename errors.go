@@ -15,4 +15,18 @@ var (
 	ErrPathNotFound           = fmt.Errorf("path not found")
 	ErrSameVertex             = fmt.Errorf("same vertex")
 	ErrDifferentComponents    = fmt.Errorf("vertices are in different connected components")
+	ErrProfileNotFound        = fmt.Errorf("weight profile not found")
+	ErrMinimumWaypoints       = fmt.Errorf("number of waypoints need to be 2 atleast")
+	ErrUnknownRouteFormat     = fmt.Errorf("unknown route geometry format")
+	ErrEmptyMaxCosts          = fmt.Errorf("at least one valid max cost is required")
+	ErrSnapshotFormat         = fmt.Errorf("invalid snapshot format")
+	ErrSnapshotVersion        = fmt.Errorf("unsupported snapshot version")
+	ErrSnapshotChecksum       = fmt.Errorf("snapshot checksum mismatch")
+	ErrSnapshotOptions        = fmt.Errorf("options of engine conflict with snapshot")
+	ErrEmptyGraph             = fmt.Errorf("graph has no edges")
+	ErrInconsistentGraph      = fmt.Errorf("inconsistent graph")
+	ErrGeoJSONFormat          = fmt.Errorf("invalid GeoJSON road graph")
+	ErrOSMFormat              = fmt.Errorf("invalid OSM PBF file")
+	ErrReloadInProgress       = fmt.Errorf("graph reload is already in progress")
+	ErrReloadSnapshot         = fmt.Errorf("graph of matcher's snapshot can't be replaced")
 )
@@ -0,0 +1,270 @@
+package horizon
+
+import (
+	"math"
+	"sort"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// Facility Point of interest (charger, warehouse and etc.)
+/*
+	ID - identifier of the facility
+	Name - optional human readable name
+	Point - location of the facility
+*/
+type Facility struct {
+	ID    int64
+	Name  string
+	Point s2.Point
+}
+
+// SnappedFacility Facility snapped to the nearest edge at load time
+/*
+	Edge - the nearest edge
+	ProjectedPoint - projection of the facility onto the edge
+	Fraction - number in [0;1], describes how far projected point from the first point of edge
+	Distance - distance from the facility to the projected point (meters for WGS84 graphs)
+*/
+type SnappedFacility struct {
+	Facility
+	Edge           *spatial.Edge
+	ProjectedPoint s2.Point
+	Fraction       float64
+	Distance       float64
+}
+
+// FacilityResult Facility found by network search
+/*
+	Facility - found facility
+	Cost - travel cost from the point to the facility for the request's weight profile
+	Length - length of the route (meters for WGS84 graphs)
+	Route - geometry of the route from projection of the point to projection of the facility
+	EdgeIDs - identifiers of edges along the route (the first and the last ones could be passed partially)
+*/
+type FacilityResult struct {
+	Facility *SnappedFacility
+	Cost     float64
+	Length   float64
+	Route    s2.Polyline
+	EdgeIDs  []int64
+}
+
+// SetFacilities Replaces set of facilities. Every facility is snapped to its nearest edge
+/*
+	facilities - new set of facilities
+	maxSnapRadius - max distance between facility and its edge (use -1 for unlimited)
+
+	Returns identifiers of facilities which have no edge within the radius (such facilities are not stored)
+*/
+func (engine *MapEngine) SetFacilities(facilities []Facility, maxSnapRadius float64) ([]int64, error) {
+	query, err := engine.prepareQuery()
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't prepare query")
+	}
+	snapped := make([]*SnappedFacility, 0, len(facilities))
+	skipped := []int64{}
+	for _, facility := range facilities {
+		nearest, err := engine.nearest(query, facility.Point, 1, maxSnapRadius)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't snap facility with id '%d'", facility.ID)
+		}
+		if len(nearest) == 0 {
+			skipped = append(skipped, facility.ID)
+			continue
+		}
+		snapped = append(snapped, &SnappedFacility{
+			Facility:       facility,
+			Edge:           nearest[0].Edge,
+			ProjectedPoint: nearest[0].ProjectedPoint,
+			Fraction:       nearest[0].Fraction,
+			Distance:       nearest[0].Distance,
+		})
+	}
+	engine.facilitiesMu.Lock()
+	engine.facilities = snapped
+	engine.facilitiesRadius = maxSnapRadius
+	engine.facilitiesMu.Unlock()
+	return skipped, nil
+}
+
+// Facilities Returns current set of snapped facilities
+func (engine *MapEngine) Facilities() []*SnappedFacility {
+	engine.facilitiesMu.RLock()
+	defer engine.facilitiesMu.RUnlock()
+	return engine.facilities
+}
+
+// SetFacilities Replaces set of facilities (see MapEngine.SetFacilities). Facilities are kept when graph is replaced (see ReplaceEngine)
+func (matcher *MapMatcher) SetFacilities(facilities []Facility, maxSnapRadius float64) ([]int64, error) {
+	if !matcher.snapshot {
+		matcher.reloadMu.Lock()
+		defer matcher.reloadMu.Unlock()
+	}
+	return matcher.Engine().SetFacilities(facilities, maxSnapRadius)
+}
+
+// Facilities Returns current set of snapped facilities (see MapEngine.Facilities)
+func (matcher *MapMatcher) Facilities() []*SnappedFacility {
+	return matcher.Engine().Facilities()
+}
+
+// NearestFacilities Returns up to K facilities which are the nearest to the point by network travel cost
+/*
+	point - point to search facilities for
+	k - max number of facilities
+	maxCost - max travel cost to facility (use -1 for unlimited)
+	maxNearestRadius - max radius of search for nearest edge of the point
+	opts - per-request options (see QueryOptions). Excluded edges are neither used for snapping nor traversed. Facilities on such edges are never returned
+
+	Bounded Dijkstra's search starts from projection of the point onto the nearest edge (and onto the opposite edge of two-way road).
+	Facility is reached along its edge or along the opposite edge of two-way road. Result is sorted by cost
+*/
+func (matcher *MapMatcher) NearestFacilities(point *GPSMeasurement, k int, maxCost float64, maxNearestRadius float64, opts ...QueryOption) ([]FacilityResult, error) {
+	engine := matcher.Engine()
+	err := checkMeasurementsSRID(point)
+	if err != nil {
+		return nil, err
+	}
+	if k <= 0 {
+		return []FacilityResult{}, nil
+	}
+	facilities := engine.Facilities()
+	if len(facilities) == 0 {
+		return []FacilityResult{}, nil
+	}
+	query, err := engine.prepareQuery(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't prepare query")
+	}
+	// Facilities are destinations: search is always done along edges direction
+	query.reverse = false
+	if maxCost < 0 {
+		maxCost = math.MaxFloat64
+	}
+	sourceEdges, err := engine.projectSource(query, point.Point, maxNearestRadius)
+	if err != nil {
+		return nil, err
+	}
+	seeds := make(map[int64]float64, len(sourceEdges))
+	for _, source := range sourceEdges {
+		cost := source.weight * (1 - source.fraction)
+		if existing, ok := seeds[source.edge.Target]; !ok || cost < existing {
+			seeds[source.edge.Target] = cost
+		}
+	}
+	settled := query.dijkstraSeeds(seeds, -1, maxCost, false)
+
+	ans := make([]FacilityResult, 0, len(facilities))
+	for _, facility := range facilities {
+		result, ok := engine.reachFacility(query, facility, sourceEdges, settled, maxCost)
+		if ok {
+			ans = append(ans, result)
+		}
+	}
+	sort.SliceStable(ans, func(i, j int) bool {
+		return ans[i].Cost < ans[j].Cost
+	})
+	if len(ans) > k {
+		ans = ans[:k]
+	}
+	return ans, nil
+}
+
+// reachFacility Returns the cheapest route to the facility: directly along the source edge or from the start of the facility's edge (or its opposite edge)
+func (engine *MapEngine) reachFacility(query *routingQuery, facility *SnappedFacility, sourceEdges []sourceEdge, settled map[int64]dijkstraLabel, maxCost float64) (FacilityResult, bool) {
+	type approach struct {
+		edge     *spatial.Edge
+		fraction float64
+	}
+	approaches := []approach{{edge: facility.Edge, fraction: facility.Fraction}}
+	twin := engine.twinEdge(facility.Edge)
+	if twin != nil && twin.Polyline != nil {
+		_, fraction, _ := engine.calcProjection(*twin.Polyline, facility.ProjectedPoint)
+		approaches = append(approaches, approach{edge: twin, fraction: fraction})
+	}
+
+	best := FacilityResult{Facility: facility, Cost: math.Inf(1)}
+	for _, a := range approaches {
+		if a.edge.Polyline == nil || query.isExcluded(a.edge.ID) {
+			continue
+		}
+		weight, ok := query.profile.weight(a.edge)
+		if !ok {
+			continue
+		}
+		// Facility is ahead of the point on the same edge
+		for _, source := range sourceEdges {
+			if source.edge.ID != a.edge.ID || a.fraction < source.fraction {
+				continue
+			}
+			cost := weight * (a.fraction - source.fraction)
+			if cost < best.Cost {
+				best.Cost = cost
+				best.Route = engine.subPolyline(*a.edge.Polyline, source.fraction, a.fraction)
+				best.EdgeIDs = []int64{a.edge.ID}
+			}
+		}
+		// Facility is reached from the start of its edge
+		label, ok := settled[a.edge.Source]
+		if !ok {
+			continue
+		}
+		cost := label.cost + weight*a.fraction
+		if cost >= best.Cost {
+			continue
+		}
+		best.Cost = cost
+		best.Route, best.EdgeIDs = engine.facilityRoute(sourceEdges, settled, a.edge.Source)
+		best.Route = appendPolyline(best.Route, engine.subPolyline(*a.edge.Polyline, 0, a.fraction))
+		best.EdgeIDs = append(best.EdgeIDs, a.edge.ID)
+	}
+	if math.IsInf(best.Cost, 1) || best.Cost > maxCost {
+		return FacilityResult{}, false
+	}
+	for i := 1; i < len(best.Route); i++ {
+		best.Length += engine.distance(best.Route[i-1], best.Route[i])
+	}
+	return best, true
+}
+
+// facilityRoute Returns geometry and edges of the route from projection of the point up to the settled vertex
+func (engine *MapEngine) facilityRoute(sourceEdges []sourceEdge, settled map[int64]dijkstraLabel, vertex int64) (s2.Polyline, []int64) {
+	path := []int64{}
+	for v := vertex; v != -1; v = settled[v].prev {
+		path = append(path, v)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	route := s2.Polyline{}
+	edgeIDs := []int64{}
+	// The first vertex is a seed: it is the end of one of source edges
+	for _, source := range sourceEdges {
+		if source.edge.Target == path[0] {
+			route = engine.subPolyline(*source.edge.Polyline, source.fraction, 1)
+			edgeIDs = append(edgeIDs, source.edge.ID)
+			break
+		}
+	}
+	for i := 1; i < len(path); i++ {
+		// Label keeps the edge which the vertex has been reached by (there could be parallel edges)
+		edge := settled[path[i]].edge
+		route = appendPolyline(route, *edge.Polyline)
+		edgeIDs = append(edgeIDs, edge.ID)
+	}
+	return route, edgeIDs
+}
+
+// appendPolyline Appends points of the tail to the polyline skipping the shared point
+func appendPolyline(polyline s2.Polyline, tail s2.Polyline) s2.Polyline {
+	for _, pt := range tail {
+		if len(polyline) > 0 && polyline[len(polyline)-1] == pt {
+			continue
+		}
+		polyline = append(polyline, pt)
+	}
+	return polyline
+}
@@ -0,0 +1,119 @@
+package horizon
+
+import (
+	"math"
+	"testing"
+
+	"github.com/LdDl/horizon/spatial"
+)
+
+func TestNearestFacilities(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	eps := 1e-9
+	skipped, err := matcher.SetFacilities([]Facility{
+		{ID: 1, Name: "A", Point: spatial.NewEuclideanS2Point(12, 0.2)},
+		{ID: 2, Name: "B", Point: spatial.NewEuclideanS2Point(2, 0.1)},
+		{ID: 3, Name: "C", Point: spatial.NewEuclideanS2Point(-4, 0.1)},
+		{ID: 4, Name: "Far away", Point: spatial.NewEuclideanS2Point(100, 100)},
+	}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 1 || skipped[0] != 4 {
+		t.Errorf("Only facility 4 should be skipped, got %v", skipped)
+	}
+	if len(matcher.Facilities()) != 3 {
+		t.Errorf("Should be 3 facilities, got %d", len(matcher.Facilities()))
+	}
+
+	point := NewGPSMeasurementFromID(1, 1, 0.1, 0)
+	result, err := matcher.NearestFacilities(point, 2, -1, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("Should be 2 facilities, got %d", len(result))
+	}
+	// Facility B is ahead on the same edge
+	if result[0].Facility.ID != 2 || math.Abs(result[0].Cost-1) > eps {
+		t.Errorf("The nearest facility should be 2 with cost 1, got %d with cost %f", result[0].Facility.ID, result[0].Cost)
+	}
+	if len(result[0].EdgeIDs) != 1 || result[0].EdgeIDs[0] != 2 || len(result[0].Route) != 2 {
+		t.Errorf("Route to facility 2 should be along edge 2 only, got %v", result[0].EdgeIDs)
+	}
+	second := result[1]
+	if second.Facility.ID != 1 || math.Abs(second.Cost-11) > eps || math.Abs(second.Length-11) > eps {
+		t.Errorf("The second facility should be 1 with cost 11, got %d with cost %f and length %f", second.Facility.ID, second.Cost, second.Length)
+	}
+	expectedEdges := []int64{2, 3, 6}
+	if len(second.EdgeIDs) != len(expectedEdges) {
+		t.Fatalf("Route to facility 1 should pass edges %v, got %v", expectedEdges, second.EdgeIDs)
+	}
+	for i := range expectedEdges {
+		if second.EdgeIDs[i] != expectedEdges[i] {
+			t.Errorf("Route to facility 1 should pass edges %v, got %v", expectedEdges, second.EdgeIDs)
+			break
+		}
+	}
+	expectedX := []float64{1, 5, 10, 12}
+	if len(second.Route) != len(expectedX) {
+		t.Fatalf("Route to facility 1 should have %d points, got %d", len(expectedX), len(second.Route))
+	}
+	for i := range expectedX {
+		if math.Abs(second.Route[i].X-expectedX[i]) > eps || math.Abs(second.Route[i].Y) > eps {
+			t.Errorf("Point #%d of route should be (%f, 0), got (%f, %f)", i, expectedX[i], second.Route[i].X, second.Route[i].Y)
+		}
+	}
+
+	// Cost restriction
+	result, err = matcher.NearestFacilities(point, 3, 5, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0].Facility.ID != 2 {
+		t.Errorf("Only facility 2 should be found within cost 5")
+	}
+
+	// Facility C is behind the point on one-way road, facility B too
+	result, err = matcher.NearestFacilities(NewGPSMeasurementFromID(2, 7.5, 0.1, 0), 3, -1, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0].Facility.ID != 1 || math.Abs(result[0].Cost-4.5) > eps {
+		t.Errorf("Only facility 1 should be reachable with cost 4.5")
+	}
+
+	// Facilities on excluded edges are never returned
+	result, err = matcher.NearestFacilities(point, 3, -1, -1, WithExcludedEdges(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0].Facility.ID != 2 {
+		t.Errorf("Only facility 2 should be found when edge 6 is excluded")
+	}
+}
+
+func TestNearestFacilitiesTwoWay(t *testing.T) {
+	matcher := prepareGridMatcher(t, [][2]float64{{0, 0}}, 10, nil)
+	// Facility is reachable along any direction of two-way road regardless of the edge it has been snapped to
+	_, err := matcher.SetFacilities([]Facility{
+		{ID: 1, Point: spatial.NewEuclideanS2Point(5.5, 3.1)},
+		{ID: 2, Point: spatial.NewEuclideanS2Point(3.5, 3.1)},
+	}, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := matcher.NearestFacilities(NewGPSMeasurementFromID(1, 4.7, 3.1, 0), 2, -1, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("Should be 2 facilities, got %d", len(result))
+	}
+	if result[0].Facility.ID != 1 || math.Abs(result[0].Cost-0.8) > 1e-9 {
+		t.Errorf("The nearest facility should be 1 with cost 0.8, got %d with cost %f", result[0].Facility.ID, result[0].Cost)
+	}
+	if result[1].Facility.ID != 2 || math.Abs(result[1].Cost-1.2) > 1e-9 {
+		t.Errorf("The second facility should be 2 with cost 1.2, got %d with cost %f", result[1].Facility.ID, result[1].Cost)
+	}
+}
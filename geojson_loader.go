@@ -0,0 +1,487 @@
+package horizon
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	geojson "github.com/paulmach/go.geojson"
+	"github.com/pkg/errors"
+)
+
+// GeoJSONOptions Names of properties of GeoJSON features and parameters of loading road graph from GeoJSON
+/*
+	SourceProperty, TargetProperty - properties holding identifiers of source and target vertices of the edge.
+		When every feature has both of them, they are used as is. When there are no such properties in any feature, then vertices are derived by snapping endpoints of lines (see SnapTolerance)
+	WeightProperty - property holding weight of the edge. When it is absent, then length of geometry is used
+	OneWayProperty - property holding direction of the road: 'yes', 'true', '1' (one-way), '-1', 'reverse' (one-way against direction of geometry) or 'no', 'false', '0' (two-way).
+		When it is absent, then road is two-way
+	EdgeIDProperty - property holding identifier of the edge. When it is absent, then identifier of the feature is used or, if there is no one, index of the feature starting from 1
+	SnapTolerance - endpoints of lines which are closer than tolerance are snapped to the same vertex (meters, or units of coordinates for SRID = 0). The first and the last points of line are moved onto its vertex. Zero means exact match of coordinates
+*/
+type GeoJSONOptions struct {
+	SourceProperty string
+	TargetProperty string
+	WeightProperty string
+	OneWayProperty string
+	EdgeIDProperty string
+	SnapTolerance  float64
+}
+
+// DefaultGeoJSONOptions Returns default names of properties: 'source', 'target', 'weight', 'oneway' and 'id'. Endpoints are snapped by exact match
+func DefaultGeoJSONOptions() GeoJSONOptions {
+	return GeoJSONOptions{
+		SourceProperty: "source",
+		TargetProperty: "target",
+		WeightProperty: "weight",
+		OneWayProperty: "oneway",
+		EdgeIDProperty: "id",
+	}
+}
+
+// NewMapMatcherFromGeoJSON Returns pointer to created MapMatcher with road graph loaded from GeoJSON file (see NewMapEngineFromGeoJSON)
+func NewMapMatcherFromGeoJSON(props *HmmProbabilities, filename string, options GeoJSONOptions, engineOpts ...func(*MapEngine)) (*MapMatcher, error) {
+	st := time.Now()
+	engine, err := NewMapEngineFromGeoJSON(filename, options, engineOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewMapMatcher(WithHmmParameters(props), WithMapEngine(engine), WithGraphSource(filename, time.Since(st))), nil
+}
+
+// NewMapEngineFromGeoJSON Returns pointer to MapEngine with road graph loaded from GeoJSON FeatureCollection of LineStrings
+/*
+	filename - path to GeoJSON file
+	options - names of properties and snapping tolerance (see GeoJSONOptions)
+	engineOpts - options of the engine, e.g. WithGraphSRID for coordinates in projected CRS or WithWeightProfiles for numeric properties to be loaded as weight profiles
+
+	Two-way road produces two edges: the second one goes against direction of geometry and gets identifier after the maximum one among features.
+	Properties which are not listed in options (and are not weight profiles) are kept as edge attributes.
+	Contraction hierarchies and connected components are prepared via MapEngineBuilder
+*/
+func NewMapEngineFromGeoJSON(filename string, options GeoJSONOptions, engineOpts ...func(*MapEngine)) (*MapEngine, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't read GeoJSON file '%s'", filename)
+	}
+	collection, err := geojson.UnmarshalFeatureCollection(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't parse GeoJSON file '%s'", filename)
+	}
+	builder, err := geoJSONBuilder(collection, options, engineOpts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't prepare graph from GeoJSON file '%s'", filename)
+	}
+	engine, err := builder.Build()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't build graph from GeoJSON file '%s'", filename)
+	}
+	return engine, nil
+}
+
+// geoJSONEdge Line of GeoJSON feature with its parsed properties
+type geoJSONEdge struct {
+	id         int64
+	source     int64
+	target     int64
+	weight     float64
+	direction  int
+	polyline   s2.Polyline
+	length     float64
+	profiles   map[string]float64
+	attributes spatial.EdgeAttributes
+}
+
+// geoJSONBuilder Returns MapEngineBuilder filled with edges and vertices of the collection
+func geoJSONBuilder(collection *geojson.FeatureCollection, options GeoJSONOptions, engineOpts ...func(*MapEngine)) (*MapEngineBuilder, error) {
+	// Engine is used for SRID, distance model and names of profiles only
+	settings := newMapEngine(engineOpts...)
+	if !spatial.IsSRIDSupported(settings.graphSRID) {
+		return nil, errors.Wrapf(spatial.ErrUnknownSRID, "Can't load graph with SRID %d", settings.graphSRID)
+	}
+	known := map[string]bool{
+		options.SourceProperty: true,
+		options.TargetProperty: true,
+		options.WeightProperty: true,
+		options.OneWayProperty: true,
+		options.EdgeIDProperty: true,
+	}
+	for _, name := range settings.profileColumns {
+		known[name] = true
+	}
+
+	edges := make([]geoJSONEdge, 0, len(collection.Features))
+	withVertices := 0
+	maxID := int64(0)
+	for i, feature := range collection.Features {
+		edge, hasVertices, err := parseGeoJSONFeature(feature, i, options, settings, known)
+		if err != nil {
+			return nil, errors.Wrapf(err, "feature #%d", i)
+		}
+		if hasVertices {
+			withVertices++
+		}
+		if edge.id > maxID {
+			maxID = edge.id
+		}
+		edges = append(edges, edge)
+	}
+	if withVertices != 0 && withVertices != len(edges) {
+		return nil, errors.Wrapf(ErrGeoJSONFormat, "properties '%s' and '%s' are set for %d of %d features: they should be set for every feature or for none of them", options.SourceProperty, options.TargetProperty, withVertices, len(edges))
+	}
+
+	vertices := make(map[int64]s2.Point)
+	if withVertices == 0 {
+		snapper := newVertexSnapper(settings.graphSRID == 0, options.SnapTolerance)
+		for i := range edges {
+			last := len(edges[i].polyline) - 1
+			edges[i].source = snapper.snap(edges[i].polyline[0])
+			edges[i].target = snapper.snap(edges[i].polyline[last])
+			// Endpoints of geometry are moved onto the vertices they are snapped to
+			edges[i].polyline[0] = snapper.points[edges[i].source]
+			edges[i].polyline[last] = snapper.points[edges[i].target]
+			edges[i].updateLength(settings.edgeLength(&spatial.Edge{Polyline: &edges[i].polyline}))
+		}
+		vertices = snapper.points
+	} else {
+		for _, edge := range edges {
+			if _, ok := vertices[edge.source]; !ok {
+				vertices[edge.source] = edge.polyline[0]
+			}
+			if _, ok := vertices[edge.target]; !ok {
+				vertices[edge.target] = edge.polyline[len(edge.polyline)-1]
+			}
+		}
+	}
+
+	builder := NewMapEngineBuilder(engineOpts...)
+	for vertexID, point := range vertices {
+		point := point
+		builder.AddVertices(&spatial.Vertex{ID: vertexID, Point: &point})
+	}
+	profiles := make(map[string]map[int64]float64, len(settings.profileColumns))
+	for _, name := range settings.profileColumns {
+		profiles[name] = make(map[int64]float64)
+	}
+	attributes := make(map[int64]spatial.EdgeAttributes)
+	nextID := maxID + 1
+	for _, edge := range edges {
+		ids := []int64{}
+		if edge.direction >= 0 {
+			polyline := edge.polyline
+			builder.AddEdges(&spatial.Edge{ID: edge.id, Source: edge.source, Target: edge.target, Weight: edge.weight, Polyline: &polyline})
+			ids = append(ids, edge.id)
+		}
+		if edge.direction <= 0 {
+			reverseID := edge.id
+			if edge.direction == 0 {
+				reverseID = nextID
+				nextID++
+			}
+			polyline := make(s2.Polyline, len(edge.polyline))
+			for i := range edge.polyline {
+				polyline[i] = edge.polyline[len(edge.polyline)-1-i]
+			}
+			builder.AddEdges(&spatial.Edge{ID: reverseID, Source: edge.target, Target: edge.source, Weight: edge.weight, Polyline: &polyline})
+			ids = append(ids, reverseID)
+		}
+		for _, edgeID := range ids {
+			for name, weight := range edge.profiles {
+				profiles[name][edgeID] = weight
+			}
+			if edge.attributes != nil {
+				attributes[edgeID] = edge.attributes
+			}
+		}
+	}
+	for _, name := range settings.profileColumns {
+		builder.AddWeightProfile(name, profiles[name])
+	}
+	builder.AddEdgeAttributes(attributes)
+	return builder, nil
+}
+
+// parseGeoJSONFeature Returns edge described by the feature and whether the feature has identifiers of vertices
+/*
+	idx - index of the feature in collection
+	settings - engine with SRID, distance model and names of profiles
+	known - names of properties which are not attributes
+*/
+func parseGeoJSONFeature(feature *geojson.Feature, idx int, options GeoJSONOptions, settings *MapEngine, known map[string]bool) (geoJSONEdge, bool, error) {
+	edge := geoJSONEdge{id: int64(idx + 1)}
+	if feature.Geometry == nil {
+		return edge, false, errors.Wrap(ErrGeoJSONFormat, "there is no geometry")
+	}
+	var coordinates [][]float64
+	switch {
+	case feature.Geometry.IsLineString():
+		coordinates = feature.Geometry.LineString
+	case feature.Geometry.IsMultiLineString() && len(feature.Geometry.MultiLineString) == 1:
+		coordinates = feature.Geometry.MultiLineString[0]
+	default:
+		return edge, false, errors.Wrapf(ErrGeoJSONFormat, "geometry should be LineString (or MultiLineString with single line), but got '%s'", feature.Geometry.Type)
+	}
+	if len(coordinates) < 2 {
+		return edge, false, errors.Wrap(ErrGeoJSONFormat, "line should have at least 2 points")
+	}
+	edge.polyline = make(s2.Polyline, 0, len(coordinates))
+	for _, coordinate := range coordinates {
+		if len(coordinate) < 2 {
+			return edge, false, errors.Wrap(ErrGeoJSONFormat, "point should have at least 2 coordinates")
+		}
+		point, err := spatial.S2PointFromSRID(coordinate[0], coordinate[1], settings.graphSRID)
+		if err != nil {
+			return edge, false, err
+		}
+		edge.polyline = append(edge.polyline, point)
+	}
+
+	properties := feature.Properties
+	if value, ok := properties[options.EdgeIDProperty]; ok {
+		edgeID, ok := geoJSONInt(value)
+		if !ok {
+			return edge, false, errors.Wrapf(ErrGeoJSONFormat, "can't parse edge identifier '%v'", value)
+		}
+		edge.id = edgeID
+	} else if feature.ID != nil {
+		edgeID, ok := geoJSONInt(feature.ID)
+		if !ok {
+			return edge, false, errors.Wrapf(ErrGeoJSONFormat, "can't parse identifier of feature '%v'", feature.ID)
+		}
+		edge.id = edgeID
+	}
+
+	sourceValue, hasSource := properties[options.SourceProperty]
+	targetValue, hasTarget := properties[options.TargetProperty]
+	if hasSource != hasTarget {
+		return edge, false, errors.Wrapf(ErrGeoJSONFormat, "properties '%s' and '%s' should be set both", options.SourceProperty, options.TargetProperty)
+	}
+	if hasSource {
+		var ok bool
+		if edge.source, ok = geoJSONInt(sourceValue); !ok {
+			return edge, false, errors.Wrapf(ErrGeoJSONFormat, "can't parse source vertex '%v'", sourceValue)
+		}
+		if edge.target, ok = geoJSONInt(targetValue); !ok {
+			return edge, false, errors.Wrapf(ErrGeoJSONFormat, "can't parse target vertex '%v'", targetValue)
+		}
+	}
+
+	polyline := edge.polyline
+	length := settings.edgeLength(&spatial.Edge{Polyline: &polyline})
+	edge.length = length
+	edge.weight = length
+	if value, ok := properties[options.WeightProperty]; ok {
+		if edge.weight, ok = geoJSONFloat(value); !ok {
+			return edge, false, errors.Wrapf(ErrGeoJSONFormat, "can't parse weight '%v'", value)
+		}
+	}
+	if value, ok := properties[options.OneWayProperty]; ok {
+		if edge.direction, ok = geoJSONDirection(value); !ok {
+			return edge, false, errors.Wrapf(ErrGeoJSONFormat, "can't parse one-way flag '%v'", value)
+		}
+	}
+
+	edge.profiles = make(map[string]float64, len(settings.profileColumns))
+	for _, name := range settings.profileColumns {
+		value, ok := properties[name]
+		if !ok || value == nil || value == "" {
+			if name == LENGTH_PROFILE {
+				edge.profiles[name] = length
+			}
+			// Otherwise the edge is not traversable for the profile
+			continue
+		}
+		weight, ok := geoJSONFloat(value)
+		if !ok {
+			return edge, false, errors.Wrapf(ErrGeoJSONFormat, "can't parse weight '%v' for profile '%s'", value, name)
+		}
+		edge.profiles[name] = weight
+	}
+
+	for name, value := range properties {
+		if known[name] || value == nil {
+			continue
+		}
+		if edge.attributes == nil {
+			edge.attributes = make(spatial.EdgeAttributes)
+		}
+		edge.attributes[name] = geoJSONAttribute(value)
+	}
+	return edge, hasSource, nil
+}
+
+// updateLength Replaces length of geometry with the new one in weight and 'length' profile when they are derived from geometry
+func (edge *geoJSONEdge) updateLength(length float64) {
+	if edge.weight == edge.length {
+		edge.weight = length
+	}
+	if weight, ok := edge.profiles[LENGTH_PROFILE]; ok && weight == edge.length {
+		edge.profiles[LENGTH_PROFILE] = length
+	}
+	edge.length = length
+}
+
+// geoJSONInt Returns integer value of JSON number or string
+func geoJSONInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false
+		}
+		return int64(v), true
+	case string:
+		ans, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return ans, err == nil
+	}
+	return 0, false
+}
+
+// geoJSONFloat Returns float value of JSON number or string
+func geoJSONFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		ans, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return ans, err == nil
+	}
+	return 0, false
+}
+
+// geoJSONDirection Returns direction of the road: 1 - one-way along geometry, -1 - one-way against geometry, 0 - two-way
+func geoJSONDirection(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case nil:
+		return 0, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case float64:
+		switch v {
+		case 1:
+			return 1, true
+		case -1:
+			return -1, true
+		case 0:
+			return 0, true
+		}
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "yes", "true", "1":
+			return 1, true
+		case "-1", "reverse":
+			return -1, true
+		case "no", "false", "0", "":
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+// geoJSONAttribute Returns value of property as edge attribute: integral numbers become int64, arrays and objects are kept as JSON strings
+func geoJSONAttribute(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+		return v
+	case bool, string:
+		return v
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// vertexSnapper Assigns identifiers to endpoints of lines. Points which are closer than tolerance get the same identifier
+/*
+	euclidean - true if points hold raw Cartesian coordinates (see spatial.NewEuclideanS2Point)
+	tolerance - max distance between snapped points (meters for points on sphere)
+	points - vertex ID to its point (the first point snapped to the vertex)
+*/
+type vertexSnapper struct {
+	euclidean bool
+	tolerance float64
+	level     int
+	exact     map[s2.Point]int64
+	cells     map[s2.CellID][]int64
+	grid      map[[2]int64][]int64
+	points    map[int64]s2.Point
+}
+
+// newVertexSnapper Returns pointer to created vertexSnapper
+func newVertexSnapper(euclidean bool, tolerance float64) *vertexSnapper {
+	snapper := &vertexSnapper{
+		euclidean: euclidean,
+		tolerance: tolerance,
+		exact:     make(map[s2.Point]int64),
+		cells:     make(map[s2.CellID][]int64),
+		grid:      make(map[[2]int64][]int64),
+		points:    make(map[int64]s2.Point),
+	}
+	if !euclidean && tolerance > 0 {
+		// The deepest level which cells are not narrower than tolerance: neighbors of the cell cover every point within tolerance
+		snapper.level = s2.MinWidthMetric.MaxLevel(tolerance / spatial.EarthRadius)
+	}
+	return snapper
+}
+
+// snap Returns identifier of the vertex for the point. Vertices are numbered from 1 in order of appearance
+func (snapper *vertexSnapper) snap(pt s2.Point) int64 {
+	if snapper.tolerance <= 0 {
+		if vertexID, ok := snapper.exact[pt]; ok {
+			return vertexID
+		}
+		vertexID := snapper.add(pt)
+		snapper.exact[pt] = vertexID
+		return vertexID
+	}
+	if snapper.euclidean {
+		x := int64(math.Floor(pt.X / snapper.tolerance))
+		y := int64(math.Floor(pt.Y / snapper.tolerance))
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for _, vertexID := range snapper.grid[[2]int64{x + dx, y + dy}] {
+					other := snapper.points[vertexID]
+					if math.Hypot(pt.X-other.X, pt.Y-other.Y) <= snapper.tolerance {
+						return vertexID
+					}
+				}
+			}
+		}
+		vertexID := snapper.add(pt)
+		snapper.grid[[2]int64{x, y}] = append(snapper.grid[[2]int64{x, y}], vertexID)
+		return vertexID
+	}
+	cell := s2.CellIDFromLatLng(s2.LatLngFromPoint(pt)).Parent(snapper.level)
+	maxAngle := s1.Angle(snapper.tolerance / spatial.EarthRadius)
+	for _, neighbor := range append(cell.AllNeighbors(snapper.level), cell) {
+		for _, vertexID := range snapper.cells[neighbor] {
+			if pt.Distance(snapper.points[vertexID]) <= maxAngle {
+				return vertexID
+			}
+		}
+	}
+	vertexID := snapper.add(pt)
+	snapper.cells[cell] = append(snapper.cells[cell], vertexID)
+	return vertexID
+}
+
+// add Registers new vertex for the point
+func (snapper *vertexSnapper) add(pt s2.Point) int64 {
+	vertexID := int64(len(snapper.points) + 1)
+	snapper.points[vertexID] = pt
+	return vertexID
+}
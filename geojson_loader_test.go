@@ -0,0 +1,152 @@
+package horizon
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+func writeTestGeoJSON(t *testing.T, content string) string {
+	filename := filepath.Join(t.TempDir(), "roads.geojson")
+	err := os.WriteFile(filename, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestGeoJSONSnapping(t *testing.T) {
+	// Endpoints of the second line are shifted a bit from the ones of the first and the third lines
+	filename := writeTestGeoJSON(t, `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "id": 10, "properties": {"highway": "primary", "lanes": 2}, "geometry": {"type": "LineString", "coordinates": [[0, 0], [5, 0]]}},
+		{"type": "Feature", "id": 20, "properties": {"highway": "primary", "oneway": "yes"}, "geometry": {"type": "LineString", "coordinates": [[5.05, 0], [10, 0.05]]}},
+		{"type": "Feature", "id": 30, "properties": {"highway": "service", "oneway": -1}, "geometry": {"type": "MultiLineString", "coordinates": [[[15, 0], [10, 0]]]}}
+	]}`)
+	engine, err := NewMapEngineFromGeoJSON(filename, GeoJSONOptions{OneWayProperty: "oneway", SnapTolerance: 0.1}, WithGraphSRID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(engine.vertices) != 4 {
+		t.Errorf("Endpoints should be snapped to 4 vertices, but got %d", len(engine.vertices))
+	}
+	// Two-way road gets reverse edge with identifier after the maximum one, reversed one-way road keeps its identifier
+	expected := map[int64][2]int64{10: {1, 2}, 31: {2, 1}, 20: {2, 3}, 30: {3, 4}}
+	if len(engine.edges) != len(expected) {
+		t.Errorf("Expected %d edges, but got %d", len(expected), len(engine.edges))
+	}
+	for edgeID, vertices := range expected {
+		edge, ok := engine.edges[edgeID]
+		if !ok {
+			t.Errorf("Edge %d should exist", edgeID)
+			continue
+		}
+		if edge.Source != vertices[0] || edge.Target != vertices[1] {
+			t.Errorf("Edge %d should go from %d to %d, but got %d and %d", edgeID, vertices[0], vertices[1], edge.Source, edge.Target)
+		}
+	}
+	if engine.edges[31].Weight != 5 {
+		t.Errorf("Weight of edge 31 should be derived from geometry, but got %f", engine.edges[31].Weight)
+	}
+	// Geometry starts and ends at the vertices which endpoints are snapped to, so derived weight is evaluated for the snapped geometry
+	for edgeID, edge := range engine.edges {
+		polyline := *edge.Polyline
+		if polyline[0] != *engine.vertices[edge.Source].Point || polyline[len(polyline)-1] != *engine.vertices[edge.Target].Point {
+			t.Errorf("Geometry of edge %d should start at vertex %d and end at vertex %d", edgeID, edge.Source, edge.Target)
+		}
+	}
+	if weight := engine.edges[20].Weight; math.Abs(weight-math.Hypot(5, 0.05)) > 1e-9 {
+		t.Errorf("Weight of edge 20 should be %f, but got %f", math.Hypot(5, 0.05), weight)
+	}
+	for _, issue := range validateEngine(engine, DefaultValidationOptions()).Issues {
+		if issue.Kind == ISSUE_ENDPOINT_MISMATCH {
+			t.Errorf("Snapped graph should have no endpoint mismatches, but got %s", issue)
+		}
+	}
+	attributes, _ := engine.EdgeAttributes(31)
+	if lanes, ok := attributes.Int("lanes"); !ok || lanes != 2 {
+		t.Errorf("Reverse edge should have attributes of the feature, but got %v", attributes)
+	}
+	if _, ok := attributes.Get("oneway"); ok {
+		t.Errorf("One-way property should not be an attribute")
+	}
+
+	// Only the first road is two-way
+	components := engine.vertexStrongComponent
+	if components[1] != components[2] || components[2] == components[3] || components[3] == components[4] {
+		t.Errorf("Only vertices 1 and 2 should be in the same strong component, but got %v", components)
+	}
+}
+
+func TestGeoJSONProperties(t *testing.T) {
+	filename := writeTestGeoJSON(t, `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "properties": {"fid": 1, "u": 101, "v": 102, "cost": 42.5, "dir": true, "travel_time": 3}, "geometry": {"type": "LineString", "coordinates": [[37.6630, 55.7730], [37.6631, 55.7740]]}},
+		{"type": "Feature", "properties": {"fid": "2", "u": "102", "v": "103", "cost": "30", "dir": "no"}, "geometry": {"type": "LineString", "coordinates": [[37.6631, 55.7740], [37.6640, 55.7741]]}}
+	]}`)
+	options := GeoJSONOptions{SourceProperty: "u", TargetProperty: "v", WeightProperty: "cost", OneWayProperty: "dir", EdgeIDProperty: "fid"}
+	engine, err := NewMapEngineFromGeoJSON(filename, options, WithWeightProfiles("travel_time", LENGTH_PROFILE))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(engine.edges) != 3 || len(engine.vertices) != 3 {
+		t.Fatalf("Expected 3 edges and 3 vertices, but got %d and %d", len(engine.edges), len(engine.vertices))
+	}
+	if edge := engine.edges[1]; edge.Source != 101 || edge.Target != 102 || edge.Weight != 42.5 {
+		t.Errorf("Unexpected edge 1: %+v", edge)
+	}
+	if edge := engine.edges[3]; edge.Source != 103 || edge.Target != 102 || edge.Weight != 30 {
+		t.Errorf("Unexpected reverse edge 3: %+v", edge)
+	}
+	if len(engine.attributes) != 0 {
+		t.Errorf("There should be no attributes, but got %v", engine.attributes)
+	}
+	travelTime := engine.profiles["travel_time"]
+	if weight, ok := travelTime.weight(engine.edges[1]); !ok || weight != 3 {
+		t.Errorf("Edge 1 should have weight 3 for profile 'travel_time', but got %f", weight)
+	}
+	if _, ok := travelTime.weight(engine.edges[2]); ok {
+		t.Errorf("Edge 2 should not be traversable for profile 'travel_time'")
+	}
+	if weight, ok := engine.profiles[LENGTH_PROFILE].weight(engine.edges[2]); !ok || weight < 50 || weight > 70 {
+		t.Errorf("Edge 2 should have length about 60 meters, but got %f", weight)
+	}
+}
+
+func TestGeoJSONErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+	}{
+		{"polygon", `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {}, "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}}]}`},
+		{"single point", `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {}, "geometry": {"type": "LineString", "coordinates": [[0, 0]]}}]}`},
+		{"partial vertices", `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "properties": {"source": 1, "target": 2}, "geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 0]]}},
+			{"type": "Feature", "properties": {}, "geometry": {"type": "LineString", "coordinates": [[1, 0], [2, 0]]}}
+		]}`},
+		{"bad one-way flag", `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {"oneway": "sometimes"}, "geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 0]]}}]}`},
+	}
+	for _, c := range cases {
+		_, err := NewMapEngineFromGeoJSON(writeTestGeoJSON(t, c.content), DefaultGeoJSONOptions(), WithGraphSRID(0))
+		if errors.Cause(err) != ErrGeoJSONFormat {
+			t.Errorf("Case '%s': expected error '%v', but got '%v'", c.name, ErrGeoJSONFormat, err)
+		}
+	}
+}
+
+func TestVertexSnapperSpherical(t *testing.T) {
+	snapper := newVertexSnapper(false, 1.0)
+	first := snapper.snap(s2.PointFromLatLng(s2.LatLngFromDegrees(55.7730, 37.6630)))
+	// About 0.6 meters to the north
+	near := snapper.snap(s2.PointFromLatLng(s2.LatLngFromDegrees(55.773005, 37.6630)))
+	// About 6 meters to the east
+	far := snapper.snap(s2.PointFromLatLng(s2.LatLngFromDegrees(55.7730, 37.6631)))
+	if first != near {
+		t.Errorf("Points closer than 1 meter should be snapped to the same vertex, but got %d and %d", first, near)
+	}
+	if first == far {
+		t.Errorf("Points farther than 1 meter should be snapped to different vertices")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
 )
 
 // GPSMeasurements Set of telematic data
@@ -46,21 +48,15 @@ type GPSTrack []*GPSMeasurement
 	id - unique identifier
 	lon - longitude (X for SRID = 0)
 	lat - latitude (Y for SRID = 0)
-	srid - SRID (see https://en.wikipedia.org/wiki/Spatial_reference_system), if not provided then SRID(4326) is used. 0 and 4326 are supported.
+	srid - SRID (see https://en.wikipedia.org/wiki/Spatial_reference_system), if not provided then SRID(4326) is used. 0, 4326 and projected CRS (see spatial.ProjectionBySRID) are supported.
+	Measurement with unknown SRID has no valid coordinates: MapMatcher methods reject it with spatial.ErrUnknownSRID
 */
 func NewGPSMeasurement(id int, lon, lat float64, srid int, options ...func(*GPSMeasurement)) *GPSMeasurement {
 	gps := &GPSMeasurement{
 		dateTime: time.Now(),
 		id:       id,
 	}
-	switch srid {
-	case 0:
-		gps.GeoPoint = spatial.NewEuclideanPoint(lon, lat)
-	case 4326:
-		gps.GeoPoint = spatial.NewWGS84Point(lon, lat)
-	default:
-		gps.GeoPoint = spatial.NewWGS84Point(lon, lat)
-	}
+	gps.GeoPoint = newGeoPointSRID(lon, lat, srid)
 	for _, o := range options {
 		o(gps)
 	}
@@ -87,7 +83,8 @@ func WithGPSAccuracy(accuracy float64) func(*GPSMeasurement) {
 	id - unique identifier (will be converted to time.Time also)
 	lon - longitude (X for SRID = 0)
 	lat - latitude (Y for SRID = 0)
-	srid - SRID (see https://en.wikipedia.org/wiki/Spatial_reference_system), if not provided then SRID(4326) is used. 0 and 4326 are supported.
+	srid - SRID (see https://en.wikipedia.org/wiki/Spatial_reference_system), if not provided then SRID(4326) is used. 0, 4326 and projected CRS (see spatial.ProjectionBySRID) are supported.
+	Measurement with unknown SRID has no valid coordinates: MapMatcher methods reject it with spatial.ErrUnknownSRID
 */
 func NewGPSMeasurementFromID(id int, lon, lat float64, srid ...int) *GPSMeasurement {
 	dateTime := time.Now().Add(time.Duration(id) * time.Second)
@@ -96,14 +93,32 @@ func NewGPSMeasurementFromID(id int, lon, lat float64, srid ...int) *GPSMeasurem
 		id:       int(dateTime.Unix()),
 	}
 	if len(srid) != 0 {
-		switch srid[0] {
-		case 0:
-			gps.GeoPoint = spatial.NewEuclideanPoint(lon, lat)
-		case 4326:
-			gps.GeoPoint = spatial.NewWGS84Point(lon, lat)
-		default:
-			gps.GeoPoint = spatial.NewWGS84Point(lon, lat)
-		}
+		gps.GeoPoint = newGeoPointSRID(lon, lat, srid[0])
 	}
 	return &gps
 }
+
+// newGeoPointSRID Returns pointer to created GeoPoint for coordinates in the SRID
+/*
+	Unknown SRID gives point without valid coordinates which keeps the SRID, so it could be rejected later (see checkMeasurementsSRID)
+*/
+func newGeoPointSRID(lon, lat float64, srid int) *spatial.GeoPoint {
+	gp, err := spatial.NewGeoPoint(lon, lat, srid)
+	if err != nil {
+		return spatial.NewGeoPointFromS2(s2.Point{}, srid)
+	}
+	return gp
+}
+
+// checkMeasurementsSRID Returns spatial.ErrUnknownSRID if any of measurements has unknown SRID
+func checkMeasurementsSRID(gpsMeasurements ...*GPSMeasurement) error {
+	for _, gps := range gpsMeasurements {
+		if gps == nil || gps.GeoPoint == nil {
+			continue
+		}
+		if !spatial.IsSRIDSupported(gps.SRID()) {
+			return errors.Wrapf(spatial.ErrUnknownSRID, "SRID %d of GPS measurement %d is not supported", gps.SRID(), gps.ID())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package horizon
+
+import (
+	"math"
+	"sort"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// IsochroneEdge Reachable part of the edge
+/*
+	Edge - reached edge
+	Geom - geometry of the reachable part (in direction of the edge)
+	FromFraction - number in [0;1], describes where the reachable part starts on the edge
+	ToFraction - number in [FromFraction;1], describes where the reachable part ends on the edge
+	FromCost - travel cost at the start of the reachable part (cost of reaching the source point for reverse requests)
+	ToCost - travel cost at the end of the reachable part (cost of reaching the source point for reverse requests)
+	Length - length of the reachable part (meters for WGS84 graphs)
+	Partial - true if only part of the edge is reachable within max cost
+*/
+type IsochroneEdge struct {
+	Edge         *spatial.Edge
+	Geom         s2.Polyline
+	FromFraction float64
+	ToFraction   float64
+	FromCost     float64
+	ToCost       float64
+	Length       float64
+	Partial      bool
+}
+
+// sourceEdge Edge which the source point is projected onto
+/*
+	edge - the edge
+	weight - travel cost of the whole edge for the request's weight profile
+	fraction - number in [0;1], describes how far projected point from the first point of edge
+*/
+type sourceEdge struct {
+	edge     *spatial.Edge
+	weight   float64
+	fraction float64
+}
+
+// projectSource Returns edges which the point is projected onto: the nearest edge and the opposite edge of two-way road (if it is traversable)
+func (engine *MapEngine) projectSource(query *routingQuery, pt s2.Point, maxNearestRadius float64) ([]sourceEdge, error) {
+	nearest, err := engine.nearest(query, pt, 1, maxNearestRadius)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't find nearest edge for source point %v", pt)
+	}
+	if len(nearest) == 0 {
+		return nil, ErrSourceNotFound
+	}
+	sourceEdges := []sourceEdge{{edge: nearest[0].Edge, weight: nearest[0].Weight, fraction: nearest[0].Fraction}}
+	twin := engine.twinEdge(nearest[0].Edge)
+	if twin != nil && twin.Polyline != nil && !query.isExcluded(twin.ID) {
+		if weight, ok := query.profile.weight(twin); ok {
+			_, fraction, _ := engine.calcProjection(*twin.Polyline, nearest[0].ProjectedPoint)
+			sourceEdges = append(sourceEdges, sourceEdge{edge: twin, weight: weight, fraction: fraction})
+		}
+	}
+	return sourceEdges, nil
+}
+
+// FindIsochroneEdges Returns reachable parts of edges: every fully reachable edge and reachable part of every boundary edge cut at max cost
+/*
+	source - source for outcoming isochrones
+	maxCost - max cost restriction
+	maxNearestRadius - max radius of search for nearest edge
+	opts - per-request options (see QueryOptions). Excluded edges are neither used for snapping nor traversed. Use WithReverse for costs of reaching the source instead
+
+	Unlike FindIsochrones the search starts from the projection of the source point onto the nearest edge (and onto the opposite edge of two-way road),
+	so the edge holding the source point is reachable partially. Cost along the edge is proportional to length of its geometry.
+	Result is sorted by edge identifiers. The same edge could occur twice only if its reachable parts do not overlap
+*/
+func (matcher *MapMatcher) FindIsochroneEdges(source *GPSMeasurement, maxCost float64, maxNearestRadius float64, opts ...QueryOption) ([]IsochroneEdge, error) {
+	engine := matcher.Engine()
+	err := checkMeasurementsSRID(source)
+	if err != nil {
+		return nil, err
+	}
+	query, err := engine.prepareQuery(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't prepare query")
+	}
+	sourceEdges, err := engine.projectSource(query, source.Point, maxNearestRadius)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make(map[int64][]IsochroneEdge)
+	addPart := func(edge *spatial.Edge, from, to, fromCost, toCost float64) {
+		parts[edge.ID] = append(parts[edge.ID], IsochroneEdge{
+			Edge:         edge,
+			FromFraction: from,
+			ToFraction:   to,
+			FromCost:     fromCost,
+			ToCost:       toCost,
+		})
+	}
+	// reachableFraction Returns fraction of the edge which could be passed with the rest of budget
+	reachableFraction := func(weight, cost float64) float64 {
+		if weight <= 0 {
+			return 1
+		}
+		return math.Min(1, (maxCost-cost)/weight)
+	}
+
+	// Source point splits its edges: the search starts from the ends of the parts
+	seeds := make(map[int64]float64, len(sourceEdges))
+	addSeed := func(vertex int64, cost float64) {
+		if existing, ok := seeds[vertex]; !ok || cost < existing {
+			seeds[vertex] = cost
+		}
+	}
+	for _, sourceEdge := range sourceEdges {
+		reach := reachableFraction(sourceEdge.weight, 0)
+		if !query.reverse {
+			addSeed(sourceEdge.edge.Target, sourceEdge.weight*(1-sourceEdge.fraction))
+			to := math.Min(1, sourceEdge.fraction+reach)
+			addPart(sourceEdge.edge, sourceEdge.fraction, to, 0, sourceEdge.weight*(to-sourceEdge.fraction))
+		} else {
+			addSeed(sourceEdge.edge.Source, sourceEdge.weight*sourceEdge.fraction)
+			from := math.Max(0, sourceEdge.fraction-reach)
+			addPart(sourceEdge.edge, from, sourceEdge.fraction, sourceEdge.weight*(sourceEdge.fraction-from), 0)
+		}
+	}
+
+	settled := query.dijkstraSeeds(seeds, -1, maxCost, query.reverse)
+	for vertex, label := range settled {
+		for _, edge := range query.adjacentEdges(vertex) {
+			if edge.Polyline == nil || query.isExcluded(edge.ID) {
+				continue
+			}
+			weight, ok := query.profile.weight(edge)
+			if !ok {
+				continue
+			}
+			reach := reachableFraction(weight, label.cost)
+			if reach <= 0 {
+				continue
+			}
+			if !query.reverse {
+				addPart(edge, 0, reach, label.cost, label.cost+weight*reach)
+			} else {
+				addPart(edge, 1-reach, 1, label.cost+weight*reach, label.cost)
+			}
+		}
+	}
+
+	edgeIDs := make([]int64, 0, len(parts))
+	for edgeID := range parts {
+		edgeIDs = append(edgeIDs, edgeID)
+	}
+	sort.Slice(edgeIDs, func(i, j int) bool {
+		return edgeIDs[i] < edgeIDs[j]
+	})
+	ans := make([]IsochroneEdge, 0, len(edgeIDs))
+	for _, edgeID := range edgeIDs {
+		for _, part := range mergeIsochroneEdgeParts(parts[edgeID]) {
+			part.Geom = engine.subPolyline(*part.Edge.Polyline, part.FromFraction, part.ToFraction)
+			part.Length = engine.edgeLength(part.Edge) * (part.ToFraction - part.FromFraction)
+			part.Partial = part.FromFraction > 0 || part.ToFraction < 1
+			ans = append(ans, part)
+		}
+	}
+	return ans, nil
+}
+
+// mergeIsochroneEdgeParts Merges overlapping reachable parts of the same edge (e.g. the edge holding the source point is reachable from its start too)
+func mergeIsochroneEdgeParts(parts []IsochroneEdge) []IsochroneEdge {
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].FromFraction < parts[j].FromFraction
+	})
+	merged := parts[:1]
+	for _, part := range parts[1:] {
+		last := &merged[len(merged)-1]
+		if part.FromFraction > last.ToFraction {
+			merged = append(merged, part)
+			continue
+		}
+		if part.ToFraction > last.ToFraction {
+			last.ToFraction = part.ToFraction
+			last.ToCost = part.ToCost
+		}
+	}
+	return merged
+}
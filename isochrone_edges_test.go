@@ -0,0 +1,101 @@
+package horizon
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFindIsochroneEdges(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	eps := 1e-9
+	// Projection is in the middle of edge 2->3
+	source := NewGPSMeasurementFromID(1, 7.5, 0.1, 0)
+
+	type expectedPart struct {
+		edgeID           int64
+		from, to         float64
+		fromCost, toCost float64
+		startX, endX     float64
+	}
+	check := func(parts []IsochroneEdge, expected []expectedPart) {
+		t.Helper()
+		if len(parts) != len(expected) {
+			t.Fatalf("Should be %d parts, got %d", len(expected), len(parts))
+		}
+		for i := range expected {
+			part := parts[i]
+			if part.Edge.ID != expected[i].edgeID {
+				t.Errorf("Part #%d should belong to edge %d, got %d", i, expected[i].edgeID, part.Edge.ID)
+				continue
+			}
+			if math.Abs(part.FromFraction-expected[i].from) > eps || math.Abs(part.ToFraction-expected[i].to) > eps {
+				t.Errorf("Part #%d should be [%f; %f], got [%f; %f]", i, expected[i].from, expected[i].to, part.FromFraction, part.ToFraction)
+			}
+			if math.Abs(part.FromCost-expected[i].fromCost) > eps || math.Abs(part.ToCost-expected[i].toCost) > eps {
+				t.Errorf("Costs of part #%d should be %f and %f, got %f and %f", i, expected[i].fromCost, expected[i].toCost, part.FromCost, part.ToCost)
+			}
+			if math.Abs(part.Geom[0].X-expected[i].startX) > eps || math.Abs(part.Geom[len(part.Geom)-1].X-expected[i].endX) > eps {
+				t.Errorf("Geometry of part #%d should be from x=%f to x=%f, got from x=%f to x=%f", i, expected[i].startX, expected[i].endX, part.Geom[0].X, part.Geom[len(part.Geom)-1].X)
+			}
+			if math.Abs(part.Length-(part.ToFraction-part.FromFraction)*5) > eps {
+				t.Errorf("Length of part #%d is wrong: %f", i, part.Length)
+			}
+		}
+	}
+
+	parts, err := matcher.FindIsochroneEdges(source, 4, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The rest of edge 2->3 is fully passed, edge 3->5 is cut at 1.5 units from its start
+	check(parts, []expectedPart{
+		{edgeID: 3, from: 0.5, to: 1, fromCost: 0, toCost: 2.5, startX: 7.5, endX: 10},
+		{edgeID: 6, from: 0, to: 0.3, fromCost: 2.5, toCost: 4, startX: 10, endX: 11.5},
+	})
+	if !parts[0].Partial || !parts[1].Partial {
+		t.Errorf("Both parts should be partial")
+	}
+
+	parts, err = matcher.FindIsochroneEdges(source, 4, -1, WithReverse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(parts, []expectedPart{
+		{edgeID: 2, from: 0.7, to: 1, fromCost: 4, toCost: 2.5, startX: 3.5, endX: 5},
+		{edgeID: 3, from: 0, to: 0.5, fromCost: 2.5, toCost: 0, startX: 5, endX: 7.5},
+	})
+
+	// Fully reachable edges
+	parts, err = matcher.FindIsochroneEdges(source, 100, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 2 || parts[1].Partial {
+		t.Errorf("Edge 3->5 should be fully reachable")
+	}
+}
+
+func TestFindIsochroneEdgesTwoWay(t *testing.T) {
+	matcher := prepareGridMatcher(t, [][2]float64{{0, 0}}, 10, nil)
+	// Both directions of road (5,3)-(6,3) are reachable from the middle
+	source := NewGPSMeasurementFromID(1, 5.5, 3.05, 0)
+	parts, err := matcher.FindIsochroneEdges(source, 0.2, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("Should be 2 parts, got %d", len(parts))
+	}
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for _, part := range parts {
+		if math.Abs(part.Length-0.2) > 1e-9 || !part.Partial {
+			t.Errorf("Part of edge %d should be partial with length 0.2, got %f", part.Edge.ID, part.Length)
+		}
+		for _, pt := range part.Geom {
+			minX, maxX = math.Min(minX, pt.X), math.Max(maxX, pt.X)
+		}
+	}
+	if math.Abs(minX-5.3) > 1e-9 || math.Abs(maxX-5.7) > 1e-9 {
+		t.Errorf("Reachable road should be from x=5.3 to x=5.7, got from %f to %f", minX, maxX)
+	}
+}
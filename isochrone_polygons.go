@@ -0,0 +1,346 @@
+package horizon
+
+import (
+	"math"
+	"sort"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// IsochronePolygonsOptions Parameters of isochrone polygons building
+/*
+	Smoothness - max circumradius of Delaunay triangles kept in alpha shape (meters for WGS84 graphs).
+		Bigger value gives smoother polygons up to convex hull, smaller one gives more detailed polygons which could split into several parts.
+		Use 0 to derive it from density of reached points
+	Rings - for multi-band isochrones only: if true then polygons of every band cover area between previous and current thresholds only (the previous band becomes a hole),
+		otherwise polygons of every band cover whole area reachable within its threshold (nested polygons)
+*/
+type IsochronePolygonsOptions struct {
+	Smoothness float64
+	Rings      bool
+}
+
+// IsochronePolygon Polygon covering area reachable within max cost
+/*
+	Rings - the first ring is outer boundary, others are holes. Every ring is closed (the last point is equal to the first one)
+	Area - area of polygon excluding holes (square meters for WGS84 graphs)
+*/
+type IsochronePolygon struct {
+	Rings [][]s2.Point
+	Area  float64
+}
+
+// IsochroneBand Polygons of single band of multi-band isochrones
+/*
+	Band - index of the band (position of MaxCost in ascending sorted thresholds)
+	MinCost - threshold of the previous band (0 for the first band)
+	MaxCost - threshold of the band
+	Polygons - polygons covering the band (bigger ones go first)
+*/
+type IsochroneBand struct {
+	Band     int
+	MinCost  float64
+	MaxCost  float64
+	Polygons []IsochronePolygon
+}
+
+// FindIsochronePolygons Returns polygons covering area reachable from the source within max cost
+/*
+	source - source for outcoming isochrones
+	maxCost - max cost restriction
+	maxNearestRadius - max radius of search for nearest vertex
+	params - polygons building parameters
+	opts - per-request options (see QueryOptions). Excluded edges are neither used for snapping nor traversed. Use WithReverse for costs of reaching the source instead
+
+	Polygons are built as alpha shape (concave hull) over the reached vertices, geometry points of fully reachable edges
+	and interpolated points of partially reachable edges (cut at max cost). Result could contain several polygons (bigger ones go first)
+*/
+func (matcher *MapMatcher) FindIsochronePolygons(source *GPSMeasurement, maxCost float64, maxNearestRadius float64, params IsochronePolygonsOptions, opts ...QueryOption) ([]IsochronePolygon, error) {
+	bands, err := matcher.Engine().isochroneBandPolygons(source, []float64{maxCost}, maxNearestRadius, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return bands[0].Polygons, nil
+}
+
+// FindIsochroneBandPolygons Returns polygons for every band of multi-band isochrones. Single bounded search is done for the biggest threshold
+/*
+	source - source for outcoming isochrones
+	maxCosts - cost thresholds of bands (order doesn't matter, duplicates are ignored)
+	maxNearestRadius - max radius of search for nearest vertex
+	params - polygons building parameters (see IsochronePolygonsOptions for nested and ring polygons)
+	opts - per-request options (see QueryOptions). Excluded edges are neither used for snapping nor traversed. Use WithReverse for costs of reaching the source instead
+
+	Single Delaunay triangulation is built over reachable part of the network (edges are additionally cut at every threshold).
+	Every triangle of alpha shape belongs to the band of its most expensive vertex, so bands never overlap in rings mode.
+	Bands are returned in ascending order of thresholds
+*/
+func (matcher *MapMatcher) FindIsochroneBandPolygons(source *GPSMeasurement, maxCosts []float64, maxNearestRadius float64, params IsochronePolygonsOptions, opts ...QueryOption) ([]IsochroneBand, error) {
+	return matcher.Engine().isochroneBandPolygons(source, maxCosts, maxNearestRadius, params, opts...)
+}
+
+// isochroneBandPolygons Returns polygons for every band of multi-band isochrones on the given engine (see FindIsochroneBandPolygons)
+func (engine *MapEngine) isochroneBandPolygons(source *GPSMeasurement, maxCosts []float64, maxNearestRadius float64, params IsochronePolygonsOptions, opts ...QueryOption) ([]IsochroneBand, error) {
+	err := checkMeasurementsSRID(source)
+	if err != nil {
+		return nil, err
+	}
+	thresholds, err := IsochroneThresholds(maxCosts)
+	if err != nil {
+		return nil, err
+	}
+	query, err := engine.prepareQuery(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't prepare query")
+	}
+	sourceVertex, err := engine.isochronesSource(query, source, maxNearestRadius)
+	if err != nil {
+		return nil, err
+	}
+	costs, err := query.isochrones(sourceVertex, thresholds[len(thresholds)-1])
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't call isochrones for vertex with id '%d'", sourceVertex)
+	}
+	projection := spatial.NewLocalProjection(source.Point, engine.isEuclidean())
+	samples, sampleCosts := query.reachableSamples(projection, costs, thresholds, nil)
+	return buildIsochroneBands(projection, samples, sampleCosts, thresholds, params), nil
+}
+
+// IsochroneThresholds Returns sorted copy of cost thresholds without duplicates: bands of multi-band isochrones refer to indices in it
+func IsochroneThresholds(maxCosts []float64) ([]float64, error) {
+	if len(maxCosts) == 0 {
+		return nil, ErrEmptyMaxCosts
+	}
+	thresholds := make([]float64, 0, len(maxCosts))
+	for _, maxCost := range maxCosts {
+		if math.IsNaN(maxCost) {
+			return nil, errors.Wrap(ErrEmptyMaxCosts, "max cost is NaN")
+		}
+		thresholds = append(thresholds, maxCost)
+	}
+	sort.Float64s(thresholds)
+	unique := thresholds[:1]
+	for _, threshold := range thresholds[1:] {
+		if threshold != unique[len(unique)-1] {
+			unique = append(unique, threshold)
+		}
+	}
+	return unique, nil
+}
+
+// bandIndex Returns index of the smallest threshold which is not less than cost (thresholds must be sorted)
+func bandIndex(thresholds []float64, cost float64) int {
+	return sort.SearchFloat64s(thresholds, cost)
+}
+
+// reachableSamples Returns planar points of the reachable part of the network with travel cost of every point: reached vertices,
+// geometry of fully reachable edges and geometry of partially reachable edges up to the point where the biggest threshold is exceeded.
+// Edges are additionally cut at every threshold they cross. Duplicated points keep the smallest cost.
+// For multi-source searches rival returns cost of the vertex reached by another source (nil for single source):
+// road between vertices of different sources is cut where the costs meet, so neighbouring service areas don't overlap
+func (query *routingQuery) reachableSamples(projection spatial.LocalProjection, costs map[int64]float64, thresholds []float64, rival func(vertex int64) (float64, bool)) ([][2]float64, []float64) {
+	maxCost := thresholds[len(thresholds)-1]
+	samples := make([][2]float64, 0, len(costs))
+	sampleCosts := make([]float64, 0, len(costs))
+	known := make(map[[2]float64]int, len(costs))
+	addSample := func(pt [2]float64, cost float64) {
+		if idx, ok := known[pt]; ok {
+			sampleCosts[idx] = math.Min(sampleCosts[idx], cost)
+			return
+		}
+		known[pt] = len(samples)
+		samples = append(samples, pt)
+		sampleCosts = append(sampleCosts, cost)
+	}
+	for vertexID, cost := range costs {
+		if cost > maxCost {
+			continue
+		}
+		if vertex, ok := query.engine.vertices[vertexID]; ok && vertex.Point != nil {
+			addSample(projection.ToPlane(*vertex.Point), cost)
+		}
+		for _, edge := range query.adjacentEdges(vertexID) {
+			if edge.Polyline == nil || query.isExcluded(edge.ID) {
+				continue
+			}
+			weight := query.weight(edge)
+			if weight >= math.MaxFloat64 {
+				continue
+			}
+			edgeThresholds := thresholds
+			if rival != nil {
+				other := edge.Target
+				if query.reverse {
+					other = edge.Source
+				}
+				edgeThresholds = query.meetingThresholds(thresholds, cost, other, edge, weight, rival)
+				if len(edgeThresholds) == 0 {
+					continue
+				}
+			}
+			// Edges are traversed from their targets in reverse requests
+			line := make([][2]float64, len(*edge.Polyline))
+			for i, pt := range *edge.Polyline {
+				if query.reverse {
+					line[len(line)-1-i] = projection.ToPlane(pt)
+				} else {
+					line[i] = projection.ToPlane(pt)
+				}
+			}
+			points, pointCosts := samplePlanarLine(line, cost, weight, edgeThresholds)
+			for i := range points {
+				addSample(points[i], pointCosts[i])
+			}
+		}
+	}
+	return samples, sampleCosts
+}
+
+// meetingThresholds Returns thresholds for the edge going from the vertex to another one which is reached by rival source:
+// thresholds beyond the point where costs of both sources meet (along the opposite edge) are replaced by the meeting cost
+func (query *routingQuery) meetingThresholds(thresholds []float64, cost float64, other int64, edge *spatial.Edge, weight float64, rival func(vertex int64) (float64, bool)) []float64 {
+	rivalCost, ok := rival(other)
+	if !ok {
+		return thresholds
+	}
+	// Points of the edge could be reached by rival only via opposite edge
+	opposite := query.engine.twinEdge(edge)
+	if opposite == nil || query.isExcluded(opposite.ID) {
+		return thresholds
+	}
+	oppositeWeight := query.weight(opposite)
+	if oppositeWeight >= math.MaxFloat64 || weight+oppositeWeight <= 0 {
+		return thresholds
+	}
+	// cost + weight*x = rivalCost + oppositeWeight*(1-x)
+	fraction := (rivalCost + oppositeWeight - cost) / (weight + oppositeWeight)
+	if fraction >= 1 {
+		return thresholds
+	}
+	if fraction <= 0 {
+		return nil
+	}
+	meetingCost := cost + weight*fraction
+	ans := make([]float64, 0, len(thresholds))
+	for _, threshold := range thresholds {
+		if threshold >= meetingCost {
+			break
+		}
+		ans = append(ans, threshold)
+	}
+	return append(ans, meetingCost)
+}
+
+// buildIsochroneBands Builds alpha shape over planar samples, splits its triangles into bands and converts polygons of every band back to points
+func buildIsochroneBands(projection spatial.LocalProjection, samples [][2]float64, sampleCosts []float64, thresholds []float64, params IsochronePolygonsOptions) []IsochroneBand {
+	triangles := spatial.DelaunayTriangulation(samples)
+	alpha := params.Smoothness
+	if alpha <= 0 {
+		alpha = spatial.DefaultAlpha(samples, triangles)
+	}
+	// Band of every triangle is defined by its most expensive vertex
+	bandTriangles := make([][][3]int, len(thresholds))
+	for _, t := range triangles {
+		if spatial.Circumradius(samples, t) > alpha {
+			continue
+		}
+		cost := math.Max(sampleCosts[t[0]], math.Max(sampleCosts[t[1]], sampleCosts[t[2]]))
+		band := bandIndex(thresholds, cost)
+		if band >= len(thresholds) {
+			continue
+		}
+		bandTriangles[band] = append(bandTriangles[band], t)
+	}
+	bands := make([]IsochroneBand, len(thresholds))
+	kept := [][3]int{}
+	for i := range thresholds {
+		bands[i] = IsochroneBand{
+			Band:    i,
+			MaxCost: thresholds[i],
+		}
+		if i > 0 {
+			bands[i].MinCost = thresholds[i-1]
+		}
+		if params.Rings {
+			kept = bandTriangles[i]
+		} else {
+			kept = append(kept, bandTriangles[i]...)
+		}
+		bands[i].Polygons = planarToIsochronePolygons(projection, spatial.TrianglesToPolygons(samples, kept))
+	}
+	return bands
+}
+
+// planarToIsochronePolygons Converts planar polygons back to points
+func planarToIsochronePolygons(projection spatial.LocalProjection, shape [][][][2]float64) []IsochronePolygon {
+	polygons := make([]IsochronePolygon, 0, len(shape))
+	for _, rings := range shape {
+		polygon := IsochronePolygon{
+			Rings: make([][]s2.Point, len(rings)),
+		}
+		for i, ring := range rings {
+			polygon.Area += spatial.RingSignedArea(ring)
+			polygon.Rings[i] = make([]s2.Point, len(ring))
+			for j := range ring {
+				polygon.Rings[i][j] = projection.FromPlane(ring[j])
+			}
+		}
+		polygons = append(polygons, polygon)
+	}
+	return polygons
+}
+
+// samplePlanarLine Returns points of planar edge geometry with travel cost of every point (cost is proportional to distance along the edge).
+// Points are returned up to the biggest threshold, interpolated points are added where the edge crosses any of thresholds
+/*
+	line - planar geometry of the edge
+	startCost - travel cost of the first point
+	weight - travel cost of the whole edge
+	thresholds - sorted cost thresholds
+*/
+func samplePlanarLine(line [][2]float64, startCost float64, weight float64, thresholds []float64) ([][2]float64, []float64) {
+	maxCost := thresholds[len(thresholds)-1]
+	if len(line) == 0 || startCost > maxCost {
+		return nil, nil
+	}
+	total := 0.0
+	for i := 1; i < len(line); i++ {
+		total += math.Hypot(line[i][0]-line[i-1][0], line[i][1]-line[i-1][1])
+	}
+	points := [][2]float64{line[0]}
+	costs := []float64{startCost}
+	passed := 0.0
+	for i := 1; i < len(line); i++ {
+		segment := math.Hypot(line[i][0]-line[i-1][0], line[i][1]-line[i-1][1])
+		costFrom := startCost
+		costTo := startCost
+		if total > 0 {
+			costFrom += weight * passed / total
+			costTo += weight * (passed + segment) / total
+		}
+		// Interpolated points for thresholds crossed inside of the segment
+		for _, threshold := range thresholds[bandIndex(thresholds, costFrom):] {
+			if threshold >= costTo {
+				break
+			}
+			if threshold <= costFrom {
+				continue
+			}
+			t := (threshold - costFrom) / (costTo - costFrom)
+			points = append(points, [2]float64{
+				line[i-1][0] + (line[i][0]-line[i-1][0])*t,
+				line[i-1][1] + (line[i][1]-line[i-1][1])*t,
+			})
+			costs = append(costs, threshold)
+		}
+		if costTo > maxCost {
+			return points, costs
+		}
+		points = append(points, line[i])
+		costs = append(costs, costTo)
+		passed += segment
+	}
+	return points, costs
+}
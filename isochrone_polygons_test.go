@@ -0,0 +1,229 @@
+package horizon
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/LdDl/ch"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+)
+
+// prepareGridMatcher Returns matcher for planar two-way grid graphs with unit spacing.
+// Every grid is defined by its origin and size; bridges connect vertices of different grids by their coordinates
+func prepareGridMatcher(t *testing.T, origins [][2]float64, size int, bridges [][2][2]float64) *MapMatcher {
+	vertices := map[[2]float64]int64{}
+	coords := map[int64][2]float64{}
+	addVertex := func(x, y float64) int64 {
+		key := [2]float64{x, y}
+		if id, ok := vertices[key]; ok {
+			return id
+		}
+		id := int64(len(vertices) + 1)
+		vertices[key] = id
+		coords[id] = key
+		return id
+	}
+	links := [][2]int64{}
+	for _, origin := range origins {
+		for i := 0; i <= size; i++ {
+			for j := 0; j <= size; j++ {
+				v := addVertex(origin[0]+float64(i), origin[1]+float64(j))
+				if i < size {
+					links = append(links, [2]int64{v, addVertex(origin[0]+float64(i+1), origin[1]+float64(j))})
+				}
+				if j < size {
+					links = append(links, [2]int64{v, addVertex(origin[0]+float64(i), origin[1]+float64(j+1))})
+				}
+			}
+		}
+	}
+	for _, bridge := range bridges {
+		links = append(links, [2]int64{addVertex(bridge[0][0], bridge[0][1]), addVertex(bridge[1][0], bridge[1][1])})
+	}
+
+	graph := ch.Graph{}
+	verticesSpatial := []*spatial.Vertex{}
+	for id, xy := range coords {
+		err := graph.CreateVertex(id)
+		if err != nil {
+			t.Fatalf("Can't add vertex with id = '%d' to the graph: %v", id, err)
+		}
+		pt := spatial.NewEuclideanS2Point(xy[0], xy[1])
+		verticesSpatial = append(verticesSpatial, &spatial.Vertex{Point: &pt, ID: id})
+	}
+	edgesSpatial := []*spatial.Edge{}
+	edgeID := int64(1)
+	for _, link := range links {
+		for _, direction := range [][2]int64{{link[0], link[1]}, {link[1], link[0]}} {
+			source, target := coords[direction[0]], coords[direction[1]]
+			weight := math.Hypot(target[0]-source[0], target[1]-source[1])
+			err := graph.AddEdge(direction[0], direction[1], weight)
+			if err != nil {
+				t.Fatalf("Can't add edge from '%d' to '%d' to the graph: %v", direction[0], direction[1], err)
+			}
+			polyline := s2.Polyline{
+				spatial.NewEuclideanS2Point(source[0], source[1]),
+				spatial.NewEuclideanS2Point(target[0], target[1]),
+			}
+			edgesSpatial = append(edgesSpatial, &spatial.Edge{
+				ID:       edgeID,
+				Source:   direction[0],
+				Target:   direction[1],
+				Weight:   weight,
+				Polyline: &polyline,
+			})
+			edgeID++
+		}
+	}
+	graph.PrepareContractionHierarchies()
+	engine := NewMapEngine(
+		WithGraph(graph),
+		WithStorage(spatial.NewStorage(spatial.StorageTypeEuclidean)),
+		WithEdges(edgesSpatial),
+		WithVertices(verticesSpatial),
+	)
+	engine.queryPool = engine.graph.NewQueryPool()
+	return NewMapMatcher(WithMapEngine(engine))
+}
+
+func TestFindIsochronePolygons(t *testing.T) {
+	matcher := prepareGridMatcher(t, [][2]float64{{0, 0}}, 10, nil)
+	// Nearest edges are (5,5)->(5,6) and (5,6)->(5,5): both snap the point to vertex (5,6)
+	source := NewGPSMeasurementFromID(1, 5.1, 5.3, 0)
+	center := [2]float64{5, 6}
+
+	polygons, err := matcher.FindIsochronePolygons(source, 3, -1, IsochronePolygonsOptions{Smoothness: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polygons) != 1 {
+		t.Fatalf("Should be 1 polygon, got %d", len(polygons))
+	}
+	// Reachable area is a diamond with area 2*3*3 = 18: its boundary is approximated by grid cells
+	if polygons[0].Area < 12 || polygons[0].Area > 18+1e-9 {
+		t.Errorf("Area should be close to 18, got %f", polygons[0].Area)
+	}
+	outer := polygons[0].Rings[0]
+	if outer[0] != outer[len(outer)-1] {
+		t.Errorf("Outer ring should be closed")
+	}
+	for _, pt := range outer {
+		if math.Abs(pt.X-center[0])+math.Abs(pt.Y-center[1]) > 3+1e-9 {
+			t.Errorf("Point (%f, %f) is out of reachable area", pt.X, pt.Y)
+		}
+	}
+
+	// Bigger budget gives bigger area
+	bigger, err := matcher.FindIsochronePolygons(source, 4, -1, IsochronePolygonsOptions{Smoothness: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bigger) != 1 || bigger[0].Area <= polygons[0].Area {
+		t.Errorf("Bigger budget should give bigger area")
+	}
+}
+
+func TestFindIsochronePolygonsMulti(t *testing.T) {
+	// Two grids connected by long bridge: there are no reachable points along the bridge except its ends
+	matcher := prepareGridMatcher(t, [][2]float64{{0, 0}, {30, 0}}, 2, [][2][2]float64{{{2, 1}, {30, 1}}})
+	source := NewGPSMeasurementFromID(1, 1, 1, 0)
+	polygons, err := matcher.FindIsochronePolygons(source, 100, -1, IsochronePolygonsOptions{Smoothness: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polygons) != 2 {
+		t.Fatalf("Should be 2 polygons, got %d", len(polygons))
+	}
+	for i := range polygons {
+		if math.Abs(polygons[i].Area-4) > 1e-9 {
+			t.Errorf("Area of polygon #%d should be 4, got %f", i, polygons[i].Area)
+		}
+	}
+
+	// Convex hull for huge smoothness
+	polygons, err = matcher.FindIsochronePolygons(source, 100, -1, IsochronePolygonsOptions{Smoothness: 1e6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(polygons) != 1 || math.Abs(polygons[0].Area-64) > 1e-9 {
+		t.Errorf("Should be single convex polygon with area 64")
+	}
+}
+
+func TestFindIsochroneBands(t *testing.T) {
+	matcher := prepareGridMatcher(t, [][2]float64{{0, 0}}, 10, nil)
+	source := NewGPSMeasurementFromID(1, 5.1, 5.3, 0)
+	isochrones, err := matcher.FindIsochroneBands(source, []float64{3, 1, 3}, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Diamond of radius 3 around (5,6): 1 + 4 + 8 + 12 vertices
+	if len(isochrones) != 25 {
+		t.Fatalf("Should be 25 reached vertices, got %d", len(isochrones))
+	}
+	for _, isochrone := range isochrones {
+		expectedBand := 0
+		if isochrone.Cost > 1 {
+			expectedBand = 1
+		}
+		if isochrone.Band != expectedBand {
+			t.Errorf("Band of vertex %d with cost %f should be %d, got %d", isochrone.Vertex.ID, isochrone.Cost, expectedBand, isochrone.Band)
+		}
+	}
+
+	_, err = matcher.FindIsochroneBands(source, nil, -1)
+	if !errors.Is(err, ErrEmptyMaxCosts) {
+		t.Errorf("Empty thresholds should give ErrEmptyMaxCosts, got %v", err)
+	}
+}
+
+func TestFindIsochroneBandPolygons(t *testing.T) {
+	matcher := prepareGridMatcher(t, [][2]float64{{0, 0}}, 10, nil)
+	source := NewGPSMeasurementFromID(1, 5.1, 5.3, 0)
+	eps := 1e-9
+
+	nested, err := matcher.FindIsochroneBandPolygons(source, []float64{3, 2}, -1, IsochronePolygonsOptions{Smoothness: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nested) != 2 {
+		t.Fatalf("Should be 2 bands, got %d", len(nested))
+	}
+	if nested[0].MinCost != 0 || nested[0].MaxCost != 2 || nested[1].MinCost != 2 || nested[1].MaxCost != 3 {
+		t.Errorf("Bands should be sorted by thresholds, got [%f;%f] and [%f;%f]", nested[0].MinCost, nested[0].MaxCost, nested[1].MinCost, nested[1].MaxCost)
+	}
+	if len(nested[0].Polygons) != 1 || len(nested[1].Polygons) != 1 {
+		t.Fatalf("Every nested band should be covered by single polygon")
+	}
+	// The same polygon as for single threshold
+	single, err := matcher.FindIsochronePolygons(source, 3, -1, IsochronePolygonsOptions{Smoothness: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(single[0].Area-nested[1].Polygons[0].Area) > eps {
+		t.Errorf("Nested polygon of the last band should have area %f, got %f", single[0].Area, nested[1].Polygons[0].Area)
+	}
+	if nested[0].Polygons[0].Area >= nested[1].Polygons[0].Area || nested[0].Polygons[0].Area > 8+eps {
+		t.Errorf("Nested polygon of the first band should be smaller than the second one and not bigger than 8, got %f", nested[0].Polygons[0].Area)
+	}
+
+	rings, err := matcher.FindIsochroneBandPolygons(source, []float64{2, 3}, -1, IsochronePolygonsOptions{Smoothness: 1, Rings: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(rings[0].Polygons[0].Area-nested[0].Polygons[0].Area) > eps {
+		t.Errorf("The first band should be the same for nested and ring polygons")
+	}
+	ringArea := 0.0
+	for _, polygon := range rings[1].Polygons {
+		ringArea += polygon.Area
+	}
+	if math.Abs(ringArea-(nested[1].Polygons[0].Area-nested[0].Polygons[0].Area)) > eps {
+		t.Errorf("Ring area should be %f, got %f", nested[1].Polygons[0].Area-nested[0].Polygons[0].Area, ringArea)
+	}
+	if len(rings[1].Polygons) != 1 || len(rings[1].Polygons[0].Rings) != 2 {
+		t.Errorf("The second band should be single polygon with hole")
+	}
+}
@@ -0,0 +1,87 @@
+package horizon
+
+import (
+	"math"
+	"testing"
+
+	"github.com/LdDl/horizon/spatial"
+)
+
+func TestFindIsochronesReverse(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	// Near vertex 5: isochrones start from vertex 3
+	source := NewGPSMeasurementFromID(1, 13, 0.1, 0)
+
+	checkCosts := func(result IsochronesResult, expected map[int64]float64) {
+		t.Helper()
+		if len(result) != len(expected) {
+			t.Fatalf("Expected %d vertices, but got %d", len(expected), len(result))
+		}
+		for _, isochrone := range result {
+			cost, ok := expected[isochrone.Vertex.ID]
+			if !ok {
+				t.Errorf("Vertex %d should not be reachable", isochrone.Vertex.ID)
+				continue
+			}
+			if math.Abs(cost-isochrone.Cost) > 1e-6 {
+				t.Errorf("Cost for vertex %d should be %f, but got %f", isochrone.Vertex.ID, cost, isochrone.Cost)
+			}
+		}
+	}
+
+	// Only vertex 5 is reachable from vertex 3 along one-way edges
+	result, err := matcher.FindIsochrones(source, 12, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCosts(result, map[int64]float64{3: 0, 5: 5})
+
+	// But vertex 3 is reachable from the most of vertices
+	result, err = matcher.FindIsochrones(source, 12, -1, WithReverse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCosts(result, map[int64]float64{3: 0, 2: 5, 4: math.Sqrt(50), 1: 10})
+
+	// Reverse search respects profiles and exclusions
+	result, err = matcher.FindIsochrones(source, 20, -1, WithReverse(), WithProfile("travel_time"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCosts(result, map[int64]float64{3: 0, 4: 7.5, 1: 15, 0: 20})
+	result, err = matcher.FindIsochrones(source, 20, -1, WithReverse(), WithProfile("travel_time"), WithExcludedEdges(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCosts(result, map[int64]float64{3: 0, 4: 7.5})
+}
+
+func TestReachableSamplesReverse(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	query, err := matcher.engine.prepareQuery(WithReverse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	costs, err := query.isochrones(3, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	projection := spatial.NewLocalProjection(spatial.NewEuclideanS2Point(0, 0), true)
+	samples, sampleCosts := query.reachableSamples(projection, costs, []float64{7}, nil)
+	// Edge 1->2 is traversed backwards from vertex 2 (cost 5) and cut 2 units before it
+	found := false
+	for i := range samples {
+		if math.Abs(samples[i][0]-3) < 1e-9 && math.Abs(samples[i][1]) < 1e-9 {
+			found = true
+			if math.Abs(sampleCosts[i]-7) > 1e-9 {
+				t.Errorf("Cost of the cut point should be 7, got %f", sampleCosts[i])
+			}
+		}
+		if samples[i][0] < 3-1e-9 {
+			t.Errorf("Point (%f, %f) should not be reachable", samples[i][0], samples[i][1])
+		}
+	}
+	if !found {
+		t.Errorf("Cut point (3, 0) should be sampled")
+	}
+}
@@ -13,9 +13,17 @@ import (
  */
 type IsochronesResult []*Isochrone
 
+// Isochrone Single reached vertex
+/*
+	Vertex - reached vertex
+	Cost - travel cost from the source to the vertex
+	Band - index of the band (see FindIsochroneBands): position of the smallest threshold which is not less than Cost in ascending sorted thresholds.
+		Always 0 for FindIsochrones
+*/
 type Isochrone struct {
 	Vertex *spatial.Vertex
 	Cost   float64
+	Band   int
 }
 
 // FindIsochrones Find shortest path between two obserations (not necessary GPS points).
@@ -24,55 +32,101 @@ type Isochrone struct {
 	source - source for outcoming isochrones
 	maxCost - max cost restriction for single isochrone line
 	maxNearestRadius - max radius of search for nearest vertex
+	opts - per-request options (see QueryOptions). Excluded edges are neither used for snapping nor traversed. Use WithReverse for costs of reaching the source instead
 */
-func (matcher *MapMatcher) FindIsochrones(source *GPSMeasurement, maxCost float64, maxNearestRadius float64) (IsochronesResult, error) {
-	var closestSource []spatial.NearestObject
+func (matcher *MapMatcher) FindIsochrones(source *GPSMeasurement, maxCost float64, maxNearestRadius float64, opts ...QueryOption) (IsochronesResult, error) {
+	return matcher.Engine().isochroneBands(source, []float64{maxCost}, maxNearestRadius, opts...)
+}
+
+// FindIsochroneBands Find isochrones for several cost thresholds at once. Single bounded search is done for the biggest threshold
+/*
+	NOTICE: this function snaps point to only one nearest vertex (without multiple candidates for provided point)
+	source - source for outcoming isochrones
+	maxCosts - cost thresholds of bands (order doesn't matter, duplicates are ignored)
+	maxNearestRadius - max radius of search for nearest vertex
+	opts - per-request options (see QueryOptions). Excluded edges are neither used for snapping nor traversed. Use WithReverse for costs of reaching the source instead
+
+	Every reached vertex is labeled with its band: index of the smallest threshold which is not less than its cost in ascending sorted thresholds
+*/
+func (matcher *MapMatcher) FindIsochroneBands(source *GPSMeasurement, maxCosts []float64, maxNearestRadius float64, opts ...QueryOption) (IsochronesResult, error) {
+	return matcher.Engine().isochroneBands(source, maxCosts, maxNearestRadius, opts...)
+}
+
+// isochroneBands Finds isochrones for several cost thresholds on the given engine (see FindIsochroneBands)
+func (engine *MapEngine) isochroneBands(source *GPSMeasurement, maxCosts []float64, maxNearestRadius float64, opts ...QueryOption) (IsochronesResult, error) {
+	err := checkMeasurementsSRID(source)
+	if err != nil {
+		return nil, err
+	}
+	thresholds, err := IsochroneThresholds(maxCosts)
+	if err != nil {
+		return nil, err
+	}
+	query, err := engine.prepareQuery(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't prepare query")
+	}
+	choosenSourceVertex, err := engine.isochronesSource(query, source, maxNearestRadius)
+	if err != nil {
+		return nil, err
+	}
+	ans, err := query.isochrones(choosenSourceVertex, thresholds[len(thresholds)-1])
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't call isochrones for vertex with id '%d'", choosenSourceVertex)
+	}
+	isochrones := make(IsochronesResult, 0, len(ans))
+	for vertexID, cost := range ans {
+		vertex, ok := engine.vertices[vertexID]
+		if !ok {
+			log.Printf("[WARNING]; No such vertex in storage: %d\n", vertexID)
+		}
+		isochrones = append(isochrones, &Isochrone{
+			Vertex: vertex,
+			Cost:   cost,
+			Band:   bandIndex(thresholds, cost),
+		})
+	}
+	return isochrones, nil
+}
+
+// isochronesSource Returns vertex which is used as source for isochrones: the closest vertex (along the edge) of the nearest edge
+func (engine *MapEngine) isochronesSource(query *routingQuery, source *GPSMeasurement, maxNearestRadius float64) (int64, error) {
 	var err error
+	// Take more than one nearest edge when exclusions are present: the nearest one could be excluded
+	nearestLimit := 1
+	if query.hasExclusions() {
+		nearestLimit = DEFAULT_CANDIDATES_LIMIT
+	}
+	var closestSource []spatial.NearestObject
 	if maxNearestRadius < 0 {
-		closestSource, err = matcher.engine.storage.FindNearest(source.Point, 1)
+		closestSource, err = engine.storage.FindNearest(source.Point, nearestLimit)
 		if err != nil {
-			return nil, errors.Wrapf(err, "FindNearest failed for source point %v", source.Point)
+			return -1, errors.Wrapf(err, "FindNearest failed for source point %v", source.Point)
 		}
 	} else {
-		closestSource, err = matcher.engine.storage.FindNearestInRadius(source.Point, maxNearestRadius, 1)
+		closestSource, err = engine.storage.FindNearestInRadius(source.Point, maxNearestRadius, nearestLimit)
 		if err != nil {
-			return nil, errors.Wrapf(err, "FindNearestInRadius failed for source point %v with radius %f", source.Point, maxNearestRadius)
+			return -1, errors.Wrapf(err, "FindNearestInRadius failed for source point %v with radius %f", source.Point, maxNearestRadius)
 		}
 	}
+	closestSource = query.filterNearest(closestSource)
 	if len(closestSource) == 0 {
 		// @todo need to handle this case properly...
-		return nil, ErrSourceNotFound
+		return -1, ErrSourceNotFound
 	}
 	// Find corresponding edge
-	s2polylineSource := matcher.engine.storage.GetEdge(closestSource[0].EdgeID)
-	// Find vertex for 'source' point
-	m, n := s2polylineSource.Source, s2polylineSource.Target
-	edgeSource := matcher.engine.edges[m][n]
+	edgeSource := engine.edges[int64(closestSource[0].EdgeID)]
 	if edgeSource == nil {
-		return nil, fmt.Errorf("Edge 'source' not found in graph")
+		return -1, fmt.Errorf("Edge 'source' not found in graph")
 	}
-	_, fractionSource, _ := spatial.CalcProjection(*edgeSource.Polyline, source.Point)
+	// Find vertex for 'source' point
+	m, n := edgeSource.Source, edgeSource.Target
+	_, fractionSource, _ := engine.calcProjection(*edgeSource.Polyline, source.Point)
 	choosenSourceVertex := n
 	if fractionSource > 0.5 {
 		choosenSourceVertex = m
 	} else {
 		choosenSourceVertex = n
 	}
-
-	ans, err := matcher.engine.graph.Isochrones(choosenSourceVertex, maxCost)
-	if err != nil {
-		return nil, errors.Wrapf(err, "Can't call isochrones for vertex with id '%d'", choosenSourceVertex)
-	}
-	isochrones := make(IsochronesResult, 0, len(ans))
-	for vertexID, cost := range ans {
-		vertex, ok := matcher.engine.vertices[vertexID]
-		if !ok {
-			log.Printf("[WARNING]; No such vertex in storage: %d\n", vertexID)
-		}
-		isochrones = append(isochrones, &Isochrone{
-			Vertex: vertex,
-			Cost:   cost,
-		})
-	}
-	return isochrones, nil
+	return choosenSourceVertex, nil
 }
@@ -5,25 +5,39 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/LdDl/ch"
 	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
 	"github.com/pkg/errors"
 )
 
 // MapEngine Engine for solving finding shortest path and KNN problems
-// edges - set of edges (map[from_vertex]map[to_vertex]Edge)
+// edges - set of edges keyed by edge ID. There could be several (parallel) edges between the same pair of vertices
+// outgoing - adjacency lists: edges going out of every vertex
 // storage - spatial storage for solving KNN problem (implements spatial.Storage interface)
 // vertices - datastore for graph vertices (with geometry property)
 // graph - Graph(E,V). It wraps ch.Graph (see https://github.com/LdDl/ch/blob/master/graph.go#L17). It used for solving finding shortest path problem.
 // queryPool - thread-safe query pool for concurrent shortest path queries (ch v1.10.0+)
 // vertexComponent - matches vertex ID to its weakly connected component ID
 // bigComponentID - ID of the largest weakly connected component. -1 if no components found
+// profiles - additional named weight profiles, each with its own contraction hierarchy (default profile is graph itself)
+// profileColumns - names of weight profiles to be loaded from edges file
+// graphSRID - SRID of geometries in CSV files (4326 by default)
+// graphSRIDSet - true if SRID is provided by WithGraphSRID (snapshot with other SRID is rejected then, see LoadSnapshot)
+// attributes - additional properties of edges keyed by edge ID (see spatial.EdgeAttributes). Edges without attributes are omitted
+// attributeNames - names of edge attributes loaded from edges file
+// ellipsoidal - true if lengths and distances are evaluated on WGS84 ellipsoid instead of sphere (spatial index is still spherical)
+// pendingProfiles - weight profiles of WithWeightProfile options which are prepared after edges are loaded
+// optionsErr - the first error of options applied by NewMapEngine (see Err)
 type MapEngine struct {
-	edges     map[int64]map[int64]*spatial.Edge
+	edges     map[int64]*spatial.Edge
+	outgoing  map[int64][]*spatial.Edge
 	storage   spatial.Storage
 	vertices  map[int64]*spatial.Vertex
 	graph     ch.Graph
@@ -35,24 +49,73 @@ type MapEngine struct {
 	vertexStrongComponent map[int64]int64
 	bigStrongComponentID  int64
 	isComponentVerySmall  map[int64]bool
+	// Additional weight profiles
+	profiles       map[string]*weightProfile
+	profileColumns []string
+	graphSRID      int
+	graphSRIDSet   bool
+	ellipsoidal    bool
+	attributes     map[int64]spatial.EdgeAttributes
+	attributeNames []string
+	// Weight profiles which need edges to be prepared
+	pendingProfiles []pendingProfile
+	optionsErr      error
+	// Incoming edges (target -> edges) for searches on reversed graph
+	incoming map[int64][]*spatial.Edge
+	// Facilities (points of interest) snapped to edges. Could be replaced at runtime
+	facilities       []*SnappedFacility
+	facilitiesRadius float64
+	facilitiesMu     sync.RWMutex
 }
 
 // NewMapEngineDefault Returns pointer to created MapEngine with default parameters
 func NewMapEngineDefault() *MapEngine {
 	storage := spatial.NewStorage(spatial.StorageTypeSpherical)
 	return &MapEngine{
-		edges:    make(map[int64]map[int64]*spatial.Edge),
-		vertices: make(map[int64]*spatial.Vertex),
-		storage:  storage,
+		edges:     make(map[int64]*spatial.Edge),
+		outgoing:  make(map[int64][]*spatial.Edge),
+		incoming:  make(map[int64][]*spatial.Edge),
+		vertices:  make(map[int64]*spatial.Vertex),
+		storage:   storage,
+		graphSRID: 4326,
 	}
 }
 
 // NewMapEngine Returns pointer to created MapEngine with provided parameters
+// Options could be provided in any order: edges are added to spatial index and connected components are evaluated after every option is applied.
+// Engine is not validated, so consider MapEngineBuilder which returns an error for inconsistent graph
 func NewMapEngine(opts ...func(*MapEngine)) *MapEngine {
+	engine := newMapEngine(opts...)
+	if engine.storage != nil {
+		for _, edge := range engine.edges {
+			err := engine.storage.AddEdge(uint64(edge.ID), edge)
+			if err != nil {
+				fmt.Printf("[WARNING]: Can't add edge %d to spatial index: %s\n", edge.ID, err.Error())
+			}
+		}
+	}
+	if len(engine.edges) > 0 {
+		engine.computeComponents()
+		// Without edges weight profiles are kept for loading (see LoadSnapshot)
+		engine.optionsErr = engine.preparePendingProfiles()
+	}
+	return engine
+}
+
+// Err Returns the first error of options applied by NewMapEngine (e.g. weight profile which can't be prepared). Nil means that every option is applied
+func (engine *MapEngine) Err() error {
+	return engine.optionsErr
+}
+
+// newMapEngine Returns pointer to MapEngine with applied options and without any post-processing
+func newMapEngine(opts ...func(*MapEngine)) *MapEngine {
 	engine := &MapEngine{
-		edges:    make(map[int64]map[int64]*spatial.Edge),
-		vertices: make(map[int64]*spatial.Vertex),
-		storage:  nil,
+		edges:     make(map[int64]*spatial.Edge),
+		outgoing:  make(map[int64][]*spatial.Edge),
+		incoming:  make(map[int64][]*spatial.Edge),
+		vertices:  make(map[int64]*spatial.Vertex),
+		storage:   nil,
+		graphSRID: 4326,
 	}
 	for _, opt := range opts {
 		opt(engine)
@@ -89,23 +152,81 @@ func WithS2Storage(storage *spatial.S2Storage) func(*MapEngine) {
 	}
 }
 
-// WithEdges is an option which sets edges for MapEngine and populate existing spatial index
-// This option requires that storage is already set in MapEngine which you can do via WithStorage option
-// If storage is nil then edges are just set without populating spatial index!!!
+// WithEdges is an option which sets edges for MapEngine
+// Edges are added to spatial index by NewMapEngine (or MapEngineBuilder) after every option is applied, so storage could be set by any other option
+// Edges are identified by their IDs: parallel edges (with the same source and target vertices) are kept, edge with duplicate ID replaces the previous one
 func WithEdges(edges []*spatial.Edge) func(*MapEngine) {
 	return func(engine *MapEngine) {
 		for _, edge := range edges {
-			if engine.edges[edge.Source] == nil {
-				engine.edges[edge.Source] = make(map[int64]*spatial.Edge)
-			}
-			engine.edges[edge.Source][edge.Target] = edge
-			if engine.storage != nil {
-				engine.storage.AddEdge(uint64(edge.ID), edge)
+			engine.addEdge(edge)
+		}
+	}
+}
+
+// WithEdgeAttributes is an option which sets attributes of edges (road class, speed limit, name and etc.) for MapEngine
+/*
+	attributes - edge ID to its attributes
+*/
+func WithEdgeAttributes(attributes map[int64]spatial.EdgeAttributes) func(*MapEngine) {
+	return func(engine *MapEngine) {
+		if engine.attributes == nil {
+			engine.attributes = make(map[int64]spatial.EdgeAttributes, len(attributes))
+		}
+		known := make(map[string]bool, len(engine.attributeNames))
+		for _, name := range engine.attributeNames {
+			known[name] = true
+		}
+		added := []string{}
+		for edgeID, edgeAttributes := range attributes {
+			engine.attributes[edgeID] = edgeAttributes
+			for name := range edgeAttributes {
+				if !known[name] {
+					known[name] = true
+					added = append(added, name)
+				}
 			}
 		}
+		// Names of new attributes are appended in alphabetical order
+		sort.Strings(added)
+		engine.attributeNames = append(engine.attributeNames, added...)
 	}
 }
 
+// WithGraphSRID is an option which sets SRID of geometries in CSV files (4326 by default)
+/*
+	srid - SRID of geometries:
+		0 - raw Cartesian coordinates, Euclidean storage is used
+		4326 - WGS84 longitude and latitude
+		any projected CRS (see spatial.ProjectionBySRID) - geometries are transformed to WGS84 on load and spherical storage is used
+
+	Observations could be provided in any supported SRID regardless of SRID of the graph, except planar ones: SRID = 0 is compatible only with SRID = 0
+*/
+func WithGraphSRID(srid int) func(*MapEngine) {
+	return func(engine *MapEngine) {
+		engine.graphSRID = srid
+		engine.graphSRIDSet = true
+		if srid == 0 {
+			engine.storage = spatial.NewStorage(spatial.StorageTypeEuclidean)
+		}
+	}
+}
+
+// WithEllipsoidalDistances is an option which makes engine evaluate lengths of edges (and routes), distances between observations and their projections on WGS84 ellipsoid (see spatial.GeodesicDistance)
+/*
+	Spherical model gives up to 0.5% error of length. Spatial index and projections onto edges are still evaluated on sphere.
+	Option should be provided before loading of graph, since 'length' weight profile is evaluated on load. It has no effect for Euclidean storage
+*/
+func WithEllipsoidalDistances() func(*MapEngine) {
+	return func(engine *MapEngine) {
+		engine.ellipsoidal = true
+	}
+}
+
+// IsEllipsoidal Returns true if engine evaluates lengths and distances on WGS84 ellipsoid
+func (engine *MapEngine) IsEllipsoidal() bool {
+	return engine.ellipsoidal
+}
+
 // WithVertices is an option which sets vertices for MapEngine
 func WithVertices(vertices []*spatial.Vertex) func(*MapEngine) {
 	return func(engine *MapEngine) {
@@ -115,14 +236,61 @@ func WithVertices(vertices []*spatial.Vertex) func(*MapEngine) {
 	}
 }
 
-func prepareEngine(edgesFilename string) (*MapEngine, error) {
+// computeComponents Evaluates weakly and strongly connected components of the graph
+func (engine *MapEngine) computeComponents() {
+	// Compute weakly connected components for the graph
+	componentsResult := engine.computeWeakConnectedComponents()
+	engine.vertexComponent = componentsResult.VertexComponent
+	engine.bigComponentID = componentsResult.BigComponentID
+
+	// Compute strongly connected components for the graph via Tarjan's algorithm
+	sccResult := engine.computeStrongConnectedComponents()
+	engine.vertexStrongComponent = sccResult.VertexComponent
+	engine.bigStrongComponentID = sccResult.BigComponentID
+	engine.isComponentVerySmall = sccResult.IsComponentVerySmall
+}
+
+// isEuclidean Returns true if engine uses planar geometry (SRID = 0)
+func (engine *MapEngine) isEuclidean() bool {
+	_, ok := engine.storage.(*spatial.EuclideanStorage)
+	return ok
+}
+
+// geoDistance Returns distance between two points using distance model of the engine (see WithEllipsoidalDistances). Nil engine uses distance model of the first point
+func (engine *MapEngine) geoDistance(a, b *spatial.GeoPoint) float64 {
+	if engine != nil && engine.ellipsoidal && a.SRID() != 0 && b.SRID() != 0 {
+		return spatial.GeodesicDistance(a.Point, b.Point)
+	}
+	return a.DistanceTo(b)
+}
+
+// calcProjection Returns projection on polyline, fraction and index of the next vertex using geometry of the engine
+func (engine *MapEngine) calcProjection(polyline s2.Polyline, pt s2.Point) (s2.Point, float64, int) {
+	if engine.isEuclidean() {
+		return spatial.CalcProjectionEuclidean(polyline, pt)
+	}
+	return spatial.CalcProjection(polyline, pt)
+}
+
+// subPolyline Returns part of polyline between two fractions of its length using geometry of the engine
+func (engine *MapEngine) subPolyline(polyline s2.Polyline, from, to float64) s2.Polyline {
+	if engine.isEuclidean() {
+		return spatial.SubPolylineEuclidean(polyline, from, to)
+	}
+	return spatial.SubPolyline(polyline, from, to)
+}
+
+func prepareEngine(edgesFilename string, opts ...func(*MapEngine)) (*MapEngine, error) {
 	engine := NewMapEngineDefault()
+	for _, opt := range opts {
+		opt(engine)
+	}
+	if !spatial.IsSRIDSupported(engine.graphSRID) {
+		return nil, errors.Wrapf(spatial.ErrUnknownSRID, "Can't load graph with SRID %d", engine.graphSRID)
+	}
 
 	/* Prepare filenames (output of 'osm2ch' CLI tool) */
-	fnamePart := strings.Split(edgesFilename, ".csv")
-	edgesFilename = fnamePart[0] + ".csv"
-	verticesFilename := fnamePart[0] + "_vertices.csv"
-	shortcutsFilename := fnamePart[0] + "_shortcuts.csv"
+	edgesFilename, verticesFilename, shortcutsFilename := csvFilenames(edgesFilename)
 	fmt.Printf("Extracting edges from '%s' file...\n", edgesFilename)
 	st := time.Now()
 	err := engine.extractDataFromCSVs(edgesFilename, verticesFilename, shortcutsFilename)
@@ -136,24 +304,101 @@ func prepareEngine(edgesFilename string) (*MapEngine, error) {
 	return engine, nil
 }
 
+// extractDataFromCSVs Loads graph from edges, vertices and shortcuts files (see NewMapMatcherFromFiles).
+// Contraction hierarchies are prepared on load when vertices file is missing or has no order positions for some vertices, or when there are no shortcuts
 func (engine *MapEngine) extractDataFromCSVs(edgesFname, verticesFname, shortcutsFname string) error {
+	profileWeights, err := engine.readEdgesCSV(edgesFname)
+	if err != nil {
+		return err
+	}
+	/* Order position and importance of each vertex help to avoid graph.PrepareContractionHierarchies() call */
+	ordered, err := engine.readVerticesCSV(verticesFname)
+	if err != nil {
+		return err
+	}
+	shortcutsNum := 0
+	if ordered {
+		shortcutsNum, err = engine.readShortcutsCSV(shortcutsFname)
+		if err != nil {
+			return err
+		}
+	}
+	engine.verticesFromEdges()
+
+	switch {
+	case ordered && shortcutsNum > 0:
+		// Finalize import after loading all vertices, edges, and shortcuts (required for ch v1.10.0+)
+		engine.graph.FinalizeImport()
+	case ordered:
+		// Order could give no shortcuts at all (e.g. for tiny graphs), so shortcuts are prepared for the given order instead of contraction from scratch
+		fmt.Printf("There are no shortcuts in '%s' file. Preparing shortcuts for order positions of vertices from '%s' file...\n", shortcutsFname, verticesFname)
+		st := time.Now()
+		shortcutsNum, err = engine.contractInOrder()
+		if err != nil {
+			return errors.Wrap(err, "Can't prepare shortcuts for order positions of vertices")
+		}
+		engine.graph.FinalizeImport()
+		fmt.Printf("Done in %v: %d shortcuts\n", time.Since(st), shortcutsNum)
+	default:
+		fmt.Printf("There are no order positions of vertices in '%s' file. Preparing contraction hierarchies...\n", verticesFname)
+		st := time.Now()
+		engine.graph.PrepareContractionHierarchies()
+		fmt.Printf("Done in %v: %d shortcuts\n", time.Since(st), engine.graph.GetShortcutsNum())
+	}
+
+	// Initialize thread-safe query pool for concurrent shortest path queries
+	engine.queryPool = engine.graph.NewQueryPool()
+
+	engine.computeComponents()
+
+	// Prepare contraction hierarchies for additional weight profiles
+	for _, name := range engine.profileColumns {
+		err = engine.AddWeightProfile(name, profileWeights[name])
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Can't prepare weight profile '%s'", name))
+		}
+	}
+
+	return engine.preparePendingProfiles()
+}
+
+// readEdgesCSV Reads edges file: adds edges to the graph, spatial index and adjacency lists and keeps their attributes. Returns weights of additional profiles
+func (engine *MapEngine) readEdgesCSV(edgesFname string) (map[string]map[int64]float64, error) {
 	// Allocate memory for edges
-	engine.edges = make(map[int64]map[int64]*spatial.Edge)
+	engine.edges = make(map[int64]*spatial.Edge)
+	engine.outgoing = make(map[int64][]*spatial.Edge)
+	engine.incoming = make(map[int64][]*spatial.Edge)
 
 	// Read edges first
 	fileEdges, err := os.Open(edgesFname)
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("Can't open edges file '%s'", edgesFname))
+		return nil, errors.Wrap(err, fmt.Sprintf("Can't open edges file '%s'", edgesFname))
 	}
 	defer fileEdges.Close()
 	readerEdges := csv.NewReader(fileEdges)
 	readerEdges.Comma = ';'
 
 	// Fill graph with edges informations
-	// Skip header of CSV-file
-	_, err = readerEdges.Read()
+	// Read header of CSV-file to find columns of additional weight profiles
+	header, err := readerEdges.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Can't read header of edges file '%s'", edgesFname))
+	}
+	columns, err := parseEdgesHeader(header, engine.profileColumns)
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("Can't read header of edges file '%s'", edgesFname))
+		return nil, errors.Wrap(err, fmt.Sprintf("Can't parse header of edges file '%s'", edgesFname))
+	}
+	profileWeights := make(map[string]map[int64]float64, len(engine.profileColumns))
+	for _, name := range engine.profileColumns {
+		profileWeights[name] = make(map[int64]float64)
+		if _, ok := columns.profiles[name]; !ok && name != LENGTH_PROFILE {
+			return nil, fmt.Errorf("there is no column '%s' for weight profile in edges file '%s'", name, edgesFname)
+		}
+	}
+	engine.attributes = make(map[int64]spatial.EdgeAttributes)
+	engine.attributeNames = make([]string, 0, len(columns.attributes))
+	for _, column := range columns.attributes {
+		engine.attributeNames = append(engine.attributeNames, column.name)
 	}
 	// Read file line by line
 	for {
@@ -161,115 +406,177 @@ func (engine *MapEngine) extractDataFromCSVs(edgesFname, verticesFname, shortcut
 		if err == io.EOF {
 			break
 		}
-		sourceVertex, err := strconv.ParseInt(record[0], 10, 64)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse source vertex in edges file. The vertex is '%s'", record[0]))
+			return nil, errors.Wrap(err, fmt.Sprintf("Can't read edges file '%s'", edgesFname))
 		}
-		targetVertex, err := strconv.ParseInt(record[1], 10, 64)
+		edge, attributes, err := columns.parseEdge(record)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse target vertex in edges file. The vertex is '%s'", record[1]))
+			return nil, err
 		}
-		weight, err := strconv.ParseFloat(record[2], 64)
-		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse weight of an edge in edges file. The weight is '%s'", record[2]))
+		if _, ok := engine.edges[edge.ID]; ok {
+			// Both edges would get into contraction hierarchies, but only the last one into engine's indices
+			line, _ := readerEdges.FieldPos(0)
+			return nil, errors.Wrapf(ErrInconsistentGraph, "duplicate edge_id %d on line %d of edges file '%s' (use ValidateGraph to repair it)", edge.ID, line, edgesFname)
 		}
-		edgeID, err := strconv.ParseInt(record[5], 10, 64)
+		err = engine.graph.CreateVertex(edge.Source)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse edge identifier in edges file. The edge is '%s'", record[5]))
+			return nil, errors.Wrap(err, fmt.Sprintf("Can't add source vertex with from_vertex_id = '%d'", edge.Source))
 		}
-		err = engine.graph.CreateVertex(sourceVertex)
+		err = engine.graph.CreateVertex(edge.Target)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't add source vertex with from_vertex_id = '%d'", sourceVertex))
+			return nil, errors.Wrap(err, fmt.Sprintf("Can't add target vertex with to_vertex_id = '%d'", edge.Target))
 		}
-		err = engine.graph.CreateVertex(targetVertex)
+		err = engine.graph.AddEdge(edge.Source, edge.Target, edge.Weight)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't add target vertex with to_vertex_id = '%d'", targetVertex))
+			return nil, errors.Wrap(err, fmt.Sprintf("Can't add edge: from_vertex_id = '%d' | to_vertex_id = '%d'", edge.Source, edge.Target))
 		}
-		err = engine.graph.AddEdge(sourceVertex, targetVertex, weight)
+		edge.Polyline, err = columns.parseGeometry(record, engine.graphSRID)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't add edge: from_vertex_id = '%d' | to_vertex_id = '%d'", sourceVertex, targetVertex))
+			return nil, err
+		}
+		engine.addEdge(edge)
+		if attributes != nil {
+			engine.attributes[edge.ID] = attributes
 		}
 
-		coordinates := record[3]
-		s2Polyline, err := spatial.WKTToS2PolylineFeature(coordinates)
+		weights, err := columns.parseProfiles(record)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse WKT geometry of the edge: from_vertex_id = '%d' | to_vertex_id = '%d' | geom = '%s'", sourceVertex, targetVertex, coordinates))
-		}
-		if _, ok := engine.edges[sourceVertex]; !ok {
-			engine.edges[sourceVertex] = make(map[int64]*spatial.Edge)
+			return nil, err
 		}
-		edge := spatial.Edge{
-			ID:       edgeID,
-			Source:   sourceVertex,
-			Target:   targetVertex,
-			Weight:   weight,
-			Polyline: s2Polyline,
+		for name := range profileWeights {
+			if _, ok := columns.profiles[name]; !ok {
+				// Only 'length' profile could be there
+				profileWeights[name][edge.ID] = engine.edgeLength(edge)
+				continue
+			}
+			if weight, ok := weights[name]; ok {
+				profileWeights[name][edge.ID] = weight
+			}
 		}
-		engine.edges[sourceVertex][targetVertex] = &edge
 
-		err = engine.storage.AddEdge(uint64(edgeID), &edge)
+		err = engine.storage.AddEdge(uint64(edge.ID), edge)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't add s2-polyline to engine: from_vertex_id = '%d' | to_vertex_id = '%d' | geom = '%s'", sourceVertex, targetVertex, coordinates))
+			return nil, errors.Wrap(err, fmt.Sprintf("Can't add s2-polyline to engine: from_vertex_id = '%d' | to_vertex_id = '%d' | geom = '%s'", edge.Source, edge.Target, record[columns.geom]))
 		}
 	}
+	return profileWeights, nil
+}
 
-	/* Now prepare order position and importance of each vertex */
-	/* This helps to avade graph.PrepareContractionHierarchies() call */
-	// Read vertices
+// verticesColumns Positions of columns in vertices file. Optional columns are -1 when they are missing
+type verticesColumns struct {
+	id         int
+	orderPos   int
+	importance int
+	geom       int
+}
+
+// parseVerticesHeader Returns positions of columns in vertices file. Header without any known name is treated as legacy one (vertex_id;order_pos;importance;geom)
+func parseVerticesHeader(header []string) (verticesColumns, error) {
+	columns := verticesColumns{id: -1, orderPos: -1, importance: -1, geom: -1}
+	known := map[string]*int{
+		"vertex_id":  &columns.id,
+		"order_pos":  &columns.orderPos,
+		"importance": &columns.importance,
+		"geom":       &columns.geom,
+	}
+	found := 0
+	for i := range header {
+		name := strings.TrimPrefix(strings.TrimSpace(header[i]), "\ufeff")
+		if idx, ok := known[name]; ok && *idx == -1 {
+			*idx = i
+			found++
+		}
+	}
+	if found == 0 {
+		if len(header) < 4 {
+			return verticesColumns{}, fmt.Errorf("not enough columns in legacy header: expected 4, got %d", len(header))
+		}
+		return verticesColumns{id: 0, orderPos: 1, importance: 2, geom: 3}, nil
+	}
+	if columns.id == -1 {
+		return verticesColumns{}, fmt.Errorf("there is no required column 'vertex_id'")
+	}
+	return columns, nil
+}
+
+// readVerticesCSV Reads vertices file: geometry of vertices and their order positions and importance in contraction hierarchy.
+// Returns false when file is missing or some vertices of the graph have no order position
+func (engine *MapEngine) readVerticesCSV(verticesFname string) (bool, error) {
 	fileVertices, err := os.Open(verticesFname)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("Can't open vertices file '%s'", verticesFname))
+		return false, errors.Wrap(err, fmt.Sprintf("Can't open vertices file '%s'", verticesFname))
 	}
 	defer fileVertices.Close()
 	readerVertices := csv.NewReader(fileVertices)
 	readerVertices.Comma = ';'
 
-	// Skip header of CSV-file
-	_, err = readerVertices.Read()
+	header, err := readerVertices.Read()
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("Can't read header of vertices file '%s'", edgesFname))
+		return false, errors.Wrap(err, fmt.Sprintf("Can't read header of vertices file '%s'", verticesFname))
 	}
+	columns, err := parseVerticesHeader(header)
+	if err != nil {
+		return false, errors.Wrap(err, fmt.Sprintf("Can't parse header of vertices file '%s'", verticesFname))
+	}
+	withOrder := columns.orderPos != -1 && columns.importance != -1
+	ordered := make(map[int64]bool, len(engine.graph.Vertices))
 	// Read file line by line
 	for {
 		record, err := readerVertices.Read()
 		if err == io.EOF {
 			break
 		}
-		vertexExternal, err := strconv.ParseInt(record[0], 10, 64)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse a vertex in vertices file. The vertex is '%s'", record[0]))
+			return false, errors.Wrap(err, fmt.Sprintf("Can't read vertices file '%s'", verticesFname))
 		}
-		vertexOrderPos, err := strconv.ParseInt(record[1], 10, 64)
+		vertexExternal, err := strconv.ParseInt(record[columns.id], 10, 64)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse order position of vertex in vertices file. The order pos is '%s'", record[1]))
-		}
-		vertexImportance, err := strconv.Atoi(record[2])
-		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse importance of vertex in vertices file. The importance is '%s'", record[2]))
+			return false, errors.Wrap(err, fmt.Sprintf("Can't parse a vertex in vertices file. The vertex is '%s'", record[columns.id]))
 		}
 		vertexInternal, vertexFound := engine.graph.FindVertex(vertexExternal)
 		if !vertexFound {
-			return fmt.Errorf("vertex with Label = %d is not found in graph", vertexExternal)
+			return false, fmt.Errorf("vertex with Label = %d is not found in graph", vertexExternal)
 		}
-		engine.graph.Vertices[vertexInternal].SetOrderPos(vertexOrderPos)
-		engine.graph.Vertices[vertexInternal].SetImportance(vertexImportance)
-
-		coordinates := record[3]
-		s2Point, err := spatial.WKTToS2PointFeature(coordinates)
+		if withOrder {
+			vertexOrderPos, err := strconv.ParseInt(record[columns.orderPos], 10, 64)
+			if err != nil {
+				return false, errors.Wrap(err, fmt.Sprintf("Can't parse order position of vertex in vertices file. The order pos is '%s'", record[columns.orderPos]))
+			}
+			vertexImportance, err := strconv.Atoi(record[columns.importance])
+			if err != nil {
+				return false, errors.Wrap(err, fmt.Sprintf("Can't parse importance of vertex in vertices file. The importance is '%s'", record[columns.importance]))
+			}
+			engine.graph.Vertices[vertexInternal].SetOrderPos(vertexOrderPos)
+			engine.graph.Vertices[vertexInternal].SetImportance(vertexImportance)
+			ordered[vertexExternal] = true
+		}
+		if columns.geom == -1 {
+			continue
+		}
+		coordinates := record[columns.geom]
+		s2Point, err := spatial.WKTToS2PointFeatureSRID(coordinates, engine.graphSRID)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse WKT geometry of the vertex '%d' | geom = '%s'", vertexExternal, coordinates))
+			return false, errors.Wrap(err, fmt.Sprintf("Can't parse WKT geometry of the vertex '%d' | geom = '%s'", vertexExternal, coordinates))
 		}
 		engine.vertices[vertexExternal] = &spatial.Vertex{
 			ID:    vertexExternal,
 			Point: &s2Point,
 		}
 	}
+	return len(ordered) == len(engine.graph.Vertices), nil
+}
 
-	/* After hierarchies prepared add shortcuts to graph */
-	// Read contractions
+// readShortcutsCSV Reads shortcuts file and adds shortcuts to the graph. Returns number of shortcuts. Missing file gives no shortcuts
+func (engine *MapEngine) readShortcutsCSV(shortcutsFname string) (int, error) {
 	fileShortcuts, err := os.Open(shortcutsFname)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("Can't open shortcuts file '%s'", shortcutsFname))
+		return 0, errors.Wrap(err, fmt.Sprintf("Can't open shortcuts file '%s'", shortcutsFname))
 	}
 	defer fileShortcuts.Close()
 	readerShortcuts := csv.NewReader(fileShortcuts)
@@ -277,56 +584,62 @@ func (engine *MapEngine) extractDataFromCSVs(edgesFname, verticesFname, shortcut
 	// Skip header of CSV-file
 	_, err = readerShortcuts.Read()
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("Can't read header of shortcuts file '%s'", edgesFname))
+		return 0, errors.Wrap(err, fmt.Sprintf("Can't read header of shortcuts file '%s'", shortcutsFname))
 	}
+	shortcutsNum := 0
 	// Read file line by line
 	for {
 		record, err := readerShortcuts.Read()
 		if err == io.EOF {
 			break
 		}
+		if err != nil {
+			return 0, errors.Wrap(err, fmt.Sprintf("Can't read shortcuts file '%s'", shortcutsFname))
+		}
+		if len(record) < 4 {
+			return 0, fmt.Errorf("not enough columns in shortcuts file: expected 4, got %d", len(record))
+		}
 		sourceExternal, err := strconv.ParseInt(record[0], 10, 64)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse source vertex in shortcuts file. The vertex is '%s'", record[0]))
+			return 0, errors.Wrap(err, fmt.Sprintf("Can't parse source vertex in shortcuts file. The vertex is '%s'", record[0]))
 		}
 		targetExternal, err := strconv.ParseInt(record[1], 10, 64)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse target vertex in shortcuts file. The vertex is '%s'", record[1]))
+			return 0, errors.Wrap(err, fmt.Sprintf("Can't parse target vertex in shortcuts file. The vertex is '%s'", record[1]))
 		}
 		weight, err := strconv.ParseFloat(record[2], 64)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse weight of a shortcut in shortcuts file. The weight is '%s'", record[2]))
+			return 0, errors.Wrap(err, fmt.Sprintf("Can't parse weight of a shortcut in shortcuts file. The weight is '%s'", record[2]))
 		}
 		contractionExternal, err := strconv.ParseInt(record[3], 10, 64)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't parse middle vertex of a shortcut in shortcuts file. The weight is '%s'", record[3]))
+			return 0, errors.Wrap(err, fmt.Sprintf("Can't parse middle vertex of a shortcut in shortcuts file. The weight is '%s'", record[3]))
 		}
 		err = engine.graph.AddEdge(sourceExternal, targetExternal, weight)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't add shortcut with source_internal_ID = '%d' and target_internal_ID = '%d'", sourceExternal, targetExternal))
+			return 0, errors.Wrap(err, fmt.Sprintf("Can't add shortcut with source_internal_ID = '%d' and target_internal_ID = '%d'", sourceExternal, targetExternal))
 		}
 		err = engine.graph.AddShortcut(sourceExternal, targetExternal, contractionExternal, weight)
 		if err != nil {
-			return errors.Wrap(err, fmt.Sprintf("Can't add shortcut with source_internal_ID = '%d' and target_internal_ID = '%d' to internal map", sourceExternal, targetExternal))
+			return 0, errors.Wrap(err, fmt.Sprintf("Can't add shortcut with source_internal_ID = '%d' and target_internal_ID = '%d' to internal map", sourceExternal, targetExternal))
 		}
+		shortcutsNum++
 	}
+	return shortcutsNum, nil
+}
 
-	// Finalize import after loading all vertices, edges, and shortcuts (required for ch v1.10.0+)
-	engine.graph.FinalizeImport()
-
-	// Initialize thread-safe query pool for concurrent shortest path queries
-	engine.queryPool = engine.graph.NewQueryPool()
-
-	// Compute weakly connected components for the graph
-	componentsResult := engine.computeWeakConnectedComponents()
-	engine.vertexComponent = componentsResult.VertexComponent
-	engine.bigComponentID = componentsResult.BigComponentID
-
-	// Compute strongly connected components for the graph via Tarjan's algorithm
-	sccResult := engine.computeStrongConnectedComponents()
-	engine.vertexStrongComponent = sccResult.VertexComponent
-	engine.bigStrongComponentID = sccResult.BigComponentID
-	engine.isComponentVerySmall = sccResult.IsComponentVerySmall
-
-	return nil
+// verticesFromEdges Adds vertices missing in engine: their geometry is taken from the first and the last points of edges geometries
+func (engine *MapEngine) verticesFromEdges() {
+	for _, edge := range engine.edges {
+		if edge.Polyline == nil || len(*edge.Polyline) == 0 {
+			continue
+		}
+		polyline := *edge.Polyline
+		if _, ok := engine.vertices[edge.Source]; !ok {
+			engine.vertices[edge.Source] = &spatial.Vertex{ID: edge.Source, Point: &polyline[0]}
+		}
+		if _, ok := engine.vertices[edge.Target]; !ok {
+			engine.vertices[edge.Target] = &spatial.Vertex{ID: edge.Target, Point: &polyline[len(polyline)-1]}
+		}
+	}
 }
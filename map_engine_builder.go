@@ -0,0 +1,265 @@
+package horizon
+
+import (
+	"iter"
+	"math"
+	"slices"
+
+	"github.com/LdDl/ch"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/pkg/errors"
+)
+
+// MapEngineBuilder Collects graph prepared in code (edges, vertices, weight profiles and attributes) in any order and builds ready-to-use MapEngine.
+// Unlike NewMapEngine it validates consistency of the graph, prepares contraction hierarchies when there are no shortcuts and returns an error instead of half-initialised engine.
+//
+// Example:
+//
+//	engine, err := horizon.NewMapEngineBuilder(horizon.WithGraphSRID(0)).
+//		AddVertices(vertices...).
+//		AddEdges(edges...).
+//		Build()
+type MapEngineBuilder struct {
+	engineOpts []func(*MapEngine)
+	graph      *ch.Graph
+	edges      []*spatial.Edge
+	edgeIDs    map[int64]bool
+	vertices   map[int64]*spatial.Vertex
+	attributes map[int64]spatial.EdgeAttributes
+	profiles   []pendingProfile
+	err        error
+}
+
+// pendingProfile Weight profile which is added to engine after edges (see MapEngineBuilder.AddWeightProfile and WithWeightProfile)
+type pendingProfile struct {
+	name    string
+	weights map[int64]float64
+}
+
+// NewMapEngineBuilder Returns pointer to created MapEngineBuilder
+/*
+	opts - options of the engine (e.g. WithStorage, WithGraphSRID, WithEllipsoidalDistances). They are applied before edges and vertices of the builder are added.
+		When there is no storage in options, then it is picked by SRID of the graph (Euclidean for SRID = 0, spherical otherwise).
+		Graph itself should be provided via methods of the builder rather than WithGraph, WithEdges and WithVertices options
+*/
+func NewMapEngineBuilder(opts ...func(*MapEngine)) *MapEngineBuilder {
+	return &MapEngineBuilder{
+		engineOpts: opts,
+		edgeIDs:    make(map[int64]bool),
+		vertices:   make(map[int64]*spatial.Vertex),
+		attributes: make(map[int64]spatial.EdgeAttributes),
+	}
+}
+
+// WithGraph Sets contraction hierarchy for the default profile (e.g. loaded from shortcuts prepared earlier).
+// When graph is not set or it has no shortcuts, then contraction hierarchies are prepared by Build
+func (builder *MapEngineBuilder) WithGraph(graph *ch.Graph) *MapEngineBuilder {
+	builder.graph = graph
+	return builder
+}
+
+// AddEdges Adds edges to the graph. Edge with duplicate ID makes Build fail
+func (builder *MapEngineBuilder) AddEdges(edges ...*spatial.Edge) *MapEngineBuilder {
+	for _, edge := range edges {
+		builder.addEdge(edge)
+	}
+	return builder
+}
+
+// AddEdgesFrom Adds edges provided by iterator (e.g. rows of database query). The first error of the iterator makes Build fail
+func (builder *MapEngineBuilder) AddEdgesFrom(edges iter.Seq2[*spatial.Edge, error]) *MapEngineBuilder {
+	for edge, err := range edges {
+		if err != nil {
+			builder.fail(errors.Wrap(err, "Can't read edges"))
+			break
+		}
+		builder.addEdge(edge)
+	}
+	return builder
+}
+
+// AddVertices Adds vertices to the graph. Vertex with duplicate ID makes Build fail.
+// Vertices which are not added explicitly are taken from the first and the last points of edges geometries
+func (builder *MapEngineBuilder) AddVertices(vertices ...*spatial.Vertex) *MapEngineBuilder {
+	for _, vertex := range vertices {
+		builder.addVertex(vertex)
+	}
+	return builder
+}
+
+// AddVerticesFrom Adds vertices provided by iterator. The first error of the iterator makes Build fail
+func (builder *MapEngineBuilder) AddVerticesFrom(vertices iter.Seq2[*spatial.Vertex, error]) *MapEngineBuilder {
+	for vertex, err := range vertices {
+		if err != nil {
+			builder.fail(errors.Wrap(err, "Can't read vertices"))
+			break
+		}
+		builder.addVertex(vertex)
+	}
+	return builder
+}
+
+// AddWeightProfile Adds named weight profile (see MapEngine.AddWeightProfile). Every edge in weights must be added to the builder
+func (builder *MapEngineBuilder) AddWeightProfile(name string, weights map[int64]float64) *MapEngineBuilder {
+	builder.profiles = append(builder.profiles, pendingProfile{name: name, weights: weights})
+	return builder
+}
+
+// AddEdgeAttributes Adds attributes of edges (see WithEdgeAttributes). Every edge in attributes must be added to the builder
+func (builder *MapEngineBuilder) AddEdgeAttributes(attributes map[int64]spatial.EdgeAttributes) *MapEngineBuilder {
+	for edgeID, edgeAttributes := range attributes {
+		builder.attributes[edgeID] = edgeAttributes
+	}
+	return builder
+}
+
+// Build Validates collected graph and returns MapEngine with prepared spatial index, contraction hierarchies and connected components
+func (builder *MapEngineBuilder) Build() (*MapEngine, error) {
+	if builder.err != nil {
+		return nil, builder.err
+	}
+	engine := newMapEngine(builder.engineOpts...)
+	if !spatial.IsSRIDSupported(engine.graphSRID) {
+		return nil, errors.Wrapf(spatial.ErrUnknownSRID, "Can't build graph with SRID %d", engine.graphSRID)
+	}
+	if engine.storage == nil {
+		if engine.graphSRID == 0 {
+			engine.storage = spatial.NewStorage(spatial.StorageTypeEuclidean)
+		} else {
+			engine.storage = spatial.NewStorage(spatial.StorageTypeSpherical)
+		}
+	}
+	for _, edge := range builder.edges {
+		engine.addEdge(edge)
+	}
+	if len(engine.edges) == 0 {
+		return nil, ErrEmptyGraph
+	}
+
+	// Vertices: explicit ones must have edges, missing ones are taken from edges geometries
+	for _, vertex := range builder.vertices {
+		engine.vertices[vertex.ID] = vertex
+	}
+	connected := make(map[int64]bool, len(engine.vertices))
+	for _, edge := range builder.edges {
+		connected[edge.Source] = true
+		connected[edge.Target] = true
+		polyline := *edge.Polyline
+		if _, ok := engine.vertices[edge.Source]; !ok {
+			engine.vertices[edge.Source] = &spatial.Vertex{ID: edge.Source, Point: &polyline[0]}
+		}
+		if _, ok := engine.vertices[edge.Target]; !ok {
+			engine.vertices[edge.Target] = &spatial.Vertex{ID: edge.Target, Point: &polyline[len(polyline)-1]}
+		}
+	}
+	for vertexID := range engine.vertices {
+		if !connected[vertexID] {
+			return nil, errors.Wrapf(ErrInconsistentGraph, "vertex %d has no edges", vertexID)
+		}
+	}
+
+	// Contraction hierarchy of the default profile
+	if builder.graph != nil {
+		for _, edge := range builder.edges {
+			for _, vertexID := range []int64{edge.Source, edge.Target} {
+				if _, ok := builder.graph.FindVertex(vertexID); !ok {
+					return nil, errors.Wrapf(ErrInconsistentGraph, "vertex %d of edge %d is not found in contraction hierarchy", vertexID, edge.ID)
+				}
+			}
+		}
+		if builder.graph.GetShortcutsNum() == 0 {
+			builder.graph.PrepareContractionHierarchies()
+		}
+		engine.graph = *builder.graph
+	} else {
+		graph := ch.Graph{}
+		for _, edge := range builder.edges {
+			err := graph.CreateVertex(edge.Source)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Can't add source vertex %d", edge.Source)
+			}
+			err = graph.CreateVertex(edge.Target)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Can't add target vertex %d", edge.Target)
+			}
+			err = graph.AddEdge(edge.Source, edge.Target, edge.Weight)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Can't add edge: from_vertex_id = '%d' | to_vertex_id = '%d'", edge.Source, edge.Target)
+			}
+		}
+		graph.PrepareContractionHierarchies()
+		engine.graph = graph
+	}
+	engine.queryPool = engine.graph.NewQueryPool()
+
+	for _, edge := range builder.edges {
+		err := engine.storage.AddEdge(uint64(edge.ID), edge)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't add edge %d to spatial index", edge.ID)
+		}
+	}
+	engine.computeComponents()
+
+	// Profiles of WithWeightProfile options are checked the same way as the builder's ones
+	profiles := slices.Concat(builder.profiles, engine.pendingProfiles)
+	engine.pendingProfiles = nil
+	for _, profile := range profiles {
+		for edgeID := range profile.weights {
+			if _, ok := engine.edges[edgeID]; !ok {
+				return nil, errors.Wrapf(ErrInconsistentGraph, "edge %d of weight profile '%s' is not found", edgeID, profile.name)
+			}
+		}
+		err := engine.AddWeightProfile(profile.name, profile.weights)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't prepare weight profile '%s'", profile.name)
+		}
+	}
+	for edgeID := range builder.attributes {
+		if _, ok := engine.edges[edgeID]; !ok {
+			return nil, errors.Wrapf(ErrInconsistentGraph, "edge %d with attributes is not found", edgeID)
+		}
+	}
+	WithEdgeAttributes(builder.attributes)(engine)
+	return engine, nil
+}
+
+// addEdge Validates the edge and keeps it for Build
+func (builder *MapEngineBuilder) addEdge(edge *spatial.Edge) {
+	switch {
+	case edge == nil:
+		builder.fail(errors.Wrap(ErrInconsistentGraph, "edge is nil"))
+		return
+	case edge.Polyline == nil || len(*edge.Polyline) < 2:
+		builder.fail(errors.Wrapf(ErrInconsistentGraph, "edge %d should have at least 2 points of geometry", edge.ID))
+		return
+	case edge.Weight < 0 || math.IsNaN(edge.Weight) || math.IsInf(edge.Weight, 0):
+		builder.fail(errors.Wrapf(ErrInconsistentGraph, "edge %d has invalid weight %f", edge.ID, edge.Weight))
+		return
+	}
+	if builder.edgeIDs[edge.ID] {
+		builder.fail(errors.Wrapf(ErrInconsistentGraph, "duplicate edge %d", edge.ID))
+		return
+	}
+	builder.edgeIDs[edge.ID] = true
+	builder.edges = append(builder.edges, edge)
+}
+
+// addVertex Validates the vertex and keeps it for Build
+func (builder *MapEngineBuilder) addVertex(vertex *spatial.Vertex) {
+	if vertex == nil || vertex.Point == nil {
+		builder.fail(errors.Wrap(ErrInconsistentGraph, "vertex without geometry"))
+		return
+	}
+	if _, ok := builder.vertices[vertex.ID]; ok {
+		builder.fail(errors.Wrapf(ErrInconsistentGraph, "duplicate vertex %d", vertex.ID))
+		return
+	}
+	builder.vertices[vertex.ID] = vertex
+}
+
+// fail Keeps the first error. It is returned by Build
+func (builder *MapEngineBuilder) fail(err error) {
+	if builder.err == nil {
+		builder.err = err
+	}
+}
@@ -0,0 +1,157 @@
+package horizon
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// builderTestEdges Returns two-way road 0 <-> 1 <-> 2 <-> 3 along X axis (edge i*10+j goes from vertex i to vertex j) and one-way dead-end 3 -> 4
+func builderTestEdges() []*spatial.Edge {
+	points := map[int64][2]float64{0: {0, 0}, 1: {5, 0}, 2: {10, 0}, 3: {15, 0}, 4: {15, 5}}
+	pairs := [][2]int64{{0, 1}, {1, 0}, {1, 2}, {2, 1}, {2, 3}, {3, 2}, {3, 4}}
+	edges := make([]*spatial.Edge, 0, len(pairs))
+	for _, pair := range pairs {
+		source, target := points[pair[0]], points[pair[1]]
+		polyline := s2.Polyline{
+			spatial.NewEuclideanS2Point(source[0], source[1]),
+			spatial.NewEuclideanS2Point(target[0], target[1]),
+		}
+		edges = append(edges, &spatial.Edge{
+			ID:       pair[0]*10 + pair[1],
+			Source:   pair[0],
+			Target:   pair[1],
+			Weight:   math.Hypot(target[0]-source[0], target[1]-source[1]),
+			Polyline: &polyline,
+		})
+	}
+	return edges
+}
+
+func TestMapEngineBuilder(t *testing.T) {
+	edges := builderTestEdges()
+	point := spatial.NewEuclideanS2Point(0, 0)
+	engine, err := NewMapEngineBuilder(WithGraphSRID(0)).
+		AddVertices(&spatial.Vertex{ID: 0, Point: &point}).
+		AddEdgesFrom(func(yield func(*spatial.Edge, error) bool) {
+			for _, edge := range edges {
+				if !yield(edge, nil) {
+					return
+				}
+			}
+		}).
+		AddWeightProfile("travel_time", map[int64]float64{1: 10, 10: 10, 12: 10, 21: 10}).
+		AddEdgeAttributes(map[int64]spatial.EdgeAttributes{34: {"highway": "service"}}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !engine.isEuclidean() {
+		t.Errorf("Euclidean storage should be picked for SRID = 0, but got %T", engine.storage)
+	}
+	if len(engine.vertices) != 5 || engine.vertices[0].Point != &point {
+		t.Errorf("Explicit vertex should be kept and missing ones should be taken from edges, but got %v", engine.vertices)
+	}
+	if len(engine.graph.Vertices) != 5 {
+		t.Errorf("Contraction hierarchy should be prepared for 5 vertices, but got %d", len(engine.graph.Vertices))
+	}
+	if len(engine.vertexStrongComponent) != 5 || len(engine.vertexComponent) != 5 {
+		t.Fatalf("Components should be evaluated for every vertex, but got %v and %v", engine.vertexStrongComponent, engine.vertexComponent)
+	}
+	if engine.vertexStrongComponent[0] != engine.vertexStrongComponent[3] || engine.vertexStrongComponent[4] == engine.vertexStrongComponent[3] {
+		t.Errorf("Vertices 0-3 should be in the same strong component and dead-end vertex 4 should not, but got %v", engine.vertexStrongComponent)
+	}
+	if engine.bigStrongComponentID != engine.vertexStrongComponent[0] {
+		t.Errorf("Biggest strong component should be %d, but got %d", engine.vertexStrongComponent[0], engine.bigStrongComponentID)
+	}
+	if attributes, _ := engine.EdgeAttributes(34); attributes["highway"] != "service" {
+		t.Errorf("Edge 34 should have attributes, but got %v", attributes)
+	}
+	if len(engine.Profiles()) != 2 {
+		t.Errorf("Engine should have 2 profiles, but got %v", engine.Profiles())
+	}
+
+	matcher := NewMapMatcher(WithMapEngine(engine))
+	nearest, err := matcher.Nearest(NewGPSMeasurementFromID(1, 7, 1, 0), 1, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nearest) != 1 || (nearest[0].Edge.ID != 12 && nearest[0].Edge.ID != 21) {
+		t.Errorf("Nearest edge should be 12 or 21, but got %v", nearest)
+	}
+	if nearest[0].StrongComponent != engine.bigStrongComponentID {
+		t.Errorf("Nearest edge should be in strong component %d, but got %d", engine.bigStrongComponentID, nearest[0].StrongComponent)
+	}
+	result, err := matcher.FindShortestPath(NewGPSMeasurementFromID(1, 2.6, 0.1, 0), NewGPSMeasurementFromID(2, 12.4, 0.1, 0), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source, target := result.SubMatches[0].Observations[0].MatchedEdge.ID, result.SubMatches[0].Observations[1].MatchedEdge.ID; source != 1 || target != 23 {
+		t.Errorf("Path should go from edge 1 to edge 23, but got %d and %d", source, target)
+	}
+}
+
+func TestMapEngineBuilderValidation(t *testing.T) {
+	point := spatial.NewEuclideanS2Point(100, 100)
+	negative := *builderTestEdges()[0]
+	negative.ID = 100
+	negative.Weight = -1
+	cases := []struct {
+		name    string
+		builder *MapEngineBuilder
+	}{
+		{"duplicate edge", NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(builderTestEdges()...).AddEdges(builderTestEdges()[0])},
+		{"edge without geometry", NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(&spatial.Edge{ID: 1, Source: 0, Target: 1})},
+		{"negative weight", NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(&negative)},
+		{"isolated vertex", NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(builderTestEdges()...).AddVertices(&spatial.Vertex{ID: 100, Point: &point})},
+		{"vertex without geometry", NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(builderTestEdges()...).AddVertices(&spatial.Vertex{ID: 1})},
+		{"unknown edge of profile", NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(builderTestEdges()...).AddWeightProfile("travel_time", map[int64]float64{100: 1})},
+		{"unknown edge of attributes", NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(builderTestEdges()...).AddEdgeAttributes(map[int64]spatial.EdgeAttributes{100: {"highway": "primary"}})},
+	}
+	for _, c := range cases {
+		engine, err := c.builder.Build()
+		if errors.Cause(err) != ErrInconsistentGraph {
+			t.Errorf("Case '%s': expected error '%v', but got '%v'", c.name, ErrInconsistentGraph, err)
+		}
+		if engine != nil {
+			t.Errorf("Case '%s': engine should be nil", c.name)
+		}
+	}
+
+	_, err := NewMapEngineBuilder().Build()
+	if err != ErrEmptyGraph {
+		t.Errorf("Expected error '%v', but got '%v'", ErrEmptyGraph, err)
+	}
+	readErr := fmt.Errorf("connection lost")
+	_, err = NewMapEngineBuilder(WithGraphSRID(0)).AddEdgesFrom(func(yield func(*spatial.Edge, error) bool) {
+		if !yield(builderTestEdges()[0], nil) {
+			return
+		}
+		yield(nil, readErr)
+	}).Build()
+	if errors.Cause(err) != readErr {
+		t.Errorf("Expected error '%v', but got '%v'", readErr, err)
+	}
+}
+
+func TestNewMapEngineOptionsOrder(t *testing.T) {
+	// Storage is set after edges: edges still must be indexed
+	engine := NewMapEngine(
+		WithEdges(builderTestEdges()),
+		WithStorage(spatial.NewStorage(spatial.StorageTypeEuclidean)),
+	)
+	nearest, err := engine.storage.FindNearest(spatial.NewEuclideanS2Point(15, 3), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nearest) != 1 || nearest[0].EdgeID != 34 {
+		t.Errorf("Nearest edge should be 34, but got %v", nearest)
+	}
+	if len(engine.vertexStrongComponent) != 5 {
+		t.Errorf("Components should be evaluated for every vertex, but got %v", engine.vertexStrongComponent)
+	}
+}
@@ -5,11 +5,11 @@ import (
 	"math"
 	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/LdDl/ch"
 	"github.com/LdDl/horizon/spatial"
 	"github.com/LdDl/viterbi"
-	"github.com/golang/geo/s2"
 	"github.com/pkg/errors"
 )
 
@@ -23,11 +23,21 @@ const (
 	hmmParams - parameters of Hidden Markov Model
 	engine - wrapper around MapEngine (for KNN and finding shortest path problems)
 	viterbiSemaphore - limits concurrent Viterbi computations globally
+	info - information about initial graph
+	active - graph which replaced initial one (see Reload). Nil if graph has never been replaced
+	reloadMu - serializes replacements of graph and changes of facilities
+	reloading - whether graph is being loaded by Reload
+	snapshot - whether matcher is a snapshot pinned to a single graph (see Snapshot)
 */
 type MapMatcher struct {
 	hmmParams        *HmmProbabilities
 	engine           *MapEngine
 	viterbiSemaphore chan struct{}
+	info             GraphInfo
+	active           atomic.Pointer[graphVersion]
+	reloadMu         sync.Mutex
+	reloading        atomic.Bool
+	snapshot         bool
 }
 
 // NewMapMatcherDefault Returns pointer to created MapMatcher with default parameters
@@ -35,6 +45,7 @@ func NewMapMatcherDefault() *MapMatcher {
 	return &MapMatcher{
 		hmmParams:        HmmProbabilitiesDefault(),
 		viterbiSemaphore: make(chan struct{}, runtime.NumCPU()),
+		info:             GraphInfo{Version: 1, LoadedAt: time.Now()},
 	}
 }
 
@@ -48,32 +59,32 @@ func NewMapMatcherDefault() *MapMatcher {
 	    Format: from_vertex_id;to_vertex_id;weight;geom;was_one_way;edge_id
 	    geom is GeoJSON LineString
 
-	  - {prefix}_vertices.csv - vertices file (required)
+	  - {prefix}_vertices.csv - vertices file (optional)
 	    Format: vertex_id;order_pos;importance;geom
-	    geom is GeoJSON Point
+	    geom is GeoJSON Point. Vertices missing in the file get geometry from endpoints of edges
 	    order_pos and importance are used for contraction hierarchies
 
-	  - {prefix}_shortcuts.csv - shortcuts file (required, can be empty with header only)
+	  - {prefix}_shortcuts.csv - shortcuts file (optional, can be empty with header only)
 	    Format: from_vertex_id;to_vertex_id;weight;via_vertex_id
 	    These are precomputed contraction hierarchy shortcuts.
-	    If empty, shortcuts will be computed via PrepareContractionHierarchies()
+
+	  If there are no order positions of vertices or no shortcuts, then contraction hierarchies are prepared via PrepareContractionHierarchies() on load.
+	  Use PrepareShortcuts to do it once and write both files
 
 	Example: if edgesFilename is "./data/roads.csv", it will look for:
 	  - ./data/roads.csv
 	  - ./data/roads_vertices.csv
 	  - ./data/roads_shortcuts.csv
+
+	engineOpts - optional MapEngine options applied before loading (e.g. WithWeightProfiles for additional weight columns)
 */
-func NewMapMatcherFromFiles(props *HmmProbabilities, edgesFilename string) (*MapMatcher, error) {
-	mm := &MapMatcher{
-		hmmParams:        props,
-		viterbiSemaphore: make(chan struct{}, runtime.NumCPU()),
-	}
-	mapEngine, err := prepareEngine(edgesFilename)
+func NewMapMatcherFromFiles(props *HmmProbabilities, edgesFilename string, engineOpts ...func(*MapEngine)) (*MapMatcher, error) {
+	st := time.Now()
+	mapEngine, err := prepareEngine(edgesFilename, engineOpts...)
 	if err != nil {
 		return nil, err
 	}
-	mm.engine = mapEngine
-	return mm, nil
+	return NewMapMatcher(WithHmmParameters(props), WithMapEngine(mapEngine), WithGraphSource(edgesFilename, time.Since(st))), nil
 }
 
 // NewMapMatcher returns pointer to created MapMatcher with provided options
@@ -81,6 +92,7 @@ func NewMapMatcher(ops ...func(*MapMatcher)) *MapMatcher {
 	mm := &MapMatcher{
 		hmmParams:        HmmProbabilitiesDefault(),
 		viterbiSemaphore: make(chan struct{}, runtime.NumCPU()),
+		info:             GraphInfo{Version: 1, LoadedAt: time.Now()},
 	}
 	for _, op := range ops {
 		op(mm)
@@ -102,6 +114,28 @@ func WithMapEngine(engine *MapEngine) func(*MapMatcher) {
 	}
 }
 
+// WithGraphSource sets source of the initial graph reported by GraphInfo
+/*
+	source - where graph has been loaded from (e.g. filename)
+	loadDuration - time spent for loading of graph
+*/
+func WithGraphSource(source string, loadDuration time.Duration) func(*MapMatcher) {
+	return func(matcher *MapMatcher) {
+		matcher.info.Source = source
+		matcher.info.LoadDuration = loadDuration
+	}
+}
+
+// Profiles Returns names of available weight profiles (see MapEngine.Profiles)
+func (matcher *MapMatcher) Profiles() []string {
+	return matcher.Engine().Profiles()
+}
+
+// HasProfile Checks if weight profile with given name exists (see MapEngine.HasProfile)
+func (matcher *MapMatcher) HasProfile(name string) bool {
+	return matcher.Engine().HasProfile(name)
+}
+
 // Segment represents a continuous matched segment to process separately (split at break points)
 type Segment struct {
 	// First observation index in this segment
@@ -141,11 +175,25 @@ type indexedSubMatch struct {
 	gpsMeasurements - Observations
 	statesRadiusMeters - maximum radius to search nearest polylines
 	maxStates - maximum of corresponding states
+	opts - per-request options (see QueryOptions)
 */
-func (matcher *MapMatcher) Run(gpsMeasurements []*GPSMeasurement, statesRadiusMeters float64, maxStates int) (MatcherResult, error) {
+func (matcher *MapMatcher) Run(gpsMeasurements []*GPSMeasurement, statesRadiusMeters float64, maxStates int, opts ...QueryOption) (MatcherResult, error) {
+	return matcher.run(matcher.Engine(), gpsMeasurements, statesRadiusMeters, maxStates, opts...)
+}
+
+// run Does map matching on the given engine (see Run)
+func (matcher *MapMatcher) run(engine *MapEngine, gpsMeasurements []*GPSMeasurement, statesRadiusMeters float64, maxStates int, opts ...QueryOption) (MatcherResult, error) {
+	err := checkMeasurementsSRID(gpsMeasurements...)
+	if err != nil {
+		return MatcherResult{}, err
+	}
 	if len(gpsMeasurements) < 3 {
 		return MatcherResult{}, ErrMinumimGPSMeasurements
 	}
+	query, err := engine.prepareQuery(opts...)
+	if err != nil {
+		return MatcherResult{}, errors.Wrap(err, "Can't prepare query")
+	}
 
 	stateID := 0
 	layers := []RoadPositions{}
@@ -163,13 +211,14 @@ func (matcher *MapMatcher) Run(gpsMeasurements []*GPSMeasurement, statesRadiusMe
 		var closest []spatial.NearestObject
 		var err error
 		if statesRadiusMeters < 0 {
-			closest, err = matcher.engine.storage.FindNearest(gpsMeasurements[i].Point, maxStates)
+			closest, err = engine.storage.FindNearest(gpsMeasurements[i].Point, maxStates)
 		} else {
-			closest, err = matcher.engine.storage.FindNearestInRadius(gpsMeasurements[i].Point, statesRadiusMeters, maxStates)
+			closest, err = engine.storage.FindNearestInRadius(gpsMeasurements[i].Point, statesRadiusMeters, maxStates)
 		}
 		if err != nil {
 			return MatcherResult{}, errors.Wrapf(err, "Can't find neighbors for point: '%s' (states radius = %f, max states = %d)", gpsMeasurements[i].Point, statesRadiusMeters, maxStates)
 		}
+		closest = query.filterNearest(closest)
 		if len(closest) == 0 {
 			// Track unmatched observation instead of just skipping as it done before
 			unmatchedObservations = append(unmatchedObservations, unmatchedObs{
@@ -206,29 +255,12 @@ func (matcher *MapMatcher) Run(gpsMeasurements []*GPSMeasurement, statesRadiusMe
 		closest := closestSets[i]
 		localStates := make(RoadPositions, len(closest))
 		for j := range closest {
-			s2polyline := matcher.engine.storage.GetEdge(closest[j].EdgeID)
-			m := s2polyline.Source
-			n := s2polyline.Target
-			edge := matcher.engine.edges[m][n]
-
-			// Use appropriate projection based on SRID
-			var proj s2.Point
-			var fraction float64
-			var next int
-			var lon, lat float64
-
-			if srid == 4326 {
-				// Spherical geometry (WGS84)
-				proj, fraction, next = spatial.CalcProjection(*edge.Polyline, s2point)
-				latLng := s2.LatLngFromPoint(proj)
-				lon = latLng.Lng.Degrees()
-				lat = latLng.Lat.Degrees()
-			} else {
-				// Euclidean geometry (SRID=0 or other)
-				proj, fraction, next = spatial.CalcProjectionEuclidean(*edge.Polyline, s2point)
-				lon = proj.Vector.X
-				lat = proj.Vector.Y
-			}
+			edge := engine.edges[int64(closest[j].EdgeID)]
+			m := edge.Source
+			n := edge.Target
+
+			// Use appropriate projection based on geometry of the engine: points of projected CRS are stored on sphere as WGS84 ones
+			proj, fraction, next := engine.calcProjection(*edge.Polyline, s2point)
 
 			pickedGraphVertex := m
 			routingGraphVertex := m
@@ -241,9 +273,10 @@ func (matcher *MapMatcher) Run(gpsMeasurements []*GPSMeasurement, statesRadiusMe
 			if i == 0 {
 				routingGraphVertex = n
 			}
-			roadPos := NewRoadPositionFromLonLat(stateID, pickedGraphVertex, routingGraphVertex, edge, lon, lat, srid)
-			roadPos.beforeProjection = edge.Weight * fraction
-			roadPos.afterProjection = edge.Weight * (1 - fraction)
+			roadPos := NewRoadPositionFromS2Point(stateID, pickedGraphVertex, routingGraphVertex, edge, proj, srid)
+			edgeWeight := query.weight(edge)
+			roadPos.beforeProjection = edgeWeight * fraction
+			roadPos.afterProjection = edgeWeight * (1 - fraction)
 			roadPos.next = next
 			localStates[j] = roadPos
 			stateID++
@@ -272,19 +305,19 @@ func (matcher *MapMatcher) Run(gpsMeasurements []*GPSMeasurement, statesRadiusMe
 			for n := range currentStates {
 				if prevStates[m].RoutingGraphVertex == currentStates[n].RoutingGraphVertex {
 					if prevStates[m].GraphEdge.ID == currentStates[n].GraphEdge.ID {
-						ans := prevStates[m].Projected.DistanceTo(currentStates[n].Projected)
+						ans := query.partialWeight(prevStates[m].GraphEdge, engine.geoDistance(prevStates[m].Projected, currentStates[n].Projected))
 						chRoutes[prevStates[m].RoadPositionID][currentStates[n].RoadPositionID] = []int64{prevStates[m].GraphEdge.Source, prevStates[m].GraphEdge.Target}
 						currentRouteLengths.AddRouteLength(prevStates[m], currentStates[n], ans)
 					} else {
 						// We should jump to source vertex of current state, since edges are not the same
-						rawCost, rawPath := getCachedPath(matcher.engine.queryPool, vertexCache, matcher.engine.vertexStrongComponent, prevStates[m].RoutingGraphVertex, currentStates[n].GraphEdge.Source)
+						rawCost, rawPath := getCachedPath(query, vertexCache, engine.vertexStrongComponent, prevStates[m].RoutingGraphVertex, currentStates[n].GraphEdge.Source)
 						var finalCost float64
 						var finalPath []int64
 						if rawCost < 0 {
 							finalCost = math.MaxFloat64
 						} else {
 							// Apply candidate-specific penalty and copy path to avoid mutating cache
-							finalCost = rawCost + currentStates[n].GraphEdge.Weight
+							finalCost = rawCost + query.weight(currentStates[n].GraphEdge)
 							finalPath = make([]int64, len(rawPath), len(rawPath)+1)
 							copy(finalPath, rawPath)
 							finalPath = append(finalPath, currentStates[n].GraphEdge.Target)
@@ -294,7 +327,7 @@ func (matcher *MapMatcher) Run(gpsMeasurements []*GPSMeasurement, statesRadiusMe
 					}
 					continue
 				}
-				rawCost, rawPath := getCachedPath(matcher.engine.queryPool, vertexCache, matcher.engine.vertexStrongComponent, prevStates[m].RoutingGraphVertex, currentStates[n].RoutingGraphVertex)
+				rawCost, rawPath := getCachedPath(query, vertexCache, engine.vertexStrongComponent, prevStates[m].RoutingGraphVertex, currentStates[n].RoutingGraphVertex)
 
 				var finalCost float64
 				var finalPath []int64
@@ -302,7 +335,7 @@ func (matcher *MapMatcher) Run(gpsMeasurements []*GPSMeasurement, statesRadiusMe
 					finalCost = math.MaxFloat64
 				} else {
 					// Apply candidate-specific penalty and copy path to avoid mutating cache
-					finalCost = rawCost + currentStates[n].GraphEdge.Weight
+					finalCost = rawCost + query.weight(currentStates[n].GraphEdge)
 					finalPath = make([]int64, len(rawPath), len(rawPath)+1)
 					copy(finalPath, rawPath)
 					finalPath = append(finalPath, currentStates[n].GraphEdge.Target)
@@ -382,7 +415,7 @@ func (matcher *MapMatcher) Run(gpsMeasurements []*GPSMeasurement, statesRadiusMe
 				if segmentObsState[0].Observation.accuracy > 0 {
 					sigma = segmentObsState[0].Observation.accuracy
 				}
-				distance := bestCandidate.Projected.DistanceTo(segmentObsState[0].Observation.GeoPoint)
+				distance := engine.geoDistance(bestCandidate.Projected, segmentObsState[0].Observation.GeoPoint)
 				emissionLogProb := LogNormalDistribution(sigma, distance)
 				results[i] = viterbiResult{
 					vpath: viterbi.ViterbiPath{
@@ -393,7 +426,7 @@ func (matcher *MapMatcher) Run(gpsMeasurements []*GPSMeasurement, statesRadiusMe
 				return
 			}
 
-			v, err := matcher.PrepareViterbi(segmentObsState, seg.routeLengths, segmentGPS)
+			v, err := matcher.prepareViterbi(engine, segmentObsState, seg.routeLengths, segmentGPS)
 			if err != nil {
 				results[i] = viterbiResult{err: err}
 				return
@@ -435,7 +468,7 @@ func (matcher *MapMatcher) Run(gpsMeasurements []*GPSMeasurement, statesRadiusMe
 			}
 		}
 
-		subMatch := matcher.prepareSubMatch(results[i].vpath, segmentGPS, segmentLayers, chRoutes)
+		subMatch := engine.prepareSubMatch(query, results[i].vpath, segmentGPS, segmentLayers, chRoutes)
 		subMatches = append(subMatches, subMatch)
 	}
 
@@ -501,6 +534,11 @@ func (matcher *MapMatcher) Run(gpsMeasurements []*GPSMeasurement, statesRadiusMe
 	gpsMeasurements - set of Observations
 */
 func (matcher *MapMatcher) PrepareViterbi(obsStates []*CandidateLayer, routeLengths map[int]map[int]float64, gpsMeasurements []*GPSMeasurement) (*viterbi.Viterbi, error) {
+	return matcher.prepareViterbi(matcher.Engine(), obsStates, routeLengths, gpsMeasurements)
+}
+
+// prepareViterbi Prepares Viterbi's algorithm on the given engine (see PrepareViterbi)
+func (matcher *MapMatcher) prepareViterbi(engine *MapEngine, obsStates []*CandidateLayer, routeLengths map[int]map[int]float64, gpsMeasurements []*GPSMeasurement) (*viterbi.Viterbi, error) {
 	v := viterbi.New()
 
 	statesIndx := make(map[int]int)
@@ -537,7 +575,7 @@ func (matcher *MapMatcher) PrepareViterbi(obsStates []*CandidateLayer, routeLeng
 	// @todo Refactor data prerapartion for Viterbi's algorithm
 	for i := range gpsMeasurements {
 		currentLayer := obsStates[i]
-		matcher.computeEmissionLogProbabilities(currentLayer)
+		matcher.computeEmissionLogProbabilities(engine, currentLayer)
 		// @experimental
 		// currentLayer.EmissionLogProbabilities = softmaxEmissions(currentLayer.EmissionLogProbabilities)
 		if i == 0 {
@@ -551,7 +589,7 @@ func (matcher *MapMatcher) PrepareViterbi(obsStates []*CandidateLayer, routeLeng
 				fmt.Println()
 			}
 		} else {
-			err := matcher.computeTransitionLogProbabilities(prevLayer, currentLayer, routeLengths)
+			err := matcher.computeTransitionLogProbabilities(engine, prevLayer, currentLayer, routeLengths)
 			if err != nil {
 				return nil, err
 			}
@@ -592,7 +630,7 @@ func (matcher *MapMatcher) PrepareViterbi(obsStates []*CandidateLayer, routeLeng
 /*
 	layer - wrapper of Observation
 */
-func (matcher *MapMatcher) computeEmissionLogProbabilities(layer *CandidateLayer) {
+func (matcher *MapMatcher) computeEmissionLogProbabilities(engine *MapEngine, layer *CandidateLayer) {
 	// Use observation's accuracy if provided, else default sigma
 	sigma := matcher.hmmParams.sigma
 	if layer.Observation.accuracy > 0 {
@@ -600,7 +638,7 @@ func (matcher *MapMatcher) computeEmissionLogProbabilities(layer *CandidateLayer
 	}
 
 	for i := range layer.States {
-		distance := layer.States[i].Projected.DistanceTo(layer.Observation.GeoPoint)
+		distance := engine.geoDistance(layer.States[i].Projected, layer.Observation.GeoPoint)
 		emissionLogProb := LogNormalDistribution(sigma, distance)
 		layer.AddEmissionProbability(layer.States[i], emissionLogProb)
 	}
@@ -611,8 +649,8 @@ func (matcher *MapMatcher) computeEmissionLogProbabilities(layer *CandidateLayer
 	prevLayer - previous Observation
 	currentLayer - current Observation
 */
-func (matcher *MapMatcher) computeTransitionLogProbabilities(prevLayer, currentLayer *CandidateLayer, routeLengths map[int]map[int]float64) error {
-	straightDistance := prevLayer.Observation.GeoPoint.DistanceTo(currentLayer.Observation.GeoPoint)
+func (matcher *MapMatcher) computeTransitionLogProbabilities(engine *MapEngine, prevLayer, currentLayer *CandidateLayer, routeLengths map[int]map[int]float64) error {
+	straightDistance := engine.geoDistance(prevLayer.Observation.GeoPoint, currentLayer.Observation.GeoPoint)
 	timeDiff := currentLayer.Observation.dateTime.Sub(prevLayer.Observation.dateTime).Seconds()
 	for i := range prevLayer.States {
 		from := prevLayer.States[i]
@@ -656,7 +694,7 @@ func isBreakPoint(prevStates, currentStates RoadPositions, chRoutes map[int]map[
 
 // getCachedPath is a helper function to get or compute shortest path with caching
 // It uses SCC (Strongly Connected Components) to quickly reject impossible routes
-func getCachedPath(queryPool *ch.QueryPool, vertexCache map[int64]map[int64]cachedRoute, vertexSCC map[int64]int64, fromVertex, toVertex int64) (float64, []int64) {
+func getCachedPath(query *routingQuery, vertexCache map[int64]map[int64]cachedRoute, vertexSCC map[int64]int64, fromVertex, toVertex int64) (float64, []int64) {
 	// SCC check: if vertices are in different SCCs, no path exists
 	fromSCC, fromOK := vertexSCC[fromVertex]
 	toSCC, toOK := vertexSCC[toVertex]
@@ -671,8 +709,8 @@ func getCachedPath(queryPool *ch.QueryPool, vertexCache map[int64]map[int64]cach
 			return cached.cost, cached.path
 		}
 	}
-	// Compute and cache using thread-safe query pool
-	rawCost, rawPath := queryPool.ShortestPath(fromVertex, toVertex)
+	// Compute and cache using thread-safe query pool (or Dijkstra's fallback when some edges are excluded)
+	rawCost, rawPath := query.shortestPath(fromVertex, toVertex)
 	if vertexCache[fromVertex] == nil {
 		vertexCache[fromVertex] = make(map[int64]cachedRoute)
 	}
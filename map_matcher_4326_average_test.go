@@ -53,16 +53,16 @@ func TestMapMatcher_4326BIG(t *testing.T) {
 		t.Error(err)
 	}
 
-	correctStates.SubMatches[0].Observations[0].MatchedEdge = *matcher.engine.edges[13640][13641]
-	correctStates.SubMatches[0].Observations[1].MatchedEdge = *matcher.engine.edges[13650][13651]
-	correctStates.SubMatches[0].Observations[2].MatchedEdge = *matcher.engine.edges[13659][13660]
-	correctStates.SubMatches[0].Observations[3].MatchedEdge = *matcher.engine.edges[13661][13662]
-	correctStates.SubMatches[0].Observations[4].MatchedEdge = *matcher.engine.edges[13663][13664]
-	correctStates.SubMatches[0].Observations[5].MatchedEdge = *matcher.engine.edges[13664][13665]
-	correctStates.SubMatches[0].Observations[6].MatchedEdge = *matcher.engine.edges[13665][13666]
-	correctStates.SubMatches[0].Observations[7].MatchedEdge = *matcher.engine.edges[16784][16785]
-	correctStates.SubMatches[0].Observations[8].MatchedEdge = *matcher.engine.edges[16788][16789]
-	correctStates.SubMatches[0].Observations[9].MatchedEdge = *matcher.engine.edges[32639][32640]
+	correctStates.SubMatches[0].Observations[0].MatchedEdge = *matcher.engine.edgesBetween(13640, 13641)[0]
+	correctStates.SubMatches[0].Observations[1].MatchedEdge = *matcher.engine.edgesBetween(13650, 13651)[0]
+	correctStates.SubMatches[0].Observations[2].MatchedEdge = *matcher.engine.edgesBetween(13659, 13660)[0]
+	correctStates.SubMatches[0].Observations[3].MatchedEdge = *matcher.engine.edgesBetween(13661, 13662)[0]
+	correctStates.SubMatches[0].Observations[4].MatchedEdge = *matcher.engine.edgesBetween(13663, 13664)[0]
+	correctStates.SubMatches[0].Observations[5].MatchedEdge = *matcher.engine.edgesBetween(13664, 13665)[0]
+	correctStates.SubMatches[0].Observations[6].MatchedEdge = *matcher.engine.edgesBetween(13665, 13666)[0]
+	correctStates.SubMatches[0].Observations[7].MatchedEdge = *matcher.engine.edgesBetween(16784, 16785)[0]
+	correctStates.SubMatches[0].Observations[8].MatchedEdge = *matcher.engine.edgesBetween(16788, 16789)[0]
+	correctStates.SubMatches[0].Observations[9].MatchedEdge = *matcher.engine.edgesBetween(32639, 32640)[0]
 
 	statesRadiusMeters := -1.0
 	maxStates := 5
@@ -47,10 +47,10 @@ func TestMapMatcherSRID_4326(t *testing.T) {
 		t.Error(err)
 	}
 
-	correctStates.SubMatches[0].Observations[0].MatchedEdge = *matcher.engine.edges[101][102]
-	correctStates.SubMatches[1].Observations[0].MatchedEdge = *matcher.engine.edges[101][102]
-	correctStates.SubMatches[1].Observations[1].MatchedEdge = *matcher.engine.edges[101][102]
-	correctStates.SubMatches[1].Observations[2].MatchedEdge = *matcher.engine.edges[102][105]
+	correctStates.SubMatches[0].Observations[0].MatchedEdge = *matcher.engine.edgesBetween(101, 102)[0]
+	correctStates.SubMatches[1].Observations[0].MatchedEdge = *matcher.engine.edgesBetween(101, 102)[0]
+	correctStates.SubMatches[1].Observations[1].MatchedEdge = *matcher.engine.edgesBetween(101, 102)[0]
+	correctStates.SubMatches[1].Observations[2].MatchedEdge = *matcher.engine.edgesBetween(102, 105)[0]
 
 	statesRadiusMeters := 7.0
 	maxStates := 5
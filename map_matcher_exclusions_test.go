@@ -0,0 +1,136 @@
+package horizon
+
+import (
+	"testing"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+func TestFindShortestPathExclusions(t *testing.T) {
+	matcher, err := NewMapMatcherFromFiles(HmmProbabilitiesDefault(), "./test_data/matcher_4326_test.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Vertex 101 (start of edge 1) and vertex 106 (end of edge 4)
+	source := NewGPSMeasurementFromID(1, 37.66309622043991, 55.773079419469, 4326)
+	target := NewGPSMeasurementFromID(2, 37.6645156918502, 55.77506033140307, 4326)
+
+	result, err := matcher.FindShortestPath(source, target, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Target's candidate vertex is 103 (source vertex of edge 4), so the path is 102 -> 103
+	if result.SubMatches[0].Observations[1].MatchedEdge.ID != 2 {
+		t.Errorf("Path should end with edge 2, but got %d", result.SubMatches[0].Observations[1].MatchedEdge.ID)
+	}
+
+	// Edge 2 is the only link between source and target
+	_, err = matcher.FindShortestPath(source, target, 20, WithExcludedEdges(2))
+	if err == nil {
+		t.Errorf("Path should not be found when edge 2 is excluded")
+	}
+
+	// Excluded edge must not be candidate
+	_, err = matcher.FindShortestPath(source, target, 20, WithExcludedEdges(4))
+	if errors.Cause(err) != ErrTargetNotFound {
+		t.Errorf("Expected error '%v', but got '%v'", ErrTargetNotFound, err)
+	}
+
+	// Polygon crossing the middle of edge 2
+	polygon, err := spatial.RingsToS2Polygon([][][]float64{
+		{{37.6636, 55.7740}, {37.6640, 55.7740}, {37.6640, 55.7745}, {37.6636, 55.7745}},
+	}, 4326)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = matcher.FindShortestPath(source, target, 20, WithAvoidPolygons(polygon))
+	if err == nil {
+		t.Errorf("Path should not be found when edge 2 is inside of avoid-polygon")
+	}
+}
+
+func TestFindIsochronesExclusions(t *testing.T) {
+	matcher, err := NewMapMatcherFromFiles(HmmProbabilitiesDefault(), "./test_data/matcher_4326_test.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Near vertex 101: isochrones start from vertex 102
+	source := NewGPSMeasurementFromID(1, 37.66309622043991, 55.773079419469, 4326)
+
+	cases := []struct {
+		opts     []QueryOption
+		vertices []int64
+	}{
+		{opts: nil, vertices: []int64{102, 103, 105, 106}},
+		{opts: []QueryOption{WithExcludedEdges(2)}, vertices: []int64{102, 105}},
+		{opts: []QueryOption{WithExcludedEdges(3, 4)}, vertices: []int64{102, 103}},
+	}
+	for i, c := range cases {
+		result, err := matcher.FindIsochrones(source, 1000, 20, c.opts...)
+		if err != nil {
+			t.Fatalf("Case #%d: %v", i, err)
+		}
+		found := make(map[int64]float64, len(result))
+		for _, isochrone := range result {
+			found[isochrone.Vertex.ID] = isochrone.Cost
+		}
+		if len(found) != len(c.vertices) {
+			t.Errorf("Case #%d: expected %d vertices, but got %d: %v", i, len(c.vertices), len(found), found)
+			continue
+		}
+		for _, vertexID := range c.vertices {
+			if _, ok := found[vertexID]; !ok {
+				t.Errorf("Case #%d: vertex %d should be reachable", i, vertexID)
+			}
+		}
+	}
+}
+
+func TestFindBestCandidatePairExclusions(t *testing.T) {
+	// Two-way road 1 - 2 - 3: every vertex is in the same SCC
+	edges := []*spatial.Edge{}
+	for _, e := range [][2]int64{{1, 2}, {2, 1}, {2, 3}, {3, 2}} {
+		polyline := s2.Polyline{spatial.NewEuclideanS2Point(float64(e[0])*10, 0), spatial.NewEuclideanS2Point(float64(e[1])*10, 0)}
+		edges = append(edges, &spatial.Edge{ID: int64(len(edges) + 1), Source: e[0], Target: e[1], Weight: 10, Polyline: &polyline})
+	}
+	engine, err := NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(edges...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = engine.AddWeightProfile("no_edge_3", map[int64]float64{1: 10, 2: 10, 4: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	candidate := func(vertex int64, distance float64) candidateInfo {
+		return candidateInfo{vertex: vertex, sccComponent: engine.vertexStrongComponent[vertex], distance: distance}
+	}
+	sources := []candidateInfo{candidate(1, 1)}
+	targets := []candidateInfo{candidate(3, 1), candidate(2, 2)}
+
+	cases := []struct {
+		opts   []QueryOption
+		target int64
+	}{
+		{opts: nil, target: 3},
+		// Vertex 3 is in the same SCC as vertex 1, but it is not reachable for the request
+		{opts: []QueryOption{WithExcludedEdges(3)}, target: 2},
+		{opts: []QueryOption{WithRoutingFilter(func(edge *spatial.Edge, _ spatial.EdgeAttributes) bool { return edge.ID != 3 })}, target: 2},
+		{opts: []QueryOption{WithProfile("no_edge_3")}, target: 2},
+	}
+	for i, c := range cases {
+		query, err := engine.prepareQuery(c.opts...)
+		if err != nil {
+			t.Fatalf("Case #%d: %v", i, err)
+		}
+		src, tgt, found := engine.findBestCandidatePair(query, sources, targets)
+		if !found {
+			t.Errorf("Case #%d: pair should be found", i)
+			continue
+		}
+		if src.vertex != 1 || tgt.vertex != c.target {
+			t.Errorf("Case #%d: expected pair (1, %d), but got (%d, %d)", i, c.target, src.vertex, tgt.vertex)
+		}
+	}
+}
@@ -13,27 +13,41 @@ import (
 	Observation - gps measurement itself
 	IsMatched - true if observation was successfully matched to a road, false if no candidates were found
 	Code - matcher code providing additional info about matching result. See MatcherCode constants (enum workaround) for details
-	MatchedEdge - edge in G(v,e) corresponding to current gps measurement (empty if IsMatched is false)
+	MatchedEdge - edge in G(v,e) corresponding to current gps measurement (empty if IsMatched is false). Its weight is evaluated for the request's weight profile
+	MatchedEdgeLength - length of the matched edge
 	MatchedVertex - stands for closest vertex to the observation (empty if IsMatched is false)
 	ProjectedPoint - projection onto the matched edge (empty if IsMatched is false)
 	ProjectedPointIdx - index of the point in polyline which follows projection point
 	NextEdges - set of leading edges up to next observation. Could be an empty array if observations are very close to each other or if it just last observation
+	MatchedEdgeAttributes - attributes of the matched edge (nil if edge has no attributes)
 */
 type ObservationResult struct {
-	Observation        *GPSMeasurement
-	IsMatched          bool
-	Code               MatcherCode
-	MatchedEdge        spatial.Edge
-	MatchedVertex      spatial.Vertex
-	ProjectedPoint     s2.Point
-	ProjectionPointIdx int
-	NextEdges          []EdgeResult
+	Observation           *GPSMeasurement
+	IsMatched             bool
+	Code                  MatcherCode
+	MatchedEdge           spatial.Edge
+	MatchedEdgeLength     float64
+	MatchedVertex         spatial.Vertex
+	ProjectedPoint        s2.Point
+	ProjectionPointIdx    int
+	NextEdges             []EdgeResult
+	MatchedEdgeAttributes spatial.EdgeAttributes
 }
 
+// EdgeResult Representation of edge in path
+/*
+	Geom - geometry of the edge
+	Weight - cost of the edge for the request's weight profile
+	Length - length of the edge
+	ID - edge identifier
+	Attributes - attributes of the edge (nil if edge has no attributes)
+*/
 type EdgeResult struct {
-	Geom   s2.Polyline
-	Weight float64
-	ID     int64
+	Geom       s2.Polyline
+	Weight     float64
+	Length     float64
+	ID         int64
+	Attributes spatial.EdgeAttributes
 }
 
 // SubMatch Representation of a single continuous matched segment
@@ -55,7 +69,7 @@ type MatcherResult struct {
 }
 
 // prepareSubMatch returns SubMatch for corresponding ViterbiPath, set of gps measurements and calculated routes' lengths
-func (matcher *MapMatcher) prepareSubMatch(vpath viterbi.ViterbiPath, gpsMeasurements GPSMeasurements, layers []RoadPositions, chRoutes map[int]map[int][]int64) SubMatch {
+func (engine *MapEngine) prepareSubMatch(query *routingQuery, vpath viterbi.ViterbiPath, gpsMeasurements GPSMeasurements, layers []RoadPositions, chRoutes map[int]map[int][]int64) SubMatch {
 	subMatch := SubMatch{
 		Observations: make([]ObservationResult, len(gpsMeasurements)),
 		Probability:  vpath.Probability,
@@ -73,13 +87,15 @@ func (matcher *MapMatcher) prepareSubMatch(vpath viterbi.ViterbiPath, gpsMeasure
 	}
 
 	subMatch.Observations[0] = ObservationResult{
-		Observation:        gpsMeasurements[0],
-		IsMatched:          true,
-		Code:               code,
-		MatchedEdge:        *rpPath[0].GraphEdge,
-		MatchedVertex:      *matcher.engine.vertices[rpPath[0].PickedGraphVertex],
-		ProjectedPoint:     rpPath[0].Projected.Point,
-		ProjectionPointIdx: rpPath[0].next,
+		Observation:           gpsMeasurements[0],
+		IsMatched:             true,
+		Code:                  code,
+		MatchedEdge:           query.matchedEdge(rpPath[0].GraphEdge),
+		MatchedEdgeLength:     engine.edgeLength(rpPath[0].GraphEdge),
+		MatchedVertex:         *engine.vertices[rpPath[0].PickedGraphVertex],
+		ProjectedPoint:        rpPath[0].Projected.Point,
+		ProjectionPointIdx:    rpPath[0].next,
+		MatchedEdgeAttributes: engine.attributes[rpPath[0].GraphEdge.ID],
 	}
 
 	// Iterate other states
@@ -88,13 +104,15 @@ func (matcher *MapMatcher) prepareSubMatch(vpath viterbi.ViterbiPath, gpsMeasure
 		previousState := rpPath[i-1]
 		currentState := rpPath[i]
 		subMatch.Observations[i] = ObservationResult{
-			Observation:        gpsMeasurements[i],
-			IsMatched:          true,
-			Code:               CODE_OK,
-			MatchedEdge:        *currentState.GraphEdge,
-			MatchedVertex:      *matcher.engine.vertices[currentState.PickedGraphVertex],
-			ProjectedPoint:     currentState.Projected.Point,
-			ProjectionPointIdx: currentState.next,
+			Observation:           gpsMeasurements[i],
+			IsMatched:             true,
+			Code:                  CODE_OK,
+			MatchedEdge:           query.matchedEdge(currentState.GraphEdge),
+			MatchedEdgeLength:     engine.edgeLength(currentState.GraphEdge),
+			MatchedVertex:         *engine.vertices[currentState.PickedGraphVertex],
+			ProjectedPoint:        currentState.Projected.Point,
+			ProjectionPointIdx:    currentState.next,
+			MatchedEdgeAttributes: engine.attributes[currentState.GraphEdge.ID],
 		}
 		if previousState.GraphEdge.ID == currentState.GraphEdge.ID {
 			continue
@@ -106,7 +124,8 @@ func (matcher *MapMatcher) prepareSubMatch(vpath viterbi.ViterbiPath, gpsMeasure
 		for j := 1; j < len(path); j++ {
 			sourceVertex := path[j-1]
 			targetVertex := path[j]
-			edge := matcher.engine.edges[sourceVertex][targetVertex]
+			// Matched edges are preferred over parallel ones
+			edge := query.pathEdge(sourceVertex, targetVertex, previousState.GraphEdge, currentState.GraphEdge)
 			if len(*edge.Polyline) < 2 {
 				fmt.Printf("[WARNING]: Edge %d have less than 2 points\n", edge.ID)
 			}
@@ -114,13 +133,7 @@ func (matcher *MapMatcher) prepareSubMatch(vpath viterbi.ViterbiPath, gpsMeasure
 				continue
 			}
 			lastEdgeID = edge.ID
-			edgeGeomCopy := make(s2.Polyline, len(*edge.Polyline))
-			copy(edgeGeomCopy, *edge.Polyline)
-			subMatch.Observations[i-1].NextEdges = append(subMatch.Observations[i-1].NextEdges, EdgeResult{
-				Geom:   edgeGeomCopy,
-				Weight: edge.Weight,
-				ID:     edge.ID,
-			})
+			subMatch.Observations[i-1].NextEdges = append(subMatch.Observations[i-1].NextEdges, query.edgeResult(edge))
 		}
 	}
 	if rpPath[len(rpPath)-1].GraphEdge.ID == lastEdgeID {
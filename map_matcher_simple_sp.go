@@ -1,7 +1,7 @@
 package horizon
 
 import (
-	"math"
+	"sort"
 
 	"github.com/LdDl/horizon/spatial"
 	"github.com/golang/geo/s2"
@@ -29,9 +29,24 @@ type candidateInfo struct {
 // Parameters:
 //   - source, target: GPS measurements to route between
 //   - statesRadiusMeters: maximum radius to search nearest edges (use -1 for unlimited)
-func (matcher *MapMatcher) FindShortestPath(source, target *GPSMeasurement, statesRadiusMeters float64) (MatcherResult, error) {
+//   - opts: per-request options (see QueryOptions)
+func (matcher *MapMatcher) FindShortestPath(source, target *GPSMeasurement, statesRadiusMeters float64, opts ...QueryOption) (MatcherResult, error) {
+	return matcher.Engine().shortestPath(source, target, statesRadiusMeters, opts...)
+}
+
+// shortestPath Finds shortest path between two observations on the given engine (see FindShortestPath)
+func (engine *MapEngine) shortestPath(source, target *GPSMeasurement, statesRadiusMeters float64, opts ...QueryOption) (MatcherResult, error) {
+	err := checkMeasurementsSRID(source, target)
+	if err != nil {
+		return MatcherResult{}, err
+	}
+	query, err := engine.prepareQuery(opts...)
+	if err != nil {
+		return MatcherResult{}, errors.Wrap(err, "failed to prepare query")
+	}
+
 	// Get multiple candidates for source
-	sourceCandidates, err := matcher.getCandidates(source.Point, statesRadiusMeters, DEFAULT_CANDIDATES_LIMIT)
+	sourceCandidates, err := engine.getCandidates(query, source.Point, statesRadiusMeters, DEFAULT_CANDIDATES_LIMIT)
 	if err != nil {
 		return MatcherResult{}, errors.Wrap(err, "failed to get source candidates")
 	}
@@ -40,7 +55,7 @@ func (matcher *MapMatcher) FindShortestPath(source, target *GPSMeasurement, stat
 	}
 
 	// Get multiple candidates for target
-	targetCandidates, err := matcher.getCandidates(target.Point, statesRadiusMeters, DEFAULT_CANDIDATES_LIMIT)
+	targetCandidates, err := engine.getCandidates(query, target.Point, statesRadiusMeters, DEFAULT_CANDIDATES_LIMIT)
 	if err != nil {
 		return MatcherResult{}, errors.Wrap(err, "failed to get target candidates")
 	}
@@ -49,14 +64,14 @@ func (matcher *MapMatcher) FindShortestPath(source, target *GPSMeasurement, stat
 	}
 
 	// Find best pair: priority to big SCC, then same SCC, then closest (fallback)
-	sourceCandidate, targetCandidate, found := matcher.findBestCandidatePair(sourceCandidates, targetCandidates)
+	sourceCandidate, targetCandidate, found := engine.findBestCandidatePair(query, sourceCandidates, targetCandidates)
 	if !found {
 		// Should not happen if we have candidates, but handle defensively
 		return MatcherResult{}, errors.Wrapf(ErrCandidatesNotFound, "no routable candidate pair found for source %d and target %d", sourceCandidate.vertex, targetCandidate.vertex)
 	}
 
 	// Route between selected candidates
-	ans, path := matcher.engine.queryPool.ShortestPath(sourceCandidate.vertex, targetCandidate.vertex)
+	ans, path := query.shortestPath(sourceCandidate.vertex, targetCandidate.vertex)
 	if ans == -1.0 {
 		return MatcherResult{}, errors.Wrapf(ErrPathNotFound, "no path found between vertices %d and %d", sourceCandidate.vertex, targetCandidate.vertex)
 	}
@@ -75,28 +90,26 @@ func (matcher *MapMatcher) FindShortestPath(source, target *GPSMeasurement, stat
 	for i := 1; i < len(path); i++ {
 		s := path[i-1]
 		t := path[i]
-		edge := matcher.engine.edges[s][t]
-		edges = append(edges, *edge)
-		edgeGeomCopy := make(s2.Polyline, len(*edge.Polyline))
-		copy(edgeGeomCopy, *edge.Polyline)
-		intermediateEdges = append(intermediateEdges, EdgeResult{
-			Geom:   edgeGeomCopy,
-			Weight: edge.Weight,
-			ID:     edge.ID,
-		})
+		edge := query.pathEdge(s, t, sourceCandidate.edge, targetCandidate.edge)
+		edges = append(edges, query.matchedEdge(edge))
+		intermediateEdges = append(intermediateEdges, query.edgeResult(edge))
 	}
 
 	subMatch.Observations[0] = ObservationResult{
-		Observation: source,
-		MatchedEdge: edges[0],
+		Observation:           source,
+		MatchedEdge:           edges[0],
+		MatchedEdgeLength:     intermediateEdges[0].Length,
+		MatchedEdgeAttributes: intermediateEdges[0].Attributes,
 	}
 	if len(intermediateEdges) > 1 {
 		subMatch.Observations[0].NextEdges = intermediateEdges[1 : len(edges)-1]
 	}
 
 	subMatch.Observations[1] = ObservationResult{
-		Observation: target,
-		MatchedEdge: edges[len(edges)-1],
+		Observation:           target,
+		MatchedEdge:           edges[len(edges)-1],
+		MatchedEdgeLength:     intermediateEdges[len(intermediateEdges)-1].Length,
+		MatchedEdgeAttributes: intermediateEdges[len(intermediateEdges)-1].Attributes,
 	}
 
 	return MatcherResult{
@@ -104,42 +117,39 @@ func (matcher *MapMatcher) FindShortestPath(source, target *GPSMeasurement, stat
 	}, nil
 }
 
-// getCandidates retrieves candidate edges for a point and converts them to candidateInfo
-func (matcher *MapMatcher) getCandidates(pt s2.Point, radiusMeters float64, limit int) ([]candidateInfo, error) {
+// getCandidates retrieves candidate edges for a point and converts them to candidateInfo.
+// Edges excluded by the query are not considered as candidates
+func (engine *MapEngine) getCandidates(query *routingQuery, pt s2.Point, radiusMeters float64, limit int) ([]candidateInfo, error) {
 	var nearestObjects []spatial.NearestObject
 	var err error
 
 	if radiusMeters < 0 {
-		nearestObjects, err = matcher.engine.storage.FindNearest(pt, limit)
+		nearestObjects, err = engine.storage.FindNearest(pt, limit)
 	} else {
-		nearestObjects, err = matcher.engine.storage.FindNearestInRadius(pt, radiusMeters, limit)
+		nearestObjects, err = engine.storage.FindNearestInRadius(pt, radiusMeters, limit)
 	}
 	if err != nil {
 		return nil, err
 	}
+	nearestObjects = query.filterNearest(nearestObjects)
 
 	candidates := make([]candidateInfo, 0, len(nearestObjects))
 	for _, obj := range nearestObjects {
-		edgeData := matcher.engine.storage.GetEdge(obj.EdgeID)
-		if edgeData == nil {
-			continue
-		}
-
-		m, n := edgeData.Source, edgeData.Target
-		edge := matcher.engine.edges[m][n]
+		edge := engine.edges[int64(obj.EdgeID)]
 		if edge == nil {
 			continue
 		}
+		m, n := edge.Source, edge.Target
 
 		// Determine which vertex to use based on projection fraction
-		_, fraction, _ := spatial.CalcProjection(*edge.Polyline, pt)
+		_, fraction, _ := engine.calcProjection(*edge.Polyline, pt)
 		vertex := n
 		if fraction > 0.5 {
 			vertex = m
 		}
 
 		// Get SCC component for this vertex
-		sccComponent, exists := matcher.engine.vertexStrongComponent[vertex]
+		sccComponent, exists := engine.vertexStrongComponent[vertex]
 		if !exists {
 			sccComponent = -1
 		}
@@ -156,100 +166,95 @@ func (matcher *MapMatcher) getCandidates(pt s2.Point, radiusMeters float64, limi
 	return candidates, nil
 }
 
+// candidatePair Pair of source and target candidates with their total distance to observations
+type candidatePair struct {
+	src, tgt candidateInfo
+	dist     float64
+}
+
+// candidatePairs Returns pairs of source and target candidates accepted by the filter (nil filter accepts every pair) sorted by total distance
+func candidatePairs(sources, targets []candidateInfo, accept func(src, tgt candidateInfo) bool) []candidatePair {
+	pairs := make([]candidatePair, 0, len(sources)*len(targets))
+	for _, src := range sources {
+		for _, tgt := range targets {
+			if accept != nil && !accept(src, tgt) {
+				continue
+			}
+			pairs = append(pairs, candidatePair{src, tgt, src.distance + tgt.distance})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].dist < pairs[j].dist })
+	return pairs
+}
+
 // findBestCandidatePair finds the best source-target pair with priority to non-tiny SCC.
 // Work in the following order:
 // 1 both candidates in the same non-tiny SCC (size >= SMALL_COMPONENT_SIZE)
 // 2: both candidates in the same SCC (including small ones)
 // 3: closest candidates regardless of SCC (fallback, routing may fail)
-func (matcher *MapMatcher) findBestCandidatePair(sources, targets []candidateInfo) (candidateInfo, candidateInfo, bool) {
+// Pairs snapped to the same vertex are skipped, since there is no route between them (e.g. vertex of one-way road is SCC itself).
+// Such pair is returned only if there is no other routable pair.
+// SCC are evaluated for the whole graph, so if request excludes some edges (or its profile makes some edges not traversable)
+// then pairs of the first two steps are routed too and unroutable ones are skipped
+func (engine *MapEngine) findBestCandidatePair(query *routingQuery, sources, targets []candidateInfo) (candidateInfo, candidateInfo, bool) {
 	if len(sources) == 0 || len(targets) == 0 {
 		return candidateInfo{}, candidateInfo{}, false
 	}
 
-	var bestSource, bestTarget candidateInfo
-	bestDistance := math.MaxFloat64
-	found := false
-
-	// Priority 1: find best pair where BOTH are in the same non-tiny SCC
-	for _, src := range sources {
-		if src.sccComponent == -1 {
-			continue
+	unroutable := make(map[[2]int64]bool)
+	routable := func(p candidatePair) bool {
+		key := [2]int64{p.src.vertex, p.tgt.vertex}
+		if unroutable[key] {
+			return false
 		}
-		if matcher.engine.isComponentVerySmall[src.sccComponent] {
-			continue
-		}
-		for _, tgt := range targets {
-			if tgt.sccComponent != src.sccComponent {
-				continue
-			}
-			totalDist := src.distance + tgt.distance
-			if totalDist < bestDistance {
-				bestDistance = totalDist
-				bestSource = src
-				bestTarget = tgt
-				found = true
-			}
+		ans, _ := query.shortestPath(p.src.vertex, p.tgt.vertex)
+		if ans == -1.0 {
+			unroutable[key] = true
+			return false
 		}
+		return true
 	}
+	checkRoutes := query.hasExclusions() || query.profile.weights != nil
 
-	if found {
-		return bestSource, bestTarget, true
+	// Priority 1: find best pair where BOTH are in the same non-tiny SCC
+	pairs := candidatePairs(sources, targets, func(src, tgt candidateInfo) bool {
+		return src.sccComponent != -1 && !engine.isComponentVerySmall[src.sccComponent] && tgt.sccComponent == src.sccComponent && tgt.vertex != src.vertex
+	})
+	for _, p := range pairs {
+		if !checkRoutes || routable(p) {
+			return p.src, p.tgt, true
+		}
 	}
 
 	// Priority 2: find best pair in ANY same SCC (including very small ones)
-	bestDistance = math.MaxFloat64
-	for _, src := range sources {
-		if src.sccComponent == -1 {
-			continue
-		}
-		for _, tgt := range targets {
-			if tgt.sccComponent == -1 {
-				continue
-			}
-			if src.sccComponent != tgt.sccComponent {
-				continue
-			}
-			totalDist := src.distance + tgt.distance
-			if totalDist < bestDistance {
-				bestDistance = totalDist
-				bestSource = src
-				bestTarget = tgt
-				found = true
-			}
+	pairs = candidatePairs(sources, targets, func(src, tgt candidateInfo) bool {
+		return src.sccComponent != -1 && tgt.sccComponent == src.sccComponent && tgt.vertex != src.vertex
+	})
+	for _, p := range pairs {
+		if !checkRoutes || routable(p) {
+			return p.src, p.tgt, true
 		}
 	}
 
-	if found {
-		return bestSource, bestTarget, true
-	}
-
 	// Priority 3: fallback - try routing pairs sorted by distance until one works
 	// @todo: this is huge performance hit, but it is what it is.
-	type candidatePair struct {
-		src, tgt candidateInfo
-		dist     float64
-	}
-	pairs := make([]candidatePair, 0, len(sources)*len(targets))
-	for _, src := range sources {
-		for _, tgt := range targets {
-			pairs = append(pairs, candidatePair{src, tgt, src.distance + tgt.distance})
-		}
-	}
-	// Sort by total distance
-	for i := 0; i < len(pairs)-1; i++ {
-		for j := i + 1; j < len(pairs); j++ {
-			if pairs[j].dist < pairs[i].dist {
-				pairs[i], pairs[j] = pairs[j], pairs[i]
+	pairs = candidatePairs(sources, targets, nil)
+	// Try pairs in order until we find a routable one
+	var samePair *candidatePair
+	for i, p := range pairs {
+		if p.src.vertex == p.tgt.vertex {
+			if samePair == nil {
+				samePair = &pairs[i]
 			}
+			continue
 		}
-	}
-	// Try pairs in order until we find a routable one
-	for _, p := range pairs {
-		ans, _ := matcher.engine.queryPool.ShortestPath(p.src.vertex, p.tgt.vertex)
-		if ans != -1.0 {
+		if routable(p) {
 			return p.src, p.tgt, true
 		}
 	}
+	if samePair != nil {
+		return samePair.src, samePair.tgt, true
+	}
 
 	return candidateInfo{}, candidateInfo{}, false
 }
@@ -0,0 +1,180 @@
+package horizon
+
+import (
+	"math"
+	"sort"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// NearestEdge Representation of the edge found near the given point
+/*
+	Edge - found edge
+	Weight - travel cost of the whole edge for the request's weight profile
+	Length - length of the whole edge (meters for WGS84 graphs)
+	ProjectedPoint - projection of the point onto the edge
+	ProjectionPointIdx - index of the edge vertex which comes after the projected point
+	Fraction - number in [0;1], describes how far projected point from the first point of edge
+	Offset - distance along the edge from its first point to the projected point (meters for WGS84 graphs)
+	Distance - distance from the point to the projected point (meters for WGS84 graphs)
+	Vertex - the closest (along the edge) vertex of the edge: source one when Fraction <= 0.5, target one otherwise
+	WeakComponent - weakly connected component of the vertex (-1 when unknown)
+	StrongComponent - strongly connected component of the vertex (-1 when unknown)
+	IsSmallComponent - true if strongly connected component of the vertex is very small (such vertex is barely routable)
+	Attributes - attributes of the edge (nil if edge has no attributes)
+*/
+type NearestEdge struct {
+	Edge               *spatial.Edge
+	Weight             float64
+	Length             float64
+	ProjectedPoint     s2.Point
+	ProjectionPointIdx int
+	Fraction           float64
+	Offset             float64
+	Distance           float64
+	Vertex             *spatial.Vertex
+	WeakComponent      int64
+	StrongComponent    int64
+	IsSmallComponent   bool
+	Attributes         spatial.EdgeAttributes
+}
+
+// Nearest Returns up to N nearest edges for the given point sorted by distance
+/*
+	point - point to search edges for
+	n - max number of edges
+	radiusMeters - max radius of search (use -1 for unlimited)
+	opts - per-request options (see QueryOptions). Excluded edges and edges which are not traversable for the request's profile are never returned
+*/
+func (matcher *MapMatcher) Nearest(point *GPSMeasurement, n int, radiusMeters float64, opts ...QueryOption) ([]NearestEdge, error) {
+	engine := matcher.Engine()
+	err := checkMeasurementsSRID(point)
+	if err != nil {
+		return nil, err
+	}
+	query, err := engine.prepareQuery(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't prepare query")
+	}
+	return engine.nearest(query, point.Point, n, radiusMeters)
+}
+
+// Snap Snaps every point to its nearest edge independently (no HMM matching is done).
+// Returned slice has the same length as points; nil element means that there is no edge for the point within the radius
+/*
+	points - points to snap
+	radiusMeters - max radius of search (use -1 for unlimited)
+	opts - per-request options (see QueryOptions). Excluded edges and edges which are not traversable for the request's profile are never used
+*/
+func (matcher *MapMatcher) Snap(points []*GPSMeasurement, radiusMeters float64, opts ...QueryOption) ([]*NearestEdge, error) {
+	engine := matcher.Engine()
+	err := checkMeasurementsSRID(points...)
+	if err != nil {
+		return nil, err
+	}
+	query, err := engine.prepareQuery(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't prepare query")
+	}
+	ans := make([]*NearestEdge, len(points))
+	for i := range points {
+		found, err := engine.nearest(query, points[i].Point, 1, radiusMeters)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't find nearest edge for point #%d", i)
+		}
+		if len(found) == 0 {
+			continue
+		}
+		ans[i] = &found[0]
+	}
+	return ans, nil
+}
+
+// nearest Returns up to N nearest edges for the given point with exact distances to projections
+func (engine *MapEngine) nearest(query *routingQuery, pt s2.Point, n int, radiusMeters float64) ([]NearestEdge, error) {
+	if n <= 0 {
+		return []NearestEdge{}, nil
+	}
+	// Storage could return approximate distances (e.g. distance from cell to edge), so take extra candidates and re-sort them
+	limit := n + DEFAULT_CANDIDATES_LIMIT
+	var nearestObjects []spatial.NearestObject
+	var err error
+	if radiusMeters < 0 {
+		nearestObjects, err = engine.storage.FindNearest(pt, limit)
+	} else {
+		nearestObjects, err = engine.storage.FindNearestInRadius(pt, radiusMeters, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	nearestObjects = query.filterNearest(nearestObjects)
+
+	ans := make([]NearestEdge, 0, len(nearestObjects))
+	for _, obj := range nearestObjects {
+		edge := engine.storage.GetEdge(obj.EdgeID)
+		if edge == nil || edge.Polyline == nil {
+			continue
+		}
+		weight, ok := query.profile.weight(edge)
+		if !ok {
+			continue
+		}
+		projected, fraction, next := engine.calcProjection(*edge.Polyline, pt)
+		distance := engine.distance(pt, projected)
+		if radiusMeters >= 0 && distance > radiusMeters {
+			continue
+		}
+		vertexID := edge.Source
+		if fraction > 0.5 {
+			vertexID = edge.Target
+		}
+		vertex, ok := engine.vertices[vertexID]
+		if !ok {
+			vertex = &spatial.Vertex{ID: vertexID}
+		}
+		length := engine.edgeLength(edge)
+		weakComponent, ok := engine.vertexComponent[vertexID]
+		if !ok {
+			weakComponent = -1
+		}
+		strongComponent, ok := engine.vertexStrongComponent[vertexID]
+		if !ok {
+			strongComponent = -1
+		}
+		ans = append(ans, NearestEdge{
+			Edge:               edge,
+			Weight:             weight,
+			Length:             length,
+			ProjectedPoint:     projected,
+			ProjectionPointIdx: next,
+			Fraction:           fraction,
+			Offset:             fraction * length,
+			Distance:           distance,
+			Vertex:             vertex,
+			WeakComponent:      weakComponent,
+			StrongComponent:    strongComponent,
+			IsSmallComponent:   strongComponent != -1 && engine.isComponentVerySmall[strongComponent],
+			Attributes:         engine.attributes[edge.ID],
+		})
+	}
+	sort.SliceStable(ans, func(i, j int) bool {
+		return ans[i].Distance < ans[j].Distance
+	})
+	if len(ans) > n {
+		ans = ans[:n]
+	}
+	return ans, nil
+}
+
+// distance Returns distance between two points: meters for spherical storage (on ellipsoid if WithEllipsoidalDistances is used), units of coordinates for Euclidean one
+func (engine *MapEngine) distance(a, b s2.Point) float64 {
+	if engine.isEuclidean() {
+		return math.Hypot(a.Vector.X-b.Vector.X, a.Vector.Y-b.Vector.Y)
+	}
+	if engine.ellipsoidal {
+		return spatial.GeodesicDistance(a, b)
+	}
+	return a.Distance(b).Radians() * spatial.EarthRadius
+}
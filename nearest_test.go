@@ -0,0 +1,106 @@
+package horizon
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNearest(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	eps := 1e-9
+	point := NewGPSMeasurementFromID(1, 3, -1, 0)
+
+	nearest, err := matcher.Nearest(point, 3, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedEdges := []int64{2, 3, 4}
+	expectedDistances := []float64{1, math.Sqrt(5), math.Sqrt(8)}
+	if len(nearest) != len(expectedEdges) {
+		t.Fatalf("Nearest edges number should be %d, got %d", len(expectedEdges), len(nearest))
+	}
+	for i := range expectedEdges {
+		if nearest[i].Edge.ID != expectedEdges[i] {
+			t.Errorf("Nearest edge #%d should be %d, got %d", i, expectedEdges[i], nearest[i].Edge.ID)
+		}
+		if math.Abs(nearest[i].Distance-expectedDistances[i]) > eps {
+			t.Errorf("Distance to nearest edge #%d should be %f, got %f", i, expectedDistances[i], nearest[i].Distance)
+		}
+	}
+	first := nearest[0]
+	if math.Abs(first.ProjectedPoint.X-3) > eps || math.Abs(first.ProjectedPoint.Y) > eps {
+		t.Errorf("Projected point should be (3, 0), got (%f, %f)", first.ProjectedPoint.X, first.ProjectedPoint.Y)
+	}
+	if math.Abs(first.Offset-3) > eps {
+		t.Errorf("Offset should be 3, got %f", first.Offset)
+	}
+	if first.Vertex.ID != 2 {
+		t.Errorf("Nearest vertex should be 2, got %d", first.Vertex.ID)
+	}
+
+	// Radius restricts distance to the projection
+	nearest, err = matcher.Nearest(point, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nearest) != 1 || nearest[0].Edge.ID != 2 {
+		t.Errorf("Only edge 2 should be found within radius")
+	}
+
+	// Excluded edge is never returned
+	nearest, err = matcher.Nearest(point, 1, -1, WithExcludedEdges(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nearest) != 1 || nearest[0].Edge.ID != 3 {
+		t.Errorf("Nearest edge should be 3 when edge 2 is excluded")
+	}
+}
+
+func TestSnap(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	points := []*GPSMeasurement{
+		NewGPSMeasurementFromID(1, 3, -1, 0),
+		NewGPSMeasurementFromID(2, 100, 100, 0),
+		NewGPSMeasurementFromID(3, 12, 0.5, 0),
+	}
+	snapped, err := matcher.Snap(points, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapped) != len(points) {
+		t.Fatalf("Snapped points number should be %d, got %d", len(points), len(snapped))
+	}
+	if snapped[0] == nil || snapped[0].Edge.ID != 2 {
+		t.Errorf("Point #0 should be snapped to edge 2")
+	}
+	if snapped[1] != nil {
+		t.Errorf("Point #1 should not be snapped, got edge %d", snapped[1].Edge.ID)
+	}
+	if snapped[2] == nil || snapped[2].Edge.ID != 6 {
+		t.Errorf("Point #2 should be snapped to edge 6")
+	}
+}
+
+func TestNearestProfile(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	// Edge 4 is the closest one, but it is not traversable for trucks
+	point := NewGPSMeasurementFromID(1, 2, 2.5, 0)
+	nearest, err := matcher.Nearest(point, 1, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nearest) != 1 || nearest[0].Edge.ID != 4 {
+		t.Fatalf("Nearest edge should be 4")
+	}
+	nearest, err = matcher.Nearest(point, 1, -1, WithProfile("truck_time"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nearest) != 1 || nearest[0].Edge.ID != 2 {
+		t.Fatalf("Nearest edge should be 2 for 'truck_time' profile")
+	}
+	if nearest[0].Weight != 50 {
+		t.Errorf("Weight of nearest edge should be 50 for 'truck_time' profile, got %f", nearest[0].Weight)
+	}
+}
@@ -0,0 +1,342 @@
+package horizon
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+const (
+	// OSM_WEIGHT_LENGTH Weight of edges is length in meters
+	OSM_WEIGHT_LENGTH = "length"
+	// OSM_WEIGHT_TRAVEL_TIME Weight of edges is travel time in seconds evaluated from 'maxspeed' tag or default speed of the road class
+	OSM_WEIGHT_TRAVEL_TIME = "travel_time"
+	// OSM_WAY_ID_ATTRIBUTE Name of edge attribute holding identifier of OSM way which the edge is cut from
+	OSM_WAY_ID_ATTRIBUTE = "osm_way_id"
+)
+
+// OSMOptions Tag profile and parameters of importing road graph from OSM PBF file
+/*
+	Highways - values of 'highway' tag which are imported with default speeds (km/h). Default speed is used for travel time when 'maxspeed' tag is absent or can't be parsed
+	Exclude - ways having any of these tag values are skipped (e.g. 'access' = 'private')
+	Weight - weight of edges: OSM_WEIGHT_LENGTH or OSM_WEIGHT_TRAVEL_TIME
+	Tags - tags of ways kept as edge attributes in addition to 'highway' and OSM_WAY_ID_ATTRIBUTE
+*/
+type OSMOptions struct {
+	Highways map[string]float64
+	Exclude  map[string][]string
+	Weight   string
+	Tags     []string
+}
+
+// DefaultOSMOptions Returns tag profile for cars: roads from motorways to living streets with typical speeds, weighted by travel time
+func DefaultOSMOptions() OSMOptions {
+	return OSMOptions{
+		Highways: map[string]float64{
+			"motorway":       110,
+			"motorway_link":  60,
+			"trunk":          90,
+			"trunk_link":     50,
+			"primary":        60,
+			"primary_link":   40,
+			"secondary":      60,
+			"secondary_link": 40,
+			"tertiary":       50,
+			"tertiary_link":  30,
+			"unclassified":   40,
+			"residential":    30,
+			"living_street":  10,
+			"service":        15,
+		},
+		Exclude: map[string][]string{
+			"area":   {"yes"},
+			"access": {"no", "private"},
+		},
+		Weight: OSM_WEIGHT_TRAVEL_TIME,
+		Tags:   []string{"name", "maxspeed"},
+	}
+}
+
+// NewMapMatcherFromOSM Returns pointer to created MapMatcher with road graph imported from OSM PBF file (see NewMapEngineFromOSM)
+func NewMapMatcherFromOSM(props *HmmProbabilities, filename string, options OSMOptions, engineOpts ...func(*MapEngine)) (*MapMatcher, error) {
+	st := time.Now()
+	engine, err := NewMapEngineFromOSM(filename, options, engineOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewMapMatcher(WithHmmParameters(props), WithMapEngine(engine), WithGraphSource(filename, time.Since(st))), nil
+}
+
+// NewMapEngineFromOSM Returns pointer to MapEngine with road graph imported from OSM PBF extract
+/*
+	filename - path to *.osm.pbf file
+	options - tag profile and weight of edges (see OSMOptions)
+	engineOpts - options of the engine. Graph is always in WGS84 (SRID = 4326).
+		WithWeightProfiles could list OSM_WEIGHT_LENGTH and OSM_WEIGHT_TRAVEL_TIME to get both weights as profiles
+
+	Ways are split into edges at intersections (nodes shared by several ways) and at nodes missing in the extract. Vertices are identified by OSM node IDs.
+	Direction of roads is taken from 'oneway' tag: 'yes', 'true', '1' (forward), '-1', 'reverse' (backward) or 'no' (two-way).
+	Motorways and roundabouts are one-way unless tagged otherwise, reversible roads are skipped.
+	Edges of two-way roads go in pairs: forward edge gets odd identifier and the backward one gets the next even identifier.
+	Use MapEngine.ExportCSV to get CSV files for NewMapMatcherFromFiles
+*/
+func NewMapEngineFromOSM(filename string, options OSMOptions, engineOpts ...func(*MapEngine)) (*MapEngine, error) {
+	settings := newMapEngine(engineOpts...)
+	if settings.graphSRID != 4326 {
+		return nil, errors.Wrapf(spatial.ErrUnknownSRID, "OSM data is in WGS84, but SRID %d is set", settings.graphSRID)
+	}
+	if options.Weight != OSM_WEIGHT_LENGTH && options.Weight != OSM_WEIGHT_TRAVEL_TIME {
+		return nil, fmt.Errorf("unknown weight '%s' of OSM edges: expected '%s' or '%s'", options.Weight, OSM_WEIGHT_LENGTH, OSM_WEIGHT_TRAVEL_TIME)
+	}
+	for highway, speed := range options.Highways {
+		if speed <= 0 {
+			return nil, fmt.Errorf("default speed of '%s' roads should be positive, but got %f", highway, speed)
+		}
+	}
+	for _, name := range settings.profileColumns {
+		if name != OSM_WEIGHT_LENGTH && name != OSM_WEIGHT_TRAVEL_TIME {
+			return nil, fmt.Errorf("weight profile '%s' can't be derived from OSM data: expected '%s' or '%s'", name, OSM_WEIGHT_LENGTH, OSM_WEIGHT_TRAVEL_TIME)
+		}
+	}
+
+	// The first pass: filter ways and count references to their nodes
+	ways := []osmWay{}
+	references := make(map[int64]uint8)
+	err := readOSMPBFFile(filename, osmPBFHandler{
+		way: func(way osmWay) {
+			if !options.accepts(way.tags) || len(way.refs) < 2 {
+				return
+			}
+			ways = append(ways, way)
+			for _, ref := range way.refs {
+				if references[ref] < 2 {
+					references[ref]++
+				}
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ways) == 0 {
+		return nil, errors.Wrapf(ErrEmptyGraph, "there are no ways matching tag profile in OSM file '%s'", filename)
+	}
+	// The second pass: coordinates of referenced nodes only
+	points := make(map[int64]s2.Point, len(references))
+	err = readOSMPBFFile(filename, osmPBFHandler{
+		node: func(id int64, lat, lon float64) {
+			if _, ok := references[id]; ok {
+				points[id] = s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lon))
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	builder := NewMapEngineBuilder(engineOpts...)
+	profiles := make(map[string]map[int64]float64, len(settings.profileColumns))
+	for _, name := range settings.profileColumns {
+		profiles[name] = make(map[int64]float64)
+	}
+	attributes := make(map[int64]spatial.EdgeAttributes)
+	nextID := int64(1)
+	for _, way := range ways {
+		direction := osmDirection(way.tags)
+		speed := options.speed(way.tags)
+		wayAttributes := options.attributes(way)
+		for _, segment := range splitOSMWay(way, points, references) {
+			polyline := make(s2.Polyline, len(segment))
+			for i, ref := range segment {
+				polyline[i] = points[ref]
+			}
+			length := settings.edgeLength(&spatial.Edge{Polyline: &polyline})
+			weights := map[string]float64{
+				OSM_WEIGHT_LENGTH:      length,
+				OSM_WEIGHT_TRAVEL_TIME: length / (speed / 3.6),
+			}
+			source, target := segment[0], segment[len(segment)-1]
+			edgeIDs := []int64{}
+			if direction >= 0 {
+				builder.AddEdges(&spatial.Edge{ID: nextID, Source: source, Target: target, Weight: weights[options.Weight], Polyline: &polyline})
+				edgeIDs = append(edgeIDs, nextID)
+			}
+			if direction <= 0 {
+				reversed := make(s2.Polyline, len(polyline))
+				for i := range polyline {
+					reversed[i] = polyline[len(polyline)-1-i]
+				}
+				builder.AddEdges(&spatial.Edge{ID: nextID + 1, Source: target, Target: source, Weight: weights[options.Weight], Polyline: &reversed})
+				edgeIDs = append(edgeIDs, nextID+1)
+			}
+			nextID += 2
+			for _, edgeID := range edgeIDs {
+				for name := range profiles {
+					profiles[name][edgeID] = weights[name]
+				}
+				attributes[edgeID] = wayAttributes
+			}
+		}
+	}
+	for _, name := range settings.profileColumns {
+		builder.AddWeightProfile(name, profiles[name])
+	}
+	builder.AddEdgeAttributes(attributes)
+	engine, err := builder.Build()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't build graph from OSM file '%s'", filename)
+	}
+	return engine, nil
+}
+
+// readOSMPBFFile Reads OSM PBF file (see readOSMPBF)
+func readOSMPBFFile(filename string, handler osmPBFHandler) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return errors.Wrapf(err, "Can't open OSM file '%s'", filename)
+	}
+	defer file.Close()
+	err = readOSMPBF(file, handler)
+	if err != nil {
+		return errors.Wrapf(err, "Can't read OSM file '%s'", filename)
+	}
+	return nil
+}
+
+// accepts Checks if way should be imported according to tag profile
+func (options OSMOptions) accepts(tags map[string]string) bool {
+	if _, ok := options.Highways[tags["highway"]]; !ok {
+		return false
+	}
+	for key, values := range options.Exclude {
+		value, ok := tags[key]
+		if !ok {
+			continue
+		}
+		for _, excluded := range values {
+			if value == excluded {
+				return false
+			}
+		}
+	}
+	return tags["oneway"] != "reversible" && tags["oneway"] != "alternating"
+}
+
+// speed Returns speed (km/h) for travel time: 'maxspeed' tag or default speed of the road class
+func (options OSMOptions) speed(tags map[string]string) float64 {
+	if speed, ok := parseOSMMaxSpeed(tags["maxspeed"]); ok {
+		return speed
+	}
+	return options.Highways[tags["highway"]]
+}
+
+// attributes Returns attributes of edges cut from the way
+func (options OSMOptions) attributes(way osmWay) spatial.EdgeAttributes {
+	attributes := spatial.EdgeAttributes{
+		"highway":            way.tags["highway"],
+		OSM_WAY_ID_ATTRIBUTE: way.id,
+	}
+	for _, name := range options.Tags {
+		value, ok := way.tags[name]
+		if !ok {
+			continue
+		}
+		if name == "maxspeed" {
+			if speed, ok := parseOSMMaxSpeed(value); ok {
+				attributes[name] = speed
+				continue
+			}
+		}
+		attributes[name] = value
+	}
+	return attributes
+}
+
+// parseOSMMaxSpeed Returns speed (km/h) for value of 'maxspeed' tag, e.g. '60', '60 km/h' or '30 mph'. Symbolic values like 'RU:urban' or 'none' are not parsed
+func parseOSMMaxSpeed(value string) (float64, bool) {
+	// Multiple values (e.g. for lanes) are separated by semicolon: the first one is used
+	value = strings.TrimSpace(strings.Split(value, ";")[0])
+	factor := 1.0
+	if strings.HasSuffix(value, "mph") {
+		factor = 1.609344
+		value = strings.TrimSpace(strings.TrimSuffix(value, "mph"))
+	} else {
+		value = strings.TrimSpace(strings.TrimSuffix(value, "km/h"))
+	}
+	speed, err := strconv.ParseFloat(value, 64)
+	if err != nil || speed <= 0 {
+		return 0, false
+	}
+	return speed * factor, true
+}
+
+// osmDirection Returns direction of the way: 1 (one-way along nodes order), -1 (one-way against nodes order) or 0 (two-way)
+func osmDirection(tags map[string]string) int {
+	switch tags["oneway"] {
+	case "yes", "true", "1":
+		return 1
+	case "-1", "reverse":
+		return -1
+	case "no", "false", "0":
+		return 0
+	}
+	switch {
+	case tags["highway"] == "motorway":
+		return 1
+	case tags["junction"] == "roundabout" || tags["junction"] == "circular":
+		return 1
+	}
+	return 0
+}
+
+// splitOSMWay Returns sequences of nodes of edges cut from the way
+/*
+	points - coordinates of nodes. Way is broken at nodes missing in the extract
+	references - number of references to nodes (capped at 2). Way is split at nodes shared with other ways
+
+	Repeated consecutive nodes are dropped. Closed segment (loop) is split in the middle, so edges never start and end at the same vertex
+*/
+func splitOSMWay(way osmWay, points map[int64]s2.Point, references map[int64]uint8) [][]int64 {
+	segments := [][]int64{}
+	current := []int64{}
+	flush := func() {
+		if len(current) >= 2 {
+			segments = append(segments, splitOSMLoop(current)...)
+		}
+		current = []int64{}
+	}
+	for _, ref := range way.refs {
+		if _, ok := points[ref]; !ok {
+			flush()
+			continue
+		}
+		if len(current) > 0 && current[len(current)-1] == ref {
+			continue
+		}
+		current = append(current, ref)
+		if len(current) > 1 && references[ref] > 1 {
+			flush()
+			current = append(current, ref)
+		}
+	}
+	flush()
+	return segments
+}
+
+// splitOSMLoop Returns segment as is or its two halves when it starts and ends at the same node. Loop of two nodes is dropped
+func splitOSMLoop(segment []int64) [][]int64 {
+	if segment[0] != segment[len(segment)-1] {
+		return [][]int64{segment}
+	}
+	if len(segment) < 4 {
+		return nil
+	}
+	middle := len(segment) / 2
+	return [][]int64{segment[:middle+1], segment[middle:]}
+}
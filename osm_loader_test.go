@@ -0,0 +1,287 @@
+package horizon
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// testOSMWay Way to be encoded into test OSM PBF file
+type testOSMWay struct {
+	id   int64
+	refs []int64
+	tags [][2]string
+}
+
+// writeTestOSMPBF Writes OSM PBF file with header block and data block. Nodes are dense except the last one
+func writeTestOSMPBF(t *testing.T, features []string, nodes map[int64][2]float64, nodeOrder []int64, ways []testOSMWay) string {
+	var header []byte
+	for _, feature := range features {
+		header = protowire.AppendTag(header, 4, protowire.BytesType)
+		header = protowire.AppendString(header, feature)
+	}
+
+	stringTable := []string{""}
+	stringIdx := func(s string) uint64 {
+		for i := range stringTable {
+			if stringTable[i] == s {
+				return uint64(i)
+			}
+		}
+		stringTable = append(stringTable, s)
+		return uint64(len(stringTable) - 1)
+	}
+	// Default granularity is 100 nanodegrees
+	encodeCoordinate := func(value float64) int64 {
+		return int64(math.Round(value * 1e7))
+	}
+	var group []byte
+	var ids, lats, lons []byte
+	var prevID, prevLat, prevLon int64
+	for _, id := range nodeOrder[:len(nodeOrder)-1] {
+		lat, lon := encodeCoordinate(nodes[id][0]), encodeCoordinate(nodes[id][1])
+		ids = protowire.AppendVarint(ids, protowire.EncodeZigZag(id-prevID))
+		lats = protowire.AppendVarint(lats, protowire.EncodeZigZag(lat-prevLat))
+		lons = protowire.AppendVarint(lons, protowire.EncodeZigZag(lon-prevLon))
+		prevID, prevLat, prevLon = id, lat, lon
+	}
+	var dense []byte
+	for _, field := range []struct {
+		num  protowire.Number
+		data []byte
+	}{{1, ids}, {8, lats}, {9, lons}} {
+		dense = protowire.AppendTag(dense, field.num, protowire.BytesType)
+		dense = protowire.AppendBytes(dense, field.data)
+	}
+	group = protowire.AppendTag(group, 2, protowire.BytesType)
+	group = protowire.AppendBytes(group, dense)
+
+	lastID := nodeOrder[len(nodeOrder)-1]
+	var node []byte
+	node = protowire.AppendTag(node, 1, protowire.VarintType)
+	node = protowire.AppendVarint(node, protowire.EncodeZigZag(lastID))
+	node = protowire.AppendTag(node, 8, protowire.VarintType)
+	node = protowire.AppendVarint(node, protowire.EncodeZigZag(encodeCoordinate(nodes[lastID][0])))
+	node = protowire.AppendTag(node, 9, protowire.VarintType)
+	node = protowire.AppendVarint(node, protowire.EncodeZigZag(encodeCoordinate(nodes[lastID][1])))
+	group = protowire.AppendTag(group, 1, protowire.BytesType)
+	group = protowire.AppendBytes(group, node)
+
+	for _, way := range ways {
+		var keys, values, refs []byte
+		for _, tag := range way.tags {
+			keys = protowire.AppendVarint(keys, stringIdx(tag[0]))
+			values = protowire.AppendVarint(values, stringIdx(tag[1]))
+		}
+		prevRef := int64(0)
+		for _, ref := range way.refs {
+			refs = protowire.AppendVarint(refs, protowire.EncodeZigZag(ref-prevRef))
+			prevRef = ref
+		}
+		var encoded []byte
+		encoded = protowire.AppendTag(encoded, 1, protowire.VarintType)
+		encoded = protowire.AppendVarint(encoded, uint64(way.id))
+		for _, field := range []struct {
+			num  protowire.Number
+			data []byte
+		}{{2, keys}, {3, values}, {8, refs}} {
+			encoded = protowire.AppendTag(encoded, field.num, protowire.BytesType)
+			encoded = protowire.AppendBytes(encoded, field.data)
+		}
+		group = protowire.AppendTag(group, 3, protowire.BytesType)
+		group = protowire.AppendBytes(group, encoded)
+	}
+
+	var table []byte
+	for _, s := range stringTable {
+		table = protowire.AppendTag(table, 1, protowire.BytesType)
+		table = protowire.AppendString(table, s)
+	}
+	var block []byte
+	block = protowire.AppendTag(block, 1, protowire.BytesType)
+	block = protowire.AppendBytes(block, table)
+	block = protowire.AppendTag(block, 2, protowire.BytesType)
+	block = protowire.AppendBytes(block, group)
+
+	var file bytes.Buffer
+	writeBlob := func(blobType string, data []byte, compress bool) {
+		var blob []byte
+		if compress {
+			var compressed bytes.Buffer
+			zw := zlib.NewWriter(&compressed)
+			zw.Write(data)
+			zw.Close()
+			blob = protowire.AppendTag(blob, 2, protowire.VarintType)
+			blob = protowire.AppendVarint(blob, uint64(len(data)))
+			blob = protowire.AppendTag(blob, 3, protowire.BytesType)
+			blob = protowire.AppendBytes(blob, compressed.Bytes())
+		} else {
+			blob = protowire.AppendTag(blob, 1, protowire.BytesType)
+			blob = protowire.AppendBytes(blob, data)
+		}
+		var blobHeader []byte
+		blobHeader = protowire.AppendTag(blobHeader, 1, protowire.BytesType)
+		blobHeader = protowire.AppendString(blobHeader, blobType)
+		blobHeader = protowire.AppendTag(blobHeader, 3, protowire.VarintType)
+		blobHeader = protowire.AppendVarint(blobHeader, uint64(len(blob)))
+		binary.Write(&file, binary.BigEndian, uint32(len(blobHeader)))
+		file.Write(blobHeader)
+		file.Write(blob)
+	}
+	writeBlob("OSMHeader", header, false)
+	writeBlob("OSMData", block, true)
+
+	filename := filepath.Join(t.TempDir(), "map.osm.pbf")
+	err := os.WriteFile(filename, file.Bytes(), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// testOSMData Returns nodes and ways of small town: two-way primary road 1-2-3, one-way residential street 2-4-5, one-way roundabout 5-7-8-5,
+// footway 3-6 and private service road 5-6 (the last two are filtered out by default profile)
+func testOSMData() (map[int64][2]float64, []int64, []testOSMWay) {
+	nodes := map[int64][2]float64{
+		1: {55.7500, 37.6000},
+		2: {55.7500, 37.6020},
+		3: {55.7500, 37.6040},
+		4: {55.7510, 37.6020},
+		5: {55.7520, 37.6020},
+		6: {55.7520, 37.6040},
+		7: {55.7530, 37.6025},
+		8: {55.7530, 37.6015},
+	}
+	ways := []testOSMWay{
+		{100, []int64{1, 2, 3}, [][2]string{{"highway", "primary"}, {"name", "Main street"}}},
+		{200, []int64{2, 4, 5}, [][2]string{{"highway", "residential"}, {"oneway", "yes"}, {"maxspeed", "20"}}},
+		{300, []int64{3, 6}, [][2]string{{"highway", "footway"}}},
+		{400, []int64{5, 6}, [][2]string{{"highway", "service"}, {"access", "private"}}},
+		{500, []int64{5, 7, 8, 5}, [][2]string{{"highway", "tertiary"}, {"junction", "roundabout"}}},
+	}
+	return nodes, []int64{1, 2, 3, 4, 5, 6, 7, 8}, ways
+}
+
+func TestOSMImport(t *testing.T) {
+	nodes, order, ways := testOSMData()
+	filename := writeTestOSMPBF(t, []string{"OsmSchema-V0.6", "DenseNodes"}, nodes, order, ways)
+	engine, err := NewMapEngineFromOSM(filename, DefaultOSMOptions(), WithWeightProfiles(OSM_WEIGHT_LENGTH))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Primary road is split at node 2, roundabout is split in the middle
+	expected := map[int64][2]int64{1: {1, 2}, 2: {2, 1}, 3: {2, 3}, 4: {3, 2}, 5: {2, 5}, 7: {5, 8}, 9: {8, 5}}
+	if len(engine.edges) != len(expected) {
+		t.Errorf("Expected %d edges, but got %d", len(expected), len(engine.edges))
+	}
+	for edgeID, vertices := range expected {
+		edge, ok := engine.edges[edgeID]
+		if !ok {
+			t.Errorf("Edge %d should exist", edgeID)
+			continue
+		}
+		if edge.Source != vertices[0] || edge.Target != vertices[1] {
+			t.Errorf("Edge %d should go from %d to %d, but got %d and %d", edgeID, vertices[0], vertices[1], edge.Source, edge.Target)
+		}
+	}
+	if len(engine.vertices) != 5 {
+		t.Errorf("Only endpoints of edges should be vertices, but got %d vertices", len(engine.vertices))
+	}
+	if len(*engine.edges[5].Polyline) != 3 {
+		t.Errorf("Edge 5 should keep intermediate node 4, but got %d points", len(*engine.edges[5].Polyline))
+	}
+
+	// Residential street has 'maxspeed' = 20 km/h: travel time is length divided by 20/3.6 m/s
+	length, _ := engine.profiles[OSM_WEIGHT_LENGTH].weight(engine.edges[5])
+	if length < 200 || length > 250 {
+		t.Errorf("Edge 5 should be about 222 meters long, but got %f", length)
+	}
+	if math.Abs(engine.edges[5].Weight-length/(20/3.6)) > 1e-9 {
+		t.Errorf("Weight of edge 5 should be travel time %f, but got %f", length/(20/3.6), engine.edges[5].Weight)
+	}
+	attributes, _ := engine.EdgeAttributes(2)
+	if name, _ := attributes.String("name"); name != "Main street" {
+		t.Errorf("Edge 2 should have name of the way, but got %v", attributes)
+	}
+	if wayID, _ := attributes.Int(OSM_WAY_ID_ATTRIBUTE); wayID != 100 {
+		t.Errorf("Edge 2 should be cut from way 100, but got %v", attributes)
+	}
+	attributes, _ = engine.EdgeAttributes(5)
+	if speed, _ := attributes.Float("maxspeed"); speed != 20 {
+		t.Errorf("Edge 5 should have parsed speed limit, but got %v", attributes)
+	}
+
+	// Export to CSV files and load them back
+	edgesFilename := filepath.Join(t.TempDir(), "graph.csv")
+	err = engine.ExportCSV(edgesFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher, err := NewMapMatcherFromFiles(NewHmmProbabilities(50, 30), edgesFilename, WithWeightProfiles(OSM_WEIGHT_LENGTH))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := matcher.engine
+	if len(loaded.edges) != len(engine.edges) || len(loaded.vertices) != len(engine.vertices) {
+		t.Errorf("Loaded graph should have %d edges and %d vertices, but got %d and %d", len(engine.edges), len(engine.vertices), len(loaded.edges), len(loaded.vertices))
+	}
+	if loaded.edges[5].Weight != engine.edges[5].Weight {
+		t.Errorf("Weight of edge 5 should be %f, but got %f", engine.edges[5].Weight, loaded.edges[5].Weight)
+	}
+	if weight, ok := loaded.profiles[OSM_WEIGHT_LENGTH].weight(loaded.edges[5]); !ok || weight != length {
+		t.Errorf("Length of edge 5 should be %f, but got %f", length, weight)
+	}
+	attributes, _ = loaded.EdgeAttributes(1)
+	if name, _ := attributes.String("name"); name != "Main street" {
+		t.Errorf("Edge 1 should keep name after export, but got %v", attributes)
+	}
+	if highway, _ := attributes.String("highway"); highway != "primary" {
+		t.Errorf("Edge 1 should keep road class after export, but got %v", attributes)
+	}
+	_, err = matcher.FindShortestPath(NewGPSMeasurementFromID(1, 37.6001, 55.7500, 4326), NewGPSMeasurementFromID(2, 37.6016, 55.7530, 4326), -1)
+	if err != nil {
+		t.Errorf("Path from primary road to roundabout should be found in loaded graph: %v", err)
+	}
+}
+
+func TestOSMImportErrors(t *testing.T) {
+	nodes, order, ways := testOSMData()
+	filename := writeTestOSMPBF(t, []string{"OsmSchema-V0.6", "HistoricalInformation"}, nodes, order, ways)
+	_, err := NewMapEngineFromOSM(filename, DefaultOSMOptions())
+	if errors.Cause(err) != ErrOSMFormat {
+		t.Errorf("Expected error '%v' for unsupported feature, but got '%v'", ErrOSMFormat, err)
+	}
+
+	filename = writeTestOSMPBF(t, []string{"OsmSchema-V0.6"}, nodes, order, ways)
+	options := DefaultOSMOptions()
+	options.Highways = map[string]float64{"motorway": 110}
+	_, err = NewMapEngineFromOSM(filename, options)
+	if errors.Cause(err) != ErrEmptyGraph {
+		t.Errorf("Expected error '%v' when nothing matches tag profile, but got '%v'", ErrEmptyGraph, err)
+	}
+
+	truncated := filepath.Join(t.TempDir(), "truncated.osm.pbf")
+	data, _ := os.ReadFile(filename)
+	os.WriteFile(truncated, data[:len(data)-10], 0644)
+	_, err = NewMapEngineFromOSM(truncated, DefaultOSMOptions())
+	if errors.Cause(err) != ErrOSMFormat {
+		t.Errorf("Expected error '%v' for truncated file, but got '%v'", ErrOSMFormat, err)
+	}
+}
+
+func TestParseOSMMaxSpeed(t *testing.T) {
+	cases := map[string]float64{"60": 60, "60 km/h": 60, "30 mph": 48.28032, "50;30": 50, "RU:urban": 0, "none": 0, "": 0}
+	for value, expected := range cases {
+		speed, ok := parseOSMMaxSpeed(value)
+		if ok != (expected != 0) || math.Abs(speed-expected) > 1e-9 {
+			t.Errorf("Speed for '%s' should be %f, but got %f", value, expected, speed)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package horizon
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Limits of OSM PBF blocks (see https://wiki.openstreetmap.org/wiki/PBF_Format#File_format)
+const (
+	osmMaxBlobHeaderSize = 64 * 1024
+	osmMaxBlobSize       = 32 * 1024 * 1024
+)
+
+// osmSupportedFeatures Required features of OSM PBF file which reader understands
+var osmSupportedFeatures = map[string]bool{
+	"OsmSchema-V0.6": true,
+	"DenseNodes":     true,
+}
+
+// osmWay Way of OSM PBF file with its node references and tags
+type osmWay struct {
+	id   int64
+	refs []int64
+	tags map[string]string
+}
+
+// osmPBFHandler Callbacks of OSM PBF reader. Nil callback means that such elements are skipped without decoding
+/*
+	node - called for every node (both plain and dense ones) with its coordinates in degrees
+	way - called for every way
+*/
+type osmPBFHandler struct {
+	node func(id int64, lat, lon float64)
+	way  func(way osmWay)
+}
+
+// readOSMPBF Reads OSM PBF stream block by block and passes its elements to the handler.
+// Relations are skipped. Only zlib-compressed and raw blobs are supported
+func readOSMPBF(r io.Reader, handler osmPBFHandler) error {
+	reader := bufio.NewReader(r)
+	sizeBuf := make([]byte, 4)
+	for blockIdx := 0; ; blockIdx++ {
+		_, err := io.ReadFull(reader, sizeBuf)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(ErrOSMFormat, "block #%d: can't read size of blob header: %s", blockIdx, err)
+		}
+		headerSize := binary.BigEndian.Uint32(sizeBuf)
+		if headerSize > osmMaxBlobHeaderSize {
+			return errors.Wrapf(ErrOSMFormat, "block #%d: blob header is too big (%d bytes)", blockIdx, headerSize)
+		}
+		headerBuf := make([]byte, headerSize)
+		_, err = io.ReadFull(reader, headerBuf)
+		if err != nil {
+			return errors.Wrapf(ErrOSMFormat, "block #%d: can't read blob header: %s", blockIdx, err)
+		}
+		blobType, blobSize, err := parseOSMBlobHeader(headerBuf)
+		if err != nil {
+			return errors.Wrapf(err, "block #%d", blockIdx)
+		}
+		if blobSize > osmMaxBlobSize {
+			return errors.Wrapf(ErrOSMFormat, "block #%d: blob is too big (%d bytes)", blockIdx, blobSize)
+		}
+		blobBuf := make([]byte, blobSize)
+		_, err = io.ReadFull(reader, blobBuf)
+		if err != nil {
+			return errors.Wrapf(ErrOSMFormat, "block #%d: can't read blob: %s", blockIdx, err)
+		}
+		switch blobType {
+		case "OSMHeader":
+			data, err := unpackOSMBlob(blobBuf)
+			if err != nil {
+				return errors.Wrapf(err, "block #%d", blockIdx)
+			}
+			err = checkOSMHeader(data)
+			if err != nil {
+				return errors.Wrapf(err, "block #%d", blockIdx)
+			}
+		case "OSMData":
+			if handler.node == nil && handler.way == nil {
+				continue
+			}
+			data, err := unpackOSMBlob(blobBuf)
+			if err != nil {
+				return errors.Wrapf(err, "block #%d", blockIdx)
+			}
+			err = parseOSMPrimitiveBlock(data, handler)
+			if err != nil {
+				return errors.Wrapf(err, "block #%d", blockIdx)
+			}
+		default:
+			// Unknown blobs should be skipped according to specification
+		}
+	}
+}
+
+// osmFields Iterates over fields of protobuf message. Returns error for malformed message
+func osmFields(data []byte, field func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return errors.Wrapf(ErrOSMFormat, "malformed field tag: %s", protowire.ParseError(n))
+		}
+		data = data[n:]
+		var value []byte
+		var varint uint64
+		switch typ {
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(data)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return errors.Wrapf(ErrOSMFormat, "malformed field %d: %s", num, protowire.ParseError(n))
+		}
+		data = data[n:]
+		err := field(num, typ, value, varint)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// osmVarints Returns values of repeated varint field: packed field gives several values, unpacked one gives single value per call
+func osmVarints(typ protowire.Type, value []byte, varint uint64) ([]uint64, error) {
+	if typ == protowire.VarintType {
+		return []uint64{varint}, nil
+	}
+	if typ != protowire.BytesType {
+		return nil, errors.Wrapf(ErrOSMFormat, "unexpected wire type %d of repeated field", typ)
+	}
+	ans := make([]uint64, 0, len(value))
+	for len(value) > 0 {
+		v, n := protowire.ConsumeVarint(value)
+		if n < 0 {
+			return nil, errors.Wrapf(ErrOSMFormat, "malformed packed field: %s", protowire.ParseError(n))
+		}
+		ans = append(ans, v)
+		value = value[n:]
+	}
+	return ans, nil
+}
+
+// parseOSMBlobHeader Returns type and size of the blob
+func parseOSMBlobHeader(data []byte) (string, int, error) {
+	blobType := ""
+	blobSize := -1
+	err := osmFields(data, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch num {
+		case 1:
+			blobType = string(value)
+		case 3:
+			blobSize = int(int32(varint))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	if blobType == "" || blobSize < 0 {
+		return "", 0, errors.Wrap(ErrOSMFormat, "blob header has no type or size")
+	}
+	return blobType, blobSize, nil
+}
+
+// unpackOSMBlob Returns uncompressed data of the blob
+func unpackOSMBlob(data []byte) ([]byte, error) {
+	var raw, zlibData []byte
+	rawSize := -1
+	compression := ""
+	err := osmFields(data, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch num {
+		case 1:
+			raw = value
+		case 2:
+			rawSize = int(int32(varint))
+		case 3:
+			zlibData = value
+		case 4:
+			compression = "lzma"
+		case 5:
+			compression = "bzip2"
+		case 6:
+			compression = "lz4"
+		case 7:
+			compression = "zstd"
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case raw != nil:
+		return raw, nil
+	case zlibData != nil:
+		if rawSize < 0 || rawSize > osmMaxBlobSize {
+			return nil, errors.Wrapf(ErrOSMFormat, "invalid raw size %d of compressed blob", rawSize)
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(zlibData))
+		if err != nil {
+			return nil, errors.Wrapf(ErrOSMFormat, "can't decompress blob: %s", err)
+		}
+		defer zr.Close()
+		buf := make([]byte, rawSize)
+		_, err = io.ReadFull(zr, buf)
+		if err != nil {
+			return nil, errors.Wrapf(ErrOSMFormat, "can't decompress blob: %s", err)
+		}
+		return buf, nil
+	case compression != "":
+		return nil, errors.Wrapf(ErrOSMFormat, "%s compression of blobs is not supported", compression)
+	}
+	return nil, errors.Wrap(ErrOSMFormat, "blob has no data")
+}
+
+// checkOSMHeader Checks that every required feature of the file is supported
+func checkOSMHeader(data []byte) error {
+	return osmFields(data, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		if num == 4 && !osmSupportedFeatures[string(value)] {
+			return errors.Wrapf(ErrOSMFormat, "required feature '%s' is not supported", value)
+		}
+		return nil
+	})
+}
+
+// osmBlock Parameters of primitive block which are needed to decode its elements
+type osmBlock struct {
+	strings     []string
+	granularity int64
+	latOffset   int64
+	lonOffset   int64
+}
+
+// coordinate Returns coordinate in degrees for encoded value
+func (block *osmBlock) coordinate(offset, value int64) float64 {
+	return 1e-9 * float64(offset+block.granularity*value)
+}
+
+// tags Returns tags for indices of keys and values in string table
+func (block *osmBlock) tags(keys, values []uint64) (map[string]string, error) {
+	if len(keys) != len(values) {
+		return nil, errors.Wrapf(ErrOSMFormat, "%d keys and %d values of tags", len(keys), len(values))
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	tags := make(map[string]string, len(keys))
+	for i := range keys {
+		if keys[i] >= uint64(len(block.strings)) || values[i] >= uint64(len(block.strings)) {
+			return nil, errors.Wrapf(ErrOSMFormat, "index of tag is out of string table")
+		}
+		tags[block.strings[keys[i]]] = block.strings[values[i]]
+	}
+	return tags, nil
+}
+
+// parseOSMPrimitiveBlock Decodes nodes and ways of the block and passes them to the handler
+func parseOSMPrimitiveBlock(data []byte, handler osmPBFHandler) error {
+	block := osmBlock{granularity: 100}
+	groups := [][]byte{}
+	err := osmFields(data, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch num {
+		case 1:
+			return osmFields(value, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+				if num == 1 {
+					block.strings = append(block.strings, string(value))
+				}
+				return nil
+			})
+		case 2:
+			groups = append(groups, value)
+		case 17:
+			block.granularity = int64(int32(varint))
+		case 19:
+			block.latOffset = int64(varint)
+		case 20:
+			block.lonOffset = int64(varint)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		err = osmFields(group, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+			switch {
+			case num == 1 && handler.node != nil:
+				return block.parseNode(value, handler.node)
+			case num == 2 && handler.node != nil:
+				return block.parseDenseNodes(value, handler.node)
+			case num == 3 && handler.way != nil:
+				return block.parseWay(value, handler.way)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseNode Decodes plain node
+func (block *osmBlock) parseNode(data []byte, node func(id int64, lat, lon float64)) error {
+	var id, lat, lon int64
+	err := osmFields(data, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch num {
+		case 1:
+			id = protowire.DecodeZigZag(varint)
+		case 8:
+			lat = protowire.DecodeZigZag(varint)
+		case 9:
+			lon = protowire.DecodeZigZag(varint)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	node(id, block.coordinate(block.latOffset, lat), block.coordinate(block.lonOffset, lon))
+	return nil
+}
+
+// parseDenseNodes Decodes delta-coded dense nodes
+func (block *osmBlock) parseDenseNodes(data []byte, node func(id int64, lat, lon float64)) error {
+	var ids, lats, lons []uint64
+	err := osmFields(data, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		var values []uint64
+		var err error
+		switch num {
+		case 1, 8, 9:
+			values, err = osmVarints(typ, value, varint)
+		}
+		switch num {
+		case 1:
+			ids = append(ids, values...)
+		case 8:
+			lats = append(lats, values...)
+		case 9:
+			lons = append(lons, values...)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(ids) != len(lats) || len(ids) != len(lons) {
+		return errors.Wrapf(ErrOSMFormat, "dense nodes have %d identifiers, %d latitudes and %d longitudes", len(ids), len(lats), len(lons))
+	}
+	var id, lat, lon int64
+	for i := range ids {
+		id += protowire.DecodeZigZag(ids[i])
+		lat += protowire.DecodeZigZag(lats[i])
+		lon += protowire.DecodeZigZag(lons[i])
+		node(id, block.coordinate(block.latOffset, lat), block.coordinate(block.lonOffset, lon))
+	}
+	return nil
+}
+
+// parseWay Decodes way with its tags and delta-coded references to nodes
+func (block *osmBlock) parseWay(data []byte, way func(way osmWay)) error {
+	var id int64
+	var keys, values, refs []uint64
+	err := osmFields(data, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		var parsed []uint64
+		var err error
+		switch num {
+		case 1:
+			id = int64(varint)
+		case 2, 3, 8:
+			parsed, err = osmVarints(typ, value, varint)
+		}
+		switch num {
+		case 2:
+			keys = append(keys, parsed...)
+		case 3:
+			values = append(values, parsed...)
+		case 8:
+			refs = append(refs, parsed...)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	tags, err := block.tags(keys, values)
+	if err != nil {
+		return errors.Wrapf(err, "way %d", id)
+	}
+	decoded := make([]int64, len(refs))
+	ref := int64(0)
+	for i := range refs {
+		ref += protowire.DecodeZigZag(refs[i])
+		decoded[i] = ref
+	}
+	way(osmWay{id: id, refs: decoded, tags: tags})
+	return nil
+}
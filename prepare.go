@@ -0,0 +1,181 @@
+package horizon
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/pkg/errors"
+)
+
+// ContractionStats Statistics of contraction hierarchies prepared by PrepareShortcuts
+/*
+	Vertices - number of vertices in graph
+	Edges - number of edges in graph
+	Shortcuts - number of shortcuts added by contraction
+	Duration - time spent for contraction (reading and writing of files are not included)
+*/
+type ContractionStats struct {
+	Vertices  int64
+	Edges     int64
+	Shortcuts int64
+	Duration  time.Duration
+}
+
+// String Returns human-readable statistics
+func (stats ContractionStats) String() string {
+	ratio := 0.0
+	if stats.Edges > 0 {
+		ratio = float64(stats.Shortcuts) / float64(stats.Edges)
+	}
+	return fmt.Sprintf("vertices: %d, edges: %d, shortcuts: %d (%.2f per edge), contraction time: %v", stats.Vertices, stats.Edges, stats.Shortcuts, ratio, stats.Duration)
+}
+
+// PrepareShortcuts Prepares contraction hierarchies for edges file and writes vertices and shortcuts files next to it (see NewMapMatcherFromFiles)
+/*
+	edgesFilename - path to the edges CSV file (e.g., "graph.csv"). Files "graph_vertices.csv" and "graph_shortcuts.csv" are overwritten
+	engineOpts - options of the engine, e.g. WithGraphSRID for geometries in projected CRS
+
+	Vertices file gets order positions and importance of vertices, geometry of vertices is taken from endpoints of edges.
+	Additional weight profiles are not contracted: they are prepared on load anyway
+*/
+func PrepareShortcuts(edgesFilename string, engineOpts ...func(*MapEngine)) (ContractionStats, error) {
+	engine := NewMapEngineDefault()
+	for _, opt := range engineOpts {
+		opt(engine)
+	}
+	if !spatial.IsSRIDSupported(engine.graphSRID) {
+		return ContractionStats{}, errors.Wrapf(spatial.ErrUnknownSRID, "Can't load graph with SRID %d", engine.graphSRID)
+	}
+	// Weights of profiles are not needed
+	engine.profileColumns = nil
+
+	edgesFilename, verticesFilename, shortcutsFilename := csvFilenames(edgesFilename)
+	_, err := engine.readEdgesCSV(edgesFilename)
+	if err != nil {
+		return ContractionStats{}, err
+	}
+	if len(engine.edges) == 0 {
+		return ContractionStats{}, errors.Wrapf(ErrEmptyGraph, "edges file '%s'", edgesFilename)
+	}
+	engine.verticesFromEdges()
+
+	st := time.Now()
+	engine.graph.PrepareContractionHierarchies()
+	stats := ContractionStats{
+		Vertices:  engine.graph.GetVerticesNum(),
+		Edges:     int64(len(engine.edges)),
+		Shortcuts: engine.graph.GetShortcutsNum(),
+		Duration:  time.Since(st),
+	}
+
+	err = engine.exportVerticesCSV(verticesFilename)
+	if err != nil {
+		return stats, err
+	}
+	err = engine.graph.ExportShortcutsToFile(shortcutsFilename)
+	if err != nil {
+		return stats, errors.Wrapf(err, "Can't write shortcuts file '%s'", shortcutsFilename)
+	}
+	return stats, nil
+}
+
+// contractInOrder Adds shortcuts of contraction hierarchy for order positions of vertices which are already set (e.g. read from vertices file).
+// Vertices are contracted one by one in ascending order: shortcut from u to w via v is added if there is no shorter witness path
+// from u to w among vertices which are not contracted yet. Returns number of added shortcuts
+func (engine *MapEngine) contractInOrder() (int, error) {
+	order := make([]int64, len(engine.graph.Vertices))
+	position := make(map[int64]int64, len(engine.graph.Vertices))
+	for i := range engine.graph.Vertices {
+		order[i] = engine.graph.Vertices[i].Label
+		position[engine.graph.Vertices[i].Label] = engine.graph.Vertices[i].OrderPos()
+	}
+	sort.Slice(order, func(i, j int) bool { return position[order[i]] < position[order[j]] })
+
+	// Remaining graph: the cheapest edge for every pair of vertices
+	outgoing := make(map[int64]map[int64]float64, len(order))
+	incoming := make(map[int64]map[int64]float64, len(order))
+	link := func(source, target int64, weight float64) {
+		if outgoing[source] == nil {
+			outgoing[source] = make(map[int64]float64)
+		}
+		if incoming[target] == nil {
+			incoming[target] = make(map[int64]float64)
+		}
+		if previous, ok := outgoing[source][target]; !ok || weight < previous {
+			outgoing[source][target] = weight
+			incoming[target][source] = weight
+		}
+	}
+	for _, edge := range engine.edges {
+		if edge.Source != edge.Target {
+			link(edge.Source, edge.Target, edge.Weight)
+		}
+	}
+
+	shortcutsNum := 0
+	contracted := make(map[int64]bool, len(order))
+	for _, via := range order {
+		contracted[via] = true
+		for source, toVia := range incoming[via] {
+			if contracted[source] {
+				continue
+			}
+			maxCost := 0.0
+			for target, fromVia := range outgoing[via] {
+				if !contracted[target] && target != source && toVia+fromVia > maxCost {
+					maxCost = toVia + fromVia
+				}
+			}
+			if maxCost == 0 {
+				continue
+			}
+			witness := witnessCosts(outgoing, contracted, source, maxCost)
+			for target, fromVia := range outgoing[via] {
+				if contracted[target] || target == source {
+					continue
+				}
+				cost := toVia + fromVia
+				if found, ok := witness[target]; ok && found <= cost {
+					continue
+				}
+				err := engine.graph.AddEdge(source, target, cost)
+				if err != nil {
+					return shortcutsNum, errors.Wrapf(err, "Can't add shortcut from %d to %d", source, target)
+				}
+				err = engine.graph.AddShortcut(source, target, via, cost)
+				if err != nil {
+					return shortcutsNum, errors.Wrapf(err, "Can't add shortcut from %d to %d via %d", source, target, via)
+				}
+				link(source, target, cost)
+				shortcutsNum++
+			}
+		}
+	}
+	return shortcutsNum, nil
+}
+
+// witnessCosts Returns costs of paths from source among vertices which are not contracted, bounded by max cost
+func witnessCosts(outgoing map[int64]map[int64]float64, contracted map[int64]bool, source int64, maxCost float64) map[int64]float64 {
+	costs := map[int64]float64{source: 0}
+	queue := &dijkstraHeap{{vertex: source, cost: 0}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(dijkstraItem)
+		if current.cost > costs[current.vertex] || current.cost > maxCost {
+			continue
+		}
+		for target, weight := range outgoing[current.vertex] {
+			if contracted[target] {
+				continue
+			}
+			cost := current.cost + weight
+			if previous, ok := costs[target]; !ok || cost < previous {
+				costs[target] = cost
+				heap.Push(queue, dijkstraItem{vertex: target, cost: cost})
+			}
+		}
+	}
+	return costs
+}
@@ -0,0 +1,203 @@
+package horizon
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+)
+
+// writeEdgesOnlyCSV Writes edges file of builderTestEdges graph (SRID = 0) without vertices and shortcuts files
+func writeEdgesOnlyCSV(t *testing.T) string {
+	engine, err := NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(builderTestEdges()...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	edgesFilename := filepath.Join(t.TempDir(), "graph.csv")
+	err = engine.ExportCSV(edgesFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, verticesFilename, shortcutsFilename := csvFilenames(edgesFilename)
+	os.Remove(verticesFilename)
+	os.Remove(shortcutsFilename)
+	return edgesFilename
+}
+
+// checkPreparedMatcher Checks that graph is loaded and shortest path goes along two-way road
+func checkPreparedMatcher(t *testing.T, matcher *MapMatcher) {
+	if len(matcher.engine.vertices) != 5 || len(matcher.engine.edges) != 7 {
+		t.Errorf("Expected 5 vertices and 7 edges, but got %d and %d", len(matcher.engine.vertices), len(matcher.engine.edges))
+	}
+	result, err := matcher.FindShortestPath(NewGPSMeasurementFromID(1, 2.6, 0.1, 0), NewGPSMeasurementFromID(2, 12.4, 0.1, 0), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source, target := result.SubMatches[0].Observations[0].MatchedEdge.ID, result.SubMatches[0].Observations[1].MatchedEdge.ID; source != 1 || target != 23 {
+		t.Errorf("Path should go from edge 1 to edge 23, but got %d and %d", source, target)
+	}
+}
+
+// gridEdges Returns two-way grid of n x n vertices with step 10 along X and Y axes: contraction of such graph gives shortcuts
+func gridEdges(n int64) []*spatial.Edge {
+	edges := []*spatial.Edge{}
+	addEdge := func(source, target int64) {
+		polyline := s2.Polyline{
+			spatial.NewEuclideanS2Point(float64(source%n)*10, float64(source/n)*10),
+			spatial.NewEuclideanS2Point(float64(target%n)*10, float64(target/n)*10),
+		}
+		edges = append(edges, &spatial.Edge{ID: int64(len(edges) + 1), Source: source, Target: target, Weight: 10, Polyline: &polyline})
+	}
+	for i := int64(0); i < n; i++ {
+		for j := int64(0); j < n; j++ {
+			vertex := i*n + j
+			if j+1 < n {
+				addEdge(vertex, vertex+1)
+				addEdge(vertex+1, vertex)
+			}
+			if i+1 < n {
+				addEdge(vertex, vertex+n)
+				addEdge(vertex+n, vertex)
+			}
+		}
+	}
+	return edges
+}
+
+func TestPrepareShortcuts(t *testing.T) {
+	engine, err := NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(gridEdges(5)...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	edgesFilename := filepath.Join(t.TempDir(), "grid.csv")
+	err = engine.exportEdgesCSV(edgesFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := PrepareShortcuts(edgesFilename, WithGraphSRID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Vertices != 25 || stats.Edges != 80 || stats.Shortcuts == 0 {
+		t.Errorf("Expected 25 vertices, 80 edges and some shortcuts in stats, but got %+v", stats)
+	}
+
+	// Order positions must be taken from vertices file rather than evaluated again
+	_, verticesFilename, _ := csvFilenames(edgesFilename)
+	file, err := os.Open(verticesFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.Comma = ';'
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 26 || len(records[0]) != 4 || records[0][3] != "geom" {
+		t.Fatalf("Vertices file should have header and 25 vertices with geometry, but got %v", records)
+	}
+	loaded, err := prepareEngine(edgesFilename, WithGraphSRID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records[1:] {
+		vertexID, _ := strconv.ParseInt(record[0], 10, 64)
+		vertexInternal, ok := loaded.graph.FindVertex(vertexID)
+		if !ok {
+			t.Fatalf("Vertex %d should be loaded", vertexID)
+		}
+		if orderPos := strconv.FormatInt(loaded.graph.Vertices[vertexInternal].OrderPos(), 10); orderPos != record[1] {
+			t.Errorf("Vertex %d should have order position %s, but got %s", vertexID, record[1], orderPos)
+		}
+	}
+	if loaded.graph.GetShortcutsNum() != stats.Shortcuts {
+		t.Errorf("Expected %d shortcuts, but got %d", stats.Shortcuts, loaded.graph.GetShortcutsNum())
+	}
+	cost, path := loaded.queryPool.ShortestPath(0, 24)
+	if len(path) != 9 || cost != 80 {
+		t.Errorf("Path between corners of grid should have 9 vertices and cost 80, but got %v and %f", path, cost)
+	}
+}
+
+func TestLoadWithoutOrder(t *testing.T) {
+	// There is no vertices file at all
+	edgesFilename := writeEdgesOnlyCSV(t)
+	matcher, err := NewMapMatcherFromFiles(NewHmmProbabilities(50, 30), edgesFilename, WithGraphSRID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPreparedMatcher(t, matcher)
+
+	// Vertices file has geometry only
+	_, verticesFilename, _ := csvFilenames(edgesFilename)
+	content := "vertex_id;geom\n0;POINT(0 0)\n1;POINT(5 0)\n2;POINT(10 0)\n3;POINT(15 0)\n4;POINT(15 5)\n"
+	err = os.WriteFile(verticesFilename, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher, err = NewMapMatcherFromFiles(NewHmmProbabilities(50, 30), edgesFilename, WithGraphSRID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPreparedMatcher(t, matcher)
+}
+
+func TestLoadOrderWithoutShortcuts(t *testing.T) {
+	// Every vertex of the fixture has order position, but contraction gives no shortcuts
+	loaded, err := prepareEngine("./test_data/matcher_4326_test.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[int64]int64{101: 0, 102: 5}
+	for vertexID, orderPos := range expected {
+		vertexInternal, _ := loaded.graph.FindVertex(vertexID)
+		if got := loaded.graph.Vertices[vertexInternal].OrderPos(); got != orderPos {
+			t.Errorf("Vertex %d should keep order position %d from vertices file, but got %d", vertexID, orderPos, got)
+		}
+	}
+
+	// Shortcuts are prepared for the given order when shortcuts file is empty
+	engine, err := NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(gridEdges(5)...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	edgesFilename := filepath.Join(t.TempDir(), "grid.csv")
+	err = engine.exportEdgesCSV(edgesFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = PrepareShortcuts(edgesFilename, WithGraphSRID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, shortcutsFilename := csvFilenames(edgesFilename)
+	err = os.WriteFile(shortcutsFilename, []byte("from_vertex_id;to_vertex_id;weight;via_vertex_id\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = prepareEngine(edgesFilename, WithGraphSRID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.graph.GetShortcutsNum() == 0 {
+		t.Error("Shortcuts should be prepared for the given order")
+	}
+	for source := int64(0); source < 25; source++ {
+		for target := int64(0); target < 25; target++ {
+			if source == target {
+				continue
+			}
+			expectedCost, _ := engine.queryPool.ShortestPath(source, target)
+			cost, _ := loaded.queryPool.ShortestPath(source, target)
+			if cost != expectedCost {
+				t.Fatalf("Path from %d to %d should have cost %f, but got %f", source, target, expectedCost, cost)
+			}
+		}
+	}
+}
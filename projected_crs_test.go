@@ -0,0 +1,150 @@
+package horizon
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// reprojectCSV Copies CSV file replacing every WGS84 coordinates pair in WKT geometries with coordinates in the projection
+func reprojectCSV(t *testing.T, src, dst string, projection spatial.Projection) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair := regexp.MustCompile(`(-?\d+\.\d+) (-?\d+\.\d+)`)
+	reprojected := pair.ReplaceAllStringFunc(string(data), func(s string) string {
+		parts := strings.Fields(s)
+		lon, _ := strconv.ParseFloat(parts[0], 64)
+		lat, _ := strconv.ParseFloat(parts[1], 64)
+		x, y := projection.Forward(lon, lat)
+		return fmt.Sprintf("%.6f %.6f", x, y)
+	})
+	err = os.WriteFile(dst, []byte(reprojected), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMapMatcherProjectedCRS(t *testing.T) {
+	// Graph is in Web Mercator
+	dir := t.TempDir()
+	for _, suffix := range []string{".csv", "_vertices.csv", "_shortcuts.csv"} {
+		reprojectCSV(t, "./test_data/matcher_4326_test"+suffix, filepath.Join(dir, "matcher_3857_test"+suffix), spatial.WebMercator{})
+	}
+	hmmParams := NewHmmProbabilities(50.0, 2.0)
+	expectedMatcher, err := NewMapMatcherFromFiles(hmmParams, "./test_data/matcher_4326_test.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher, err := NewMapMatcherFromFiles(hmmParams, filepath.Join(dir, "matcher_3857_test.csv"), WithGraphSRID(spatial.SRID_WEB_MERCATOR))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedEdge := expectedMatcher.engine.edgesBetween(101, 102)[0]
+	edge := matcher.engine.edgesBetween(101, 102)[0]
+	if length, expectedLength := spatial.PolylineLength(*edge.Polyline), spatial.PolylineLength(*expectedEdge.Polyline); math.Abs(length-expectedLength) > 1e-3 {
+		t.Errorf("Length of reprojected edge should be %f, but got %f", expectedLength, length)
+	}
+
+	// Observations are in UTM zone 37N
+	utmSRID := spatial.SRID_UTM_NORTH_BASE + 37
+	utm, err := spatial.ProjectionBySRID(utmSRID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lonLats := [][2]float64{
+		{37.662745994981435, 55.77323867786974},
+		{37.66373679411533, 55.77352528537278},
+		{37.6634658408828, 55.77408712095024},
+		{37.66271768643477, 55.77491052526131},
+	}
+	expectedGPS := GPSMeasurements{}
+	gps := GPSMeasurements{}
+	for i, lonLat := range lonLats {
+		expectedGPS = append(expectedGPS, NewGPSMeasurementFromID(i, lonLat[0], lonLat[1], 4326))
+		x, y := utm.Forward(lonLat[0], lonLat[1])
+		gps = append(gps, NewGPSMeasurementFromID(i, x, y, utmSRID))
+	}
+	expected, err := expectedMatcher.Run(expectedGPS, 7.0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := matcher.Run(gps, 7.0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.SubMatches) != len(expected.SubMatches) {
+		t.Fatalf("Expected %d sub-matches, but got %d", len(expected.SubMatches), len(result.SubMatches))
+	}
+	for s := range result.SubMatches {
+		if math.Abs(result.SubMatches[s].Probability-expected.SubMatches[s].Probability) > 1e-3 {
+			t.Errorf("Probability of sub-match #%d should be %f, but got %f", s, expected.SubMatches[s].Probability, result.SubMatches[s].Probability)
+		}
+		for i, observation := range result.SubMatches[s].Observations {
+			expectedObservation := expected.SubMatches[s].Observations[i]
+			if observation.MatchedEdge.ID != expectedObservation.MatchedEdge.ID {
+				t.Errorf("Observation #%d of sub-match #%d should be matched to edge %d, but got %d", i, s, expectedObservation.MatchedEdge.ID, observation.MatchedEdge.ID)
+			}
+			if observation.Observation.SRID() != utmSRID {
+				t.Errorf("SRID of observation #%d of sub-match #%d should be %d, but got %d", i, s, utmSRID, observation.Observation.SRID())
+			}
+			// Results are returned in caller's CRS
+			x, y, err := spatial.S2PointToSRID(observation.ProjectedPoint, observation.Observation.SRID())
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedX, expectedY, _ := spatial.S2PointToSRID(expectedObservation.ProjectedPoint, utmSRID)
+			if math.Abs(x-expectedX) > 1e-3 || math.Abs(y-expectedY) > 1e-3 {
+				t.Errorf("Projection of observation #%d of sub-match #%d should be (%f, %f), but got (%f, %f)", i, s, expectedX, expectedY, x, y)
+			}
+		}
+	}
+	x, y := gps[0].Coordinates()
+	expectedX, expectedY := utm.Forward(lonLats[0][0], lonLats[0][1])
+	if math.Abs(x-expectedX) > 1e-2 || math.Abs(y-expectedY) > 1e-2 {
+		t.Errorf("Coordinates of observation should be (%f, %f), but got (%f, %f)", expectedX, expectedY, x, y)
+	}
+}
+
+func TestUnknownSRID(t *testing.T) {
+	engine, err := NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(gridEdges(3)...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher := NewMapMatcher(WithMapEngine(engine))
+	source := NewGPSMeasurement(1, 0, 0, 0)
+	unknown := NewGPSMeasurement(2, 20, 20, 999999)
+	if unknown.SRID() != 999999 {
+		t.Errorf("Measurement should keep its SRID, but got %d", unknown.SRID())
+	}
+	if _, err = matcher.FindShortestPath(source, unknown, 5); errors.Cause(err) != spatial.ErrUnknownSRID {
+		t.Errorf("Shortest path for unknown SRID should give ErrUnknownSRID, but got %v", err)
+	}
+	if _, err = matcher.Nearest(unknown, 1, 5); errors.Cause(err) != spatial.ErrUnknownSRID {
+		t.Errorf("Nearest edges for unknown SRID should give ErrUnknownSRID, but got %v", err)
+	}
+
+	// Merged route geometry is encoded in requested CRS
+	line := RouteLine{Geom: s2.Polyline{spatial.NewWGS84Point(37.6, 55.75).Point, spatial.NewWGS84Point(37.61, 55.75).Point}}
+	encoded, err := line.EncodeSRID(ROUTE_FORMAT_WKT, spatial.SRID_WEB_MERCATOR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := spatial.S2PolylineToWKTSRID(line.Geom, spatial.SRID_WEB_MERCATOR)
+	if encoded != expected {
+		t.Errorf("WKT should be %s, but got %s", expected, encoded)
+	}
+	if _, err = line.EncodeSRID(ROUTE_FORMAT_POLYLINE, 999999); errors.Cause(err) != spatial.ErrUnknownSRID {
+		t.Errorf("Encoding in unknown SRID should give ErrUnknownSRID, but got %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package horizon
+
+import (
+	"math"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// QueryOptions Per-request options for map matching, shortest path and isochrones
+/*
+	ExcludedEdges - identifiers of edges which must be neither candidates nor traversed by routing (e.g. road closures)
+	AvoidPolygons - areas to avoid. Every edge having common points with any of polygons is excluded.
+		For SRID = 4326 graphs polygons are expected to be spherical (see spatial.RingsToS2Polygon).
+		For SRID = 0 graphs polygons are expected to hold raw Cartesian coordinates.
+	Profile - name of weight profile used for routing, transitions and isochrones. Empty string stands for DEFAULT_PROFILE
+	Reverse - isochrones only: search on reversed graph, so costs are the ones of reaching the source point from vertices (catchment area).
+		Map matching and shortest path ignore it
+	EmissionFilter - edges rejected by the filter are never candidates for observations (so they can't be matched), but they still could be traversed by routes
+	RoutingFilter - edges rejected by the filter are excluded: they are neither candidates nor traversed by routing (the same as ExcludedEdges)
+*/
+type QueryOptions struct {
+	ExcludedEdges  []int64
+	AvoidPolygons  []*s2.Polygon
+	Profile        string
+	Reverse        bool
+	EmissionFilter EdgeFilter
+	RoutingFilter  EdgeFilter
+}
+
+// QueryOption is a functional option for configuring single request
+type QueryOption func(*QueryOptions)
+
+// WithExcludedEdges excludes edges with given identifiers from the request
+func WithExcludedEdges(edgeIDs ...int64) QueryOption {
+	return func(o *QueryOptions) {
+		o.ExcludedEdges = append(o.ExcludedEdges, edgeIDs...)
+	}
+}
+
+// WithAvoidPolygons excludes edges intersecting given polygons from the request
+func WithAvoidPolygons(polygons ...*s2.Polygon) QueryOption {
+	return func(o *QueryOptions) {
+		o.AvoidPolygons = append(o.AvoidPolygons, polygons...)
+	}
+}
+
+// WithProfile selects weight profile for the request
+func WithProfile(name string) QueryOption {
+	return func(o *QueryOptions) {
+		o.Profile = name
+	}
+}
+
+// WithReverse makes isochrones to be searched on reversed graph ("who can reach this point" instead of "where can I get to")
+func WithReverse() QueryOption {
+	return func(o *QueryOptions) {
+		o.Reverse = true
+	}
+}
+
+// WithEmissionFilter makes edges rejected by the filter never be candidates for observations. Several filters are combined: edge must pass each of them
+func WithEmissionFilter(filter EdgeFilter) QueryOption {
+	return func(o *QueryOptions) {
+		o.EmissionFilter = o.EmissionFilter.And(filter)
+	}
+}
+
+// WithRoutingFilter excludes edges rejected by the filter from the request. Several filters are combined: edge must pass each of them
+func WithRoutingFilter(filter EdgeFilter) QueryOption {
+	return func(o *QueryOptions) {
+		o.RoutingFilter = o.RoutingFilter.And(filter)
+	}
+}
+
+// routingQuery Resolved per-request state which is shared by candidates search and routing
+/*
+	engine - engine which the request is bound to
+	profile - weight profile used for the request
+	excluded - set of excluded edges identifiers. If it is empty then contraction hierarchies are used for routing, otherwise Dijkstra's algorithm is used as a fallback
+	reverse - isochrones are searched on reversed graph (always by Dijkstra's algorithm)
+	emissionFilter - edges rejected by the filter are not candidates (could be nil)
+*/
+type routingQuery struct {
+	engine         *MapEngine
+	profile        *weightProfile
+	excluded       map[int64]struct{}
+	reverse        bool
+	emissionFilter EdgeFilter
+}
+
+// prepareQuery Resolves provided options against the engine
+func (engine *MapEngine) prepareQuery(opts ...QueryOption) (*routingQuery, error) {
+	options := &QueryOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	profile, err := engine.getProfile(options.Profile)
+	if err != nil {
+		return nil, err
+	}
+	query := &routingQuery{
+		engine:         engine,
+		profile:        profile,
+		reverse:        options.Reverse,
+		emissionFilter: options.EmissionFilter,
+	}
+	if len(options.ExcludedEdges) == 0 && len(options.AvoidPolygons) == 0 && options.RoutingFilter == nil {
+		return query, nil
+	}
+	query.excluded = make(map[int64]struct{}, len(options.ExcludedEdges))
+	for _, edgeID := range options.ExcludedEdges {
+		query.excluded[edgeID] = struct{}{}
+	}
+	for i, polygon := range options.AvoidPolygons {
+		edgeIDs, err := engine.edgesIntersectingPolygon(polygon)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't resolve edges for avoid-polygon #%d", i)
+		}
+		for _, edgeID := range edgeIDs {
+			query.excluded[edgeID] = struct{}{}
+		}
+	}
+	if options.RoutingFilter != nil {
+		for edgeID, edge := range engine.edges {
+			if !options.RoutingFilter(edge, engine.attributes[edgeID]) {
+				query.excluded[edgeID] = struct{}{}
+			}
+		}
+	}
+	return query, nil
+}
+
+// hasExclusions Returns true if at least one edge has been excluded for the request
+func (query *routingQuery) hasExclusions() bool {
+	return len(query.excluded) > 0
+}
+
+// isExcluded Checks if edge is excluded for the request
+func (query *routingQuery) isExcluded(edgeID int64) bool {
+	if len(query.excluded) == 0 {
+		return false
+	}
+	_, ok := query.excluded[edgeID]
+	return ok
+}
+
+// filterNearest Removes excluded edges and edges rejected by emission filter from set of nearest objects
+func (query *routingQuery) filterNearest(nearestObjects []spatial.NearestObject) []spatial.NearestObject {
+	if !query.hasExclusions() && query.emissionFilter == nil {
+		return nearestObjects
+	}
+	filtered := nearestObjects[:0:0]
+	for _, obj := range nearestObjects {
+		if query.isExcluded(int64(obj.EdgeID)) {
+			continue
+		}
+		if query.emissionFilter != nil {
+			if edge, ok := query.engine.edges[int64(obj.EdgeID)]; ok && !query.emissionFilter(edge, query.engine.attributes[edge.ID]) {
+				continue
+			}
+		}
+		filtered = append(filtered, obj)
+	}
+	return filtered
+}
+
+// shortestPath Returns cost and vertices of the shortest path between two vertices.
+// Cost is -1 when path does not exist
+func (query *routingQuery) shortestPath(source, target int64) (float64, []int64) {
+	if !query.hasExclusions() {
+		return query.profile.queryPool.ShortestPath(source, target)
+	}
+	return query.dijkstraShortestPath(source, target)
+}
+
+// isochrones Returns costs of reaching vertices within maxCost from the source vertex (costs of reaching the source vertex for reverse requests)
+func (query *routingQuery) isochrones(source int64, maxCost float64) (map[int64]float64, error) {
+	if !query.hasExclusions() && !query.reverse {
+		return query.profile.graph.Isochrones(source, maxCost)
+	}
+	return query.dijkstraIsochrones(source, maxCost), nil
+}
+
+// adjacentEdges Returns edges which are traversed by isochrones from the vertex: outgoing ones or incoming ones for reverse requests
+func (query *routingQuery) adjacentEdges(vertex int64) []*spatial.Edge {
+	if query.reverse {
+		return query.engine.incomingEdges(vertex)
+	}
+	return query.engine.outgoingEdges(vertex)
+}
+
+// weight Returns cost of the whole edge for the request's profile.
+// Edges which are not traversable for the profile get math.MaxFloat64
+func (query *routingQuery) weight(edge *spatial.Edge) float64 {
+	weight, ok := query.profile.weight(edge)
+	if !ok {
+		return math.MaxFloat64
+	}
+	return weight
+}
+
+// partialWeight Returns cost of moving along the part of the edge with given length for the request's profile
+func (query *routingQuery) partialWeight(edge *spatial.Edge, length float64) float64 {
+	if query.profile.weights == nil {
+		// Keep lengths for default profile
+		return length
+	}
+	edgeLength := query.engine.edgeLength(edge)
+	if edgeLength == 0 {
+		return 0
+	}
+	return query.weight(edge) * length / edgeLength
+}
+
+// edgeResult Returns EdgeResult for the edge with cost evaluated for the request's profile
+func (query *routingQuery) edgeResult(edge *spatial.Edge) EdgeResult {
+	edgeGeomCopy := make(s2.Polyline, len(*edge.Polyline))
+	copy(edgeGeomCopy, *edge.Polyline)
+	return EdgeResult{
+		Geom:       edgeGeomCopy,
+		Weight:     query.weight(edge),
+		Length:     query.engine.edgeLength(edge),
+		ID:         edge.ID,
+		Attributes: query.engine.attributes[edge.ID],
+	}
+}
+
+// matchedEdge Returns copy of the edge with weight evaluated for the request's profile
+func (query *routingQuery) matchedEdge(edge *spatial.Edge) spatial.Edge {
+	edgeCopy := *edge
+	edgeCopy.Weight = query.weight(edge)
+	return edgeCopy
+}
+
+// edgesIntersectingPolygon Returns identifiers of edges having common points with polygon
+func (engine *MapEngine) edgesIntersectingPolygon(polygon *s2.Polygon) ([]int64, error) {
+	if polygon == nil || polygon.NumLoops() == 0 {
+		return nil, nil
+	}
+	found, err := engine.storage.FindInPolygon(polygon)
+	if err != nil {
+		return nil, err
+	}
+	edgeIDs := make([]int64, 0, len(found))
+	for _, edgeID := range found {
+		edgeIDs = append(edgeIDs, int64(edgeID))
+	}
+	return edgeIDs, nil
+}
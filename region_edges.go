@@ -0,0 +1,91 @@
+package horizon
+
+import (
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// RegionEdge Representation of the edge found inside of the region (rectangle or polygon)
+/*
+	Edge - found edge
+	Weight - travel cost of the whole edge for the request's weight profile
+	Length - length of the whole edge (meters for WGS84 graphs)
+	Attributes - attributes of the edge (nil if edge has no attributes)
+*/
+type RegionEdge struct {
+	Edge       *spatial.Edge
+	Weight     float64
+	Length     float64
+	Attributes spatial.EdgeAttributes
+}
+
+// EdgesInRect Returns edges having common points with rectangle sorted by identifier
+/*
+	lo - one corner of rectangle
+	hi - opposite corner of rectangle
+	opts - per-request options (see QueryOptions). Excluded edges and edges which are not traversable for the request's profile are never returned
+*/
+func (matcher *MapMatcher) EdgesInRect(lo, hi *GPSMeasurement, opts ...QueryOption) ([]RegionEdge, error) {
+	engine := matcher.Engine()
+	err := checkMeasurementsSRID(lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	query, err := engine.prepareQuery(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't prepare query")
+	}
+	found, err := engine.storage.FindInRect(lo.Point, hi.Point)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't find edges in rectangle")
+	}
+	return engine.regionEdges(query, found), nil
+}
+
+// EdgesInPolygon Returns edges having common points with polygon sorted by identifier
+/*
+	polygon - region of search. For Euclidean graphs loops should hold raw Cartesian coordinates (see spatial.RingsToS2Polygon)
+	opts - per-request options (see QueryOptions). Excluded edges and edges which are not traversable for the request's profile are never returned
+*/
+func (matcher *MapMatcher) EdgesInPolygon(polygon *s2.Polygon, opts ...QueryOption) ([]RegionEdge, error) {
+	engine := matcher.Engine()
+	query, err := engine.prepareQuery(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't prepare query")
+	}
+	found, err := engine.edgesIntersectingPolygon(polygon)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't find edges in polygon")
+	}
+	edgeIDs := make([]uint64, 0, len(found))
+	for _, edgeID := range found {
+		edgeIDs = append(edgeIDs, uint64(edgeID))
+	}
+	return engine.regionEdges(query, edgeIDs), nil
+}
+
+// regionEdges Resolves found identifiers to edges with respect to the request's exclusions and profile
+func (engine *MapEngine) regionEdges(query *routingQuery, edgeIDs []uint64) []RegionEdge {
+	ans := make([]RegionEdge, 0, len(edgeIDs))
+	for _, edgeID := range edgeIDs {
+		if query.isExcluded(int64(edgeID)) {
+			continue
+		}
+		edge := engine.storage.GetEdge(edgeID)
+		if edge == nil || edge.Polyline == nil {
+			continue
+		}
+		weight, ok := query.profile.weight(edge)
+		if !ok {
+			continue
+		}
+		ans = append(ans, RegionEdge{
+			Edge:       edge,
+			Weight:     weight,
+			Length:     engine.edgeLength(edge),
+			Attributes: engine.attributes[edge.ID],
+		})
+	}
+	return ans
+}
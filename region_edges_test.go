@@ -0,0 +1,70 @@
+package horizon
+
+import (
+	"testing"
+
+	"github.com/LdDl/horizon/spatial"
+)
+
+func regionEdgeIDs(edges []RegionEdge) []int64 {
+	ids := make([]int64, 0, len(edges))
+	for _, edge := range edges {
+		ids = append(ids, edge.Edge.ID)
+	}
+	return ids
+}
+
+func TestEdgesInRect(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	lo := NewGPSMeasurementFromID(1, 6, 1, 0)
+	hi := NewGPSMeasurementFromID(2, 1, -1, 0)
+	tests := []struct {
+		opts     []QueryOption
+		expected []int64
+	}{
+		// Edge 4 touches the corner (1, 1)
+		{nil, []int64{2, 3, 4}},
+		{[]QueryOption{WithProfile("truck_time")}, []int64{2, 3}},
+		{[]QueryOption{WithExcludedEdges(3)}, []int64{2, 4}},
+	}
+	for i, test := range tests {
+		edges, err := matcher.EdgesInRect(lo, hi, test.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := regionEdgeIDs(edges)
+		if len(ids) != len(test.expected) {
+			t.Errorf("Test #%d: edges should be %v, got %v", i, test.expected, ids)
+			continue
+		}
+		for j := range ids {
+			if ids[j] != test.expected[j] {
+				t.Errorf("Test #%d: edges should be %v, got %v", i, test.expected, ids)
+				break
+			}
+		}
+	}
+	edges, err := matcher.EdgesInRect(lo, hi, WithProfile("truck_time"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edges[0].Weight != 50 || edges[0].Length != 5 {
+		t.Errorf("Edge 2 should have weight 50 and length 5 for 'truck_time' profile, got %f and %f", edges[0].Weight, edges[0].Length)
+	}
+}
+
+func TestEdgesInPolygon(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	polygon, err := spatial.RingsToS2Polygon([][][]float64{{{6, 1}, {9, 1}, {9, 4}, {6, 4}}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edges, err := matcher.EdgesInPolygon(polygon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := regionEdgeIDs(edges)
+	if len(ids) != 1 || ids[0] != 5 {
+		t.Errorf("Only edge 5 should be found inside of polygon, got %v", ids)
+	}
+}
@@ -0,0 +1,184 @@
+package horizon
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// GraphInfo Information about active road graph of the matcher
+/*
+	Version - version of graph: initial graph has version 1, every replacement increments it
+	Source - where graph has been loaded from (e.g. filename). Empty if unknown
+	LoadedAt - time when graph has become active
+	LoadDuration - time spent for loading of graph (zero if unknown)
+	Edges - number of edges
+	Vertices - number of vertices
+	Profiles - names of weight profiles
+*/
+type GraphInfo struct {
+	Version      int64
+	Source       string
+	LoadedAt     time.Time
+	LoadDuration time.Duration
+	Edges        int
+	Vertices     int
+	Profiles     []string
+}
+
+// graphVersion Graph which replaced initial graph of the matcher
+type graphVersion struct {
+	engine *MapEngine
+	info   GraphInfo
+}
+
+// Snapshot Returns matcher pinned to the active graph: it is not affected by further replacements of graph.
+// Use it once per request, so the whole request is served by the same graph even if graph is reloaded meanwhile
+func (matcher *MapMatcher) Snapshot() *MapMatcher {
+	if matcher.snapshot {
+		return matcher
+	}
+	engine, info := matcher.engine, matcher.info
+	if active := matcher.active.Load(); active != nil {
+		engine, info = active.engine, active.info
+	}
+	return &MapMatcher{
+		hmmParams:        matcher.hmmParams,
+		engine:           engine,
+		viterbiSemaphore: matcher.viterbiSemaphore,
+		info:             info,
+		snapshot:         true,
+	}
+}
+
+// Engine Returns active engine of the matcher. Every method of MapMatcher takes it once and serves the whole call with it
+func (matcher *MapMatcher) Engine() *MapEngine {
+	return matcher.Snapshot().engine
+}
+
+// GraphInfo Returns information about active graph
+func (matcher *MapMatcher) GraphInfo() GraphInfo {
+	pinned := matcher.Snapshot()
+	info := pinned.info
+	if pinned.engine != nil {
+		info.Edges = len(pinned.engine.edges)
+		info.Vertices = len(pinned.engine.vertices)
+		info.Profiles = pinned.engine.Profiles()
+	}
+	return info
+}
+
+// Reload Loads new graph and replaces active one if it is valid (see ReplaceEngine)
+/*
+	source - where graph is loaded from (e.g. filename), it is reported by GraphInfo
+	load - function which loads new engine, e.g. NewMapEngineFromOSM or MapEngineBuilder.Build
+
+	Loading is done in the calling goroutine while requests are served by the active graph.
+	Returns ErrReloadInProgress if another reload is running
+*/
+func (matcher *MapMatcher) Reload(source string, load func() (*MapEngine, error)) (GraphInfo, error) {
+	if matcher.snapshot {
+		return GraphInfo{}, errors.Wrap(ErrReloadSnapshot, "Can't reload graph")
+	}
+	if !matcher.reloading.CompareAndSwap(false, true) {
+		return GraphInfo{}, ErrReloadInProgress
+	}
+	defer matcher.reloading.Store(false)
+	st := time.Now()
+	engine, err := load()
+	if err != nil {
+		return GraphInfo{}, errors.Wrapf(err, "Can't load graph from '%s'", source)
+	}
+	return matcher.ReplaceEngine(engine, source, time.Since(st))
+}
+
+// IsReloading Checks if graph is being reloaded (see Reload)
+func (matcher *MapMatcher) IsReloading() bool {
+	return matcher.reloading.Load()
+}
+
+// ReplaceEngine Atomically replaces active engine of the matcher
+/*
+	engine - new engine
+	source - where graph has been loaded from (e.g. filename), it is reported by GraphInfo
+	loadDuration - time spent for loading of graph, it is reported by GraphInfo
+
+	New engine must be prepared (see MapEngineBuilder), pass checks of edges, geometry and connected components of ValidateGraph
+	(with DefaultValidationOptions), have the same SRID and every weight profile of the active engine,
+	otherwise ErrInconsistentGraph is returned and the active engine is kept. Facilities are snapped to the new graph again
+	(ones without edge within their snap radius are dropped).
+	Requests which have already taken Snapshot are finished with the previous engine
+*/
+func (matcher *MapMatcher) ReplaceEngine(engine *MapEngine, source string, loadDuration time.Duration) (GraphInfo, error) {
+	if matcher.snapshot {
+		return GraphInfo{}, errors.Wrap(ErrReloadSnapshot, "Can't replace engine")
+	}
+	matcher.reloadMu.Lock()
+	defer matcher.reloadMu.Unlock()
+	current := matcher.Snapshot()
+	err := validateReplacement(current.engine, engine)
+	if err != nil {
+		return GraphInfo{}, err
+	}
+	if current.engine != nil {
+		facilities := current.engine.Facilities()
+		if len(facilities) > 0 {
+			plain := make([]Facility, len(facilities))
+			for i := range facilities {
+				plain[i] = facilities[i].Facility
+			}
+			_, err = engine.SetFacilities(plain, current.engine.facilitiesRadius)
+			if err != nil {
+				return GraphInfo{}, errors.Wrap(err, "Can't snap facilities to new graph")
+			}
+		}
+	}
+	version := &graphVersion{
+		engine: engine,
+		info: GraphInfo{
+			Version:      current.info.Version + 1,
+			Source:       source,
+			LoadedAt:     time.Now(),
+			LoadDuration: loadDuration,
+		},
+	}
+	matcher.active.Store(version)
+	return matcher.GraphInfo(), nil
+}
+
+// validateReplacement Checks that new engine could replace the current one (see validateEngine for checks of edges and components)
+func validateReplacement(current, engine *MapEngine) error {
+	if engine == nil || len(engine.edges) == 0 {
+		return ErrEmptyGraph
+	}
+	if engine.queryPool == nil {
+		return errors.Wrap(ErrInconsistentGraph, "contraction hierarchies are not prepared")
+	}
+	for _, edge := range engine.edges {
+		for _, vertexID := range []int64{edge.Source, edge.Target} {
+			if _, ok := engine.vertices[vertexID]; !ok {
+				return errors.Wrapf(ErrInconsistentGraph, "vertex %d of edge %d is not found", vertexID, edge.ID)
+			}
+			if _, ok := engine.graph.FindVertex(vertexID); !ok {
+				return errors.Wrapf(ErrInconsistentGraph, "vertex %d of edge %d is not found in contraction hierarchy", vertexID, edge.ID)
+			}
+		}
+	}
+	report := validateEngine(engine, DefaultValidationOptions())
+	if len(report.Issues) > 0 {
+		issue := report.Issues[0]
+		return errors.Wrapf(ErrInconsistentGraph, "%s: %s (%d issues in total)", issue.Kind, issue.Message, len(report.Issues))
+	}
+	if current == nil {
+		return nil
+	}
+	if engine.graphSRID != current.graphSRID {
+		return errors.Wrapf(ErrInconsistentGraph, "SRID of new graph is %d, but active graph has %d", engine.graphSRID, current.graphSRID)
+	}
+	for _, name := range current.Profiles() {
+		if !engine.HasProfile(name) {
+			return errors.Wrapf(ErrInconsistentGraph, "weight profile '%s' is missing in new graph", name)
+		}
+	}
+	return nil
+}
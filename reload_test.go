@@ -0,0 +1,138 @@
+package horizon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+)
+
+func TestReloadGraph(t *testing.T) {
+	initial, err := NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(builderTestEdges()...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher := NewMapMatcher(WithMapEngine(initial), WithGraphSource("initial.csv", 0))
+	if info := matcher.GraphInfo(); info.Version != 1 || info.Source != "initial.csv" || info.Edges != 7 || info.LoadedAt.IsZero() {
+		t.Errorf("Wrong info of initial graph: %+v", info)
+	}
+	_, err = matcher.SetFacilities([]Facility{{ID: 1, Point: spatial.NewEuclideanS2Point(10, 1)}}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pinned := matcher.Snapshot()
+	info, err := matcher.Reload("grid.csv", func() (*MapEngine, error) {
+		return NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(gridEdges(5)...).Build()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != 2 || info.Source != "grid.csv" || info.Edges != 80 || info.Vertices != 25 {
+		t.Errorf("Wrong info of reloaded graph: %+v", info)
+	}
+	if matcher.Engine() == initial || len(matcher.Facilities()) != 1 {
+		t.Error("Engine should be replaced and facilities should be snapped to the new graph")
+	}
+	// Snapshot taken before reload keeps the previous graph
+	if pinned.Engine() != initial || pinned.GraphInfo().Version != 1 {
+		t.Error("Snapshot should keep initial graph")
+	}
+	if _, err = pinned.Reload("grid.csv", nil); !errors.Is(err, ErrReloadSnapshot) {
+		t.Errorf("Reload of snapshot should give ErrReloadSnapshot, but got %v", err)
+	}
+
+	// Invalid graphs are rejected and the active one is kept
+	active := matcher.Engine()
+	wrongSRID, err := NewMapEngineBuilder(WithGraphSRID(3857)).AddEdges(gridEdges(3)...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = matcher.ReplaceEngine(wrongSRID, "", 0); !errors.Is(err, ErrInconsistentGraph) {
+		t.Errorf("Graph with different SRID should give ErrInconsistentGraph, but got %v", err)
+	}
+	if _, err = matcher.ReplaceEngine(nil, "", 0); !errors.Is(err, ErrEmptyGraph) {
+		t.Errorf("Nil engine should give ErrEmptyGraph, but got %v", err)
+	}
+	if _, err = matcher.Reload("broken.csv", func() (*MapEngine, error) { return nil, ErrEmptyGraph }); !errors.Is(err, ErrEmptyGraph) {
+		t.Errorf("Error of loading should be returned, but got %v", err)
+	}
+	if matcher.Engine() != active || matcher.GraphInfo().Version != 2 {
+		t.Error("Active graph should be kept after failed reloads")
+	}
+}
+
+func TestReloadInProgress(t *testing.T) {
+	initial, err := NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(builderTestEdges()...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher := NewMapMatcher(WithMapEngine(initial))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := matcher.Reload("grid.csv", func() (*MapEngine, error) {
+			close(started)
+			<-release
+			return NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(gridEdges(3)...).Build()
+		})
+		done <- err
+	}()
+	<-started
+	if !matcher.IsReloading() {
+		t.Error("Matcher should report running reload")
+	}
+	if _, err = matcher.Reload("grid.csv", nil); !errors.Is(err, ErrReloadInProgress) {
+		t.Errorf("Concurrent reload should give ErrReloadInProgress, but got %v", err)
+	}
+	// Requests are served by the active graph while new one is loading
+	if matcher.Engine() != initial {
+		t.Error("Initial graph should be active during reload")
+	}
+	close(release)
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+	if matcher.IsReloading() || matcher.GraphInfo().Version != 2 {
+		t.Error("Reload should be finished")
+	}
+}
+
+func TestReloadValidatesEngine(t *testing.T) {
+	initial, err := NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(gridEdges(3)...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher := NewMapMatcher(WithMapEngine(initial))
+
+	// Geometry of edge goes from its target vertex to the source one
+	reversed, err := NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(gridEdges(3)...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	edge := reversed.edges[1]
+	polyline := *edge.Polyline
+	backward := s2.Polyline{polyline[1], polyline[0]}
+	edge.Polyline = &backward
+	if _, err = matcher.ReplaceEngine(reversed, "", 0); !errors.Is(err, ErrInconsistentGraph) {
+		t.Errorf("Graph with reversed geometry should give ErrInconsistentGraph, but got %v", err)
+	}
+	if edge.Polyline != &backward {
+		t.Error("Validation should not modify edges of new engine")
+	}
+
+	// Stored components do not match edges
+	stale, err := NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(gridEdges(3)...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.vertexStrongComponent[0] = -100
+	if _, err = matcher.ReplaceEngine(stale, "", 0); !errors.Is(err, ErrInconsistentGraph) {
+		t.Errorf("Graph with stale components should give ErrInconsistentGraph, but got %v", err)
+	}
+	if matcher.Engine() != initial || matcher.GraphInfo().Version != 1 {
+		t.Error("Invalid graphs should not replace active one")
+	}
+}
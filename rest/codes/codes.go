@@ -14,6 +14,13 @@ type Success201 struct {
 	Status string `json:"Status" example:"Created"`
 }
 
+// Success202 Accepted
+// swagger:model
+type Success202 struct {
+	// Code text
+	Status string `json:"Status" example:"Accepted"`
+}
+
 // Error500 Internal Server Error
 // swagger:model
 type Error500 struct {
@@ -0,0 +1,212 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/LdDl/horizon"
+	"github.com/gofiber/fiber/v2"
+	geojson "github.com/paulmach/go.geojson"
+)
+
+// FacilityRequest Single facility (point of interest)
+// swagger:model
+type FacilityRequest struct {
+	// Identifier of the facility
+	ID int64 `json:"id" example:"1"`
+	// Optional human readable name
+	Name string `json:"name" example:"Charger #1"`
+	// [Longitude, Latitude] (or [X, Y] for projected SRID)
+	LonLat [2]float64 `json:"lon_lat" example:"37.601249363208915,55.745374309126895"`
+}
+
+// SetFacilitiesRequest User's request for uploading set of facilities. Existing set is replaced
+// swagger:model
+type SetFacilitiesRequest struct {
+	// Max distance between facility and its edge.
+	// Use -1 for no limit, 0 for default (100m), or positive value.
+	Radius *float64 `json:"radius" example:"100.0"`
+	// New set of facilities
+	Facilities []FacilityRequest `json:"facilities"`
+	// Coordinate reference system of facilities
+	SRIDRequest
+}
+
+// SetFacilitiesResponse Server's response for uploading set of facilities
+// swagger:model
+type SetFacilitiesResponse struct {
+	// Number of stored facilities
+	Loaded int `json:"loaded" example:"10"`
+	// Identifiers of facilities which have no edge within the radius (they are not stored)
+	Skipped []int64 `json:"skipped" example:"11"`
+	// Warnings
+	Warnings []string `json:"warnings" example:"Warning"`
+}
+
+// NearestFacilitiesRequest User's request for facilities which are the nearest by road
+// swagger:model
+type NearestFacilitiesRequest struct {
+	// [Longitude, Latitude] (or [X, Y] for projected SRID)
+	LonLat [2]float64 `json:"lon_lat" example:"37.601249363208915,55.745374309126895"`
+	// Max number of facilities (in range [1, 100], default is 3)
+	K *int `json:"k" example:"3"`
+	// Max travel cost to facility. Use -1 or omit for no limit
+	MaxCost *float64 `json:"max_cost" example:"2100.0"`
+	// Max radius of search for nearest edge.
+	// Use -1 for no limit, 0 for default (100m), or positive value.
+	MaxNearestRadius *float64 `json:"nearest_radius" example:"100.0"`
+	// Coordinate reference system of the point and returned geometries
+	SRIDRequest
+	// Per-request routing options
+	QueryOptionsRequest
+}
+
+// FacilityResponse Facility found by network search
+// swagger:model
+type FacilityResponse struct {
+	// Identifier of the facility
+	ID int64 `json:"id" example:"1"`
+	// Human readable name
+	Name string `json:"name" example:"Charger #1"`
+	// Location of the facility as GeoJSON Point feature
+	Point *geojson.Feature `json:"point" swaggertype:"object"`
+	// Projection of the facility onto its edge as GeoJSON Point feature
+	ProjectedPoint *geojson.Feature `json:"projected_point" swaggertype:"object"`
+	// Edge which the facility is snapped to
+	EdgeID int64 `json:"edge_id" example:"3149"`
+	// Travel cost for the request's weight profile
+	Cost float64 `json:"cost" example:"1240.5"`
+	// Length of the route (meters)
+	Length float64 `json:"length" example:"1240.5"`
+	// Route to the facility as GeoJSON LineString feature
+	Route *geojson.Feature `json:"route" swaggertype:"object"`
+	// Identifiers of edges along the route
+	EdgeIDs []int64 `json:"edge_ids" example:"3149,4278"`
+}
+
+// NearestFacilitiesResponse Server's response for nearest facilities request
+// swagger:model
+type NearestFacilitiesResponse struct {
+	// Facilities sorted by travel cost
+	Data []FacilityResponse `json:"data"`
+	// Name of weight profile used for the request. Costs are evaluated for this profile
+	Profile string `json:"profile" example:"default"`
+	// Warnings
+	Warnings []string `json:"warnings" example:"Warning"`
+}
+
+// SetFacilities Upload (replace) set of facilities via POST-request
+// @Summary Upload set of facilities (points of interest) via POST-request. Existing set is replaced
+// @Tags Facilities
+// @Produce json
+// @Param POST-body body rest.SetFacilitiesRequest true "Example of request"
+// @Success 200 {object} rest.SetFacilitiesResponse
+// @Failure 424 {object} codes.Error424
+// @Failure 500 {object} codes.Error500
+// @Router /api/v0.1.0/facilities [POST]
+func SetFacilities(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
+	fn := func(ctx *fiber.Ctx) error {
+		bodyBytes := ctx.Context().PostBody()
+		data := SetFacilitiesRequest{}
+		err := json.Unmarshal(bodyBytes, &data)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		srid, err := data.srid()
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		facilities := make([]horizon.Facility, len(data.Facilities))
+		for i := range data.Facilities {
+			facilities[i] = horizon.Facility{
+				ID:    data.Facilities[i].ID,
+				Name:  data.Facilities[i].Name,
+				Point: horizon.NewGPSMeasurementFromID(i, data.Facilities[i].LonLat[0], data.Facilities[i].LonLat[1], srid).Point,
+			}
+		}
+		radius := horizon.ResolveRadius(data.Radius, horizon.DEFAULT_SP_RADIUS)
+		skipped, err := matcher.SetFacilities(facilities, radius)
+		if err != nil {
+			log.Println(err)
+			return ctx.Status(500).JSON(fiber.Map{"Error": "Something went wrong on server side"})
+		}
+		ans := SetFacilitiesResponse{
+			Loaded:  len(facilities) - len(skipped),
+			Skipped: skipped,
+		}
+		for _, id := range skipped {
+			ans.Warnings = append(ans.Warnings, fmt.Sprintf("no edge found for facility with id '%d'", id))
+		}
+		return ctx.Status(200).JSON(ans)
+	}
+	return fn
+}
+
+// NearestFacilities Find facilities which are the nearest by road via POST-request
+// @Summary Find facilities which are the nearest by road (closest facility problem) via POST-request
+// @Tags Facilities
+// @Produce json
+// @Param POST-body body rest.NearestFacilitiesRequest true "Example of request"
+// @Success 200 {object} rest.NearestFacilitiesResponse
+// @Failure 424 {object} codes.Error424
+// @Failure 500 {object} codes.Error500
+// @Router /api/v0.1.0/facilities/nearest [POST]
+func NearestFacilities(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
+	fn := func(ctx *fiber.Ctx) error {
+		matcher := matcher.Snapshot()
+		bodyBytes := ctx.Context().PostBody()
+		data := NearestFacilitiesRequest{}
+		err := json.Unmarshal(bodyBytes, &data)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		ans := NearestFacilitiesResponse{
+			Data:    []FacilityResponse{},
+			Profile: data.profileName(),
+		}
+		srid, err := data.srid()
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		k := 3
+		if data.K != nil && *data.K > 0 && *data.K <= 100 {
+			k = *data.K
+		} else if data.K != nil {
+			ans.Warnings = append(ans.Warnings, "k not in range [1,100]. Using default value: 3")
+		}
+		maxCost := -1.0
+		if data.MaxCost != nil {
+			maxCost = *data.MaxCost
+		}
+		maxNearestRadius := horizon.ResolveRadius(data.MaxNearestRadius, horizon.DEFAULT_SP_RADIUS)
+		queryOptions, err := data.toQueryOptions(matcher, srid)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		point := horizon.NewGPSMeasurementFromID(0, data.LonLat[0], data.LonLat[1], srid)
+		result, err := matcher.NearestFacilities(point, k, maxCost, maxNearestRadius, queryOptions...)
+		if err != nil {
+			log.Println(err)
+			return ctx.Status(500).JSON(fiber.Map{"Error": "Something went wrong on server side"})
+		}
+		if len(matcher.Facilities()) == 0 {
+			ans.Warnings = append(ans.Warnings, "there are no facilities. Upload them via /facilities")
+		}
+		for _, found := range result {
+			ans.Data = append(ans.Data, FacilityResponse{
+				ID:             found.Facility.ID,
+				Name:           found.Facility.Name,
+				Point:          pointFeatureSRID(&found.Facility.Point, srid),
+				ProjectedPoint: pointFeatureSRID(&found.Facility.ProjectedPoint, srid),
+				EdgeID:         found.Facility.Edge.ID,
+				Cost:           found.Cost,
+				Length:         found.Length,
+				Route:          polylineFeatureSRID(found.Route, srid),
+				EdgeIDs:        found.EdgeIDs,
+			})
+		}
+		return ctx.Status(200).JSON(ans)
+	}
+	return fn
+}
@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/LdDl/horizon"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GraphInfoResponse Server's response with information about active road graph
+// swagger:model
+type GraphInfoResponse struct {
+	// Version of graph: initial graph has version 1, every reload increments it
+	Version int64 `json:"version" example:"2"`
+	// Where graph has been loaded from
+	Source string `json:"source" example:"graph.csv"`
+	// Time when graph has become active (RFC3339)
+	LoadedAt time.Time `json:"loaded_at" example:"2024-01-01T12:00:00Z"`
+	// Time spent for loading of graph (seconds)
+	LoadDuration float64 `json:"load_duration" example:"12.5"`
+	// Number of edges
+	Edges int `json:"edges" example:"1200"`
+	// Number of vertices
+	Vertices int `json:"vertices" example:"800"`
+	// Available weight profiles
+	Profiles []string `json:"profiles" example:"default"`
+	// Whether new graph is being loaded
+	Reloading bool `json:"reloading" example:"false"`
+}
+
+// GraphInfo Returns information about active road graph via GET-request
+// @Summary Returns version, source and load time of active road graph
+// @Tags Graph
+// @Produce json
+// @Success 200 {object} rest.GraphInfoResponse
+// @Router /api/v0.1.0/graph [GET]
+func GraphInfo(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
+	fn := func(ctx *fiber.Ctx) error {
+		info := matcher.GraphInfo()
+		return ctx.Status(200).JSON(GraphInfoResponse{
+			Version:      info.Version,
+			Source:       info.Source,
+			LoadedAt:     info.LoadedAt,
+			LoadDuration: info.LoadDuration.Seconds(),
+			Edges:        info.Edges,
+			Vertices:     info.Vertices,
+			Profiles:     info.Profiles,
+			Reloading:    matcher.IsReloading(),
+		})
+	}
+	return fn
+}
+
+// ReloadGraph Starts reload of road graph in the background via POST-request
+// @Summary Starts reload of road graph in the background. Requests are served by the active graph until the new one is loaded and validated
+// @Tags Graph
+// @Produce json
+// @Param Authorization header string true "Admin token as 'Bearer <token>'"
+// @Success 202 {object} codes.Success202
+// @Failure 401 {object} codes.Error401
+// @Failure 409 {object} codes.Error409
+// @Router /api/v0.1.0/graph/reload [POST]
+func ReloadGraph(matcher *horizon.MapMatcher, token string, reload func()) func(*fiber.Ctx) error {
+	fn := func(ctx *fiber.Ctx) error {
+		provided := strings.TrimPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			return ctx.Status(401).JSON(fiber.Map{"Error": "Unauthorized"})
+		}
+		if matcher.IsReloading() {
+			return ctx.Status(409).JSON(fiber.Map{"Error": horizon.ErrReloadInProgress.Error()})
+		}
+		go reload()
+		return ctx.Status(202).JSON(fiber.Map{"Status": "Accepted"})
+	}
+	return fn
+}
@@ -2,10 +2,10 @@ package rest
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 
 	"github.com/LdDl/horizon"
-	"github.com/LdDl/horizon/spatial"
 	"github.com/gofiber/fiber/v2"
 	geojson "github.com/paulmach/go.geojson"
 )
@@ -15,18 +15,44 @@ import (
 type IsochronesRequest struct {
 	// Max cost restrictions for single isochrone. Should be >= 0.
 	MaxCost *float64 `json:"max_cost" example:"2100.0"`
+	// Cost thresholds for multi-band isochrones. Overrides max_cost when provided: single search is done for the biggest threshold and every vertex gets "band" property. Should be >= 0.
+	MaxCosts []float64 `json:"max_costs" example:"600.0,1200.0,2100.0"`
 	// Max radius of search for nearest vertex.
 	// Use -1 for no limit, 0 for default (100m), or positive value.
 	MaxNearestRadius *float64 `json:"nearest_radius" example:"100.0"`
-	// [Longitude, Latitude]
+	// [Longitude, Latitude] (or [X, Y] for projected SRID)
 	LonLat [2]float64 `json:"lon_lat" example:"37.601249363208915,55.745374309126895"`
+	// Build polygon covering reachable area (alpha shape over reachable part of the network)
+	Polygons bool `json:"polygons" example:"true"`
+	// Max circumradius of triangles in alpha shape (in meters). Bigger value gives smoother polygon up to convex hull. Use 0 or omit for automatic value
+	Smoothness *float64 `json:"smoothness" example:"150.0"`
+	// For multi-band isochrones only: polygons of every band cover area between previous and current thresholds (rings) instead of whole area reachable within the threshold (nested polygons)
+	Rings bool `json:"rings" example:"false"`
+	// Search on reversed graph: costs are the ones of reaching the point from vertices ("who can reach this point"), e.g. catchment area of a store
+	Reverse bool `json:"reverse" example:"false"`
+	// Return reachable parts of edges: fully reachable edges and boundary edges cut at max cost. Search starts from projection of the point onto the nearest edge
+	Edges bool `json:"edges" example:"false"`
+	// Coordinate reference system of coordinates and returned geometries
+	SRIDRequest
+	// Per-request routing options
+	QueryOptionsRequest
 }
 
 // IsochronesResponse Server's response for isochrones request
 // swagger:model
 type IsochronesResponse struct {
-	// GeojSON data with properties on each feature: "cost" - travel cost to reach the vertex; "vertex_id" - corresponding vertex
+	// GeojSON data with properties on each feature: "cost" - travel cost to reach the vertex; "vertex_id" - corresponding vertex; "band" - index of band (only if 'max_costs' is provided)
 	Isochrones *geojson.FeatureCollection `json:"data" swaggerignore:"true"`
+	// GeoJSON Polygon (or MultiPolygon when reachable area consists of several parts) feature covering reachable area. Properties: "max_cost" - cost restriction; "area" - area in square meters. Only if 'polygons' is requested
+	Polygon *geojson.Feature `json:"polygon,omitempty" swaggertype:"object"`
+	// Sorted thresholds of multi-band isochrones: "band" property refers to index in this list. Only if 'max_costs' is provided
+	MaxCosts []float64 `json:"max_costs,omitempty" example:"600.0,1200.0,2100.0"`
+	// GeoJSON Polygon (or MultiPolygon) feature for every band. Properties: "band" - index of band; "min_cost" and "max_cost" - thresholds of band; "area" - area in square meters. Only if both 'polygons' and 'max_costs' are requested
+	Bands *geojson.FeatureCollection `json:"bands,omitempty" swaggerignore:"true"`
+	// GeoJSON LineString feature for reachable part of every edge. Properties: "edge_id"; "from_fraction" and "to_fraction" - reachable part of edge; "from_cost" and "to_cost" - travel costs at the ends of the part; "length" - length of the part in meters; "partial" - whether edge is reachable partially. Only if 'edges' is requested (the biggest of 'max_costs' is used for multi-band isochrones)
+	Edges *geojson.FeatureCollection `json:"edges,omitempty" swaggerignore:"true"`
+	// Name of weight profile used for the request. Costs are evaluated for this profile
+	Profile string `json:"profile" example:"default"`
 	// Warnings
 	Warnings []string `json:"warnings" example:"Warning"`
 }
@@ -42,22 +68,47 @@ type IsochronesResponse struct {
 // @Router /api/v0.1.0/isochrones [POST]
 func FindIsochrones(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
 	fn := func(ctx *fiber.Ctx) error {
+		matcher := matcher.Snapshot()
 		bodyBytes := ctx.Context().PostBody()
 		data := IsochronesRequest{}
 		err := json.Unmarshal(bodyBytes, &data)
 		if err != nil {
 			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
 		}
-		gpsMeasurement := horizon.NewGPSMeasurementFromID(0, data.LonLat[0], data.LonLat[1], 4326)
+		srid, err := data.srid()
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		gpsMeasurement := horizon.NewGPSMeasurementFromID(0, data.LonLat[0], data.LonLat[1], srid)
 		maxCost := 0.0
-		ans := IsochronesResponse{}
+		ans := IsochronesResponse{
+			Profile: data.profileName(),
+		}
 		if data.MaxCost != nil && *data.MaxCost >= 0 {
 			maxCost = *data.MaxCost
 		} else if data.MaxCost != nil {
 			ans.Warnings = append(ans.Warnings, "max_cost should be >= 0. Using default value: 0.0")
 		}
 		maxNearestRadius := horizon.ResolveRadius(data.MaxNearestRadius, horizon.DEFAULT_SP_RADIUS)
-		result, err := matcher.FindIsochrones(gpsMeasurement, maxCost, maxNearestRadius)
+		queryOptions, err := data.toQueryOptions(matcher, srid)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		if data.Reverse {
+			queryOptions = append(queryOptions, horizon.WithReverse())
+		}
+		multiBand := len(data.MaxCosts) > 0
+		maxCosts := []float64{maxCost}
+		if multiBand {
+			var warnings []string
+			maxCosts, warnings = prepareMaxCosts(data.MaxCosts)
+			ans.Warnings = append(ans.Warnings, warnings...)
+			if len(maxCosts) == 0 {
+				return ctx.Status(400).JSON(fiber.Map{"Error": "max_costs should contain at least one value >= 0"})
+			}
+			ans.MaxCosts = maxCosts
+		}
+		result, err := matcher.FindIsochroneBands(gpsMeasurement, maxCosts, maxNearestRadius, queryOptions...)
 		if err != nil {
 			log.Println(err)
 			return ctx.Status(500).JSON(fiber.Map{"Error": "Something went wrong on server side"})
@@ -69,13 +120,106 @@ func FindIsochrones(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
 				log.Println("Empty vertex")
 				ctx.Status(500).JSON("Empty vertex")
 			}
-			f := spatial.S2PointToGeoJSONFeature(isochrone.Vertex.Point)
+			f := pointFeatureSRID(isochrone.Vertex.Point, srid)
 			f.ID = i
 			f.Properties["cost"] = isochrone.Cost
 			f.Properties["vertex_id"] = isochrone.Vertex.ID
+			if multiBand {
+				f.Properties["band"] = isochrone.Band
+			}
 			ans.Isochrones.AddFeature(f)
 		}
+		if data.Edges {
+			edges, err := matcher.FindIsochroneEdges(gpsMeasurement, maxCosts[len(maxCosts)-1], maxNearestRadius, queryOptions...)
+			if err != nil {
+				log.Println(err)
+				return ctx.Status(500).JSON(fiber.Map{"Error": "Something went wrong on server side"})
+			}
+			ans.Edges = geojson.NewFeatureCollection()
+			for _, edge := range edges {
+				f := polylineFeatureSRID(edge.Geom, srid)
+				f.SetProperty("edge_id", edge.Edge.ID)
+				f.SetProperty("from_fraction", edge.FromFraction)
+				f.SetProperty("to_fraction", edge.ToFraction)
+				f.SetProperty("from_cost", edge.FromCost)
+				f.SetProperty("to_cost", edge.ToCost)
+				f.SetProperty("length", edge.Length)
+				f.SetProperty("partial", edge.Partial)
+				ans.Edges.AddFeature(f)
+			}
+		}
+		if data.Polygons {
+			params := horizon.IsochronePolygonsOptions{
+				Rings: data.Rings,
+			}
+			if data.Smoothness != nil && *data.Smoothness > 0 {
+				params.Smoothness = *data.Smoothness
+			}
+			bands, err := matcher.FindIsochroneBandPolygons(gpsMeasurement, maxCosts, maxNearestRadius, params, queryOptions...)
+			if err != nil {
+				log.Println(err)
+				return ctx.Status(500).JSON(fiber.Map{"Error": "Something went wrong on server side"})
+			}
+			if multiBand {
+				ans.Bands = geojson.NewFeatureCollection()
+				for _, band := range bands {
+					if len(band.Polygons) == 0 {
+						ans.Warnings = append(ans.Warnings, fmt.Sprintf("area of band #%d is too small (or degenerate) to build polygon", band.Band))
+						continue
+					}
+					feature := isochronePolygonsToFeature(band.Polygons, srid)
+					feature.SetProperty("band", band.Band)
+					feature.SetProperty("min_cost", band.MinCost)
+					feature.SetProperty("max_cost", band.MaxCost)
+					ans.Bands.AddFeature(feature)
+				}
+			} else if len(bands[0].Polygons) == 0 {
+				ans.Warnings = append(ans.Warnings, "reachable area is too small (or degenerate) to build polygon")
+			} else {
+				ans.Polygon = isochronePolygonsToFeature(bands[0].Polygons, srid)
+				ans.Polygon.SetProperty("max_cost", maxCost)
+			}
+		}
 		return ctx.Status(200).JSON(ans)
 	}
 	return fn
 }
+
+// prepareMaxCosts Returns sorted thresholds of multi-band isochrones without duplicates and negative values
+func prepareMaxCosts(maxCosts []float64) ([]float64, []string) {
+	warnings := []string{}
+	ans := make([]float64, 0, len(maxCosts))
+	for _, maxCost := range maxCosts {
+		if maxCost < 0 {
+			warnings = append(warnings, fmt.Sprintf("max_costs should be >= 0. Value %f is ignored", maxCost))
+			continue
+		}
+		ans = append(ans, maxCost)
+	}
+	thresholds, err := horizon.IsochroneThresholds(ans)
+	if err != nil {
+		return []float64{}, warnings
+	}
+	return thresholds, warnings
+}
+
+// isochronePolygonsToFeature Returns GeoJSON Polygon feature (coordinates in the SRID) for single polygon and MultiPolygon feature for several ones. Total area is stored in "area" property
+func isochronePolygonsToFeature(polygons []horizon.IsochronePolygon, srid int) *geojson.Feature {
+	area := 0.0
+	coordinates := make([][][][]float64, len(polygons))
+	for i := range polygons {
+		area += polygons[i].Area
+		coordinates[i] = make([][][]float64, len(polygons[i].Rings))
+		for j, ring := range polygons[i].Rings {
+			coordinates[i][j] = ringCoordinatesSRID(ring, srid)
+		}
+	}
+	var feature *geojson.Feature
+	if len(coordinates) == 1 {
+		feature = geojson.NewPolygonFeature(coordinates[0])
+	} else {
+		feature = geojson.NewMultiPolygonFeature(coordinates...)
+	}
+	feature.SetProperty("area", area)
+	return feature
+}
@@ -25,6 +25,12 @@ type MapMatchRequest struct {
 	StateRadius *float64 `json:"state_radius" example:"50.0"`
 	// Set of GPS data
 	Data []GPSToMapMatch `json:"gps"`
+	// Coordinate reference system of GPS data and returned geometries
+	SRIDRequest
+	// Per-request routing options
+	QueryOptionsRequest
+	// Merged route geometry output options
+	RouteGeometryRequest
 }
 
 // GPSToMapMatch Representation of GPS data
@@ -32,7 +38,7 @@ type MapMatchRequest struct {
 type GPSToMapMatch struct {
 	// Timestamp. Field would be ignored for request on '/shortest' service.
 	Timestamp string `json:"tm" example:"2020-03-11T00:00:00"`
-	// [Longitude, Latitude]
+	// [Longitude, Latitude] (or [X, Y] for projected SRID)
 	LonLat [2]float64 `json:"lon_lat" example:"37.601249363208915,55.745374309126895"`
 	// GPS measurement accuracy in meters (optional, <=0 or null means use default sigma)
 	Accuracy *float64 `json:"accuracy" example:"5.0"`
@@ -45,6 +51,8 @@ type SubMatchResponse struct {
 	Observations []ObservationEdgeResponse `json:"observations"`
 	// Probability from Viterbi algorithm for this segment
 	Probability float64 `json:"probability" example:"-86.578520"`
+	// Merged geometry of the segment (only if 'route_format' is provided)
+	Route *RouteGeometryResponse `json:"route,omitempty"`
 }
 
 // MapMatchResponse Server's response for map matching request
@@ -52,6 +60,8 @@ type SubMatchResponse struct {
 type MapMatchResponse struct {
 	// Array of sub-matches (segments split when route cannot be computed between consecutive points)
 	SubMatches []SubMatchResponse `json:"sub_matches"`
+	// Name of weight profile used for the request
+	Profile string `json:"profile" example:"default"`
 	// Warnings
 	Warnings []string `json:"warnings" example:"Warning"`
 }
@@ -59,10 +69,12 @@ type MapMatchResponse struct {
 // IntermediateEdgeResponse Edge which is not matched to any observation but helps to form whole travel path
 // swagger:model
 type IntermediateEdgeResponse struct {
-	// Edge geometry as GeoJSON LineString feature
+	// Edge geometry as GeoJSON LineString feature. Attributes of edge (road class, speed limit and etc.) are its properties
 	Geom *geojson.Feature `json:"geom" swaggertype:"object"`
-	// Travel cost
+	// Travel cost for the request's weight profile
 	Weight float64 `json:"weight"`
+	// Edge length (meters for WGS84 graphs)
+	Length float64 `json:"length"`
 	// Edge identifier
 	ID int64 `json:"id" example:"4278"`
 }
@@ -79,8 +91,12 @@ type ObservationEdgeResponse struct {
 	EdgeID int64 `json:"edge_id" example:"3149"`
 	// Matched vertex identifier (0 if is_matched=false)
 	VertexID int64 `json:"vertex_id" example:"44014"`
-	// Corresponding matched edge as GeoJSON LineString feature (null if is_matched=false)
+	// Corresponding matched edge as GeoJSON LineString feature (null if is_matched=false). Attributes of edge are its properties
 	MatchedEdge *geojson.Feature `json:"matched_edge" swaggertype:"object"`
+	// Travel cost of the whole matched edge for the request's weight profile (0 if is_matched=false)
+	Weight float64 `json:"weight" example:"12.5"`
+	// Length of the whole matched edge (0 if is_matched=false)
+	Length float64 `json:"length" example:"12.5"`
 	// Cut for excess part of the matched edge. Will be null for every observation except the first and the last. Could be null for first/last edge when projection point corresponds to source/target vertices of the edge
 	MatchedEdgeCut *geojson.Feature `json:"matched_edge_cut" swaggertype:"object"`
 	// Corresponding matched vertex as GeoJSON Point feature (null if is_matched=false)
@@ -104,6 +120,7 @@ type ObservationEdgeResponse struct {
 // @Router /api/v0.1.0/mapmatch [POST]
 func MapMatch(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
 	fn := func(ctx *fiber.Ctx) error {
+		matcher := matcher.Snapshot()
 		bodyBytes := ctx.Context().PostBody()
 		data := MapMatchRequest{}
 		err := json.Unmarshal(bodyBytes, &data)
@@ -113,6 +130,10 @@ func MapMatch(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
 		if len(data.Data) < 3 {
 			return ctx.Status(400).JSON(fiber.Map{"Error": fmt.Sprintf("please provide 3 GPS points atleast. Provided: %d", len(data.Data))})
 		}
+		srid, err := data.srid()
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
 		gpsMeasurements := horizon.GPSMeasurements{}
 		for i := range data.Data {
 			tm, err := time.Parse(timestampLayout, data.Data[i].Timestamp)
@@ -122,21 +143,31 @@ func MapMatch(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
 			// Use index of measurement as ID
 			var gpsMeasurement *horizon.GPSMeasurement
 			if data.Data[i].Accuracy != nil && *data.Data[i].Accuracy > 0 {
-				gpsMeasurement = horizon.NewGPSMeasurement(i, data.Data[i].LonLat[0], data.Data[i].LonLat[1], 4326, horizon.WithGPSTime(tm), horizon.WithGPSAccuracy(*data.Data[i].Accuracy))
+				gpsMeasurement = horizon.NewGPSMeasurement(i, data.Data[i].LonLat[0], data.Data[i].LonLat[1], srid, horizon.WithGPSTime(tm), horizon.WithGPSAccuracy(*data.Data[i].Accuracy))
 			} else {
-				gpsMeasurement = horizon.NewGPSMeasurement(i, data.Data[i].LonLat[0], data.Data[i].LonLat[1], 4326, horizon.WithGPSTime(tm))
+				gpsMeasurement = horizon.NewGPSMeasurement(i, data.Data[i].LonLat[0], data.Data[i].LonLat[1], srid, horizon.WithGPSTime(tm))
 			}
 			gpsMeasurements = append(gpsMeasurements, gpsMeasurement)
 		}
 		statesRadiusMeters := horizon.ResolveRadius(data.StateRadius, horizon.DEFAULT_STATE_RADIUS)
 		maxStates := 5
-		ans := MapMatchResponse{}
+		ans := MapMatchResponse{
+			Profile: data.profileName(),
+		}
 		if data.MaxStates != nil && *data.MaxStates > 0 && *data.MaxStates <= 10 {
 			maxStates = *data.MaxStates
 		} else if data.MaxStates != nil {
 			ans.Warnings = append(ans.Warnings, "max_states not in range [1,10]. Using default value: 5")
 		}
-		result, err := matcher.Run(gpsMeasurements, statesRadiusMeters, maxStates)
+		queryOptions, err := data.toQueryOptions(matcher, srid)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		err = data.validateRouteFormat()
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		result, err := matcher.Run(gpsMeasurements, statesRadiusMeters, maxStates, queryOptions...)
 		if err != nil {
 			log.Println(err)
 			return ctx.Status(500).JSON(fiber.Map{"Error": "Something went wrong on server side"})
@@ -158,7 +189,7 @@ func MapMatch(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
 						ObservationIdx: observationResult.Observation.ID(),
 						IsMatched:      false,
 						Code:           observationResult.Code,
-						OriginalPoint:  observationResult.Observation.GeoPoint.GeoJSON(),
+						OriginalPoint:  pointFeatureSRID(&observationResult.Observation.GeoPoint.Point, srid),
 						NextEdges:      []IntermediateEdgeResponse{},
 					}
 					continue
@@ -177,25 +208,44 @@ func MapMatch(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
 					IsMatched:      true,
 					Code:           observationResult.Code,
 					EdgeID:         observationResult.MatchedEdge.ID,
-					MatchedEdge:    spatial.S2PolylineToGeoJSONFeature(matchedEdgePolyline),
-					MatchedVertex:  spatial.S2PointToGeoJSONFeature(observationResult.MatchedVertex.Point),
-					ProjectedPoint: spatial.S2PointToGeoJSONFeature(&observationResult.ProjectedPoint),
+					Weight:         observationResult.MatchedEdge.Weight,
+					Length:         observationResult.MatchedEdgeLength,
+					MatchedEdge:    setAttributeProperties(polylineFeatureSRID(matchedEdgePolyline, srid), observationResult.MatchedEdgeAttributes),
+					MatchedVertex:  pointFeatureSRID(observationResult.MatchedVertex.Point, srid),
+					ProjectedPoint: pointFeatureSRID(&observationResult.ProjectedPoint, srid),
 					NextEdges:      make([]IntermediateEdgeResponse, len(observationResult.NextEdges)),
 				}
 				if len(matchedEdgeCut) > 0 {
-					subMatchResp.Observations[i].MatchedEdgeCut = spatial.S2PolylineToGeoJSONFeature(matchedEdgeCut)
+					subMatchResp.Observations[i].MatchedEdgeCut = polylineFeatureSRID(matchedEdgeCut, srid)
 				}
 				for j := range observationResult.NextEdges {
 					subMatchResp.Observations[i].NextEdges[j] = IntermediateEdgeResponse{
-						Geom:   spatial.S2PolylineToGeoJSONFeature(observationResult.NextEdges[j].Geom),
+						Geom:   setAttributeProperties(polylineFeatureSRID(observationResult.NextEdges[j].Geom, srid), observationResult.NextEdges[j].Attributes),
 						Weight: observationResult.NextEdges[j].Weight,
+						Length: observationResult.NextEdges[j].Length,
 						ID:     observationResult.NextEdges[j].ID,
 					}
 				}
 			}
+			subMatchResp.Route, err = data.prepareRouteGeometry(matcher, subMatch, srid)
+			if err != nil {
+				log.Println(err)
+				return ctx.Status(500).JSON(fiber.Map{"Error": "Something went wrong on server side"})
+			}
 			ans.SubMatches[s] = subMatchResp
 		}
 		return ctx.Status(200).JSON(ans)
 	}
 	return fn
 }
+
+// setAttributeProperties Adds attributes of edge to properties of its GeoJSON feature. Existing properties (e.g. "weight") are not overwritten
+func setAttributeProperties(feature *geojson.Feature, attributes spatial.EdgeAttributes) *geojson.Feature {
+	for name, value := range attributes {
+		if _, ok := feature.Properties[name]; ok {
+			continue
+		}
+		feature.SetProperty(name, value)
+	}
+	return feature
+}
@@ -0,0 +1,224 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/LdDl/horizon"
+	"github.com/gofiber/fiber/v2"
+	geojson "github.com/paulmach/go.geojson"
+)
+
+// NearestRequest User's request for nearest edges
+// swagger:model
+type NearestRequest struct {
+	// [Longitude, Latitude] (or [X, Y] for projected SRID)
+	LonLat [2]float64 `json:"lon_lat" example:"37.601249363208915,55.745374309126895"`
+	// Max number of edges (in range [1, 100], default is 5)
+	N *int `json:"n" example:"5"`
+	// Max radius of search.
+	// Use -1 for no limit, 0 for default (100m), or positive value.
+	Radius *float64 `json:"radius" example:"100.0"`
+	// Coordinate reference system of the point and returned geometries
+	SRIDRequest
+	// Per-request routing options
+	QueryOptionsRequest
+}
+
+// SnapRequest User's request for snapping every point to its nearest edge (no map matching is done)
+// swagger:model
+type SnapRequest struct {
+	// Max radius of search.
+	// Use -1 for no limit, 0 for default (100m), or positive value.
+	Radius *float64 `json:"radius" example:"100.0"`
+	// Set of GPS data
+	Data []GPSToShortestPath `json:"gps"`
+	// Coordinate reference system of GPS data and returned geometries
+	SRIDRequest
+	// Per-request routing options
+	QueryOptionsRequest
+}
+
+// NearestEdgeResponse Edge found near the given point
+// swagger:model
+type NearestEdgeResponse struct {
+	// Edge identifier
+	EdgeID int64 `json:"edge_id" example:"3149"`
+	// Edge geometry as GeoJSON LineString feature. Attributes of edge are its properties
+	Geom *geojson.Feature `json:"geom" swaggertype:"object"`
+	// Travel cost of the whole edge for the request's weight profile
+	Weight float64 `json:"weight" example:"12.5"`
+	// Edge length (meters for WGS84 graphs)
+	Length float64 `json:"length" example:"12.5"`
+	// Projection of the point onto the edge as GeoJSON Point feature
+	ProjectedPoint *geojson.Feature `json:"projected_point" swaggertype:"object"`
+	// Number in [0;1], describes how far projected point from the first point of edge
+	Fraction float64 `json:"fraction" example:"0.25"`
+	// Distance along the edge from its first point to the projected point (meters for WGS84 graphs)
+	Offset float64 `json:"offset" example:"3.125"`
+	// Distance from the point to the projected point (meters for WGS84 graphs)
+	Distance float64 `json:"distance" example:"4.2"`
+	// The closest (along the edge) vertex of the edge
+	VertexID int64 `json:"vertex_id" example:"44014"`
+	// The closest vertex as GeoJSON Point feature
+	Vertex *geojson.Feature `json:"vertex" swaggertype:"object"`
+	// Weakly connected component of the vertex (-1 when unknown)
+	WeakComponent int64 `json:"weak_component" example:"0"`
+	// Strongly connected component of the vertex (-1 when unknown)
+	StrongComponent int64 `json:"strong_component" example:"0"`
+	// Whether strongly connected component of the vertex is very small (such vertex is barely routable)
+	IsSmallComponent bool `json:"is_small_component" example:"false"`
+}
+
+// NearestResponse Server's response for nearest edges request
+// swagger:model
+type NearestResponse struct {
+	// Nearest edges sorted by distance
+	Data []NearestEdgeResponse `json:"data"`
+	// Name of weight profile used for the request
+	Profile string `json:"profile" example:"default"`
+	// Warnings
+	Warnings []string `json:"warnings" example:"Warning"`
+}
+
+// SnapResponse Server's response for snapping request
+// swagger:model
+type SnapResponse struct {
+	// Nearest edge for each point. Index corresponds to index in incoming request. Null if there is no edge within the radius
+	Data []*NearestEdgeResponse `json:"data"`
+	// Name of weight profile used for the request
+	Profile string `json:"profile" example:"default"`
+	// Warnings
+	Warnings []string `json:"warnings" example:"Warning"`
+}
+
+// Nearest Find nearest edges for the point via POST-request
+// @Summary Find nearest edges for the point via POST-request
+// @Tags Map matching
+// @Produce json
+// @Param POST-body body rest.NearestRequest true "Example of request"
+// @Success 200 {object} rest.NearestResponse
+// @Failure 424 {object} codes.Error424
+// @Failure 500 {object} codes.Error500
+// @Router /api/v0.1.0/nearest [POST]
+func Nearest(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
+	fn := func(ctx *fiber.Ctx) error {
+		matcher := matcher.Snapshot()
+		bodyBytes := ctx.Context().PostBody()
+		data := NearestRequest{}
+		err := json.Unmarshal(bodyBytes, &data)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		ans := NearestResponse{
+			Data:    []NearestEdgeResponse{},
+			Profile: data.profileName(),
+		}
+		srid, err := data.srid()
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		n := 5
+		if data.N != nil && *data.N > 0 && *data.N <= 100 {
+			n = *data.N
+		} else if data.N != nil {
+			ans.Warnings = append(ans.Warnings, "n not in range [1,100]. Using default value: 5")
+		}
+		radius := horizon.ResolveRadius(data.Radius, horizon.DEFAULT_SP_RADIUS)
+		queryOptions, err := data.toQueryOptions(matcher, srid)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		point := horizon.NewGPSMeasurementFromID(0, data.LonLat[0], data.LonLat[1], srid)
+		result, err := matcher.Nearest(point, n, radius, queryOptions...)
+		if err != nil {
+			log.Println(err)
+			return ctx.Status(500).JSON(fiber.Map{"Error": "Something went wrong on server side"})
+		}
+		for i := range result {
+			ans.Data = append(ans.Data, nearestEdgeToResponse(&result[i], srid))
+		}
+		return ctx.Status(200).JSON(ans)
+	}
+	return fn
+}
+
+// Snap Snap every point to its nearest edge via POST-request
+// @Summary Snap every point to its nearest edge independently (without map matching) via POST-request
+// @Tags Map matching
+// @Produce json
+// @Param POST-body body rest.SnapRequest true "Example of request"
+// @Success 200 {object} rest.SnapResponse
+// @Failure 424 {object} codes.Error424
+// @Failure 500 {object} codes.Error500
+// @Router /api/v0.1.0/snap [POST]
+func Snap(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
+	fn := func(ctx *fiber.Ctx) error {
+		matcher := matcher.Snapshot()
+		bodyBytes := ctx.Context().PostBody()
+		data := SnapRequest{}
+		err := json.Unmarshal(bodyBytes, &data)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		if len(data.Data) < 1 {
+			return ctx.Status(400).JSON(fiber.Map{"Error": fmt.Sprintf("please provide 1 GPS point atleast. Provided: %d", len(data.Data))})
+		}
+		srid, err := data.srid()
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		points := horizon.GPSMeasurements{}
+		for i := range data.Data {
+			// Use index of measurement as ID
+			points = append(points, horizon.NewGPSMeasurementFromID(i, data.Data[i].LonLat[0], data.Data[i].LonLat[1], srid))
+		}
+		radius := horizon.ResolveRadius(data.Radius, horizon.DEFAULT_SP_RADIUS)
+		queryOptions, err := data.toQueryOptions(matcher, srid)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		result, err := matcher.Snap(points, radius, queryOptions...)
+		if err != nil {
+			log.Println(err)
+			return ctx.Status(500).JSON(fiber.Map{"Error": "Something went wrong on server side"})
+		}
+		ans := SnapResponse{
+			Data:    make([]*NearestEdgeResponse, len(result)),
+			Profile: data.profileName(),
+		}
+		for i := range result {
+			if result[i] == nil {
+				ans.Warnings = append(ans.Warnings, fmt.Sprintf("no edge found for point #%d", i))
+				continue
+			}
+			edge := nearestEdgeToResponse(result[i], srid)
+			ans.Data[i] = &edge
+		}
+		return ctx.Status(200).JSON(ans)
+	}
+	return fn
+}
+
+// nearestEdgeToResponse Converts horizon.NearestEdge to its REST representation with geometries in the SRID
+func nearestEdgeToResponse(nearest *horizon.NearestEdge, srid int) NearestEdgeResponse {
+	ans := NearestEdgeResponse{
+		EdgeID:           nearest.Edge.ID,
+		Geom:             setAttributeProperties(polylineFeatureSRID(*nearest.Edge.Polyline, srid), nearest.Attributes),
+		Weight:           nearest.Weight,
+		Length:           nearest.Length,
+		ProjectedPoint:   pointFeatureSRID(&nearest.ProjectedPoint, srid),
+		Fraction:         nearest.Fraction,
+		Offset:           nearest.Offset,
+		Distance:         nearest.Distance,
+		VertexID:         nearest.Vertex.ID,
+		WeakComponent:    nearest.WeakComponent,
+		StrongComponent:  nearest.StrongComponent,
+		IsSmallComponent: nearest.IsSmallComponent,
+	}
+	if nearest.Vertex.Point != nil {
+		ans.Vertex = pointFeatureSRID(nearest.Vertex.Point, srid)
+	}
+	return ans
+}
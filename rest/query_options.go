@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/LdDl/horizon"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+)
+
+// QueryOptionsRequest Per-request routing options which are shared by map matching, shortest path and isochrones requests
+// swagger:model
+type QueryOptionsRequest struct {
+	// Identifiers of edges which must be neither candidates nor traversed (e.g. road closures)
+	ExcludedEdges []int64 `json:"excluded_edges" example:"3149,4278"`
+	// Areas to avoid as GeoJSON Polygon coordinates (first ring is outer one, others are holes). Every edge having common points with any of polygons is excluded
+	AvoidPolygons [][][][]float64 `json:"avoid_polygons" swaggertype:"array,object"`
+	// Name of weight profile used for routing, transitions and isochrones. Empty or omitted stands for 'default' profile (corresponds to 'weight' column of edges file)
+	Profile string `json:"profile" example:"travel_time"`
+}
+
+// toQueryOptions Converts request's fields to horizon.QueryOption set. Coordinates of polygons are given in the SRID
+func (req *QueryOptionsRequest) toQueryOptions(matcher *horizon.MapMatcher, srid int) ([]horizon.QueryOption, error) {
+	opts := []horizon.QueryOption{}
+	if req.Profile != "" {
+		if !matcher.HasProfile(req.Profile) {
+			return nil, fmt.Errorf("unknown profile '%s'. Available profiles: %v", req.Profile, matcher.Profiles())
+		}
+		opts = append(opts, horizon.WithProfile(req.Profile))
+	}
+	if len(req.ExcludedEdges) > 0 {
+		opts = append(opts, horizon.WithExcludedEdges(req.ExcludedEdges...))
+	}
+	if len(req.AvoidPolygons) > 0 {
+		polygons := make([]*s2.Polygon, 0, len(req.AvoidPolygons))
+		for i := range req.AvoidPolygons {
+			polygon, err := spatial.RingsToS2Polygon(req.AvoidPolygons[i], srid)
+			if err != nil {
+				return nil, fmt.Errorf("invalid avoid_polygons[%d]: %s", i, err.Error())
+			}
+			polygons = append(polygons, polygon)
+		}
+		opts = append(opts, horizon.WithAvoidPolygons(polygons...))
+	}
+	return opts, nil
+}
+
+// profileName Returns name of the profile used for the request
+func (req *QueryOptionsRequest) profileName() string {
+	if req.Profile == "" {
+		return horizon.DEFAULT_PROFILE
+	}
+	return req.Profile
+}
@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/LdDl/horizon"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/geo/s2"
+	geojson "github.com/paulmach/go.geojson"
+)
+
+// EdgesInRegionRequest User's request for edges inside of bounding box or polygon
+// swagger:model
+type EdgesInRegionRequest struct {
+	// Bounding box as [min longitude, min latitude, max longitude, max latitude] (or [min X, min Y, max X, max Y] for projected SRID). Either 'bbox' or 'polygon' must be provided
+	BBox []float64 `json:"bbox" example:"37.600,55.740,37.610,55.750"`
+	// Region as GeoJSON Polygon coordinates (first ring is outer one, others are holes). Used only when 'bbox' is omitted
+	Polygon [][][]float64 `json:"polygon" swaggertype:"array,object"`
+	// Coordinate reference system of region and returned geometries
+	SRIDRequest
+	// Per-request routing options
+	QueryOptionsRequest
+}
+
+// EdgesInRegionResponse Server's response for edges inside of region
+// swagger:model
+type EdgesInRegionResponse struct {
+	// GeoJSON LineString feature for every edge having common points with region. Properties: "edge_id"; "source" and "target" - vertices of edge; "weight" - travel cost for the request's weight profile; "length" - length in meters; attributes of edge (road class, speed limit and etc.)
+	Data *geojson.FeatureCollection `json:"data" swaggerignore:"true"`
+	// Name of weight profile used for the request
+	Profile string `json:"profile" example:"default"`
+	// Warnings
+	Warnings []string `json:"warnings" example:"Warning"`
+}
+
+// EdgesInRegion Find edges inside of bounding box or polygon via POST-request
+// @Summary Find edges having common points with bounding box or polygon via POST-request
+// @Tags Map matching
+// @Produce json
+// @Param POST-body body rest.EdgesInRegionRequest true "Example of request"
+// @Success 200 {object} rest.EdgesInRegionResponse
+// @Failure 424 {object} codes.Error424
+// @Failure 500 {object} codes.Error500
+// @Router /api/v0.1.0/edges [POST]
+func EdgesInRegion(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
+	fn := func(ctx *fiber.Ctx) error {
+		matcher := matcher.Snapshot()
+		bodyBytes := ctx.Context().PostBody()
+		data := EdgesInRegionRequest{}
+		err := json.Unmarshal(bodyBytes, &data)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		srid, err := data.srid()
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		queryOptions, err := data.toQueryOptions(matcher, srid)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		ans := EdgesInRegionResponse{
+			Data:    geojson.NewFeatureCollection(),
+			Profile: data.profileName(),
+		}
+		var result []horizon.RegionEdge
+		switch {
+		case len(data.BBox) > 0:
+			if len(data.BBox) != 4 {
+				return ctx.Status(400).JSON(fiber.Map{"Error": fmt.Sprintf("bbox should contain 4 numbers, got %d", len(data.BBox))})
+			}
+			if len(data.Polygon) > 0 {
+				ans.Warnings = append(ans.Warnings, "both bbox and polygon are provided. Using bbox")
+			}
+			lo := horizon.NewGPSMeasurementFromID(0, data.BBox[0], data.BBox[1], srid)
+			hi := horizon.NewGPSMeasurementFromID(1, data.BBox[2], data.BBox[3], srid)
+			result, err = matcher.EdgesInRect(lo, hi, queryOptions...)
+		case len(data.Polygon) > 0:
+			var polygon *s2.Polygon
+			polygon, err = spatial.RingsToS2Polygon(data.Polygon, srid)
+			if err != nil {
+				return ctx.Status(400).JSON(fiber.Map{"Error": fmt.Sprintf("invalid polygon: %s", err.Error())})
+			}
+			result, err = matcher.EdgesInPolygon(polygon, queryOptions...)
+		default:
+			return ctx.Status(400).JSON(fiber.Map{"Error": "please provide either bbox or polygon"})
+		}
+		if err != nil {
+			log.Println(err)
+			return ctx.Status(500).JSON(fiber.Map{"Error": "Something went wrong on server side"})
+		}
+		for _, edge := range result {
+			feature := polylineFeatureSRID(*edge.Edge.Polyline, srid)
+			feature.SetProperty("edge_id", edge.Edge.ID)
+			feature.SetProperty("source", edge.Edge.Source)
+			feature.SetProperty("target", edge.Edge.Target)
+			feature.SetProperty("weight", edge.Weight)
+			feature.SetProperty("length", edge.Length)
+			setAttributeProperties(feature, edge.Attributes)
+			ans.Data.AddFeature(feature)
+		}
+		return ctx.Status(200).JSON(ans)
+	}
+	return fn
+}
@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/LdDl/horizon"
+	geojson "github.com/paulmach/go.geojson"
+)
+
+// RouteGeometryRequest Options of merged route geometry output
+// swagger:model
+type RouteGeometryRequest struct {
+	// Format of merged route geometry: 'geojson', 'polyline' (Google encoded polyline, precision 5), 'polyline6' (precision 6) or 'wkt'. Empty or omitted means that merged geometry is not returned
+	RouteFormat string `json:"route_format" example:"polyline6"`
+}
+
+// validateRouteFormat Checks if requested route format is supported
+func (req *RouteGeometryRequest) validateRouteFormat() error {
+	if req.RouteFormat == "" || horizon.IsRouteFormatSupported(req.RouteFormat) {
+		return nil
+	}
+	return fmt.Errorf("unknown route_format '%s'. Supported formats: %s, %s, %s, %s", req.RouteFormat, horizon.ROUTE_FORMAT_GEOJSON, horizon.ROUTE_FORMAT_POLYLINE, horizon.ROUTE_FORMAT_POLYLINE6, horizon.ROUTE_FORMAT_WKT)
+}
+
+// RouteGeometryResponse Merged geometry of the route: single continuous line trimmed at the projections of the first and the last points
+// swagger:model
+type RouteGeometryResponse struct {
+	// Format of geometry
+	Format string `json:"format" example:"polyline6"`
+	// Geometry as GeoJSON LineString feature (only for 'geojson' format)
+	GeoJSON *geojson.Feature `json:"geojson,omitempty" swaggertype:"object"`
+	// Encoded geometry (for 'polyline', 'polyline6' and 'wkt' formats)
+	Encoded string `json:"encoded,omitempty" example:"_p~iF~ps|U_ulLnnqC"`
+	// Cumulative distance from the start of the route for every point of geometry (meters for WGS84 graphs)
+	Distances []float64 `json:"distances" example:"0,120.5,250.5"`
+	// Total length of the route (meters for WGS84 graphs)
+	Length float64 `json:"length" example:"250.5"`
+}
+
+// prepareRouteGeometry Returns merged geometry of the sub-match in requested format with coordinates in the SRID. Returns nil if format is empty
+func (req *RouteGeometryRequest) prepareRouteGeometry(matcher *horizon.MapMatcher, subMatch horizon.SubMatch, srid int) (*RouteGeometryResponse, error) {
+	if req.RouteFormat == "" {
+		return nil, nil
+	}
+	line := matcher.AssembleRoute(subMatch)
+	ans := &RouteGeometryResponse{
+		Format:    req.RouteFormat,
+		Distances: line.Distances,
+		Length:    line.Length(),
+	}
+	if ans.Distances == nil {
+		ans.Distances = []float64{}
+	}
+	if req.RouteFormat == horizon.ROUTE_FORMAT_GEOJSON {
+		ans.GeoJSON = polylineFeatureSRID(line.Geom, srid)
+		return ans, nil
+	}
+	encoded, err := line.EncodeSRID(req.RouteFormat, srid)
+	if err != nil {
+		return nil, err
+	}
+	ans.Encoded = encoded
+	return ans, nil
+}
@@ -0,0 +1,146 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/LdDl/horizon"
+	"github.com/gofiber/fiber/v2"
+	geojson "github.com/paulmach/go.geojson"
+)
+
+// OptimizeRouteRequest User's request for waypoints order optimization
+// swagger:model
+type OptimizeRouteRequest struct {
+	// Max radius of search for potential candidates.
+	// Use -1 for no limit, 0 for default (100m), or positive value.
+	StateRadius *float64 `json:"state_radius" example:"100.0"`
+	// Set of waypoints (at least 2)
+	Data []GPSToShortestPath `json:"gps"`
+	// Return to the first waypoint at the end of the route
+	Closed bool `json:"closed" example:"false"`
+	// Keep the first waypoint as the first visited one
+	FixedStart bool `json:"fixed_start" example:"true"`
+	// Keep the last waypoint as the last visited one (ignored for closed routes)
+	FixedEnd bool `json:"fixed_end" example:"false"`
+	// Coordinate reference system of waypoints and returned geometries
+	SRIDRequest
+	// Per-request routing options
+	QueryOptionsRequest
+}
+
+// RouteLegResponse Part of the route between two consecutive waypoints
+// swagger:model
+type RouteLegResponse struct {
+	// Index of the source waypoint in request
+	From int `json:"from" example:"0"`
+	// Index of the target waypoint in request
+	To int `json:"to" example:"2"`
+	// Travel cost of the leg for the request's weight profile
+	Cost float64 `json:"cost" example:"250.5"`
+	// Length of the leg (meters for WGS84 graphs)
+	Length float64 `json:"length" example:"250.5"`
+	// Edges of the leg as GeoJSON LineString objects. Each feature contains edge identifier (`id`), travel cost (`weight`), edge length (`length`), attributes of edge and geometry (`coordinates`)
+	Data []*geojson.Feature `json:"data" swaggertype:"object"`
+}
+
+// OptimizeRouteResponse Server's response for waypoints order optimization request
+// swagger:model
+type OptimizeRouteResponse struct {
+	// Indices of waypoints (as in request) in visiting order. For closed route the first waypoint is not repeated at the end
+	Order []int `json:"order" example:"0,2,3,1"`
+	// Total travel cost of the route for the request's weight profile
+	Cost float64 `json:"cost" example:"1250.5"`
+	// Total length of the route (meters for WGS84 graphs)
+	Length float64 `json:"length" example:"1250.5"`
+	// Stitched geometry of the whole route as GeoJSON LineString feature
+	Route *geojson.Feature `json:"route" swaggertype:"object"`
+	// Routes between consecutive waypoints
+	Legs []RouteLegResponse `json:"legs"`
+	// Name of weight profile used for the request
+	Profile string `json:"profile" example:"default"`
+	// Warnings
+	Warnings []string `json:"warnings" example:"Warning"`
+}
+
+// OptimizeRoute Find the best visiting order for waypoints via POST-request
+// @Summary Find the best visiting order for waypoints (travelling salesman problem) via POST-request
+// @Tags Routing
+// @Produce json
+// @Param POST-body body rest.OptimizeRouteRequest true "Example of request"
+// @Success 200 {object} rest.OptimizeRouteResponse
+// @Failure 424 {object} codes.Error424
+// @Failure 500 {object} codes.Error500
+// @Router /api/v0.1.0/optimize [POST]
+func OptimizeRoute(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
+	fn := func(ctx *fiber.Ctx) error {
+		matcher := matcher.Snapshot()
+		bodyBytes := ctx.Context().PostBody()
+		data := OptimizeRouteRequest{}
+		err := json.Unmarshal(bodyBytes, &data)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		if len(data.Data) < 2 {
+			return ctx.Status(400).JSON(fiber.Map{"Error": fmt.Sprintf("please provide 2 GPS points atleast. Provided: %d", len(data.Data))})
+		}
+		srid, err := data.srid()
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		waypoints := horizon.GPSMeasurements{}
+		ut := time.Now().UTC().Unix()
+		for i := range data.Data {
+			waypoints = append(waypoints, horizon.NewGPSMeasurementFromID(int(ut), data.Data[i].LonLat[0], data.Data[i].LonLat[1], srid))
+			ut++
+		}
+		statesRadiusMeters := horizon.ResolveRadius(data.StateRadius, horizon.DEFAULT_SP_RADIUS)
+		queryOptions, err := data.toQueryOptions(matcher, srid)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		params := horizon.RouteOptimizationOptions{
+			Closed:     data.Closed,
+			FixedStart: data.FixedStart,
+			FixedEnd:   data.FixedEnd,
+		}
+		ans := OptimizeRouteResponse{
+			Profile: data.profileName(),
+		}
+		if data.Closed && data.FixedEnd {
+			ans.Warnings = append(ans.Warnings, "fixed_end is ignored for closed routes")
+		}
+		result, err := matcher.OptimizeRoute(waypoints, statesRadiusMeters, params, queryOptions...)
+		if err != nil {
+			log.Println(err)
+			return ctx.Status(500).JSON(fiber.Map{"Error": err.Error()})
+		}
+		ans.Order = result.Order
+		ans.Cost = result.Cost
+		ans.Route = polylineFeatureSRID(result.Geometry(), srid)
+		ans.Legs = make([]RouteLegResponse, len(result.Legs))
+		for i := range result.Legs {
+			leg := RouteLegResponse{
+				From: result.Order[i],
+				To:   result.Order[(i+1)%len(result.Order)],
+				Data: []*geojson.Feature{},
+			}
+			for _, edge := range horizon.PathEdges(result.Legs[i]) {
+				feature := polylineFeatureSRID(edge.Geom, srid)
+				feature.ID = edge.ID
+				feature.SetProperty("weight", edge.Weight)
+				feature.SetProperty("length", edge.Length)
+				setAttributeProperties(feature, edge.Attributes)
+				leg.Data = append(leg.Data, feature)
+				leg.Cost += edge.Weight
+				leg.Length += edge.Length
+			}
+			ans.Length += leg.Length
+			ans.Legs[i] = leg
+		}
+		return ctx.Status(200).JSON(ans)
+	}
+	return fn
+}
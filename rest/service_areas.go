@@ -0,0 +1,169 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/LdDl/horizon"
+	"github.com/gofiber/fiber/v2"
+	geojson "github.com/paulmach/go.geojson"
+)
+
+// ServiceAreasRequest User's request for service areas of several sources (network Voronoi diagram)
+// swagger:model
+type ServiceAreasRequest struct {
+	// Max cost restriction. Should be >= 0.
+	MaxCost *float64 `json:"max_cost" example:"2100.0"`
+	// Max radius of search for nearest vertex.
+	// Use -1 for no limit, 0 for default (100m), or positive value.
+	MaxNearestRadius *float64 `json:"nearest_radius" example:"100.0"`
+	// Sources (e.g. depots)
+	Sources []GPSToShortestPath `json:"sources"`
+	// Build polygons covering every service area
+	Polygons bool `json:"polygons" example:"true"`
+	// Max circumradius of triangles in alpha shape (in meters). Bigger value gives smoother polygon up to convex hull. Use 0 or omit for automatic value
+	Smoothness *float64 `json:"smoothness" example:"150.0"`
+	// Search on reversed graph: costs are the ones of reaching the sources from vertices
+	Reverse bool `json:"reverse" example:"false"`
+	// Coordinate reference system of sources and returned geometries
+	SRIDRequest
+	// Per-request routing options
+	QueryOptionsRequest
+}
+
+// ServiceAreaStatsResponse Coverage statistics of the service area
+// swagger:model
+type ServiceAreaStatsResponse struct {
+	// Number of served vertices
+	VerticesNum int `json:"vertices_num" example:"120"`
+	// Cost of reaching the farthest served vertex
+	MaxCost float64 `json:"max_cost" example:"2080.5"`
+	// Average cost of reaching served vertices
+	AvgCost float64 `json:"avg_cost" example:"1130.2"`
+	// Area of polygons in square meters (zero if polygons are not requested)
+	Area float64 `json:"area" example:"1250000.0"`
+}
+
+// ServiceAreaResponse Part of the network served by single source
+// swagger:model
+type ServiceAreaResponse struct {
+	// Index of the source in the request
+	Source int `json:"source" example:"0"`
+	// Vertex which the source has been snapped to
+	SourceVertexID int64 `json:"source_vertex_id" example:"44014"`
+	// GeojSON data with properties on each feature: "cost" - travel cost to reach the vertex; "vertex_id" - corresponding vertex
+	Isochrones *geojson.FeatureCollection `json:"data" swaggerignore:"true"`
+	// GeoJSON Polygon (or MultiPolygon) feature covering the service area. Properties: "source" - index of the source; "area" - area in square meters. Only if 'polygons' is requested
+	Polygon *geojson.Feature `json:"polygon,omitempty" swaggertype:"object"`
+	// Coverage statistics
+	Stats ServiceAreaStatsResponse `json:"stats"`
+}
+
+// ServiceAreasResponse Server's response for service areas request
+// swagger:model
+type ServiceAreasResponse struct {
+	// Service area for each source. Index corresponds to index in incoming request
+	Data []ServiceAreaResponse `json:"data"`
+	// Name of weight profile used for the request. Costs are evaluated for this profile
+	Profile string `json:"profile" example:"default"`
+	// Warnings
+	Warnings []string `json:"warnings" example:"Warning"`
+}
+
+// FindServiceAreas Find service areas of several sources via POST-request
+// @Summary Find service areas of several sources (multi-source isochrones, network Voronoi diagram) via POST-request
+// @Tags Isochrones
+// @Produce json
+// @Param POST-body body rest.ServiceAreasRequest true "Example of request"
+// @Success 200 {object} rest.ServiceAreasResponse
+// @Failure 424 {object} codes.Error424
+// @Failure 500 {object} codes.Error500
+// @Router /api/v0.1.0/service_areas [POST]
+func FindServiceAreas(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
+	fn := func(ctx *fiber.Ctx) error {
+		matcher := matcher.Snapshot()
+		bodyBytes := ctx.Context().PostBody()
+		data := ServiceAreasRequest{}
+		err := json.Unmarshal(bodyBytes, &data)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		if len(data.Sources) < 1 {
+			return ctx.Status(400).JSON(fiber.Map{"Error": fmt.Sprintf("please provide 1 source atleast. Provided: %d", len(data.Sources))})
+		}
+		srid, err := data.srid()
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		ans := ServiceAreasResponse{
+			Data:    []ServiceAreaResponse{},
+			Profile: data.profileName(),
+		}
+		maxCost := 0.0
+		if data.MaxCost != nil && *data.MaxCost >= 0 {
+			maxCost = *data.MaxCost
+		} else if data.MaxCost != nil {
+			ans.Warnings = append(ans.Warnings, "max_cost should be >= 0. Using default value: 0.0")
+		}
+		maxNearestRadius := horizon.ResolveRadius(data.MaxNearestRadius, horizon.DEFAULT_SP_RADIUS)
+		queryOptions, err := data.toQueryOptions(matcher, srid)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		if data.Reverse {
+			queryOptions = append(queryOptions, horizon.WithReverse())
+		}
+		sources := make([]*horizon.GPSMeasurement, len(data.Sources))
+		for i := range data.Sources {
+			// Use index of source as ID
+			sources[i] = horizon.NewGPSMeasurementFromID(i, data.Sources[i].LonLat[0], data.Sources[i].LonLat[1], srid)
+		}
+		var params *horizon.IsochronePolygonsOptions
+		if data.Polygons {
+			params = &horizon.IsochronePolygonsOptions{}
+			if data.Smoothness != nil && *data.Smoothness > 0 {
+				params.Smoothness = *data.Smoothness
+			}
+		}
+		areas, err := matcher.FindServiceAreas(sources, maxCost, maxNearestRadius, params, queryOptions...)
+		if err != nil {
+			log.Println(err)
+			return ctx.Status(500).JSON(fiber.Map{"Error": "Something went wrong on server side"})
+		}
+		for _, area := range areas {
+			areaResponse := ServiceAreaResponse{
+				Source:         area.Source,
+				SourceVertexID: area.SourceVertex,
+				Isochrones:     geojson.NewFeatureCollection(),
+				Stats: ServiceAreaStatsResponse{
+					VerticesNum: area.Stats.VerticesNum,
+					MaxCost:     area.Stats.MaxCost,
+					AvgCost:     area.Stats.AvgCost,
+					Area:        area.Stats.Area,
+				},
+			}
+			for i, isochrone := range area.Isochrones {
+				if isochrone.Vertex == nil || isochrone.Vertex.Point == nil {
+					continue
+				}
+				f := pointFeatureSRID(isochrone.Vertex.Point, srid)
+				f.ID = i
+				f.Properties["cost"] = isochrone.Cost
+				f.Properties["vertex_id"] = isochrone.Vertex.ID
+				areaResponse.Isochrones.AddFeature(f)
+			}
+			if data.Polygons {
+				if len(area.Polygons) == 0 {
+					ans.Warnings = append(ans.Warnings, fmt.Sprintf("service area of source #%d is too small (or degenerate) to build polygon", area.Source))
+				} else {
+					areaResponse.Polygon = isochronePolygonsToFeature(area.Polygons, srid)
+					areaResponse.Polygon.SetProperty("source", area.Source)
+				}
+			}
+			ans.Data = append(ans.Data, areaResponse)
+		}
+		return ctx.Status(200).JSON(ans)
+	}
+	return fn
+}
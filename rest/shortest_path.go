@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/LdDl/horizon"
-	"github.com/LdDl/horizon/spatial"
 	"github.com/gofiber/fiber/v2"
 	geojson "github.com/paulmach/go.geojson"
 )
@@ -19,20 +18,34 @@ type SPRequest struct {
 	StateRadius *float64 `json:"state_radius" example:"100.0"`
 	// Set of GPS data
 	Data []GPSToShortestPath `json:"gps"`
+	// Coordinate reference system of GPS data and returned geometries
+	SRIDRequest
+	// Per-request routing options
+	QueryOptionsRequest
+	// Merged route geometry output options
+	RouteGeometryRequest
 }
 
 // GPSToShortestPath Representation of GPS data
 // swagger:model
 type GPSToShortestPath struct {
-	// [Longitude, Latitude]
+	// [Longitude, Latitude] (or [X, Y] for projected SRID)
 	LonLat [2]float64 `json:"lon_lat" example:"37.601249363208915,55.745374309126895"`
 }
 
 // SPResponse Server's response for shortest path request
 // swagger:model
 type SPResponse struct {
-	// Set of matched edges for each path's edge as GeoJSON LineString objects. Each feature contains edge identifier (`id`), travel cost (`weight`) and geometry (`coordinates`)
+	// Set of matched edges for each path's edge as GeoJSON LineString objects. Each feature contains edge identifier (`id`), travel cost for the request's weight profile (`weight`), edge length (`length`), attributes of edge (road class, speed limit and etc.) and geometry (`coordinates`)
 	Data []*geojson.Feature `json:"data" swaggertype:"object"`
+	// Name of weight profile used for the request
+	Profile string `json:"profile" example:"default"`
+	// Total travel cost of the path for the request's weight profile
+	Cost float64 `json:"cost" example:"1250.5"`
+	// Total length of the path (meters for WGS84 graphs)
+	Length float64 `json:"length" example:"1250.5"`
+	// Merged geometry of the path (only if 'route_format' is provided)
+	Route *RouteGeometryResponse `json:"route,omitempty"`
 	// Warnings
 	Warnings []string `json:"warnings" example:"Warning"`
 }
@@ -52,6 +65,7 @@ type SPResponse struct {
 // @Router /api/v0.1.0/shortest [POST]
 func FindSP(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
 	fn := func(ctx *fiber.Ctx) error {
+		matcher := matcher.Snapshot()
 		bodyBytes := ctx.Context().PostBody()
 		data := SPRequest{}
 		err := json.Unmarshal(bodyBytes, &data)
@@ -61,16 +75,30 @@ func FindSP(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
 		if len(data.Data) != 2 {
 			return ctx.Status(400).JSON(fiber.Map{"Error": fmt.Sprintf("please provide 2 GPS points only. Provided: %d", len(data.Data))})
 		}
+		srid, err := data.srid()
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
 		gpsMeasurements := horizon.GPSMeasurements{}
 		ut := time.Now().UTC().Unix()
 		for i := range data.Data {
-			gpsMeasurement := horizon.NewGPSMeasurementFromID(int(ut), data.Data[i].LonLat[0], data.Data[i].LonLat[1], 4326)
+			gpsMeasurement := horizon.NewGPSMeasurementFromID(int(ut), data.Data[i].LonLat[0], data.Data[i].LonLat[1], srid)
 			gpsMeasurements = append(gpsMeasurements, gpsMeasurement)
 			ut++
 		}
 		statesRadiusMeters := horizon.ResolveRadius(data.StateRadius, horizon.DEFAULT_SP_RADIUS)
-		ans := SPResponse{}
-		result, err := matcher.FindShortestPath(gpsMeasurements[0], gpsMeasurements[1], statesRadiusMeters)
+		ans := SPResponse{
+			Profile: data.profileName(),
+		}
+		queryOptions, err := data.toQueryOptions(matcher, srid)
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		err = data.validateRouteFormat()
+		if err != nil {
+			return ctx.Status(400).JSON(fiber.Map{"Error": err.Error()})
+		}
+		result, err := matcher.FindShortestPath(gpsMeasurements[0], gpsMeasurements[1], statesRadiusMeters, queryOptions...)
 		if err != nil {
 			return ctx.Status(500).JSON(fiber.Map{"Error": err.Error()})
 		}
@@ -78,18 +106,19 @@ func FindSP(matcher *horizon.MapMatcher) func(*fiber.Ctx) error {
 		// @todo: For now, we only handle the first sub-match
 		// Do we need to handle multiple sub-matches at all? Shortest path should exists...
 		subMatch := result.SubMatches[0]
-		for i := range subMatch.Observations {
-			observationResult := subMatch.Observations[i]
-			feature := spatial.S2PolylineToGeoJSONFeature(*observationResult.MatchedEdge.Polyline)
-			feature.ID = observationResult.MatchedEdge.ID
-			feature.SetProperty("weight", observationResult.MatchedEdge.Weight)
+		for _, edge := range horizon.PathEdges(result) {
+			feature := polylineFeatureSRID(edge.Geom, srid)
+			feature.ID = edge.ID
+			feature.SetProperty("weight", edge.Weight)
+			feature.SetProperty("length", edge.Length)
+			setAttributeProperties(feature, edge.Attributes)
 			ans.Data = append(ans.Data, feature)
-			for j := range observationResult.NextEdges {
-				edgeFeature := spatial.S2PolylineToGeoJSONFeature(observationResult.NextEdges[j].Geom)
-				edgeFeature.ID = observationResult.NextEdges[j].ID
-				edgeFeature.SetProperty("weight", observationResult.NextEdges[j].Weight)
-				ans.Data = append(ans.Data, edgeFeature)
-			}
+			ans.Cost += edge.Weight
+			ans.Length += edge.Length
+		}
+		ans.Route, err = data.prepareRouteGeometry(matcher, subMatch, srid)
+		if err != nil {
+			return ctx.Status(500).JSON(fiber.Map{"Error": err.Error()})
 		}
 		return ctx.Status(200).JSON(ans)
 	}
@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LdDl/horizon"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/geo/s2"
+)
+
+// testRoadMatcher Returns matcher for two-way road of three edges along the parallel: 0 - 1 - 2 - 3
+func testRoadMatcher(t *testing.T) *horizon.MapMatcher {
+	edges := []*spatial.Edge{}
+	for i := int64(0); i < 3; i++ {
+		source := spatial.NewWGS84Point(37.60+0.01*float64(i), 55.75).Point
+		target := spatial.NewWGS84Point(37.61+0.01*float64(i), 55.75).Point
+		forward, backward := s2.Polyline{source, target}, s2.Polyline{target, source}
+		weight := forward.Length().Radians() * spatial.EarthRadius
+		edges = append(edges,
+			&spatial.Edge{ID: 2*i + 1, Source: i, Target: i + 1, Weight: weight, Polyline: &forward},
+			&spatial.Edge{ID: 2*i + 2, Source: i + 1, Target: i, Weight: weight, Polyline: &backward},
+		)
+	}
+	engine, err := horizon.NewMapEngineBuilder().AddEdges(edges...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return horizon.NewMapMatcher(horizon.WithMapEngine(engine))
+}
+
+func TestFindSPSingleEdge(t *testing.T) {
+	app := fiber.New()
+	app.Post("/shortest", FindSP(testRoadMatcher(t)))
+	body, _ := json.Marshal(map[string]interface{}{
+		"gps": []map[string]interface{}{
+			{"lon_lat": [2]float64{37.612, 55.7501}},
+			{"lon_lat": [2]float64{37.618, 55.7501}},
+		},
+	})
+	resp, err := app.Test(httptest.NewRequest("POST", "/shortest", bytes.NewReader(body)), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("Expected status 200, but got %d", resp.StatusCode)
+	}
+	ans := SPResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&ans)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ans.Data) != 1 {
+		t.Fatalf("Path should consist of single edge, but got %d edges", len(ans.Data))
+	}
+	edgeLength := ans.Data[0].Properties["length"].(float64)
+	if math.Abs(ans.Length-edgeLength) > 1e-9 || math.Abs(ans.Cost-ans.Data[0].Properties["weight"].(float64)) > 1e-9 {
+		t.Errorf("Cost and length should be counted once: got cost %f and length %f for edge of length %f", ans.Cost, ans.Length, edgeLength)
+	}
+}
+
+func TestFindSPProjectedCRS(t *testing.T) {
+	app := fiber.New()
+	app.Post("/shortest", FindSP(testRoadMatcher(t)))
+	mercator := spatial.WebMercator{}
+	sourceX, sourceY := mercator.Forward(37.602, 55.7501)
+	targetX, targetY := mercator.Forward(37.628, 55.7501)
+	body, _ := json.Marshal(map[string]interface{}{
+		"srid":         spatial.SRID_WEB_MERCATOR,
+		"route_format": horizon.ROUTE_FORMAT_GEOJSON,
+		"gps": []map[string]interface{}{
+			{"lon_lat": [2]float64{sourceX, sourceY}},
+			{"lon_lat": [2]float64{targetX, targetY}},
+		},
+	})
+	resp, err := app.Test(httptest.NewRequest("POST", "/shortest", bytes.NewReader(body)), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("Expected status 200, but got %d", resp.StatusCode)
+	}
+	ans := SPResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&ans)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ans.Data) != 1 || ans.Route == nil || ans.Route.GeoJSON == nil {
+		t.Fatalf("Path should consist of single edge and merged geometry, but got %d edges and route %+v", len(ans.Data), ans.Route)
+	}
+	// Geometries of edges and merged route are returned in Web Mercator
+	expectedX, expectedY := mercator.Forward(37.61, 55.75)
+	first := ans.Data[0].Geometry.LineString[0]
+	if math.Abs(first[0]-expectedX) > 1e-3 || math.Abs(first[1]-expectedY) > 1e-3 {
+		t.Errorf("First point of path should be (%f, %f), but got (%f, %f)", expectedX, expectedY, first[0], first[1])
+	}
+	lastX, _ := mercator.Forward(37.62, 55.75)
+	route := ans.Route.GeoJSON.Geometry.LineString
+	if math.Abs(route[0][0]-expectedX) > 1e-3 || math.Abs(route[len(route)-1][0]-lastX) > 1e-3 {
+		t.Errorf("Merged route should be from X = %f to X = %f, but got from %f to %f", expectedX, lastX, route[0][0], route[len(route)-1][0])
+	}
+
+	// Unknown SRID is rejected
+	body, _ = json.Marshal(map[string]interface{}{
+		"srid": 999999,
+		"gps": []map[string]interface{}{
+			{"lon_lat": [2]float64{sourceX, sourceY}},
+			{"lon_lat": [2]float64{targetX, targetY}},
+		},
+	})
+	resp, err = app.Test(httptest.NewRequest("POST", "/shortest", bytes.NewReader(body)), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 400 {
+		t.Errorf("Expected status 400 for unknown SRID, but got %d", resp.StatusCode)
+	}
+}
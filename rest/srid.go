@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	geojson "github.com/paulmach/go.geojson"
+)
+
+// SRIDRequest Coordinate reference system of the request
+// swagger:model
+type SRIDRequest struct {
+	// SRID of input coordinates (points, bounding boxes and polygons) and of returned geometries (4326 by default). Projected CRS are supported: 3857, UTM zones 32601-32660 and 32701-32760 and registered custom ones. Use 0 for graphs with raw Cartesian coordinates
+	SRID *int `json:"srid" example:"4326"`
+}
+
+// srid Returns SRID of the request. Returns error if SRID is not supported
+func (req *SRIDRequest) srid() (int, error) {
+	srid := 4326
+	if req.SRID != nil {
+		srid = *req.SRID
+	}
+	if !spatial.IsSRIDSupported(srid) {
+		return 0, fmt.Errorf("SRID %d is not supported", srid)
+	}
+	return srid, nil
+}
+
+// pointFeatureSRID Returns GeoJSON Point feature with coordinates in the SRID. SRID must be supported (see SRIDRequest.srid)
+func pointFeatureSRID(pt *s2.Point, srid int) *geojson.Feature {
+	x, y, _ := spatial.S2PointToSRID(*pt, srid)
+	return geojson.NewPointFeature([]float64{x, y})
+}
+
+// polylineFeatureSRID Returns GeoJSON LineString feature with coordinates in the SRID. SRID must be supported (see SRIDRequest.srid)
+func polylineFeatureSRID(pts s2.Polyline, srid int) *geojson.Feature {
+	return geojson.NewLineStringFeature(ringCoordinatesSRID(pts, srid))
+}
+
+// ringCoordinatesSRID Returns coordinates of points in the SRID. SRID must be supported (see SRIDRequest.srid)
+func ringCoordinatesSRID(pts []s2.Point, srid int) [][]float64 {
+	coordinates := make([][]float64, len(pts))
+	for i := range pts {
+		x, y, _ := spatial.S2PointToSRID(pts[i], srid)
+		coordinates[i] = []float64{x, y}
+	}
+	return coordinates
+}
@@ -49,14 +49,7 @@ func NewRoadPositionFromLonLat(stateID int, pickedGraphVertex, routingGraphVerte
 		RoutingGraphVertex: routingGraphVertex,
 	}
 	if len(srid) != 0 {
-		switch srid[0] {
-		case 0:
-			state.Projected = spatial.NewEuclideanPoint(lon, lat)
-		case 4326:
-			state.Projected = spatial.NewWGS84Point(lon, lat)
-		default:
-			state.Projected = spatial.NewWGS84Point(lon, lat)
-		}
+		state.Projected = newGeoPointSRID(lon, lat, srid[0])
 	}
 	return &state
 }
@@ -82,15 +75,33 @@ func NewRoadPositionFromS2LatLng(stateID int, pickedGraphVertex, routingGraphVer
 		switch srid[0] {
 		case 0:
 			state.Projected = spatial.NewEuclideanPoint(latLng.Lng.Degrees(), latLng.Lat.Degrees())
-		case 4326:
-			state.Projected = spatial.NewWGS84Point(latLng.Lng.Degrees(), latLng.Lat.Degrees())
 		default:
-			state.Projected = spatial.NewWGS84Point(latLng.Lng.Degrees(), latLng.Lat.Degrees())
+			// Coordinates are already on sphere, only SRID of caller is kept
+			state.Projected = spatial.NewGeoPointFromS2(s2.PointFromLatLng(*latLng), srid[0])
 		}
 	}
 	return &state
 }
 
+// NewRoadPositionFromS2Point Returns pointer to created State
+/*
+	stateID - unique identifier for state
+	pickedGraphVertex - indentifier of vertex which is closest to Observation
+	routingGraphVertex - indentifier of vertex which will be used in routing (initially it should match pickedGraphVertex in most cases)
+	e - pointer to Edge
+	pt - projected point in geometry of the engine (raw Cartesian coordinates for SRID = 0, point on sphere otherwise)
+	srid - SRID of Observation. Coordinates of the point could be obtained in this SRID via Projected.Coordinates()
+*/
+func NewRoadPositionFromS2Point(stateID int, pickedGraphVertex, routingGraphVertex int64, e *spatial.Edge, pt s2.Point, srid int) *RoadPosition {
+	return &RoadPosition{
+		RoadPositionID:     stateID,
+		GraphEdge:          e,
+		PickedGraphVertex:  pickedGraphVertex,
+		RoutingGraphVertex: routingGraphVertex,
+		Projected:          spatial.NewGeoPointFromS2(pt, srid),
+	}
+}
+
 // ID Method to fit interface State (see https://github.com/LdDl/viterbi/blob/master/viterbi.go#L9)
 func (state RoadPosition) ID() int {
 	return state.RoadPositionID
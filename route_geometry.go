@@ -0,0 +1,150 @@
+package horizon
+
+import (
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+const (
+	// GeoJSON LineString
+	ROUTE_FORMAT_GEOJSON = "geojson"
+	// Google encoded polyline with precision 5
+	ROUTE_FORMAT_POLYLINE = "polyline"
+	// Google encoded polyline with precision 6
+	ROUTE_FORMAT_POLYLINE6 = "polyline6"
+	// WKT LINESTRING
+	ROUTE_FORMAT_WKT = "wkt"
+)
+
+// RouteLine Continuous geometry of the route
+/*
+	Geom - merged geometry of the route's edges without repeated shared vertices
+	Distances - cumulative distance from the start of the route for every point of Geom (meters for WGS84 graphs)
+*/
+type RouteLine struct {
+	Geom      s2.Polyline
+	Distances []float64
+}
+
+// Length Returns total length of the route
+func (line RouteLine) Length() float64 {
+	if len(line.Distances) == 0 {
+		return 0
+	}
+	return line.Distances[len(line.Distances)-1]
+}
+
+// Encode Returns text representation of the route geometry (WGS84)
+/*
+	format - one of ROUTE_FORMAT_POLYLINE, ROUTE_FORMAT_POLYLINE6, ROUTE_FORMAT_WKT
+*/
+func (line RouteLine) Encode(format string) (string, error) {
+	return line.EncodeSRID(format, 4326)
+}
+
+// EncodeSRID Returns text representation of the route geometry with coordinates in the SRID
+/*
+	format - one of ROUTE_FORMAT_POLYLINE, ROUTE_FORMAT_POLYLINE6, ROUTE_FORMAT_WKT
+	srid - SRID of coordinates (see spatial.S2PointToSRID). Encoded polylines keep order of coordinates as for WGS84: Y first, then X
+*/
+func (line RouteLine) EncodeSRID(format string, srid int) (string, error) {
+	switch format {
+	case ROUTE_FORMAT_POLYLINE:
+		return spatial.EncodePolylineSRID(line.Geom, 5, srid)
+	case ROUTE_FORMAT_POLYLINE6:
+		return spatial.EncodePolylineSRID(line.Geom, 6, srid)
+	case ROUTE_FORMAT_WKT:
+		return spatial.S2PolylineToWKTSRID(line.Geom, srid)
+	default:
+		return "", errors.Wrapf(ErrUnknownRouteFormat, "format '%s' can't be encoded as text", format)
+	}
+}
+
+// IsRouteFormatSupported Checks if route geometry could be represented in given format
+func IsRouteFormatSupported(format string) bool {
+	switch format {
+	case ROUTE_FORMAT_GEOJSON, ROUTE_FORMAT_POLYLINE, ROUTE_FORMAT_POLYLINE6, ROUTE_FORMAT_WKT:
+		return true
+	default:
+		return false
+	}
+}
+
+// AssembleRoute Merges edges of the sub-match into single continuous line
+/*
+	subMatch - result of map matching (see Run) or path finding (see FindShortestPath)
+
+	Matched and intermediate edges are merged in travel order, consecutive duplicates of the same edge are skipped.
+	When observations have projections (map matching) the first edge is trimmed at the first projection (see spatial.ExtractCutUpTo)
+	and the last edge is trimmed at the last projection (see spatial.ExtractCutUpFrom). Unmatched observations are ignored
+*/
+func (matcher *MapMatcher) AssembleRoute(subMatch SubMatch) RouteLine {
+	engine := matcher.Engine()
+	type routeEdge struct {
+		id   int64
+		geom s2.Polyline
+	}
+	edges := []routeEdge{}
+	appendEdge := func(id int64, geom s2.Polyline) {
+		if len(edges) > 0 && edges[len(edges)-1].id == id {
+			return
+		}
+		edges = append(edges, routeEdge{id: id, geom: geom})
+	}
+	first, last := -1, -1
+	for i, observation := range subMatch.Observations {
+		// Path finding doesn't mark observations as matched, so check geometry only
+		if observation.MatchedEdge.Polyline == nil {
+			continue
+		}
+		if first == -1 {
+			first = i
+		}
+		last = i
+		appendEdge(observation.MatchedEdge.ID, *observation.MatchedEdge.Polyline)
+		for _, edge := range observation.NextEdges {
+			appendEdge(edge.ID, edge.Geom)
+		}
+	}
+	if len(edges) == 0 {
+		return RouteLine{}
+	}
+
+	// Trim the first and the last edges when projections are known (ProjectionPointIdx is always positive for projected observations)
+	firstObservation := subMatch.Observations[first]
+	lastObservation := subMatch.Observations[last]
+	if firstObservation.ProjectionPointIdx > 0 {
+		edges[0].geom, _ = spatial.ExtractCutUpTo(edges[0].geom, firstObservation.ProjectedPoint, firstObservation.ProjectionPointIdx)
+	}
+	if lastObservation.ProjectionPointIdx > 0 && edges[len(edges)-1].id == lastObservation.MatchedEdge.ID {
+		lastEdge := &edges[len(edges)-1]
+		if len(edges) == 1 && firstObservation.ProjectionPointIdx > 0 {
+			// Both projections are on the same edge: trimmed geometry starts with the first projection, so index of the last projection has to be shifted
+			projectionIdx := lastObservation.ProjectionPointIdx - firstObservation.ProjectionPointIdx + 1
+			if projectionIdx < 1 {
+				// The last projection is before the first one on the edge (moving backwards)
+				projectionIdx = 1
+			}
+			lastEdge.geom, _ = spatial.ExtractCutUpFrom(lastEdge.geom, lastObservation.ProjectedPoint, projectionIdx)
+		} else {
+			lastEdge.geom, _ = spatial.ExtractCutUpFrom(lastEdge.geom, lastObservation.ProjectedPoint, lastObservation.ProjectionPointIdx)
+		}
+	}
+
+	line := RouteLine{}
+	for _, edge := range edges {
+		for _, pt := range edge.geom {
+			if len(line.Geom) > 0 && line.Geom[len(line.Geom)-1] == pt {
+				continue
+			}
+			distance := 0.0
+			if len(line.Geom) > 0 {
+				distance = line.Distances[len(line.Distances)-1] + engine.distance(line.Geom[len(line.Geom)-1], pt)
+			}
+			line.Geom = append(line.Geom, pt)
+			line.Distances = append(line.Distances, distance)
+		}
+	}
+	return line
+}
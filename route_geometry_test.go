@@ -0,0 +1,116 @@
+package horizon
+
+import (
+	"math"
+	"testing"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+)
+
+func TestAssembleRoute(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	eps := 1e-9
+	engine := matcher.engine
+
+	// Map matching like sub-match: two observations on consecutive edges, the second observation is repeated on the same edge
+	subMatch := SubMatch{
+		Observations: []ObservationResult{
+			{
+				IsMatched:          true,
+				MatchedEdge:        *engine.edgesBetween(1, 2)[0],
+				ProjectedPoint:     spatial.NewEuclideanS2Point(2, 0),
+				ProjectionPointIdx: 1,
+			},
+			{
+				IsMatched:          true,
+				MatchedEdge:        *engine.edgesBetween(2, 3)[0],
+				ProjectedPoint:     spatial.NewEuclideanS2Point(6, 0),
+				ProjectionPointIdx: 1,
+			},
+			{
+				IsMatched:          true,
+				MatchedEdge:        *engine.edgesBetween(2, 3)[0],
+				ProjectedPoint:     spatial.NewEuclideanS2Point(7, 0),
+				ProjectionPointIdx: 1,
+			},
+		},
+	}
+	line := matcher.AssembleRoute(subMatch)
+	checkRouteLine(t, line, [][2]float64{{2, 0}, {5, 0}, {7, 0}}, []float64{0, 3, 5}, eps)
+
+	// Both projections are on the same edge
+	subMatch = SubMatch{
+		Observations: []ObservationResult{
+			{
+				IsMatched:          true,
+				MatchedEdge:        *engine.edgesBetween(1, 2)[0],
+				ProjectedPoint:     spatial.NewEuclideanS2Point(1, 0),
+				ProjectionPointIdx: 1,
+			},
+			{
+				IsMatched:          true,
+				MatchedEdge:        *engine.edgesBetween(1, 2)[0],
+				ProjectedPoint:     spatial.NewEuclideanS2Point(4, 0),
+				ProjectionPointIdx: 1,
+			},
+		},
+	}
+	line = matcher.AssembleRoute(subMatch)
+	checkRouteLine(t, line, [][2]float64{{1, 0}, {4, 0}}, []float64{0, 3}, eps)
+
+	// Path finding result has no projections: edges between snapped vertices are not trimmed
+	source := NewGPSMeasurementFromID(1, -4.9, 0.1, 0)
+	target := NewGPSMeasurementFromID(2, 14.9, 0.1, 0)
+	result, err := matcher.FindShortestPath(source, target, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line = matcher.AssembleRoute(result.SubMatches[0])
+	checkRouteLine(t, line, [][2]float64{{0, 0}, {5, 0}, {10, 0}}, []float64{0, 5, 10}, eps)
+	if math.Abs(line.Length()-10) > eps {
+		t.Errorf("Route length should be 10, got %f", line.Length())
+	}
+}
+
+func checkRouteLine(t *testing.T, line RouteLine, expectedPoints [][2]float64, expectedDistances []float64, eps float64) {
+	t.Helper()
+	if len(line.Geom) != len(expectedPoints) || len(line.Distances) != len(expectedDistances) {
+		t.Fatalf("Route should have %d points, got %d (distances: %d)", len(expectedPoints), len(line.Geom), len(line.Distances))
+	}
+	for i := range expectedPoints {
+		expected := spatial.NewEuclideanS2Point(expectedPoints[i][0], expectedPoints[i][1])
+		if math.Abs(line.Geom[i].X-expected.X) > eps || math.Abs(line.Geom[i].Y-expected.Y) > eps {
+			t.Errorf("Point #%d should be %v, got (%f, %f)", i, expectedPoints[i], line.Geom[i].X, line.Geom[i].Y)
+		}
+		if math.Abs(line.Distances[i]-expectedDistances[i]) > eps {
+			t.Errorf("Cumulative distance for point #%d should be %f, got %f", i, expectedDistances[i], line.Distances[i])
+		}
+	}
+}
+
+func TestRouteLineEncode(t *testing.T) {
+	line := RouteLine{
+		Geom: *s2.PolylineFromLatLngs([]s2.LatLng{
+			s2.LatLngFromDegrees(38.5, -120.2),
+			s2.LatLngFromDegrees(40.7, -120.95),
+		}),
+	}
+	encoded, err := line.Encode(ROUTE_FORMAT_POLYLINE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != "_p~iF~ps|U_ulLnnqC" {
+		t.Errorf("Wrong encoded polyline: %s", encoded)
+	}
+	encoded, err = line.Encode(ROUTE_FORMAT_WKT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != "LINESTRING(-120.2 38.5, -120.95 40.7)" {
+		t.Errorf("Wrong WKT: %s", encoded)
+	}
+	if _, err = line.Encode(ROUTE_FORMAT_GEOJSON); err == nil {
+		t.Errorf("GeoJSON format can't be encoded as text")
+	}
+}
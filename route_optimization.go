@@ -0,0 +1,339 @@
+package horizon
+
+import (
+	"math"
+
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// RouteOptimizationOptions Parameters of waypoints order optimization (travelling salesman problem)
+/*
+	Closed - if true then route returns to its first waypoint (closed tour), otherwise route ends at the last visited waypoint (open path)
+	FixedStart - if true then the first provided waypoint is always the first visited one
+	FixedEnd - if true then the last provided waypoint is always the last visited one. Ignored for closed routes
+*/
+type RouteOptimizationOptions struct {
+	Closed     bool
+	FixedStart bool
+	FixedEnd   bool
+}
+
+// OptimizedRoute Representation of waypoints order optimization output
+/*
+	Order - indices of provided waypoints in visiting order. For closed route the first waypoint is not repeated at the end
+	Cost - total cost of the route evaluated via cost matrix
+	Legs - routes between consecutive waypoints (see FindShortestPath). Leg i goes from Order[i] to Order[i+1] (for closed route the last leg goes back to Order[0]).
+		Leg is empty MatcherResult when both waypoints are snapped to the same vertex
+*/
+type OptimizedRoute struct {
+	Order []int
+	Cost  float64
+	Legs  []MatcherResult
+}
+
+// OptimizeRoute Finds the best visiting order for the given waypoints and builds route for it
+/*
+	waypoints - set of waypoints (at least 2)
+	statesRadiusMeters - maximum radius to search nearest edges for waypoints (use -1 for unlimited)
+	params - route kind (open/closed) and fixed start/end constraints
+	opts - per-request options (see QueryOptions)
+
+	Cost matrix is built via contraction hierarchies many-to-many query (or Dijkstra's algorithm when some edges are excluded).
+	Initial order is built by nearest neighbor heuristic and then improved by 2-opt and Or-opt moves.
+*/
+func (matcher *MapMatcher) OptimizeRoute(waypoints []*GPSMeasurement, statesRadiusMeters float64, params RouteOptimizationOptions, opts ...QueryOption) (OptimizedRoute, error) {
+	engine := matcher.Engine()
+	err := checkMeasurementsSRID(waypoints...)
+	if err != nil {
+		return OptimizedRoute{}, err
+	}
+	if len(waypoints) < 2 {
+		return OptimizedRoute{}, ErrMinimumWaypoints
+	}
+	query, err := engine.prepareQuery(opts...)
+	if err != nil {
+		return OptimizedRoute{}, errors.Wrap(err, "Can't prepare query")
+	}
+	if params.Closed {
+		params.FixedEnd = false
+	}
+
+	// Snap every waypoint to a single vertex
+	vertices := make([]int64, len(waypoints))
+	for i := range waypoints {
+		candidates, err := engine.getCandidates(query, waypoints[i].Point, statesRadiusMeters, DEFAULT_CANDIDATES_LIMIT)
+		if err != nil {
+			return OptimizedRoute{}, errors.Wrapf(err, "Can't get candidates for waypoint #%d", i)
+		}
+		if len(candidates) == 0 {
+			return OptimizedRoute{}, errors.Wrapf(ErrCandidatesNotFound, "waypoint #%d", i)
+		}
+		vertices[i] = engine.pickRoutableCandidate(candidates).vertex
+	}
+
+	costs := query.costMatrix(vertices, vertices)
+	order := solveTSP(costs, params)
+	totalCost := tourCost(costs, order, params.Closed)
+	if totalCost >= math.MaxFloat64 {
+		return OptimizedRoute{}, errors.Wrap(ErrPathNotFound, "some of waypoints are not reachable from each other")
+	}
+
+	// Stitch the route
+	legsNum := len(order) - 1
+	if params.Closed {
+		legsNum = len(order)
+	}
+	legs := make([]MatcherResult, 0, legsNum)
+	for i := 0; i < legsNum; i++ {
+		from := order[i]
+		to := order[(i+1)%len(order)]
+		leg, err := engine.shortestPath(waypoints[from], waypoints[to], statesRadiusMeters, opts...)
+		if err != nil {
+			if errors.Cause(err) == ErrSameVertex {
+				legs = append(legs, MatcherResult{})
+				continue
+			}
+			return OptimizedRoute{}, errors.Wrapf(err, "Can't find path between waypoints #%d and #%d", from, to)
+		}
+		legs = append(legs, leg)
+	}
+	return OptimizedRoute{
+		Order: order,
+		Cost:  totalCost,
+		Legs:  legs,
+	}, nil
+}
+
+// Geometry Returns stitched geometry of the whole route (edges of all legs in visiting order)
+func (route OptimizedRoute) Geometry() s2.Polyline {
+	geometry := s2.Polyline{}
+	for _, leg := range route.Legs {
+		for _, edge := range PathEdges(leg) {
+			for i, pt := range edge.Geom {
+				if i == 0 && len(geometry) > 0 && geometry[len(geometry)-1] == pt {
+					continue
+				}
+				geometry = append(geometry, pt)
+			}
+		}
+	}
+	return geometry
+}
+
+// PathEdges Returns edges of the path found by FindShortestPath in travel order
+func PathEdges(result MatcherResult) []EdgeResult {
+	if len(result.SubMatches) == 0 {
+		return nil
+	}
+	edges := []EdgeResult{}
+	for _, observation := range result.SubMatches[0].Observations {
+		// Path consisting of single edge has the same matched edge for both source and target
+		sameAsPrevious := len(edges) > 0 && edges[len(edges)-1].ID == observation.MatchedEdge.ID
+		if observation.MatchedEdge.Polyline != nil && !sameAsPrevious {
+			edges = append(edges, EdgeResult{
+				Geom:       *observation.MatchedEdge.Polyline,
+				Weight:     observation.MatchedEdge.Weight,
+				Length:     observation.MatchedEdgeLength,
+				ID:         observation.MatchedEdge.ID,
+				Attributes: observation.MatchedEdgeAttributes,
+			})
+		}
+		edges = append(edges, observation.NextEdges...)
+	}
+	return edges
+}
+
+// pickRoutableCandidate Returns the closest candidate which belongs to non-tiny SCC. If there is no such candidate then the closest one is returned
+func (engine *MapEngine) pickRoutableCandidate(candidates []candidateInfo) candidateInfo {
+	for _, candidate := range candidates {
+		if candidate.sccComponent == -1 || engine.isComponentVerySmall[candidate.sccComponent] {
+			continue
+		}
+		return candidate
+	}
+	return candidates[0]
+}
+
+// costMatrix Returns matrix of shortest paths costs between sources and targets. Unreachable pairs get math.MaxFloat64
+func (query *routingQuery) costMatrix(sources, targets []int64) [][]float64 {
+	matrix := make([][]float64, len(sources))
+	if !query.hasExclusions() {
+		ans, _ := query.profile.queryPool.ShortestPathManyToMany(sources, targets)
+		for i := range sources {
+			matrix[i] = make([]float64, len(targets))
+			for j := range targets {
+				matrix[i][j] = ans[i][j]
+				if sources[i] == targets[j] {
+					matrix[i][j] = 0
+				} else if ans[i][j] < 0 {
+					matrix[i][j] = math.MaxFloat64
+				}
+			}
+		}
+		return matrix
+	}
+	// Single Dijkstra's search for each source
+	for i := range sources {
+		settled := query.dijkstra(sources[i], -1, math.MaxFloat64, false)
+		matrix[i] = make([]float64, len(targets))
+		for j := range targets {
+			label, ok := settled[targets[j]]
+			if !ok {
+				matrix[i][j] = math.MaxFloat64
+				continue
+			}
+			matrix[i][j] = label.cost
+		}
+	}
+	return matrix
+}
+
+// solveTSP Returns visiting order for asymmetric cost matrix
+func solveTSP(costs [][]float64, params RouteOptimizationOptions) []int {
+	n := len(costs)
+	if n == 0 {
+		return []int{}
+	}
+	order := nearestNeighborOrder(costs, params)
+	// Number of positions at the start and the end of order which can't be changed
+	lockedStart := 0
+	if params.FixedStart || params.Closed {
+		// Rotation of closed tour doesn't change its cost, so the first waypoint could be locked in any case
+		lockedStart = 1
+	}
+	lockedEnd := 0
+	if params.FixedEnd {
+		lockedEnd = 1
+	}
+	best := tourCost(costs, order, params.Closed)
+	for improved := true; improved; {
+		improved = false
+		var cost float64
+		if order, cost = twoOpt(costs, order, lockedStart, lockedEnd, params.Closed, best); cost < best {
+			best = cost
+			improved = true
+		}
+		if order, cost = orOpt(costs, order, lockedStart, lockedEnd, params.Closed, best); cost < best {
+			best = cost
+			improved = true
+		}
+	}
+	return order
+}
+
+// nearestNeighborOrder Builds initial order via nearest neighbor heuristic
+func nearestNeighborOrder(costs [][]float64, params RouteOptimizationOptions) []int {
+	n := len(costs)
+	visited := make([]bool, n)
+	order := make([]int, 0, n)
+	current := 0
+	if !params.FixedStart && !params.Closed {
+		// Pick start which gives the cheapest first move
+		bestCost := math.MaxFloat64
+		for i := 0; i < n; i++ {
+			if params.FixedEnd && i == n-1 {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				if i == j || (params.FixedEnd && j == n-1 && n > 2) {
+					continue
+				}
+				if costs[i][j] < bestCost {
+					bestCost = costs[i][j]
+					current = i
+				}
+			}
+		}
+	}
+	visited[current] = true
+	order = append(order, current)
+	if params.FixedEnd && current != n-1 {
+		visited[n-1] = true
+	}
+	for len(order) < n {
+		next := -1
+		nextCost := math.MaxFloat64
+		for j := 0; j < n; j++ {
+			if visited[j] {
+				continue
+			}
+			if next == -1 || costs[current][j] < nextCost {
+				next = j
+				nextCost = costs[current][j]
+			}
+		}
+		if next == -1 {
+			// Only fixed end is left
+			next = n - 1
+		}
+		visited[next] = true
+		order = append(order, next)
+		current = next
+	}
+	return order
+}
+
+// tourCost Returns total cost of visiting waypoints in given order. Returns math.MaxFloat64 if some of legs is unreachable
+func tourCost(costs [][]float64, order []int, closed bool) float64 {
+	total := 0.0
+	legs := len(order) - 1
+	if closed {
+		legs = len(order)
+	}
+	for i := 0; i < legs; i++ {
+		cost := costs[order[i]][order[(i+1)%len(order)]]
+		if cost >= math.MaxFloat64 {
+			return math.MaxFloat64
+		}
+		total += cost
+	}
+	return total
+}
+
+// twoOpt Improves order by reversing segments (improving moves are applied immediately). Full cost is re-evaluated for each move since matrix could be asymmetric
+func twoOpt(costs [][]float64, order []int, lockedStart, lockedEnd int, closed bool, best float64) ([]int, float64) {
+	n := len(order)
+	candidate := make([]int, n)
+	for i := lockedStart; i < n-lockedEnd-1; i++ {
+		for j := i + 1; j < n-lockedEnd; j++ {
+			copy(candidate, order)
+			for l, r := i, j; l < r; l, r = l+1, r-1 {
+				candidate[l], candidate[r] = candidate[r], candidate[l]
+			}
+			if cost := tourCost(costs, candidate, closed); cost < best {
+				best = cost
+				copy(order, candidate)
+			}
+		}
+	}
+	return order, best
+}
+
+// orOpt Improves order by moving segments of 1-3 consecutive waypoints to another position (first improvement strategy)
+func orOpt(costs [][]float64, order []int, lockedStart, lockedEnd int, closed bool, best float64) ([]int, float64) {
+	n := len(order)
+	candidate := make([]int, 0, n)
+	for segmentLen := 1; segmentLen <= 3; segmentLen++ {
+		for i := lockedStart; i+segmentLen <= n-lockedEnd; i++ {
+			segment := order[i : i+segmentLen]
+			rest := make([]int, 0, n-segmentLen)
+			rest = append(rest, order[:i]...)
+			rest = append(rest, order[i+segmentLen:]...)
+			for pos := lockedStart; pos <= len(rest)-lockedEnd; pos++ {
+				if pos == i {
+					continue
+				}
+				candidate = candidate[:0]
+				candidate = append(candidate, rest[:pos]...)
+				candidate = append(candidate, segment...)
+				candidate = append(candidate, rest[pos:]...)
+				if cost := tourCost(costs, candidate, closed); cost < best {
+					copy(order, candidate)
+					return order, cost
+				}
+			}
+		}
+	}
+	return order, best
+}
@@ -0,0 +1,132 @@
+package horizon
+
+import (
+	"math"
+	"testing"
+
+	"github.com/LdDl/ch"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+)
+
+// lineCosts Returns symmetric cost matrix for points on a line
+func lineCosts(positions []float64) [][]float64 {
+	costs := make([][]float64, len(positions))
+	for i := range positions {
+		costs[i] = make([]float64, len(positions))
+		for j := range positions {
+			costs[i][j] = math.Abs(positions[i] - positions[j])
+		}
+	}
+	return costs
+}
+
+func TestSolveTSP(t *testing.T) {
+	positions := []float64{0, 5, 1, 4, 2, 3}
+	costs := lineCosts(positions)
+	cases := []struct {
+		params       RouteOptimizationOptions
+		expectedCost float64
+	}{
+		{params: RouteOptimizationOptions{}, expectedCost: 5},
+		{params: RouteOptimizationOptions{FixedStart: true}, expectedCost: 5},
+		{params: RouteOptimizationOptions{Closed: true}, expectedCost: 10},
+		{params: RouteOptimizationOptions{FixedStart: true, FixedEnd: true}, expectedCost: 7},
+		{params: RouteOptimizationOptions{FixedEnd: true}, expectedCost: 7},
+	}
+	for i, c := range cases {
+		order := solveTSP(costs, c.params)
+		if len(order) != len(positions) {
+			t.Errorf("Case #%d: order should contain %d waypoints, but got %d", i, len(positions), len(order))
+			continue
+		}
+		seen := make(map[int]bool, len(order))
+		for _, idx := range order {
+			seen[idx] = true
+		}
+		if len(seen) != len(positions) {
+			t.Errorf("Case #%d: order %v is not a permutation", i, order)
+		}
+		if c.params.FixedStart && order[0] != 0 {
+			t.Errorf("Case #%d: first waypoint should be fixed, but order is %v", i, order)
+		}
+		if c.params.FixedEnd && order[len(order)-1] != len(positions)-1 {
+			t.Errorf("Case #%d: last waypoint should be fixed, but order is %v", i, order)
+		}
+		cost := tourCost(costs, order, c.params.Closed)
+		if cost != c.expectedCost {
+			t.Errorf("Case #%d: cost should be %f, but got %f (order %v)", i, c.expectedCost, cost, order)
+		}
+	}
+}
+
+func TestOptimizeRoute(t *testing.T) {
+	// Two-way road along X axis: 0 - 1 - 2 - 3 - 4 - 5
+	graph := ch.Graph{}
+	edgesSpatial := []*spatial.Edge{}
+	verticesSpatial := []*spatial.Vertex{}
+	for i := int64(0); i < 6; i++ {
+		err := graph.CreateVertex(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s2Point := spatial.NewEuclideanS2Point(float64(i)*10, 0)
+		verticesSpatial = append(verticesSpatial, &spatial.Vertex{Point: &s2Point, ID: i})
+	}
+	edgeID := int64(1)
+	for i := int64(0); i < 5; i++ {
+		for _, pair := range [][2]int64{{i, i + 1}, {i + 1, i}} {
+			err := graph.AddEdge(pair[0], pair[1], 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s2Polyline := s2.Polyline{
+				spatial.NewEuclideanS2Point(float64(pair[0])*10, 0),
+				spatial.NewEuclideanS2Point(float64(pair[1])*10, 0),
+			}
+			edgesSpatial = append(edgesSpatial, &spatial.Edge{ID: edgeID, Source: pair[0], Target: pair[1], Weight: 10, Polyline: &s2Polyline})
+			edgeID++
+		}
+	}
+	engine := NewMapEngine(
+		WithGraph(graph),
+		WithStorage(spatial.NewStorage(spatial.StorageTypeEuclidean)),
+		WithEdges(edgesSpatial),
+		WithVertices(verticesSpatial),
+	)
+	matcher := NewMapMatcher(WithMapEngine(engine))
+
+	// Waypoints are placed right after vertices 0, 4, 1, 3 (in that order)
+	waypoints := []*GPSMeasurement{
+		NewGPSMeasurementFromID(0, 1, 0.5, 0),
+		NewGPSMeasurementFromID(1, 41, 0.5, 0),
+		NewGPSMeasurementFromID(2, 11, 0.5, 0),
+		NewGPSMeasurementFromID(3, 31, 0.5, 0),
+	}
+	result, err := matcher.OptimizeRoute(waypoints, -1, RouteOptimizationOptions{FixedStart: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedOrder := []int{0, 2, 3, 1}
+	for i := range expectedOrder {
+		if result.Order[i] != expectedOrder[i] {
+			t.Fatalf("Order should be %v, but got %v", expectedOrder, result.Order)
+		}
+	}
+	if len(result.Legs) != len(waypoints)-1 {
+		t.Errorf("Expected %d legs, but got %d", len(waypoints)-1, len(result.Legs))
+	}
+
+	result, err = matcher.OptimizeRoute(waypoints, -1, RouteOptimizationOptions{Closed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Legs) != len(waypoints) {
+		t.Errorf("Expected %d legs for closed route, but got %d", len(waypoints), len(result.Legs))
+	}
+
+	_, err = matcher.OptimizeRoute(waypoints[:1], -1, RouteOptimizationOptions{})
+	if err != ErrMinimumWaypoints {
+		t.Errorf("Expected error '%v', but got '%v'", ErrMinimumWaypoints, err)
+	}
+}
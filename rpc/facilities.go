@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LdDl/horizon"
+	"github.com/LdDl/horizon/rpc/protos_pb"
+)
+
+// SetFacilities Implement SetFacilities() to match interface
+func (ts *Microservice) SetFacilities(ctx context.Context, in *protos_pb.SetFacilitiesRequest) (*protos_pb.SetFacilitiesResponse, error) {
+	srid, err := requestSRID(in.Srid)
+	if err != nil {
+		return nil, err
+	}
+	facilities := make([]horizon.Facility, len(in.Facilities))
+	for i, facility := range in.Facilities {
+		if facility.Point == nil {
+			return nil, fmt.Errorf("facility #%d has no point", i)
+		}
+		facilities[i] = horizon.Facility{
+			ID:    facility.Id,
+			Name:  facility.Name,
+			Point: horizon.NewGPSMeasurementFromID(i, facility.Point.Lon, facility.Point.Lat, srid).Point,
+		}
+	}
+	radius := horizon.ResolveRadius(in.Radius, horizon.DEFAULT_SP_RADIUS)
+	skipped, err := ts.matcher.SetFacilities(facilities, radius)
+	if err != nil {
+		return nil, err
+	}
+	response := &protos_pb.SetFacilitiesResponse{
+		Loaded:   int32(len(facilities) - len(skipped)),
+		Skipped:  skipped,
+		Warnings: []string{},
+	}
+	for _, id := range skipped {
+		response.Warnings = append(response.Warnings, fmt.Sprintf("no edge found for facility with id '%d'", id))
+	}
+	return response, nil
+}
+
+// GetNearestFacilities Implement GetNearestFacilities() to match interface
+func (ts *Microservice) GetNearestFacilities(ctx context.Context, in *protos_pb.NearestFacilitiesRequest) (*protos_pb.NearestFacilitiesResponse, error) {
+	matcher := ts.matcher.Snapshot()
+	response := &protos_pb.NearestFacilitiesResponse{
+		Data:     []*protos_pb.FacilityRoute{},
+		Warnings: []string{},
+		Profile:  profileName(in.Profile),
+	}
+	k := 3
+	if in.K != nil && *in.K > 0 && *in.K <= 100 {
+		k = int(*in.K)
+	} else if in.K != nil {
+		response.Warnings = append(response.Warnings, "k not in range [1,100]. Using default value: 3")
+	}
+	maxCost := -1.0
+	if in.MaxCost != nil {
+		maxCost = *in.MaxCost
+	}
+	maxNearestRadius := horizon.ResolveRadius(in.MaxNearestRadius, horizon.DEFAULT_SP_RADIUS)
+	srid, err := requestSRID(in.Srid)
+	if err != nil {
+		return nil, err
+	}
+	queryOptions, err := prepareQueryOptions(matcher, in.Profile, in.ExcludedEdges, in.AvoidPolygons, srid)
+	if err != nil {
+		return nil, err
+	}
+	point := horizon.NewGPSMeasurementFromID(0, in.Lon, in.Lat, srid)
+	result, err := matcher.NearestFacilities(point, k, maxCost, maxNearestRadius, queryOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if len(matcher.Facilities()) == 0 {
+		response.Warnings = append(response.Warnings, "there are no facilities. Upload them via SetFacilities")
+	}
+	for _, found := range result {
+		response.Data = append(response.Data, &protos_pb.FacilityRoute{
+			Facility: &protos_pb.Facility{
+				Id:    found.Facility.ID,
+				Name:  found.Facility.Name,
+				Point: s2PointToGeoPoint(found.Facility.Point, srid),
+			},
+			ProjectedPoint: s2PointToGeoPoint(found.Facility.ProjectedPoint, srid),
+			EdgeId:         found.Facility.Edge.ID,
+			Cost:           found.Cost,
+			Length:         found.Length,
+			Route:          s2PolylineToGeoPoints(found.Route, srid),
+			EdgeIds:        found.EdgeIDs,
+		})
+	}
+	return response, nil
+}
@@ -6,28 +6,57 @@ import (
 
 	"github.com/LdDl/horizon"
 	"github.com/LdDl/horizon/rpc/protos_pb"
-	"github.com/golang/geo/s2"
 )
 
 // GetIsochrones Implement GetIsochrones() to match interface
 func (ts *Microservice) GetIsochrones(ctx context.Context, in *protos_pb.IsochronesRequest) (*protos_pb.IsochronesResponse, error) {
+	matcher := ts.matcher.Snapshot()
 	response := &protos_pb.IsochronesResponse{
 		Isochrones: []*protos_pb.Isochrone{},
 		Warnings:   []string{},
+		Profile:    profileName(in.Profile),
 	}
 
-	gpsMeasurement := horizon.NewGPSMeasurementFromID(0, in.Lon, in.Lat, 4326)
+	srid, err := requestSRID(in.Srid)
+	if err != nil {
+		return nil, err
+	}
+	gpsMeasurement := horizon.NewGPSMeasurementFromID(0, in.Lon, in.Lat, srid)
 
 	maxCost := 0.0
 	if in.MaxCost != nil && *in.MaxCost >= 0 {
 		maxCost = *in.MaxCost
-	} else {
+	} else if len(in.MaxCosts) == 0 {
 		response.Warnings = append(response.Warnings, "max_cost either nil or not in range [0,+Inf]. Using default value: 0.0")
 	}
 
 	maxNearestRadius := horizon.ResolveRadius(in.MaxNearestRadius, horizon.DEFAULT_SP_RADIUS)
 
-	result, err := ts.matcher.FindIsochrones(gpsMeasurement, maxCost, maxNearestRadius)
+	queryOptions, err := prepareQueryOptions(matcher, in.Profile, in.ExcludedEdges, in.AvoidPolygons, srid)
+	if err != nil {
+		return nil, err
+	}
+	if in.Reverse {
+		queryOptions = append(queryOptions, horizon.WithReverse())
+	}
+	multiBand := len(in.MaxCosts) > 0
+	maxCosts := []float64{maxCost}
+	if multiBand {
+		maxCosts = []float64{}
+		for _, cost := range in.MaxCosts {
+			if cost < 0 {
+				response.Warnings = append(response.Warnings, fmt.Sprintf("max_costs should be in range [0,+Inf]. Value %f is ignored", cost))
+				continue
+			}
+			maxCosts = append(maxCosts, cost)
+		}
+		maxCosts, err = horizon.IsochroneThresholds(maxCosts)
+		if err != nil {
+			return nil, err
+		}
+		response.MaxCosts = maxCosts
+	}
+	result, err := matcher.FindIsochroneBands(gpsMeasurement, maxCosts, maxNearestRadius, queryOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -37,19 +66,79 @@ func (ts *Microservice) GetIsochrones(ctx context.Context, in *protos_pb.Isochro
 		if isochrone.Vertex == nil {
 			return nil, fmt.Errorf("empty vertex")
 		}
-		latLon := s2.LatLngFromPoint(*isochrone.Vertex.Point)
-		lon := latLon.Lng.Degrees()
-		lat := latLon.Lat.Degrees()
 		feature := &protos_pb.Isochrone{
 			Id:       int64(i),
 			VertexId: isochrone.Vertex.ID,
 			Cost:     isochrone.Cost,
-			Point: &protos_pb.GeoPoint{
-				Lon: lon,
-				Lat: lat,
-			},
+			Point:    s2PointToGeoPoint(*isochrone.Vertex.Point, srid),
+			Band:     int32(isochrone.Band),
 		}
 		response.Isochrones = append(response.Isochrones, feature)
 	}
+	if in.Edges {
+		edges, err := matcher.FindIsochroneEdges(gpsMeasurement, maxCosts[len(maxCosts)-1], maxNearestRadius, queryOptions...)
+		if err != nil {
+			return nil, err
+		}
+		for _, edge := range edges {
+			response.Edges = append(response.Edges, &protos_pb.IsochroneEdge{
+				EdgeId:       edge.Edge.ID,
+				Geom:         s2PolylineToGeoPoints(edge.Geom, srid),
+				FromFraction: edge.FromFraction,
+				ToFraction:   edge.ToFraction,
+				FromCost:     edge.FromCost,
+				ToCost:       edge.ToCost,
+				Length:       edge.Length,
+				Partial:      edge.Partial,
+			})
+		}
+	}
+	if in.Polygons {
+		params := horizon.IsochronePolygonsOptions{
+			Rings: in.Rings,
+		}
+		if in.Smoothness != nil && *in.Smoothness > 0 {
+			params.Smoothness = *in.Smoothness
+		}
+		bands, err := matcher.FindIsochroneBandPolygons(gpsMeasurement, maxCosts, maxNearestRadius, params, queryOptions...)
+		if err != nil {
+			return nil, err
+		}
+		if multiBand {
+			for _, band := range bands {
+				if len(band.Polygons) == 0 {
+					response.Warnings = append(response.Warnings, fmt.Sprintf("area of band #%d is too small (or degenerate) to build polygon", band.Band))
+				}
+				response.Bands = append(response.Bands, &protos_pb.IsochroneBand{
+					Band:     int32(band.Band),
+					MinCost:  band.MinCost,
+					MaxCost:  band.MaxCost,
+					Polygons: isochronePolygonsToProto(band.Polygons, srid),
+				})
+			}
+		} else {
+			if len(bands[0].Polygons) == 0 {
+				response.Warnings = append(response.Warnings, "reachable area is too small (or degenerate) to build polygon")
+			}
+			response.Polygons = isochronePolygonsToProto(bands[0].Polygons, srid)
+		}
+	}
 	return response, nil
 }
+
+// isochronePolygonsToProto Converts isochrone polygons to gRPC representation with coordinates in the SRID
+func isochronePolygonsToProto(polygons []horizon.IsochronePolygon, srid int) []*protos_pb.IsochronePolygon {
+	ans := make([]*protos_pb.IsochronePolygon, len(polygons))
+	for i := range polygons {
+		ans[i] = &protos_pb.IsochronePolygon{
+			Rings: make([]*protos_pb.Ring, len(polygons[i].Rings)),
+			Area:  polygons[i].Area,
+		}
+		for j, ring := range polygons[i].Rings {
+			ans[i].Rings[j] = &protos_pb.Ring{
+				Points: s2PolylineToGeoPoints(ring, srid),
+			}
+		}
+	}
+	return ans
+}
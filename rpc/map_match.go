@@ -15,13 +15,19 @@ var timestampLayout = "2006-01-02T15:04:05"
 
 // RunMapMatch Implement RunMapMatch() to match interface
 func (ts *Microservice) RunMapMatch(ctx context.Context, in *protos_pb.MapMatchRequest) (*protos_pb.MapMatchResponse, error) {
+	matcher := ts.matcher.Snapshot()
 	if len(in.Gps) < 3 {
 		return nil, fmt.Errorf("please provide 3 GPS points atleast. Provided: %d", len(in.Gps))
 	}
 	response := &protos_pb.MapMatchResponse{
 		Warnings: []string{},
+		Profile:  profileName(in.Profile),
 	}
 
+	srid, err := requestSRID(in.Srid)
+	if err != nil {
+		return nil, err
+	}
 	gpsMeasurements := horizon.GPSMeasurements{}
 	for i := range in.Gps {
 		tm, err := time.Parse(timestampLayout, in.Gps[i].Tm)
@@ -31,9 +37,9 @@ func (ts *Microservice) RunMapMatch(ctx context.Context, in *protos_pb.MapMatchR
 		// Use index of measurement as ID
 		var gpsMeasurement *horizon.GPSMeasurement
 		if in.Gps[i].Accuracy != nil && *in.Gps[i].Accuracy > 0 {
-			gpsMeasurement = horizon.NewGPSMeasurement(i, in.Gps[i].Lon, in.Gps[i].Lat, 4326, horizon.WithGPSTime(tm), horizon.WithGPSAccuracy(*in.Gps[i].Accuracy))
+			gpsMeasurement = horizon.NewGPSMeasurement(i, in.Gps[i].Lon, in.Gps[i].Lat, srid, horizon.WithGPSTime(tm), horizon.WithGPSAccuracy(*in.Gps[i].Accuracy))
 		} else {
-			gpsMeasurement = horizon.NewGPSMeasurement(i, in.Gps[i].Lon, in.Gps[i].Lat, 4326, horizon.WithGPSTime(tm))
+			gpsMeasurement = horizon.NewGPSMeasurement(i, in.Gps[i].Lon, in.Gps[i].Lat, srid, horizon.WithGPSTime(tm))
 		}
 		gpsMeasurements = append(gpsMeasurements, gpsMeasurement)
 	}
@@ -47,7 +53,15 @@ func (ts *Microservice) RunMapMatch(ctx context.Context, in *protos_pb.MapMatchR
 
 	statesRadiusMeters := horizon.ResolveRadius(in.StateRadius, horizon.DEFAULT_STATE_RADIUS)
 
-	result, err := ts.matcher.Run(gpsMeasurements, statesRadiusMeters, maxStates)
+	queryOptions, err := prepareQueryOptions(matcher, in.Profile, in.ExcludedEdges, in.AvoidPolygons, srid)
+	if err != nil {
+		return nil, err
+	}
+	err = validateRouteFormat(in.RouteFormat)
+	if err != nil {
+		return nil, err
+	}
+	result, err := matcher.Run(gpsMeasurements, statesRadiusMeters, maxStates, queryOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("something went wrong on server side: %v", err)
 	}
@@ -65,16 +79,12 @@ func (ts *Microservice) RunMapMatch(ctx context.Context, in *protos_pb.MapMatchR
 
 			// Handle unmatched observations
 			if !observationResult.IsMatched {
-				originalPoint := s2.LatLngFromPoint(observationResult.Observation.GeoPoint.Point)
 				subMatchResp.Observations[i] = &protos_pb.ObservationEdge{
-					ObsIdx:    int32(observationResult.Observation.ID()),
-					IsMatched: false,
-					Code:      uint32(observationResult.Code),
-					OriginalPoint: &protos_pb.GeoPoint{
-						Lon: originalPoint.Lng.Degrees(),
-						Lat: originalPoint.Lat.Degrees(),
-					},
-					NextEdges: []*protos_pb.IntermediateEdge{},
+					ObsIdx:        int32(observationResult.Observation.ID()),
+					IsMatched:     false,
+					Code:          uint32(observationResult.Code),
+					OriginalPoint: s2PointToGeoPoint(observationResult.Observation.GeoPoint.Point, srid),
+					NextEdges:     []*protos_pb.IntermediateEdge{},
 				}
 				continue
 			}
@@ -95,61 +105,36 @@ func (ts *Microservice) RunMapMatch(ctx context.Context, in *protos_pb.MapMatchR
 			if observationResult.MatchedVertex.Point == nil {
 				return nil, fmt.Errorf("matched vertex has nil point for observation %d", observationResult.Observation.ID())
 			}
-			vertexPoint := s2.LatLngFromPoint(*observationResult.MatchedVertex.Point)
-			projectedPoint := s2.LatLngFromPoint(observationResult.ProjectedPoint)
-
-			geomLen := len(matchedEdgePolyline)
-			line := make([]*protos_pb.GeoPoint, geomLen)
-			for k := range matchedEdgePolyline {
-				latLng := s2.LatLngFromPoint(matchedEdgePolyline[k])
-				line[k] = &protos_pb.GeoPoint{
-					Lon: latLng.Lng.Degrees(),
-					Lat: latLng.Lat.Degrees(),
-				}
-			}
 			subMatchResp.Observations[i] = &protos_pb.ObservationEdge{
-				ObsIdx:      int32(observationResult.Observation.ID()),
-				IsMatched:   true,
-				Code:        uint32(observationResult.Code),
-				EdgeId:      observationResult.MatchedEdge.ID,
-				MatchedEdge: line,
-				MatchedVertex: &protos_pb.GeoPoint{
-					Lon: vertexPoint.Lng.Degrees(),
-					Lat: vertexPoint.Lat.Degrees(),
-				},
-				ProjectedPoint: &protos_pb.GeoPoint{
-					Lon: projectedPoint.Lng.Degrees(),
-					Lat: projectedPoint.Lat.Degrees(),
-				},
-				NextEdges: make([]*protos_pb.IntermediateEdge, len(observationResult.NextEdges)),
+				ObsIdx:         int32(observationResult.Observation.ID()),
+				IsMatched:      true,
+				Code:           uint32(observationResult.Code),
+				EdgeId:         observationResult.MatchedEdge.ID,
+				Weight:         observationResult.MatchedEdge.Weight,
+				Length:         observationResult.MatchedEdgeLength,
+				MatchedEdge:    s2PolylineToGeoPoints(matchedEdgePolyline, srid),
+				MatchedVertex:  s2PointToGeoPoint(*observationResult.MatchedVertex.Point, srid),
+				ProjectedPoint: s2PointToGeoPoint(observationResult.ProjectedPoint, srid),
+				NextEdges:      make([]*protos_pb.IntermediateEdge, len(observationResult.NextEdges)),
+				Attributes:     observationResult.MatchedEdgeAttributes.Strings(),
 			}
 			if len(matchedEdgeCut) > 0 {
-				cutLine := make([]*protos_pb.GeoPoint, len(matchedEdgeCut))
-				for k := range matchedEdgeCut {
-					latLng := s2.LatLngFromPoint(matchedEdgeCut[k])
-					cutLine[k] = &protos_pb.GeoPoint{
-						Lon: latLng.Lng.Degrees(),
-						Lat: latLng.Lat.Degrees(),
-					}
-				}
-				subMatchResp.Observations[i].MatchedEdgeCut = cutLine
+				subMatchResp.Observations[i].MatchedEdgeCut = s2PolylineToGeoPoints(matchedEdgeCut, srid)
 			}
 			for j := range observationResult.NextEdges {
-				nextLine := make([]*protos_pb.GeoPoint, len(observationResult.NextEdges[j].Geom))
-				for k := range observationResult.NextEdges[j].Geom {
-					latLng := s2.LatLngFromPoint(observationResult.NextEdges[j].Geom[k])
-					nextLine[k] = &protos_pb.GeoPoint{
-						Lon: latLng.Lng.Degrees(),
-						Lat: latLng.Lat.Degrees(),
-					}
-				}
 				subMatchResp.Observations[i].NextEdges[j] = &protos_pb.IntermediateEdge{
-					Geom:   nextLine,
-					Weight: observationResult.NextEdges[j].Weight,
-					Id:     observationResult.NextEdges[j].ID,
+					Geom:       s2PolylineToGeoPoints(observationResult.NextEdges[j].Geom, srid),
+					Weight:     observationResult.NextEdges[j].Weight,
+					Length:     observationResult.NextEdges[j].Length,
+					Id:         observationResult.NextEdges[j].ID,
+					Attributes: observationResult.NextEdges[j].Attributes.Strings(),
 				}
 			}
 		}
+		subMatchResp.Route, err = prepareRouteGeometry(matcher, subMatch, in.RouteFormat, srid)
+		if err != nil {
+			return nil, fmt.Errorf("something went wrong on server side: %v", err)
+		}
 		response.SubMatches[s] = subMatchResp
 	}
 	return response, nil
@@ -0,0 +1,109 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LdDl/horizon"
+	"github.com/LdDl/horizon/rpc/protos_pb"
+)
+
+// GetNearest Implement GetNearest() to match interface
+func (ts *Microservice) GetNearest(ctx context.Context, in *protos_pb.NearestRequest) (*protos_pb.NearestResponse, error) {
+	matcher := ts.matcher.Snapshot()
+	response := &protos_pb.NearestResponse{
+		Data:     []*protos_pb.NearestEdge{},
+		Warnings: []string{},
+		Profile:  profileName(in.Profile),
+	}
+	n := 5
+	if in.N != nil && *in.N > 0 && *in.N <= 100 {
+		n = int(*in.N)
+	} else if in.N != nil {
+		response.Warnings = append(response.Warnings, "n not in range [1,100]. Using default value: 5")
+	}
+	radius := horizon.ResolveRadius(in.Radius, horizon.DEFAULT_SP_RADIUS)
+	srid, err := requestSRID(in.Srid)
+	if err != nil {
+		return nil, err
+	}
+	queryOptions, err := prepareQueryOptions(matcher, in.Profile, in.ExcludedEdges, in.AvoidPolygons, srid)
+	if err != nil {
+		return nil, err
+	}
+	point := horizon.NewGPSMeasurementFromID(0, in.Lon, in.Lat, srid)
+	result, err := matcher.Nearest(point, n, radius, queryOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("something went wrong on server side: %v", err)
+	}
+	for i := range result {
+		response.Data = append(response.Data, nearestEdgeToProto(&result[i], srid))
+	}
+	return response, nil
+}
+
+// Snap Implement Snap() to match interface
+func (ts *Microservice) Snap(ctx context.Context, in *protos_pb.SnapRequest) (*protos_pb.SnapResponse, error) {
+	matcher := ts.matcher.Snapshot()
+	if len(in.Gps) < 1 {
+		return nil, fmt.Errorf("please provide 1 GPS point atleast. Provided: %d", len(in.Gps))
+	}
+	response := &protos_pb.SnapResponse{
+		Data:     make([]*protos_pb.SnappedPoint, len(in.Gps)),
+		Warnings: []string{},
+		Profile:  profileName(in.Profile),
+	}
+	srid, err := requestSRID(in.Srid)
+	if err != nil {
+		return nil, err
+	}
+	points := horizon.GPSMeasurements{}
+	for i := range in.Gps {
+		// Use index of measurement as ID
+		points = append(points, horizon.NewGPSMeasurementFromID(i, in.Gps[i].Lon, in.Gps[i].Lat, srid))
+	}
+	radius := horizon.ResolveRadius(in.Radius, horizon.DEFAULT_SP_RADIUS)
+	queryOptions, err := prepareQueryOptions(matcher, in.Profile, in.ExcludedEdges, in.AvoidPolygons, srid)
+	if err != nil {
+		return nil, err
+	}
+	result, err := matcher.Snap(points, radius, queryOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("something went wrong on server side: %v", err)
+	}
+	for i := range result {
+		if result[i] == nil {
+			response.Data[i] = &protos_pb.SnappedPoint{IsSnapped: false}
+			response.Warnings = append(response.Warnings, fmt.Sprintf("no edge found for point #%d", i))
+			continue
+		}
+		response.Data[i] = &protos_pb.SnappedPoint{
+			IsSnapped: true,
+			Edge:      nearestEdgeToProto(result[i], srid),
+		}
+	}
+	return response, nil
+}
+
+// nearestEdgeToProto Converts horizon.NearestEdge to its gRPC representation
+func nearestEdgeToProto(nearest *horizon.NearestEdge, srid int) *protos_pb.NearestEdge {
+	ans := &protos_pb.NearestEdge{
+		EdgeId:           nearest.Edge.ID,
+		Geom:             s2PolylineToGeoPoints(*nearest.Edge.Polyline, srid),
+		Weight:           nearest.Weight,
+		Length:           nearest.Length,
+		ProjectedPoint:   s2PointToGeoPoint(nearest.ProjectedPoint, srid),
+		Fraction:         nearest.Fraction,
+		Offset:           nearest.Offset,
+		Distance:         nearest.Distance,
+		VertexId:         nearest.Vertex.ID,
+		WeakComponent:    nearest.WeakComponent,
+		StrongComponent:  nearest.StrongComponent,
+		IsSmallComponent: nearest.IsSmallComponent,
+		Attributes:       nearest.Attributes.Strings(),
+	}
+	if nearest.Vertex.Point != nil {
+		ans.Vertex = s2PointToGeoPoint(*nearest.Vertex.Point, srid)
+	}
+	return ans
+}
@@ -0,0 +1,627 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.32.1
+// source: facilities.proto
+
+package protos_pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Single facility (point of interest)
+type Facility struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Identifier of the facility
+	// Example: 1
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Optional human readable name
+	// Example: Charger #1
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Longitude, Latitude
+	Point         *GeoPoint `protobuf:"bytes,3,opt,name=point,proto3" json:"point,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Facility) Reset() {
+	*x = Facility{}
+	mi := &file_facilities_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Facility) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Facility) ProtoMessage() {}
+
+func (x *Facility) ProtoReflect() protoreflect.Message {
+	mi := &file_facilities_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Facility.ProtoReflect.Descriptor instead.
+func (*Facility) Descriptor() ([]byte, []int) {
+	return file_facilities_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Facility) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Facility) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Facility) GetPoint() *GeoPoint {
+	if x != nil {
+		return x.Point
+	}
+	return nil
+}
+
+// User's request for uploading set of facilities. Existing set is replaced
+type SetFacilitiesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Max distance between facility and its edge (in meters).
+	// Use -1 for no limit, 0 or omit for default (100m), or positive value.
+	Radius *float64 `protobuf:"fixed64,1,opt,name=radius,proto3,oneof" json:"radius,omitempty"`
+	// New set of facilities
+	Facilities []*Facility `protobuf:"bytes,2,rep,name=facilities,proto3" json:"facilities,omitempty"`
+	// SRID of facilities (4326 if omitted). Projected CRS are supported: 3857, UTM zones 32601-32660 and 32701-32760 and registered custom ones. Use 0 for graphs with raw Cartesian coordinates
+	// Example: 4326
+	Srid          *int32 `protobuf:"varint,3,opt,name=srid,proto3,oneof" json:"srid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFacilitiesRequest) Reset() {
+	*x = SetFacilitiesRequest{}
+	mi := &file_facilities_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFacilitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFacilitiesRequest) ProtoMessage() {}
+
+func (x *SetFacilitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_facilities_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFacilitiesRequest.ProtoReflect.Descriptor instead.
+func (*SetFacilitiesRequest) Descriptor() ([]byte, []int) {
+	return file_facilities_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SetFacilitiesRequest) GetRadius() float64 {
+	if x != nil && x.Radius != nil {
+		return *x.Radius
+	}
+	return 0
+}
+
+func (x *SetFacilitiesRequest) GetFacilities() []*Facility {
+	if x != nil {
+		return x.Facilities
+	}
+	return nil
+}
+
+func (x *SetFacilitiesRequest) GetSrid() int32 {
+	if x != nil && x.Srid != nil {
+		return *x.Srid
+	}
+	return 0
+}
+
+// Server's response for uploading set of facilities
+type SetFacilitiesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of stored facilities
+	// Example: 10
+	Loaded int32 `protobuf:"varint,1,opt,name=loaded,proto3" json:"loaded,omitempty"`
+	// Identifiers of facilities which have no edge within the radius (they are not stored)
+	Skipped []int64 `protobuf:"varint,2,rep,packed,name=skipped,proto3" json:"skipped,omitempty"`
+	// List of warnings
+	Warnings      []string `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetFacilitiesResponse) Reset() {
+	*x = SetFacilitiesResponse{}
+	mi := &file_facilities_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetFacilitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetFacilitiesResponse) ProtoMessage() {}
+
+func (x *SetFacilitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_facilities_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetFacilitiesResponse.ProtoReflect.Descriptor instead.
+func (*SetFacilitiesResponse) Descriptor() ([]byte, []int) {
+	return file_facilities_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SetFacilitiesResponse) GetLoaded() int32 {
+	if x != nil {
+		return x.Loaded
+	}
+	return 0
+}
+
+func (x *SetFacilitiesResponse) GetSkipped() []int64 {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+func (x *SetFacilitiesResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+// User's request for facilities which are the nearest by road
+type NearestFacilitiesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Longitude (X for projected SRID)
+	// Example: 37.601249363208915
+	Lon float64 `protobuf:"fixed64,1,opt,name=lon,proto3" json:"lon,omitempty"`
+	// Latitude (Y for projected SRID)
+	// Example: 55.745374309126895
+	Lat float64 `protobuf:"fixed64,2,opt,name=lat,proto3" json:"lat,omitempty"`
+	// Max number of facilities (in range [1, 100], default is 3)
+	// Example: 3
+	K *int32 `protobuf:"varint,3,opt,name=k,proto3,oneof" json:"k,omitempty"`
+	// Max travel cost to facility. Use -1 or omit for no limit
+	// Example: 2100.0
+	MaxCost *float64 `protobuf:"fixed64,4,opt,name=max_cost,json=maxCost,proto3,oneof" json:"max_cost,omitempty"`
+	// Max radius of search for nearest edge (in meters).
+	// Use -1 for no limit, 0 or omit for default (100m), or positive value.
+	MaxNearestRadius *float64 `protobuf:"fixed64,5,opt,name=max_nearest_radius,json=maxNearestRadius,proto3,oneof" json:"max_nearest_radius,omitempty"`
+	// Identifiers of edges which must be neither candidates nor traversed (e.g. road closures)
+	ExcludedEdges []int64 `protobuf:"varint,6,rep,packed,name=excluded_edges,json=excludedEdges,proto3" json:"excluded_edges,omitempty"`
+	// Areas to avoid. Every edge having common points with any of polygons is excluded
+	AvoidPolygons []*Polygon `protobuf:"bytes,7,rep,name=avoid_polygons,json=avoidPolygons,proto3" json:"avoid_polygons,omitempty"`
+	// Name of weight profile used for routing. Empty or omitted stands for 'default' profile
+	// Example: travel_time
+	Profile *string `protobuf:"bytes,8,opt,name=profile,proto3,oneof" json:"profile,omitempty"`
+	// SRID of input coordinates (points and avoid_polygons) and returned geometries (4326 if omitted). Projected CRS are supported: 3857, UTM zones 32601-32660 and 32701-32760 and registered custom ones. Use 0 for graphs with raw Cartesian coordinates
+	// Example: 4326
+	Srid          *int32 `protobuf:"varint,9,opt,name=srid,proto3,oneof" json:"srid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NearestFacilitiesRequest) Reset() {
+	*x = NearestFacilitiesRequest{}
+	mi := &file_facilities_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NearestFacilitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NearestFacilitiesRequest) ProtoMessage() {}
+
+func (x *NearestFacilitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_facilities_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NearestFacilitiesRequest.ProtoReflect.Descriptor instead.
+func (*NearestFacilitiesRequest) Descriptor() ([]byte, []int) {
+	return file_facilities_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *NearestFacilitiesRequest) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+func (x *NearestFacilitiesRequest) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *NearestFacilitiesRequest) GetK() int32 {
+	if x != nil && x.K != nil {
+		return *x.K
+	}
+	return 0
+}
+
+func (x *NearestFacilitiesRequest) GetMaxCost() float64 {
+	if x != nil && x.MaxCost != nil {
+		return *x.MaxCost
+	}
+	return 0
+}
+
+func (x *NearestFacilitiesRequest) GetMaxNearestRadius() float64 {
+	if x != nil && x.MaxNearestRadius != nil {
+		return *x.MaxNearestRadius
+	}
+	return 0
+}
+
+func (x *NearestFacilitiesRequest) GetExcludedEdges() []int64 {
+	if x != nil {
+		return x.ExcludedEdges
+	}
+	return nil
+}
+
+func (x *NearestFacilitiesRequest) GetAvoidPolygons() []*Polygon {
+	if x != nil {
+		return x.AvoidPolygons
+	}
+	return nil
+}
+
+func (x *NearestFacilitiesRequest) GetProfile() string {
+	if x != nil && x.Profile != nil {
+		return *x.Profile
+	}
+	return ""
+}
+
+func (x *NearestFacilitiesRequest) GetSrid() int32 {
+	if x != nil && x.Srid != nil {
+		return *x.Srid
+	}
+	return 0
+}
+
+// Server's response for nearest facilities request
+type NearestFacilitiesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Facilities sorted by travel cost
+	Data []*FacilityRoute `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+	// List of warnings
+	Warnings []string `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// Name of weight profile used for the request. Costs are evaluated for this profile
+	// Example: default
+	Profile       string `protobuf:"bytes,3,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NearestFacilitiesResponse) Reset() {
+	*x = NearestFacilitiesResponse{}
+	mi := &file_facilities_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NearestFacilitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NearestFacilitiesResponse) ProtoMessage() {}
+
+func (x *NearestFacilitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_facilities_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NearestFacilitiesResponse.ProtoReflect.Descriptor instead.
+func (*NearestFacilitiesResponse) Descriptor() ([]byte, []int) {
+	return file_facilities_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NearestFacilitiesResponse) GetData() []*FacilityRoute {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *NearestFacilitiesResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *NearestFacilitiesResponse) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+// Facility found by network search with the route to it
+type FacilityRoute struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The facility
+	Facility *Facility `protobuf:"bytes,1,opt,name=facility,proto3" json:"facility,omitempty"`
+	// Projection of the facility onto its edge
+	ProjectedPoint *GeoPoint `protobuf:"bytes,2,opt,name=projected_point,json=projectedPoint,proto3" json:"projected_point,omitempty"`
+	// Edge which the facility is snapped to
+	// Example: 3149
+	EdgeId int64 `protobuf:"varint,3,opt,name=edge_id,json=edgeId,proto3" json:"edge_id,omitempty"`
+	// Travel cost for the request's weight profile
+	// Example: 1240.5
+	Cost float64 `protobuf:"fixed64,4,opt,name=cost,proto3" json:"cost,omitempty"`
+	// Length of the route (meters)
+	// Example: 1240.5
+	Length float64 `protobuf:"fixed64,5,opt,name=length,proto3" json:"length,omitempty"`
+	// Route geometry
+	Route []*GeoPoint `protobuf:"bytes,6,rep,name=route,proto3" json:"route,omitempty"`
+	// Identifiers of edges along the route
+	EdgeIds       []int64 `protobuf:"varint,7,rep,packed,name=edge_ids,json=edgeIds,proto3" json:"edge_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FacilityRoute) Reset() {
+	*x = FacilityRoute{}
+	mi := &file_facilities_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FacilityRoute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FacilityRoute) ProtoMessage() {}
+
+func (x *FacilityRoute) ProtoReflect() protoreflect.Message {
+	mi := &file_facilities_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FacilityRoute.ProtoReflect.Descriptor instead.
+func (*FacilityRoute) Descriptor() ([]byte, []int) {
+	return file_facilities_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FacilityRoute) GetFacility() *Facility {
+	if x != nil {
+		return x.Facility
+	}
+	return nil
+}
+
+func (x *FacilityRoute) GetProjectedPoint() *GeoPoint {
+	if x != nil {
+		return x.ProjectedPoint
+	}
+	return nil
+}
+
+func (x *FacilityRoute) GetEdgeId() int64 {
+	if x != nil {
+		return x.EdgeId
+	}
+	return 0
+}
+
+func (x *FacilityRoute) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *FacilityRoute) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *FacilityRoute) GetRoute() []*GeoPoint {
+	if x != nil {
+		return x.Route
+	}
+	return nil
+}
+
+func (x *FacilityRoute) GetEdgeIds() []int64 {
+	if x != nil {
+		return x.EdgeIds
+	}
+	return nil
+}
+
+var File_facilities_proto protoreflect.FileDescriptor
+
+const file_facilities_proto_rawDesc = "" +
+	"\n" +
+	"\x10facilities.proto\x12\ahorizon\x1a\vpoint.proto\"W\n" +
+	"\bFacility\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12'\n" +
+	"\x05point\x18\x03 \x01(\v2\x11.horizon.GeoPointR\x05point\"\x93\x01\n" +
+	"\x14SetFacilitiesRequest\x12\x1b\n" +
+	"\x06radius\x18\x01 \x01(\x01H\x00R\x06radius\x88\x01\x01\x121\n" +
+	"\n" +
+	"facilities\x18\x02 \x03(\v2\x11.horizon.FacilityR\n" +
+	"facilities\x12\x17\n" +
+	"\x04srid\x18\x03 \x01(\x05H\x01R\x04srid\x88\x01\x01B\t\n" +
+	"\a_radiusB\a\n" +
+	"\x05_srid\"e\n" +
+	"\x15SetFacilitiesResponse\x12\x16\n" +
+	"\x06loaded\x18\x01 \x01(\x05R\x06loaded\x12\x18\n" +
+	"\askipped\x18\x02 \x03(\x03R\askipped\x12\x1a\n" +
+	"\bwarnings\x18\x03 \x03(\tR\bwarnings\"\xfb\x02\n" +
+	"\x18NearestFacilitiesRequest\x12\x10\n" +
+	"\x03lon\x18\x01 \x01(\x01R\x03lon\x12\x10\n" +
+	"\x03lat\x18\x02 \x01(\x01R\x03lat\x12\x11\n" +
+	"\x01k\x18\x03 \x01(\x05H\x00R\x01k\x88\x01\x01\x12\x1e\n" +
+	"\bmax_cost\x18\x04 \x01(\x01H\x01R\amaxCost\x88\x01\x01\x121\n" +
+	"\x12max_nearest_radius\x18\x05 \x01(\x01H\x02R\x10maxNearestRadius\x88\x01\x01\x12%\n" +
+	"\x0eexcluded_edges\x18\x06 \x03(\x03R\rexcludedEdges\x127\n" +
+	"\x0eavoid_polygons\x18\a \x03(\v2\x10.horizon.PolygonR\ravoidPolygons\x12\x1d\n" +
+	"\aprofile\x18\b \x01(\tH\x03R\aprofile\x88\x01\x01\x12\x17\n" +
+	"\x04srid\x18\t \x01(\x05H\x04R\x04srid\x88\x01\x01B\x04\n" +
+	"\x02_kB\v\n" +
+	"\t_max_costB\x15\n" +
+	"\x13_max_nearest_radiusB\n" +
+	"\n" +
+	"\b_profileB\a\n" +
+	"\x05_srid\"}\n" +
+	"\x19NearestFacilitiesResponse\x12*\n" +
+	"\x04data\x18\x01 \x03(\v2\x16.horizon.FacilityRouteR\x04data\x12\x1a\n" +
+	"\bwarnings\x18\x02 \x03(\tR\bwarnings\x12\x18\n" +
+	"\aprofile\x18\x03 \x01(\tR\aprofile\"\x83\x02\n" +
+	"\rFacilityRoute\x12-\n" +
+	"\bfacility\x18\x01 \x01(\v2\x11.horizon.FacilityR\bfacility\x12:\n" +
+	"\x0fprojected_point\x18\x02 \x01(\v2\x11.horizon.GeoPointR\x0eprojectedPoint\x12\x17\n" +
+	"\aedge_id\x18\x03 \x01(\x03R\x06edgeId\x12\x12\n" +
+	"\x04cost\x18\x04 \x01(\x01R\x04cost\x12\x16\n" +
+	"\x06length\x18\x05 \x01(\x01R\x06length\x12'\n" +
+	"\x05route\x18\x06 \x03(\v2\x11.horizon.GeoPointR\x05route\x12\x19\n" +
+	"\bedge_ids\x18\a \x03(\x03R\aedgeIdsB\x0eZ\f./;protos_pbb\x06proto3"
+
+var (
+	file_facilities_proto_rawDescOnce sync.Once
+	file_facilities_proto_rawDescData []byte
+)
+
+func file_facilities_proto_rawDescGZIP() []byte {
+	file_facilities_proto_rawDescOnce.Do(func() {
+		file_facilities_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_facilities_proto_rawDesc), len(file_facilities_proto_rawDesc)))
+	})
+	return file_facilities_proto_rawDescData
+}
+
+var file_facilities_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_facilities_proto_goTypes = []any{
+	(*Facility)(nil),                  // 0: horizon.Facility
+	(*SetFacilitiesRequest)(nil),      // 1: horizon.SetFacilitiesRequest
+	(*SetFacilitiesResponse)(nil),     // 2: horizon.SetFacilitiesResponse
+	(*NearestFacilitiesRequest)(nil),  // 3: horizon.NearestFacilitiesRequest
+	(*NearestFacilitiesResponse)(nil), // 4: horizon.NearestFacilitiesResponse
+	(*FacilityRoute)(nil),             // 5: horizon.FacilityRoute
+	(*GeoPoint)(nil),                  // 6: horizon.GeoPoint
+	(*Polygon)(nil),                   // 7: horizon.Polygon
+}
+var file_facilities_proto_depIdxs = []int32{
+	6, // 0: horizon.Facility.point:type_name -> horizon.GeoPoint
+	0, // 1: horizon.SetFacilitiesRequest.facilities:type_name -> horizon.Facility
+	7, // 2: horizon.NearestFacilitiesRequest.avoid_polygons:type_name -> horizon.Polygon
+	5, // 3: horizon.NearestFacilitiesResponse.data:type_name -> horizon.FacilityRoute
+	0, // 4: horizon.FacilityRoute.facility:type_name -> horizon.Facility
+	6, // 5: horizon.FacilityRoute.projected_point:type_name -> horizon.GeoPoint
+	6, // 6: horizon.FacilityRoute.route:type_name -> horizon.GeoPoint
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_facilities_proto_init() }
+func file_facilities_proto_init() {
+	if File_facilities_proto != nil {
+		return
+	}
+	file_point_proto_init()
+	file_facilities_proto_msgTypes[1].OneofWrappers = []any{}
+	file_facilities_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_facilities_proto_rawDesc), len(file_facilities_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_facilities_proto_goTypes,
+		DependencyIndexes: file_facilities_proto_depIdxs,
+		MessageInfos:      file_facilities_proto_msgTypes,
+	}.Build()
+	File_facilities_proto = out.File
+	file_facilities_proto_goTypes = nil
+	file_facilities_proto_depIdxs = nil
+}
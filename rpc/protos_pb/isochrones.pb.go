@@ -30,12 +30,40 @@ type IsochronesRequest struct {
 	// Max radius of search for nearest vertex (in meters).
 	// Use -1 for no limit, 0 or omit for default (100m), or positive value.
 	MaxNearestRadius *float64 `protobuf:"fixed64,2,opt,name=max_nearest_radius,json=maxNearestRadius,proto3,oneof" json:"max_nearest_radius,omitempty"`
-	// Longitude
+	// Longitude (X for projected SRID)
 	// Example: 37.601249363208915
 	Lon float64 `protobuf:"fixed64,3,opt,name=lon,proto3" json:"lon,omitempty"`
-	// Latitude
+	// Latitude (Y for projected SRID)
 	// Example: 55.745374309126895
-	Lat           float64 `protobuf:"fixed64,4,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lat float64 `protobuf:"fixed64,4,opt,name=lat,proto3" json:"lat,omitempty"`
+	// Identifiers of edges which must be neither candidates nor traversed (e.g. road closures)
+	ExcludedEdges []int64 `protobuf:"varint,5,rep,packed,name=excluded_edges,json=excludedEdges,proto3" json:"excluded_edges,omitempty"`
+	// Areas to avoid. Every edge having common points with any of polygons is excluded
+	AvoidPolygons []*Polygon `protobuf:"bytes,6,rep,name=avoid_polygons,json=avoidPolygons,proto3" json:"avoid_polygons,omitempty"`
+	// Name of weight profile used for routing, transitions and isochrones. Empty or omitted stands for 'default' profile
+	// Example: travel_time
+	Profile *string `protobuf:"bytes,7,opt,name=profile,proto3,oneof" json:"profile,omitempty"`
+	// Build polygons covering reachable area (alpha shape over reachable part of the network)
+	// Example: true
+	Polygons bool `protobuf:"varint,8,opt,name=polygons,proto3" json:"polygons,omitempty"`
+	// Max circumradius of triangles in alpha shape (in meters). Bigger value gives smoother polygons up to convex hull. Use 0 or omit for automatic value
+	// Example: 150.0
+	Smoothness *float64 `protobuf:"fixed64,9,opt,name=smoothness,proto3,oneof" json:"smoothness,omitempty"`
+	// Cost thresholds for multi-band isochrones. Overrides max_cost when provided: single search is done for the biggest threshold and every isochrone gets band label. Should be in range [0,+Inf]
+	// Example: [600.0, 1200.0, 2100.0]
+	MaxCosts []float64 `protobuf:"fixed64,10,rep,packed,name=max_costs,json=maxCosts,proto3" json:"max_costs,omitempty"`
+	// For multi-band isochrones only: polygons of every band cover area between previous and current thresholds (rings) instead of whole area reachable within the threshold (nested polygons)
+	// Example: false
+	Rings bool `protobuf:"varint,11,opt,name=rings,proto3" json:"rings,omitempty"`
+	// Search on reversed graph: costs are the ones of reaching the point from vertices ("who can reach this point"), e.g. catchment area of a store
+	// Example: false
+	Reverse bool `protobuf:"varint,12,opt,name=reverse,proto3" json:"reverse,omitempty"`
+	// Return reachable parts of edges: fully reachable edges and boundary edges cut at max cost. Search starts from projection of the point onto the nearest edge
+	// Example: false
+	Edges bool `protobuf:"varint,13,opt,name=edges,proto3" json:"edges,omitempty"`
+	// SRID of input coordinates (points and avoid_polygons) and returned geometries (4326 if omitted). Projected CRS are supported: 3857, UTM zones 32601-32660 and 32701-32760 and registered custom ones. Use 0 for graphs with raw Cartesian coordinates
+	// Example: 4326
+	Srid          *int32 `protobuf:"varint,14,opt,name=srid,proto3,oneof" json:"srid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -98,13 +126,94 @@ func (x *IsochronesRequest) GetLat() float64 {
 	return 0
 }
 
+func (x *IsochronesRequest) GetExcludedEdges() []int64 {
+	if x != nil {
+		return x.ExcludedEdges
+	}
+	return nil
+}
+
+func (x *IsochronesRequest) GetAvoidPolygons() []*Polygon {
+	if x != nil {
+		return x.AvoidPolygons
+	}
+	return nil
+}
+
+func (x *IsochronesRequest) GetProfile() string {
+	if x != nil && x.Profile != nil {
+		return *x.Profile
+	}
+	return ""
+}
+
+func (x *IsochronesRequest) GetPolygons() bool {
+	if x != nil {
+		return x.Polygons
+	}
+	return false
+}
+
+func (x *IsochronesRequest) GetSmoothness() float64 {
+	if x != nil && x.Smoothness != nil {
+		return *x.Smoothness
+	}
+	return 0
+}
+
+func (x *IsochronesRequest) GetMaxCosts() []float64 {
+	if x != nil {
+		return x.MaxCosts
+	}
+	return nil
+}
+
+func (x *IsochronesRequest) GetRings() bool {
+	if x != nil {
+		return x.Rings
+	}
+	return false
+}
+
+func (x *IsochronesRequest) GetReverse() bool {
+	if x != nil {
+		return x.Reverse
+	}
+	return false
+}
+
+func (x *IsochronesRequest) GetEdges() bool {
+	if x != nil {
+		return x.Edges
+	}
+	return false
+}
+
+func (x *IsochronesRequest) GetSrid() int32 {
+	if x != nil && x.Srid != nil {
+		return *x.Srid
+	}
+	return 0
+}
+
 // Server's response for isochrones request
 type IsochronesResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// List of isochrones
 	Isochrones []*Isochrone `protobuf:"bytes,1,rep,name=isochrones,proto3" json:"isochrones,omitempty"`
 	// List of warnings
-	Warnings      []string `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Warnings []string `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// Name of weight profile used for the request. Costs are evaluated for this profile
+	// Example: default
+	Profile string `protobuf:"bytes,3,opt,name=profile,proto3" json:"profile,omitempty"`
+	// Polygons covering reachable area (bigger ones go first). Only if 'polygons' is requested
+	Polygons []*IsochronePolygon `protobuf:"bytes,4,rep,name=polygons,proto3" json:"polygons,omitempty"`
+	// Sorted thresholds of multi-band isochrones: band label refers to index in this list. Only if 'max_costs' is provided
+	MaxCosts []float64 `protobuf:"fixed64,5,rep,packed,name=max_costs,json=maxCosts,proto3" json:"max_costs,omitempty"`
+	// Polygons for every band. Only if both 'polygons' and 'max_costs' are requested
+	Bands []*IsochroneBand `protobuf:"bytes,6,rep,name=bands,proto3" json:"bands,omitempty"`
+	// Reachable parts of edges. Only if 'edges' is requested (the biggest of 'max_costs' is used for multi-band isochrones)
+	Edges         []*IsochroneEdge `protobuf:"bytes,7,rep,name=edges,proto3" json:"edges,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -153,6 +262,289 @@ func (x *IsochronesResponse) GetWarnings() []string {
 	return nil
 }
 
+func (x *IsochronesResponse) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *IsochronesResponse) GetPolygons() []*IsochronePolygon {
+	if x != nil {
+		return x.Polygons
+	}
+	return nil
+}
+
+func (x *IsochronesResponse) GetMaxCosts() []float64 {
+	if x != nil {
+		return x.MaxCosts
+	}
+	return nil
+}
+
+func (x *IsochronesResponse) GetBands() []*IsochroneBand {
+	if x != nil {
+		return x.Bands
+	}
+	return nil
+}
+
+func (x *IsochronesResponse) GetEdges() []*IsochroneEdge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+// Reachable part of the edge
+type IsochroneEdge struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Edge identifier
+	// Example: 3149
+	EdgeId int64 `protobuf:"varint,1,opt,name=edge_id,json=edgeId,proto3" json:"edge_id,omitempty"`
+	// Geometry of the reachable part (in direction of the edge)
+	Geom []*GeoPoint `protobuf:"bytes,2,rep,name=geom,proto3" json:"geom,omitempty"`
+	// Number in [0;1], describes where the reachable part starts on the edge
+	// Example: 0.0
+	FromFraction float64 `protobuf:"fixed64,3,opt,name=from_fraction,json=fromFraction,proto3" json:"from_fraction,omitempty"`
+	// Number in [from_fraction;1], describes where the reachable part ends on the edge
+	// Example: 0.35
+	ToFraction float64 `protobuf:"fixed64,4,opt,name=to_fraction,json=toFraction,proto3" json:"to_fraction,omitempty"`
+	// Travel cost at the start of the reachable part
+	// Example: 1950.0
+	FromCost float64 `protobuf:"fixed64,5,opt,name=from_cost,json=fromCost,proto3" json:"from_cost,omitempty"`
+	// Travel cost at the end of the reachable part
+	// Example: 2100.0
+	ToCost float64 `protobuf:"fixed64,6,opt,name=to_cost,json=toCost,proto3" json:"to_cost,omitempty"`
+	// Length of the reachable part (meters)
+	// Example: 150.0
+	Length float64 `protobuf:"fixed64,7,opt,name=length,proto3" json:"length,omitempty"`
+	// Whether only part of the edge is reachable
+	// Example: true
+	Partial       bool `protobuf:"varint,8,opt,name=partial,proto3" json:"partial,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IsochroneEdge) Reset() {
+	*x = IsochroneEdge{}
+	mi := &file_isochrones_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IsochroneEdge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsochroneEdge) ProtoMessage() {}
+
+func (x *IsochroneEdge) ProtoReflect() protoreflect.Message {
+	mi := &file_isochrones_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsochroneEdge.ProtoReflect.Descriptor instead.
+func (*IsochroneEdge) Descriptor() ([]byte, []int) {
+	return file_isochrones_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IsochroneEdge) GetEdgeId() int64 {
+	if x != nil {
+		return x.EdgeId
+	}
+	return 0
+}
+
+func (x *IsochroneEdge) GetGeom() []*GeoPoint {
+	if x != nil {
+		return x.Geom
+	}
+	return nil
+}
+
+func (x *IsochroneEdge) GetFromFraction() float64 {
+	if x != nil {
+		return x.FromFraction
+	}
+	return 0
+}
+
+func (x *IsochroneEdge) GetToFraction() float64 {
+	if x != nil {
+		return x.ToFraction
+	}
+	return 0
+}
+
+func (x *IsochroneEdge) GetFromCost() float64 {
+	if x != nil {
+		return x.FromCost
+	}
+	return 0
+}
+
+func (x *IsochroneEdge) GetToCost() float64 {
+	if x != nil {
+		return x.ToCost
+	}
+	return 0
+}
+
+func (x *IsochroneEdge) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *IsochroneEdge) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+// Polygons of single band of multi-band isochrones
+type IsochroneBand struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Index of the band
+	// Example: 1
+	Band int32 `protobuf:"varint,1,opt,name=band,proto3" json:"band,omitempty"`
+	// Threshold of the previous band (0 for the first band)
+	// Example: 600.0
+	MinCost float64 `protobuf:"fixed64,2,opt,name=min_cost,json=minCost,proto3" json:"min_cost,omitempty"`
+	// Threshold of the band
+	// Example: 1200.0
+	MaxCost float64 `protobuf:"fixed64,3,opt,name=max_cost,json=maxCost,proto3" json:"max_cost,omitempty"`
+	// Polygons covering the band (bigger ones go first)
+	Polygons      []*IsochronePolygon `protobuf:"bytes,4,rep,name=polygons,proto3" json:"polygons,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IsochroneBand) Reset() {
+	*x = IsochroneBand{}
+	mi := &file_isochrones_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IsochroneBand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsochroneBand) ProtoMessage() {}
+
+func (x *IsochroneBand) ProtoReflect() protoreflect.Message {
+	mi := &file_isochrones_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsochroneBand.ProtoReflect.Descriptor instead.
+func (*IsochroneBand) Descriptor() ([]byte, []int) {
+	return file_isochrones_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *IsochroneBand) GetBand() int32 {
+	if x != nil {
+		return x.Band
+	}
+	return 0
+}
+
+func (x *IsochroneBand) GetMinCost() float64 {
+	if x != nil {
+		return x.MinCost
+	}
+	return 0
+}
+
+func (x *IsochroneBand) GetMaxCost() float64 {
+	if x != nil {
+		return x.MaxCost
+	}
+	return 0
+}
+
+func (x *IsochroneBand) GetPolygons() []*IsochronePolygon {
+	if x != nil {
+		return x.Polygons
+	}
+	return nil
+}
+
+// Polygon covering reachable area
+type IsochronePolygon struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The first ring is outer boundary, others are holes. Every ring is closed
+	Rings []*Ring `protobuf:"bytes,1,rep,name=rings,proto3" json:"rings,omitempty"`
+	// Area of polygon excluding holes (square meters)
+	// Example: 8359.9
+	Area          float64 `protobuf:"fixed64,2,opt,name=area,proto3" json:"area,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IsochronePolygon) Reset() {
+	*x = IsochronePolygon{}
+	mi := &file_isochrones_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IsochronePolygon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsochronePolygon) ProtoMessage() {}
+
+func (x *IsochronePolygon) ProtoReflect() protoreflect.Message {
+	mi := &file_isochrones_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsochronePolygon.ProtoReflect.Descriptor instead.
+func (*IsochronePolygon) Descriptor() ([]byte, []int) {
+	return file_isochrones_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *IsochronePolygon) GetRings() []*Ring {
+	if x != nil {
+		return x.Rings
+	}
+	return nil
+}
+
+func (x *IsochronePolygon) GetArea() float64 {
+	if x != nil {
+		return x.Area
+	}
+	return 0
+}
+
 // Single isochrone information
 type Isochrone struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -163,14 +555,17 @@ type Isochrone struct {
 	// Vertex ID in the graph
 	VertexId int64 `protobuf:"varint,3,opt,name=vertex_id,json=vertexId,proto3" json:"vertex_id,omitempty"`
 	// Longitude, Latitude
-	Point         *GeoPoint `protobuf:"bytes,4,opt,name=point,proto3" json:"point,omitempty"`
+	Point *GeoPoint `protobuf:"bytes,4,opt,name=point,proto3" json:"point,omitempty"`
+	// Index of the band: position of the smallest threshold which is not less than cost in sorted 'max_costs'. Always 0 when 'max_costs' is not provided
+	// Example: 1
+	Band          int32 `protobuf:"varint,5,opt,name=band,proto3" json:"band,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Isochrone) Reset() {
 	*x = Isochrone{}
-	mi := &file_isochrones_proto_msgTypes[2]
+	mi := &file_isochrones_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -182,7 +577,7 @@ func (x *Isochrone) String() string {
 func (*Isochrone) ProtoMessage() {}
 
 func (x *Isochrone) ProtoReflect() protoreflect.Message {
-	mi := &file_isochrones_proto_msgTypes[2]
+	mi := &file_isochrones_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -195,7 +590,7 @@ func (x *Isochrone) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Isochrone.ProtoReflect.Descriptor instead.
 func (*Isochrone) Descriptor() ([]byte, []int) {
-	return file_isochrones_proto_rawDescGZIP(), []int{2}
+	return file_isochrones_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Isochrone) GetId() int64 {
@@ -226,28 +621,76 @@ func (x *Isochrone) GetPoint() *GeoPoint {
 	return nil
 }
 
+func (x *Isochrone) GetBand() int32 {
+	if x != nil {
+		return x.Band
+	}
+	return 0
+}
+
 var File_isochrones_proto protoreflect.FileDescriptor
 
 const file_isochrones_proto_rawDesc = "" +
 	"\n" +
-	"\x10isochrones.proto\x12\ahorizon\x1a\vpoint.proto\"\xae\x01\n" +
+	"\x10isochrones.proto\x12\ahorizon\x1a\vpoint.proto\"\x8e\x04\n" +
 	"\x11IsochronesRequest\x12\x1e\n" +
 	"\bmax_cost\x18\x01 \x01(\x01H\x00R\amaxCost\x88\x01\x01\x121\n" +
 	"\x12max_nearest_radius\x18\x02 \x01(\x01H\x01R\x10maxNearestRadius\x88\x01\x01\x12\x10\n" +
 	"\x03lon\x18\x03 \x01(\x01R\x03lon\x12\x10\n" +
-	"\x03lat\x18\x04 \x01(\x01R\x03latB\v\n" +
+	"\x03lat\x18\x04 \x01(\x01R\x03lat\x12%\n" +
+	"\x0eexcluded_edges\x18\x05 \x03(\x03R\rexcludedEdges\x127\n" +
+	"\x0eavoid_polygons\x18\x06 \x03(\v2\x10.horizon.PolygonR\ravoidPolygons\x12\x1d\n" +
+	"\aprofile\x18\a \x01(\tH\x02R\aprofile\x88\x01\x01\x12\x1a\n" +
+	"\bpolygons\x18\b \x01(\bR\bpolygons\x12#\n" +
+	"\n" +
+	"smoothness\x18\t \x01(\x01H\x03R\n" +
+	"smoothness\x88\x01\x01\x12\x1b\n" +
+	"\tmax_costs\x18\n" +
+	" \x03(\x01R\bmaxCosts\x12\x14\n" +
+	"\x05rings\x18\v \x01(\bR\x05rings\x12\x18\n" +
+	"\areverse\x18\f \x01(\bR\areverse\x12\x14\n" +
+	"\x05edges\x18\r \x01(\bR\x05edges\x12\x17\n" +
+	"\x04srid\x18\x0e \x01(\x05H\x04R\x04srid\x88\x01\x01B\v\n" +
 	"\t_max_costB\x15\n" +
-	"\x13_max_nearest_radius\"d\n" +
+	"\x13_max_nearest_radiusB\n" +
+	"\n" +
+	"\b_profileB\r\n" +
+	"\v_smoothnessB\a\n" +
+	"\x05_srid\"\xae\x02\n" +
 	"\x12IsochronesResponse\x122\n" +
 	"\n" +
 	"isochrones\x18\x01 \x03(\v2\x12.horizon.IsochroneR\n" +
 	"isochrones\x12\x1a\n" +
-	"\bwarnings\x18\x02 \x03(\tR\bwarnings\"u\n" +
+	"\bwarnings\x18\x02 \x03(\tR\bwarnings\x12\x18\n" +
+	"\aprofile\x18\x03 \x01(\tR\aprofile\x125\n" +
+	"\bpolygons\x18\x04 \x03(\v2\x19.horizon.IsochronePolygonR\bpolygons\x12\x1b\n" +
+	"\tmax_costs\x18\x05 \x03(\x01R\bmaxCosts\x12,\n" +
+	"\x05bands\x18\x06 \x03(\v2\x16.horizon.IsochroneBandR\x05bands\x12,\n" +
+	"\x05edges\x18\a \x03(\v2\x16.horizon.IsochroneEdgeR\x05edges\"\xfd\x01\n" +
+	"\rIsochroneEdge\x12\x17\n" +
+	"\aedge_id\x18\x01 \x01(\x03R\x06edgeId\x12%\n" +
+	"\x04geom\x18\x02 \x03(\v2\x11.horizon.GeoPointR\x04geom\x12#\n" +
+	"\rfrom_fraction\x18\x03 \x01(\x01R\ffromFraction\x12\x1f\n" +
+	"\vto_fraction\x18\x04 \x01(\x01R\n" +
+	"toFraction\x12\x1b\n" +
+	"\tfrom_cost\x18\x05 \x01(\x01R\bfromCost\x12\x17\n" +
+	"\ato_cost\x18\x06 \x01(\x01R\x06toCost\x12\x16\n" +
+	"\x06length\x18\a \x01(\x01R\x06length\x12\x18\n" +
+	"\apartial\x18\b \x01(\bR\apartial\"\x90\x01\n" +
+	"\rIsochroneBand\x12\x12\n" +
+	"\x04band\x18\x01 \x01(\x05R\x04band\x12\x19\n" +
+	"\bmin_cost\x18\x02 \x01(\x01R\aminCost\x12\x19\n" +
+	"\bmax_cost\x18\x03 \x01(\x01R\amaxCost\x125\n" +
+	"\bpolygons\x18\x04 \x03(\v2\x19.horizon.IsochronePolygonR\bpolygons\"K\n" +
+	"\x10IsochronePolygon\x12#\n" +
+	"\x05rings\x18\x01 \x03(\v2\r.horizon.RingR\x05rings\x12\x12\n" +
+	"\x04area\x18\x02 \x01(\x01R\x04area\"\x89\x01\n" +
 	"\tIsochrone\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04cost\x18\x02 \x01(\x01R\x04cost\x12\x1b\n" +
 	"\tvertex_id\x18\x03 \x01(\x03R\bvertexId\x12'\n" +
-	"\x05point\x18\x04 \x01(\v2\x11.horizon.GeoPointR\x05pointB\x0eZ\f./;protos_pbb\x06proto3"
+	"\x05point\x18\x04 \x01(\v2\x11.horizon.GeoPointR\x05point\x12\x12\n" +
+	"\x04band\x18\x05 \x01(\x05R\x04bandB\x0eZ\f./;protos_pbb\x06proto3"
 
 var (
 	file_isochrones_proto_rawDescOnce sync.Once
@@ -261,21 +704,33 @@ func file_isochrones_proto_rawDescGZIP() []byte {
 	return file_isochrones_proto_rawDescData
 }
 
-var file_isochrones_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_isochrones_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_isochrones_proto_goTypes = []any{
 	(*IsochronesRequest)(nil),  // 0: horizon.IsochronesRequest
 	(*IsochronesResponse)(nil), // 1: horizon.IsochronesResponse
-	(*Isochrone)(nil),          // 2: horizon.Isochrone
-	(*GeoPoint)(nil),           // 3: horizon.GeoPoint
+	(*IsochroneEdge)(nil),      // 2: horizon.IsochroneEdge
+	(*IsochroneBand)(nil),      // 3: horizon.IsochroneBand
+	(*IsochronePolygon)(nil),   // 4: horizon.IsochronePolygon
+	(*Isochrone)(nil),          // 5: horizon.Isochrone
+	(*Polygon)(nil),            // 6: horizon.Polygon
+	(*GeoPoint)(nil),           // 7: horizon.GeoPoint
+	(*Ring)(nil),               // 8: horizon.Ring
 }
 var file_isochrones_proto_depIdxs = []int32{
-	2, // 0: horizon.IsochronesResponse.isochrones:type_name -> horizon.Isochrone
-	3, // 1: horizon.Isochrone.point:type_name -> horizon.GeoPoint
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	6, // 0: horizon.IsochronesRequest.avoid_polygons:type_name -> horizon.Polygon
+	5, // 1: horizon.IsochronesResponse.isochrones:type_name -> horizon.Isochrone
+	4, // 2: horizon.IsochronesResponse.polygons:type_name -> horizon.IsochronePolygon
+	3, // 3: horizon.IsochronesResponse.bands:type_name -> horizon.IsochroneBand
+	2, // 4: horizon.IsochronesResponse.edges:type_name -> horizon.IsochroneEdge
+	7, // 5: horizon.IsochroneEdge.geom:type_name -> horizon.GeoPoint
+	4, // 6: horizon.IsochroneBand.polygons:type_name -> horizon.IsochronePolygon
+	8, // 7: horizon.IsochronePolygon.rings:type_name -> horizon.Ring
+	7, // 8: horizon.Isochrone.point:type_name -> horizon.GeoPoint
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	9, // [9:9] is the sub-list for extension type_name
+	9, // [9:9] is the sub-list for extension extendee
+	0, // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_isochrones_proto_init() }
@@ -291,7 +746,7 @@ func file_isochrones_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_isochrones_proto_rawDesc), len(file_isochrones_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -31,7 +31,20 @@ type MapMatchRequest struct {
 	// Use -1 for no limit, 0 or omit for default (50m), or positive value.
 	StateRadius *float64 `protobuf:"fixed64,2,opt,name=state_radius,json=stateRadius,proto3,oneof" json:"state_radius,omitempty"`
 	// Set of GPS data
-	Gps           []*GPSToMapMatch `protobuf:"bytes,3,rep,name=gps,proto3" json:"gps,omitempty"`
+	Gps []*GPSToMapMatch `protobuf:"bytes,3,rep,name=gps,proto3" json:"gps,omitempty"`
+	// Identifiers of edges which must be neither candidates nor traversed (e.g. road closures)
+	ExcludedEdges []int64 `protobuf:"varint,4,rep,packed,name=excluded_edges,json=excludedEdges,proto3" json:"excluded_edges,omitempty"`
+	// Areas to avoid. Every edge having common points with any of polygons is excluded
+	AvoidPolygons []*Polygon `protobuf:"bytes,5,rep,name=avoid_polygons,json=avoidPolygons,proto3" json:"avoid_polygons,omitempty"`
+	// Name of weight profile used for routing, transitions and isochrones. Empty or omitted stands for 'default' profile
+	// Example: travel_time
+	Profile *string `protobuf:"bytes,6,opt,name=profile,proto3,oneof" json:"profile,omitempty"`
+	// Format of merged route geometry: 'geojson', 'polyline' (Google encoded polyline, precision 5), 'polyline6' (precision 6) or 'wkt'. Empty or omitted means that merged geometry is not returned
+	// Example: polyline6
+	RouteFormat *string `protobuf:"bytes,7,opt,name=route_format,json=routeFormat,proto3,oneof" json:"route_format,omitempty"`
+	// SRID of input coordinates (points and avoid_polygons) and returned geometries (4326 if omitted). Projected CRS are supported: 3857, UTM zones 32601-32660 and 32701-32760 and registered custom ones. Use 0 for graphs with raw Cartesian coordinates
+	// Example: 4326
+	Srid          *int32 `protobuf:"varint,8,opt,name=srid,proto3,oneof" json:"srid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -87,16 +100,51 @@ func (x *MapMatchRequest) GetGps() []*GPSToMapMatch {
 	return nil
 }
 
+func (x *MapMatchRequest) GetExcludedEdges() []int64 {
+	if x != nil {
+		return x.ExcludedEdges
+	}
+	return nil
+}
+
+func (x *MapMatchRequest) GetAvoidPolygons() []*Polygon {
+	if x != nil {
+		return x.AvoidPolygons
+	}
+	return nil
+}
+
+func (x *MapMatchRequest) GetProfile() string {
+	if x != nil && x.Profile != nil {
+		return *x.Profile
+	}
+	return ""
+}
+
+func (x *MapMatchRequest) GetRouteFormat() string {
+	if x != nil && x.RouteFormat != nil {
+		return *x.RouteFormat
+	}
+	return ""
+}
+
+func (x *MapMatchRequest) GetSrid() int32 {
+	if x != nil && x.Srid != nil {
+		return *x.Srid
+	}
+	return 0
+}
+
 // Representation of GPS data
 type GPSToMapMatch struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Timestamp. Field would be ignored for request on '/shortest' service.
 	// Example: 2020-03-11T00:00:00
 	Tm string `protobuf:"bytes,1,opt,name=tm,proto3" json:"tm,omitempty"`
-	// Longitude
+	// Longitude (X for projected SRID)
 	// Example: 37.601249363208915
 	Lon float64 `protobuf:"fixed64,3,opt,name=lon,proto3" json:"lon,omitempty"`
-	// Latitude
+	// Latitude (Y for projected SRID)
 	// Example: 55.745374309126895
 	Lat float64 `protobuf:"fixed64,4,opt,name=lat,proto3" json:"lat,omitempty"`
 	// GPS measurement accuracy in meters (optional, <=0 or null means use default sigma)
@@ -171,7 +219,9 @@ type SubMatch struct {
 	Observations []*ObservationEdge `protobuf:"bytes,1,rep,name=observations,proto3" json:"observations,omitempty"`
 	// Probability from Viterbi algorithm for this segment
 	// Example: -86.578520
-	Probability   float64 `protobuf:"fixed64,2,opt,name=probability,proto3" json:"probability,omitempty"`
+	Probability float64 `protobuf:"fixed64,2,opt,name=probability,proto3" json:"probability,omitempty"`
+	// Merged geometry of the segment (only if 'route_format' is provided)
+	Route         *RouteGeometry `protobuf:"bytes,3,opt,name=route,proto3" json:"route,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -220,13 +270,23 @@ func (x *SubMatch) GetProbability() float64 {
 	return 0
 }
 
+func (x *SubMatch) GetRoute() *RouteGeometry {
+	if x != nil {
+		return x.Route
+	}
+	return nil
+}
+
 // Server's response for map matching request
 type MapMatchResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Array of sub-matches (segments split when route cannot be computed between consecutive points)
 	SubMatches []*SubMatch `protobuf:"bytes,1,rep,name=sub_matches,json=subMatches,proto3" json:"sub_matches,omitempty"`
 	// List of warnings
-	Warnings      []string `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Warnings []string `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// Name of weight profile used for the request
+	// Example: default
+	Profile       string `protobuf:"bytes,3,opt,name=profile,proto3" json:"profile,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -275,6 +335,13 @@ func (x *MapMatchResponse) GetWarnings() []string {
 	return nil
 }
 
+func (x *MapMatchResponse) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
 // Relation between observation and matched edge
 type ObservationEdge struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -304,7 +371,15 @@ type ObservationEdge struct {
 	// Original GPS point as point feature (useful when is_matched=false)
 	OriginalPoint *GeoPoint `protobuf:"bytes,10,opt,name=original_point,json=originalPoint,proto3" json:"original_point,omitempty"`
 	// Set of leading edges up to next observation (so these edges is not matched to any observation explicitly). Could be an empty array if observations are very close to each other or if it just last observation
-	NextEdges     []*IntermediateEdge `protobuf:"bytes,11,rep,name=next_edges,json=nextEdges,proto3" json:"next_edges,omitempty"`
+	NextEdges []*IntermediateEdge `protobuf:"bytes,11,rep,name=next_edges,json=nextEdges,proto3" json:"next_edges,omitempty"`
+	// Travel cost of the whole matched edge for the request's weight profile (0 if is_matched=false)
+	// Example: 12.5
+	Weight float64 `protobuf:"fixed64,12,opt,name=weight,proto3" json:"weight,omitempty"`
+	// Length of the whole matched edge (0 if is_matched=false)
+	// Example: 12.5
+	Length float64 `protobuf:"fixed64,13,opt,name=length,proto3" json:"length,omitempty"`
+	// Attributes of the matched edge (road class, speed limit, name and etc.) in string form
+	Attributes    map[string]string `protobuf:"bytes,14,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -416,6 +491,27 @@ func (x *ObservationEdge) GetNextEdges() []*IntermediateEdge {
 	return nil
 }
 
+func (x *ObservationEdge) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *ObservationEdge) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *ObservationEdge) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
 // Edge which is not matched to any observation but helps to form whole travel path
 type IntermediateEdge struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -426,7 +522,12 @@ type IntermediateEdge struct {
 	Weight float64 `protobuf:"fixed64,2,opt,name=weight,proto3" json:"weight,omitempty"`
 	// Edge identifier
 	// Example: 4278
-	Id            int64 `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"`
+	Id int64 `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"`
+	// Edge length (meters for WGS84 graphs)
+	// Example: 2.0
+	Length float64 `protobuf:"fixed64,4,opt,name=length,proto3" json:"length,omitempty"`
+	// Attributes of the edge (road class, speed limit, name and etc.) in string form
+	Attributes    map[string]string `protobuf:"bytes,5,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -482,31 +583,56 @@ func (x *IntermediateEdge) GetId() int64 {
 	return 0
 }
 
+func (x *IntermediateEdge) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *IntermediateEdge) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
 var File_map_match_proto protoreflect.FileDescriptor
 
 const file_map_match_proto_rawDesc = "" +
 	"\n" +
-	"\x0fmap_match.proto\x12\ahorizon\x1a\vpoint.proto\"\xa7\x01\n" +
+	"\x0fmap_match.proto\x12\ahorizon\x1a\vpoint.proto\x1a\x14route_geometry.proto\"\x8d\x03\n" +
 	"\x0fMapMatchRequest\x12\"\n" +
 	"\n" +
 	"max_states\x18\x01 \x01(\x05H\x00R\tmaxStates\x88\x01\x01\x12&\n" +
 	"\fstate_radius\x18\x02 \x01(\x01H\x01R\vstateRadius\x88\x01\x01\x12(\n" +
-	"\x03gps\x18\x03 \x03(\v2\x16.horizon.GPSToMapMatchR\x03gpsB\r\n" +
+	"\x03gps\x18\x03 \x03(\v2\x16.horizon.GPSToMapMatchR\x03gps\x12%\n" +
+	"\x0eexcluded_edges\x18\x04 \x03(\x03R\rexcludedEdges\x127\n" +
+	"\x0eavoid_polygons\x18\x05 \x03(\v2\x10.horizon.PolygonR\ravoidPolygons\x12\x1d\n" +
+	"\aprofile\x18\x06 \x01(\tH\x02R\aprofile\x88\x01\x01\x12&\n" +
+	"\froute_format\x18\a \x01(\tH\x03R\vrouteFormat\x88\x01\x01\x12\x17\n" +
+	"\x04srid\x18\b \x01(\x05H\x04R\x04srid\x88\x01\x01B\r\n" +
 	"\v_max_statesB\x0f\n" +
-	"\r_state_radius\"q\n" +
+	"\r_state_radiusB\n" +
+	"\n" +
+	"\b_profileB\x0f\n" +
+	"\r_route_formatB\a\n" +
+	"\x05_srid\"q\n" +
 	"\rGPSToMapMatch\x12\x0e\n" +
 	"\x02tm\x18\x01 \x01(\tR\x02tm\x12\x10\n" +
 	"\x03lon\x18\x03 \x01(\x01R\x03lon\x12\x10\n" +
 	"\x03lat\x18\x04 \x01(\x01R\x03lat\x12\x1f\n" +
 	"\baccuracy\x18\x05 \x01(\x01H\x00R\baccuracy\x88\x01\x01B\v\n" +
-	"\t_accuracy\"j\n" +
+	"\t_accuracy\"\x98\x01\n" +
 	"\bSubMatch\x12<\n" +
 	"\fobservations\x18\x01 \x03(\v2\x18.horizon.ObservationEdgeR\fobservations\x12 \n" +
-	"\vprobability\x18\x02 \x01(\x01R\vprobability\"b\n" +
+	"\vprobability\x18\x02 \x01(\x01R\vprobability\x12,\n" +
+	"\x05route\x18\x03 \x01(\v2\x16.horizon.RouteGeometryR\x05route\"|\n" +
 	"\x10MapMatchResponse\x122\n" +
 	"\vsub_matches\x18\x01 \x03(\v2\x11.horizon.SubMatchR\n" +
 	"subMatches\x12\x1a\n" +
-	"\bwarnings\x18\x02 \x03(\tR\bwarnings\"\xf0\x03\n" +
+	"\bwarnings\x18\x02 \x03(\tR\bwarnings\x12\x18\n" +
+	"\aprofile\x18\x03 \x01(\tR\aprofile\"\xa9\x05\n" +
 	"\x0fObservationEdge\x12\x17\n" +
 	"\aobs_idx\x18\x01 \x01(\x05R\x06obsIdx\x12\x1d\n" +
 	"\n" +
@@ -521,11 +647,26 @@ const file_map_match_proto_rawDesc = "" +
 	"\x0eoriginal_point\x18\n" +
 	" \x01(\v2\x11.horizon.GeoPointR\roriginalPoint\x128\n" +
 	"\n" +
-	"next_edges\x18\v \x03(\v2\x19.horizon.IntermediateEdgeR\tnextEdges\"a\n" +
+	"next_edges\x18\v \x03(\v2\x19.horizon.IntermediateEdgeR\tnextEdges\x12\x16\n" +
+	"\x06weight\x18\f \x01(\x01R\x06weight\x12\x16\n" +
+	"\x06length\x18\r \x01(\x01R\x06length\x12H\n" +
+	"\n" +
+	"attributes\x18\x0e \x03(\v2(.horizon.ObservationEdge.AttributesEntryR\n" +
+	"attributes\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x83\x02\n" +
 	"\x10IntermediateEdge\x12%\n" +
 	"\x04geom\x18\x01 \x03(\v2\x11.horizon.GeoPointR\x04geom\x12\x16\n" +
 	"\x06weight\x18\x02 \x01(\x01R\x06weight\x12\x0e\n" +
-	"\x02id\x18\x03 \x01(\x03R\x02idB\x0eZ\f./;protos_pbb\x06proto3"
+	"\x02id\x18\x03 \x01(\x03R\x02id\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x01R\x06length\x12I\n" +
+	"\n" +
+	"attributes\x18\x05 \x03(\v2).horizon.IntermediateEdge.AttributesEntryR\n" +
+	"attributes\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x0eZ\f./;protos_pbb\x06proto3"
 
 var (
 	file_map_match_proto_rawDescOnce sync.Once
@@ -539,7 +680,7 @@ func file_map_match_proto_rawDescGZIP() []byte {
 	return file_map_match_proto_rawDescData
 }
 
-var file_map_match_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_map_match_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_map_match_proto_goTypes = []any{
 	(*MapMatchRequest)(nil),  // 0: horizon.MapMatchRequest
 	(*GPSToMapMatch)(nil),    // 1: horizon.GPSToMapMatch
@@ -547,24 +688,32 @@ var file_map_match_proto_goTypes = []any{
 	(*MapMatchResponse)(nil), // 3: horizon.MapMatchResponse
 	(*ObservationEdge)(nil),  // 4: horizon.ObservationEdge
 	(*IntermediateEdge)(nil), // 5: horizon.IntermediateEdge
-	(*GeoPoint)(nil),         // 6: horizon.GeoPoint
+	nil,                      // 6: horizon.ObservationEdge.AttributesEntry
+	nil,                      // 7: horizon.IntermediateEdge.AttributesEntry
+	(*Polygon)(nil),          // 8: horizon.Polygon
+	(*RouteGeometry)(nil),    // 9: horizon.RouteGeometry
+	(*GeoPoint)(nil),         // 10: horizon.GeoPoint
 }
 var file_map_match_proto_depIdxs = []int32{
 	1,  // 0: horizon.MapMatchRequest.gps:type_name -> horizon.GPSToMapMatch
-	4,  // 1: horizon.SubMatch.observations:type_name -> horizon.ObservationEdge
-	2,  // 2: horizon.MapMatchResponse.sub_matches:type_name -> horizon.SubMatch
-	6,  // 3: horizon.ObservationEdge.matched_edge:type_name -> horizon.GeoPoint
-	6,  // 4: horizon.ObservationEdge.matched_edge_cut:type_name -> horizon.GeoPoint
-	6,  // 5: horizon.ObservationEdge.matched_vertex:type_name -> horizon.GeoPoint
-	6,  // 6: horizon.ObservationEdge.projected_point:type_name -> horizon.GeoPoint
-	6,  // 7: horizon.ObservationEdge.original_point:type_name -> horizon.GeoPoint
-	5,  // 8: horizon.ObservationEdge.next_edges:type_name -> horizon.IntermediateEdge
-	6,  // 9: horizon.IntermediateEdge.geom:type_name -> horizon.GeoPoint
-	10, // [10:10] is the sub-list for method output_type
-	10, // [10:10] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	8,  // 1: horizon.MapMatchRequest.avoid_polygons:type_name -> horizon.Polygon
+	4,  // 2: horizon.SubMatch.observations:type_name -> horizon.ObservationEdge
+	9,  // 3: horizon.SubMatch.route:type_name -> horizon.RouteGeometry
+	2,  // 4: horizon.MapMatchResponse.sub_matches:type_name -> horizon.SubMatch
+	10, // 5: horizon.ObservationEdge.matched_edge:type_name -> horizon.GeoPoint
+	10, // 6: horizon.ObservationEdge.matched_edge_cut:type_name -> horizon.GeoPoint
+	10, // 7: horizon.ObservationEdge.matched_vertex:type_name -> horizon.GeoPoint
+	10, // 8: horizon.ObservationEdge.projected_point:type_name -> horizon.GeoPoint
+	10, // 9: horizon.ObservationEdge.original_point:type_name -> horizon.GeoPoint
+	5,  // 10: horizon.ObservationEdge.next_edges:type_name -> horizon.IntermediateEdge
+	6,  // 11: horizon.ObservationEdge.attributes:type_name -> horizon.ObservationEdge.AttributesEntry
+	10, // 12: horizon.IntermediateEdge.geom:type_name -> horizon.GeoPoint
+	7,  // 13: horizon.IntermediateEdge.attributes:type_name -> horizon.IntermediateEdge.AttributesEntry
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_map_match_proto_init() }
@@ -573,6 +722,7 @@ func file_map_match_proto_init() {
 		return
 	}
 	file_point_proto_init()
+	file_route_geometry_proto_init()
 	file_map_match_proto_msgTypes[0].OneofWrappers = []any{}
 	file_map_match_proto_msgTypes[1].OneofWrappers = []any{}
 	type x struct{}
@@ -581,7 +731,7 @@ func file_map_match_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_map_match_proto_rawDesc), len(file_map_match_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
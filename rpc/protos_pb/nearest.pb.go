@@ -0,0 +1,723 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.32.1
+// source: nearest.proto
+
+package protos_pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// User's request for nearest edges
+type NearestRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Longitude (X for projected SRID)
+	// Example: 37.601249363208915
+	Lon float64 `protobuf:"fixed64,1,opt,name=lon,proto3" json:"lon,omitempty"`
+	// Latitude (Y for projected SRID)
+	// Example: 55.745374309126895
+	Lat float64 `protobuf:"fixed64,2,opt,name=lat,proto3" json:"lat,omitempty"`
+	// Max number of edges (in range [1, 100], default is 5)
+	// Example: 5
+	N *int32 `protobuf:"varint,3,opt,name=n,proto3,oneof" json:"n,omitempty"`
+	// Max radius of search (in meters).
+	// Use -1 for no limit, 0 or omit for default (100m), or positive value.
+	Radius *float64 `protobuf:"fixed64,4,opt,name=radius,proto3,oneof" json:"radius,omitempty"`
+	// Identifiers of edges which must never be returned (e.g. road closures)
+	ExcludedEdges []int64 `protobuf:"varint,5,rep,packed,name=excluded_edges,json=excludedEdges,proto3" json:"excluded_edges,omitempty"`
+	// Areas to avoid. Every edge having common points with any of polygons is excluded
+	AvoidPolygons []*Polygon `protobuf:"bytes,6,rep,name=avoid_polygons,json=avoidPolygons,proto3" json:"avoid_polygons,omitempty"`
+	// Name of weight profile. Edges which are not traversable for the profile are never returned. Empty or omitted stands for 'default' profile
+	// Example: travel_time
+	Profile *string `protobuf:"bytes,7,opt,name=profile,proto3,oneof" json:"profile,omitempty"`
+	// SRID of input coordinates (points and avoid_polygons) and returned geometries (4326 if omitted). Projected CRS are supported: 3857, UTM zones 32601-32660 and 32701-32760 and registered custom ones. Use 0 for graphs with raw Cartesian coordinates
+	// Example: 4326
+	Srid          *int32 `protobuf:"varint,8,opt,name=srid,proto3,oneof" json:"srid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NearestRequest) Reset() {
+	*x = NearestRequest{}
+	mi := &file_nearest_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NearestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NearestRequest) ProtoMessage() {}
+
+func (x *NearestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nearest_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NearestRequest.ProtoReflect.Descriptor instead.
+func (*NearestRequest) Descriptor() ([]byte, []int) {
+	return file_nearest_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *NearestRequest) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+func (x *NearestRequest) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *NearestRequest) GetN() int32 {
+	if x != nil && x.N != nil {
+		return *x.N
+	}
+	return 0
+}
+
+func (x *NearestRequest) GetRadius() float64 {
+	if x != nil && x.Radius != nil {
+		return *x.Radius
+	}
+	return 0
+}
+
+func (x *NearestRequest) GetExcludedEdges() []int64 {
+	if x != nil {
+		return x.ExcludedEdges
+	}
+	return nil
+}
+
+func (x *NearestRequest) GetAvoidPolygons() []*Polygon {
+	if x != nil {
+		return x.AvoidPolygons
+	}
+	return nil
+}
+
+func (x *NearestRequest) GetProfile() string {
+	if x != nil && x.Profile != nil {
+		return *x.Profile
+	}
+	return ""
+}
+
+func (x *NearestRequest) GetSrid() int32 {
+	if x != nil && x.Srid != nil {
+		return *x.Srid
+	}
+	return 0
+}
+
+// Server's response for nearest edges request
+type NearestResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Nearest edges sorted by distance
+	Data []*NearestEdge `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+	// List of warnings
+	Warnings []string `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// Name of weight profile used for the request
+	// Example: default
+	Profile       string `protobuf:"bytes,3,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NearestResponse) Reset() {
+	*x = NearestResponse{}
+	mi := &file_nearest_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NearestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NearestResponse) ProtoMessage() {}
+
+func (x *NearestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nearest_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NearestResponse.ProtoReflect.Descriptor instead.
+func (*NearestResponse) Descriptor() ([]byte, []int) {
+	return file_nearest_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NearestResponse) GetData() []*NearestEdge {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *NearestResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *NearestResponse) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+// User's request for snapping every point to its nearest edge (no map matching is done)
+type SnapRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Max radius of search (in meters).
+	// Use -1 for no limit, 0 or omit for default (100m), or positive value.
+	Radius *float64 `protobuf:"fixed64,1,opt,name=radius,proto3,oneof" json:"radius,omitempty"`
+	// Set of GPS data
+	Gps []*GeoPoint `protobuf:"bytes,2,rep,name=gps,proto3" json:"gps,omitempty"`
+	// Identifiers of edges which must never be used (e.g. road closures)
+	ExcludedEdges []int64 `protobuf:"varint,3,rep,packed,name=excluded_edges,json=excludedEdges,proto3" json:"excluded_edges,omitempty"`
+	// Areas to avoid. Every edge having common points with any of polygons is excluded
+	AvoidPolygons []*Polygon `protobuf:"bytes,4,rep,name=avoid_polygons,json=avoidPolygons,proto3" json:"avoid_polygons,omitempty"`
+	// Name of weight profile. Edges which are not traversable for the profile are never used. Empty or omitted stands for 'default' profile
+	// Example: travel_time
+	Profile *string `protobuf:"bytes,5,opt,name=profile,proto3,oneof" json:"profile,omitempty"`
+	// SRID of input coordinates (points and avoid_polygons) and returned geometries (4326 if omitted). Projected CRS are supported: 3857, UTM zones 32601-32660 and 32701-32760 and registered custom ones. Use 0 for graphs with raw Cartesian coordinates
+	// Example: 4326
+	Srid          *int32 `protobuf:"varint,6,opt,name=srid,proto3,oneof" json:"srid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapRequest) Reset() {
+	*x = SnapRequest{}
+	mi := &file_nearest_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapRequest) ProtoMessage() {}
+
+func (x *SnapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nearest_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapRequest.ProtoReflect.Descriptor instead.
+func (*SnapRequest) Descriptor() ([]byte, []int) {
+	return file_nearest_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SnapRequest) GetRadius() float64 {
+	if x != nil && x.Radius != nil {
+		return *x.Radius
+	}
+	return 0
+}
+
+func (x *SnapRequest) GetGps() []*GeoPoint {
+	if x != nil {
+		return x.Gps
+	}
+	return nil
+}
+
+func (x *SnapRequest) GetExcludedEdges() []int64 {
+	if x != nil {
+		return x.ExcludedEdges
+	}
+	return nil
+}
+
+func (x *SnapRequest) GetAvoidPolygons() []*Polygon {
+	if x != nil {
+		return x.AvoidPolygons
+	}
+	return nil
+}
+
+func (x *SnapRequest) GetProfile() string {
+	if x != nil && x.Profile != nil {
+		return *x.Profile
+	}
+	return ""
+}
+
+func (x *SnapRequest) GetSrid() int32 {
+	if x != nil && x.Srid != nil {
+		return *x.Srid
+	}
+	return 0
+}
+
+// Server's response for snapping request
+type SnapResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Snapping result for each point. Index corresponds to index in incoming request
+	Data []*SnappedPoint `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+	// List of warnings
+	Warnings []string `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// Name of weight profile used for the request
+	// Example: default
+	Profile       string `protobuf:"bytes,3,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapResponse) Reset() {
+	*x = SnapResponse{}
+	mi := &file_nearest_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapResponse) ProtoMessage() {}
+
+func (x *SnapResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nearest_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapResponse.ProtoReflect.Descriptor instead.
+func (*SnapResponse) Descriptor() ([]byte, []int) {
+	return file_nearest_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SnapResponse) GetData() []*SnappedPoint {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *SnapResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *SnapResponse) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+// Snapping result for single point
+type SnappedPoint struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether there is an edge within the radius
+	// Example: true
+	IsSnapped bool `protobuf:"varint,1,opt,name=is_snapped,json=isSnapped,proto3" json:"is_snapped,omitempty"`
+	// Nearest edge (null if is_snapped=false)
+	Edge          *NearestEdge `protobuf:"bytes,2,opt,name=edge,proto3" json:"edge,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnappedPoint) Reset() {
+	*x = SnappedPoint{}
+	mi := &file_nearest_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnappedPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnappedPoint) ProtoMessage() {}
+
+func (x *SnappedPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_nearest_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnappedPoint.ProtoReflect.Descriptor instead.
+func (*SnappedPoint) Descriptor() ([]byte, []int) {
+	return file_nearest_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SnappedPoint) GetIsSnapped() bool {
+	if x != nil {
+		return x.IsSnapped
+	}
+	return false
+}
+
+func (x *SnappedPoint) GetEdge() *NearestEdge {
+	if x != nil {
+		return x.Edge
+	}
+	return nil
+}
+
+// Edge found near the given point
+type NearestEdge struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Edge identifier
+	// Example: 3149
+	EdgeId int64 `protobuf:"varint,1,opt,name=edge_id,json=edgeId,proto3" json:"edge_id,omitempty"`
+	// Edge geometry as line feature
+	Geom []*GeoPoint `protobuf:"bytes,2,rep,name=geom,proto3" json:"geom,omitempty"`
+	// Travel cost of the whole edge for the request's weight profile
+	// Example: 12.5
+	Weight float64 `protobuf:"fixed64,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	// Edge length (meters for WGS84 graphs)
+	// Example: 12.5
+	Length float64 `protobuf:"fixed64,4,opt,name=length,proto3" json:"length,omitempty"`
+	// Projection of the point onto the edge
+	ProjectedPoint *GeoPoint `protobuf:"bytes,5,opt,name=projected_point,json=projectedPoint,proto3" json:"projected_point,omitempty"`
+	// Number in [0;1], describes how far projected point from the first point of edge
+	// Example: 0.25
+	Fraction float64 `protobuf:"fixed64,6,opt,name=fraction,proto3" json:"fraction,omitempty"`
+	// Distance along the edge from its first point to the projected point (meters for WGS84 graphs)
+	// Example: 3.125
+	Offset float64 `protobuf:"fixed64,7,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Distance from the point to the projected point (meters for WGS84 graphs)
+	// Example: 4.2
+	Distance float64 `protobuf:"fixed64,8,opt,name=distance,proto3" json:"distance,omitempty"`
+	// The closest (along the edge) vertex of the edge
+	// Example: 44014
+	VertexId int64 `protobuf:"varint,9,opt,name=vertex_id,json=vertexId,proto3" json:"vertex_id,omitempty"`
+	// The closest vertex as point feature
+	Vertex *GeoPoint `protobuf:"bytes,10,opt,name=vertex,proto3" json:"vertex,omitempty"`
+	// Weakly connected component of the vertex (-1 when unknown)
+	// Example: 0
+	WeakComponent int64 `protobuf:"varint,11,opt,name=weak_component,json=weakComponent,proto3" json:"weak_component,omitempty"`
+	// Strongly connected component of the vertex (-1 when unknown)
+	// Example: 0
+	StrongComponent int64 `protobuf:"varint,12,opt,name=strong_component,json=strongComponent,proto3" json:"strong_component,omitempty"`
+	// Whether strongly connected component of the vertex is very small (such vertex is barely routable)
+	// Example: false
+	IsSmallComponent bool `protobuf:"varint,13,opt,name=is_small_component,json=isSmallComponent,proto3" json:"is_small_component,omitempty"`
+	// Attributes of the edge (road class, speed limit, name and etc.) in string form
+	Attributes    map[string]string `protobuf:"bytes,14,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NearestEdge) Reset() {
+	*x = NearestEdge{}
+	mi := &file_nearest_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NearestEdge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NearestEdge) ProtoMessage() {}
+
+func (x *NearestEdge) ProtoReflect() protoreflect.Message {
+	mi := &file_nearest_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NearestEdge.ProtoReflect.Descriptor instead.
+func (*NearestEdge) Descriptor() ([]byte, []int) {
+	return file_nearest_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *NearestEdge) GetEdgeId() int64 {
+	if x != nil {
+		return x.EdgeId
+	}
+	return 0
+}
+
+func (x *NearestEdge) GetGeom() []*GeoPoint {
+	if x != nil {
+		return x.Geom
+	}
+	return nil
+}
+
+func (x *NearestEdge) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *NearestEdge) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *NearestEdge) GetProjectedPoint() *GeoPoint {
+	if x != nil {
+		return x.ProjectedPoint
+	}
+	return nil
+}
+
+func (x *NearestEdge) GetFraction() float64 {
+	if x != nil {
+		return x.Fraction
+	}
+	return 0
+}
+
+func (x *NearestEdge) GetOffset() float64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *NearestEdge) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *NearestEdge) GetVertexId() int64 {
+	if x != nil {
+		return x.VertexId
+	}
+	return 0
+}
+
+func (x *NearestEdge) GetVertex() *GeoPoint {
+	if x != nil {
+		return x.Vertex
+	}
+	return nil
+}
+
+func (x *NearestEdge) GetWeakComponent() int64 {
+	if x != nil {
+		return x.WeakComponent
+	}
+	return 0
+}
+
+func (x *NearestEdge) GetStrongComponent() int64 {
+	if x != nil {
+		return x.StrongComponent
+	}
+	return 0
+}
+
+func (x *NearestEdge) GetIsSmallComponent() bool {
+	if x != nil {
+		return x.IsSmallComponent
+	}
+	return false
+}
+
+func (x *NearestEdge) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+var File_nearest_proto protoreflect.FileDescriptor
+
+const file_nearest_proto_rawDesc = "" +
+	"\n" +
+	"\rnearest.proto\x12\ahorizon\x1a\vpoint.proto\"\xa2\x02\n" +
+	"\x0eNearestRequest\x12\x10\n" +
+	"\x03lon\x18\x01 \x01(\x01R\x03lon\x12\x10\n" +
+	"\x03lat\x18\x02 \x01(\x01R\x03lat\x12\x11\n" +
+	"\x01n\x18\x03 \x01(\x05H\x00R\x01n\x88\x01\x01\x12\x1b\n" +
+	"\x06radius\x18\x04 \x01(\x01H\x01R\x06radius\x88\x01\x01\x12%\n" +
+	"\x0eexcluded_edges\x18\x05 \x03(\x03R\rexcludedEdges\x127\n" +
+	"\x0eavoid_polygons\x18\x06 \x03(\v2\x10.horizon.PolygonR\ravoidPolygons\x12\x1d\n" +
+	"\aprofile\x18\a \x01(\tH\x02R\aprofile\x88\x01\x01\x12\x17\n" +
+	"\x04srid\x18\b \x01(\x05H\x03R\x04srid\x88\x01\x01B\x04\n" +
+	"\x02_nB\t\n" +
+	"\a_radiusB\n" +
+	"\n" +
+	"\b_profileB\a\n" +
+	"\x05_srid\"q\n" +
+	"\x0fNearestResponse\x12(\n" +
+	"\x04data\x18\x01 \x03(\v2\x14.horizon.NearestEdgeR\x04data\x12\x1a\n" +
+	"\bwarnings\x18\x02 \x03(\tR\bwarnings\x12\x18\n" +
+	"\aprofile\x18\x03 \x01(\tR\aprofile\"\x87\x02\n" +
+	"\vSnapRequest\x12\x1b\n" +
+	"\x06radius\x18\x01 \x01(\x01H\x00R\x06radius\x88\x01\x01\x12#\n" +
+	"\x03gps\x18\x02 \x03(\v2\x11.horizon.GeoPointR\x03gps\x12%\n" +
+	"\x0eexcluded_edges\x18\x03 \x03(\x03R\rexcludedEdges\x127\n" +
+	"\x0eavoid_polygons\x18\x04 \x03(\v2\x10.horizon.PolygonR\ravoidPolygons\x12\x1d\n" +
+	"\aprofile\x18\x05 \x01(\tH\x01R\aprofile\x88\x01\x01\x12\x17\n" +
+	"\x04srid\x18\x06 \x01(\x05H\x02R\x04srid\x88\x01\x01B\t\n" +
+	"\a_radiusB\n" +
+	"\n" +
+	"\b_profileB\a\n" +
+	"\x05_srid\"o\n" +
+	"\fSnapResponse\x12)\n" +
+	"\x04data\x18\x01 \x03(\v2\x15.horizon.SnappedPointR\x04data\x12\x1a\n" +
+	"\bwarnings\x18\x02 \x03(\tR\bwarnings\x12\x18\n" +
+	"\aprofile\x18\x03 \x01(\tR\aprofile\"W\n" +
+	"\fSnappedPoint\x12\x1d\n" +
+	"\n" +
+	"is_snapped\x18\x01 \x01(\bR\tisSnapped\x12(\n" +
+	"\x04edge\x18\x02 \x01(\v2\x14.horizon.NearestEdgeR\x04edge\"\xd6\x04\n" +
+	"\vNearestEdge\x12\x17\n" +
+	"\aedge_id\x18\x01 \x01(\x03R\x06edgeId\x12%\n" +
+	"\x04geom\x18\x02 \x03(\v2\x11.horizon.GeoPointR\x04geom\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x01R\x06weight\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x01R\x06length\x12:\n" +
+	"\x0fprojected_point\x18\x05 \x01(\v2\x11.horizon.GeoPointR\x0eprojectedPoint\x12\x1a\n" +
+	"\bfraction\x18\x06 \x01(\x01R\bfraction\x12\x16\n" +
+	"\x06offset\x18\a \x01(\x01R\x06offset\x12\x1a\n" +
+	"\bdistance\x18\b \x01(\x01R\bdistance\x12\x1b\n" +
+	"\tvertex_id\x18\t \x01(\x03R\bvertexId\x12)\n" +
+	"\x06vertex\x18\n" +
+	" \x01(\v2\x11.horizon.GeoPointR\x06vertex\x12%\n" +
+	"\x0eweak_component\x18\v \x01(\x03R\rweakComponent\x12)\n" +
+	"\x10strong_component\x18\f \x01(\x03R\x0fstrongComponent\x12,\n" +
+	"\x12is_small_component\x18\r \x01(\bR\x10isSmallComponent\x12D\n" +
+	"\n" +
+	"attributes\x18\x0e \x03(\v2$.horizon.NearestEdge.AttributesEntryR\n" +
+	"attributes\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x0eZ\f./;protos_pbb\x06proto3"
+
+var (
+	file_nearest_proto_rawDescOnce sync.Once
+	file_nearest_proto_rawDescData []byte
+)
+
+func file_nearest_proto_rawDescGZIP() []byte {
+	file_nearest_proto_rawDescOnce.Do(func() {
+		file_nearest_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_nearest_proto_rawDesc), len(file_nearest_proto_rawDesc)))
+	})
+	return file_nearest_proto_rawDescData
+}
+
+var file_nearest_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_nearest_proto_goTypes = []any{
+	(*NearestRequest)(nil),  // 0: horizon.NearestRequest
+	(*NearestResponse)(nil), // 1: horizon.NearestResponse
+	(*SnapRequest)(nil),     // 2: horizon.SnapRequest
+	(*SnapResponse)(nil),    // 3: horizon.SnapResponse
+	(*SnappedPoint)(nil),    // 4: horizon.SnappedPoint
+	(*NearestEdge)(nil),     // 5: horizon.NearestEdge
+	nil,                     // 6: horizon.NearestEdge.AttributesEntry
+	(*Polygon)(nil),         // 7: horizon.Polygon
+	(*GeoPoint)(nil),        // 8: horizon.GeoPoint
+}
+var file_nearest_proto_depIdxs = []int32{
+	7,  // 0: horizon.NearestRequest.avoid_polygons:type_name -> horizon.Polygon
+	5,  // 1: horizon.NearestResponse.data:type_name -> horizon.NearestEdge
+	8,  // 2: horizon.SnapRequest.gps:type_name -> horizon.GeoPoint
+	7,  // 3: horizon.SnapRequest.avoid_polygons:type_name -> horizon.Polygon
+	4,  // 4: horizon.SnapResponse.data:type_name -> horizon.SnappedPoint
+	5,  // 5: horizon.SnappedPoint.edge:type_name -> horizon.NearestEdge
+	8,  // 6: horizon.NearestEdge.geom:type_name -> horizon.GeoPoint
+	8,  // 7: horizon.NearestEdge.projected_point:type_name -> horizon.GeoPoint
+	8,  // 8: horizon.NearestEdge.vertex:type_name -> horizon.GeoPoint
+	6,  // 9: horizon.NearestEdge.attributes:type_name -> horizon.NearestEdge.AttributesEntry
+	10, // [10:10] is the sub-list for method output_type
+	10, // [10:10] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_nearest_proto_init() }
+func file_nearest_proto_init() {
+	if File_nearest_proto != nil {
+		return
+	}
+	file_point_proto_init()
+	file_nearest_proto_msgTypes[0].OneofWrappers = []any{}
+	file_nearest_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_nearest_proto_rawDesc), len(file_nearest_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_nearest_proto_goTypes,
+		DependencyIndexes: file_nearest_proto_depIdxs,
+		MessageInfos:      file_nearest_proto_msgTypes,
+	}.Build()
+	File_nearest_proto = out.File
+	file_nearest_proto_goTypes = nil
+	file_nearest_proto_depIdxs = nil
+}
@@ -23,10 +23,10 @@ const (
 
 type GeoPoint struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Longitude
+	// Longitude (X for projected SRID)
 	// Example: 37.601249363208915
 	Lon float64 `protobuf:"fixed64,1,opt,name=lon,proto3" json:"lon,omitempty"`
-	// Latitude
+	// Latitude (Y for projected SRID)
 	// Example: 55.745374309126895
 	Lat           float64 `protobuf:"fixed64,2,opt,name=lat,proto3" json:"lat,omitempty"`
 	unknownFields protoimpl.UnknownFields
@@ -77,6 +77,98 @@ func (x *GeoPoint) GetLat() float64 {
 	return 0
 }
 
+// Closed ring of points. Closing point (equal to the first one) is optional
+type Ring struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Set of points
+	Points        []*GeoPoint `protobuf:"bytes,1,rep,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ring) Reset() {
+	*x = Ring{}
+	mi := &file_point_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ring) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ring) ProtoMessage() {}
+
+func (x *Ring) ProtoReflect() protoreflect.Message {
+	mi := &file_point_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ring.ProtoReflect.Descriptor instead.
+func (*Ring) Descriptor() ([]byte, []int) {
+	return file_point_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Ring) GetPoints() []*GeoPoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+// Polygon: first ring is outer one, others are holes
+type Polygon struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Set of rings
+	Rings         []*Ring `protobuf:"bytes,1,rep,name=rings,proto3" json:"rings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Polygon) Reset() {
+	*x = Polygon{}
+	mi := &file_point_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Polygon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Polygon) ProtoMessage() {}
+
+func (x *Polygon) ProtoReflect() protoreflect.Message {
+	mi := &file_point_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Polygon.ProtoReflect.Descriptor instead.
+func (*Polygon) Descriptor() ([]byte, []int) {
+	return file_point_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Polygon) GetRings() []*Ring {
+	if x != nil {
+		return x.Rings
+	}
+	return nil
+}
+
 var File_point_proto protoreflect.FileDescriptor
 
 const file_point_proto_rawDesc = "" +
@@ -84,7 +176,11 @@ const file_point_proto_rawDesc = "" +
 	"\vpoint.proto\x12\ahorizon\".\n" +
 	"\bGeoPoint\x12\x10\n" +
 	"\x03lon\x18\x01 \x01(\x01R\x03lon\x12\x10\n" +
-	"\x03lat\x18\x02 \x01(\x01R\x03latB\x0eZ\f./;protos_pbb\x06proto3"
+	"\x03lat\x18\x02 \x01(\x01R\x03lat\"1\n" +
+	"\x04Ring\x12)\n" +
+	"\x06points\x18\x01 \x03(\v2\x11.horizon.GeoPointR\x06points\".\n" +
+	"\aPolygon\x12#\n" +
+	"\x05rings\x18\x01 \x03(\v2\r.horizon.RingR\x05ringsB\x0eZ\f./;protos_pbb\x06proto3"
 
 var (
 	file_point_proto_rawDescOnce sync.Once
@@ -98,16 +194,20 @@ func file_point_proto_rawDescGZIP() []byte {
 	return file_point_proto_rawDescData
 }
 
-var file_point_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_point_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_point_proto_goTypes = []any{
 	(*GeoPoint)(nil), // 0: horizon.GeoPoint
+	(*Ring)(nil),     // 1: horizon.Ring
+	(*Polygon)(nil),  // 2: horizon.Polygon
 }
 var file_point_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: horizon.Ring.points:type_name -> horizon.GeoPoint
+	1, // 1: horizon.Polygon.rings:type_name -> horizon.Ring
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_point_proto_init() }
@@ -121,7 +221,7 @@ func file_point_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_point_proto_rawDesc), len(file_point_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,170 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.32.1
+// source: route_geometry.proto
+
+package protos_pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Merged geometry of the route: single continuous line trimmed at the projections of the first and the last points
+type RouteGeometry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Format of geometry
+	// Example: polyline6
+	Format string `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	// Geometry as set of points (only for 'geojson' format)
+	Geom []*GeoPoint `protobuf:"bytes,2,rep,name=geom,proto3" json:"geom,omitempty"`
+	// Encoded geometry (for 'polyline', 'polyline6' and 'wkt' formats)
+	// Example: _p~iF~ps|U_ulLnnqC
+	Encoded string `protobuf:"bytes,3,opt,name=encoded,proto3" json:"encoded,omitempty"`
+	// Cumulative distance from the start of the route for every point of geometry (meters for WGS84 graphs)
+	Distances []float64 `protobuf:"fixed64,4,rep,packed,name=distances,proto3" json:"distances,omitempty"`
+	// Total length of the route (meters for WGS84 graphs)
+	// Example: 250.5
+	Length        float64 `protobuf:"fixed64,5,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RouteGeometry) Reset() {
+	*x = RouteGeometry{}
+	mi := &file_route_geometry_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RouteGeometry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RouteGeometry) ProtoMessage() {}
+
+func (x *RouteGeometry) ProtoReflect() protoreflect.Message {
+	mi := &file_route_geometry_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RouteGeometry.ProtoReflect.Descriptor instead.
+func (*RouteGeometry) Descriptor() ([]byte, []int) {
+	return file_route_geometry_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RouteGeometry) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *RouteGeometry) GetGeom() []*GeoPoint {
+	if x != nil {
+		return x.Geom
+	}
+	return nil
+}
+
+func (x *RouteGeometry) GetEncoded() string {
+	if x != nil {
+		return x.Encoded
+	}
+	return ""
+}
+
+func (x *RouteGeometry) GetDistances() []float64 {
+	if x != nil {
+		return x.Distances
+	}
+	return nil
+}
+
+func (x *RouteGeometry) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+var File_route_geometry_proto protoreflect.FileDescriptor
+
+const file_route_geometry_proto_rawDesc = "" +
+	"\n" +
+	"\x14route_geometry.proto\x12\ahorizon\x1a\vpoint.proto\"\x9e\x01\n" +
+	"\rRouteGeometry\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12%\n" +
+	"\x04geom\x18\x02 \x03(\v2\x11.horizon.GeoPointR\x04geom\x12\x18\n" +
+	"\aencoded\x18\x03 \x01(\tR\aencoded\x12\x1c\n" +
+	"\tdistances\x18\x04 \x03(\x01R\tdistances\x12\x16\n" +
+	"\x06length\x18\x05 \x01(\x01R\x06lengthB\x0eZ\f./;protos_pbb\x06proto3"
+
+var (
+	file_route_geometry_proto_rawDescOnce sync.Once
+	file_route_geometry_proto_rawDescData []byte
+)
+
+func file_route_geometry_proto_rawDescGZIP() []byte {
+	file_route_geometry_proto_rawDescOnce.Do(func() {
+		file_route_geometry_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_route_geometry_proto_rawDesc), len(file_route_geometry_proto_rawDesc)))
+	})
+	return file_route_geometry_proto_rawDescData
+}
+
+var file_route_geometry_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_route_geometry_proto_goTypes = []any{
+	(*RouteGeometry)(nil), // 0: horizon.RouteGeometry
+	(*GeoPoint)(nil),      // 1: horizon.GeoPoint
+}
+var file_route_geometry_proto_depIdxs = []int32{
+	1, // 0: horizon.RouteGeometry.geom:type_name -> horizon.GeoPoint
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_route_geometry_proto_init() }
+func file_route_geometry_proto_init() {
+	if File_route_geometry_proto != nil {
+		return
+	}
+	file_point_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_route_geometry_proto_rawDesc), len(file_route_geometry_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_route_geometry_proto_goTypes,
+		DependencyIndexes: file_route_geometry_proto_depIdxs,
+		MessageInfos:      file_route_geometry_proto_msgTypes,
+	}.Build()
+	File_route_geometry_proto = out.File
+	file_route_geometry_proto_goTypes = nil
+	file_route_geometry_proto_depIdxs = nil
+}
@@ -0,0 +1,431 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.32.1
+// source: route_optimization.proto
+
+package protos_pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// User's request for waypoints order optimization
+type OptimizeRouteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Max radius of search for potential candidates (in meters).
+	// Use -1 for no limit, 0 or omit for default (100m), or positive value.
+	StateRadius *float64 `protobuf:"fixed64,1,opt,name=state_radius,json=stateRadius,proto3,oneof" json:"state_radius,omitempty"`
+	// Set of waypoints (at least 2)
+	Gps []*GeoPoint `protobuf:"bytes,2,rep,name=gps,proto3" json:"gps,omitempty"`
+	// Return to the first waypoint at the end of the route
+	// Example: false
+	Closed bool `protobuf:"varint,3,opt,name=closed,proto3" json:"closed,omitempty"`
+	// Keep the first waypoint as the first visited one
+	// Example: true
+	FixedStart bool `protobuf:"varint,4,opt,name=fixed_start,json=fixedStart,proto3" json:"fixed_start,omitempty"`
+	// Keep the last waypoint as the last visited one (ignored for closed routes)
+	// Example: false
+	FixedEnd bool `protobuf:"varint,5,opt,name=fixed_end,json=fixedEnd,proto3" json:"fixed_end,omitempty"`
+	// Identifiers of edges which must be neither candidates nor traversed (e.g. road closures)
+	ExcludedEdges []int64 `protobuf:"varint,6,rep,packed,name=excluded_edges,json=excludedEdges,proto3" json:"excluded_edges,omitempty"`
+	// Areas to avoid. Every edge having common points with any of polygons is excluded
+	AvoidPolygons []*Polygon `protobuf:"bytes,7,rep,name=avoid_polygons,json=avoidPolygons,proto3" json:"avoid_polygons,omitempty"`
+	// Name of weight profile used for routing. Empty or omitted stands for 'default' profile
+	// Example: travel_time
+	Profile *string `protobuf:"bytes,8,opt,name=profile,proto3,oneof" json:"profile,omitempty"`
+	// SRID of input coordinates (points and avoid_polygons) and returned geometries (4326 if omitted). Projected CRS are supported: 3857, UTM zones 32601-32660 and 32701-32760 and registered custom ones. Use 0 for graphs with raw Cartesian coordinates
+	// Example: 4326
+	Srid          *int32 `protobuf:"varint,9,opt,name=srid,proto3,oneof" json:"srid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OptimizeRouteRequest) Reset() {
+	*x = OptimizeRouteRequest{}
+	mi := &file_route_optimization_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptimizeRouteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptimizeRouteRequest) ProtoMessage() {}
+
+func (x *OptimizeRouteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_route_optimization_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptimizeRouteRequest.ProtoReflect.Descriptor instead.
+func (*OptimizeRouteRequest) Descriptor() ([]byte, []int) {
+	return file_route_optimization_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *OptimizeRouteRequest) GetStateRadius() float64 {
+	if x != nil && x.StateRadius != nil {
+		return *x.StateRadius
+	}
+	return 0
+}
+
+func (x *OptimizeRouteRequest) GetGps() []*GeoPoint {
+	if x != nil {
+		return x.Gps
+	}
+	return nil
+}
+
+func (x *OptimizeRouteRequest) GetClosed() bool {
+	if x != nil {
+		return x.Closed
+	}
+	return false
+}
+
+func (x *OptimizeRouteRequest) GetFixedStart() bool {
+	if x != nil {
+		return x.FixedStart
+	}
+	return false
+}
+
+func (x *OptimizeRouteRequest) GetFixedEnd() bool {
+	if x != nil {
+		return x.FixedEnd
+	}
+	return false
+}
+
+func (x *OptimizeRouteRequest) GetExcludedEdges() []int64 {
+	if x != nil {
+		return x.ExcludedEdges
+	}
+	return nil
+}
+
+func (x *OptimizeRouteRequest) GetAvoidPolygons() []*Polygon {
+	if x != nil {
+		return x.AvoidPolygons
+	}
+	return nil
+}
+
+func (x *OptimizeRouteRequest) GetProfile() string {
+	if x != nil && x.Profile != nil {
+		return *x.Profile
+	}
+	return ""
+}
+
+func (x *OptimizeRouteRequest) GetSrid() int32 {
+	if x != nil && x.Srid != nil {
+		return *x.Srid
+	}
+	return 0
+}
+
+// Part of the route between two consecutive waypoints
+type RouteLeg struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Index of the source waypoint in request
+	// Example: 0
+	From int32 `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	// Index of the target waypoint in request
+	// Example: 2
+	To int32 `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	// Travel cost of the leg for the request's weight profile
+	// Example: 250.5
+	Cost float64 `protobuf:"fixed64,3,opt,name=cost,proto3" json:"cost,omitempty"`
+	// Length of the leg (meters for WGS84 graphs)
+	// Example: 250.5
+	Length float64 `protobuf:"fixed64,4,opt,name=length,proto3" json:"length,omitempty"`
+	// List of edges in the leg
+	Edges         []*EdgeInfo `protobuf:"bytes,5,rep,name=edges,proto3" json:"edges,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RouteLeg) Reset() {
+	*x = RouteLeg{}
+	mi := &file_route_optimization_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RouteLeg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RouteLeg) ProtoMessage() {}
+
+func (x *RouteLeg) ProtoReflect() protoreflect.Message {
+	mi := &file_route_optimization_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RouteLeg.ProtoReflect.Descriptor instead.
+func (*RouteLeg) Descriptor() ([]byte, []int) {
+	return file_route_optimization_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RouteLeg) GetFrom() int32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *RouteLeg) GetTo() int32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *RouteLeg) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *RouteLeg) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *RouteLeg) GetEdges() []*EdgeInfo {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+// Server's response for waypoints order optimization request
+type OptimizeRouteResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Indices of waypoints (as in request) in visiting order. For closed route the first waypoint is not repeated at the end
+	Order []int32 `protobuf:"varint,1,rep,packed,name=order,proto3" json:"order,omitempty"`
+	// Total travel cost of the route for the request's weight profile
+	// Example: 1250.5
+	Cost float64 `protobuf:"fixed64,2,opt,name=cost,proto3" json:"cost,omitempty"`
+	// Total length of the route (meters for WGS84 graphs)
+	// Example: 1250.5
+	Length float64 `protobuf:"fixed64,3,opt,name=length,proto3" json:"length,omitempty"`
+	// Stitched geometry of the whole route
+	Geom []*GeoPoint `protobuf:"bytes,4,rep,name=geom,proto3" json:"geom,omitempty"`
+	// Routes between consecutive waypoints
+	Legs []*RouteLeg `protobuf:"bytes,5,rep,name=legs,proto3" json:"legs,omitempty"`
+	// Name of weight profile used for the request
+	// Example: default
+	Profile string `protobuf:"bytes,6,opt,name=profile,proto3" json:"profile,omitempty"`
+	// List of warnings
+	Warnings      []string `protobuf:"bytes,7,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OptimizeRouteResponse) Reset() {
+	*x = OptimizeRouteResponse{}
+	mi := &file_route_optimization_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OptimizeRouteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptimizeRouteResponse) ProtoMessage() {}
+
+func (x *OptimizeRouteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_route_optimization_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptimizeRouteResponse.ProtoReflect.Descriptor instead.
+func (*OptimizeRouteResponse) Descriptor() ([]byte, []int) {
+	return file_route_optimization_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OptimizeRouteResponse) GetOrder() []int32 {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+func (x *OptimizeRouteResponse) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *OptimizeRouteResponse) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *OptimizeRouteResponse) GetGeom() []*GeoPoint {
+	if x != nil {
+		return x.Geom
+	}
+	return nil
+}
+
+func (x *OptimizeRouteResponse) GetLegs() []*RouteLeg {
+	if x != nil {
+		return x.Legs
+	}
+	return nil
+}
+
+func (x *OptimizeRouteResponse) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *OptimizeRouteResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+var File_route_optimization_proto protoreflect.FileDescriptor
+
+const file_route_optimization_proto_rawDesc = "" +
+	"\n" +
+	"\x18route_optimization.proto\x12\ahorizon\x1a\vpoint.proto\x1a\x13shortest_path.proto\"\xf7\x02\n" +
+	"\x14OptimizeRouteRequest\x12&\n" +
+	"\fstate_radius\x18\x01 \x01(\x01H\x00R\vstateRadius\x88\x01\x01\x12#\n" +
+	"\x03gps\x18\x02 \x03(\v2\x11.horizon.GeoPointR\x03gps\x12\x16\n" +
+	"\x06closed\x18\x03 \x01(\bR\x06closed\x12\x1f\n" +
+	"\vfixed_start\x18\x04 \x01(\bR\n" +
+	"fixedStart\x12\x1b\n" +
+	"\tfixed_end\x18\x05 \x01(\bR\bfixedEnd\x12%\n" +
+	"\x0eexcluded_edges\x18\x06 \x03(\x03R\rexcludedEdges\x127\n" +
+	"\x0eavoid_polygons\x18\a \x03(\v2\x10.horizon.PolygonR\ravoidPolygons\x12\x1d\n" +
+	"\aprofile\x18\b \x01(\tH\x01R\aprofile\x88\x01\x01\x12\x17\n" +
+	"\x04srid\x18\t \x01(\x05H\x02R\x04srid\x88\x01\x01B\x0f\n" +
+	"\r_state_radiusB\n" +
+	"\n" +
+	"\b_profileB\a\n" +
+	"\x05_srid\"\x83\x01\n" +
+	"\bRouteLeg\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x05R\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\x05R\x02to\x12\x12\n" +
+	"\x04cost\x18\x03 \x01(\x01R\x04cost\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x01R\x06length\x12'\n" +
+	"\x05edges\x18\x05 \x03(\v2\x11.horizon.EdgeInfoR\x05edges\"\xdd\x01\n" +
+	"\x15OptimizeRouteResponse\x12\x14\n" +
+	"\x05order\x18\x01 \x03(\x05R\x05order\x12\x12\n" +
+	"\x04cost\x18\x02 \x01(\x01R\x04cost\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x01R\x06length\x12%\n" +
+	"\x04geom\x18\x04 \x03(\v2\x11.horizon.GeoPointR\x04geom\x12%\n" +
+	"\x04legs\x18\x05 \x03(\v2\x11.horizon.RouteLegR\x04legs\x12\x18\n" +
+	"\aprofile\x18\x06 \x01(\tR\aprofile\x12\x1a\n" +
+	"\bwarnings\x18\a \x03(\tR\bwarningsB\x0eZ\f./;protos_pbb\x06proto3"
+
+var (
+	file_route_optimization_proto_rawDescOnce sync.Once
+	file_route_optimization_proto_rawDescData []byte
+)
+
+func file_route_optimization_proto_rawDescGZIP() []byte {
+	file_route_optimization_proto_rawDescOnce.Do(func() {
+		file_route_optimization_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_route_optimization_proto_rawDesc), len(file_route_optimization_proto_rawDesc)))
+	})
+	return file_route_optimization_proto_rawDescData
+}
+
+var file_route_optimization_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_route_optimization_proto_goTypes = []any{
+	(*OptimizeRouteRequest)(nil),  // 0: horizon.OptimizeRouteRequest
+	(*RouteLeg)(nil),              // 1: horizon.RouteLeg
+	(*OptimizeRouteResponse)(nil), // 2: horizon.OptimizeRouteResponse
+	(*GeoPoint)(nil),              // 3: horizon.GeoPoint
+	(*Polygon)(nil),               // 4: horizon.Polygon
+	(*EdgeInfo)(nil),              // 5: horizon.EdgeInfo
+}
+var file_route_optimization_proto_depIdxs = []int32{
+	3, // 0: horizon.OptimizeRouteRequest.gps:type_name -> horizon.GeoPoint
+	4, // 1: horizon.OptimizeRouteRequest.avoid_polygons:type_name -> horizon.Polygon
+	5, // 2: horizon.RouteLeg.edges:type_name -> horizon.EdgeInfo
+	3, // 3: horizon.OptimizeRouteResponse.geom:type_name -> horizon.GeoPoint
+	1, // 4: horizon.OptimizeRouteResponse.legs:type_name -> horizon.RouteLeg
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_route_optimization_proto_init() }
+func file_route_optimization_proto_init() {
+	if File_route_optimization_proto != nil {
+		return
+	}
+	file_point_proto_init()
+	file_shortest_path_proto_init()
+	file_route_optimization_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_route_optimization_proto_rawDesc), len(file_route_optimization_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_route_optimization_proto_goTypes,
+		DependencyIndexes: file_route_optimization_proto_depIdxs,
+		MessageInfos:      file_route_optimization_proto_msgTypes,
+	}.Build()
+	File_route_optimization_proto = out.File
+	file_route_optimization_proto_goTypes = nil
+	file_route_optimization_proto_depIdxs = nil
+}
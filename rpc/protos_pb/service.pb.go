@@ -24,32 +24,63 @@ var File_service_proto protoreflect.FileDescriptor
 
 const file_service_proto_rawDesc = "" +
 	"\n" +
-	"\rservice.proto\x12\ahorizon\x1a\x0fmap_match.proto\x1a\x13shortest_path.proto\x1a\x10isochrones.proto2\xcf\x01\n" +
+	"\rservice.proto\x12\ahorizon\x1a\x0fmap_match.proto\x1a\x13shortest_path.proto\x1a\x10isochrones.proto\x1a\x18route_optimization.proto\x1a\rnearest.proto\x1a\x13service_areas.proto\x1a\x10facilities.proto2\xa0\x05\n" +
 	"\aService\x12D\n" +
 	"\vRunMapMatch\x12\x18.horizon.MapMatchRequest\x1a\x19.horizon.MapMatchResponse\"\x00\x122\n" +
 	"\x05GetSP\x12\x12.horizon.SPRequest\x1a\x13.horizon.SPResponse\"\x00\x12J\n" +
-	"\rGetIsochrones\x12\x1a.horizon.IsochronesRequest\x1a\x1b.horizon.IsochronesResponse\"\x00B\x0eZ\f./;protos_pbb\x06proto3"
+	"\rGetIsochrones\x12\x1a.horizon.IsochronesRequest\x1a\x1b.horizon.IsochronesResponse\"\x00\x12P\n" +
+	"\x0fGetServiceAreas\x12\x1c.horizon.ServiceAreasRequest\x1a\x1d.horizon.ServiceAreasResponse\"\x00\x12P\n" +
+	"\rOptimizeRoute\x12\x1d.horizon.OptimizeRouteRequest\x1a\x1e.horizon.OptimizeRouteResponse\"\x00\x12A\n" +
+	"\n" +
+	"GetNearest\x12\x17.horizon.NearestRequest\x1a\x18.horizon.NearestResponse\"\x00\x125\n" +
+	"\x04Snap\x12\x14.horizon.SnapRequest\x1a\x15.horizon.SnapResponse\"\x00\x12P\n" +
+	"\rSetFacilities\x12\x1d.horizon.SetFacilitiesRequest\x1a\x1e.horizon.SetFacilitiesResponse\"\x00\x12_\n" +
+	"\x14GetNearestFacilities\x12!.horizon.NearestFacilitiesRequest\x1a\".horizon.NearestFacilitiesResponse\"\x00B\x0eZ\f./;protos_pbb\x06proto3"
 
 var file_service_proto_goTypes = []any{
-	(*MapMatchRequest)(nil),    // 0: horizon.MapMatchRequest
-	(*SPRequest)(nil),          // 1: horizon.SPRequest
-	(*IsochronesRequest)(nil),  // 2: horizon.IsochronesRequest
-	(*MapMatchResponse)(nil),   // 3: horizon.MapMatchResponse
-	(*SPResponse)(nil),         // 4: horizon.SPResponse
-	(*IsochronesResponse)(nil), // 5: horizon.IsochronesResponse
+	(*MapMatchRequest)(nil),           // 0: horizon.MapMatchRequest
+	(*SPRequest)(nil),                 // 1: horizon.SPRequest
+	(*IsochronesRequest)(nil),         // 2: horizon.IsochronesRequest
+	(*ServiceAreasRequest)(nil),       // 3: horizon.ServiceAreasRequest
+	(*OptimizeRouteRequest)(nil),      // 4: horizon.OptimizeRouteRequest
+	(*NearestRequest)(nil),            // 5: horizon.NearestRequest
+	(*SnapRequest)(nil),               // 6: horizon.SnapRequest
+	(*SetFacilitiesRequest)(nil),      // 7: horizon.SetFacilitiesRequest
+	(*NearestFacilitiesRequest)(nil),  // 8: horizon.NearestFacilitiesRequest
+	(*MapMatchResponse)(nil),          // 9: horizon.MapMatchResponse
+	(*SPResponse)(nil),                // 10: horizon.SPResponse
+	(*IsochronesResponse)(nil),        // 11: horizon.IsochronesResponse
+	(*ServiceAreasResponse)(nil),      // 12: horizon.ServiceAreasResponse
+	(*OptimizeRouteResponse)(nil),     // 13: horizon.OptimizeRouteResponse
+	(*NearestResponse)(nil),           // 14: horizon.NearestResponse
+	(*SnapResponse)(nil),              // 15: horizon.SnapResponse
+	(*SetFacilitiesResponse)(nil),     // 16: horizon.SetFacilitiesResponse
+	(*NearestFacilitiesResponse)(nil), // 17: horizon.NearestFacilitiesResponse
 }
 var file_service_proto_depIdxs = []int32{
-	0, // 0: horizon.Service.RunMapMatch:input_type -> horizon.MapMatchRequest
-	1, // 1: horizon.Service.GetSP:input_type -> horizon.SPRequest
-	2, // 2: horizon.Service.GetIsochrones:input_type -> horizon.IsochronesRequest
-	3, // 3: horizon.Service.RunMapMatch:output_type -> horizon.MapMatchResponse
-	4, // 4: horizon.Service.GetSP:output_type -> horizon.SPResponse
-	5, // 5: horizon.Service.GetIsochrones:output_type -> horizon.IsochronesResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: horizon.Service.RunMapMatch:input_type -> horizon.MapMatchRequest
+	1,  // 1: horizon.Service.GetSP:input_type -> horizon.SPRequest
+	2,  // 2: horizon.Service.GetIsochrones:input_type -> horizon.IsochronesRequest
+	3,  // 3: horizon.Service.GetServiceAreas:input_type -> horizon.ServiceAreasRequest
+	4,  // 4: horizon.Service.OptimizeRoute:input_type -> horizon.OptimizeRouteRequest
+	5,  // 5: horizon.Service.GetNearest:input_type -> horizon.NearestRequest
+	6,  // 6: horizon.Service.Snap:input_type -> horizon.SnapRequest
+	7,  // 7: horizon.Service.SetFacilities:input_type -> horizon.SetFacilitiesRequest
+	8,  // 8: horizon.Service.GetNearestFacilities:input_type -> horizon.NearestFacilitiesRequest
+	9,  // 9: horizon.Service.RunMapMatch:output_type -> horizon.MapMatchResponse
+	10, // 10: horizon.Service.GetSP:output_type -> horizon.SPResponse
+	11, // 11: horizon.Service.GetIsochrones:output_type -> horizon.IsochronesResponse
+	12, // 12: horizon.Service.GetServiceAreas:output_type -> horizon.ServiceAreasResponse
+	13, // 13: horizon.Service.OptimizeRoute:output_type -> horizon.OptimizeRouteResponse
+	14, // 14: horizon.Service.GetNearest:output_type -> horizon.NearestResponse
+	15, // 15: horizon.Service.Snap:output_type -> horizon.SnapResponse
+	16, // 16: horizon.Service.SetFacilities:output_type -> horizon.SetFacilitiesResponse
+	17, // 17: horizon.Service.GetNearestFacilities:output_type -> horizon.NearestFacilitiesResponse
+	9,  // [9:18] is the sub-list for method output_type
+	0,  // [0:9] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_service_proto_init() }
@@ -60,6 +91,10 @@ func file_service_proto_init() {
 	file_map_match_proto_init()
 	file_shortest_path_proto_init()
 	file_isochrones_proto_init()
+	file_route_optimization_proto_init()
+	file_nearest_proto_init()
+	file_service_areas_proto_init()
+	file_facilities_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
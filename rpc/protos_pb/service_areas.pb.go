@@ -0,0 +1,489 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.32.1
+// source: service_areas.proto
+
+package protos_pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// User's request for service areas of several sources (network Voronoi diagram)
+type ServiceAreasRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Max cost restriction. Should be in range [0,+Inf]. Minumim is 0.
+	// Example: 2100.0
+	MaxCost *float64 `protobuf:"fixed64,1,opt,name=max_cost,json=maxCost,proto3,oneof" json:"max_cost,omitempty"`
+	// Max radius of search for nearest vertex (in meters).
+	// Use -1 for no limit, 0 or omit for default (100m), or positive value.
+	MaxNearestRadius *float64 `protobuf:"fixed64,2,opt,name=max_nearest_radius,json=maxNearestRadius,proto3,oneof" json:"max_nearest_radius,omitempty"`
+	// Sources (e.g. depots)
+	Sources []*GeoPoint `protobuf:"bytes,3,rep,name=sources,proto3" json:"sources,omitempty"`
+	// Identifiers of edges which must be neither candidates nor traversed (e.g. road closures)
+	ExcludedEdges []int64 `protobuf:"varint,4,rep,packed,name=excluded_edges,json=excludedEdges,proto3" json:"excluded_edges,omitempty"`
+	// Areas to avoid. Every edge having common points with any of polygons is excluded
+	AvoidPolygons []*Polygon `protobuf:"bytes,5,rep,name=avoid_polygons,json=avoidPolygons,proto3" json:"avoid_polygons,omitempty"`
+	// Name of weight profile used for routing. Empty or omitted stands for 'default' profile
+	// Example: travel_time
+	Profile *string `protobuf:"bytes,6,opt,name=profile,proto3,oneof" json:"profile,omitempty"`
+	// Build polygons covering every service area
+	// Example: true
+	Polygons bool `protobuf:"varint,7,opt,name=polygons,proto3" json:"polygons,omitempty"`
+	// Max circumradius of triangles in alpha shape (in meters). Bigger value gives smoother polygons up to convex hull. Use 0 or omit for automatic value
+	// Example: 150.0
+	Smoothness *float64 `protobuf:"fixed64,8,opt,name=smoothness,proto3,oneof" json:"smoothness,omitempty"`
+	// Search on reversed graph: costs are the ones of reaching the sources from vertices
+	// Example: false
+	Reverse bool `protobuf:"varint,9,opt,name=reverse,proto3" json:"reverse,omitempty"`
+	// SRID of input coordinates (points and avoid_polygons) and returned geometries (4326 if omitted). Projected CRS are supported: 3857, UTM zones 32601-32660 and 32701-32760 and registered custom ones. Use 0 for graphs with raw Cartesian coordinates
+	// Example: 4326
+	Srid          *int32 `protobuf:"varint,10,opt,name=srid,proto3,oneof" json:"srid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceAreasRequest) Reset() {
+	*x = ServiceAreasRequest{}
+	mi := &file_service_areas_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceAreasRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceAreasRequest) ProtoMessage() {}
+
+func (x *ServiceAreasRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_areas_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceAreasRequest.ProtoReflect.Descriptor instead.
+func (*ServiceAreasRequest) Descriptor() ([]byte, []int) {
+	return file_service_areas_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ServiceAreasRequest) GetMaxCost() float64 {
+	if x != nil && x.MaxCost != nil {
+		return *x.MaxCost
+	}
+	return 0
+}
+
+func (x *ServiceAreasRequest) GetMaxNearestRadius() float64 {
+	if x != nil && x.MaxNearestRadius != nil {
+		return *x.MaxNearestRadius
+	}
+	return 0
+}
+
+func (x *ServiceAreasRequest) GetSources() []*GeoPoint {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *ServiceAreasRequest) GetExcludedEdges() []int64 {
+	if x != nil {
+		return x.ExcludedEdges
+	}
+	return nil
+}
+
+func (x *ServiceAreasRequest) GetAvoidPolygons() []*Polygon {
+	if x != nil {
+		return x.AvoidPolygons
+	}
+	return nil
+}
+
+func (x *ServiceAreasRequest) GetProfile() string {
+	if x != nil && x.Profile != nil {
+		return *x.Profile
+	}
+	return ""
+}
+
+func (x *ServiceAreasRequest) GetPolygons() bool {
+	if x != nil {
+		return x.Polygons
+	}
+	return false
+}
+
+func (x *ServiceAreasRequest) GetSmoothness() float64 {
+	if x != nil && x.Smoothness != nil {
+		return *x.Smoothness
+	}
+	return 0
+}
+
+func (x *ServiceAreasRequest) GetReverse() bool {
+	if x != nil {
+		return x.Reverse
+	}
+	return false
+}
+
+func (x *ServiceAreasRequest) GetSrid() int32 {
+	if x != nil && x.Srid != nil {
+		return *x.Srid
+	}
+	return 0
+}
+
+// Server's response for service areas request
+type ServiceAreasResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Service area for each source. Index corresponds to index in incoming request
+	Data []*ServiceArea `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+	// List of warnings
+	Warnings []string `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// Name of weight profile used for the request. Costs are evaluated for this profile
+	// Example: default
+	Profile       string `protobuf:"bytes,3,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceAreasResponse) Reset() {
+	*x = ServiceAreasResponse{}
+	mi := &file_service_areas_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceAreasResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceAreasResponse) ProtoMessage() {}
+
+func (x *ServiceAreasResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_areas_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceAreasResponse.ProtoReflect.Descriptor instead.
+func (*ServiceAreasResponse) Descriptor() ([]byte, []int) {
+	return file_service_areas_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ServiceAreasResponse) GetData() []*ServiceArea {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ServiceAreasResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *ServiceAreasResponse) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+// Part of the network served by single source
+type ServiceArea struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Index of the source in the request
+	// Example: 0
+	Source int32 `protobuf:"varint,1,opt,name=source,proto3" json:"source,omitempty"`
+	// Vertex which the source has been snapped to
+	// Example: 44014
+	SourceVertexId int64 `protobuf:"varint,2,opt,name=source_vertex_id,json=sourceVertexId,proto3" json:"source_vertex_id,omitempty"`
+	// Served vertices with costs of reaching them
+	Isochrones []*Isochrone `protobuf:"bytes,3,rep,name=isochrones,proto3" json:"isochrones,omitempty"`
+	// Polygons covering the service area (bigger ones go first). Only if 'polygons' is requested
+	Polygons []*IsochronePolygon `protobuf:"bytes,4,rep,name=polygons,proto3" json:"polygons,omitempty"`
+	// Coverage statistics
+	Stats         *ServiceAreaStats `protobuf:"bytes,5,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceArea) Reset() {
+	*x = ServiceArea{}
+	mi := &file_service_areas_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceArea) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceArea) ProtoMessage() {}
+
+func (x *ServiceArea) ProtoReflect() protoreflect.Message {
+	mi := &file_service_areas_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceArea.ProtoReflect.Descriptor instead.
+func (*ServiceArea) Descriptor() ([]byte, []int) {
+	return file_service_areas_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ServiceArea) GetSource() int32 {
+	if x != nil {
+		return x.Source
+	}
+	return 0
+}
+
+func (x *ServiceArea) GetSourceVertexId() int64 {
+	if x != nil {
+		return x.SourceVertexId
+	}
+	return 0
+}
+
+func (x *ServiceArea) GetIsochrones() []*Isochrone {
+	if x != nil {
+		return x.Isochrones
+	}
+	return nil
+}
+
+func (x *ServiceArea) GetPolygons() []*IsochronePolygon {
+	if x != nil {
+		return x.Polygons
+	}
+	return nil
+}
+
+func (x *ServiceArea) GetStats() *ServiceAreaStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+// Coverage statistics of the service area
+type ServiceAreaStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of served vertices
+	// Example: 120
+	VerticesNum int32 `protobuf:"varint,1,opt,name=vertices_num,json=verticesNum,proto3" json:"vertices_num,omitempty"`
+	// Cost of reaching the farthest served vertex
+	// Example: 2080.5
+	MaxCost float64 `protobuf:"fixed64,2,opt,name=max_cost,json=maxCost,proto3" json:"max_cost,omitempty"`
+	// Average cost of reaching served vertices
+	// Example: 1130.2
+	AvgCost float64 `protobuf:"fixed64,3,opt,name=avg_cost,json=avgCost,proto3" json:"avg_cost,omitempty"`
+	// Area of polygons in square meters (zero if polygons are not requested)
+	// Example: 1250000.0
+	Area          float64 `protobuf:"fixed64,4,opt,name=area,proto3" json:"area,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceAreaStats) Reset() {
+	*x = ServiceAreaStats{}
+	mi := &file_service_areas_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceAreaStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceAreaStats) ProtoMessage() {}
+
+func (x *ServiceAreaStats) ProtoReflect() protoreflect.Message {
+	mi := &file_service_areas_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceAreaStats.ProtoReflect.Descriptor instead.
+func (*ServiceAreaStats) Descriptor() ([]byte, []int) {
+	return file_service_areas_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ServiceAreaStats) GetVerticesNum() int32 {
+	if x != nil {
+		return x.VerticesNum
+	}
+	return 0
+}
+
+func (x *ServiceAreaStats) GetMaxCost() float64 {
+	if x != nil {
+		return x.MaxCost
+	}
+	return 0
+}
+
+func (x *ServiceAreaStats) GetAvgCost() float64 {
+	if x != nil {
+		return x.AvgCost
+	}
+	return 0
+}
+
+func (x *ServiceAreaStats) GetArea() float64 {
+	if x != nil {
+		return x.Area
+	}
+	return 0
+}
+
+var File_service_areas_proto protoreflect.FileDescriptor
+
+const file_service_areas_proto_rawDesc = "" +
+	"\n" +
+	"\x13service_areas.proto\x12\ahorizon\x1a\vpoint.proto\x1a\x10isochrones.proto\"\xd0\x03\n" +
+	"\x13ServiceAreasRequest\x12\x1e\n" +
+	"\bmax_cost\x18\x01 \x01(\x01H\x00R\amaxCost\x88\x01\x01\x121\n" +
+	"\x12max_nearest_radius\x18\x02 \x01(\x01H\x01R\x10maxNearestRadius\x88\x01\x01\x12+\n" +
+	"\asources\x18\x03 \x03(\v2\x11.horizon.GeoPointR\asources\x12%\n" +
+	"\x0eexcluded_edges\x18\x04 \x03(\x03R\rexcludedEdges\x127\n" +
+	"\x0eavoid_polygons\x18\x05 \x03(\v2\x10.horizon.PolygonR\ravoidPolygons\x12\x1d\n" +
+	"\aprofile\x18\x06 \x01(\tH\x02R\aprofile\x88\x01\x01\x12\x1a\n" +
+	"\bpolygons\x18\a \x01(\bR\bpolygons\x12#\n" +
+	"\n" +
+	"smoothness\x18\b \x01(\x01H\x03R\n" +
+	"smoothness\x88\x01\x01\x12\x18\n" +
+	"\areverse\x18\t \x01(\bR\areverse\x12\x17\n" +
+	"\x04srid\x18\n" +
+	" \x01(\x05H\x04R\x04srid\x88\x01\x01B\v\n" +
+	"\t_max_costB\x15\n" +
+	"\x13_max_nearest_radiusB\n" +
+	"\n" +
+	"\b_profileB\r\n" +
+	"\v_smoothnessB\a\n" +
+	"\x05_srid\"v\n" +
+	"\x14ServiceAreasResponse\x12(\n" +
+	"\x04data\x18\x01 \x03(\v2\x14.horizon.ServiceAreaR\x04data\x12\x1a\n" +
+	"\bwarnings\x18\x02 \x03(\tR\bwarnings\x12\x18\n" +
+	"\aprofile\x18\x03 \x01(\tR\aprofile\"\xeb\x01\n" +
+	"\vServiceArea\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\x05R\x06source\x12(\n" +
+	"\x10source_vertex_id\x18\x02 \x01(\x03R\x0esourceVertexId\x122\n" +
+	"\n" +
+	"isochrones\x18\x03 \x03(\v2\x12.horizon.IsochroneR\n" +
+	"isochrones\x125\n" +
+	"\bpolygons\x18\x04 \x03(\v2\x19.horizon.IsochronePolygonR\bpolygons\x12/\n" +
+	"\x05stats\x18\x05 \x01(\v2\x19.horizon.ServiceAreaStatsR\x05stats\"\x7f\n" +
+	"\x10ServiceAreaStats\x12!\n" +
+	"\fvertices_num\x18\x01 \x01(\x05R\vverticesNum\x12\x19\n" +
+	"\bmax_cost\x18\x02 \x01(\x01R\amaxCost\x12\x19\n" +
+	"\bavg_cost\x18\x03 \x01(\x01R\aavgCost\x12\x12\n" +
+	"\x04area\x18\x04 \x01(\x01R\x04areaB\x0eZ\f./;protos_pbb\x06proto3"
+
+var (
+	file_service_areas_proto_rawDescOnce sync.Once
+	file_service_areas_proto_rawDescData []byte
+)
+
+func file_service_areas_proto_rawDescGZIP() []byte {
+	file_service_areas_proto_rawDescOnce.Do(func() {
+		file_service_areas_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_service_areas_proto_rawDesc), len(file_service_areas_proto_rawDesc)))
+	})
+	return file_service_areas_proto_rawDescData
+}
+
+var file_service_areas_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_service_areas_proto_goTypes = []any{
+	(*ServiceAreasRequest)(nil),  // 0: horizon.ServiceAreasRequest
+	(*ServiceAreasResponse)(nil), // 1: horizon.ServiceAreasResponse
+	(*ServiceArea)(nil),          // 2: horizon.ServiceArea
+	(*ServiceAreaStats)(nil),     // 3: horizon.ServiceAreaStats
+	(*GeoPoint)(nil),             // 4: horizon.GeoPoint
+	(*Polygon)(nil),              // 5: horizon.Polygon
+	(*Isochrone)(nil),            // 6: horizon.Isochrone
+	(*IsochronePolygon)(nil),     // 7: horizon.IsochronePolygon
+}
+var file_service_areas_proto_depIdxs = []int32{
+	4, // 0: horizon.ServiceAreasRequest.sources:type_name -> horizon.GeoPoint
+	5, // 1: horizon.ServiceAreasRequest.avoid_polygons:type_name -> horizon.Polygon
+	2, // 2: horizon.ServiceAreasResponse.data:type_name -> horizon.ServiceArea
+	6, // 3: horizon.ServiceArea.isochrones:type_name -> horizon.Isochrone
+	7, // 4: horizon.ServiceArea.polygons:type_name -> horizon.IsochronePolygon
+	3, // 5: horizon.ServiceArea.stats:type_name -> horizon.ServiceAreaStats
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_service_areas_proto_init() }
+func file_service_areas_proto_init() {
+	if File_service_areas_proto != nil {
+		return
+	}
+	file_point_proto_init()
+	file_isochrones_proto_init()
+	file_service_areas_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_service_areas_proto_rawDesc), len(file_service_areas_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_service_areas_proto_goTypes,
+		DependencyIndexes: file_service_areas_proto_depIdxs,
+		MessageInfos:      file_service_areas_proto_msgTypes,
+	}.Build()
+	File_service_areas_proto = out.File
+	file_service_areas_proto_goTypes = nil
+	file_service_areas_proto_depIdxs = nil
+}
@@ -19,9 +19,15 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Service_RunMapMatch_FullMethodName   = "/horizon.Service/RunMapMatch"
-	Service_GetSP_FullMethodName         = "/horizon.Service/GetSP"
-	Service_GetIsochrones_FullMethodName = "/horizon.Service/GetIsochrones"
+	Service_RunMapMatch_FullMethodName          = "/horizon.Service/RunMapMatch"
+	Service_GetSP_FullMethodName                = "/horizon.Service/GetSP"
+	Service_GetIsochrones_FullMethodName        = "/horizon.Service/GetIsochrones"
+	Service_GetServiceAreas_FullMethodName      = "/horizon.Service/GetServiceAreas"
+	Service_OptimizeRoute_FullMethodName        = "/horizon.Service/OptimizeRoute"
+	Service_GetNearest_FullMethodName           = "/horizon.Service/GetNearest"
+	Service_Snap_FullMethodName                 = "/horizon.Service/Snap"
+	Service_SetFacilities_FullMethodName        = "/horizon.Service/SetFacilities"
+	Service_GetNearestFacilities_FullMethodName = "/horizon.Service/GetNearestFacilities"
 )
 
 // ServiceClient is the client API for Service service.
@@ -31,6 +37,12 @@ type ServiceClient interface {
 	RunMapMatch(ctx context.Context, in *MapMatchRequest, opts ...grpc.CallOption) (*MapMatchResponse, error)
 	GetSP(ctx context.Context, in *SPRequest, opts ...grpc.CallOption) (*SPResponse, error)
 	GetIsochrones(ctx context.Context, in *IsochronesRequest, opts ...grpc.CallOption) (*IsochronesResponse, error)
+	GetServiceAreas(ctx context.Context, in *ServiceAreasRequest, opts ...grpc.CallOption) (*ServiceAreasResponse, error)
+	OptimizeRoute(ctx context.Context, in *OptimizeRouteRequest, opts ...grpc.CallOption) (*OptimizeRouteResponse, error)
+	GetNearest(ctx context.Context, in *NearestRequest, opts ...grpc.CallOption) (*NearestResponse, error)
+	Snap(ctx context.Context, in *SnapRequest, opts ...grpc.CallOption) (*SnapResponse, error)
+	SetFacilities(ctx context.Context, in *SetFacilitiesRequest, opts ...grpc.CallOption) (*SetFacilitiesResponse, error)
+	GetNearestFacilities(ctx context.Context, in *NearestFacilitiesRequest, opts ...grpc.CallOption) (*NearestFacilitiesResponse, error)
 }
 
 type serviceClient struct {
@@ -71,6 +83,66 @@ func (c *serviceClient) GetIsochrones(ctx context.Context, in *IsochronesRequest
 	return out, nil
 }
 
+func (c *serviceClient) GetServiceAreas(ctx context.Context, in *ServiceAreasRequest, opts ...grpc.CallOption) (*ServiceAreasResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServiceAreasResponse)
+	err := c.cc.Invoke(ctx, Service_GetServiceAreas_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) OptimizeRoute(ctx context.Context, in *OptimizeRouteRequest, opts ...grpc.CallOption) (*OptimizeRouteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OptimizeRouteResponse)
+	err := c.cc.Invoke(ctx, Service_OptimizeRoute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetNearest(ctx context.Context, in *NearestRequest, opts ...grpc.CallOption) (*NearestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NearestResponse)
+	err := c.cc.Invoke(ctx, Service_GetNearest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) Snap(ctx context.Context, in *SnapRequest, opts ...grpc.CallOption) (*SnapResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SnapResponse)
+	err := c.cc.Invoke(ctx, Service_Snap_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) SetFacilities(ctx context.Context, in *SetFacilitiesRequest, opts ...grpc.CallOption) (*SetFacilitiesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetFacilitiesResponse)
+	err := c.cc.Invoke(ctx, Service_SetFacilities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) GetNearestFacilities(ctx context.Context, in *NearestFacilitiesRequest, opts ...grpc.CallOption) (*NearestFacilitiesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NearestFacilitiesResponse)
+	err := c.cc.Invoke(ctx, Service_GetNearestFacilities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ServiceServer is the server API for Service service.
 // All implementations must embed UnimplementedServiceServer
 // for forward compatibility.
@@ -78,6 +150,12 @@ type ServiceServer interface {
 	RunMapMatch(context.Context, *MapMatchRequest) (*MapMatchResponse, error)
 	GetSP(context.Context, *SPRequest) (*SPResponse, error)
 	GetIsochrones(context.Context, *IsochronesRequest) (*IsochronesResponse, error)
+	GetServiceAreas(context.Context, *ServiceAreasRequest) (*ServiceAreasResponse, error)
+	OptimizeRoute(context.Context, *OptimizeRouteRequest) (*OptimizeRouteResponse, error)
+	GetNearest(context.Context, *NearestRequest) (*NearestResponse, error)
+	Snap(context.Context, *SnapRequest) (*SnapResponse, error)
+	SetFacilities(context.Context, *SetFacilitiesRequest) (*SetFacilitiesResponse, error)
+	GetNearestFacilities(context.Context, *NearestFacilitiesRequest) (*NearestFacilitiesResponse, error)
 	mustEmbedUnimplementedServiceServer()
 }
 
@@ -97,6 +175,24 @@ func (UnimplementedServiceServer) GetSP(context.Context, *SPRequest) (*SPRespons
 func (UnimplementedServiceServer) GetIsochrones(context.Context, *IsochronesRequest) (*IsochronesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetIsochrones not implemented")
 }
+func (UnimplementedServiceServer) GetServiceAreas(context.Context, *ServiceAreasRequest) (*ServiceAreasResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServiceAreas not implemented")
+}
+func (UnimplementedServiceServer) OptimizeRoute(context.Context, *OptimizeRouteRequest) (*OptimizeRouteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OptimizeRoute not implemented")
+}
+func (UnimplementedServiceServer) GetNearest(context.Context, *NearestRequest) (*NearestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNearest not implemented")
+}
+func (UnimplementedServiceServer) Snap(context.Context, *SnapRequest) (*SnapResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Snap not implemented")
+}
+func (UnimplementedServiceServer) SetFacilities(context.Context, *SetFacilitiesRequest) (*SetFacilitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetFacilities not implemented")
+}
+func (UnimplementedServiceServer) GetNearestFacilities(context.Context, *NearestFacilitiesRequest) (*NearestFacilitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNearestFacilities not implemented")
+}
 func (UnimplementedServiceServer) mustEmbedUnimplementedServiceServer() {}
 func (UnimplementedServiceServer) testEmbeddedByValue()                 {}
 
@@ -172,6 +268,114 @@ func _Service_GetIsochrones_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Service_GetServiceAreas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServiceAreasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetServiceAreas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetServiceAreas_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetServiceAreas(ctx, req.(*ServiceAreasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_OptimizeRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OptimizeRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).OptimizeRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_OptimizeRoute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).OptimizeRoute(ctx, req.(*OptimizeRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetNearest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NearestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetNearest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetNearest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetNearest(ctx, req.(*NearestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_Snap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).Snap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_Snap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).Snap(ctx, req.(*SnapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_SetFacilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetFacilitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).SetFacilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_SetFacilities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).SetFacilities(ctx, req.(*SetFacilitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_GetNearestFacilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NearestFacilitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).GetNearestFacilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Service_GetNearestFacilities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).GetNearestFacilities(ctx, req.(*NearestFacilitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Service_ServiceDesc is the grpc.ServiceDesc for Service service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,6 +395,30 @@ var Service_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetIsochrones",
 			Handler:    _Service_GetIsochrones_Handler,
 		},
+		{
+			MethodName: "GetServiceAreas",
+			Handler:    _Service_GetServiceAreas_Handler,
+		},
+		{
+			MethodName: "OptimizeRoute",
+			Handler:    _Service_OptimizeRoute_Handler,
+		},
+		{
+			MethodName: "GetNearest",
+			Handler:    _Service_GetNearest_Handler,
+		},
+		{
+			MethodName: "Snap",
+			Handler:    _Service_Snap_Handler,
+		},
+		{
+			MethodName: "SetFacilities",
+			Handler:    _Service_SetFacilities_Handler,
+		},
+		{
+			MethodName: "GetNearestFacilities",
+			Handler:    _Service_GetNearestFacilities_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "service.proto",
@@ -28,7 +28,20 @@ type SPRequest struct {
 	// Use -1 for no limit, 0 or omit for default (100m), or positive value.
 	StateRadius *float64 `protobuf:"fixed64,1,opt,name=state_radius,json=stateRadius,proto3,oneof" json:"state_radius,omitempty"`
 	// Set of GPS data
-	Gps           []*GeoPoint `protobuf:"bytes,2,rep,name=gps,proto3" json:"gps,omitempty"`
+	Gps []*GeoPoint `protobuf:"bytes,2,rep,name=gps,proto3" json:"gps,omitempty"`
+	// Identifiers of edges which must be neither candidates nor traversed (e.g. road closures)
+	ExcludedEdges []int64 `protobuf:"varint,3,rep,packed,name=excluded_edges,json=excludedEdges,proto3" json:"excluded_edges,omitempty"`
+	// Areas to avoid. Every edge having common points with any of polygons is excluded
+	AvoidPolygons []*Polygon `protobuf:"bytes,4,rep,name=avoid_polygons,json=avoidPolygons,proto3" json:"avoid_polygons,omitempty"`
+	// Name of weight profile used for routing, transitions and isochrones. Empty or omitted stands for 'default' profile
+	// Example: travel_time
+	Profile *string `protobuf:"bytes,5,opt,name=profile,proto3,oneof" json:"profile,omitempty"`
+	// Format of merged route geometry: 'geojson', 'polyline' (Google encoded polyline, precision 5), 'polyline6' (precision 6) or 'wkt'. Empty or omitted means that merged geometry is not returned
+	// Example: polyline6
+	RouteFormat *string `protobuf:"bytes,6,opt,name=route_format,json=routeFormat,proto3,oneof" json:"route_format,omitempty"`
+	// SRID of input coordinates (points and avoid_polygons) and returned geometries (4326 if omitted). Projected CRS are supported: 3857, UTM zones 32601-32660 and 32701-32760 and registered custom ones. Use 0 for graphs with raw Cartesian coordinates
+	// Example: 4326
+	Srid          *int32 `protobuf:"varint,7,opt,name=srid,proto3,oneof" json:"srid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -77,13 +90,59 @@ func (x *SPRequest) GetGps() []*GeoPoint {
 	return nil
 }
 
+func (x *SPRequest) GetExcludedEdges() []int64 {
+	if x != nil {
+		return x.ExcludedEdges
+	}
+	return nil
+}
+
+func (x *SPRequest) GetAvoidPolygons() []*Polygon {
+	if x != nil {
+		return x.AvoidPolygons
+	}
+	return nil
+}
+
+func (x *SPRequest) GetProfile() string {
+	if x != nil && x.Profile != nil {
+		return *x.Profile
+	}
+	return ""
+}
+
+func (x *SPRequest) GetRouteFormat() string {
+	if x != nil && x.RouteFormat != nil {
+		return *x.RouteFormat
+	}
+	return ""
+}
+
+func (x *SPRequest) GetSrid() int32 {
+	if x != nil && x.Srid != nil {
+		return *x.Srid
+	}
+	return 0
+}
+
 // Server's response for shortest path request
 type SPResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// List of edges in a path
 	Data []*EdgeInfo `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
 	// List of warnings
-	Warnings      []string `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Warnings []string `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// Name of weight profile used for the request
+	// Example: default
+	Profile string `protobuf:"bytes,3,opt,name=profile,proto3" json:"profile,omitempty"`
+	// Total travel cost of the path for the request's weight profile
+	// Example: 1250.5
+	Cost float64 `protobuf:"fixed64,4,opt,name=cost,proto3" json:"cost,omitempty"`
+	// Total length of the path (meters for WGS84 graphs)
+	// Example: 1250.5
+	Length float64 `protobuf:"fixed64,5,opt,name=length,proto3" json:"length,omitempty"`
+	// Merged geometry of the path (only if 'route_format' is provided)
+	Route         *RouteGeometry `protobuf:"bytes,6,opt,name=route,proto3" json:"route,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -132,14 +191,46 @@ func (x *SPResponse) GetWarnings() []string {
 	return nil
 }
 
+func (x *SPResponse) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *SPResponse) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *SPResponse) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *SPResponse) GetRoute() *RouteGeometry {
+	if x != nil {
+		return x.Route
+	}
+	return nil
+}
+
 // Edge information
 type EdgeInfo struct {
 	state  protoimpl.MessageState `protogen:"open.v1"`
 	EdgeId int64                  `protobuf:"varint,1,opt,name=edge_id,json=edgeId,proto3" json:"edge_id,omitempty"`
-	// Travel cost to the target vertex
+	// Travel cost for the request's weight profile
 	Weight float64 `protobuf:"fixed64,2,opt,name=weight,proto3" json:"weight,omitempty"`
 	// Line
-	Geom          []*GeoPoint `protobuf:"bytes,3,rep,name=geom,proto3" json:"geom,omitempty"`
+	Geom []*GeoPoint `protobuf:"bytes,3,rep,name=geom,proto3" json:"geom,omitempty"`
+	// Edge length (meters for WGS84 graphs)
+	Length float64 `protobuf:"fixed64,4,opt,name=length,proto3" json:"length,omitempty"`
+	// Attributes of the edge (road class, speed limit, name and etc.) in string form
+	Attributes    map[string]string `protobuf:"bytes,5,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -195,23 +286,57 @@ func (x *EdgeInfo) GetGeom() []*GeoPoint {
 	return nil
 }
 
+func (x *EdgeInfo) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *EdgeInfo) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
 var File_shortest_path_proto protoreflect.FileDescriptor
 
 const file_shortest_path_proto_rawDesc = "" +
 	"\n" +
-	"\x13shortest_path.proto\x12\ahorizon\x1a\vpoint.proto\"i\n" +
+	"\x13shortest_path.proto\x12\ahorizon\x1a\vpoint.proto\x1a\x14route_geometry.proto\"\xcf\x02\n" +
 	"\tSPRequest\x12&\n" +
 	"\fstate_radius\x18\x01 \x01(\x01H\x00R\vstateRadius\x88\x01\x01\x12#\n" +
-	"\x03gps\x18\x02 \x03(\v2\x11.horizon.GeoPointR\x03gpsB\x0f\n" +
-	"\r_state_radius\"O\n" +
+	"\x03gps\x18\x02 \x03(\v2\x11.horizon.GeoPointR\x03gps\x12%\n" +
+	"\x0eexcluded_edges\x18\x03 \x03(\x03R\rexcludedEdges\x127\n" +
+	"\x0eavoid_polygons\x18\x04 \x03(\v2\x10.horizon.PolygonR\ravoidPolygons\x12\x1d\n" +
+	"\aprofile\x18\x05 \x01(\tH\x01R\aprofile\x88\x01\x01\x12&\n" +
+	"\froute_format\x18\x06 \x01(\tH\x02R\vrouteFormat\x88\x01\x01\x12\x17\n" +
+	"\x04srid\x18\a \x01(\x05H\x03R\x04srid\x88\x01\x01B\x0f\n" +
+	"\r_state_radiusB\n" +
+	"\n" +
+	"\b_profileB\x0f\n" +
+	"\r_route_formatB\a\n" +
+	"\x05_srid\"\xc3\x01\n" +
 	"\n" +
 	"SPResponse\x12%\n" +
 	"\x04data\x18\x01 \x03(\v2\x11.horizon.EdgeInfoR\x04data\x12\x1a\n" +
-	"\bwarnings\x18\x02 \x03(\tR\bwarnings\"b\n" +
+	"\bwarnings\x18\x02 \x03(\tR\bwarnings\x12\x18\n" +
+	"\aprofile\x18\x03 \x01(\tR\aprofile\x12\x12\n" +
+	"\x04cost\x18\x04 \x01(\x01R\x04cost\x12\x16\n" +
+	"\x06length\x18\x05 \x01(\x01R\x06length\x12,\n" +
+	"\x05route\x18\x06 \x01(\v2\x16.horizon.RouteGeometryR\x05route\"\xfc\x01\n" +
 	"\bEdgeInfo\x12\x17\n" +
 	"\aedge_id\x18\x01 \x01(\x03R\x06edgeId\x12\x16\n" +
 	"\x06weight\x18\x02 \x01(\x01R\x06weight\x12%\n" +
-	"\x04geom\x18\x03 \x03(\v2\x11.horizon.GeoPointR\x04geomB\x0eZ\f./;protos_pbb\x06proto3"
+	"\x04geom\x18\x03 \x03(\v2\x11.horizon.GeoPointR\x04geom\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x01R\x06length\x12A\n" +
+	"\n" +
+	"attributes\x18\x05 \x03(\v2!.horizon.EdgeInfo.AttributesEntryR\n" +
+	"attributes\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x0eZ\f./;protos_pbb\x06proto3"
 
 var (
 	file_shortest_path_proto_rawDescOnce sync.Once
@@ -225,22 +350,28 @@ func file_shortest_path_proto_rawDescGZIP() []byte {
 	return file_shortest_path_proto_rawDescData
 }
 
-var file_shortest_path_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_shortest_path_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_shortest_path_proto_goTypes = []any{
-	(*SPRequest)(nil),  // 0: horizon.SPRequest
-	(*SPResponse)(nil), // 1: horizon.SPResponse
-	(*EdgeInfo)(nil),   // 2: horizon.EdgeInfo
-	(*GeoPoint)(nil),   // 3: horizon.GeoPoint
+	(*SPRequest)(nil),     // 0: horizon.SPRequest
+	(*SPResponse)(nil),    // 1: horizon.SPResponse
+	(*EdgeInfo)(nil),      // 2: horizon.EdgeInfo
+	nil,                   // 3: horizon.EdgeInfo.AttributesEntry
+	(*GeoPoint)(nil),      // 4: horizon.GeoPoint
+	(*Polygon)(nil),       // 5: horizon.Polygon
+	(*RouteGeometry)(nil), // 6: horizon.RouteGeometry
 }
 var file_shortest_path_proto_depIdxs = []int32{
-	3, // 0: horizon.SPRequest.gps:type_name -> horizon.GeoPoint
-	2, // 1: horizon.SPResponse.data:type_name -> horizon.EdgeInfo
-	3, // 2: horizon.EdgeInfo.geom:type_name -> horizon.GeoPoint
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	4, // 0: horizon.SPRequest.gps:type_name -> horizon.GeoPoint
+	5, // 1: horizon.SPRequest.avoid_polygons:type_name -> horizon.Polygon
+	2, // 2: horizon.SPResponse.data:type_name -> horizon.EdgeInfo
+	6, // 3: horizon.SPResponse.route:type_name -> horizon.RouteGeometry
+	4, // 4: horizon.EdgeInfo.geom:type_name -> horizon.GeoPoint
+	3, // 5: horizon.EdgeInfo.attributes:type_name -> horizon.EdgeInfo.AttributesEntry
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_shortest_path_proto_init() }
@@ -249,6 +380,7 @@ func file_shortest_path_proto_init() {
 		return
 	}
 	file_point_proto_init()
+	file_route_geometry_proto_init()
 	file_shortest_path_proto_msgTypes[0].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -256,7 +388,7 @@ func file_shortest_path_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_shortest_path_proto_rawDesc), len(file_shortest_path_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,52 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/LdDl/horizon"
+	"github.com/LdDl/horizon/rpc/protos_pb"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+)
+
+// prepareQueryOptions Converts request's routing options to horizon.QueryOption set. Coordinates of polygons are given in the SRID
+func prepareQueryOptions(matcher *horizon.MapMatcher, profile *string, excludedEdges []int64, avoidPolygons []*protos_pb.Polygon, srid int) ([]horizon.QueryOption, error) {
+	opts := []horizon.QueryOption{}
+	if profile != nil && *profile != "" {
+		if !matcher.HasProfile(*profile) {
+			return nil, fmt.Errorf("unknown profile '%s'. Available profiles: %v", *profile, matcher.Profiles())
+		}
+		opts = append(opts, horizon.WithProfile(*profile))
+	}
+	if len(excludedEdges) > 0 {
+		opts = append(opts, horizon.WithExcludedEdges(excludedEdges...))
+	}
+	if len(avoidPolygons) > 0 {
+		polygons := make([]*s2.Polygon, 0, len(avoidPolygons))
+		for i := range avoidPolygons {
+			rings := make([][][]float64, 0, len(avoidPolygons[i].Rings))
+			for _, ring := range avoidPolygons[i].Rings {
+				coords := make([][]float64, 0, len(ring.Points))
+				for _, pt := range ring.Points {
+					coords = append(coords, []float64{pt.Lon, pt.Lat})
+				}
+				rings = append(rings, coords)
+			}
+			polygon, err := spatial.RingsToS2Polygon(rings, srid)
+			if err != nil {
+				return nil, fmt.Errorf("invalid avoid_polygons[%d]: %v", i, err)
+			}
+			polygons = append(polygons, polygon)
+		}
+		opts = append(opts, horizon.WithAvoidPolygons(polygons...))
+	}
+	return opts, nil
+}
+
+// profileName Returns name of the profile used for the request
+func profileName(profile *string) string {
+	if profile == nil || *profile == "" {
+		return horizon.DEFAULT_PROFILE
+	}
+	return *profile
+}
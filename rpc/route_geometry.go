@@ -0,0 +1,39 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/LdDl/horizon"
+	"github.com/LdDl/horizon/rpc/protos_pb"
+)
+
+// validateRouteFormat Checks if requested route format is supported
+func validateRouteFormat(format *string) error {
+	if format == nil || *format == "" || horizon.IsRouteFormatSupported(*format) {
+		return nil
+	}
+	return fmt.Errorf("unknown route_format '%s'. Supported formats: %s, %s, %s, %s", *format, horizon.ROUTE_FORMAT_GEOJSON, horizon.ROUTE_FORMAT_POLYLINE, horizon.ROUTE_FORMAT_POLYLINE6, horizon.ROUTE_FORMAT_WKT)
+}
+
+// prepareRouteGeometry Returns merged geometry of the sub-match in requested format with coordinates in the SRID. Returns nil if format is empty
+func prepareRouteGeometry(matcher *horizon.MapMatcher, subMatch horizon.SubMatch, format *string, srid int) (*protos_pb.RouteGeometry, error) {
+	if format == nil || *format == "" {
+		return nil, nil
+	}
+	line := matcher.AssembleRoute(subMatch)
+	ans := &protos_pb.RouteGeometry{
+		Format:    *format,
+		Distances: line.Distances,
+		Length:    line.Length(),
+	}
+	if *format == horizon.ROUTE_FORMAT_GEOJSON {
+		ans.Geom = s2PolylineToGeoPoints(line.Geom, srid)
+		return ans, nil
+	}
+	encoded, err := line.EncodeSRID(*format, srid)
+	if err != nil {
+		return nil, err
+	}
+	ans.Encoded = encoded
+	return ans, nil
+}
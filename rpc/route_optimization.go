@@ -0,0 +1,80 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/LdDl/horizon"
+	"github.com/LdDl/horizon/rpc/protos_pb"
+)
+
+// OptimizeRoute Implement OptimizeRoute() to match interface
+func (ts *Microservice) OptimizeRoute(ctx context.Context, in *protos_pb.OptimizeRouteRequest) (*protos_pb.OptimizeRouteResponse, error) {
+	matcher := ts.matcher.Snapshot()
+	if len(in.Gps) < 2 {
+		return nil, fmt.Errorf("please provide 2 GPS points atleast. Provided: %d", len(in.Gps))
+	}
+
+	response := &protos_pb.OptimizeRouteResponse{
+		Legs:     []*protos_pb.RouteLeg{},
+		Warnings: []string{},
+		Profile:  profileName(in.Profile),
+	}
+	if in.Closed && in.FixedEnd {
+		response.Warnings = append(response.Warnings, "fixed_end is ignored for closed routes")
+	}
+
+	statesRadiusMeters := horizon.ResolveRadius(in.StateRadius, horizon.DEFAULT_SP_RADIUS)
+
+	srid, err := requestSRID(in.Srid)
+	if err != nil {
+		return nil, err
+	}
+	waypoints := horizon.GPSMeasurements{}
+	ut := time.Now().UTC().Unix()
+	for i := range in.Gps {
+		waypoints = append(waypoints, horizon.NewGPSMeasurementFromID(int(ut), in.Gps[i].Lon, in.Gps[i].Lat, srid))
+		ut++
+	}
+	queryOptions, err := prepareQueryOptions(matcher, in.Profile, in.ExcludedEdges, in.AvoidPolygons, srid)
+	if err != nil {
+		return nil, err
+	}
+	params := horizon.RouteOptimizationOptions{
+		Closed:     in.Closed,
+		FixedStart: in.FixedStart,
+		FixedEnd:   in.FixedEnd,
+	}
+	result, err := matcher.OptimizeRoute(waypoints, statesRadiusMeters, params, queryOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("something went wrong on server side: %v", err)
+	}
+	response.Cost = result.Cost
+	response.Order = make([]int32, len(result.Order))
+	for i := range result.Order {
+		response.Order[i] = int32(result.Order[i])
+	}
+	response.Geom = s2PolylineToGeoPoints(result.Geometry(), srid)
+	for i := range result.Legs {
+		leg := &protos_pb.RouteLeg{
+			From:  int32(result.Order[i]),
+			To:    int32(result.Order[(i+1)%len(result.Order)]),
+			Edges: []*protos_pb.EdgeInfo{},
+		}
+		for _, edge := range horizon.PathEdges(result.Legs[i]) {
+			leg.Edges = append(leg.Edges, &protos_pb.EdgeInfo{
+				EdgeId:     edge.ID,
+				Weight:     edge.Weight,
+				Length:     edge.Length,
+				Geom:       s2PolylineToGeoPoints(edge.Geom, srid),
+				Attributes: edge.Attributes.Strings(),
+			})
+			leg.Cost += edge.Weight
+			leg.Length += edge.Length
+		}
+		response.Length += leg.Length
+		response.Legs = append(response.Legs, leg)
+	}
+	return response, nil
+}
@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LdDl/horizon"
+	"github.com/LdDl/horizon/rpc/protos_pb"
+)
+
+// GetServiceAreas Implement GetServiceAreas() to match interface
+func (ts *Microservice) GetServiceAreas(ctx context.Context, in *protos_pb.ServiceAreasRequest) (*protos_pb.ServiceAreasResponse, error) {
+	matcher := ts.matcher.Snapshot()
+	if len(in.Sources) < 1 {
+		return nil, fmt.Errorf("please provide 1 source atleast. Provided: %d", len(in.Sources))
+	}
+	response := &protos_pb.ServiceAreasResponse{
+		Data:     []*protos_pb.ServiceArea{},
+		Warnings: []string{},
+		Profile:  profileName(in.Profile),
+	}
+
+	maxCost := 0.0
+	if in.MaxCost != nil && *in.MaxCost >= 0 {
+		maxCost = *in.MaxCost
+	} else {
+		response.Warnings = append(response.Warnings, "max_cost either nil or not in range [0,+Inf]. Using default value: 0.0")
+	}
+
+	maxNearestRadius := horizon.ResolveRadius(in.MaxNearestRadius, horizon.DEFAULT_SP_RADIUS)
+
+	srid, err := requestSRID(in.Srid)
+	if err != nil {
+		return nil, err
+	}
+	queryOptions, err := prepareQueryOptions(matcher, in.Profile, in.ExcludedEdges, in.AvoidPolygons, srid)
+	if err != nil {
+		return nil, err
+	}
+	if in.Reverse {
+		queryOptions = append(queryOptions, horizon.WithReverse())
+	}
+	sources := make([]*horizon.GPSMeasurement, len(in.Sources))
+	for i := range in.Sources {
+		// Use index of source as ID
+		sources[i] = horizon.NewGPSMeasurementFromID(i, in.Sources[i].Lon, in.Sources[i].Lat, srid)
+	}
+	var params *horizon.IsochronePolygonsOptions
+	if in.Polygons {
+		params = &horizon.IsochronePolygonsOptions{}
+		if in.Smoothness != nil && *in.Smoothness > 0 {
+			params.Smoothness = *in.Smoothness
+		}
+	}
+	areas, err := matcher.FindServiceAreas(sources, maxCost, maxNearestRadius, params, queryOptions...)
+	if err != nil {
+		return nil, err
+	}
+	for _, area := range areas {
+		areaResponse := &protos_pb.ServiceArea{
+			Source:         int32(area.Source),
+			SourceVertexId: area.SourceVertex,
+			Isochrones:     make([]*protos_pb.Isochrone, 0, len(area.Isochrones)),
+			Polygons:       isochronePolygonsToProto(area.Polygons, srid),
+			Stats: &protos_pb.ServiceAreaStats{
+				VerticesNum: int32(area.Stats.VerticesNum),
+				MaxCost:     area.Stats.MaxCost,
+				AvgCost:     area.Stats.AvgCost,
+				Area:        area.Stats.Area,
+			},
+		}
+		for i, isochrone := range area.Isochrones {
+			if isochrone.Vertex == nil || isochrone.Vertex.Point == nil {
+				return nil, fmt.Errorf("empty vertex")
+			}
+			areaResponse.Isochrones = append(areaResponse.Isochrones, &protos_pb.Isochrone{
+				Id:       int64(i),
+				VertexId: isochrone.Vertex.ID,
+				Cost:     isochrone.Cost,
+				Point:    s2PointToGeoPoint(*isochrone.Vertex.Point, srid),
+			})
+		}
+		if in.Polygons && len(area.Polygons) == 0 {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("service area of source #%d is too small (or degenerate) to build polygon", area.Source))
+		}
+		response.Data = append(response.Data, areaResponse)
+	}
+	return response, nil
+}
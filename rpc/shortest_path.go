@@ -7,11 +7,11 @@ import (
 
 	"github.com/LdDl/horizon"
 	"github.com/LdDl/horizon/rpc/protos_pb"
-	"github.com/golang/geo/s2"
 )
 
 // GetSP Implement GetSP() to match interface
 func (ts *Microservice) GetSP(ctx context.Context, in *protos_pb.SPRequest) (*protos_pb.SPResponse, error) {
+	matcher := ts.matcher.Snapshot()
 	if len(in.Gps) != 2 {
 		return nil, fmt.Errorf("please provide 2 GPS points only. Provided: %d", len(in.Gps))
 	}
@@ -19,61 +19,51 @@ func (ts *Microservice) GetSP(ctx context.Context, in *protos_pb.SPRequest) (*pr
 	response := &protos_pb.SPResponse{
 		Data:     []*protos_pb.EdgeInfo{},
 		Warnings: []string{},
+		Profile:  profileName(in.Profile),
 	}
 
 	statesRadiusMeters := horizon.ResolveRadius(in.StateRadius, horizon.DEFAULT_SP_RADIUS)
 
+	srid, err := requestSRID(in.Srid)
+	if err != nil {
+		return nil, err
+	}
 	gpsMeasurements := horizon.GPSMeasurements{}
 	ut := time.Now().UTC().Unix()
 	for i := range in.Gps {
-		gpsMeasurement := horizon.NewGPSMeasurementFromID(int(ut), in.Gps[i].Lon, in.Gps[i].Lat, 4326)
+		gpsMeasurement := horizon.NewGPSMeasurementFromID(int(ut), in.Gps[i].Lon, in.Gps[i].Lat, srid)
 		gpsMeasurements = append(gpsMeasurements, gpsMeasurement)
 		ut++
 	}
-	result, err := ts.matcher.FindShortestPath(gpsMeasurements[0], gpsMeasurements[1], statesRadiusMeters)
+	queryOptions, err := prepareQueryOptions(matcher, in.Profile, in.ExcludedEdges, in.AvoidPolygons, srid)
+	if err != nil {
+		return nil, err
+	}
+	err = validateRouteFormat(in.RouteFormat)
+	if err != nil {
+		return nil, err
+	}
+	result, err := matcher.FindShortestPath(gpsMeasurements[0], gpsMeasurements[1], statesRadiusMeters, queryOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("something went wrong on server side: %v", err)
 	}
 	// @todo: For now, we only handle the first sub-match
 	// Do we need to handle multiple sub-matches at all? Shortest path should exists...
 	subMatch := result.SubMatches[0]
-	for i := range subMatch.Observations {
-		observationResult := subMatch.Observations[i]
-		feature := &protos_pb.EdgeInfo{
-			EdgeId: observationResult.MatchedEdge.ID,
-			Weight: observationResult.MatchedEdge.Weight,
-		}
-		if observationResult.MatchedEdge.Polyline != nil {
-			geomLen := len(*observationResult.MatchedEdge.Polyline)
-			feature.Geom = make([]*protos_pb.GeoPoint, geomLen)
-			for k := range *observationResult.MatchedEdge.Polyline {
-				latLng := s2.LatLngFromPoint((*observationResult.MatchedEdge.Polyline)[k])
-				feature.Geom[k] = &protos_pb.GeoPoint{
-					Lon: latLng.Lng.Degrees(),
-					Lat: latLng.Lat.Degrees(),
-				}
-			}
-		}
-		response.Data = append(response.Data, feature)
-
-		for j := range observationResult.NextEdges {
-			edgeFeature := &protos_pb.EdgeInfo{
-				EdgeId: observationResult.NextEdges[j].ID,
-				Weight: observationResult.NextEdges[j].Weight,
-			}
-			if observationResult.NextEdges[j].Geom != nil {
-				geomLen := len(observationResult.NextEdges[j].Geom)
-				edgeFeature.Geom = make([]*protos_pb.GeoPoint, geomLen)
-				for k := range observationResult.NextEdges[j].Geom {
-					latLng := s2.LatLngFromPoint(observationResult.NextEdges[j].Geom[k])
-					edgeFeature.Geom[k] = &protos_pb.GeoPoint{
-						Lon: latLng.Lng.Degrees(),
-						Lat: latLng.Lat.Degrees(),
-					}
-				}
-			}
-			response.Data = append(response.Data, edgeFeature)
-		}
+	for _, edge := range horizon.PathEdges(result) {
+		response.Data = append(response.Data, &protos_pb.EdgeInfo{
+			EdgeId:     edge.ID,
+			Weight:     edge.Weight,
+			Length:     edge.Length,
+			Geom:       s2PolylineToGeoPoints(edge.Geom, srid),
+			Attributes: edge.Attributes.Strings(),
+		})
+		response.Cost += edge.Weight
+		response.Length += edge.Length
+	}
+	response.Route, err = prepareRouteGeometry(matcher, subMatch, in.RouteFormat, srid)
+	if err != nil {
+		return nil, fmt.Errorf("something went wrong on server side: %v", err)
 	}
 	return response, nil
 }
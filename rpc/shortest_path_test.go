@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/LdDl/horizon"
+	"github.com/LdDl/horizon/rpc/protos_pb"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+)
+
+// testRoadService Returns service for two-way road of three edges along the parallel: 0 - 1 - 2 - 3
+func testRoadService(t *testing.T) *Microservice {
+	edges := []*spatial.Edge{}
+	for i := int64(0); i < 3; i++ {
+		source := spatial.NewWGS84Point(37.60+0.01*float64(i), 55.75).Point
+		target := spatial.NewWGS84Point(37.61+0.01*float64(i), 55.75).Point
+		forward, backward := s2.Polyline{source, target}, s2.Polyline{target, source}
+		weight := forward.Length().Radians() * spatial.EarthRadius
+		edges = append(edges,
+			&spatial.Edge{ID: 2*i + 1, Source: i, Target: i + 1, Weight: weight, Polyline: &forward},
+			&spatial.Edge{ID: 2*i + 2, Source: i + 1, Target: i, Weight: weight, Polyline: &backward},
+		)
+	}
+	engine, err := horizon.NewMapEngineBuilder().AddEdges(edges...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Microservice{matcher: horizon.NewMapMatcher(horizon.WithMapEngine(engine))}
+}
+
+func TestGetSPSingleEdge(t *testing.T) {
+	response, err := testRoadService(t).GetSP(context.Background(), &protos_pb.SPRequest{
+		Gps: []*protos_pb.GeoPoint{{Lon: 37.612, Lat: 55.7501}, {Lon: 37.618, Lat: 55.7501}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Data) != 1 {
+		t.Fatalf("Path should consist of single edge, but got %d edges", len(response.Data))
+	}
+	if math.Abs(response.Length-response.Data[0].Length) > 1e-9 || math.Abs(response.Cost-response.Data[0].Weight) > 1e-9 {
+		t.Errorf("Cost and length should be counted once: got cost %f and length %f for edge of length %f", response.Cost, response.Length, response.Data[0].Length)
+	}
+}
+
+func TestGetSPProjectedCRS(t *testing.T) {
+	service := testRoadService(t)
+	mercator := spatial.WebMercator{}
+	sourceX, sourceY := mercator.Forward(37.602, 55.7501)
+	targetX, targetY := mercator.Forward(37.628, 55.7501)
+	srid := int32(spatial.SRID_WEB_MERCATOR)
+	format := horizon.ROUTE_FORMAT_WKT
+	response, err := service.GetSP(context.Background(), &protos_pb.SPRequest{
+		Gps:         []*protos_pb.GeoPoint{{Lon: sourceX, Lat: sourceY}, {Lon: targetX, Lat: targetY}},
+		Srid:        &srid,
+		RouteFormat: &format,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Data) != 1 || response.Route == nil {
+		t.Fatalf("Path should consist of single edge and merged geometry, but got %d edges and route %v", len(response.Data), response.Route)
+	}
+	// Geometries of edges and merged route are returned in Web Mercator
+	expectedX, expectedY := mercator.Forward(37.61, 55.75)
+	first := response.Data[0].Geom[0]
+	if math.Abs(first.Lon-expectedX) > 1e-3 || math.Abs(first.Lat-expectedY) > 1e-3 {
+		t.Errorf("First point of path should be (%f, %f), but got (%f, %f)", expectedX, expectedY, first.Lon, first.Lat)
+	}
+	route, err := spatial.WKTToS2PolylineFeatureSRID(response.Route.Encoded, spatial.SRID_WEB_MERCATOR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if startX, _, _ := spatial.S2PointToSRID((*route)[0], spatial.SRID_WEB_MERCATOR); math.Abs(startX-expectedX) > 1e-3 {
+		t.Errorf("Merged route should start at X = %f, but got %f", expectedX, startX)
+	}
+
+	// Unknown SRID is rejected
+	unknown := int32(999999)
+	_, err = service.GetSP(context.Background(), &protos_pb.SPRequest{
+		Gps:  []*protos_pb.GeoPoint{{Lon: sourceX, Lat: sourceY}, {Lon: targetX, Lat: targetY}},
+		Srid: &unknown,
+	})
+	if err == nil {
+		t.Error("Request with unknown SRID should be rejected")
+	}
+}
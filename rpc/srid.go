@@ -0,0 +1,39 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/LdDl/horizon/rpc/protos_pb"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+)
+
+// requestSRID Returns SRID of the request (4326 if omitted). Returns error if SRID is not supported
+func requestSRID(srid *int32) (int, error) {
+	ans := 4326
+	if srid != nil {
+		ans = int(*srid)
+	}
+	if !spatial.IsSRIDSupported(ans) {
+		return 0, fmt.Errorf("SRID %d is not supported", ans)
+	}
+	return ans, nil
+}
+
+// s2PointToGeoPoint Converts point to GeoPoint message with coordinates in the SRID. SRID must be supported (see requestSRID)
+func s2PointToGeoPoint(pt s2.Point, srid int) *protos_pb.GeoPoint {
+	x, y, _ := spatial.S2PointToSRID(pt, srid)
+	return &protos_pb.GeoPoint{
+		Lon: x,
+		Lat: y,
+	}
+}
+
+// s2PolylineToGeoPoints Converts polyline to set of GeoPoint messages with coordinates in the SRID. SRID must be supported (see requestSRID)
+func s2PolylineToGeoPoints(polyline []s2.Point, srid int) []*protos_pb.GeoPoint {
+	points := make([]*protos_pb.GeoPoint, len(polyline))
+	for i := range polyline {
+		points[i] = s2PointToGeoPoint(polyline[i], srid)
+	}
+	return points
+}
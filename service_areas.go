@@ -0,0 +1,138 @@
+package horizon
+
+import (
+	"log"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/pkg/errors"
+)
+
+// ServiceArea Part of the network served by single source (cell of network Voronoi diagram)
+/*
+	Source - index of the source in the request
+	SourceVertex - vertex which the source has been snapped to
+	Isochrones - vertices for which the source is the nearest one with costs of reaching them
+	Polygons - polygons covering the area (only if polygons are requested)
+	Stats - coverage statistics
+*/
+type ServiceArea struct {
+	Source       int
+	SourceVertex int64
+	Isochrones   IsochronesResult
+	Polygons     []IsochronePolygon
+	Stats        ServiceAreaStats
+}
+
+// ServiceAreaStats Coverage statistics of the service area
+/*
+	VerticesNum - number of served vertices
+	MaxCost - cost of reaching the farthest served vertex
+	AvgCost - average cost of reaching served vertices
+	Area - area of polygons (square meters for WGS84 graphs). Zero if polygons are not requested
+*/
+type ServiceAreaStats struct {
+	VerticesNum int
+	MaxCost     float64
+	AvgCost     float64
+	Area        float64
+}
+
+// FindServiceAreas Multi-source variant of FindIsochrones: single Dijkstra's search is seeded from every source,
+// so every reached vertex is assigned to its nearest source (network Voronoi diagram)
+/*
+	NOTICE: this function snaps every source to only one nearest vertex (the same way as FindIsochrones does)
+	sources - sources (e.g. depots)
+	maxCost - max cost restriction
+	maxNearestRadius - max radius of search for nearest vertex
+	params - polygons building parameters. Use nil when polygons are not needed
+	opts - per-request options (see QueryOptions). Excluded edges are neither used for snapping nor traversed. Use WithReverse for costs of reaching the sources instead
+
+	Service areas are returned in order of sources. Sources snapped to the same vertex share it: the first of them serves the area and others get empty areas.
+	Polygons of neighbouring areas do not overlap: roads between vertices of different areas are cut where the costs meet
+*/
+func (matcher *MapMatcher) FindServiceAreas(sources []*GPSMeasurement, maxCost float64, maxNearestRadius float64, params *IsochronePolygonsOptions, opts ...QueryOption) ([]ServiceArea, error) {
+	engine := matcher.Engine()
+	err := checkMeasurementsSRID(sources...)
+	if err != nil {
+		return nil, err
+	}
+	query, err := engine.prepareQuery(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't prepare query")
+	}
+	areas := make([]ServiceArea, len(sources))
+	// Seed vertex -> index of the source
+	owners := make(map[int64]int, len(sources))
+	seeds := make(map[int64]float64, len(sources))
+	for i, source := range sources {
+		sourceVertex, err := engine.isochronesSource(query, source, maxNearestRadius)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't snap source #%d", i)
+		}
+		areas[i] = ServiceArea{
+			Source:       i,
+			SourceVertex: sourceVertex,
+			Isochrones:   IsochronesResult{},
+			Polygons:     []IsochronePolygon{},
+		}
+		if _, ok := owners[sourceVertex]; !ok {
+			owners[sourceVertex] = i
+			seeds[sourceVertex] = 0
+		}
+	}
+
+	settled := query.dijkstraSeeds(seeds, -1, maxCost, query.reverse)
+	costs := make([]map[int64]float64, len(sources))
+	for i := range costs {
+		costs[i] = make(map[int64]float64)
+	}
+	for vertexID, label := range settled {
+		owner := owners[label.origin]
+		costs[owner][vertexID] = label.cost
+		vertex, ok := engine.vertices[vertexID]
+		if !ok {
+			log.Printf("[WARNING]; No such vertex in storage: %d\n", vertexID)
+		}
+		area := &areas[owner]
+		area.Isochrones = append(area.Isochrones, &Isochrone{
+			Vertex: vertex,
+			Cost:   label.cost,
+		})
+		area.Stats.VerticesNum++
+		area.Stats.AvgCost += label.cost
+		if label.cost > area.Stats.MaxCost {
+			area.Stats.MaxCost = label.cost
+		}
+	}
+	for i := range areas {
+		if areas[i].Stats.VerticesNum > 0 {
+			areas[i].Stats.AvgCost /= float64(areas[i].Stats.VerticesNum)
+		}
+	}
+	if params == nil {
+		return areas, nil
+	}
+
+	thresholds := []float64{maxCost}
+	for i := range areas {
+		if len(costs[i]) == 0 {
+			continue
+		}
+		owner := i
+		rival := func(vertex int64) (float64, bool) {
+			label, ok := settled[vertex]
+			if !ok || owners[label.origin] == owner {
+				return 0, false
+			}
+			return label.cost, true
+		}
+		projection := spatial.NewLocalProjection(sources[i].Point, engine.isEuclidean())
+		samples, sampleCosts := query.reachableSamples(projection, costs[i], thresholds, rival)
+		bands := buildIsochroneBands(projection, samples, sampleCosts, thresholds, *params)
+		areas[i].Polygons = bands[0].Polygons
+		for _, polygon := range areas[i].Polygons {
+			areas[i].Stats.Area += polygon.Area
+		}
+	}
+	return areas, nil
+}
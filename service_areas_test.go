@@ -0,0 +1,68 @@
+package horizon
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFindServiceAreas(t *testing.T) {
+	matcher := prepareGridMatcher(t, [][2]float64{{0, 0}}, 10, nil)
+	// Sources are snapped to vertices (2,5) and (8,5)
+	sources := []*GPSMeasurement{
+		NewGPSMeasurementFromID(1, 2.1, 4.3, 0),
+		NewGPSMeasurementFromID(2, 8.1, 4.3, 0),
+	}
+	centers := [][2]float64{{2, 5}, {8, 5}}
+	areas, err := matcher.FindServiceAreas(sources, 100, -1, &IsochronePolygonsOptions{Smoothness: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(areas) != 2 {
+		t.Fatalf("Should be 2 service areas, got %d", len(areas))
+	}
+	total := 0
+	totalArea := 0.0
+	for i, area := range areas {
+		total += area.Stats.VerticesNum
+		totalArea += area.Stats.Area
+		if area.Stats.VerticesNum != len(area.Isochrones) {
+			t.Errorf("Vertices number of area #%d should be %d, got %d", i, len(area.Isochrones), area.Stats.VerticesNum)
+		}
+		for _, isochrone := range area.Isochrones {
+			x, y := isochrone.Vertex.Point.X, isochrone.Vertex.Point.Y
+			// Vertices in the middle are equidistant from both sources
+			if (i == 0 && x > 5) || (i == 1 && x < 5) {
+				t.Errorf("Vertex (%f, %f) should not be served by source #%d", x, y, i)
+			}
+			expectedCost := math.Abs(x-centers[i][0]) + math.Abs(y-centers[i][1])
+			if math.Abs(isochrone.Cost-expectedCost) > 1e-9 {
+				t.Errorf("Cost of vertex (%f, %f) should be %f, got %f", x, y, expectedCost, isochrone.Cost)
+			}
+		}
+		if area.Stats.MaxCost > 8+1e-9 || area.Stats.AvgCost <= 0 {
+			t.Errorf("Wrong cost statistics of area #%d: max %f, avg %f", i, area.Stats.MaxCost, area.Stats.AvgCost)
+		}
+		if len(area.Polygons) == 0 || area.Stats.Area < 40 {
+			t.Errorf("Area #%d should be covered by polygons with area close to 50, got %f", i, area.Stats.Area)
+		}
+	}
+	if total != 121 {
+		t.Errorf("Every vertex should be served, got %d", total)
+	}
+	// Polygons of neighbouring areas don't overlap
+	if totalArea > 100+1e-9 {
+		t.Errorf("Total area should not exceed area of the grid, got %f", totalArea)
+	}
+
+	// Sources snapped to the same vertex share it
+	areas, err = matcher.FindServiceAreas([]*GPSMeasurement{sources[0], sources[0]}, 2, -1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if areas[0].Stats.VerticesNum != 13 || areas[1].Stats.VerticesNum != 0 {
+		t.Errorf("The first source should serve 13 vertices and the second one nothing, got %d and %d", areas[0].Stats.VerticesNum, areas[1].Stats.VerticesNum)
+	}
+	if len(areas[0].Polygons) != 0 {
+		t.Errorf("Polygons should not be built when they are not requested")
+	}
+}
@@ -0,0 +1,895 @@
+package horizon
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/LdDl/ch"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/r3"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SNAPSHOT_VERSION Version of binary snapshot format. Snapshots of other versions are rejected by LoadSnapshot
+	SNAPSHOT_VERSION = 4
+	// snapshotMagic First bytes of every snapshot file
+	snapshotMagic = "HRZNSNAP"
+)
+
+const (
+	snapshotStorageSpherical uint8 = iota
+	snapshotStorageEuclidean
+)
+
+// snapshotMaxCellLevel Deepest level of S2 cells
+const snapshotMaxCellLevel = 30
+
+// SaveSnapshot Writes binary snapshot of the engine to the file
+/*
+	path - path to the snapshot file. It is overwritten if exists
+
+	Snapshot holds everything which is needed to start serving requests without parsing CSVs:
+	SRID of the graph and model of distances (sphere or ellipsoid), edges with geometry and attributes, vertices, contraction hierarchies (vertices order and shortcuts) of default and additional weight profiles,
+	cells of spatial index (spherical storage only) and connected components. Facilities are not saved.
+	All numbers are little-endian, the file ends with CRC-32 (IEEE) of preceding bytes.
+	Data is written to temporary file in the same directory first and then renamed, so existing snapshot is never left half-written
+*/
+func (engine *MapEngine) SaveSnapshot(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return errors.Wrapf(err, "Can't create temporary file for snapshot '%s'", path)
+	}
+	defer os.Remove(tmp.Name())
+	sw := newSnapshotWriter(tmp)
+	err = engine.writeSnapshot(sw)
+	if err == nil {
+		err = sw.finish()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Can't write snapshot '%s'", path)
+	}
+	return errors.Wrapf(os.Rename(tmp.Name(), path), "Can't move snapshot to '%s'", path)
+}
+
+// LoadSnapshot Replaces data of the engine with data from binary snapshot (see SaveSnapshot)
+/*
+	path - path to the snapshot file
+
+	Storage of the engine is replaced with the one of the same type and level as in snapshot, SRID and model of distances are taken from snapshot too.
+	Snapshot is rejected with ErrSnapshotOptions if engine has conflicting options: SRID set by WithGraphSRID differs from the stored one,
+	WithEllipsoidalDistances is used for spherical snapshot or profile requested by WithWeightProfiles is missing in snapshot.
+	It should be called before the engine starts serving requests
+*/
+func (engine *MapEngine) LoadSnapshot(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "Can't open snapshot '%s'", path)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "Can't stat snapshot '%s'", path)
+	}
+	sr := newSnapshotReader(file, info.Size())
+	err = engine.readSnapshot(sr)
+	if err == nil {
+		err = sr.finish()
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Can't read snapshot '%s'", path)
+	}
+	// Profiles of WithWeightProfile options are prepared for edges of snapshot
+	return engine.preparePendingProfiles()
+}
+
+// NewMapMatcherFromSnapshot Returns pointer to created MapMatcher from binary snapshot (see MapEngine.SaveSnapshot)
+/*
+	props - parameters of Hidden Markov Model
+	path - path to the snapshot file
+	opts - options of the engine. SRID, model of distances and weight profiles are stored in snapshot, so such options are only checked against it (see MapEngine.LoadSnapshot)
+*/
+func NewMapMatcherFromSnapshot(props *HmmProbabilities, path string, opts ...func(*MapEngine)) (*MapMatcher, error) {
+	st := time.Now()
+	engine := NewMapEngine(opts...)
+	err := engine.LoadSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewMapMatcher(WithHmmParameters(props), WithMapEngine(engine), WithGraphSource(path, time.Since(st))), nil
+}
+
+// SaveSnapshot Writes binary snapshot of the matcher's engine to the file (see MapEngine.SaveSnapshot)
+func (matcher *MapMatcher) SaveSnapshot(path string) error {
+	return matcher.Engine().SaveSnapshot(path)
+}
+
+func (engine *MapEngine) writeSnapshot(sw *snapshotWriter) error {
+	sw.bytes([]byte(snapshotMagic))
+	sw.uint32(SNAPSHOT_VERSION)
+
+	// Storage
+	s2Storage, _ := engine.storage.(*spatial.S2Storage)
+	switch {
+	case engine.isEuclidean():
+		sw.uint8(snapshotStorageEuclidean)
+		sw.int64(0)
+		sw.bool(false)
+	case s2Storage != nil:
+		sw.uint8(snapshotStorageSpherical)
+		sw.int64(int64(s2Storage.StorageLevel()))
+		sw.bool(s2Storage.ExactDistances())
+	default:
+		return fmt.Errorf("storage of type %T can't be saved", engine.storage)
+	}
+	// Euclidean storage is used for raw Cartesian coordinates only, whatever SRID is set
+	graphSRID := engine.graphSRID
+	if engine.isEuclidean() {
+		graphSRID = 0
+	}
+	sw.int64(int64(graphSRID))
+	sw.bool(engine.ellipsoidal)
+
+	// Edges
+	edges := make([]*spatial.Edge, 0, len(engine.edges))
+	for _, edge := range engine.edges {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].ID < edges[j].ID })
+	sw.uint64(uint64(len(edges)))
+	for _, edge := range edges {
+		sw.int64(edge.ID)
+		sw.int64(edge.Source)
+		sw.int64(edge.Target)
+		sw.float64(edge.Weight)
+		var polyline s2.Polyline
+		if edge.Polyline != nil {
+			polyline = *edge.Polyline
+		}
+		sw.uint64(uint64(len(polyline)))
+		for _, pt := range polyline {
+			sw.point(pt)
+		}
+		var cells []s2.CellID
+		if s2Storage != nil {
+			cells = s2Storage.EdgeCells(uint64(edge.ID))
+		}
+		sw.uint64(uint64(len(cells)))
+		for _, cell := range cells {
+			sw.uint64(uint64(cell))
+		}
+	}
+
+	// Vertices
+	vertexIDs := make([]int64, 0, len(engine.vertices))
+	for vertexID := range engine.vertices {
+		vertexIDs = append(vertexIDs, vertexID)
+	}
+	sort.Slice(vertexIDs, func(i, j int) bool { return vertexIDs[i] < vertexIDs[j] })
+	sw.uint64(uint64(len(vertexIDs)))
+	for _, vertexID := range vertexIDs {
+		vertex := engine.vertices[vertexID]
+		sw.int64(vertexID)
+		sw.bool(vertex.Point != nil)
+		if vertex.Point != nil {
+			sw.point(*vertex.Point)
+		}
+	}
+
+	// Contraction hierarchies of default profile
+	err := sw.graph(&engine.graph, snapshotGraphEdges(edges, nil))
+	if err != nil {
+		return errors.Wrap(err, "Can't write graph of default profile")
+	}
+
+	// Connected components
+	sw.components(engine.vertexComponent)
+	sw.int64(engine.bigComponentID)
+	sw.components(engine.vertexStrongComponent)
+	sw.int64(engine.bigStrongComponentID)
+	smallComponents := make([]int64, 0, len(engine.isComponentVerySmall))
+	for componentID, small := range engine.isComponentVerySmall {
+		if small {
+			smallComponents = append(smallComponents, componentID)
+		}
+	}
+	sort.Slice(smallComponents, func(i, j int) bool { return smallComponents[i] < smallComponents[j] })
+	sw.uint64(uint64(len(smallComponents)))
+	for _, componentID := range smallComponents {
+		sw.int64(componentID)
+	}
+
+	// Additional weight profiles
+	names := engine.Profiles()[1:]
+	sw.uint64(uint64(len(names)))
+	for _, name := range names {
+		profile := engine.profiles[name]
+		sw.string(name)
+		edgeIDs := make([]int64, 0, len(profile.weights))
+		for edgeID := range profile.weights {
+			edgeIDs = append(edgeIDs, edgeID)
+		}
+		sort.Slice(edgeIDs, func(i, j int) bool { return edgeIDs[i] < edgeIDs[j] })
+		sw.uint64(uint64(len(edgeIDs)))
+		for _, edgeID := range edgeIDs {
+			sw.int64(edgeID)
+			sw.float64(profile.weights[edgeID])
+		}
+		err = sw.graph(profile.graph, snapshotGraphEdges(edges, profile))
+		if err != nil {
+			return errors.Wrapf(err, "Can't write graph of profile '%s'", name)
+		}
+	}
+
+	// Edge attributes
+	sw.uint64(uint64(len(engine.attributeNames)))
+	for _, name := range engine.attributeNames {
+		sw.string(name)
+	}
+	attributedIDs := make([]int64, 0, len(engine.attributes))
+	for edgeID := range engine.attributes {
+		attributedIDs = append(attributedIDs, edgeID)
+	}
+	sort.Slice(attributedIDs, func(i, j int) bool { return attributedIDs[i] < attributedIDs[j] })
+	sw.uint64(uint64(len(attributedIDs)))
+	for _, edgeID := range attributedIDs {
+		attributes := engine.attributes[edgeID]
+		sw.int64(edgeID)
+		sw.uint64(uint64(len(attributes)))
+		for _, name := range attributes.Names() {
+			sw.string(name)
+			sw.attribute(attributes[name])
+		}
+	}
+	return sw.err
+}
+
+func (engine *MapEngine) readSnapshot(sr *snapshotReader) error {
+	magic := sr.bytes(len(snapshotMagic))
+	if sr.err != nil {
+		return sr.err
+	}
+	if string(magic) != snapshotMagic {
+		return errors.Wrap(ErrSnapshotFormat, "unexpected magic bytes")
+	}
+	version := sr.uint32()
+	if sr.err == nil && version != SNAPSHOT_VERSION {
+		return errors.Wrapf(ErrSnapshotVersion, "snapshot version is %d, supported version is %d", version, SNAPSHOT_VERSION)
+	}
+
+	// Storage
+	storageKind := sr.uint8()
+	storageLevel := sr.int64()
+	exactDistances := sr.bool()
+	graphSRID := sr.int64()
+	ellipsoidal := sr.bool()
+	if sr.err != nil {
+		return sr.err
+	}
+	if !spatial.IsSRIDSupported(int(graphSRID)) || (graphSRID == 0) != (storageKind == snapshotStorageEuclidean) {
+		return errors.Wrapf(ErrSnapshotFormat, "invalid SRID %d for storage type %d", graphSRID, storageKind)
+	}
+	if engine.graphSRIDSet && int64(engine.graphSRID) != graphSRID {
+		return errors.Wrapf(ErrSnapshotOptions, "SRID %d is set, but graph in snapshot has SRID %d", engine.graphSRID, graphSRID)
+	}
+	if engine.ellipsoidal && !ellipsoidal && graphSRID != 0 {
+		return errors.Wrap(ErrSnapshotOptions, "ellipsoidal distances are enabled, but snapshot is prepared for spherical ones")
+	}
+	engine.graphSRID = int(graphSRID)
+	engine.ellipsoidal = ellipsoidal
+	var s2Storage *spatial.S2Storage
+	switch storageKind {
+	case snapshotStorageSpherical:
+		if storageLevel < 0 || storageLevel > snapshotMaxCellLevel {
+			return errors.Wrapf(ErrSnapshotFormat, "invalid storage level %d", storageLevel)
+		}
+		opts := []spatial.StorageOption{spatial.WithStorageLevel(int(storageLevel))}
+		if exactDistances {
+			opts = append(opts, spatial.WithExactDistances())
+		}
+		engine.storage = spatial.NewStorage(spatial.StorageTypeSpherical, opts...)
+		s2Storage = engine.storage.(*spatial.S2Storage)
+	case snapshotStorageEuclidean:
+		engine.storage = spatial.NewStorage(spatial.StorageTypeEuclidean)
+	default:
+		return errors.Wrapf(ErrSnapshotFormat, "unknown storage type %d", storageKind)
+	}
+
+	// Edges
+	edgesNum := sr.count()
+	engine.edges = make(map[int64]*spatial.Edge)
+	engine.outgoing = make(map[int64][]*spatial.Edge)
+	engine.incoming = make(map[int64][]*spatial.Edge)
+	for i := uint64(0); i < edgesNum && sr.err == nil; i++ {
+		edge := &spatial.Edge{
+			ID:     sr.int64(),
+			Source: sr.int64(),
+			Target: sr.int64(),
+			Weight: sr.float64(),
+		}
+		pointsNum := sr.count()
+		if pointsNum > 0 {
+			polyline := make(s2.Polyline, 0, pointsNum)
+			for j := uint64(0); j < pointsNum && sr.err == nil; j++ {
+				polyline = append(polyline, sr.point())
+			}
+			edge.Polyline = &polyline
+		}
+		cellsNum := sr.count()
+		cells := make([]s2.CellID, 0, cellsNum)
+		for j := uint64(0); j < cellsNum && sr.err == nil; j++ {
+			cells = append(cells, s2.CellID(sr.uint64()))
+		}
+		if sr.err != nil {
+			break
+		}
+		engine.addEdge(edge)
+		if edge.Polyline == nil {
+			continue
+		}
+		var err error
+		if s2Storage != nil && len(cells) > 0 {
+			err = s2Storage.AddEdgeWithCells(uint64(edge.ID), edge, cells)
+		} else {
+			err = engine.storage.AddEdge(uint64(edge.ID), edge)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Can't add edge %d to storage", edge.ID)
+		}
+	}
+
+	// Vertices
+	verticesNum := sr.count()
+	engine.vertices = make(map[int64]*spatial.Vertex)
+	for i := uint64(0); i < verticesNum && sr.err == nil; i++ {
+		vertex := &spatial.Vertex{ID: sr.int64()}
+		if sr.bool() {
+			pt := sr.point()
+			vertex.Point = &pt
+		}
+		engine.vertices[vertex.ID] = vertex
+	}
+
+	// Contraction hierarchies of default profile
+	graph, err := sr.graph()
+	if err != nil {
+		return errors.Wrap(err, "Can't read graph of default profile")
+	}
+	engine.graph = *graph
+	engine.queryPool = engine.graph.NewQueryPool()
+
+	// Connected components
+	engine.vertexComponent = sr.components()
+	engine.bigComponentID = sr.int64()
+	engine.vertexStrongComponent = sr.components()
+	engine.bigStrongComponentID = sr.int64()
+	smallNum := sr.count()
+	engine.isComponentVerySmall = make(map[int64]bool)
+	for i := uint64(0); i < smallNum && sr.err == nil; i++ {
+		engine.isComponentVerySmall[sr.int64()] = true
+	}
+
+	// Additional weight profiles
+	profilesNum := sr.count()
+	requestedProfiles := engine.profileColumns
+	engine.profiles = make(map[string]*weightProfile)
+	engine.profileColumns = nil
+	for i := uint64(0); i < profilesNum && sr.err == nil; i++ {
+		name := sr.string()
+		weightsNum := sr.count()
+		weights := make(map[int64]float64, weightsNum)
+		for j := uint64(0); j < weightsNum && sr.err == nil; j++ {
+			edgeID := sr.int64()
+			weights[edgeID] = sr.float64()
+		}
+		profileGraph, err := sr.graph()
+		if err != nil {
+			return errors.Wrapf(err, "Can't read graph of profile '%s'", name)
+		}
+		engine.profiles[name] = &weightProfile{
+			name:      name,
+			graph:     profileGraph,
+			queryPool: profileGraph.NewQueryPool(),
+			weights:   weights,
+		}
+	}
+	for _, name := range requestedProfiles {
+		if sr.err == nil && engine.profiles[name] == nil {
+			return errors.Wrapf(ErrSnapshotOptions, "weight profile '%s' is not found in snapshot", name)
+		}
+	}
+
+	// Edge attributes
+	attributeNamesNum := sr.count()
+	engine.attributeNames = make([]string, 0, attributeNamesNum)
+	for i := uint64(0); i < attributeNamesNum && sr.err == nil; i++ {
+		engine.attributeNames = append(engine.attributeNames, sr.string())
+	}
+	attributedNum := sr.count()
+	engine.attributes = make(map[int64]spatial.EdgeAttributes, attributedNum)
+	for i := uint64(0); i < attributedNum && sr.err == nil; i++ {
+		edgeID := sr.int64()
+		attributesNum := sr.count()
+		attributes := make(spatial.EdgeAttributes, attributesNum)
+		for j := uint64(0); j < attributesNum && sr.err == nil; j++ {
+			name := sr.string()
+			attributes[name] = sr.attribute()
+		}
+		engine.attributes[edgeID] = attributes
+	}
+
+	// Facilities are not saved
+	engine.facilitiesMu.Lock()
+	engine.facilities = nil
+	engine.facilitiesMu.Unlock()
+	return sr.err
+}
+
+// snapshotWriter Writes little-endian values and evaluates checksum. The first error is kept and every next write is skipped
+type snapshotWriter struct {
+	w   *bufio.Writer
+	crc hash.Hash32
+	buf [8]byte
+	err error
+}
+
+func newSnapshotWriter(w io.Writer) *snapshotWriter {
+	crc := crc32.NewIEEE()
+	return &snapshotWriter{
+		w:   bufio.NewWriterSize(io.MultiWriter(w, crc), 1<<20),
+		crc: crc,
+	}
+}
+
+func (sw *snapshotWriter) bytes(data []byte) {
+	if sw.err != nil {
+		return
+	}
+	_, sw.err = sw.w.Write(data)
+}
+
+func (sw *snapshotWriter) uint8(v uint8) {
+	sw.buf[0] = v
+	sw.bytes(sw.buf[:1])
+}
+
+func (sw *snapshotWriter) bool(v bool) {
+	if v {
+		sw.uint8(1)
+		return
+	}
+	sw.uint8(0)
+}
+
+func (sw *snapshotWriter) uint32(v uint32) {
+	binary.LittleEndian.PutUint32(sw.buf[:4], v)
+	sw.bytes(sw.buf[:4])
+}
+
+func (sw *snapshotWriter) uint64(v uint64) {
+	binary.LittleEndian.PutUint64(sw.buf[:], v)
+	sw.bytes(sw.buf[:])
+}
+
+func (sw *snapshotWriter) int64(v int64) {
+	sw.uint64(uint64(v))
+}
+
+func (sw *snapshotWriter) float64(v float64) {
+	sw.uint64(math.Float64bits(v))
+}
+
+func (sw *snapshotWriter) string(v string) {
+	sw.uint64(uint64(len(v)))
+	sw.bytes([]byte(v))
+}
+
+// point Writes raw vector, so both spherical and Euclidean points are restored exactly
+func (sw *snapshotWriter) point(pt s2.Point) {
+	sw.float64(pt.X)
+	sw.float64(pt.Y)
+	sw.float64(pt.Z)
+}
+
+// attribute Writes type of attribute value followed by the value itself
+func (sw *snapshotWriter) attribute(value interface{}) {
+	switch v := value.(type) {
+	case int64:
+		sw.uint8(uint8(spatial.AttributeTypeInt))
+		sw.int64(v)
+	case float64:
+		sw.uint8(uint8(spatial.AttributeTypeFloat))
+		sw.float64(v)
+	case bool:
+		sw.uint8(uint8(spatial.AttributeTypeBool))
+		sw.bool(v)
+	default:
+		sw.uint8(uint8(spatial.AttributeTypeString))
+		sw.string(spatial.FormatAttributeValue(value))
+	}
+}
+
+func (sw *snapshotWriter) components(vertexComponent map[int64]int64) {
+	vertexIDs := make([]int64, 0, len(vertexComponent))
+	for vertexID := range vertexComponent {
+		vertexIDs = append(vertexIDs, vertexID)
+	}
+	sort.Slice(vertexIDs, func(i, j int) bool { return vertexIDs[i] < vertexIDs[j] })
+	sw.uint64(uint64(len(vertexIDs)))
+	for _, vertexID := range vertexIDs {
+		sw.int64(vertexID)
+		sw.int64(vertexComponent[vertexID])
+	}
+}
+
+// graph Writes vertices (in order of internal identifiers), edges and shortcuts of contraction hierarchies.
+/*
+	graph - contraction hierarchies
+	edges - original edges of the graph (see snapshotGraphEdges)
+
+	Shortcuts are not accessible directly, so they are exported to temporary CSV file first
+*/
+func (sw *snapshotWriter) graph(graph *ch.Graph, edges []snapshotGraphEdge) error {
+	if sw.err != nil {
+		return sw.err
+	}
+	tmpDir, err := os.MkdirTemp("", "horizon_snapshot")
+	if err != nil {
+		return errors.Wrap(err, "Can't create temporary directory")
+	}
+	defer os.RemoveAll(tmpDir)
+	shortcutsFname := filepath.Join(tmpDir, "shortcuts.csv")
+	err = graph.ExportShortcutsToFile(shortcutsFname)
+	if err != nil {
+		return errors.Wrap(err, "Can't export shortcuts")
+	}
+	shortcuts, err := readSnapshotShortcuts(shortcutsFname)
+	if err != nil {
+		return err
+	}
+
+	sw.uint64(uint64(len(graph.Vertices)))
+	for i := range graph.Vertices {
+		sw.int64(graph.Vertices[i].Label)
+		sw.int64(graph.Vertices[i].OrderPos())
+		sw.int64(int64(graph.Vertices[i].Importance()))
+	}
+	sw.uint64(uint64(len(edges)))
+	for _, edge := range edges {
+		sw.int64(edge.from)
+		sw.int64(edge.to)
+		sw.float64(edge.weight)
+	}
+	sw.uint64(uint64(len(shortcuts)))
+	for _, shortcut := range shortcuts {
+		sw.int64(shortcut.from)
+		sw.int64(shortcut.to)
+		sw.float64(shortcut.weight)
+		sw.int64(shortcut.via)
+	}
+	return sw.err
+}
+
+// finish Writes checksum of all previous data and flushes buffer
+func (sw *snapshotWriter) finish() error {
+	if sw.err != nil {
+		return sw.err
+	}
+	err := sw.w.Flush()
+	if err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(sw.buf[:4], sw.crc.Sum32())
+	sw.bytes(sw.buf[:4])
+	if sw.err != nil {
+		return sw.err
+	}
+	return sw.w.Flush()
+}
+
+// snapshotGraphEdge Edge or shortcut of contraction hierarchies. Via is not used for edges
+type snapshotGraphEdge struct {
+	from   int64
+	to     int64
+	weight float64
+	via    int64
+}
+
+// snapshotGraphEdges Returns original edges of contraction hierarchies the same way as they are added to the graph on loading.
+// Every edge is kept, even if there is shortcut between the same vertices (ch.Graph.ExportEdgesToFile skips such edges)
+/*
+	edges - edges of the engine sorted by identifiers
+	profile - additional weight profile. Nil for default profile: edges are taken with their own weights, otherwise edges missing in profile are skipped
+*/
+func snapshotGraphEdges(edges []*spatial.Edge, profile *weightProfile) []snapshotGraphEdge {
+	ans := make([]snapshotGraphEdge, 0, len(edges))
+	for _, edge := range edges {
+		weight := edge.Weight
+		if profile != nil {
+			var ok bool
+			weight, ok = profile.weights[edge.ID]
+			if !ok {
+				continue
+			}
+		}
+		ans = append(ans, snapshotGraphEdge{from: edge.Source, to: edge.Target, weight: weight})
+	}
+	return ans
+}
+
+// readSnapshotShortcuts Reads CSV file of shortcuts exported by ch library (see ch.Graph.ExportShortcutsToFile).
+// Shortcuts are sorted, so the same graph always gives the same snapshot
+func readSnapshotShortcuts(fname string) ([]snapshotGraphEdge, error) {
+	file, err := os.Open(fname)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't open '%s'", fname)
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.Comma = ';'
+	reader.FieldsPerRecord = 4
+	// Skip header
+	_, err = reader.Read()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't read header of '%s'", fname)
+	}
+	ans := []snapshotGraphEdge{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't read '%s'", fname)
+		}
+		shortcut := snapshotGraphEdge{}
+		shortcut.from, err = strconv.ParseInt(record[0], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't parse source vertex '%s' in '%s'", record[0], fname)
+		}
+		shortcut.to, err = strconv.ParseInt(record[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't parse target vertex '%s' in '%s'", record[1], fname)
+		}
+		shortcut.weight, err = strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't parse weight '%s' in '%s'", record[2], fname)
+		}
+		shortcut.via, err = strconv.ParseInt(record[3], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't parse via vertex '%s' in '%s'", record[3], fname)
+		}
+		ans = append(ans, shortcut)
+	}
+	sort.Slice(ans, func(i, j int) bool {
+		if ans[i].from != ans[j].from {
+			return ans[i].from < ans[j].from
+		}
+		return ans[i].to < ans[j].to
+	})
+	return ans, nil
+}
+
+// snapshotReader Reads little-endian values and evaluates checksum. The first error is kept and every next read returns zero value
+// remaining - number of bytes left unread (see count)
+type snapshotReader struct {
+	r         *bufio.Reader
+	crc       hash.Hash32
+	buf       [8]byte
+	remaining int64
+	err       error
+}
+
+func newSnapshotReader(r io.Reader, size int64) *snapshotReader {
+	return &snapshotReader{
+		r:         bufio.NewReaderSize(r, 1<<20),
+		crc:       crc32.NewIEEE(),
+		remaining: size,
+	}
+}
+
+func (sr *snapshotReader) read(data []byte) {
+	if sr.err != nil {
+		return
+	}
+	_, sr.err = io.ReadFull(sr.r, data)
+	if sr.err == io.EOF || sr.err == io.ErrUnexpectedEOF {
+		sr.err = errors.Wrap(ErrSnapshotFormat, "unexpected end of file")
+		return
+	}
+	sr.crc.Write(data)
+	sr.remaining -= int64(len(data))
+}
+
+func (sr *snapshotReader) bytes(n int) []byte {
+	data := make([]byte, n)
+	sr.read(data)
+	return data
+}
+
+func (sr *snapshotReader) uint8() uint8 {
+	sr.read(sr.buf[:1])
+	if sr.err != nil {
+		return 0
+	}
+	return sr.buf[0]
+}
+
+func (sr *snapshotReader) bool() bool {
+	return sr.uint8() != 0
+}
+
+func (sr *snapshotReader) uint32() uint32 {
+	sr.read(sr.buf[:4])
+	if sr.err != nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(sr.buf[:4])
+}
+
+func (sr *snapshotReader) uint64() uint64 {
+	sr.read(sr.buf[:])
+	if sr.err != nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(sr.buf[:])
+}
+
+func (sr *snapshotReader) int64() int64 {
+	return int64(sr.uint64())
+}
+
+func (sr *snapshotReader) float64() float64 {
+	return math.Float64frombits(sr.uint64())
+}
+
+// count Reads number of elements in section.
+// Every element takes at least one byte, so number greater than number of bytes left means corrupted file: it protects from huge allocations
+func (sr *snapshotReader) count() uint64 {
+	n := sr.uint64()
+	if sr.err == nil && (sr.remaining < 0 || n > uint64(sr.remaining)) {
+		sr.err = errors.Wrapf(ErrSnapshotFormat, "invalid number of elements %d", n)
+		return 0
+	}
+	return n
+}
+
+func (sr *snapshotReader) string() string {
+	n := sr.count()
+	if n > 1<<16 {
+		sr.err = errors.Wrapf(ErrSnapshotFormat, "invalid length of string %d", n)
+		return ""
+	}
+	return string(sr.bytes(int(n)))
+}
+
+func (sr *snapshotReader) point() s2.Point {
+	x := sr.float64()
+	y := sr.float64()
+	z := sr.float64()
+	return s2.Point{Vector: r3.Vector{X: x, Y: y, Z: z}}
+}
+
+func (sr *snapshotReader) attribute() interface{} {
+	attributeType := spatial.AttributeType(sr.uint8())
+	switch attributeType {
+	case spatial.AttributeTypeInt:
+		return sr.int64()
+	case spatial.AttributeTypeFloat:
+		return sr.float64()
+	case spatial.AttributeTypeBool:
+		return sr.bool()
+	case spatial.AttributeTypeString:
+		return sr.string()
+	}
+	if sr.err == nil {
+		sr.err = errors.Wrapf(ErrSnapshotFormat, "unknown attribute type %d", attributeType)
+	}
+	return nil
+}
+
+func (sr *snapshotReader) components() map[int64]int64 {
+	n := sr.count()
+	ans := make(map[int64]int64)
+	for i := uint64(0); i < n && sr.err == nil; i++ {
+		vertexID := sr.int64()
+		ans[vertexID] = sr.int64()
+	}
+	return ans
+}
+
+// graph Restores contraction hierarchies the same way as CSV loader does: vertices, edges, order of vertices and then shortcuts
+func (sr *snapshotReader) graph() (*ch.Graph, error) {
+	graph := ch.NewGraph()
+	type vertexOrder struct {
+		label      int64
+		orderPos   int64
+		importance int64
+	}
+	verticesNum := sr.count()
+	vertices := make([]vertexOrder, 0, verticesNum)
+	for i := uint64(0); i < verticesNum && sr.err == nil; i++ {
+		vertex := vertexOrder{label: sr.int64(), orderPos: sr.int64(), importance: sr.int64()}
+		if sr.err != nil {
+			break
+		}
+		err := graph.CreateVertex(vertex.label)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't add vertex %d", vertex.label)
+		}
+		vertices = append(vertices, vertex)
+	}
+	edgesNum := sr.count()
+	for i := uint64(0); i < edgesNum && sr.err == nil; i++ {
+		from, to, weight := sr.int64(), sr.int64(), sr.float64()
+		if sr.err != nil {
+			break
+		}
+		err := graph.AddEdge(from, to, weight)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't add edge from %d to %d", from, to)
+		}
+	}
+	for _, vertex := range vertices {
+		idx, ok := graph.FindVertex(vertex.label)
+		if !ok {
+			return nil, errors.Wrapf(ErrSnapshotFormat, "vertex %d is not found in graph", vertex.label)
+		}
+		graph.Vertices[idx].SetOrderPos(vertex.orderPos)
+		graph.Vertices[idx].SetImportance(int(vertex.importance))
+	}
+	shortcutsNum := sr.count()
+	for i := uint64(0); i < shortcutsNum && sr.err == nil; i++ {
+		from, to, weight, via := sr.int64(), sr.int64(), sr.float64(), sr.int64()
+		if sr.err != nil {
+			break
+		}
+		err := graph.AddEdge(from, to, weight)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't add shortcut from %d to %d", from, to)
+		}
+		err = graph.AddShortcut(from, to, via, weight)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't add shortcut from %d to %d via %d", from, to, via)
+		}
+	}
+	if sr.err != nil {
+		return nil, sr.err
+	}
+	graph.FinalizeImport()
+	return graph, nil
+}
+
+// finish Reads checksum and compares it with evaluated one. There must be no data after checksum
+func (sr *snapshotReader) finish() error {
+	if sr.err != nil {
+		return sr.err
+	}
+	expected := sr.crc.Sum32()
+	_, err := io.ReadFull(sr.r, sr.buf[:4])
+	if err != nil {
+		return errors.Wrap(ErrSnapshotFormat, "checksum is missing")
+	}
+	if binary.LittleEndian.Uint32(sr.buf[:4]) != expected {
+		return ErrSnapshotChecksum
+	}
+	if _, err := sr.r.ReadByte(); err != io.EOF {
+		return errors.Wrap(ErrSnapshotFormat, "unexpected data after checksum")
+	}
+	return nil
+}
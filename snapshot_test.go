@@ -0,0 +1,333 @@
+package horizon
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/LdDl/ch"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+func TestSnapshotEuclideanRoundTrip(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	WithEdgeAttributes(map[int64]spatial.EdgeAttributes{
+		1: {"highway": "primary", "maxspeed": int64(60), "lanes": 2.5, "lit": true},
+		4: {"highway": "service"},
+	})(matcher.engine)
+	path := filepath.Join(t.TempDir(), "graph.snapshot")
+	err := matcher.SaveSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := NewMapMatcherFromSnapshot(NewHmmProbabilities(50.0, 30.0), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restored.engine.isEuclidean() {
+		t.Fatalf("Restored engine should use euclidean storage, but got %T", restored.engine.storage)
+	}
+	if !reflect.DeepEqual(matcher.engine.edges, restored.engine.edges) {
+		t.Errorf("Edges of restored engine differ from the original ones")
+	}
+	if !reflect.DeepEqual(matcher.engine.vertices, restored.engine.vertices) {
+		t.Errorf("Vertices of restored engine differ from the original ones")
+	}
+	if !reflect.DeepEqual(matcher.Profiles(), restored.Profiles()) {
+		t.Errorf("Profiles should be %v, but got %v", matcher.Profiles(), restored.Profiles())
+	}
+	if !reflect.DeepEqual(matcher.engine.attributes, restored.engine.attributes) || !reflect.DeepEqual(matcher.engine.AttributeNames(), restored.engine.AttributeNames()) {
+		t.Errorf("Edge attributes of restored engine differ from the original ones")
+	}
+
+	point := NewGPSMeasurementFromID(1, 4.5, 0.3, 0)
+	expectedNearest, err := matcher.Nearest(point, 3, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nearest, err := restored.Nearest(point, 3, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nearest) != len(expectedNearest) {
+		t.Fatalf("Expected %d nearest edges, but got %d", len(expectedNearest), len(nearest))
+	}
+	for i := range nearest {
+		if nearest[i].Edge.ID != expectedNearest[i].Edge.ID {
+			t.Errorf("Nearest edge #%d should be %d, but got %d", i, expectedNearest[i].Edge.ID, nearest[i].Edge.ID)
+		}
+	}
+
+	source := NewGPSMeasurementFromID(1, -4.9, 0.1, 0)
+	target := NewGPSMeasurementFromID(2, 14.9, 0.1, 0)
+	eps := 1e-9
+	for _, profile := range matcher.Profiles() {
+		expected, err := matcher.FindShortestPath(source, target, -1, WithProfile(profile))
+		if err != nil {
+			t.Fatalf("Profile '%s': %v", profile, err)
+		}
+		result, err := restored.FindShortestPath(source, target, -1, WithProfile(profile))
+		if err != nil {
+			t.Fatalf("Profile '%s': %v", profile, err)
+		}
+		expectedNext := expected.SubMatches[0].Observations[0].NextEdges
+		next := result.SubMatches[0].Observations[0].NextEdges
+		if len(next) != len(expectedNext) {
+			t.Errorf("Profile '%s': expected %d intermediate edges, but got %d", profile, len(expectedNext), len(next))
+			continue
+		}
+		for i := range next {
+			if next[i].ID != expectedNext[i].ID || math.Abs(next[i].Weight-expectedNext[i].Weight) > eps {
+				t.Errorf("Profile '%s': intermediate edge #%d should be %d (%f), but got %d (%f)", profile, i, expectedNext[i].ID, expectedNext[i].Weight, next[i].ID, next[i].Weight)
+			}
+		}
+	}
+}
+
+func TestSnapshotSphericalRoundTrip(t *testing.T) {
+	hmmParams := NewHmmProbabilities(50.0, 2.0)
+	matcher, err := NewMapMatcherFromFiles(hmmParams, "./test_data/matcher_4326_test.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "graph.snapshot")
+	err = matcher.SaveSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := NewMapMatcherFromSnapshot(hmmParams, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(matcher.engine.vertexComponent, restored.engine.vertexComponent) || matcher.engine.bigComponentID != restored.engine.bigComponentID {
+		t.Errorf("Weakly connected components of restored engine differ from the original ones")
+	}
+	if !reflect.DeepEqual(matcher.engine.vertexStrongComponent, restored.engine.vertexStrongComponent) || matcher.engine.bigStrongComponentID != restored.engine.bigStrongComponentID {
+		t.Errorf("Strongly connected components of restored engine differ from the original ones")
+	}
+	if !reflect.DeepEqual(matcher.engine.isComponentVerySmall, restored.engine.isComponentVerySmall) {
+		t.Errorf("Small components of restored engine differ from the original ones")
+	}
+
+	gpsMeasurements := GPSMeasurements{
+		NewGPSMeasurementFromID(1, 37.662745994981435, 55.77323867786974, 4326),
+		NewGPSMeasurementFromID(2, 37.66373679411533, 55.77352528537278, 4326),
+		NewGPSMeasurementFromID(3, 37.6634658408828, 55.77408712095024, 4326),
+		NewGPSMeasurementFromID(4, 37.66271768643477, 55.77491052526131, 4326),
+	}
+	expected, err := matcher.Run(gpsMeasurements, 7.0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := restored.Run(gpsMeasurements, 7.0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.SubMatches) != len(expected.SubMatches) {
+		t.Fatalf("Expected %d sub-matches, but got %d", len(expected.SubMatches), len(result.SubMatches))
+	}
+	eps := 1e-6
+	for s := range result.SubMatches {
+		if math.Abs(result.SubMatches[s].Probability-expected.SubMatches[s].Probability) > eps {
+			t.Errorf("Probability of sub-match #%d should be %f, but got %f", s, expected.SubMatches[s].Probability, result.SubMatches[s].Probability)
+		}
+		for i, observation := range result.SubMatches[s].Observations {
+			expectedEdgeID := expected.SubMatches[s].Observations[i].MatchedEdge.ID
+			if observation.MatchedEdge.ID != expectedEdgeID {
+				t.Errorf("Observation #%d of sub-match #%d should be matched to edge %d, but got %d", i, s, expectedEdgeID, observation.MatchedEdge.ID)
+			}
+		}
+	}
+}
+
+func TestSnapshotCorrupted(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "graph.snapshot")
+	err := matcher.SaveSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		modify   func([]byte) []byte
+		expected error
+	}{
+		{"flipped byte", func(d []byte) []byte { d[len(d)/2] ^= 0xFF; return d }, nil},
+		{"bad magic", func(d []byte) []byte { d[0] = 'X'; return d }, ErrSnapshotFormat},
+		{"unknown version", func(d []byte) []byte { d[len(snapshotMagic)] = SNAPSHOT_VERSION + 1; return d }, ErrSnapshotVersion},
+		{"truncated", func(d []byte) []byte { return d[:len(d)-10] }, ErrSnapshotFormat},
+		{"trailing data", func(d []byte) []byte { return append(d, 0) }, ErrSnapshotFormat},
+		{"huge number of points", func(d []byte) []byte {
+			// Header (magic, version, storage type, level, exact distances, SRID and ellipsoidal flag), number of edges and fields of the first edge come first
+			binary.LittleEndian.PutUint64(d[len(snapshotMagic)+4+1+8+1+8+1+8+4*8:], 1<<39)
+			return d
+		}, ErrSnapshotFormat},
+	}
+	for _, c := range cases {
+		corrupted := c.modify(append([]byte{}, data...))
+		corruptedPath := filepath.Join(dir, "corrupted.snapshot")
+		err = os.WriteFile(corruptedPath, corrupted, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = NewMapMatcherFromSnapshot(NewHmmProbabilities(50.0, 30.0), corruptedPath)
+		if err == nil {
+			t.Errorf("Case '%s': expected error, but got nil", c.name)
+			continue
+		}
+		if c.expected != nil && errors.Cause(err) != c.expected {
+			t.Errorf("Case '%s': expected error '%v', but got '%v'", c.name, c.expected, err)
+		}
+	}
+}
+
+func TestSnapshotEdgeWithShortcut(t *testing.T) {
+	// Edge 1 -> 3 is cheaper than shortcut 1 -> 3 via 2, so both of them must survive the round trip
+	points := map[int64]s2.Point{
+		1: spatial.NewEuclideanS2Point(0, 0),
+		2: spatial.NewEuclideanS2Point(1, 1),
+		3: spatial.NewEuclideanS2Point(2, 0),
+	}
+	edges := []*spatial.Edge{}
+	for _, e := range [][3]int64{{1, 2, 15}, {2, 3, 15}, {1, 3, 20}} {
+		polyline := s2.Polyline{points[e[0]], points[e[1]]}
+		edges = append(edges, &spatial.Edge{ID: int64(len(edges) + 1), Source: e[0], Target: e[1], Weight: float64(e[2]) / 10, Polyline: &polyline})
+	}
+	engine, err := NewMapEngineBuilder(WithGraphSRID(0)).AddEdges(edges...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph := ch.NewGraph()
+	for _, vertexID := range []int64{1, 2, 3} {
+		err = graph.CreateVertex(vertexID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, edge := range edges {
+		err = graph.AddEdge(edge.Source, edge.Target, edge.Weight)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for vertexID, orderPos := range map[int64]int64{2: 0, 1: 1, 3: 2} {
+		idx, _ := graph.FindVertex(vertexID)
+		graph.Vertices[idx].SetOrderPos(orderPos)
+	}
+	err = graph.AddEdge(1, 3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = graph.AddShortcut(1, 3, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph.FinalizeImport()
+	engine.graph = *graph
+	engine.queryPool = engine.graph.NewQueryPool()
+
+	path := filepath.Join(t.TempDir(), "graph.snapshot")
+	err = engine.SaveSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewMapEngine()
+	err = restored.LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedEdges := engine.graph.GetEdgesNum() - engine.graph.GetShortcutsNum()
+	if edgesNum := restored.graph.GetEdgesNum() - restored.graph.GetShortcutsNum(); edgesNum != expectedEdges {
+		t.Errorf("Restored graph should have %d edges, but got %d", expectedEdges, edgesNum)
+	}
+	if restored.graph.GetShortcutsNum() != engine.graph.GetShortcutsNum() {
+		t.Errorf("Restored graph should have %d shortcuts, but got %d", engine.graph.GetShortcutsNum(), restored.graph.GetShortcutsNum())
+	}
+	expectedCost, _ := engine.queryPool.ShortestPath(1, 3)
+	cost, _ := restored.queryPool.ShortestPath(1, 3)
+	if math.Abs(expectedCost-2) > 1e-9 || math.Abs(cost-expectedCost) > 1e-9 {
+		t.Errorf("Cost of path from 1 to 3 should be 2 before and after snapshot, but got %f and %f", expectedCost, cost)
+	}
+}
+
+func TestSnapshotGraphSRID(t *testing.T) {
+	dir := t.TempDir()
+	hmmParams := NewHmmProbabilities(50.0, 30.0)
+
+	// SRID of Euclidean graph is restored without any option
+	euclideanPath := filepath.Join(dir, "euclidean.snapshot")
+	err := prepareProfilesMatcher(t).SaveSnapshot(euclideanPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := NewMapMatcherFromSnapshot(hmmParams, euclideanPath, WithEllipsoidalDistances())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.engine.graphSRID != 0 {
+		t.Errorf("SRID of restored Euclidean graph should be 0, but got %d", restored.engine.graphSRID)
+	}
+
+	// SRID and model of distances of projected graph are restored too
+	edges := []*spatial.Edge{}
+	for i, lon := range []float64{37.60, 37.61, 37.62} {
+		polyline := s2.Polyline{s2.PointFromLatLng(s2.LatLngFromDegrees(55.75, lon)), s2.PointFromLatLng(s2.LatLngFromDegrees(55.75, lon+0.01))}
+		edges = append(edges, &spatial.Edge{ID: int64(i + 1), Source: int64(i + 1), Target: int64(i + 2), Weight: 1, Polyline: &polyline})
+	}
+	projected, err := NewMapEngineBuilder(WithGraphSRID(3857), WithEllipsoidalDistances()).AddEdges(edges...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectedPath := filepath.Join(dir, "projected.snapshot")
+	err = projected.SaveSnapshot(projectedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err = NewMapMatcherFromSnapshot(hmmParams, projectedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.engine.graphSRID != 3857 || !restored.engine.IsEllipsoidal() {
+		t.Errorf("Restored graph should have SRID 3857 and ellipsoidal distances, but got %d and %t", restored.engine.graphSRID, restored.engine.IsEllipsoidal())
+	}
+	if _, err = NewMapMatcherFromSnapshot(hmmParams, projectedPath, WithGraphSRID(3857), WithEllipsoidalDistances()); err != nil {
+		t.Errorf("Options matching snapshot should be accepted, but got %v", err)
+	}
+
+	spherical, err := NewMapEngineBuilder().AddEdges(edges...).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sphericalPath := filepath.Join(dir, "spherical.snapshot")
+	err = spherical.SaveSnapshot(sphericalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conflicts := []struct {
+		name string
+		path string
+		opts []func(*MapEngine)
+	}{
+		{"other SRID", projectedPath, []func(*MapEngine){WithGraphSRID(4326)}},
+		{"planar SRID", euclideanPath, []func(*MapEngine){WithGraphSRID(32637)}},
+		{"ellipsoidal distances", sphericalPath, []func(*MapEngine){WithEllipsoidalDistances()}},
+		{"missing profile", euclideanPath, []func(*MapEngine){WithWeightProfiles("travel_time", "bus_time")}},
+	}
+	for _, c := range conflicts {
+		_, err = NewMapMatcherFromSnapshot(hmmParams, c.path, c.opts...)
+		if errors.Cause(err) != ErrSnapshotOptions {
+			t.Errorf("Case '%s': expected error '%v', but got '%v'", c.name, ErrSnapshotOptions, err)
+		}
+	}
+}
@@ -0,0 +1,443 @@
+package spatial
+
+import (
+	"math"
+	"sort"
+)
+
+// delaunayTriangle Triangle of Delaunay triangulation with its circumcircle.
+// Adjacent triangles are kept for edges ab, bc and ca (-1 if there is no one), removed triangles stay in place until the end
+type delaunayTriangle struct {
+	a, b, c  int
+	cx, cy   float64
+	r2       float64
+	adjacent [3]int
+	removed  bool
+}
+
+// newDelaunayTriangle Returns counterclockwise oriented triangle with precalculated circumcircle
+func newDelaunayTriangle(points [][2]float64, a, b, c int) delaunayTriangle {
+	pa, pb, pc := points[a], points[b], points[c]
+	if orientation(pa[0], pa[1], pb[0], pb[1], pc[0], pc[1]) < 0 {
+		b, c = c, b
+	}
+	return newOrientedTriangle(points, a, b, c)
+}
+
+// newOrientedTriangle Returns triangle with precalculated circumcircle keeping order of vertices
+func newOrientedTriangle(points [][2]float64, a, b, c int) delaunayTriangle {
+	pa, pb, pc := points[a], points[b], points[c]
+	t := delaunayTriangle{a: a, b: b, c: c, adjacent: [3]int{-1, -1, -1}}
+	d := 2 * (pa[0]*(pb[1]-pc[1]) + pb[0]*(pc[1]-pa[1]) + pc[0]*(pa[1]-pb[1]))
+	if d == 0 {
+		// Degenerate (collinear) triangle: every point is inside of its circumcircle
+		t.r2 = math.Inf(1)
+		return t
+	}
+	sa := pa[0]*pa[0] + pa[1]*pa[1]
+	sb := pb[0]*pb[0] + pb[1]*pb[1]
+	sc := pc[0]*pc[0] + pc[1]*pc[1]
+	t.cx = (sa*(pb[1]-pc[1]) + sb*(pc[1]-pa[1]) + sc*(pa[1]-pb[1])) / d
+	t.cy = (sa*(pc[0]-pb[0]) + sb*(pa[0]-pc[0]) + sc*(pb[0]-pa[0])) / d
+	t.r2 = (pa[0]-t.cx)*(pa[0]-t.cx) + (pa[1]-t.cy)*(pa[1]-t.cy)
+	return t
+}
+
+// vertices Returns vertices of triangle in counterclockwise order: edge i goes from vertex i to vertex i+1
+func (t *delaunayTriangle) vertices() [3]int {
+	return [3]int{t.a, t.b, t.c}
+}
+
+// inCircumcircle Checks if point is strictly inside of circumcircle
+func (t *delaunayTriangle) inCircumcircle(pt [2]float64) bool {
+	dx, dy := pt[0]-t.cx, pt[1]-t.cy
+	return dx*dx+dy*dy < t.r2
+}
+
+// cavityEdge Boundary edge of cavity: it goes counterclockwise around the cavity
+/*
+	from, to - vertices of edge
+	inner - removed triangle of cavity
+	outer - kept triangle on the other side of edge (-1 if there is no one)
+*/
+type cavityEdge struct {
+	from, to     int
+	inner, outer int
+}
+
+// DelaunayTriangulation Returns Delaunay triangulation of planar points (Bowyer-Watson algorithm)
+/*
+	points - planar points as [x, y]. Duplicates are allowed, but only one of them is used
+
+	Returns triangles as triplets of indices in points (counterclockwise oriented).
+	Triangle containing the next point is found by walk from the last created triangle (points are inserted bin by bin, so walks are short)
+	and cavity of the point is collected via adjacent triangles, hence expected time is close to O(n log n)
+*/
+func DelaunayTriangulation(points [][2]float64) [][3]int {
+	n := len(points)
+	if n < 3 {
+		return [][3]int{}
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, pt := range points {
+		minX, maxX = math.Min(minX, pt[0]), math.Max(maxX, pt[0])
+		minY, maxY = math.Min(minY, pt[1]), math.Max(maxY, pt[1])
+	}
+	delta := math.Max(maxX-minX, maxY-minY)
+	if delta == 0 {
+		return [][3]int{}
+	}
+	midX, midY := (minX+maxX)/2, (minY+maxY)/2
+
+	// Working set of points: input points and vertices of super triangle
+	work := make([][2]float64, n, n+3)
+	copy(work, points)
+	work = append(work,
+		[2]float64{midX - 20*delta, midY - delta},
+		[2]float64{midX, midY + 20*delta},
+		[2]float64{midX + 20*delta, midY - delta},
+	)
+	triangles := make([]delaunayTriangle, 0, 6*n)
+	triangles = append(triangles, newDelaunayTriangle(work, n, n+1, n+2))
+
+	// Insert points bin by bin (rows of bins are traversed in alternating directions): it keeps newly created triangles close to each other
+	bins := int(math.Max(1, math.Ceil(math.Sqrt(float64(n)/2))))
+	binOf := make([]int, n)
+	for i, pt := range points {
+		row := int(float64(bins-1) * (pt[1] - minY) / delta)
+		col := int(float64(bins-1) * (pt[0] - minX) / delta)
+		if row%2 == 1 {
+			col = bins - 1 - col
+		}
+		binOf[i] = row*bins + col
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		pi, pj := points[order[i]], points[order[j]]
+		if binOf[order[i]] != binOf[order[j]] {
+			return binOf[order[i]] < binOf[order[j]]
+		}
+		if pi[0] == pj[0] {
+			return pi[1] < pj[1]
+		}
+		return pi[0] < pj[0]
+	})
+	last := 0
+	cavity := []int{}
+	boundary := []cavityEdge{}
+	startsAt := make(map[int]int)
+	endsAt := make(map[int]int)
+	for k, idx := range order {
+		if k > 0 && points[order[k-1]] == points[idx] {
+			continue
+		}
+		pt := work[idx]
+		// Triangles which circumcircle contains the point form connected cavity around the triangle containing the point
+		located := locateTriangle(work, triangles, last, pt)
+		triangles[located].removed = true
+		cavity = append(cavity[:0], located)
+		boundary = boundary[:0]
+		for i := 0; i < len(cavity); i++ {
+			t := &triangles[cavity[i]]
+			vertices := t.vertices()
+			for e, adjacent := range t.adjacent {
+				if adjacent >= 0 && triangles[adjacent].removed {
+					continue
+				}
+				if adjacent >= 0 && triangles[adjacent].inCircumcircle(pt) {
+					triangles[adjacent].removed = true
+					cavity = append(cavity, adjacent)
+					continue
+				}
+				boundary = append(boundary, cavityEdge{from: vertices[e], to: vertices[(e+1)%3], inner: cavity[i], outer: adjacent})
+			}
+		}
+
+		// Connect the point with every boundary edge of cavity
+		first := len(triangles)
+		clear(startsAt)
+		clear(endsAt)
+		for _, e := range boundary {
+			created := len(triangles)
+			t := newOrientedTriangle(work, e.from, e.to, idx)
+			t.adjacent[0] = e.outer
+			triangles = append(triangles, t)
+			if e.outer >= 0 {
+				outer := &triangles[e.outer]
+				for j := range outer.adjacent {
+					if outer.adjacent[j] == e.inner {
+						outer.adjacent[j] = created
+					}
+				}
+			}
+			startsAt[e.from] = created
+			endsAt[e.to] = created
+		}
+		for i := first; i < len(triangles); i++ {
+			t := &triangles[i]
+			t.adjacent[1] = startsAt[t.b]
+			t.adjacent[2] = endsAt[t.a]
+		}
+		last = len(triangles) - 1
+	}
+
+	ans := make([][3]int, 0, 2*n)
+	for _, t := range triangles {
+		if t.removed || t.a >= n || t.b >= n || t.c >= n {
+			continue
+		}
+		ans = append(ans, [3]int{t.a, t.b, t.c})
+	}
+	return ans
+}
+
+// locateTriangle Returns kept triangle containing the point (or having it on the edge)
+/*
+	points - points of triangulation
+	triangles - triangles of triangulation
+	start - kept triangle to start walk from
+
+	Walk crosses edges which separate current triangle from the point. It always ends in Delaunay triangulation,
+	but rounding errors could make it cycle: then triangle is found by circumcircles
+*/
+func locateTriangle(points [][2]float64, triangles []delaunayTriangle, start int, pt [2]float64) int {
+	current := start
+	for steps := 0; steps < len(triangles); steps++ {
+		t := &triangles[current]
+		vertices := t.vertices()
+		next := -1
+		for e, adjacent := range t.adjacent {
+			from, to := points[vertices[e]], points[vertices[(e+1)%3]]
+			if adjacent >= 0 && orientation(from[0], from[1], to[0], to[1], pt[0], pt[1]) < 0 {
+				next = adjacent
+				break
+			}
+		}
+		if next < 0 {
+			return current
+		}
+		current = next
+	}
+	for i := range triangles {
+		if !triangles[i].removed && triangles[i].inCircumcircle(pt) {
+			return i
+		}
+	}
+	return start
+}
+
+// AlphaShape Returns polygons of alpha shape (concave hull) for set of planar points
+/*
+	points - planar points as [x, y]
+	alpha - max circumradius of Delaunay triangles forming the shape: bigger value gives smoother shape (up to convex hull).
+		Use 0 (or negative value) to derive it from the points density (see DefaultAlpha)
+
+	Returns set of polygons: the first ring of each polygon is outer one (counterclockwise), others are holes (clockwise).
+	Every ring is closed: the last point is equal to the first one
+*/
+func AlphaShape(points [][2]float64, alpha float64) [][][][2]float64 {
+	triangles := DelaunayTriangulation(points)
+	if alpha <= 0 {
+		alpha = DefaultAlpha(points, triangles)
+	}
+	kept := make([][3]int, 0, len(triangles))
+	for _, t := range triangles {
+		if Circumradius(points, t) <= alpha {
+			kept = append(kept, t)
+		}
+	}
+	return TrianglesToPolygons(points, kept)
+}
+
+// TrianglesToPolygons Returns polygons covering union of triangles
+/*
+	points - planar points as [x, y]
+	triangles - counterclockwise oriented triangles (as DelaunayTriangulation returns) which do not overlap each other
+
+	Returns set of polygons (bigger ones go first): the first ring of each polygon is outer one (counterclockwise), others are holes (clockwise).
+	Every ring is closed: the last point is equal to the first one
+*/
+func TrianglesToPolygons(points [][2]float64, triangles [][3]int) [][][][2]float64 {
+	// Directed edges of kept triangles
+	directed := make(map[[2]int]struct{})
+	for _, t := range triangles {
+		directed[[2]int{t[0], t[1]}] = struct{}{}
+		directed[[2]int{t[1], t[2]}] = struct{}{}
+		directed[[2]int{t[2], t[0]}] = struct{}{}
+	}
+	// Boundary edges are the ones which have no opposite edge: interior of the shape is on the left side of every boundary edge
+	outgoing := make(map[int][]int)
+	boundaryEdges := 0
+	for e := range directed {
+		if _, ok := directed[[2]int{e[1], e[0]}]; ok {
+			continue
+		}
+		outgoing[e[0]] = append(outgoing[e[0]], e[1])
+		boundaryEdges++
+	}
+	if boundaryEdges == 0 {
+		return [][][][2]float64{}
+	}
+	// Make tracing deterministic
+	starts := make([]int, 0, len(outgoing))
+	for v := range outgoing {
+		sort.Ints(outgoing[v])
+		starts = append(starts, v)
+	}
+	sort.Ints(starts)
+
+	outers := [][][2]float64{}
+	holes := [][][2]float64{}
+	for _, start := range starts {
+		for len(outgoing[start]) > 0 {
+			ring := traceRing(points, outgoing, start)
+			if len(ring) < 4 {
+				continue
+			}
+			if RingSignedArea(ring) > 0 {
+				outers = append(outers, ring)
+			} else {
+				holes = append(holes, ring)
+			}
+		}
+	}
+	// Sort outer rings by area so each hole goes to the smallest containing ring
+	sort.SliceStable(outers, func(i, j int) bool {
+		return RingSignedArea(outers[i]) < RingSignedArea(outers[j])
+	})
+	polygons := make([][][][2]float64, len(outers))
+	for i := range outers {
+		polygons[i] = [][][2]float64{outers[i]}
+	}
+	for _, hole := range holes {
+		for i := range outers {
+			if ringContainsRing(outers[i], hole) {
+				polygons[i] = append(polygons[i], hole)
+				break
+			}
+		}
+	}
+	// Bigger polygons first
+	for l, r := 0, len(polygons)-1; l < r; l, r = l+1, r-1 {
+		polygons[l], polygons[r] = polygons[r], polygons[l]
+	}
+	return polygons
+}
+
+// DefaultAlpha Returns alpha parameter derived from triangulation: three medians of triangles circumradius
+func DefaultAlpha(points [][2]float64, triangles [][3]int) float64 {
+	if len(triangles) == 0 {
+		return 0
+	}
+	radiuses := make([]float64, len(triangles))
+	for i, t := range triangles {
+		radiuses[i] = Circumradius(points, t)
+	}
+	sort.Float64s(radiuses)
+	return 3 * radiuses[len(radiuses)/2]
+}
+
+// traceRing Traces closed ring of boundary edges starting from given vertex. Used edges are removed from outgoing.
+// When vertex has several outgoing edges (shapes touching each other at single point) the one keeping the same interior is chosen:
+// it is the first edge met by clockwise rotation from the reversed incoming edge
+func traceRing(points [][2]float64, outgoing map[int][]int, start int) [][2]float64 {
+	ring := [][2]float64{points[start]}
+	prev := start
+	cur := popEdge(points, outgoing, start, -1)
+	for steps := 0; cur != -1; steps++ {
+		ring = append(ring, points[cur])
+		if cur == start || steps > len(points)*3 {
+			break
+		}
+		next := popEdge(points, outgoing, cur, prev)
+		prev, cur = cur, next
+	}
+	if ring[len(ring)-1] != ring[0] {
+		// Unclosed chain could appear only for invalid input
+		return nil
+	}
+	return ring
+}
+
+// popEdge Removes and returns target of outgoing edge for vertex v (see traceRing). Returns -1 if there are no outgoing edges
+func popEdge(points [][2]float64, outgoing map[int][]int, v, prev int) int {
+	targets := outgoing[v]
+	if len(targets) == 0 {
+		return -1
+	}
+	best := 0
+	if prev != -1 && len(targets) > 1 {
+		bx, by := points[prev][0]-points[v][0], points[prev][1]-points[v][1]
+		bestAngle := math.Inf(1)
+		for i, target := range targets {
+			tx, ty := points[target][0]-points[v][0], points[target][1]-points[v][1]
+			// Counterclockwise angle from back direction to the target direction
+			ccw := math.Atan2(bx*ty-by*tx, bx*tx+by*ty)
+			cw := -ccw
+			if cw <= 0 {
+				cw += 2 * math.Pi
+			}
+			if cw < bestAngle {
+				bestAngle = cw
+				best = i
+			}
+		}
+	}
+	target := targets[best]
+	outgoing[v] = append(targets[:best], targets[best+1:]...)
+	return target
+}
+
+// Circumradius Returns radius of circumcircle for the triangle
+func Circumradius(points [][2]float64, t [3]int) float64 {
+	a, b, c := points[t[0]], points[t[1]], points[t[2]]
+	ab := math.Hypot(a[0]-b[0], a[1]-b[1])
+	bc := math.Hypot(b[0]-c[0], b[1]-c[1])
+	ca := math.Hypot(c[0]-a[0], c[1]-a[1])
+	area2 := math.Abs(orientation(a[0], a[1], b[0], b[1], c[0], c[1]))
+	if area2 == 0 {
+		return math.Inf(1)
+	}
+	return ab * bc * ca / (2 * area2)
+}
+
+// RingSignedArea Returns signed area of the ring (positive for counterclockwise orientation)
+func RingSignedArea(ring [][2]float64) float64 {
+	area := 0.0
+	for i := 0; i+1 < len(ring); i++ {
+		area += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	if len(ring) > 0 && ring[0] != ring[len(ring)-1] {
+		last := len(ring) - 1
+		area += ring[last][0]*ring[0][1] - ring[0][0]*ring[last][1]
+	}
+	return area / 2
+}
+
+// RingContainsPoint Checks if point is inside of the ring (ray casting)
+func RingContainsPoint(ring [][2]float64, pt [2]float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > pt[1]) != (yj > pt[1]) && pt[0] < (xj-xi)*(pt[1]-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// ringContainsRing Checks if inner ring is inside of outer one. Rings could share vertices, so middle points of inner ring's edges are checked
+func ringContainsRing(outer, inner [][2]float64) bool {
+	for i := 0; i+1 < len(inner); i++ {
+		mid := [2]float64{(inner[i][0] + inner[i+1][0]) / 2, (inner[i][1] + inner[i+1][1]) / 2}
+		if !RingContainsPoint(outer, mid) {
+			return false
+		}
+	}
+	return true
+}
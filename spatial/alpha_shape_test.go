@@ -0,0 +1,133 @@
+package spatial
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestDelaunayTriangulation(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+	points := make([][2]float64, 300)
+	for i := range points {
+		points[i] = [2]float64{rnd.Float64() * 1000, rnd.Float64() * 1000}
+	}
+	triangles := DelaunayTriangulation(points)
+	// Number of triangles for points in general position: 2n - 2 - h, where h is number of convex hull vertices
+	if len(triangles) < len(points) {
+		t.Fatalf("Too few triangles: %d", len(triangles))
+	}
+	for _, tr := range triangles {
+		ct := newDelaunayTriangle(points, tr[0], tr[1], tr[2])
+		for i, pt := range points {
+			if i == tr[0] || i == tr[1] || i == tr[2] {
+				continue
+			}
+			dx, dy := pt[0]-ct.cx, pt[1]-ct.cy
+			if dx*dx+dy*dy < ct.r2*(1-1e-9) {
+				t.Fatalf("Point #%d is inside of circumcircle of triangle %v", i, tr)
+			}
+		}
+	}
+}
+
+func gridPoints(x0, y0 float64, size int, skip func(x, y int) bool) [][2]float64 {
+	points := [][2]float64{}
+	for x := 0; x <= size; x++ {
+		for y := 0; y <= size; y++ {
+			if skip != nil && skip(x, y) {
+				continue
+			}
+			points = append(points, [2]float64{x0 + float64(x), y0 + float64(y)})
+		}
+	}
+	return points
+}
+
+func polygonArea(polygon [][][2]float64) float64 {
+	area := 0.0
+	for _, ring := range polygon {
+		area += RingSignedArea(ring)
+	}
+	return area
+}
+
+func TestAlphaShape(t *testing.T) {
+	eps := 1e-9
+
+	// Solid square
+	polygons := AlphaShape(gridPoints(0, 0, 10, nil), 1)
+	if len(polygons) != 1 {
+		t.Fatalf("Should be 1 polygon, got %d", len(polygons))
+	}
+	if len(polygons[0]) != 1 {
+		t.Errorf("Solid square should have no holes, got %d", len(polygons[0])-1)
+	}
+	if math.Abs(polygonArea(polygons[0])-100) > eps {
+		t.Errorf("Area should be 100, got %f", polygonArea(polygons[0]))
+	}
+	for _, ring := range polygons[0] {
+		if ring[0] != ring[len(ring)-1] {
+			t.Errorf("Ring should be closed")
+		}
+	}
+
+	// Square with hole (corners of the hole are cut by triangles of neighbor cells)
+	polygons = AlphaShape(gridPoints(0, 0, 10, func(x, y int) bool {
+		return x > 3 && x < 7 && y > 3 && y < 7
+	}), 1)
+	if len(polygons) != 1 {
+		t.Fatalf("Should be 1 polygon, got %d", len(polygons))
+	}
+	if len(polygons[0]) != 2 {
+		t.Fatalf("Square should have 1 hole, got %d", len(polygons[0])-1)
+	}
+	if RingSignedArea(polygons[0][1]) >= 0 {
+		t.Errorf("Hole should be oriented clockwise")
+	}
+	if math.Abs(polygonArea(polygons[0])-86) > eps {
+		t.Errorf("Area should be 86, got %f", polygonArea(polygons[0]))
+	}
+
+	// Two separate squares (the bigger one goes first)
+	points := append(gridPoints(0, 0, 4, nil), gridPoints(100, 100, 2, nil)...)
+	polygons = AlphaShape(points, 1)
+	if len(polygons) != 2 {
+		t.Fatalf("Should be 2 polygons, got %d", len(polygons))
+	}
+	if math.Abs(polygonArea(polygons[0])-16) > eps || math.Abs(polygonArea(polygons[1])-4) > eps {
+		t.Errorf("Areas should be 16 and 4, got %f and %f", polygonArea(polygons[0]), polygonArea(polygons[1]))
+	}
+
+	// Big alpha gives convex hull
+	polygons = AlphaShape(points, 1e6)
+	if len(polygons) != 1 || len(polygons[0]) != 1 {
+		t.Fatalf("Should be single polygon without holes for big alpha")
+	}
+
+	// Two triangles touching at single vertex (hourglass)
+	points = [][2]float64{{0, 0}, {2, 0}, {1, 1}, {0, 3}, {2, 3}}
+	polygons = AlphaShape(points, 1.3)
+	if len(polygons) != 2 {
+		t.Fatalf("Should be 2 polygons for triangles touching at single vertex, got %d", len(polygons))
+	}
+	if math.Abs(polygonArea(polygons[0])-2) > eps || math.Abs(polygonArea(polygons[1])-1) > eps {
+		t.Errorf("Areas should be 2 and 1, got %f and %f", polygonArea(polygons[0]), polygonArea(polygons[1]))
+	}
+}
+
+func BenchmarkDelaunayTriangulation(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000} {
+		rnd := rand.New(rand.NewSource(42))
+		points := make([][2]float64, n)
+		for i := range points {
+			points[i] = [2]float64{rnd.Float64() * 1000, rnd.Float64() * 1000}
+		}
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				DelaunayTriangulation(points)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package spatial
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrAttributeType = fmt.Errorf("unknown attribute type")
+)
+
+// AttributeType Type of values of edge attribute
+type AttributeType uint8
+
+const (
+	// AttributeTypeAuto Type is guessed for every value: integer, float, boolean or string (the first which value could be parsed as)
+	AttributeTypeAuto = AttributeType(iota)
+	// AttributeTypeString Values are kept as is
+	AttributeTypeString
+	// AttributeTypeInt Values are parsed as int64
+	AttributeTypeInt
+	// AttributeTypeFloat Values are parsed as float64
+	AttributeTypeFloat
+	// AttributeTypeBool Values are parsed as bool (see strconv.ParseBool)
+	AttributeTypeBool
+)
+
+var attributeTypes = map[string]AttributeType{
+	"auto":   AttributeTypeAuto,
+	"string": AttributeTypeString,
+	"int":    AttributeTypeInt,
+	"float":  AttributeTypeFloat,
+	"bool":   AttributeTypeBool,
+}
+
+// String Returns name of the type as it is written in CSV header
+func (attributeType AttributeType) String() string {
+	for name, t := range attributeTypes {
+		if t == attributeType {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// ParseAttributeColumn Returns name and type of attribute for column of CSV header
+/*
+	column - name of column. Type could be provided after colon, e.g. 'maxspeed:int', 'name:string', 'oneway:bool'.
+		When there is no type, then AttributeTypeAuto is used
+*/
+func ParseAttributeColumn(column string) (string, AttributeType, error) {
+	column = strings.TrimSpace(column)
+	idx := strings.LastIndex(column, ":")
+	if idx == -1 {
+		return column, AttributeTypeAuto, nil
+	}
+	name := strings.TrimSpace(column[:idx])
+	attributeType, ok := attributeTypes[strings.ToLower(strings.TrimSpace(column[idx+1:]))]
+	if !ok {
+		return "", AttributeTypeAuto, errors.Wrapf(ErrAttributeType, "column '%s'", column)
+	}
+	return name, attributeType, nil
+}
+
+// ParseAttributeValue Returns value of attribute parsed according to the type: int64, float64, bool or string
+func ParseAttributeValue(value string, attributeType AttributeType) (interface{}, error) {
+	value = strings.TrimSpace(value)
+	switch attributeType {
+	case AttributeTypeString:
+		return value, nil
+	case AttributeTypeInt:
+		return strconv.ParseInt(value, 10, 64)
+	case AttributeTypeFloat:
+		return strconv.ParseFloat(value, 64)
+	case AttributeTypeBool:
+		return strconv.ParseBool(value)
+	case AttributeTypeAuto:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v, nil
+		}
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v, nil
+		}
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v, nil
+		}
+		return value, nil
+	}
+	return nil, errors.Wrapf(ErrAttributeType, "type %d", attributeType)
+}
+
+// EdgeAttributes Additional properties of edge: road class, speed limit, name, OSM way ID and etc.
+// Values are int64, float64, bool or string (see ParseAttributeValue)
+type EdgeAttributes map[string]interface{}
+
+// Get Returns value of the attribute and whether the attribute exists
+func (attributes EdgeAttributes) Get(name string) (interface{}, bool) {
+	value, ok := attributes[name]
+	return value, ok
+}
+
+// String Returns value of the attribute formatted as string
+func (attributes EdgeAttributes) String(name string) (string, bool) {
+	value, ok := attributes[name]
+	if !ok {
+		return "", false
+	}
+	return FormatAttributeValue(value), true
+}
+
+// Int Returns value of integer attribute. Float values without fractional part are converted too
+func (attributes EdgeAttributes) Int(name string) (int64, bool) {
+	switch value := attributes[name].(type) {
+	case int64:
+		return value, true
+	case float64:
+		if value == float64(int64(value)) {
+			return int64(value), true
+		}
+	}
+	return 0, false
+}
+
+// Float Returns value of numeric attribute
+func (attributes EdgeAttributes) Float(name string) (float64, bool) {
+	switch value := attributes[name].(type) {
+	case float64:
+		return value, true
+	case int64:
+		return float64(value), true
+	}
+	return 0, false
+}
+
+// Bool Returns value of boolean attribute
+func (attributes EdgeAttributes) Bool(name string) (bool, bool) {
+	value, ok := attributes[name].(bool)
+	return value, ok
+}
+
+// Names Returns sorted names of attributes
+func (attributes EdgeAttributes) Names() []string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Strings Returns every attribute formatted as string (e.g. for protocols without dynamic types)
+func (attributes EdgeAttributes) Strings() map[string]string {
+	if len(attributes) == 0 {
+		return nil
+	}
+	ans := make(map[string]string, len(attributes))
+	for name, value := range attributes {
+		ans[name] = FormatAttributeValue(value)
+	}
+	return ans
+}
+
+// FormatAttributeValue Returns string representation of attribute value (float values are formatted without trailing zeros)
+func FormatAttributeValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(value)
+}
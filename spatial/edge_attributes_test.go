@@ -0,0 +1,94 @@
+package spatial
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestParseAttributeColumn(t *testing.T) {
+	cases := []struct {
+		column        string
+		name          string
+		attributeType AttributeType
+	}{
+		{"highway", "highway", AttributeTypeAuto},
+		{"maxspeed:int", "maxspeed", AttributeTypeInt},
+		{" name : String ", "name", AttributeTypeString},
+		{"lanes:float", "lanes", AttributeTypeFloat},
+		{"lit:bool", "lit", AttributeTypeBool},
+		{"osm:way_id:auto", "osm:way_id", AttributeTypeAuto},
+	}
+	for _, c := range cases {
+		name, attributeType, err := ParseAttributeColumn(c.column)
+		if err != nil {
+			t.Fatalf("Column '%s': %v", c.column, err)
+		}
+		if name != c.name || attributeType != c.attributeType {
+			t.Errorf("Column '%s' should be parsed as '%s' of type '%s', but got '%s' of type '%s'", c.column, c.name, c.attributeType, name, attributeType)
+		}
+	}
+	_, _, err := ParseAttributeColumn("maxspeed:integer")
+	if errors.Cause(err) != ErrAttributeType {
+		t.Errorf("Expected error '%v', but got '%v'", ErrAttributeType, err)
+	}
+}
+
+func TestParseAttributeValue(t *testing.T) {
+	cases := []struct {
+		value         string
+		attributeType AttributeType
+		expected      interface{}
+	}{
+		{"60", AttributeTypeAuto, int64(60)},
+		{"2.5", AttributeTypeAuto, 2.5},
+		{"true", AttributeTypeAuto, true},
+		{"primary", AttributeTypeAuto, "primary"},
+		{"60", AttributeTypeString, "60"},
+		{"60", AttributeTypeFloat, 60.0},
+		{"0", AttributeTypeBool, false},
+	}
+	for _, c := range cases {
+		value, err := ParseAttributeValue(c.value, c.attributeType)
+		if err != nil {
+			t.Fatalf("Value '%s': %v", c.value, err)
+		}
+		if value != c.expected {
+			t.Errorf("Value '%s' of type '%s' should be parsed as %#v, but got %#v", c.value, c.attributeType, c.expected, value)
+		}
+	}
+	_, err := ParseAttributeValue("2.5", AttributeTypeInt)
+	if err == nil {
+		t.Errorf("Expected error for value '2.5' of type 'int'")
+	}
+}
+
+func TestEdgeAttributesGetters(t *testing.T) {
+	attributes := EdgeAttributes{"maxspeed": int64(60), "lanes": 2.0, "width": 3.5, "lit": true, "name": "Main street"}
+	if value, ok := attributes.Int("lanes"); !ok || value != 2 {
+		t.Errorf("Attribute 'lanes' should be converted to 2, but got %d", value)
+	}
+	if _, ok := attributes.Int("width"); ok {
+		t.Errorf("Attribute 'width' should not be converted to integer")
+	}
+	if value, ok := attributes.Float("maxspeed"); !ok || value != 60 {
+		t.Errorf("Attribute 'maxspeed' should be converted to 60.0, but got %f", value)
+	}
+	if _, ok := attributes.Bool("name"); ok {
+		t.Errorf("Attribute 'name' should not be converted to boolean")
+	}
+	strs := attributes.Strings()
+	expected := map[string]string{"maxspeed": "60", "lanes": "2", "width": "3.5", "lit": "true", "name": "Main street"}
+	for name, value := range expected {
+		if strs[name] != value {
+			t.Errorf("Attribute '%s' should be formatted as '%s', but got '%s'", name, value, strs[name])
+		}
+	}
+	names := attributes.Names()
+	if len(names) != 5 || names[0] != "lanes" || names[4] != "width" {
+		t.Errorf("Names should be sorted, but got %v", names)
+	}
+	if EdgeAttributes(nil).Strings() != nil {
+		t.Errorf("Nil attributes should be formatted as nil")
+	}
+}
@@ -18,10 +18,13 @@ const (
 	Needs additional field "srid" to determine what algorithm has to be used to calculate distance between objects
 	SRID = 0, Euclidean distance
 	SRID = 4326 (WGS84), Distance on sphere
+	Other SRIDs (projected CRS, see ProjectionBySRID), point is stored on sphere as for SRID = 4326. SRID is kept to return coordinates in the same CRS
+	Field "ellipsoidal" switches distance calculation for non-planar points from sphere to WGS84 ellipsoid (see GeodesicDistance)
 */
 type GeoPoint struct {
 	s2.Point
-	srid int
+	srid        int
+	ellipsoidal bool
 }
 
 // SRID Returns SRID of point
@@ -29,6 +32,16 @@ func (gp *GeoPoint) SRID() int {
 	return gp.srid
 }
 
+// SetEllipsoidal Enables (or disables) distance calculation on WGS84 ellipsoid instead of sphere. It has no effect for SRID = 0
+func (gp *GeoPoint) SetEllipsoidal(ellipsoidal bool) {
+	gp.ellipsoidal = ellipsoidal
+}
+
+// IsEllipsoidal Returns true if distances are evaluated on WGS84 ellipsoid
+func (gp *GeoPoint) IsEllipsoidal() bool {
+	return gp.ellipsoidal
+}
+
 // String Pretty print
 func (gp *GeoPoint) String() string {
 	return fmt.Sprintf("Point{s2: %v, srid: %d}", gp.Point, gp.srid)
@@ -39,34 +52,51 @@ func (gp *GeoPoint) SetSRID(srid int) {
 	gp.srid = srid
 }
 
-// newGeoPoint Returns pointer to created GeoPoint
+// NewGeoPoint Returns pointer to created GeoPoint
 /*
-	@NOT EXPORTED
+	x - longitude for SRID = 4326, X for SRID = 0, easting for projected CRS
+	y - latitude for SRID = 4326, Y for SRID = 0, northing for projected CRS
+	srid - SRID: 0, 4326 or any SRID with known projection (see ProjectionBySRID)
+*/
+func NewGeoPoint(x, y float64, srid int) (*GeoPoint, error) {
+	pt, err := S2PointFromSRID(x, y, srid)
+	if err != nil {
+		return nil, err
+	}
+	return &GeoPoint{
+		srid:  srid,
+		Point: pt,
+	}, nil
+}
 
-	lon - longitude (X for SRID = 0)
-	lat - latitude (Y for SRID = 0)
-	srid - SRID
+// NewGeoPointFromS2 Returns pointer to created GeoPoint for s2.Point which has been already prepared (see S2PointFromSRID)
+func NewGeoPointFromS2(pt s2.Point, srid int) *GeoPoint {
+	return &GeoPoint{
+		srid:  srid,
+		Point: pt,
+	}
+}
+
+// Coordinates Returns coordinates of point in its SRID: longitude and latitude for SRID = 4326, X and Y otherwise
+/*
+	Point with unknown SRID returns longitude and latitude
 */
-func newGeoPoint(lon, lat float64, srid int) *GeoPoint {
-	switch srid {
-	case 4326:
-		return &GeoPoint{
-			srid:  srid,
-			Point: s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lon)),
-		}
-	default:
-		return &GeoPoint{
-			srid:  srid,
-			Point: s2.Point{Vector: r3.Vector{X: lon, Y: lat, Z: 0}},
-		}
+func (gp *GeoPoint) Coordinates() (x, y float64) {
+	x, y, err := S2PointToSRID(gp.Point, gp.srid)
+	if err != nil {
+		x, y, _ = S2PointToSRID(gp.Point, 4326)
 	}
+	return x, y
 }
 
 // NewEuclideanPoint Returns pointer to created GeoPoint with SRID = 0
 // Uses raw r3.Vector to preserve exact Cartesian coordinates.
 // Note: s2.PointFromCoords normalizes to unit sphere, which would distort Euclidean coordinates.
 func NewEuclideanPoint(x, y float64) *GeoPoint {
-	return newGeoPoint(x, y, 0)
+	return &GeoPoint{
+		srid:  0,
+		Point: NewEuclideanS2Point(x, y),
+	}
 }
 
 // NewEuclideanS2Point Returns s2.Point with raw Euclidean coordinates (not normalized)
@@ -78,27 +108,34 @@ func NewEuclideanS2Point(x, y float64) s2.Point {
 
 // NewWGS84Point Returns pointer to created GeoPoint with SRID = 4326
 func NewWGS84Point(lon, lat float64) *GeoPoint {
-	return newGeoPoint(lon, lat, 4326)
+	return &GeoPoint{
+		srid:  4326,
+		Point: s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lon)),
+	}
 }
 
 // DistanceTo Compute distance between two points.
 /*
 	Algorithm of distance calculation depends on SRID.
 	SRID = 0, Euclidean distance
-	SRID = 4326 (WGS84), Distance on sphere
+	SRID = 4326 (WGS84) or projected CRS, Distance on sphere (points of different SRIDs could be compared unless one of them is planar)
+	or geodesic distance on WGS84 ellipsoid if the point is ellipsoidal one (see SetEllipsoidal)
 */
 func (gp *GeoPoint) DistanceTo(gp2 *GeoPoint) float64 {
-	if gp.SRID() != gp2.SRID() {
-		// SRIDs has to be equal, need to make assert actually. But we are just use Euclidean distance for this case
+	if (gp.SRID() == 0) != (gp2.SRID() == 0) {
+		// SRIDs has to be compatible, need to make assert actually. But we are just use Euclidean distance for this case
 		return gp.Vector.Distance(gp2.Vector)
 	}
 	switch gp.SRID() {
-	case 4326:
-		// Deal with WGS84
-		return gp.Distance(gp2.Point).Radians() * EarthRadius
-	default:
+	case 0:
 		// Deal with planar geometry
 		return gp.Vector.Distance(gp2.Vector)
+	default:
+		// Deal with WGS84 (projected points are stored on sphere too)
+		if gp.ellipsoidal {
+			return GeodesicDistance(gp.Point, gp2.Point)
+		}
+		return gp.Distance(gp2.Point).Radians() * EarthRadius
 	}
 }
 
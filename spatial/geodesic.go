@@ -0,0 +1,83 @@
+package spatial
+
+import (
+	"math"
+
+	"github.com/golang/geo/s2"
+)
+
+const (
+	// wgs84SemiMinorAxis Semi-minor axis of WGS84 ellipsoid
+	wgs84SemiMinorAxis = wgs84SemiMajorAxis * (1 - wgs84Flattening)
+	// vincentyMaxIterations Max number of iterations of Vincenty's inverse formula. Formula converges in few iterations except for nearly antipodal points
+	vincentyMaxIterations = 200
+	// vincentyPrecision Precision of longitude on auxiliary sphere (radians), about 0.006 mm
+	vincentyPrecision = 1e-12
+)
+
+// GeodesicDistance Returns distance in meters between two points on WGS84 ellipsoid
+/*
+	a - first point (s2.Point on sphere, see NewWGS84Point)
+	b - second point
+
+	Vincenty's inverse formula is used (accuracy is about 0.5 mm), see https://en.wikipedia.org/wiki/Vincenty%27s_formulae.
+	For nearly antipodal points (when formula doesn't converge) distance on sphere is returned
+*/
+func GeodesicDistance(a, b s2.Point) float64 {
+	if a == b {
+		return 0
+	}
+	latLngA := s2.LatLngFromPoint(a)
+	latLngB := s2.LatLngFromPoint(b)
+	f := wgs84Flattening
+	L := math.Remainder(latLngB.Lng.Radians()-latLngA.Lng.Radians(), 2*math.Pi)
+	U1 := math.Atan((1 - f) * math.Tan(latLngA.Lat.Radians()))
+	U2 := math.Atan((1 - f) * math.Tan(latLngB.Lat.Radians()))
+	sinU1, cosU1 := math.Sincos(U1)
+	sinU2, cosU2 := math.Sincos(U2)
+
+	lambda := L
+	var sinSigma, cosSigma, sigma, cosSqAlpha, cos2SigmaM float64
+	converged := false
+	for i := 0; i < vincentyMaxIterations; i++ {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			// Coincident points
+			return 0
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cosSqAlpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0
+		if cosSqAlpha != 0 {
+			// Otherwise both points are on equator
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cosSqAlpha
+		}
+		C := f / 16 * cosSqAlpha * (4 + f*(4-3*cosSqAlpha))
+		prevLambda := lambda
+		lambda = L + (1-C)*f*sinAlpha*(sigma+C*sinSigma*(cos2SigmaM+C*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-prevLambda) < vincentyPrecision {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return a.Distance(b).Radians() * EarthRadius
+	}
+	uSq := cosSqAlpha * (wgs84SemiMajorAxis*wgs84SemiMajorAxis - wgs84SemiMinorAxis*wgs84SemiMinorAxis) / (wgs84SemiMinorAxis * wgs84SemiMinorAxis)
+	A := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	B := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+	deltaSigma := B * sinSigma * (cos2SigmaM + B/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-B/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+	return wgs84SemiMinorAxis * A * (sigma - deltaSigma)
+}
+
+// PolylineLengthGeodesic Returns length of polyline in meters on WGS84 ellipsoid (see GeodesicDistance)
+func PolylineLengthGeodesic(line s2.Polyline) float64 {
+	length := 0.0
+	for i := 1; i < len(line); i++ {
+		length += GeodesicDistance(line[i-1], line[i])
+	}
+	return length
+}
@@ -0,0 +1,98 @@
+package spatial
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+// dmsToDegrees Converts degrees, minutes and seconds to decimal degrees
+func dmsToDegrees(degrees, minutes, seconds float64) float64 {
+	sign := 1.0
+	if degrees < 0 {
+		sign = -1.0
+	}
+	return sign * (math.Abs(degrees) + minutes/60 + seconds/3600)
+}
+
+func TestGeodesicDistance(t *testing.T) {
+	cases := []struct {
+		name     string
+		a, b     s2.Point
+		expected float64
+	}{
+		{
+			// Length of 1 degree of equator: a * pi / 180
+			name:     "equator",
+			a:        NewWGS84Point(0, 0).Point,
+			b:        NewWGS84Point(1, 0).Point,
+			expected: 111319.490793,
+		},
+		{
+			// Length of 1 degree of meridian at equator
+			name:     "meridian",
+			a:        NewWGS84Point(0, 0).Point,
+			b:        NewWGS84Point(0, 1).Point,
+			expected: 110574.388556,
+		},
+		{
+			// Quarter of meridian
+			name:     "quarter meridian",
+			a:        NewWGS84Point(0, 0).Point,
+			b:        NewWGS84Point(0, 90).Point,
+			expected: 10001965.729,
+		},
+		{
+			// Example from Vincenty's paper: Flinders Peak - Buninyong
+			name:     "Flinders Peak - Buninyong",
+			a:        NewWGS84Point(dmsToDegrees(144, 25, 29.52440), dmsToDegrees(-37, 57, 3.72030)).Point,
+			b:        NewWGS84Point(dmsToDegrees(143, 55, 35.38390), dmsToDegrees(-37, 39, 10.15610)).Point,
+			expected: 54972.271,
+		},
+		{
+			name:     "same point",
+			a:        NewWGS84Point(37.6, 55.7).Point,
+			b:        NewWGS84Point(37.6, 55.7).Point,
+			expected: 0,
+		},
+	}
+	eps := 1e-3
+	for _, c := range cases {
+		distance := GeodesicDistance(c.a, c.b)
+		if math.Abs(distance-c.expected) > eps {
+			t.Errorf("Case '%s': distance should be %f, but got %f", c.name, c.expected, distance)
+		}
+		reversed := GeodesicDistance(c.b, c.a)
+		if math.Abs(distance-reversed) > 1e-6 {
+			t.Errorf("Case '%s': distance should not depend on direction, but got %f and %f", c.name, distance, reversed)
+		}
+	}
+
+	// Nearly antipodal points: fallback to sphere
+	distance := GeodesicDistance(NewWGS84Point(0, 0).Point, NewWGS84Point(179.7, 0.5).Point)
+	if math.IsNaN(distance) || distance < 19900000 || distance > 20020000 {
+		t.Errorf("Distance between nearly antipodal points should be about 20000 km, but got %f", distance)
+	}
+}
+
+func TestGeoPointEllipsoidal(t *testing.T) {
+	a := NewWGS84Point(0, 0)
+	b := NewWGS84Point(0, 1)
+	spherical := a.DistanceTo(b)
+	a.SetEllipsoidal(true)
+	if !a.IsEllipsoidal() {
+		t.Fatalf("Point should be ellipsoidal")
+	}
+	ellipsoidal := a.DistanceTo(b)
+	if math.Abs(ellipsoidal-110574.388556) > 1e-3 {
+		t.Errorf("Ellipsoidal distance should be %f, but got %f", 110574.388556, ellipsoidal)
+	}
+	if math.Abs(spherical-ellipsoidal) < 100 {
+		t.Errorf("Spherical distance %f should differ from ellipsoidal one %f", spherical, ellipsoidal)
+	}
+	line := s2.Polyline{a.Point, b.Point, NewWGS84Point(1, 1).Point}
+	if length := PolylineLengthGeodesic(line); math.Abs(length-ellipsoidal-GeodesicDistance(b.Point, line[2])) > 1e-6 {
+		t.Errorf("Length of polyline should be sum of geodesic distances, but got %f", length)
+	}
+}
@@ -0,0 +1,62 @@
+package spatial
+
+import (
+	"math"
+
+	"github.com/golang/geo/s2"
+)
+
+// LocalProjection Converts points to local planar coordinates and back
+/*
+	For WGS84 points equirectangular projection around the origin is used (planar coordinates are in meters).
+	Euclidean points are kept as is (Vector.X/Y are used as planar coordinates)
+*/
+type LocalProjection struct {
+	euclidean bool
+	lat0      float64
+	lng0      float64
+	cosLat0   float64
+}
+
+// NewLocalProjection Returns projection around the given origin
+/*
+	origin - center of projection (distortion grows with distance from it)
+	euclidean - true if points are Euclidean ones
+*/
+func NewLocalProjection(origin s2.Point, euclidean bool) LocalProjection {
+	if euclidean {
+		return LocalProjection{euclidean: true}
+	}
+	latLng := s2.LatLngFromPoint(origin)
+	return LocalProjection{
+		lat0:    latLng.Lat.Radians(),
+		lng0:    latLng.Lng.Radians(),
+		cosLat0: math.Cos(latLng.Lat.Radians()),
+	}
+}
+
+// ToPlane Returns planar coordinates of the point
+func (projection LocalProjection) ToPlane(pt s2.Point) [2]float64 {
+	if projection.euclidean {
+		return [2]float64{pt.Vector.X, pt.Vector.Y}
+	}
+	latLng := s2.LatLngFromPoint(pt)
+	dLng := math.Remainder(latLng.Lng.Radians()-projection.lng0, 2*math.Pi)
+	return [2]float64{
+		EarthRadius * dLng * projection.cosLat0,
+		EarthRadius * (latLng.Lat.Radians() - projection.lat0),
+	}
+}
+
+// FromPlane Returns point for planar coordinates
+func (projection LocalProjection) FromPlane(xy [2]float64) s2.Point {
+	if projection.euclidean {
+		return NewEuclideanS2Point(xy[0], xy[1])
+	}
+	lat := projection.lat0 + xy[1]/EarthRadius
+	lng := projection.lng0
+	if projection.cosLat0 != 0 {
+		lng += xy[0] / (EarthRadius * projection.cosLat0)
+	}
+	return s2.PointFromLatLng(s2.LatLngFromDegrees(lat*180/math.Pi, lng*180/math.Pi).Normalized())
+}
@@ -0,0 +1,266 @@
+package spatial
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// RingsToS2Polygon Returns *s2.Polygon representation of GeoJSON-like polygon coordinates
+/*
+	rings - set of rings: first one is outer ring, others are holes. Each ring is set of [lon, lat] (or [X, Y] for SRID = 0) pairs
+	srid - SRID of coordinates. 0, 4326 and projected CRS (see ProjectionBySRID) are supported.
+
+	Closing point of a ring (which is equal to the first one) is optional.
+	For SRID = 0 loops hold raw Cartesian coordinates (see NewEuclideanS2Point), so the polygon must be used only with Euclidean helpers.
+*/
+func RingsToS2Polygon(rings [][][]float64, srid int) (*s2.Polygon, error) {
+	if len(rings) == 0 {
+		return nil, fmt.Errorf("polygon must have at least one ring")
+	}
+	loops := make([]*s2.Loop, 0, len(rings))
+	for i, ring := range rings {
+		if len(ring) > 1 && ring[0][0] == ring[len(ring)-1][0] && ring[0][1] == ring[len(ring)-1][1] {
+			ring = ring[:len(ring)-1]
+		}
+		if len(ring) < 3 {
+			return nil, fmt.Errorf("ring %d must have at least 3 distinct points", i)
+		}
+		points := make([]s2.Point, 0, len(ring))
+		for j, pt := range ring {
+			if len(pt) < 2 {
+				return nil, fmt.Errorf("invalid coordinate pair at ring %d, position %d", i, j)
+			}
+			point, err := S2PointFromSRID(pt[0], pt[1], srid)
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, point)
+		}
+		loop := s2.LoopFromPoints(points)
+		if srid != 0 {
+			// Orientation of user's rings is not guaranteed: make every loop to cover the smaller area
+			loop.Normalize()
+		}
+		loops = append(loops, loop)
+	}
+	if srid == 0 {
+		// Nesting of loops can't be evaluated on sphere for raw Cartesian coordinates
+		return s2.PolygonFromOrientedLoops(loops), nil
+	}
+	return s2.PolygonFromLoops(loops), nil
+}
+
+// PolylineIntersectsPolygon Checks if polyline has common points with polygon (spherical geometry)
+func PolylineIntersectsPolygon(polyline s2.Polyline, polygon *s2.Polygon) bool {
+	if len(polyline) == 0 || polygon == nil {
+		return false
+	}
+	for _, pt := range polyline {
+		if polygon.ContainsPoint(pt) {
+			return true
+		}
+	}
+	for i := 0; i < polyline.NumEdges(); i++ {
+		edge := polyline.Edge(i)
+		for j := 0; j < polygon.NumEdges(); j++ {
+			polygonEdge := polygon.Edge(j)
+			if s2.CrossingSign(edge.V0, edge.V1, polygonEdge.V0, polygonEdge.V1) != s2.DoNotCross {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PolylineIntersectsPolygonEuclidean Checks if polyline has common points with polygon (Euclidean/planar geometry)
+/*
+	Both polyline and polygon are expected to use Vector.X/Y as Euclidean coordinates
+*/
+func PolylineIntersectsPolygonEuclidean(polyline s2.Polyline, polygon *s2.Polygon) bool {
+	if len(polyline) == 0 || polygon == nil {
+		return false
+	}
+	for _, pt := range polyline {
+		if PolygonContainsPointEuclidean(polygon, pt) {
+			return true
+		}
+	}
+	for i := 0; i < len(polyline)-1; i++ {
+		a, b := polyline[i], polyline[i+1]
+		for _, loop := range polygon.Loops() {
+			vertices := loop.Vertices()
+			for j := range vertices {
+				c, d := vertices[j], vertices[(j+1)%len(vertices)]
+				if segmentsIntersectEuclidean(a.X, a.Y, b.X, b.Y, c.X, c.Y, d.X, d.Y) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// PolygonContainsPointEuclidean Checks if point is inside of polygon (Euclidean/planar geometry, even-odd rule)
+/*
+	Holes are handled naturally by even-odd rule: point inside of a hole crosses outer ring and hole ring.
+*/
+func PolygonContainsPointEuclidean(polygon *s2.Polygon, pt s2.Point) bool {
+	inside := false
+	for _, loop := range polygon.Loops() {
+		vertices := loop.Vertices()
+		for i, j := 0, len(vertices)-1; i < len(vertices); j, i = i, i+1 {
+			xi, yi := vertices[i].X, vertices[i].Y
+			xj, yj := vertices[j].X, vertices[j].Y
+			if (yi > pt.Y) != (yj > pt.Y) && pt.X < (xj-xi)*(pt.Y-yi)/(yj-yi)+xi {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// PolygonBoundingCircleEuclidean Returns center and radius of circle covering the polygon (Euclidean/planar geometry)
+func PolygonBoundingCircleEuclidean(polygon *s2.Polygon) (s2.Point, float64) {
+	minX, minY, maxX, maxY := PolygonBoundsEuclidean(polygon)
+	center := NewEuclideanS2Point((minX+maxX)/2, (minY+maxY)/2)
+	radius := math.Hypot(maxX-minX, maxY-minY) / 2
+	return center, radius
+}
+
+// PolygonBoundsEuclidean Returns bounding box of the polygon (Euclidean/planar geometry)
+func PolygonBoundsEuclidean(polygon *s2.Polygon) (minX, minY, maxX, maxY float64) {
+	minX, minY = math.MaxFloat64, math.MaxFloat64
+	maxX, maxY = -math.MaxFloat64, -math.MaxFloat64
+	for _, loop := range polygon.Loops() {
+		for _, v := range loop.Vertices() {
+			minX, maxX = math.Min(minX, v.X), math.Max(maxX, v.X)
+			minY, maxY = math.Min(minY, v.Y), math.Max(maxY, v.Y)
+		}
+	}
+	return minX, minY, maxX, maxY
+}
+
+// PolylineIntersectsRect Checks if polyline has common points with latitude-longitude rectangle (spherical geometry)
+/*
+	Sides of constant longitude are geodesics, while sides of constant latitude are not: they are checked separately (see intersectsLatSide)
+*/
+func PolylineIntersectsRect(polyline s2.Polyline, rect s2.Rect) bool {
+	if len(polyline) == 0 || rect.IsEmpty() {
+		return false
+	}
+	if !rect.Intersects(polyline.RectBound()) {
+		return false
+	}
+	for _, pt := range polyline {
+		if rect.ContainsPoint(pt) {
+			return true
+		}
+	}
+	lngLo := s2.PointFromLatLng(s2.LatLng{Lat: s1.Angle(rect.Lat.Lo), Lng: s1.Angle(rect.Lng.Lo)})
+	lngLoEnd := s2.PointFromLatLng(s2.LatLng{Lat: s1.Angle(rect.Lat.Hi), Lng: s1.Angle(rect.Lng.Lo)})
+	lngHi := s2.PointFromLatLng(s2.LatLng{Lat: s1.Angle(rect.Lat.Lo), Lng: s1.Angle(rect.Lng.Hi)})
+	lngHiEnd := s2.PointFromLatLng(s2.LatLng{Lat: s1.Angle(rect.Lat.Hi), Lng: s1.Angle(rect.Lng.Hi)})
+	for i := 0; i < polyline.NumEdges(); i++ {
+		edge := polyline.Edge(i)
+		if s2.CrossingSign(edge.V0, edge.V1, lngLo, lngLoEnd) != s2.DoNotCross {
+			return true
+		}
+		if s2.CrossingSign(edge.V0, edge.V1, lngHi, lngHiEnd) != s2.DoNotCross {
+			return true
+		}
+		if intersectsLatSide(edge.V0, edge.V1, rect.Lat.Lo, rect.Lng) || intersectsLatSide(edge.V0, edge.V1, rect.Lat.Hi, rect.Lng) {
+			return true
+		}
+	}
+	return false
+}
+
+// intersectsLatSide Checks if geodesic a-b crosses line of constant latitude (radians) within the longitude interval
+/*
+	Line of constant latitude could be crossed by geodesic in 0, 1 or 2 points: candidates are found in the frame
+	where x-axis points to the maximum latitude of the great circle through a and b
+*/
+func intersectsLatSide(a, b s2.Point, lat float64, lng s1.Interval) bool {
+	z := s2.Point{Vector: a.PointCross(b).Normalize()}
+	if z.Z < 0 {
+		z = s2.Point{Vector: z.Mul(-1)}
+	}
+	y := s2.Point{Vector: z.PointCross(s2.PointFromCoords(0, 0, 1)).Normalize()}
+	x := y.Cross(z.Vector)
+	sinLat := math.Sin(lat)
+	if math.Abs(sinLat) >= x.Z {
+		// Great circle does not reach the latitude
+		return false
+	}
+	cosTheta := sinLat / x.Z
+	sinTheta := math.Sqrt(1 - cosTheta*cosTheta)
+	theta := math.Atan2(sinTheta, cosTheta)
+	abTheta := s1.IntervalFromPointPair(math.Atan2(a.Dot(y.Vector), a.Dot(x)), math.Atan2(b.Dot(y.Vector), b.Dot(x)))
+	for _, candidate := range []float64{theta, -theta} {
+		if !abTheta.Contains(candidate) {
+			continue
+		}
+		isect := x.Mul(math.Cos(candidate)).Add(y.Mul(math.Sin(candidate)))
+		if lng.Contains(math.Atan2(isect.Y, isect.X)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PolylineIntersectsRectEuclidean Checks if polyline has common points with axis-aligned rectangle (Euclidean/planar geometry)
+func PolylineIntersectsRectEuclidean(polyline s2.Polyline, minX, minY, maxX, maxY float64) bool {
+	for _, pt := range polyline {
+		if minX <= pt.X && pt.X <= maxX && minY <= pt.Y && pt.Y <= maxY {
+			return true
+		}
+	}
+	corners := [4][2]float64{{minX, minY}, {maxX, minY}, {maxX, maxY}, {minX, maxY}}
+	for i := 0; i < len(polyline)-1; i++ {
+		a, b := polyline[i], polyline[i+1]
+		for j := range corners {
+			c, d := corners[j], corners[(j+1)%len(corners)]
+			if segmentsIntersectEuclidean(a.X, a.Y, b.X, b.Y, c[0], c[1], d[0], d[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// segmentsIntersectEuclidean Checks if segments (x1,y1)-(x2,y2) and (x3,y3)-(x4,y4) have common points
+func segmentsIntersectEuclidean(x1, y1, x2, y2, x3, y3, x4, y4 float64) bool {
+	d1 := orientation(x3, y3, x4, y4, x1, y1)
+	d2 := orientation(x3, y3, x4, y4, x2, y2)
+	d3 := orientation(x1, y1, x2, y2, x3, y3)
+	d4 := orientation(x1, y1, x2, y2, x4, y4)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	if d1 == 0 && onSegment(x3, y3, x4, y4, x1, y1) {
+		return true
+	}
+	if d2 == 0 && onSegment(x3, y3, x4, y4, x2, y2) {
+		return true
+	}
+	if d3 == 0 && onSegment(x1, y1, x2, y2, x3, y3) {
+		return true
+	}
+	if d4 == 0 && onSegment(x1, y1, x2, y2, x4, y4) {
+		return true
+	}
+	return false
+}
+
+// orientation Returns cross product sign of vectors (a->b) and (a->c)
+func orientation(ax, ay, bx, by, cx, cy float64) float64 {
+	return (bx-ax)*(cy-ay) - (by-ay)*(cx-ax)
+}
+
+// onSegment Checks if collinear point (px, py) lies on segment (ax,ay)-(bx,by)
+func onSegment(ax, ay, bx, by, px, py float64) bool {
+	return math.Min(ax, bx) <= px && px <= math.Max(ax, bx) && math.Min(ay, by) <= py && py <= math.Max(ay, by)
+}
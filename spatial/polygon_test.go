@@ -0,0 +1,67 @@
+package spatial
+
+import (
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestPolylineIntersectsPolygon(t *testing.T) {
+	polygon, err := RingsToS2Polygon([][][]float64{
+		{{37.6630, 55.7730}, {37.6635, 55.7730}, {37.6635, 55.7735}, {37.6630, 55.7735}, {37.6630, 55.7730}},
+	}, 4326)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Polyline crossing the polygon without any vertex inside of it
+	crossing := s2.Polyline{
+		s2.PointFromLatLng(s2.LatLngFromDegrees(55.7732, 37.6620)),
+		s2.PointFromLatLng(s2.LatLngFromDegrees(55.7732, 37.6645)),
+	}
+	if !PolylineIntersectsPolygon(crossing, polygon) {
+		t.Errorf("Polyline crossing the polygon should intersect it")
+	}
+	inside := s2.Polyline{
+		s2.PointFromLatLng(s2.LatLngFromDegrees(55.7731, 37.6631)),
+		s2.PointFromLatLng(s2.LatLngFromDegrees(55.7734, 37.6634)),
+	}
+	if !PolylineIntersectsPolygon(inside, polygon) {
+		t.Errorf("Polyline inside of the polygon should intersect it")
+	}
+	outside := s2.Polyline{
+		s2.PointFromLatLng(s2.LatLngFromDegrees(55.7740, 37.6620)),
+		s2.PointFromLatLng(s2.LatLngFromDegrees(55.7740, 37.6645)),
+	}
+	if PolylineIntersectsPolygon(outside, polygon) {
+		t.Errorf("Polyline outside of the polygon should not intersect it")
+	}
+}
+
+func TestPolylineIntersectsPolygonEuclidean(t *testing.T) {
+	// Square with a hole
+	polygon, err := RingsToS2Polygon([][][]float64{
+		{{0, 0}, {10, 0}, {10, 10}, {0, 10}},
+		{{4, 4}, {6, 4}, {6, 6}, {4, 6}},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !PolygonContainsPointEuclidean(polygon, NewEuclideanS2Point(2, 2)) {
+		t.Errorf("Point (2, 2) should be inside of the polygon")
+	}
+	if PolygonContainsPointEuclidean(polygon, NewEuclideanS2Point(5, 5)) {
+		t.Errorf("Point (5, 5) is inside of the hole and should not be inside of the polygon")
+	}
+	crossing := s2.Polyline{NewEuclideanS2Point(-5, 2), NewEuclideanS2Point(15, 2)}
+	if !PolylineIntersectsPolygonEuclidean(crossing, polygon) {
+		t.Errorf("Polyline crossing the polygon should intersect it")
+	}
+	inHole := s2.Polyline{NewEuclideanS2Point(4.5, 4.5), NewEuclideanS2Point(5.5, 5.5)}
+	if PolylineIntersectsPolygonEuclidean(inHole, polygon) {
+		t.Errorf("Polyline inside of the hole should not intersect the polygon")
+	}
+	center, radius := PolygonBoundingCircleEuclidean(polygon)
+	if center.X != 5 || center.Y != 5 || radius < 7.07 || radius > 7.08 {
+		t.Errorf("Wrong bounding circle: center (%f, %f), radius %f", center.X, center.Y, radius)
+	}
+}
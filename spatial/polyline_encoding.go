@@ -0,0 +1,146 @@
+package spatial
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/golang/geo/s2"
+)
+
+// EncodePolyline Returns Google encoded polyline representation of s2.Polyline
+/*
+	line - s2.Polyline (WGS84)
+	precision - number of decimal digits to keep: 5 for the original Google format, 6 for OSRM/Valhalla 'polyline6' one
+*/
+func EncodePolyline(line s2.Polyline, precision int) string {
+	encoded, _ := EncodePolylineSRID(line, precision, 4326)
+	return encoded
+}
+
+// EncodePolylineSRID Returns Google encoded polyline representation of s2.Polyline with coordinates in the SRID (see S2PointToSRID)
+/*
+	line - s2.Polyline
+	precision - number of decimal digits to keep
+	srid - SRID of encoded coordinates. Every pair is encoded as for WGS84: Y (latitude, northing) first, then X (longitude, easting)
+*/
+func EncodePolylineSRID(line s2.Polyline, precision int, srid int) (string, error) {
+	factor := math.Pow10(precision)
+	var sb strings.Builder
+	prevY, prevX := int64(0), int64(0)
+	for i := range line {
+		x, y, err := S2PointToSRID(line[i], srid)
+		if err != nil {
+			return "", err
+		}
+		iy := int64(math.Round(y * factor))
+		ix := int64(math.Round(x * factor))
+		encodePolylineValue(&sb, iy-prevY)
+		encodePolylineValue(&sb, ix-prevX)
+		prevY, prevX = iy, ix
+	}
+	return sb.String(), nil
+}
+
+// encodePolylineValue Writes single signed value in encoded polyline format
+func encodePolylineValue(sb *strings.Builder, value int64) {
+	shifted := value << 1
+	if value < 0 {
+		shifted = ^shifted
+	}
+	for shifted >= 0x20 {
+		sb.WriteByte(byte((0x20 | (shifted & 0x1f)) + 63))
+		shifted >>= 5
+	}
+	sb.WriteByte(byte(shifted + 63))
+}
+
+// DecodePolyline Returns s2.Polyline for Google encoded polyline
+/*
+	encoded - encoded polyline
+	precision - number of decimal digits used for encoding (5 or 6 usually)
+*/
+func DecodePolyline(encoded string, precision int) (s2.Polyline, error) {
+	factor := math.Pow10(precision)
+	line := s2.Polyline{}
+	lat, lng := int64(0), int64(0)
+	for idx := 0; idx < len(encoded); {
+		dLat, next, err := decodePolylineValue(encoded, idx)
+		if err != nil {
+			return nil, err
+		}
+		dLng, next, err := decodePolylineValue(encoded, next)
+		if err != nil {
+			return nil, err
+		}
+		idx = next
+		lat += dLat
+		lng += dLng
+		line = append(line, s2.PointFromLatLng(s2.LatLngFromDegrees(float64(lat)/factor, float64(lng)/factor)))
+	}
+	return line, nil
+}
+
+// decodePolylineValue Reads single signed value in encoded polyline format starting from idx. Returns value and index of the next value
+func decodePolylineValue(encoded string, idx int) (int64, int, error) {
+	result := int64(0)
+	shift := uint(0)
+	for {
+		if idx >= len(encoded) {
+			return 0, idx, fmt.Errorf("unexpected end of encoded polyline")
+		}
+		b := int64(encoded[idx]) - 63
+		idx++
+		if b < 0 || shift > 60 {
+			return 0, idx, fmt.Errorf("invalid character '%c' in encoded polyline at position %d", encoded[idx-1], idx-1)
+		}
+		result |= (b & 0x1f) << shift
+		shift += 5
+		if b < 0x20 {
+			break
+		}
+	}
+	if result&1 != 0 {
+		return ^(result >> 1), idx, nil
+	}
+	return result >> 1, idx, nil
+}
+
+// S2PolylineToWKT Returns WKT LINESTRING representation of s2.Polyline
+// Output format: LINESTRING(lon1 lat1, lon2 lat2, ...)
+func S2PolylineToWKT(line s2.Polyline) string {
+	coords := make([]string, len(line))
+	for i := range line {
+		latLng := s2.LatLngFromPoint(line[i])
+		coords[i] = formatWKTCoordinate(latLng.Lng.Degrees()) + " " + formatWKTCoordinate(latLng.Lat.Degrees())
+	}
+	return "LINESTRING(" + strings.Join(coords, ", ") + ")"
+}
+
+// S2PolylineToWKTSRID Returns WKT LINESTRING representation of s2.Polyline with coordinates in the SRID (see S2PointToSRID)
+func S2PolylineToWKTSRID(line s2.Polyline, srid int) (string, error) {
+	coords := make([]string, len(line))
+	for i := range line {
+		x, y, err := S2PointToSRID(line[i], srid)
+		if err != nil {
+			return "", err
+		}
+		coords[i] = formatWKTCoordinate(x) + " " + formatWKTCoordinate(y)
+	}
+	return "LINESTRING(" + strings.Join(coords, ", ") + ")", nil
+}
+
+// S2PointToWKTSRID Returns WKT POINT representation of s2.Point with coordinates in the SRID (see S2PointToSRID)
+func S2PointToWKTSRID(pt s2.Point, srid int) (string, error) {
+	x, y, err := S2PointToSRID(pt, srid)
+	if err != nil {
+		return "", err
+	}
+	return "POINT(" + formatWKTCoordinate(x) + " " + formatWKTCoordinate(y) + ")", nil
+}
+
+// formatWKTCoordinate Returns shortest text representation of coordinate rounded to 9 decimal digits (to get rid of conversion noise)
+func formatWKTCoordinate(value float64) string {
+	return strconv.FormatFloat(math.Round(value*1e9)/1e9, 'f', -1, 64)
+}
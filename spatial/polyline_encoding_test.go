@@ -0,0 +1,69 @@
+package spatial
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestEncodePolyline(t *testing.T) {
+	// Example from Google's documentation
+	line := s2.PolylineFromLatLngs([]s2.LatLng{
+		s2.LatLngFromDegrees(38.5, -120.2),
+		s2.LatLngFromDegrees(40.7, -120.95),
+		s2.LatLngFromDegrees(43.252, -126.453),
+	})
+	encoded := EncodePolyline(*line, 5)
+	expected := "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+	if encoded != expected {
+		t.Errorf("Encoded polyline should be '%s', got '%s'", expected, encoded)
+	}
+	for _, precision := range []int{5, 6} {
+		decoded, err := DecodePolyline(EncodePolyline(*line, precision), precision)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(decoded) != len(*line) {
+			t.Fatalf("Decoded polyline should have %d points, got %d", len(*line), len(decoded))
+		}
+		for i := range decoded {
+			a, b := s2.LatLngFromPoint(decoded[i]), s2.LatLngFromPoint((*line)[i])
+			if math.Abs(a.Lat.Degrees()-b.Lat.Degrees()) > 1e-6 || math.Abs(a.Lng.Degrees()-b.Lng.Degrees()) > 1e-6 {
+				t.Errorf("Point #%d with precision %d should be %v, got %v", i, precision, b, a)
+			}
+		}
+	}
+	if _, err := DecodePolyline("_p~iF~ps|U_", 5); err == nil {
+		t.Errorf("Truncated polyline should not be decoded")
+	}
+}
+
+func TestWKTSRID(t *testing.T) {
+	line := s2.Polyline{NewEuclideanS2Point(1.5, -2), NewEuclideanS2Point(10, 20.25)}
+	wkt, err := S2PolylineToWKTSRID(line, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wkt != "LINESTRING(1.5 -2, 10 20.25)" {
+		t.Errorf("Unexpected WKT for SRID = 0: '%s'", wkt)
+	}
+	pt, err := S2PointFromSRID(4187000, 7508000, 3857)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wkt, err = S2PointToWKTSRID(pt, 3857)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := WKTToS2PointFeatureSRID(wkt, 3857)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Distance(pt).Radians()*6371000 > 1e-3 {
+		t.Errorf("Point should survive WKT round trip, but got '%s'", wkt)
+	}
+	if _, err = S2PointToWKTSRID(pt, 1); err == nil {
+		t.Errorf("Unknown SRID should not be formatted")
+	}
+}
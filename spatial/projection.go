@@ -0,0 +1,268 @@
+package spatial
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrUnknownSRID  = fmt.Errorf("unknown SRID")
+	ErrReservedSRID = fmt.Errorf("SRID is reserved and can't be registered")
+	ErrUTMZone      = fmt.Errorf("UTM zone must be in range [1, 60]")
+)
+
+const (
+	// SRID_WEB_MERCATOR Spherical (Web) Mercator, see https://epsg.io/3857
+	SRID_WEB_MERCATOR = 3857
+	// SRID_UTM_NORTH_BASE Base of SRIDs for UTM zones of northern hemisphere (WGS84): 32601 for zone 1 and up to 32660 for zone 60
+	SRID_UTM_NORTH_BASE = 32600
+	// SRID_UTM_SOUTH_BASE Base of SRIDs for UTM zones of southern hemisphere (WGS84): 32701 for zone 1 and up to 32760 for zone 60
+	SRID_UTM_SOUTH_BASE = 32700
+)
+
+// Projection Transforms coordinates between WGS84 longitude/latitude (degrees) and projected CRS
+/*
+	Internally every point with SRID other than 0 is stored on sphere (as for SRID = 4326),
+	so projection is used only when coordinates come in and out of library
+*/
+type Projection interface {
+	// Forward Returns projected coordinates for WGS84 longitude and latitude
+	Forward(lon, lat float64) (x, y float64)
+	// Inverse Returns WGS84 longitude and latitude for projected coordinates
+	Inverse(x, y float64) (lon, lat float64)
+}
+
+var projections = struct {
+	sync.RWMutex
+	bySRID map[int]Projection
+}{
+	bySRID: make(map[int]Projection),
+}
+
+// RegisterProjection Registers custom projection for the SRID. Registered projection takes precedence over built-in one
+/*
+	srid - SRID. 0 and 4326 are reserved
+	projection - transformation for the SRID
+*/
+func RegisterProjection(srid int, projection Projection) error {
+	if srid == 0 || srid == 4326 {
+		return ErrReservedSRID
+	}
+	projections.Lock()
+	projections.bySRID[srid] = projection
+	projections.Unlock()
+	return nil
+}
+
+// UnregisterProjection Removes custom projection for the SRID. Built-in projections could not be removed
+func UnregisterProjection(srid int) {
+	projections.Lock()
+	delete(projections.bySRID, srid)
+	projections.Unlock()
+}
+
+// ProjectionBySRID Returns projection for the SRID
+/*
+	srid - SRID. Custom projections (see RegisterProjection) are checked first, then built-in ones:
+		3857 (and its legacy alias 900913) - Web Mercator
+		32601-32660 - UTM zones of northern hemisphere
+		32701-32760 - UTM zones of southern hemisphere
+*/
+func ProjectionBySRID(srid int) (Projection, error) {
+	projections.RLock()
+	projection, ok := projections.bySRID[srid]
+	projections.RUnlock()
+	if ok {
+		return projection, nil
+	}
+	switch {
+	case srid == SRID_WEB_MERCATOR || srid == 900913:
+		return WebMercator{}, nil
+	case srid > SRID_UTM_NORTH_BASE && srid <= SRID_UTM_NORTH_BASE+60:
+		return NewUTM(srid-SRID_UTM_NORTH_BASE, false)
+	case srid > SRID_UTM_SOUTH_BASE && srid <= SRID_UTM_SOUTH_BASE+60:
+		return NewUTM(srid-SRID_UTM_SOUTH_BASE, true)
+	}
+	return nil, errors.Wrapf(ErrUnknownSRID, "SRID %d", srid)
+}
+
+// IsSRIDSupported Checks if coordinates in the SRID could be handled
+func IsSRIDSupported(srid int) bool {
+	if srid == 0 || srid == 4326 {
+		return true
+	}
+	_, err := ProjectionBySRID(srid)
+	return err == nil
+}
+
+// S2PointFromSRID Returns s2.Point for coordinates in the SRID
+/*
+	x - longitude for SRID = 4326, X for SRID = 0, easting for projected CRS
+	y - latitude for SRID = 4326, Y for SRID = 0, northing for projected CRS
+	srid - SRID
+
+	For SRID = 0 returned point holds raw Cartesian coordinates (see NewEuclideanS2Point), otherwise it is a point on sphere
+*/
+func S2PointFromSRID(x, y float64, srid int) (s2.Point, error) {
+	switch srid {
+	case 0:
+		return NewEuclideanS2Point(x, y), nil
+	case 4326:
+		return s2.PointFromLatLng(s2.LatLngFromDegrees(y, x)), nil
+	}
+	projection, err := ProjectionBySRID(srid)
+	if err != nil {
+		return s2.Point{}, err
+	}
+	lon, lat := projection.Inverse(x, y)
+	return s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lon)), nil
+}
+
+// S2PointToSRID Returns coordinates of s2.Point in the SRID (see S2PointFromSRID)
+func S2PointToSRID(pt s2.Point, srid int) (x, y float64, err error) {
+	if srid == 0 {
+		return pt.X, pt.Y, nil
+	}
+	latLng := s2.LatLngFromPoint(pt)
+	if srid == 4326 {
+		return latLng.Lng.Degrees(), latLng.Lat.Degrees(), nil
+	}
+	projection, err := ProjectionBySRID(srid)
+	if err != nil {
+		return 0, 0, err
+	}
+	x, y = projection.Forward(latLng.Lng.Degrees(), latLng.Lat.Degrees())
+	return x, y, nil
+}
+
+const (
+	// webMercatorRadius Radius of sphere used by Web Mercator
+	webMercatorRadius = 6378137.0
+	// webMercatorMaxLatitude Latitude at which Web Mercator map becomes square
+	webMercatorMaxLatitude = 85.05112877980659
+)
+
+// WebMercator Spherical Mercator projection used by web maps (EPSG:3857)
+type WebMercator struct{}
+
+// Forward See Projection interface. Latitude is clamped to [-85.0511, 85.0511]
+func (WebMercator) Forward(lon, lat float64) (x, y float64) {
+	lat = math.Max(-webMercatorMaxLatitude, math.Min(webMercatorMaxLatitude, lat))
+	x = webMercatorRadius * lon * math.Pi / 180
+	y = webMercatorRadius * math.Log(math.Tan(math.Pi/4+lat*math.Pi/360))
+	return x, y
+}
+
+// Inverse See Projection interface
+func (WebMercator) Inverse(x, y float64) (lon, lat float64) {
+	lon = x / webMercatorRadius * 180 / math.Pi
+	lat = (2*math.Atan(math.Exp(y/webMercatorRadius)) - math.Pi/2) * 180 / math.Pi
+	return lon, lat
+}
+
+const (
+	// WGS84 ellipsoid
+	wgs84SemiMajorAxis = 6378137.0
+	wgs84Flattening    = 1 / 298.257223563
+	// UTM parameters
+	utmScale         = 0.9996
+	utmFalseEasting  = 500000.0
+	utmFalseNorthing = 10000000.0
+)
+
+// UTM Universal Transverse Mercator projection on WGS84 ellipsoid
+/*
+	Transformations use Krüger series up to third order of third flattening (accuracy is about millimeter within the zone),
+	see https://en.wikipedia.org/wiki/Universal_Transverse_Mercator_coordinate_system#Simplified_formulae
+*/
+type UTM struct {
+	zone  int
+	south bool
+	// Central meridian in radians
+	lon0 float64
+	// Parameters of series
+	n     float64
+	a     float64
+	alpha [3]float64
+	beta  [3]float64
+	delta [3]float64
+}
+
+// NewUTM Returns pointer to created UTM projection
+/*
+	zone - UTM zone in range [1, 60]
+	south - true for southern hemisphere (false northing is 10000 km)
+*/
+func NewUTM(zone int, south bool) (*UTM, error) {
+	if zone < 1 || zone > 60 {
+		return nil, ErrUTMZone
+	}
+	n := wgs84Flattening / (2 - wgs84Flattening)
+	n2, n3 := n*n, n*n*n
+	return &UTM{
+		zone:  zone,
+		south: south,
+		lon0:  float64(6*zone-183) * math.Pi / 180,
+		n:     n,
+		a:     wgs84SemiMajorAxis / (1 + n) * (1 + n2/4 + n2*n2/64),
+		alpha: [3]float64{n/2 - 2*n2/3 + 5*n3/16, 13*n2/48 - 3*n3/5, 61 * n3 / 240},
+		beta:  [3]float64{n/2 - 2*n2/3 + 37*n3/96, n2/48 + n3/15, 17 * n3 / 480},
+		delta: [3]float64{2*n - 2*n2/3 - 2*n3, 7*n2/3 - 8*n3/5, 56 * n3 / 15},
+	}, nil
+}
+
+// Zone Returns UTM zone
+func (utm *UTM) Zone() int {
+	return utm.zone
+}
+
+// IsSouth Returns true if projection is for southern hemisphere
+func (utm *UTM) IsSouth() bool {
+	return utm.south
+}
+
+// Forward See Projection interface
+func (utm *UTM) Forward(lon, lat float64) (x, y float64) {
+	phi := lat * math.Pi / 180
+	dLambda := lon*math.Pi/180 - utm.lon0
+	k := 2 * math.Sqrt(utm.n) / (1 + utm.n)
+	t := math.Sinh(math.Atanh(math.Sin(phi)) - k*math.Atanh(k*math.Sin(phi)))
+	xiPrime := math.Atan2(t, math.Cos(dLambda))
+	etaPrime := math.Atanh(math.Sin(dLambda) / math.Sqrt(1+t*t))
+	xi, eta := xiPrime, etaPrime
+	for j := 1; j <= 3; j++ {
+		xi += utm.alpha[j-1] * math.Sin(2*float64(j)*xiPrime) * math.Cosh(2*float64(j)*etaPrime)
+		eta += utm.alpha[j-1] * math.Cos(2*float64(j)*xiPrime) * math.Sinh(2*float64(j)*etaPrime)
+	}
+	x = utmFalseEasting + utmScale*utm.a*eta
+	y = utmScale * utm.a * xi
+	if utm.south {
+		y += utmFalseNorthing
+	}
+	return x, y
+}
+
+// Inverse See Projection interface
+func (utm *UTM) Inverse(x, y float64) (lon, lat float64) {
+	if utm.south {
+		y -= utmFalseNorthing
+	}
+	xi := y / (utmScale * utm.a)
+	eta := (x - utmFalseEasting) / (utmScale * utm.a)
+	xiPrime, etaPrime := xi, eta
+	for j := 1; j <= 3; j++ {
+		xiPrime -= utm.beta[j-1] * math.Sin(2*float64(j)*xi) * math.Cosh(2*float64(j)*eta)
+		etaPrime -= utm.beta[j-1] * math.Cos(2*float64(j)*xi) * math.Sinh(2*float64(j)*eta)
+	}
+	chi := math.Asin(math.Sin(xiPrime) / math.Cosh(etaPrime))
+	phi := chi
+	for j := 1; j <= 3; j++ {
+		phi += utm.delta[j-1] * math.Sin(2*float64(j)*chi)
+	}
+	lambda := utm.lon0 + math.Atan2(math.Sinh(etaPrime), math.Cos(xiPrime))
+	return lambda * 180 / math.Pi, phi * 180 / math.Pi
+}
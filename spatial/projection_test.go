@@ -0,0 +1,123 @@
+package spatial
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestWebMercator(t *testing.T) {
+	projection, err := ProjectionBySRID(SRID_WEB_MERCATOR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		lon, lat float64
+		x, y     float64
+	}{
+		{0, 0, 0, 0},
+		{180, 0, 20037508.342789244, 0},
+		{-180, webMercatorMaxLatitude, -20037508.342789244, 20037508.342789244},
+	}
+	eps := 1e-3
+	for _, c := range cases {
+		x, y := projection.Forward(c.lon, c.lat)
+		if math.Abs(x-c.x) > eps || math.Abs(y-c.y) > eps {
+			t.Errorf("Point (%f, %f) should be projected to (%f, %f), but got (%f, %f)", c.lon, c.lat, c.x, c.y, x, y)
+		}
+		lon, lat := projection.Inverse(x, y)
+		if math.Abs(lon-c.lon) > 1e-9 || math.Abs(lat-c.lat) > 1e-9 {
+			t.Errorf("Point (%f, %f) should be restored to (%f, %f), but got (%f, %f)", x, y, c.lon, c.lat, lon, lat)
+		}
+	}
+}
+
+func TestUTM(t *testing.T) {
+	cases := []struct {
+		srid     int
+		lon, lat float64
+		x, y     float64
+		eps      float64
+	}{
+		// Central meridian of zone 31 on equator
+		{32631, 3, 0, 500000, 0, 1e-6},
+		// Reference values are evaluated via Snyder's series (USGS Professional Paper 1395, p. 61)
+		{32631, 2.294481, 48.858370, 448250.577, 5411951.588, 0.01},
+		// Southern hemisphere
+		{32723, -43.210467, -22.951916, 683479.872, 7460685.495, 0.01},
+	}
+	for _, c := range cases {
+		projection, err := ProjectionBySRID(c.srid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y := projection.Forward(c.lon, c.lat)
+		if math.Abs(x-c.x) > c.eps || math.Abs(y-c.y) > c.eps {
+			t.Errorf("SRID %d: point (%f, %f) should be projected to (%f, %f), but got (%f, %f)", c.srid, c.lon, c.lat, c.x, c.y, x, y)
+		}
+		lon, lat := projection.Inverse(x, y)
+		if math.Abs(lon-c.lon) > 1e-8 || math.Abs(lat-c.lat) > 1e-8 {
+			t.Errorf("SRID %d: point (%f, %f) should be restored to (%f, %f), but got (%f, %f)", c.srid, x, y, c.lon, c.lat, lon, lat)
+		}
+	}
+
+	_, err := NewUTM(61, false)
+	if err != ErrUTMZone {
+		t.Errorf("Expected error '%v', but got '%v'", ErrUTMZone, err)
+	}
+}
+
+// shiftedProjection Plain coordinates shifted from WGS84 ones (for testing registry)
+type shiftedProjection struct {
+	dx, dy float64
+}
+
+func (p shiftedProjection) Forward(lon, lat float64) (float64, float64) {
+	return lon + p.dx, lat + p.dy
+}
+
+func (p shiftedProjection) Inverse(x, y float64) (float64, float64) {
+	return x - p.dx, y - p.dy
+}
+
+func TestProjectionRegistry(t *testing.T) {
+	srid := 990001
+	if IsSRIDSupported(srid) {
+		t.Fatalf("SRID %d should not be supported before registration", srid)
+	}
+	_, err := NewGeoPoint(10, 10, srid)
+	if errors.Cause(err) != ErrUnknownSRID {
+		t.Errorf("Expected error '%v', but got '%v'", ErrUnknownSRID, err)
+	}
+	err = RegisterProjection(4326, shiftedProjection{})
+	if err != ErrReservedSRID {
+		t.Errorf("Expected error '%v', but got '%v'", ErrReservedSRID, err)
+	}
+
+	err = RegisterProjection(srid, shiftedProjection{dx: 100, dy: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterProjection(srid)
+	gp, err := NewGeoPoint(137.6, 65.7, srid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wgs84 := NewWGS84Point(37.6, 55.7)
+	if distance := gp.DistanceTo(wgs84); distance > 1e-6 {
+		t.Errorf("Points should be the same, but distance is %f", distance)
+	}
+	x, y := gp.Coordinates()
+	if math.Abs(x-137.6) > 1e-9 || math.Abs(y-65.7) > 1e-9 {
+		t.Errorf("Coordinates should be (137.6, 65.7), but got (%f, %f)", x, y)
+	}
+	x, y, err = S2PointToSRID(gp.Point, SRID_WEB_MERCATOR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedX, expectedY := WebMercator{}.Forward(37.6, 55.7)
+	if math.Abs(x-expectedX) > 1e-3 || math.Abs(y-expectedY) > 1e-3 {
+		t.Errorf("Coordinates in Web Mercator should be (%f, %f), but got (%f, %f)", expectedX, expectedY, x, y)
+	}
+}
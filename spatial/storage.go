@@ -1,6 +1,15 @@
 package spatial
 
-import "github.com/golang/geo/s2"
+import (
+	"fmt"
+
+	"github.com/golang/geo/s2"
+)
+
+var (
+	// ErrEdgeNotFound is returned when edge is not presented in storage
+	ErrEdgeNotFound = fmt.Errorf("edge not found in storage")
+)
 
 // StorageType represents the type of spatial storage
 type StorageType int
@@ -13,10 +22,19 @@ const (
 )
 
 // Storage is the interface for spatial storage implementations
+// Implementations are safe for concurrent readers while edges are being added, removed or updated
 type Storage interface {
 	// AddEdge adds an edge to the storage
 	AddEdge(edgeID uint64, edge *Edge) error
 
+	// RemoveEdge removes an edge from the storage
+	// Returns ErrEdgeNotFound if there is no edge with given ID
+	RemoveEdge(edgeID uint64) error
+
+	// UpdateEdge replaces geometry (and other data) of the existing edge and reindexes it
+	// Returns ErrEdgeNotFound if there is no edge with given ID
+	UpdateEdge(edgeID uint64, edge *Edge) error
+
 	// GetEdge returns an edge by ID
 	GetEdge(edgeID uint64) *Edge
 
@@ -36,6 +54,17 @@ type Storage interface {
 	// For spherical: uses s2.Point on unit sphere
 	// For Euclidean: uses s2.Point.Vector.X/Y as Cartesian coordinates
 	FindNearest(pt s2.Point, n int) ([]NearestObject, error)
+
+	// FindInRect returns identifiers (sorted ascending) of edges having common points with rectangle
+	// lo and hi are opposite corners of rectangle
+	// For spherical: rectangle is built on latitudes and longitudes of points
+	// For Euclidean: rectangle is built on s2.Point.Vector.X/Y
+	FindInRect(lo, hi s2.Point) ([]uint64, error)
+
+	// FindInPolygon returns identifiers (sorted ascending) of edges having common points with polygon
+	// For spherical: polygon is regular *s2.Polygon
+	// For Euclidean: loops of polygon hold raw Cartesian coordinates (see RingsToS2Polygon with SRID = 0)
+	FindInPolygon(polygon *s2.Polygon) ([]uint64, error)
 }
 
 // StorageOptions holds configuration for creating a Storage
@@ -43,6 +72,8 @@ type StorageOptions struct {
 	StorageType  StorageType
 	StorageLevel int // S2 cell level for spherical storage
 	BTreeDegree  int // B-tree degree
+	// Exact point-to-edge distances for spherical storage (see S2Storage.SetExactDistances)
+	ExactDistances bool
 }
 
 // StorageOption is a functional option for configuring Storage
@@ -62,6 +93,14 @@ func WithBTreeDegree(degree int) StorageOption {
 	}
 }
 
+// WithExactDistances enables exact point-to-edge distances for spherical storage
+// Euclidean storage always measures exact distances
+func WithExactDistances() StorageOption {
+	return func(o *StorageOptions) {
+		o.ExactDistances = true
+	}
+}
+
 // NewStorage creates a new Storage based on provided options
 func NewStorage(storageType StorageType, opts ...StorageOption) Storage {
 	// Default options
@@ -78,7 +117,9 @@ func NewStorage(storageType StorageType, opts ...StorageOption) Storage {
 
 	switch options.StorageType {
 	case StorageTypeSpherical:
-		return NewS2Storage(options.StorageLevel, options.BTreeDegree)
+		storage := NewS2Storage(options.StorageLevel, options.BTreeDegree)
+		storage.SetExactDistances(options.ExactDistances)
+		return storage
 	case StorageTypeEuclidean:
 		return NewEuclideanStorage()
 	default:
@@ -1,38 +1,82 @@
 package spatial
 
 import (
-	"container/heap"
 	"math"
+	"sort"
+	"sync"
 
 	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
 	"github.com/tidwall/rtree"
 )
 
 // EuclideanStorage Spatial datastore for Euclidean/Cartesian coordinates
+/*
+	rtree - R-tree of edges' bounding boxes
+	edges - map of edges
+	bounds - bounding boxes of indexed edges (R-tree deletion needs exact box). Edges without geometry are not indexed
+	mu - guards R-tree and maps: searches are done under read lock, modifications under write lock
+*/
 type EuclideanStorage struct {
-	rtree *rtree.RTreeG[uint64]
-	edges map[uint64]*Edge
+	rtree  *rtree.RTreeG[uint64]
+	edges  map[uint64]*Edge
+	bounds map[uint64][2][2]float64
+	mu     sync.RWMutex
 }
 
 // NewEuclideanStorage Returns pointer to created EuclideanStorage
 func NewEuclideanStorage() *EuclideanStorage {
 	return &EuclideanStorage{
-		rtree: &rtree.RTreeG[uint64]{},
-		edges: make(map[uint64]*Edge),
+		rtree:  &rtree.RTreeG[uint64]{},
+		edges:  make(map[uint64]*Edge),
+		bounds: make(map[uint64][2][2]float64),
 	}
 }
 
 // GetEdge Returns edge by ID from storage
 func (storage *EuclideanStorage) GetEdge(edgeID uint64) *Edge {
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
 	return storage.edges[edgeID]
 }
 
 // AddEdge Add edge (polyline) to storage
+// If edge with the same identifier is already presented in storage then it is replaced
 func (storage *EuclideanStorage) AddEdge(edgeID uint64, edge *Edge) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.removeEdge(edgeID)
+	storage.insertEdge(edgeID, edge)
+	return nil
+}
+
+// RemoveEdge Removes edge from storage
+func (storage *EuclideanStorage) RemoveEdge(edgeID uint64) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	if !storage.removeEdge(edgeID) {
+		return errors.Wrapf(ErrEdgeNotFound, "can't remove edge %d", edgeID)
+	}
+	return nil
+}
+
+// UpdateEdge Replaces existing edge in storage and reindexes it with new geometry
+func (storage *EuclideanStorage) UpdateEdge(edgeID uint64, edge *Edge) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	if !storage.removeEdge(edgeID) {
+		return errors.Wrapf(ErrEdgeNotFound, "can't update edge %d", edgeID)
+	}
+	storage.insertEdge(edgeID, edge)
+	return nil
+}
+
+// insertEdge Indexes edge in R-tree. Caller must hold write lock
+func (storage *EuclideanStorage) insertEdge(edgeID uint64, edge *Edge) {
+	storage.edges[edgeID] = edge
 	// Calculate bounding box from polyline points
 	if edge.Polyline == nil || len(*edge.Polyline) == 0 {
-		storage.edges[edgeID] = edge
-		return nil
+		return
 	}
 
 	// Find min/max coordinates
@@ -66,18 +110,43 @@ func (storage *EuclideanStorage) AddEdge(edgeID uint64, edge *Edge) error {
 	}
 
 	// Insert into R-tree: [minX, minY], [maxX, maxY], data
-	storage.rtree.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, edgeID)
+	box := [2][2]float64{{minX, minY}, {maxX, maxY}}
+	storage.rtree.Insert(box[0], box[1], edgeID)
+	storage.bounds[edgeID] = box
+}
 
-	storage.edges[edgeID] = edge
-	return nil
+// removeEdge Removes edge from R-tree and maps. Returns false if there is no such edge. Caller must hold write lock
+func (storage *EuclideanStorage) removeEdge(edgeID uint64) bool {
+	if _, ok := storage.edges[edgeID]; !ok {
+		return false
+	}
+	if box, ok := storage.bounds[edgeID]; ok {
+		storage.rtree.Delete(box[0], box[1], edgeID)
+		delete(storage.bounds, edgeID)
+	}
+	delete(storage.edges, edgeID)
+	return true
 }
 
 // FindInRadius implements Storage interface
 // For EuclideanStorage: uses pt.Vector.X/Y as Cartesian coordinates
 func (storage *EuclideanStorage) FindInRadius(pt s2.Point, radiusMeters float64) (map[uint64]float64, error) {
+	found := storage.findInRadius(pt, radiusMeters)
+	result := make(map[uint64]float64, len(found))
+	for edgeID, obj := range found {
+		result[edgeID] = obj.DistanceTo
+	}
+	return result, nil
+}
+
+// findInRadius Returns edges within radius with projections of the point onto them
+func (storage *EuclideanStorage) findInRadius(pt s2.Point, radiusMeters float64) map[uint64]NearestObject {
 	x, y := pt.Vector.X, pt.Vector.Y
 
-	result := make(map[uint64]float64)
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+
+	result := make(map[uint64]NearestObject)
 
 	// Search R-tree for candidates in bounding box
 	storage.rtree.Search(
@@ -90,53 +159,36 @@ func (storage *EuclideanStorage) FindInRadius(pt s2.Point, radiusMeters float64)
 			}
 
 			// Calculate minimum distance from point to edge polyline
-			minDist := math.MaxFloat64
+			obj := NearestObject{EdgeID: edgeID, DistanceTo: math.MaxFloat64, SegmentIdx: -1}
 			for i := 0; i < len(*edge.Polyline)-1; i++ {
 				p1 := (*edge.Polyline)[i]
 				p2 := (*edge.Polyline)[i+1]
-				dist := pointToSegmentDistance(x, y, p1.Vector.X, p1.Vector.Y, p2.Vector.X, p2.Vector.Y)
-				if dist < minDist {
-					minDist = dist
+				projX, projY, dist := projectOnSegment(x, y, p1.Vector.X, p1.Vector.Y, p2.Vector.X, p2.Vector.Y)
+				if dist < obj.DistanceTo {
+					obj.DistanceTo = dist
+					obj.ProjectedPoint = NewEuclideanS2Point(projX, projY)
+					obj.SegmentIdx = i
 				}
 			}
 
 			// Only include if within radius
-			if minDist <= radiusMeters {
-				result[edgeID] = minDist
+			if obj.DistanceTo <= radiusMeters {
+				result[edgeID] = obj
 			}
 
 			return true // continue searching
 		},
 	)
 
-	return result, nil
+	return result
 }
 
 // FindNearestInRadius implements Storage interface
 // For EuclideanStorage: uses pt.Vector.X/Y as Cartesian coordinates
 func (storage *EuclideanStorage) FindNearestInRadius(pt s2.Point, radiusMeters float64, n int) ([]NearestObject, error) {
-	found, err := storage.FindInRadius(pt, radiusMeters)
-	if err != nil {
-		return nil, err
-	}
-
+	found := storage.findInRadius(pt, radiusMeters)
 	// Use heap for top-N selection
-	h := &nearestHeap{}
-	heap.Init(h)
-	for k, v := range found {
-		heap.Push(h, NearestObject{k, v})
-	}
-
-	l := h.Len()
-	if l < n {
-		n = l
-	}
-
-	ans := make([]NearestObject, n)
-	for i := 0; i < n; i++ {
-		ans[i] = heap.Pop(h).(NearestObject)
-	}
-	return ans, nil
+	return selectNearest(found, n), nil
 }
 
 // FindNearest implements Storage interface using iterative radius expansion
@@ -153,22 +205,18 @@ func (storage *EuclideanStorage) FindNearest(pt s2.Point, n int) ([]NearestObjec
 	// Max 100 km
 	maxRadius := 100000.0
 
-	var found map[uint64]float64
-	var err error
+	var found map[uint64]NearestObject
 
 	for radius <= maxRadius {
-		found, err = storage.FindInRadius(pt, radius)
-		if err != nil {
-			return nil, err
-		}
+		found = storage.findInRadius(pt, radius)
 
 		if len(found) >= n {
 			// Check if closest edge is within our search radius
 			// This ensures we haven't missed any closer edges
 			minDist := math.MaxFloat64
-			for _, dist := range found {
-				if dist < minDist {
-					minDist = dist
+			for _, obj := range found {
+				if obj.DistanceTo < minDist {
+					minDist = obj.DistanceTo
 				}
 			}
 			// If closest is well within radius, we can stop
@@ -186,35 +234,71 @@ func (storage *EuclideanStorage) FindNearest(pt s2.Point, n int) ([]NearestObjec
 	}
 
 	// Use heap for top-N selection
-	h := &nearestHeap{}
-	heap.Init(h)
-	for edgeID, dist := range found {
-		heap.Push(h, NearestObject{edgeID, dist})
-	}
+	return selectNearest(found, n), nil
 
-	l := h.Len()
-	if l < n {
-		n = l
-	}
+	// @todo
+	// consider alternative use of tidwall/rtree library functions of KNN
+}
+
+// FindInRect implements Storage interface
+// For EuclideanStorage: uses lo.Vector.X/Y and hi.Vector.X/Y as Cartesian coordinates of rectangle corners
+func (storage *EuclideanStorage) FindInRect(lo, hi s2.Point) ([]uint64, error) {
+	minX, maxX := math.Min(lo.X, hi.X), math.Max(lo.X, hi.X)
+	minY, maxY := math.Min(lo.Y, hi.Y), math.Max(lo.Y, hi.Y)
+	return storage.findInBox(minX, minY, maxX, maxY, func(polyline s2.Polyline) bool {
+		return PolylineIntersectsRectEuclidean(polyline, minX, minY, maxX, maxY)
+	}), nil
+}
 
-	ans := make([]NearestObject, n)
-	for i := 0; i < n; i++ {
-		ans[i] = heap.Pop(h).(NearestObject)
+// FindInPolygon implements Storage interface
+// For EuclideanStorage: loops of polygon hold Cartesian coordinates
+func (storage *EuclideanStorage) FindInPolygon(polygon *s2.Polygon) ([]uint64, error) {
+	if polygon == nil || polygon.NumLoops() == 0 {
+		return []uint64{}, nil
 	}
-	return ans, nil
+	minX, minY, maxX, maxY := PolygonBoundsEuclidean(polygon)
+	return storage.findInBox(minX, minY, maxX, maxY, func(polyline s2.Polyline) bool {
+		return PolylineIntersectsPolygonEuclidean(polyline, polygon)
+	}), nil
+}
 
-	// @todo
-	// consider alternative use of tidwall/rtree library functions of KNN
+// findInBox Returns sorted identifiers of edges which bounding boxes intersect given box and which pass exact check
+func (storage *EuclideanStorage) findInBox(minX, minY, maxX, maxY float64, intersects func(polyline s2.Polyline) bool) []uint64 {
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+	result := []uint64{}
+	storage.rtree.Search(
+		[2]float64{minX, minY},
+		[2]float64{maxX, maxY},
+		func(min, max [2]float64, edgeID uint64) bool {
+			edge := storage.edges[edgeID]
+			if edge == nil || edge.Polyline == nil {
+				return true
+			}
+			if intersects(*edge.Polyline) {
+				result = append(result, edgeID)
+			}
+			return true
+		},
+	)
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
 }
 
 // pointToSegmentDistance calculates the minimum distance from point (px, py) to line segment (x1,y1)-(x2,y2)
 func pointToSegmentDistance(px, py, x1, y1, x2, y2 float64) float64 {
+	_, _, dist := projectOnSegment(px, py, x1, y1, x2, y2)
+	return dist
+}
+
+// projectOnSegment Returns the closest to point (px, py) point of line segment (x1,y1)-(x2,y2) and distance to it
+func projectOnSegment(px, py, x1, y1, x2, y2 float64) (float64, float64, float64) {
 	dx := x2 - x1
 	dy := y2 - y1
 
 	if dx == 0 && dy == 0 {
 		// Segment is a point
-		return math.Sqrt((px-x1)*(px-x1) + (py-y1)*(py-y1))
+		return x1, y1, math.Sqrt((px-x1)*(px-x1) + (py-y1)*(py-y1))
 	}
 
 	// Parameter t for the projection of point onto the line
@@ -222,14 +306,14 @@ func pointToSegmentDistance(px, py, x1, y1, x2, y2 float64) float64 {
 
 	if t < 0 {
 		// Closest to first endpoint
-		return math.Sqrt((px-x1)*(px-x1) + (py-y1)*(py-y1))
+		return x1, y1, math.Sqrt((px-x1)*(px-x1) + (py-y1)*(py-y1))
 	} else if t > 1 {
 		// Closest to second endpoint
-		return math.Sqrt((px-x2)*(px-x2) + (py-y2)*(py-y2))
+		return x2, y2, math.Sqrt((px-x2)*(px-x2) + (py-y2)*(py-y2))
 	}
 
 	// Closest to interior point
 	projX := x1 + t*dx
 	projY := y1 + t*dy
-	return math.Sqrt((px-projX)*(px-projX) + (py-projY)*(py-projY))
+	return projX, projY, math.Sqrt((px-projX)*(px-projX) + (py-projY)*(py-projY))
 }
@@ -1,9 +1,12 @@
 package spatial
 
 import (
+	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
 )
 
 func TestEuclideanStorageBasic(t *testing.T) {
@@ -167,3 +170,173 @@ func TestEuclideanStorageFindNearest(t *testing.T) {
 		t.Errorf("Expected empty result for n=0, got %d", len(nearest0))
 	}
 }
+
+// euclideanGridEdge Returns horizontal unit segment starting at (x, y)
+func euclideanGridEdge(x, y float64) *Edge {
+	poly := s2.Polyline{NewEuclideanS2Point(x, y), NewEuclideanS2Point(x+1, y)}
+	return &Edge{Polyline: &poly}
+}
+
+func TestEuclideanStorageRemoveUpdate(t *testing.T) {
+	storage := NewEuclideanStorage()
+	final := map[uint64]*Edge{}
+	for row := 0; row < 10; row++ {
+		for col := 0; col < 10; col++ {
+			edgeID := uint64(row*10 + col)
+			edge := euclideanGridEdge(float64(col*2), float64(row*2))
+			storage.AddEdge(edgeID, edge)
+			final[edgeID] = edge
+		}
+	}
+	// Churn: remove every third edge, move every fifth one, re-add some removed ones with new geometry
+	for edgeID := uint64(0); edgeID < 100; edgeID++ {
+		switch {
+		case edgeID%3 == 0:
+			if err := storage.RemoveEdge(edgeID); err != nil {
+				t.Fatal(err)
+			}
+			delete(final, edgeID)
+		case edgeID%5 == 0:
+			edge := euclideanGridEdge(float64(edgeID%10*2)+0.5, float64(edgeID/10*2)+1)
+			if err := storage.UpdateEdge(edgeID, edge); err != nil {
+				t.Fatal(err)
+			}
+			final[edgeID] = edge
+		}
+	}
+	for edgeID := uint64(0); edgeID < 100; edgeID += 9 {
+		edge := euclideanGridEdge(float64(edgeID/10*2), float64(edgeID%10*2)+0.5)
+		storage.AddEdge(edgeID, edge)
+		final[edgeID] = edge
+	}
+
+	if err := storage.RemoveEdge(1000); !errors.Is(err, ErrEdgeNotFound) {
+		t.Errorf("Removing unknown edge should return ErrEdgeNotFound, got %v", err)
+	}
+	if err := storage.UpdateEdge(3, euclideanGridEdge(0, 0)); !errors.Is(err, ErrEdgeNotFound) {
+		t.Errorf("Updating removed edge should return ErrEdgeNotFound, got %v", err)
+	}
+	if storage.rtree.Len() != len(final) {
+		t.Errorf("R-tree should contain %d edges, got %d", len(final), storage.rtree.Len())
+	}
+
+	// Compare search results with brute force over the final set of edges
+	for x := 0.0; x < 20; x += 1.5 {
+		for y := 0.0; y < 20; y += 1.5 {
+			pt := NewEuclideanS2Point(x, y)
+			expected := map[uint64]float64{}
+			for edgeID, edge := range final {
+				a, b := (*edge.Polyline)[0], (*edge.Polyline)[1]
+				dist := pointToSegmentDistance(x, y, a.X, a.Y, b.X, b.Y)
+				if dist <= 1.2 {
+					expected[edgeID] = dist
+				}
+			}
+			found, err := storage.FindInRadius(pt, 1.2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(expected, found) {
+				t.Errorf("Search around (%f, %f) after churn should return %v, got %v", x, y, expected, found)
+			}
+		}
+	}
+}
+
+func TestEuclideanStorageConcurrentReaders(t *testing.T) {
+	storage := NewEuclideanStorage()
+	for edgeID := 0; edgeID < 50; edgeID++ {
+		storage.AddEdge(uint64(edgeID), euclideanGridEdge(float64(edgeID%10*2), float64(edgeID/10*2)))
+	}
+	pt := NewEuclideanS2Point(5, 5)
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if _, err := storage.FindNearest(pt, 5); err != nil {
+					t.Error(err)
+					return
+				}
+				storage.GetEdge(7)
+			}
+		}()
+	}
+	for i := 0; i < 500; i++ {
+		edgeID := uint64(i % 50)
+		storage.RemoveEdge(edgeID)
+		storage.AddEdge(edgeID, euclideanGridEdge(float64(edgeID%10*2)+float64(i%3)*0.1, float64(edgeID/10*2)))
+	}
+	close(done)
+	wg.Wait()
+	if storage.rtree.Len() != 50 {
+		t.Errorf("R-tree should contain 50 edges after churn, got %d", storage.rtree.Len())
+	}
+}
+
+func TestEuclideanStorageFindInRegion(t *testing.T) {
+	storage := NewEuclideanStorage()
+	for row := 0; row < 5; row++ {
+		for col := 0; col < 5; col++ {
+			storage.AddEdge(uint64(row*5+col), euclideanGridEdge(float64(col*2), float64(row*2)))
+		}
+	}
+	// Corners are given in arbitrary order. Edge 0 crosses the rectangle while none of its vertices are inside
+	found, err := storage.FindInRect(NewEuclideanS2Point(0.5, 2.5), NewEuclideanS2Point(0.2, -0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found, []uint64{0, 5}) {
+		t.Errorf("Edges in rectangle should be [0 5], got %v", found)
+	}
+	// Bounding box of edge 6 intersects the rectangle, but edge itself doesn't
+	found, err = storage.FindInRect(NewEuclideanS2Point(2.1, 2.1), NewEuclideanS2Point(2.9, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 0 {
+		t.Errorf("Rectangle above edge 6 should contain no edges, got %v", found)
+	}
+
+	// Triangle which bounding box covers edges 0, 1, 5, 6, but triangle itself touches only 0, 1 and 5
+	polygon, err := RingsToS2Polygon([][][]float64{{{0, -1}, {4, -1}, {0, 3}}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err = storage.FindInPolygon(polygon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found, []uint64{0, 1, 5}) {
+		t.Errorf("Edges in polygon should be [0 1 5], got %v", found)
+	}
+}
+
+func TestEuclideanStorageProjection(t *testing.T) {
+	storage := NewEuclideanStorage()
+	poly := s2.Polyline{NewEuclideanS2Point(0, 0), NewEuclideanS2Point(2, 0), NewEuclideanS2Point(2, 2)}
+	storage.AddEdge(1, &Edge{Polyline: &poly})
+	nearest, err := storage.FindNearest(NewEuclideanS2Point(3, 1.5), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nearest) != 1 {
+		t.Fatalf("Expected 1 nearest, got %d", len(nearest))
+	}
+	if nearest[0].SegmentIdx != 1 {
+		t.Errorf("Closest segment should be 1, got %d", nearest[0].SegmentIdx)
+	}
+	if nearest[0].ProjectedPoint.X != 2 || nearest[0].ProjectedPoint.Y != 1.5 {
+		t.Errorf("Projection should be (2, 1.5), got (%f, %f)", nearest[0].ProjectedPoint.X, nearest[0].ProjectedPoint.Y)
+	}
+	if nearest[0].DistanceTo != 1 {
+		t.Errorf("Distance should be 1, got %f", nearest[0].DistanceTo)
+	}
+}
@@ -2,22 +2,31 @@ package spatial
 
 import (
 	"container/heap"
+	"sort"
+	"sync"
 
 	"github.com/golang/geo/s1"
 	"github.com/golang/geo/s2"
 	"github.com/google/btree"
+	"github.com/pkg/errors"
 )
 
 // S2Storage Spatial datastore
 /*
 	storageLevel - level for S2
 	edges - map of edges
+	edgeCells - cells covering every edge (needed to clean up b-tree items on removal)
 	BTree - b-tree (wraps)
+	exactDistances - distances are measured from the query point to its projection onto edge instead of from the query's leaf cell
+	mu - guards b-tree and maps: searches are done under read lock, modifications under write lock
 */
 type S2Storage struct {
 	*btree.BTree
-	edges        map[uint64]*Edge
-	storageLevel int
+	edges          map[uint64]*Edge
+	edgeCells      map[uint64][]s2.CellID
+	storageLevel   int
+	exactDistances bool
+	mu             sync.RWMutex
 }
 
 // NewS2Storage Returns pointer to created S2Storage
@@ -30,11 +39,66 @@ func NewS2Storage(storageLevel int, degree int) *S2Storage {
 		storageLevel: storageLevel,
 		BTree:        btree.New(degree),
 		edges:        make(map[uint64]*Edge),
+		edgeCells:    make(map[uint64][]s2.CellID),
 	}
 }
 
+// SetExactDistances Switches mode of distance evaluation for searches
+/*
+	exact - if true then distance from the query point to its projection onto every edge (see s2.DistanceFromSegment) is used
+	for ranking and radius filtering, and NearestObject holds the projection and the index of the closest segment.
+	Otherwise (default) distance is measured from the query's leaf cell, which is faster but biased
+*/
+func (storage *S2Storage) SetExactDistances(exact bool) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.exactDistances = exact
+}
+
+// StorageLevel Returns S2 level of indexed cells
+func (storage *S2Storage) StorageLevel() int {
+	return storage.storageLevel
+}
+
+// ExactDistances Returns true if exact point-to-edge distances are used (see SetExactDistances)
+func (storage *S2Storage) ExactDistances() bool {
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+	return storage.exactDistances
+}
+
+// EdgeCells Returns cells (at storage level) covering the edge. Nil if there is no such edge
+func (storage *S2Storage) EdgeCells(edgeID uint64) []s2.CellID {
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+	return storage.edgeCells[edgeID]
+}
+
+// AddEdgeWithCells Add edge (polyline) to storage using precomputed covering (see EdgeCells)
+/*
+	edgeID - unique identifier
+	edge - edge
+	cells - cells covering the edge. Every cell must be at storage level
+
+	Covering computation is the most expensive part of AddEdge, so this method is used to restore storage from snapshot
+*/
+func (storage *S2Storage) AddEdgeWithCells(edgeID uint64, edge *Edge, cells []s2.CellID) error {
+	for _, cell := range cells {
+		if !cell.IsValid() || cell.Level() != storage.storageLevel {
+			return errors.Errorf("cell %d of edge %d is not valid cell at level %d", uint64(cell), edgeID, storage.storageLevel)
+		}
+	}
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.removeEdge(edgeID)
+	storage.indexEdge(edgeID, edge, cells)
+	return nil
+}
+
 // GetEdge Returns edge by ID from storage
 func (storage *S2Storage) GetEdge(edgeID uint64) *Edge {
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
 	return storage.edges[edgeID]
 }
 
@@ -53,10 +117,55 @@ func (ii indexedItem) Less(than btree.Item) bool {
 /*
 	edgeID - unique identifier
 	edge - edge
+
+	If edge with the same identifier is already presented in storage then it is replaced
 */
 func (storage *S2Storage) AddEdge(edgeID uint64, edge *Edge) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.removeEdge(edgeID)
+	storage.insertEdge(edgeID, edge)
+	return nil
+}
+
+// RemoveEdge Removes edge from storage
+/*
+	edgeID - unique identifier
+
+	Edge identifier is removed from every b-tree item covering the edge. Items which become empty are deleted
+*/
+func (storage *S2Storage) RemoveEdge(edgeID uint64) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	if !storage.removeEdge(edgeID) {
+		return errors.Wrapf(ErrEdgeNotFound, "can't remove edge %d", edgeID)
+	}
+	return nil
+}
+
+// UpdateEdge Replaces existing edge in storage and reindexes it with new geometry
+/*
+	edgeID - unique identifier
+	edge - new edge
+*/
+func (storage *S2Storage) UpdateEdge(edgeID uint64, edge *Edge) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	if !storage.removeEdge(edgeID) {
+		return errors.Wrapf(ErrEdgeNotFound, "can't update edge %d", edgeID)
+	}
+	storage.insertEdge(edgeID, edge)
+	return nil
+}
+
+// insertEdge Indexes edge in b-tree. Caller must hold write lock
+func (storage *S2Storage) insertEdge(edgeID uint64, edge *Edge) {
 	coverer := s2.RegionCoverer{MinLevel: storage.storageLevel, MaxLevel: storage.storageLevel}
-	cells := coverer.Covering(edge.Polyline)
+	storage.indexEdge(edgeID, edge, coverer.Covering(edge.Polyline))
+}
+
+// indexEdge Adds edge to b-tree items of given cells. Caller must hold write lock
+func (storage *S2Storage) indexEdge(edgeID uint64, edge *Edge, cells []s2.CellID) {
 	for _, cell := range cells {
 		ii := indexedItem{CellID: cell}
 		item := storage.BTree.Get(ii)
@@ -67,7 +176,37 @@ func (storage *S2Storage) AddEdge(edgeID uint64, edge *Edge) error {
 		storage.BTree.ReplaceOrInsert(ii)
 	}
 	storage.edges[edgeID] = edge
-	return nil
+	storage.edgeCells[edgeID] = cells
+}
+
+// removeEdge Removes edge from b-tree and maps. Returns false if there is no such edge. Caller must hold write lock
+func (storage *S2Storage) removeEdge(edgeID uint64) bool {
+	if _, ok := storage.edges[edgeID]; !ok {
+		return false
+	}
+	for _, cell := range storage.edgeCells[edgeID] {
+		item := storage.BTree.Get(indexedItem{CellID: cell})
+		if item == nil {
+			continue
+		}
+		ii := item.(indexedItem)
+		// Build new slice instead of filtering in place: items share backing arrays after append
+		edgesInCell := make([]uint64, 0, len(ii.edgesInCell))
+		for _, id := range ii.edgesInCell {
+			if id != edgeID {
+				edgesInCell = append(edgesInCell, id)
+			}
+		}
+		if len(edgesInCell) == 0 {
+			storage.BTree.Delete(ii)
+			continue
+		}
+		ii.edgesInCell = edgesInCell
+		storage.BTree.ReplaceOrInsert(ii)
+	}
+	delete(storage.edges, edgeID)
+	delete(storage.edgeCells, edgeID)
+	return true
 }
 
 // SearchInRadiusLonLat Returns edges in radius
@@ -77,38 +216,7 @@ func (storage *S2Storage) AddEdge(edgeID uint64, edge *Edge) error {
 	radius - radius of search
 */
 func (storage *S2Storage) SearchInRadiusLonLat(lon, lat float64, radius float64) (map[uint64]float64, error) {
-	latlng := s2.LatLngFromDegrees(lat, lon)
-	cell := s2.CellFromLatLng(latlng)
-	centerPoint := s2.PointFromLatLng(latlng)
-	centerAngle := radius / EarthRadius
-	cap := s2.CapFromCenterAngle(centerPoint, s1.Angle(centerAngle))
-	rc := s2.RegionCoverer{MaxLevel: storage.storageLevel, MinLevel: storage.storageLevel}
-	cu := rc.Covering(cap)
-	result := make(map[uint64]float64)
-	for _, cellID := range cu {
-		item := storage.BTree.Get(indexedItem{CellID: cellID})
-		if item != nil {
-			for _, edgeID := range item.(indexedItem).edgesInCell {
-				polyline := storage.edges[edgeID]
-				minEdge := s2.Edge{}
-				minDist := s1.ChordAngle(0)
-				for i := 0; i < polyline.Polyline.NumEdges(); i++ {
-					if i == 0 {
-						minEdge = polyline.Polyline.Edge(0)
-						minDist = cell.DistanceToEdge(minEdge.V0, minEdge.V1)
-						continue
-					}
-					edge := polyline.Polyline.Edge(i)
-					distance := cell.DistanceToEdge(edge.V0, edge.V1)
-					if distance < minDist {
-						minDist = distance
-					}
-				}
-				result[edgeID] = minDist.Angle().Radians() * EarthRadius
-			}
-		}
-	}
-	return result, nil
+	return storage.SearchInRadius(s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lon)), radius)
 }
 
 // FindInRadius implements Storage interface
@@ -125,49 +233,101 @@ func (storage *S2Storage) FindNearestInRadius(pt s2.Point, radiusMeters float64,
 /*
 	pt - s2.Point
 	radius - radius of search
+
+	In exact mode (see SetExactDistances) edges which are farther than radius are not returned
 */
 func (storage *S2Storage) SearchInRadius(pt s2.Point, radius float64) (map[uint64]float64, error) {
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+	found := storage.searchInRadius(pt, radius)
+	result := make(map[uint64]float64, len(found))
+	for edgeID, obj := range found {
+		result[edgeID] = obj.DistanceTo
+	}
+	return result, nil
+}
+
+// searchInRadius Returns edges from cells covering the cap around the point. Caller must hold read lock
+func (storage *S2Storage) searchInRadius(pt s2.Point, radius float64) map[uint64]NearestObject {
 	cell := s2.CellFromPoint(pt)
-	centerPoint := pt
 	centerAngle := radius / EarthRadius
-	cap := s2.CapFromCenterAngle(centerPoint, s1.Angle(centerAngle))
+	cap := s2.CapFromCenterAngle(pt, s1.Angle(centerAngle))
 	rc := s2.RegionCoverer{MaxLevel: storage.storageLevel, MinLevel: storage.storageLevel}
 	cu := rc.Covering(cap)
-	result := make(map[uint64]float64)
+	result := make(map[uint64]NearestObject)
 	for _, cellID := range cu {
 		item := storage.BTree.Get(indexedItem{CellID: cellID})
-		if item != nil {
-			for _, edgeID := range item.(indexedItem).edgesInCell {
-				polyline := storage.edges[edgeID]
-				minEdge := s2.Edge{}
-				minDist := s1.ChordAngle(0)
-				for i := 0; i < polyline.Polyline.NumEdges(); i++ {
-					if i == 0 {
-						minEdge = polyline.Polyline.Edge(0)
-						minDist = cell.DistanceToEdge(minEdge.V0, minEdge.V1)
-						continue
-					}
-					edge := polyline.Polyline.Edge(i)
-					distance := cell.DistanceToEdge(edge.V0, edge.V1)
-					if distance < minDist {
-						minDist = distance
-					}
-				}
-				result[edgeID] = minDist.Angle().Radians() * EarthRadius
+		if item == nil {
+			continue
+		}
+		for _, edgeID := range item.(indexedItem).edgesInCell {
+			if _, ok := result[edgeID]; ok {
+				continue
+			}
+			edge := storage.edges[edgeID]
+			if edge == nil || edge.Polyline == nil {
+				continue
+			}
+			obj := storage.distanceToEdge(cell, pt, *edge.Polyline)
+			if storage.exactDistances && obj.DistanceTo > radius {
+				continue
 			}
+			obj.EdgeID = edgeID
+			result[edgeID] = obj
 		}
 	}
-	return result, nil
+	return result
+}
+
+// distanceToEdge Returns distance from the query to the polyline: either from the query's leaf cell (default mode)
+// or from the point itself with projection onto the closest segment (exact mode). EdgeID is not filled
+func (storage *S2Storage) distanceToEdge(cell s2.Cell, pt s2.Point, polyline s2.Polyline) NearestObject {
+	ans := NearestObject{SegmentIdx: -1}
+	if !storage.exactDistances {
+		minDist := s1.ChordAngle(0)
+		for i := 0; i < polyline.NumEdges(); i++ {
+			edge := polyline.Edge(i)
+			distance := cell.DistanceToEdge(edge.V0, edge.V1)
+			if i == 0 || distance < minDist {
+				minDist = distance
+				ans.SegmentIdx = i
+			}
+		}
+		ans.DistanceTo = minDist.Angle().Radians() * EarthRadius
+		return ans
+	}
+	if len(polyline) == 1 {
+		ans.ProjectedPoint = polyline[0]
+		ans.DistanceTo = pt.Distance(polyline[0]).Radians() * EarthRadius
+		return ans
+	}
+	minDist := s1.Angle(0)
+	for i := 0; i < polyline.NumEdges(); i++ {
+		edge := polyline.Edge(i)
+		distance := s2.DistanceFromSegment(pt, edge.V0, edge.V1)
+		if i == 0 || distance < minDist {
+			minDist = distance
+			ans.SegmentIdx = i
+		}
+	}
+	edge := polyline.Edge(ans.SegmentIdx)
+	ans.ProjectedPoint = s2.Project(pt, edge.V0, edge.V1)
+	ans.DistanceTo = minDist.Radians() * EarthRadius
+	return ans
 }
 
 // NearestObject Nearest object to given point
 /*
 	EdgeID - unique identifier
 	DistanceTo - distance to object
+	ProjectedPoint - projection of the point onto the object. Filled by EuclideanStorage and by S2Storage in exact mode only (see SetExactDistances)
+	SegmentIdx - index of the object's segment closest to the point: projection lies between vertices SegmentIdx and SegmentIdx+1. -1 when unknown
 */
 type NearestObject struct {
-	EdgeID     uint64
-	DistanceTo float64
+	EdgeID         uint64
+	DistanceTo     float64
+	ProjectedPoint s2.Point
+	SegmentIdx     int
 }
 
 // NearestNeighborsInRadius Returns edges in radius with max objects restriction (KNN)
@@ -177,14 +337,18 @@ type NearestObject struct {
 	n - first N closest edges
 */
 func (storage *S2Storage) NearestNeighborsInRadius(pt s2.Point, radius float64, n int) ([]NearestObject, error) {
-	found, err := storage.SearchInRadius(pt, radius)
-	if err != nil {
-		return nil, err
-	}
+	storage.mu.RLock()
+	found := storage.searchInRadius(pt, radius)
+	storage.mu.RUnlock()
+	return selectNearest(found, n), nil
+}
+
+// selectNearest Returns first N closest objects sorted by distance
+func selectNearest(found map[uint64]NearestObject, n int) []NearestObject {
 	h := &nearestHeap{}
 	heap.Init(h)
-	for k, v := range found {
-		heap.Push(h, NearestObject{k, v})
+	for _, obj := range found {
+		heap.Push(h, obj)
 	}
 	l := h.Len()
 	if l < n {
@@ -194,7 +358,64 @@ func (storage *S2Storage) NearestNeighborsInRadius(pt s2.Point, radius float64,
 	for i := 0; i < n; i++ {
 		ans[i] = heap.Pop(h).(NearestObject)
 	}
-	return ans, nil
+	return ans
+}
+
+// maxRegionCells is the maximum number of cells in covering of region for FindInRect and FindInPolygon.
+// Covering cells could be coarser than storage level: every such cell is scanned as range of b-tree items
+const maxRegionCells = 32
+
+// FindInRect implements Storage interface
+func (storage *S2Storage) FindInRect(lo, hi s2.Point) ([]uint64, error) {
+	rect := s2.RectFromLatLng(s2.LatLngFromPoint(lo)).AddPoint(s2.LatLngFromPoint(hi))
+	return storage.findInRegion(rect, func(polyline s2.Polyline) bool {
+		return PolylineIntersectsRect(polyline, rect)
+	}), nil
+}
+
+// FindInPolygon implements Storage interface
+func (storage *S2Storage) FindInPolygon(polygon *s2.Polygon) ([]uint64, error) {
+	if polygon == nil || polygon.IsEmpty() {
+		return []uint64{}, nil
+	}
+	return storage.findInRegion(polygon, func(polyline s2.Polyline) bool {
+		return PolylineIntersectsPolygon(polyline, polygon)
+	}), nil
+}
+
+// findInRegion Returns sorted identifiers of edges from cells covering the region which pass exact check
+func (storage *S2Storage) findInRegion(region s2.Region, intersects func(polyline s2.Polyline) bool) []uint64 {
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+	rc := s2.RegionCoverer{MaxLevel: storage.storageLevel, MaxCells: maxRegionCells}
+	checked := make(map[uint64]struct{})
+	result := []uint64{}
+	for _, cellID := range rc.Covering(region) {
+		// Items are stored at storage level, so descendants of the covering cell lie in [RangeMin; RangeMax]
+		rangeMax := cellID.RangeMax()
+		storage.BTree.AscendGreaterOrEqual(indexedItem{CellID: cellID.RangeMin()}, func(item btree.Item) bool {
+			ii := item.(indexedItem)
+			if ii.CellID > rangeMax {
+				return false
+			}
+			for _, edgeID := range ii.edgesInCell {
+				if _, ok := checked[edgeID]; ok {
+					continue
+				}
+				checked[edgeID] = struct{}{}
+				edge := storage.edges[edgeID]
+				if edge == nil || edge.Polyline == nil {
+					continue
+				}
+				if intersects(*edge.Polyline) {
+					result = append(result, edgeID)
+				}
+			}
+			return true
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
 }
 
 // maxSearchRings is the maximum number of rings to expand during FindNearest
@@ -207,19 +428,23 @@ func (storage *S2Storage) FindNearest(pt s2.Point, n int) ([]NearestObject, erro
 	if n <= 0 {
 		return nil, nil
 	}
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
 
 	centerCell := s2.CellFromPoint(pt).ID().Parent(storage.storageLevel)
 	cell := s2.CellFromPoint(pt)
 
 	// Track visited cells and found edges
 	visited := make(map[s2.CellID]bool)
-	found := make(map[uint64]float64)
+	found := make(map[uint64]NearestObject)
 
 	// Frontier-based expansion: start with center cell
 	frontier := []s2.CellID{centerCell}
 	visited[centerCell] = true
 
 	cellSize := storage.cellSizeMeters()
+	// Every point within ring * minCellWidth from the query point lies in visited cells
+	minCellWidth := s2.MinWidthMetric.Value(storage.storageLevel) * EarthRadius
 
 	for ring := 0; ring <= maxSearchRings && len(frontier) > 0; ring++ {
 		// Process all cells in current frontier (ring)
@@ -240,33 +465,34 @@ func (storage *S2Storage) FindNearest(pt s2.Point, n int) ([]NearestObject, erro
 				}
 
 				// Calculate minimum distance to edge
-				minDist := s1.ChordAngle(0)
-				for i := 0; i < polyline.Polyline.NumEdges(); i++ {
-					edge := polyline.Polyline.Edge(i)
-					distance := cell.DistanceToEdge(edge.V0, edge.V1)
-					if i == 0 || distance < minDist {
-						minDist = distance
-					}
-				}
-				found[edgeID] = minDist.Angle().Radians() * EarthRadius
+				obj := storage.distanceToEdge(cell, pt, *polyline.Polyline)
+				obj.EdgeID = edgeID
+				found[edgeID] = obj
 			}
 		}
 
 		// Early exit check
 		if len(found) >= n && ring > 0 {
-			ringRadius := cellSize * float64(ring)
-
-			// Find minimum distance among candidates
-			minFoundDist := float64(1e18)
-			for _, dist := range found {
-				if dist < minFoundDist {
-					minFoundDist = dist
+			if storage.exactDistances {
+				// N-th closest edge is within the visited area, so no unvisited edge could beat it
+				if nthDistance(found, n) <= minCellWidth*float64(ring) {
+					break
+				}
+			} else {
+				ringRadius := cellSize * float64(ring)
+
+				// Find minimum distance among candidates
+				minFoundDist := float64(1e18)
+				for _, obj := range found {
+					if obj.DistanceTo < minFoundDist {
+						minFoundDist = obj.DistanceTo
+					}
 				}
-			}
 
-			// If closest edge is closer than ring boundary, we can stop
-			if minFoundDist < ringRadius {
-				break
+				// If closest edge is closer than ring boundary, we can stop
+				if minFoundDist < ringRadius {
+					break
+				}
 			}
 		}
 
@@ -293,22 +519,17 @@ func (storage *S2Storage) FindNearest(pt s2.Point, n int) ([]NearestObject, erro
 	}
 
 	// Build result using heap for top-N selection
-	h := &nearestHeap{}
-	heap.Init(h)
-	for k, v := range found {
-		heap.Push(h, NearestObject{k, v})
-	}
-
-	l := h.Len()
-	if l < n {
-		n = l
-	}
+	return selectNearest(found, n), nil
+}
 
-	ans := make([]NearestObject, n)
-	for i := 0; i < n; i++ {
-		ans[i] = heap.Pop(h).(NearestObject)
+// nthDistance Returns distance to the N-th closest object
+func nthDistance(found map[uint64]NearestObject, n int) float64 {
+	distances := make([]float64, 0, len(found))
+	for _, obj := range found {
+		distances = append(distances, obj.DistanceTo)
 	}
-	return ans, nil
+	sort.Float64s(distances)
+	return distances[n-1]
 }
 
 // getCellsAtRing returns all cells at a given ring distance from center
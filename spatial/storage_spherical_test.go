@@ -1,9 +1,16 @@
 package spatial
 
 import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
 	"testing"
 
 	"github.com/golang/geo/s2"
+	"github.com/google/btree"
+	"github.com/pkg/errors"
 )
 
 func TestS2StorageSearchInRadius(t *testing.T) {
@@ -131,3 +138,324 @@ func TestS2StorageFindNearest(t *testing.T) {
 		t.Errorf("Expected empty result for n=0, got %d", len(nearest0))
 	}
 }
+
+// s2GridEdge Returns horizontal segment of the grid near Moscow: row and column are in units of ~0.001 degrees
+func s2GridEdge(row, col int, shift float64) *Edge {
+	lat := 55.75 + float64(row)*0.001 + shift
+	lon := 37.60 + float64(col)*0.001 + shift
+	return &Edge{Polyline: s2.PolylineFromLatLngs([]s2.LatLng{
+		s2.LatLngFromDegrees(lat, lon),
+		s2.LatLngFromDegrees(lat, lon+0.0008),
+	})}
+}
+
+func TestS2StorageRemoveUpdate(t *testing.T) {
+	storage := NewS2Storage(17, 35)
+	final := map[uint64]*Edge{}
+	for row := 0; row < 10; row++ {
+		for col := 0; col < 10; col++ {
+			edgeID := uint64(row*10 + col)
+			edge := s2GridEdge(row, col, 0)
+			if err := storage.AddEdge(edgeID, edge); err != nil {
+				t.Fatal(err)
+			}
+			final[edgeID] = edge
+		}
+	}
+	// Churn: remove every third edge, move every fifth one, re-add some removed ones with new geometry
+	for edgeID := uint64(0); edgeID < 100; edgeID++ {
+		switch {
+		case edgeID%3 == 0:
+			if err := storage.RemoveEdge(edgeID); err != nil {
+				t.Fatal(err)
+			}
+			delete(final, edgeID)
+		case edgeID%5 == 0:
+			edge := s2GridEdge(int(edgeID/10), int(edgeID%10), 0.0004)
+			if err := storage.UpdateEdge(edgeID, edge); err != nil {
+				t.Fatal(err)
+			}
+			final[edgeID] = edge
+		}
+	}
+	for edgeID := uint64(0); edgeID < 100; edgeID += 9 {
+		edge := s2GridEdge(int(edgeID%10), int(edgeID/10), 0.0002)
+		if err := storage.AddEdge(edgeID, edge); err != nil {
+			t.Fatal(err)
+		}
+		final[edgeID] = edge
+	}
+
+	if err := storage.RemoveEdge(1000); !errors.Is(err, ErrEdgeNotFound) {
+		t.Errorf("Removing unknown edge should return ErrEdgeNotFound, got %v", err)
+	}
+	if err := storage.UpdateEdge(3, s2GridEdge(0, 0, 0)); !errors.Is(err, ErrEdgeNotFound) {
+		t.Errorf("Updating removed edge should return ErrEdgeNotFound, got %v", err)
+	}
+	if storage.GetEdge(3) != nil {
+		t.Error("Removed edge should not be returned")
+	}
+
+	// B-tree must not contain empty items or identifiers of removed edges
+	storage.BTree.Ascend(func(item btree.Item) bool {
+		ii := item.(indexedItem)
+		if len(ii.edgesInCell) == 0 {
+			t.Errorf("Cell %v has no edges, but is still indexed", ii.CellID)
+		}
+		for _, edgeID := range ii.edgesInCell {
+			if _, ok := final[edgeID]; !ok {
+				t.Errorf("Cell %v still references removed edge %d", ii.CellID, edgeID)
+			}
+		}
+		return true
+	})
+
+	// Search results must be the same as for the storage built from scratch
+	fresh := NewS2Storage(17, 35)
+	for edgeID, edge := range final {
+		fresh.AddEdge(edgeID, edge)
+	}
+	if storage.BTree.Len() != fresh.BTree.Len() {
+		t.Errorf("Number of indexed cells should be %d, got %d", fresh.BTree.Len(), storage.BTree.Len())
+	}
+	for row := 0; row < 10; row++ {
+		for col := 0; col < 10; col++ {
+			pt := s2.PointFromLatLng(s2.LatLngFromDegrees(55.75+float64(row)*0.001+0.0003, 37.60+float64(col)*0.001+0.0004))
+			expected, _ := fresh.FindInRadius(pt, 150)
+			found, _ := storage.FindInRadius(pt, 150)
+			if !reflect.DeepEqual(expected, found) {
+				t.Errorf("Search around (%d, %d) after churn should return %v, got %v", row, col, expected, found)
+			}
+			expectedNearest, _ := fresh.FindNearest(pt, 3)
+			foundNearest, _ := storage.FindNearest(pt, 3)
+			for i := range expectedNearest {
+				if expectedNearest[i].DistanceTo != foundNearest[i].DistanceTo {
+					t.Errorf("Nearest #%d around (%d, %d) after churn should be at %f, got %f", i, row, col, expectedNearest[i].DistanceTo, foundNearest[i].DistanceTo)
+				}
+			}
+		}
+	}
+}
+
+func TestS2StorageConcurrentReaders(t *testing.T) {
+	storage := NewS2Storage(17, 35)
+	for edgeID := 0; edgeID < 50; edgeID++ {
+		storage.AddEdge(uint64(edgeID), s2GridEdge(edgeID/10, edgeID%10, 0))
+	}
+	pt := s2.PointFromLatLng(s2.LatLngFromDegrees(55.752, 37.602))
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if _, err := storage.FindNearest(pt, 5); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := storage.FindInRadius(pt, 200); err != nil {
+					t.Error(err)
+					return
+				}
+				storage.GetEdge(7)
+			}
+		}()
+	}
+	for i := 0; i < 500; i++ {
+		edgeID := uint64(i % 50)
+		storage.RemoveEdge(edgeID)
+		storage.AddEdge(edgeID, s2GridEdge(int(edgeID/10), int(edgeID%10), float64(i%3)*0.0001))
+	}
+	close(done)
+	wg.Wait()
+	if storage.BTree.Len() == 0 {
+		t.Error("Storage should not be empty after churn")
+	}
+}
+
+func TestS2StorageFindInRegion(t *testing.T) {
+	storage := NewS2Storage(17, 35)
+	edges := map[uint64]*Edge{}
+	for row := 0; row < 20; row++ {
+		for col := 0; col < 20; col++ {
+			edgeID := uint64(row*20 + col)
+			edge := s2GridEdge(row, col, 0)
+			storage.AddEdge(edgeID, edge)
+			edges[edgeID] = edge
+		}
+	}
+	bruteForce := func(intersects func(polyline s2.Polyline) bool) []uint64 {
+		ans := []uint64{}
+		for edgeID := uint64(0); edgeID < 400; edgeID++ {
+			if intersects(*edges[edgeID].Polyline) {
+				ans = append(ans, edgeID)
+			}
+		}
+		return ans
+	}
+
+	// Viewport much larger than single cell: covering consists of coarser cells
+	lo := s2.PointFromLatLng(s2.LatLngFromDegrees(55.7535, 37.6035))
+	hi := s2.PointFromLatLng(s2.LatLngFromDegrees(55.7625, 37.6125))
+	rect := s2.RectFromLatLng(s2.LatLngFromPoint(lo)).AddPoint(s2.LatLngFromPoint(hi))
+	found, err := storage.FindInRect(hi, lo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := bruteForce(func(polyline s2.Polyline) bool { return PolylineIntersectsRect(polyline, rect) })
+	if len(expected) == 0 || !reflect.DeepEqual(expected, found) {
+		t.Errorf("Edges in rectangle should be %v, got %v", expected, found)
+	}
+
+	// Rectangle between rows of edges contains nothing
+	found, err = storage.FindInRect(
+		s2.PointFromLatLng(s2.LatLngFromDegrees(55.7502, 37.6)),
+		s2.PointFromLatLng(s2.LatLngFromDegrees(55.7508, 37.62)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 0 {
+		t.Errorf("Rectangle between rows should contain no edges, got %v", found)
+	}
+
+	// Edge 0 crosses the rectangle while none of its vertices are inside
+	found, err = storage.FindInRect(
+		s2.PointFromLatLng(s2.LatLngFromDegrees(55.7499, 37.6003)),
+		s2.PointFromLatLng(s2.LatLngFromDegrees(55.7501, 37.6005)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found, []uint64{0}) {
+		t.Errorf("Only edge 0 should cross the rectangle, got %v", found)
+	}
+
+	// Triangle with a hole
+	polygon, err := RingsToS2Polygon([][][]float64{
+		{{37.601, 55.751}, {37.618, 55.753}, {37.606, 55.768}},
+		{{37.605, 55.755}, {37.608, 55.755}, {37.607, 55.758}},
+	}, 4326)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err = storage.FindInPolygon(polygon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = bruteForce(func(polyline s2.Polyline) bool { return PolylineIntersectsPolygon(polyline, polygon) })
+	if len(expected) == 0 || !reflect.DeepEqual(expected, found) {
+		t.Errorf("Edges in polygon should be %v, got %v", expected, found)
+	}
+}
+
+func TestS2StorageExactDistances(t *testing.T) {
+	storage := NewStorage(StorageTypeSpherical, WithExactDistances()).(*S2Storage)
+	// Dense set of short random polylines (~1km x 1km area)
+	rnd := rand.New(rand.NewSource(42))
+	edges := map[uint64]s2.Polyline{}
+	for edgeID := uint64(0); edgeID < 300; edgeID++ {
+		lat := 55.75 + rnd.Float64()*0.009
+		lon := 37.60 + rnd.Float64()*0.016
+		latlngs := []s2.LatLng{s2.LatLngFromDegrees(lat, lon)}
+		for i := 0; i < 1+rnd.Intn(3); i++ {
+			lat += (rnd.Float64() - 0.5) * 0.001
+			lon += (rnd.Float64() - 0.5) * 0.001
+			latlngs = append(latlngs, s2.LatLngFromDegrees(lat, lon))
+		}
+		polyline := s2.PolylineFromLatLngs(latlngs)
+		storage.AddEdge(edgeID, &Edge{Polyline: polyline})
+		edges[edgeID] = *polyline
+	}
+	type bruteForceResult struct {
+		edgeID     uint64
+		distance   float64
+		segmentIdx int
+	}
+	bruteForce := func(pt s2.Point) []bruteForceResult {
+		ans := make([]bruteForceResult, 0, len(edges))
+		for edgeID, polyline := range edges {
+			best := bruteForceResult{edgeID: edgeID, distance: math.MaxFloat64}
+			for i := 0; i < polyline.NumEdges(); i++ {
+				distance := s2.DistanceFromSegment(pt, polyline[i], polyline[i+1]).Radians() * EarthRadius
+				if distance < best.distance {
+					best.distance = distance
+					best.segmentIdx = i
+				}
+			}
+			ans = append(ans, best)
+		}
+		sort.Slice(ans, func(i, j int) bool { return ans[i].distance < ans[j].distance })
+		return ans
+	}
+	eps := 1e-6
+	for q := 0; q < 100; q++ {
+		pt := s2.PointFromLatLng(s2.LatLngFromDegrees(55.75+rnd.Float64()*0.009, 37.60+rnd.Float64()*0.016))
+		expected := bruteForce(pt)
+
+		nearest, err := storage.FindNearest(pt, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(nearest) != 5 {
+			t.Fatalf("Query #%d: should find 5 edges, got %d", q, len(nearest))
+		}
+		for i, obj := range nearest {
+			if math.Abs(obj.DistanceTo-expected[i].distance) > eps {
+				t.Errorf("Query #%d: distance to nearest edge #%d should be %f, got %f", q, i, expected[i].distance, obj.DistanceTo)
+			}
+			polyline := edges[obj.EdgeID]
+			if obj.SegmentIdx < 0 || obj.SegmentIdx >= polyline.NumEdges() {
+				t.Errorf("Query #%d: segment index %d is out of range for edge %d", q, obj.SegmentIdx, obj.EdgeID)
+				continue
+			}
+			// Projection lies on the reported segment and is at the reported distance
+			if d := s2.DistanceFromSegment(obj.ProjectedPoint, polyline[obj.SegmentIdx], polyline[obj.SegmentIdx+1]).Radians() * EarthRadius; d > eps {
+				t.Errorf("Query #%d: projection should lie on segment %d of edge %d, but it is %f meters away", q, obj.SegmentIdx, obj.EdgeID, d)
+			}
+			if d := pt.Distance(obj.ProjectedPoint).Radians() * EarthRadius; math.Abs(d-obj.DistanceTo) > eps {
+				t.Errorf("Query #%d: distance to projection should be %f, got %f", q, obj.DistanceTo, d)
+			}
+		}
+
+		radius := 60.0
+		found, err := storage.FindInRadius(pt, radius)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedInRadius := 0
+		for _, res := range expected {
+			if res.distance > radius {
+				break
+			}
+			expectedInRadius++
+			distance, ok := found[res.edgeID]
+			if !ok {
+				t.Errorf("Query #%d: edge %d at %f should be found in radius", q, res.edgeID, res.distance)
+				continue
+			}
+			if math.Abs(distance-res.distance) > eps {
+				t.Errorf("Query #%d: distance to edge %d should be %f, got %f", q, res.edgeID, res.distance, distance)
+			}
+		}
+		if len(found) != expectedInRadius {
+			t.Errorf("Query #%d: should find %d edges in radius, got %d", q, expectedInRadius, len(found))
+		}
+
+		nearestInRadius, err := storage.FindNearestInRadius(pt, radius, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, obj := range nearestInRadius {
+			if obj.EdgeID != expected[i].edgeID || obj.SegmentIdx != expected[i].segmentIdx {
+				t.Errorf("Query #%d: nearest edge #%d in radius should be %d (segment %d), got %d (segment %d)", q, i, expected[i].edgeID, expected[i].segmentIdx, obj.EdgeID, obj.SegmentIdx)
+			}
+		}
+	}
+}
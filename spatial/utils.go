@@ -29,6 +29,23 @@ func CalcProjection(line s2.Polyline, point s2.Point) (projected s2.Point, fract
 	return pr, (subs.Length() / line.Length()).Radians(), next
 }
 
+// PolylineLength Returns length of polyline in meters (spherical geometry)
+func PolylineLength(line s2.Polyline) float64 {
+	return line.Length().Radians() * EarthRadius
+}
+
+// PolylineLengthEuclidean Returns length of polyline (Euclidean/planar geometry)
+/*
+	line - s2.Polyline (using Vector.X/Y as Euclidean coordinates)
+*/
+func PolylineLengthEuclidean(line s2.Polyline) float64 {
+	length := 0.0
+	for i := 1; i < len(line); i++ {
+		length += math.Hypot(line[i].Vector.X-line[i-1].Vector.X, line[i].Vector.Y-line[i-1].Vector.Y)
+	}
+	return length
+}
+
 // CalcProjectionEuclidean Returns projection on line and fraction for point (Euclidean/planar geometry)
 /*
 	line - s2.Polyline (using Vector.X/Y as Euclidean coordinates)
@@ -130,6 +147,19 @@ func S2PolylineToGeoJSONFeature(pts s2.Polyline) *geojson.Feature {
 	return geojson.NewLineStringFeature(coordinates)
 }
 
+// S2PolylineToGeoJSONFeatureSRID Returns GeoJSON representation of *s2.Polyline with coordinates in the SRID (see S2PointToSRID)
+func S2PolylineToGeoJSONFeatureSRID(pts s2.Polyline, srid int) (*geojson.Feature, error) {
+	coordinates := make([][]float64, len(pts))
+	for i := range pts {
+		x, y, err := S2PointToSRID(pts[i], srid)
+		if err != nil {
+			return nil, err
+		}
+		coordinates[i] = []float64{x, y}
+	}
+	return geojson.NewLineStringFeature(coordinates), nil
+}
+
 // GeoJSONToS2PointFeature Returns s2.Point representation of *geojson.Geometry (of Point type)
 func GeoJSONToS2PointFeature(pts *geojson.Geometry) (s2.Point, error) {
 	var latLng s2.LatLng
@@ -147,9 +177,24 @@ func S2PointToGeoJSONFeature(pt *s2.Point) *geojson.Feature {
 	return geojson.NewPointFeature([]float64{latLng.Lng.Degrees(), latLng.Lat.Degrees()})
 }
 
+// S2PointToGeoJSONFeatureSRID Returns GeoJSON representation of *s2.Point with coordinates in the SRID (see S2PointToSRID)
+func S2PointToGeoJSONFeatureSRID(pt *s2.Point, srid int) (*geojson.Feature, error) {
+	x, y, err := S2PointToSRID(*pt, srid)
+	if err != nil {
+		return nil, err
+	}
+	return geojson.NewPointFeature([]float64{x, y}), nil
+}
+
 // WKTToS2PolylineFeature parses WKT LINESTRING and returns *s2.Polyline
 // Expected format: LINESTRING(lon1 lat1, lon2 lat2, ...)
 func WKTToS2PolylineFeature(wkt string) (*s2.Polyline, error) {
+	return WKTToS2PolylineFeatureSRID(wkt, 4326)
+}
+
+// WKTToS2PolylineFeatureSRID parses WKT LINESTRING with coordinates in the SRID and returns *s2.Polyline (see S2PointFromSRID)
+// Expected format: LINESTRING(x1 y1, x2 y2, ...)
+func WKTToS2PolylineFeatureSRID(wkt string, srid int) (*s2.Polyline, error) {
 	wkt = strings.TrimSpace(wkt)
 
 	// Check for LINESTRING prefix
@@ -167,37 +212,31 @@ func WKTToS2PolylineFeature(wkt string) (*s2.Polyline, error) {
 	coordsStr := wkt[start+1 : end]
 	pointStrs := strings.Split(coordsStr, ",")
 
-	latLngs := make([]s2.LatLng, 0, len(pointStrs))
+	polyline := make(s2.Polyline, 0, len(pointStrs))
 	for _, ptStr := range pointStrs {
-		ptStr = strings.TrimSpace(ptStr)
-		parts := strings.Fields(ptStr)
-		if len(parts) < 2 {
-			return nil, fmt.Errorf("invalid coordinate pair: %s", ptStr)
-		}
-
-		lon, err := strconv.ParseFloat(parts[0], 64)
-		if err != nil {
-			return nil, fmt.Errorf("invalid longitude: %s", parts[0])
-		}
-
-		lat, err := strconv.ParseFloat(parts[1], 64)
+		pt, err := parseWKTCoordinates(strings.TrimSpace(ptStr), srid)
 		if err != nil {
-			return nil, fmt.Errorf("invalid latitude: %s", parts[1])
+			return nil, err
 		}
-
-		latLngs = append(latLngs, s2.LatLngFromDegrees(lat, lon))
+		polyline = append(polyline, pt)
 	}
 
-	if len(latLngs) < 2 {
+	if len(polyline) < 2 {
 		return nil, fmt.Errorf("LINESTRING must have at least 2 points")
 	}
 
-	return s2.PolylineFromLatLngs(latLngs), nil
+	return &polyline, nil
 }
 
 // WKTToS2PointFeature parses WKT POINT and returns s2.Point
 // Expected format: POINT(lon lat)
 func WKTToS2PointFeature(wkt string) (s2.Point, error) {
+	return WKTToS2PointFeatureSRID(wkt, 4326)
+}
+
+// WKTToS2PointFeatureSRID parses WKT POINT with coordinates in the SRID and returns s2.Point (see S2PointFromSRID)
+// Expected format: POINT(x y)
+func WKTToS2PointFeatureSRID(wkt string, srid int) (s2.Point, error) {
 	wkt = strings.TrimSpace(wkt)
 
 	// Check for POINT prefix
@@ -212,7 +251,11 @@ func WKTToS2PointFeature(wkt string) (s2.Point, error) {
 		return s2.Point{}, fmt.Errorf("invalid WKT POINT format: %s", wkt)
 	}
 
-	coordsStr := strings.TrimSpace(wkt[start+1 : end])
+	return parseWKTCoordinates(strings.TrimSpace(wkt[start+1:end]), srid)
+}
+
+// parseWKTCoordinates Parses single pair of coordinates "x y" in the SRID
+func parseWKTCoordinates(coordsStr string, srid int) (s2.Point, error) {
 	parts := strings.Fields(coordsStr)
 	if len(parts) < 2 {
 		return s2.Point{}, fmt.Errorf("invalid coordinate pair: %s", coordsStr)
@@ -228,41 +271,110 @@ func WKTToS2PointFeature(wkt string) (s2.Point, error) {
 		return s2.Point{}, fmt.Errorf("invalid latitude: %s", parts[1])
 	}
 
-	return s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lon)), nil
+	return S2PointFromSRID(lon, lat, srid)
 }
 
-// ExtractCutUpTo cuts geometry between very first point and neighbor of the projected point index in the polyline
+// ExtractCutUpTo cuts geometry between very first point and the projected point.
+// Returns remaining part (from the projected point up to the last point) and the cut (from the first point up to the projected point).
+// Source polyline is not modified
 func ExtractCutUpTo(polyline s2.Polyline, projected s2.Point, projectedIdx int) (s2.Polyline, s2.Polyline) {
-	polyCopy := polyline
-	polyCopyCut := polyline
+	head, tail := splitAtProjection(polyline, projected, projectedIdx)
+	return tail, head
+}
 
-	// Cut segment from the start of the polyline up to projection poit
-	polyCopy = append(s2.Polyline{projected}, polyCopy[projectedIdx:]...)
+// ExtractCutUpFrom cuts geometry between the projected point and last point.
+// Returns remaining part (from the first point up to the projected point) and the cut (from the projected point up to the last point).
+// Source polyline is not modified
+func ExtractCutUpFrom(polyline s2.Polyline, projected s2.Point, projectedIdx int) (s2.Polyline, s2.Polyline) {
+	return splitAtProjection(polyline, projected, projectedIdx)
+}
 
-	// Cut segment from projection point up to the end of the polyline
-	part := polyCopyCut[:projectedIdx-1]
-	if len(part) == 0 {
-		polyCopyCut = s2.Polyline{polyCopyCut[0], projected}
-	} else {
-		polyCopyCut = append(polyCopyCut[:projectedIdx-1], projected)
+// splitAtProjection Splits polyline into two new ones by the projected point: points before projectedIdx with the projected point and the projected point with the rest points
+func splitAtProjection(polyline s2.Polyline, projected s2.Point, projectedIdx int) (s2.Polyline, s2.Polyline) {
+	if projectedIdx < 1 {
+		projectedIdx = 1
+	}
+	if projectedIdx > len(polyline) {
+		projectedIdx = len(polyline)
 	}
-	return polyCopy, polyCopyCut
+	head := make(s2.Polyline, 0, projectedIdx+1)
+	head = append(head, polyline[:projectedIdx]...)
+	head = append(head, projected)
+	tail := make(s2.Polyline, 0, len(polyline)-projectedIdx+1)
+	tail = append(tail, projected)
+	tail = append(tail, polyline[projectedIdx:]...)
+	return head, tail
 }
 
-// ExtractCutUpFrom cuts geometry between neighbor of the projected point index in the polyline and last point
-func ExtractCutUpFrom(polyline s2.Polyline, projected s2.Point, projectedIdx int) (s2.Polyline, s2.Polyline) {
-	polyCopy := polyline
-	polyCopyCut := polyline
+// SubPolyline Returns part of polyline between two fractions of its length (spherical geometry)
+/*
+	line - s2.Polyline
+	from - number in [0;1], describes where the part starts
+	to - number in [from;1], describes where the part ends
 
-	// Cut segment from the projection poit up to the end of the polyline
-	part := polyCopy[:projectedIdx-1]
-	if len(part) == 0 {
-		polyCopy = s2.Polyline{polyCopy[0], projected}
-	} else {
-		polyCopy = append(polyCopy[:projectedIdx-1], projected)
-	}
+	Returned polyline is a new one: interpolated end points are added when fractions fall inside of segments
+*/
+func SubPolyline(line s2.Polyline, from, to float64) s2.Polyline {
+	return subPolyline(line, from, to, func(a, b s2.Point) float64 {
+		return a.Distance(b).Radians()
+	}, func(a, b s2.Point, t float64) s2.Point {
+		return s2.Interpolate(t, a, b)
+	})
+}
 
-	// Cut segment from the start of the polyline up to projection poit
-	polyCopyCut = append(s2.Polyline{projected}, polyCopyCut[projectedIdx:]...)
-	return polyCopy, polyCopyCut
+// SubPolylineEuclidean Returns part of polyline between two fractions of its length (Euclidean/planar geometry)
+/*
+	line - s2.Polyline (using Vector.X/Y as Euclidean coordinates)
+	from - number in [0;1], describes where the part starts
+	to - number in [from;1], describes where the part ends
+
+	Returned polyline is a new one: interpolated end points are added when fractions fall inside of segments
+*/
+func SubPolylineEuclidean(line s2.Polyline, from, to float64) s2.Polyline {
+	return subPolyline(line, from, to, func(a, b s2.Point) float64 {
+		return math.Hypot(b.Vector.X-a.Vector.X, b.Vector.Y-a.Vector.Y)
+	}, func(a, b s2.Point, t float64) s2.Point {
+		return NewEuclideanS2Point(a.Vector.X+(b.Vector.X-a.Vector.X)*t, a.Vector.Y+(b.Vector.Y-a.Vector.Y)*t)
+	})
+}
+
+// subPolyline Returns part of polyline between two fractions of its length using given metric and interpolation
+func subPolyline(line s2.Polyline, from, to float64, distance func(a, b s2.Point) float64, interpolate func(a, b s2.Point, t float64) s2.Point) s2.Polyline {
+	if len(line) == 0 {
+		return s2.Polyline{}
+	}
+	from = math.Max(0, math.Min(1, from))
+	to = math.Max(from, math.Min(1, to))
+	total := 0.0
+	for i := 1; i < len(line); i++ {
+		total += distance(line[i-1], line[i])
+	}
+	if total == 0 {
+		return s2.Polyline{line[0], line[len(line)-1]}
+	}
+	start, end := total*from, total*to
+	ans := s2.Polyline{}
+	passed := 0.0
+	for i := 1; i < len(line); i++ {
+		segment := distance(line[i-1], line[i])
+		if len(ans) == 0 && passed+segment >= start {
+			t := 0.0
+			if segment > 0 {
+				t = (start - passed) / segment
+			}
+			ans = append(ans, interpolate(line[i-1], line[i], t))
+		}
+		if len(ans) > 0 {
+			if passed+segment >= end {
+				t := 1.0
+				if segment > 0 {
+					t = (end - passed) / segment
+				}
+				return append(ans, interpolate(line[i-1], line[i], t))
+			}
+			ans = append(ans, line[i])
+		}
+		passed += segment
+	}
+	return ans
 }
@@ -0,0 +1,83 @@
+package spatial
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestExtractCut(t *testing.T) {
+	line := s2.Polyline{
+		NewEuclideanS2Point(0, 0),
+		NewEuclideanS2Point(1, 0),
+		NewEuclideanS2Point(2, 0),
+		NewEuclideanS2Point(3, 0),
+	}
+	original := append(s2.Polyline{}, line...)
+	projected := NewEuclideanS2Point(1.5, 0)
+
+	rest, cut := ExtractCutUpTo(line, projected, 2)
+	if !polylinesEqual(rest, s2.Polyline{projected, line[2], line[3]}) {
+		t.Errorf("Wrong remaining part for ExtractCutUpTo: %v", rest)
+	}
+	if !polylinesEqual(cut, s2.Polyline{line[0], line[1], projected}) {
+		t.Errorf("Wrong cut for ExtractCutUpTo: %v", cut)
+	}
+
+	rest, cut = ExtractCutUpFrom(line, projected, 2)
+	if !polylinesEqual(rest, s2.Polyline{line[0], line[1], projected}) {
+		t.Errorf("Wrong remaining part for ExtractCutUpFrom: %v", rest)
+	}
+	if !polylinesEqual(cut, s2.Polyline{projected, line[2], line[3]}) {
+		t.Errorf("Wrong cut for ExtractCutUpFrom: %v", cut)
+	}
+
+	if !polylinesEqual(line, original) {
+		t.Errorf("Source polyline must not be modified: %v", line)
+	}
+}
+
+func TestSubPolyline(t *testing.T) {
+	line := s2.Polyline{
+		NewEuclideanS2Point(0, 0),
+		NewEuclideanS2Point(2, 0),
+		NewEuclideanS2Point(2, 2),
+	}
+	sub := SubPolylineEuclidean(line, 0.25, 0.75)
+	expected := s2.Polyline{NewEuclideanS2Point(1, 0), NewEuclideanS2Point(2, 0), NewEuclideanS2Point(2, 1)}
+	if !polylinesEqual(sub, expected) {
+		t.Errorf("Wrong sub-polyline: %v", sub)
+	}
+	sub = SubPolylineEuclidean(line, 0, 1)
+	if !polylinesEqual(sub, line) {
+		t.Errorf("Whole polyline should be returned: %v", sub)
+	}
+	sub = SubPolylineEuclidean(line, 0.1, 0.2)
+	expected = s2.Polyline{NewEuclideanS2Point(0.4, 0), NewEuclideanS2Point(0.8, 0)}
+	if len(sub) != 2 || sub[0].Sub(expected[0].Vector).Norm() > 1e-12 || sub[1].Sub(expected[1].Vector).Norm() > 1e-12 {
+		t.Errorf("Wrong sub-polyline inside of single segment: %v", sub)
+	}
+
+	spherical := s2.Polyline{
+		s2.PointFromLatLng(s2.LatLngFromDegrees(0, 0)),
+		s2.PointFromLatLng(s2.LatLngFromDegrees(0, 1)),
+	}
+	sub = SubPolyline(spherical, 0, 0.5)
+	middle := s2.LatLngFromPoint(sub[len(sub)-1])
+	if len(sub) != 2 || math.Abs(middle.Lng.Degrees()-0.5) > 1e-9 || math.Abs(middle.Lat.Degrees()) > 1e-9 {
+		t.Errorf("Wrong spherical sub-polyline: %v", sub)
+	}
+}
+
+func polylinesEqual(a, b s2.Polyline) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -71,7 +71,8 @@ func (engine *MapEngine) strongConnect(v int64, state *tarjanState) {
 	state.onStack[v] = true
 
 	// Consider successors of v (only outgoing edges for SCC)
-	for neighbor := range engine.edges[v] {
+	for _, edge := range engine.outgoing[v] {
+		neighbor := edge.Target
 		if _, visited := state.indexMap[neighbor]; !visited {
 			// Successor has not yet been visited; recurse on it
 			engine.strongConnect(neighbor, state)
@@ -110,11 +111,9 @@ func (engine *MapEngine) computeStrongConnectedComponents() StrongComponentsResu
 
 	// Collect all vertices
 	vertices := make(map[int64]bool)
-	for src, targets := range engine.edges {
-		vertices[src] = true
-		for dst := range targets {
-			vertices[dst] = true
-		}
+	for _, edge := range engine.edges {
+		vertices[edge.Source] = true
+		vertices[edge.Target] = true
 	}
 
 	// Run Tarjan's algorithm from each unvisited vertex
@@ -8,13 +8,15 @@ import (
 
 func TestTarjanSCC_SimpleChain(t *testing.T) {
 	engine := &MapEngine{
-		edges: make(map[int64]map[int64]*spatial.Edge),
+		edges:    make(map[int64]*spatial.Edge),
+		outgoing: make(map[int64][]*spatial.Edge),
+		incoming: make(map[int64][]*spatial.Edge),
 	}
 
 	// Create a simple chain: 1 -> 2 -> 3
 	// Each vertex is its own SCC (no cycles)
-	engine.edges[1] = map[int64]*spatial.Edge{2: {}}
-	engine.edges[2] = map[int64]*spatial.Edge{3: {}}
+	engine.addEdge(&spatial.Edge{ID: 102, Source: 1, Target: 2})
+	engine.addEdge(&spatial.Edge{ID: 203, Source: 2, Target: 3})
 
 	result := engine.computeStrongConnectedComponents()
 
@@ -42,14 +44,16 @@ func TestTarjanSCC_SimpleChain(t *testing.T) {
 
 func TestTarjanSCC_SimpleCycle(t *testing.T) {
 	engine := &MapEngine{
-		edges: make(map[int64]map[int64]*spatial.Edge),
+		edges:    make(map[int64]*spatial.Edge),
+		outgoing: make(map[int64][]*spatial.Edge),
+		incoming: make(map[int64][]*spatial.Edge),
 	}
 
 	// Create a cycle: 1 -> 2 -> 3 -> 1
 	// All vertices should be in one SCC
-	engine.edges[1] = map[int64]*spatial.Edge{2: {}}
-	engine.edges[2] = map[int64]*spatial.Edge{3: {}}
-	engine.edges[3] = map[int64]*spatial.Edge{1: {}}
+	engine.addEdge(&spatial.Edge{ID: 102, Source: 1, Target: 2})
+	engine.addEdge(&spatial.Edge{ID: 203, Source: 2, Target: 3})
+	engine.addEdge(&spatial.Edge{ID: 301, Source: 3, Target: 1})
 
 	result := engine.computeStrongConnectedComponents()
 
@@ -75,16 +79,18 @@ func TestTarjanSCC_SimpleCycle(t *testing.T) {
 
 func TestTarjanSCC_TwoSeparateCycles(t *testing.T) {
 	engine := &MapEngine{
-		edges: make(map[int64]map[int64]*spatial.Edge),
+		edges:    make(map[int64]*spatial.Edge),
+		outgoing: make(map[int64][]*spatial.Edge),
+		incoming: make(map[int64][]*spatial.Edge),
 	}
 
 	// Create two separate cycles:
 	// Cycle 1: 1 -> 2 -> 1
 	// Cycle 2: 3 -> 4 -> 3
-	engine.edges[1] = map[int64]*spatial.Edge{2: {}}
-	engine.edges[2] = map[int64]*spatial.Edge{1: {}}
-	engine.edges[3] = map[int64]*spatial.Edge{4: {}}
-	engine.edges[4] = map[int64]*spatial.Edge{3: {}}
+	engine.addEdge(&spatial.Edge{ID: 102, Source: 1, Target: 2})
+	engine.addEdge(&spatial.Edge{ID: 201, Source: 2, Target: 1})
+	engine.addEdge(&spatial.Edge{ID: 304, Source: 3, Target: 4})
+	engine.addEdge(&spatial.Edge{ID: 403, Source: 4, Target: 3})
 
 	result := engine.computeStrongConnectedComponents()
 
@@ -111,14 +117,16 @@ func TestTarjanSCC_TwoSeparateCycles(t *testing.T) {
 
 func TestTarjanSCC_CycleWithTail(t *testing.T) {
 	engine := &MapEngine{
-		edges: make(map[int64]map[int64]*spatial.Edge),
+		edges:    make(map[int64]*spatial.Edge),
+		outgoing: make(map[int64][]*spatial.Edge),
+		incoming: make(map[int64][]*spatial.Edge),
 	}
 
 	// Create: 1 -> 2 -> 3 -> 2 (cycle 2-3) with entry from 1
 	// Vertex 1 is separate, vertices 2,3 form an SCC
-	engine.edges[1] = map[int64]*spatial.Edge{2: {}}
-	engine.edges[2] = map[int64]*spatial.Edge{3: {}}
-	engine.edges[3] = map[int64]*spatial.Edge{2: {}}
+	engine.addEdge(&spatial.Edge{ID: 102, Source: 1, Target: 2})
+	engine.addEdge(&spatial.Edge{ID: 203, Source: 2, Target: 3})
+	engine.addEdge(&spatial.Edge{ID: 302, Source: 3, Target: 2})
 
 	result := engine.computeStrongConnectedComponents()
 
@@ -140,7 +148,9 @@ func TestTarjanSCC_CycleWithTail(t *testing.T) {
 
 func TestTarjanSCC_BigComponent(t *testing.T) {
 	engine := &MapEngine{
-		edges: make(map[int64]map[int64]*spatial.Edge),
+		edges:    make(map[int64]*spatial.Edge),
+		outgoing: make(map[int64][]*spatial.Edge),
+		incoming: make(map[int64][]*spatial.Edge),
 	}
 
 	// Create a large cycle (size > SMALL_COMPONENT_SIZE would not be that small)
@@ -150,10 +160,7 @@ func TestTarjanSCC_BigComponent(t *testing.T) {
 		if next > 5 {
 			next = 1
 		}
-		if engine.edges[i] == nil {
-			engine.edges[i] = make(map[int64]*spatial.Edge)
-		}
-		engine.edges[i][next] = &spatial.Edge{}
+		engine.addEdge(&spatial.Edge{ID: i*100 + next, Source: i, Target: next})
 	}
 
 	result := engine.computeStrongConnectedComponents()
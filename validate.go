@@ -0,0 +1,667 @@
+package horizon
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// Kinds of problems found by ValidateGraph
+const (
+	// ISSUE_MALFORMED_ROW Row can't be parsed (not enough columns, bad number or attribute). Row is dropped by repair
+	ISSUE_MALFORMED_ROW = "malformed_row"
+	// ISSUE_INVALID_WEIGHT Weight of edge is negative, NaN or infinite. Edge is dropped by repair
+	ISSUE_INVALID_WEIGHT = "invalid_weight"
+	// ISSUE_DEGENERATE_GEOMETRY Geometry of edge can't be parsed, has single point or zero length. Edge is dropped by repair
+	ISSUE_DEGENERATE_GEOMETRY = "degenerate_geometry"
+	// ISSUE_DUPLICATE_EDGE Identifier of edge is used by previous edge. Edge gets new identifier after the maximum one by repair
+	ISSUE_DUPLICATE_EDGE = "duplicate_edge_id"
+	// ISSUE_REVERSED_GEOMETRY Geometry of edge goes from target vertex to source one. Geometry is reversed by repair
+	ISSUE_REVERSED_GEOMETRY = "reversed_geometry"
+	// ISSUE_ENDPOINT_MISMATCH Endpoint of edge geometry is far from geometry of its vertex. It is not repaired
+	ISSUE_ENDPOINT_MISMATCH = "endpoint_mismatch"
+	// ISSUE_DUPLICATE_VERTEX Vertex is listed in vertices file several times. The first occurrence is kept by repair
+	ISSUE_DUPLICATE_VERTEX = "duplicate_vertex"
+	// ISSUE_ISOLATED_VERTEX Vertex of vertices file has no edges. Vertex is dropped by repair
+	ISSUE_ISOLATED_VERTEX = "isolated_vertex"
+	// ISSUE_MISSING_VERTEX Vertex of edge is missing in vertices file. Geometry of vertex is taken from edges by repair
+	ISSUE_MISSING_VERTEX = "missing_vertex"
+	// ISSUE_UNKNOWN_SHORTCUT_VERTEX Shortcut references vertex which has no edges. Shortcuts are prepared again by repair
+	ISSUE_UNKNOWN_SHORTCUT_VERTEX = "unknown_shortcut_vertex"
+	// ISSUE_COMPONENTS_MISMATCH Connected components of prepared engine differ from the ones of its edges. It is reported for engines only (see MapMatcher.ReplaceEngine)
+	ISSUE_COMPONENTS_MISMATCH = "components_mismatch"
+)
+
+// ValidationOptions Parameters of graph validation
+/*
+	Tolerance - endpoint of edge geometry farther than tolerance from its vertex is a problem (meters, or units of coordinates for SRID = 0)
+	TinyComponentSize - strongly connected components with at most this number of vertices are counted as tiny ones
+	RepairFilename - when it is not empty, then repaired graph is written to this edges file and to vertices and shortcuts files next to it (see MapEngine.ExportCSV)
+*/
+type ValidationOptions struct {
+	Tolerance         float64
+	TinyComponentSize int
+	RepairFilename    string
+}
+
+// DefaultValidationOptions Returns 1 meter tolerance, components up to 10 vertices as tiny ones and no repair
+func DefaultValidationOptions() ValidationOptions {
+	return ValidationOptions{
+		Tolerance:         1.0,
+		TinyComponentSize: 10,
+	}
+}
+
+// ValidationIssue Problem of graph files
+/*
+	File - name of the file
+	Line - number of line in the file starting from 1 (header is the first line)
+	Kind - kind of problem (see ISSUE_* constants)
+	Message - description of problem
+	Fix - action which is applied to the problem in repaired graph. Empty if problem is not repaired
+*/
+type ValidationIssue struct {
+	File    string
+	Line    int
+	Kind    string
+	Message string
+	Fix     string
+}
+
+// String Returns issue in 'file:line: kind: message (fix)' form
+func (issue ValidationIssue) String() string {
+	ans := fmt.Sprintf("%s:%d: %s: %s", issue.File, issue.Line, issue.Kind, issue.Message)
+	if issue.Fix != "" {
+		ans += " (" + issue.Fix + ")"
+	}
+	return ans
+}
+
+// ValidationReport Problems and statistics of graph files
+/*
+	Issues - every problem in order of files (edges, vertices, shortcuts) and lines
+	Edges, Vertices, Shortcuts - numbers of valid rows
+	WeakComponents, StrongComponents - numbers of connected components of valid edges
+	LargestStrongComponent - number of vertices in the largest strongly connected component
+	TinyStrongComponents, VerticesInTinyComponents - number of tiny strongly connected components (see ValidationOptions) and number of their vertices
+	Repaired - whether repaired graph has been written
+*/
+type ValidationReport struct {
+	Issues                   []ValidationIssue
+	Edges                    int
+	Vertices                 int
+	Shortcuts                int
+	WeakComponents           int
+	StrongComponents         int
+	LargestStrongComponent   int
+	TinyStrongComponents     int
+	VerticesInTinyComponents int
+	Repaired                 bool
+}
+
+// IssuesCount Returns number of issues of every kind
+func (report *ValidationReport) IssuesCount() map[string]int {
+	ans := make(map[string]int)
+	for _, issue := range report.Issues {
+		ans[issue.Kind]++
+	}
+	return ans
+}
+
+// String Returns statistics of the report
+func (report *ValidationReport) String() string {
+	ans := fmt.Sprintf("edges: %d, vertices: %d, shortcuts: %d, weak components: %d, strong components: %d (the largest has %d vertices, %d tiny ones have %d vertices), issues: %d",
+		report.Edges, report.Vertices, report.Shortcuts, report.WeakComponents, report.StrongComponents, report.LargestStrongComponent, report.TinyStrongComponents, report.VerticesInTinyComponents, len(report.Issues))
+	counts := report.IssuesCount()
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for i, kind := range kinds {
+		if i == 0 {
+			ans += " ("
+		} else {
+			ans += ", "
+		}
+		ans += fmt.Sprintf("%s: %d", kind, counts[kind])
+	}
+	if len(kinds) > 0 {
+		ans += ")"
+	}
+	return ans
+}
+
+// validatedEdge Edge which has passed row-level checks
+type validatedEdge struct {
+	edge       *spatial.Edge
+	attributes spatial.EdgeAttributes
+	profiles   map[string]float64
+	line       int
+}
+
+// graphValidator State of graph validation
+type graphValidator struct {
+	settings  *MapEngine
+	options   ValidationOptions
+	report    *ValidationReport
+	edges     []*validatedEdge
+	vertices  map[int64]s2.Point
+	connected map[int64]bool
+}
+
+// ValidateGraph Checks edges, vertices and shortcuts files (see NewMapMatcherFromFiles) and reports every problem with its line number and statistics of the graph
+/*
+	edgesFilename - path to the edges CSV file (e.g., "graph.csv"). Vertices and shortcuts files are optional
+	options - tolerance, size of tiny components and filename for repaired graph (see ValidationOptions)
+	engineOpts - options of the engine, e.g. WithGraphSRID for geometries in projected CRS or WithWeightProfiles for weight columns
+
+	Returns error only when files can't be read at all (e.g. there is no edges file or its header is broken): problems of rows are reported as issues.
+	Repaired graph consists of valid edges with fixed geometry direction and unique identifiers, contraction hierarchies are prepared again
+*/
+func ValidateGraph(edgesFilename string, options ValidationOptions, engineOpts ...func(*MapEngine)) (*ValidationReport, error) {
+	validator := &graphValidator{
+		settings:  newMapEngine(engineOpts...),
+		options:   options,
+		report:    &ValidationReport{},
+		vertices:  make(map[int64]s2.Point),
+		connected: make(map[int64]bool),
+	}
+	if !spatial.IsSRIDSupported(validator.settings.graphSRID) {
+		return nil, errors.Wrapf(spatial.ErrUnknownSRID, "Can't validate graph with SRID %d", validator.settings.graphSRID)
+	}
+	edgesFilename, verticesFilename, shortcutsFilename := csvFilenames(edgesFilename)
+	err := validator.validateEdges(edgesFilename)
+	if err != nil {
+		return nil, err
+	}
+	hasVertices, err := validator.validateVertices(verticesFilename)
+	if err != nil {
+		return nil, err
+	}
+	validator.validateGeometry(edgesFilename, hasVertices)
+	err = validator.validateShortcuts(shortcutsFilename)
+	if err != nil {
+		return nil, err
+	}
+	validator.evaluateComponents()
+	if options.RepairFilename != "" {
+		err = validator.repair(engineOpts...)
+		if err != nil {
+			return nil, err
+		}
+		validator.report.Repaired = true
+	}
+	return validator.report, nil
+}
+
+// validateEngine Runs checks of edges, geometry and connected components of ValidateGraph on prepared engine (e.g. before it replaces active one).
+// Issues have neither file nor line, the engine is not modified. Components stored in the engine must match the ones evaluated from its edges
+func validateEngine(engine *MapEngine, options ValidationOptions) *ValidationReport {
+	validator := &graphValidator{
+		settings:  engine,
+		options:   options,
+		report:    &ValidationReport{},
+		vertices:  make(map[int64]s2.Point),
+		connected: make(map[int64]bool),
+	}
+	edgeIDs := make([]int64, 0, len(engine.edges))
+	for edgeID := range engine.edges {
+		edgeIDs = append(edgeIDs, edgeID)
+	}
+	sort.Slice(edgeIDs, func(i, j int) bool { return edgeIDs[i] < edgeIDs[j] })
+	for _, edgeID := range edgeIDs {
+		// Geometry could be reversed by checks, so they work with copy of edge
+		edge := *engine.edges[edgeID]
+		if edge.Polyline == nil || len(*edge.Polyline) < 2 {
+			validator.addIssue("", 0, ISSUE_DEGENERATE_GEOMETRY, "", "edge %d has less than 2 points of geometry", edge.ID)
+			continue
+		}
+		if !validator.checkWeight("", 0, &edge, strconv.FormatFloat(edge.Weight, 'f', -1, 64)) || !validator.checkLength("", 0, &edge) {
+			continue
+		}
+		validator.edges = append(validator.edges, &validatedEdge{edge: &edge})
+		validator.connected[edge.Source] = true
+		validator.connected[edge.Target] = true
+	}
+	validator.report.Edges = len(validator.edges)
+	for vertexID, vertex := range engine.vertices {
+		if vertex.Point != nil {
+			validator.vertices[vertexID] = *vertex.Point
+		}
+	}
+	validator.validateGeometry("", len(validator.vertices) > 0)
+	weak, strong := validator.evaluateComponents()
+	if !sameComponents(engine.vertexComponent, weak.VertexComponent) {
+		validator.addIssue("", 0, ISSUE_COMPONENTS_MISMATCH, "", "weakly connected components of engine differ from the ones of its edges")
+	}
+	if !sameComponents(engine.vertexStrongComponent, strong.VertexComponent) {
+		validator.addIssue("", 0, ISSUE_COMPONENTS_MISMATCH, "", "strongly connected components of engine differ from the ones of its edges")
+	}
+	return validator.report
+}
+
+// sameComponents Checks that both mappings split the same vertices into the same components (identifiers of components could differ)
+func sameComponents(stored, evaluated map[int64]int64) bool {
+	if len(stored) != len(evaluated) {
+		return false
+	}
+	storedToEvaluated := make(map[int64]int64)
+	evaluatedToStored := make(map[int64]int64)
+	for vertexID, evaluatedID := range evaluated {
+		storedID, ok := stored[vertexID]
+		if !ok {
+			return false
+		}
+		if id, ok := storedToEvaluated[storedID]; ok && id != evaluatedID {
+			return false
+		}
+		if id, ok := evaluatedToStored[evaluatedID]; ok && id != storedID {
+			return false
+		}
+		storedToEvaluated[storedID] = evaluatedID
+		evaluatedToStored[evaluatedID] = storedID
+	}
+	return true
+}
+
+// addIssue Adds problem to the report
+func (validator *graphValidator) addIssue(file string, line int, kind, fix, format string, args ...interface{}) {
+	validator.report.Issues = append(validator.report.Issues, ValidationIssue{
+		File:    file,
+		Line:    line,
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+		Fix:     fix,
+	})
+}
+
+// openCSV Returns reader of CSV file and its header. Returns nil reader when file is missing and it is optional
+func openValidationCSV(filename string, optional bool) (*os.File, *csv.Reader, []string, error) {
+	file, err := os.Open(filename)
+	if optional && os.IsNotExist(err) {
+		return nil, nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "Can't open file '%s'", filename)
+	}
+	reader := csv.NewReader(file)
+	reader.Comma = ';'
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		file.Close()
+		return nil, nil, nil, errors.Wrapf(err, "Can't read header of file '%s'", filename)
+	}
+	return file, reader, header, nil
+}
+
+// validateEdges Checks rows of edges file one by one and keeps valid edges. Duplicate identifiers are replaced after the maximum one
+func (validator *graphValidator) validateEdges(filename string) error {
+	file, reader, header, err := openValidationCSV(filename, false)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	columns, err := parseEdgesHeader(header, validator.settings.profileColumns)
+	if err != nil {
+		return errors.Wrapf(err, "Can't parse header of edges file '%s'", filename)
+	}
+	for _, name := range validator.settings.profileColumns {
+		if _, ok := columns.profiles[name]; !ok && name != LENGTH_PROFILE {
+			return fmt.Errorf("there is no column '%s' for weight profile in edges file '%s'", name, filename)
+		}
+	}
+
+	firstLine := make(map[int64]int)
+	duplicates := []*validatedEdge{}
+	maxID := int64(math.MinInt64)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return errors.Wrapf(err, "Can't read edges file '%s'", filename)
+			}
+			validator.addIssue(filename, parseErr.Line, ISSUE_MALFORMED_ROW, "dropped", "%s", parseErr.Err)
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		edge, attributes, err := columns.parseEdge(record)
+		if err != nil {
+			validator.addIssue(filename, line, ISSUE_MALFORMED_ROW, "dropped", "%s", err)
+			continue
+		}
+		if !validator.checkWeight(filename, line, edge, record[columns.weight]) {
+			continue
+		}
+		profiles, err := columns.parseProfiles(record)
+		if err != nil {
+			validator.addIssue(filename, line, ISSUE_MALFORMED_ROW, "dropped", "%s", err)
+			continue
+		}
+		edge.Polyline, err = columns.parseGeometry(record, validator.settings.graphSRID)
+		if err != nil {
+			validator.addIssue(filename, line, ISSUE_DEGENERATE_GEOMETRY, "dropped", "edge %d: %s", edge.ID, errors.Cause(err))
+			continue
+		}
+		if !validator.checkLength(filename, line, edge) {
+			continue
+		}
+		validated := &validatedEdge{edge: edge, attributes: attributes, profiles: profiles, line: line}
+		validator.edges = append(validator.edges, validated)
+		if edge.ID > maxID {
+			maxID = edge.ID
+		}
+		if previous, ok := firstLine[edge.ID]; ok {
+			validator.addIssue(filename, line, ISSUE_DUPLICATE_EDGE, "", "edge %d is already defined on line %d", edge.ID, previous)
+			duplicates = append(duplicates, validated)
+			continue
+		}
+		firstLine[edge.ID] = line
+	}
+	// Fixes of duplicates are known after every identifier is read
+	issueIdx := len(validator.report.Issues) - 1
+	for i := len(duplicates) - 1; i >= 0; i-- {
+		for validator.report.Issues[issueIdx].Kind != ISSUE_DUPLICATE_EDGE || validator.report.Issues[issueIdx].Line != duplicates[i].line {
+			issueIdx--
+		}
+		newID := maxID + int64(i) + 1
+		validator.report.Issues[issueIdx].Fix = fmt.Sprintf("reassigned to %d", newID)
+		duplicates[i].edge.ID = newID
+	}
+	for _, validated := range validator.edges {
+		validator.connected[validated.edge.Source] = true
+		validator.connected[validated.edge.Target] = true
+	}
+	validator.report.Edges = len(validator.edges)
+	return nil
+}
+
+// checkWeight Reports negative, NaN or infinite weight of edge. Returns false if edge has to be dropped
+func (validator *graphValidator) checkWeight(filename string, line int, edge *spatial.Edge, weight string) bool {
+	if edge.Weight < 0 || math.IsNaN(edge.Weight) || math.IsInf(edge.Weight, 0) {
+		validator.addIssue(filename, line, ISSUE_INVALID_WEIGHT, "dropped", "edge %d has weight %s", edge.ID, weight)
+		return false
+	}
+	return true
+}
+
+// checkLength Reports edge of zero length. Returns false if edge has to be dropped
+func (validator *graphValidator) checkLength(filename string, line int, edge *spatial.Edge) bool {
+	if validator.settings.edgeLength(edge) == 0 {
+		validator.addIssue(filename, line, ISSUE_DEGENERATE_GEOMETRY, "dropped", "edge %d has zero length", edge.ID)
+		return false
+	}
+	return true
+}
+
+// validateVertices Checks rows of vertices file and keeps geometry of vertices. Returns false when there is no vertices file or it has no geometry
+func (validator *graphValidator) validateVertices(filename string) (bool, error) {
+	file, reader, header, err := openValidationCSV(filename, true)
+	if err != nil || file == nil {
+		return false, err
+	}
+	defer file.Close()
+	columns, err := parseVerticesHeader(header)
+	if err != nil {
+		return false, errors.Wrapf(err, "Can't parse header of vertices file '%s'", filename)
+	}
+	if columns.geom == -1 {
+		return false, nil
+	}
+	firstLine := make(map[int64]int)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return false, errors.Wrapf(err, "Can't read vertices file '%s'", filename)
+			}
+			validator.addIssue(filename, parseErr.Line, ISSUE_MALFORMED_ROW, "dropped", "%s", parseErr.Err)
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) <= columns.id || len(record) <= columns.geom {
+			validator.addIssue(filename, line, ISSUE_MALFORMED_ROW, "dropped", "not enough columns: %d", len(record))
+			continue
+		}
+		vertexID, err := strconv.ParseInt(record[columns.id], 10, 64)
+		if err != nil {
+			validator.addIssue(filename, line, ISSUE_MALFORMED_ROW, "dropped", "can't parse vertex identifier '%s'", record[columns.id])
+			continue
+		}
+		point, err := spatial.WKTToS2PointFeatureSRID(record[columns.geom], validator.settings.graphSRID)
+		if err != nil {
+			validator.addIssue(filename, line, ISSUE_MALFORMED_ROW, "dropped", "vertex %d: %s", vertexID, err)
+			continue
+		}
+		if previous, ok := firstLine[vertexID]; ok {
+			validator.addIssue(filename, line, ISSUE_DUPLICATE_VERTEX, "dropped", "vertex %d is already defined on line %d", vertexID, previous)
+			continue
+		}
+		firstLine[vertexID] = line
+		if !validator.connected[vertexID] {
+			validator.addIssue(filename, line, ISSUE_ISOLATED_VERTEX, "dropped", "vertex %d has no edges", vertexID)
+			continue
+		}
+		validator.vertices[vertexID] = point
+	}
+	validator.report.Vertices = len(validator.vertices)
+	return true, nil
+}
+
+// validateGeometry Checks that geometry of every edge starts at its source vertex and ends at its target one.
+// Vertices missing in vertices file are placed at the most frequent endpoint of their edges
+func (validator *graphValidator) validateGeometry(filename string, hasVertices bool) {
+	reported := make(map[int64]bool)
+	for _, validated := range validator.edges {
+		edge := validated.edge
+		for _, vertexID := range []int64{edge.Source, edge.Target} {
+			if _, ok := validator.vertices[vertexID]; ok || reported[vertexID] {
+				continue
+			}
+			reported[vertexID] = true
+			if hasVertices {
+				validator.addIssue(filename, validated.line, ISSUE_MISSING_VERTEX, "geometry is taken from edges", "vertex %d of edge %d is missing in vertices file", vertexID, edge.ID)
+			}
+		}
+	}
+	// Candidates for geometry of missing vertices: endpoints which edges claim
+	claims := make(map[int64]map[s2.Point]int)
+	for _, validated := range validator.edges {
+		polyline := *validated.edge.Polyline
+		for vertexID, point := range map[int64]s2.Point{validated.edge.Source: polyline[0], validated.edge.Target: polyline[len(polyline)-1]} {
+			if _, ok := validator.vertices[vertexID]; ok && !reported[vertexID] {
+				continue
+			}
+			if claims[vertexID] == nil {
+				claims[vertexID] = make(map[s2.Point]int)
+			}
+			claims[vertexID][point]++
+		}
+	}
+	for vertexID, points := range claims {
+		best := 0
+		for point, count := range points {
+			current, ok := validator.vertices[vertexID]
+			// Ties are resolved by coordinates, so result does not depend on order of map iteration
+			if count > best || (count == best && ok && pointLess(point, current)) {
+				best = count
+				validator.vertices[vertexID] = point
+			}
+		}
+	}
+	validator.report.Vertices = len(validator.vertices)
+
+	for _, validated := range validator.edges {
+		edge := validated.edge
+		polyline := *edge.Polyline
+		first, last := polyline[0], polyline[len(polyline)-1]
+		source, target := validator.vertices[edge.Source], validator.vertices[edge.Target]
+		sourceDistance, targetDistance := validator.distance(first, source), validator.distance(last, target)
+		if sourceDistance <= validator.options.Tolerance && targetDistance <= validator.options.Tolerance {
+			continue
+		}
+		if validator.distance(first, target) <= validator.options.Tolerance && validator.distance(last, source) <= validator.options.Tolerance {
+			validator.addIssue(filename, validated.line, ISSUE_REVERSED_GEOMETRY, "reversed", "geometry of edge %d goes from vertex %d to vertex %d", edge.ID, edge.Target, edge.Source)
+			reversed := make(s2.Polyline, len(polyline))
+			for i := range polyline {
+				reversed[i] = polyline[len(polyline)-1-i]
+			}
+			edge.Polyline = &reversed
+			continue
+		}
+		validator.addIssue(filename, validated.line, ISSUE_ENDPOINT_MISMATCH, "", "geometry of edge %d starts %.3f away from vertex %d and ends %.3f away from vertex %d", edge.ID, sourceDistance, edge.Source, targetDistance, edge.Target)
+	}
+}
+
+// validateShortcuts Checks rows of shortcuts file: every vertex of shortcut must have edges
+func (validator *graphValidator) validateShortcuts(filename string) error {
+	file, reader, _, err := openValidationCSV(filename, true)
+	if err != nil || file == nil {
+		return err
+	}
+	defer file.Close()
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return errors.Wrapf(err, "Can't read shortcuts file '%s'", filename)
+			}
+			validator.addIssue(filename, parseErr.Line, ISSUE_MALFORMED_ROW, "shortcuts are prepared again", "%s", parseErr.Err)
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) < 4 {
+			validator.addIssue(filename, line, ISSUE_MALFORMED_ROW, "shortcuts are prepared again", "not enough columns: expected 4, got %d", len(record))
+			continue
+		}
+		vertices := make([]int64, 0, 3)
+		for _, idx := range []int{0, 1, 3} {
+			vertexID, err := strconv.ParseInt(strings.TrimSpace(record[idx]), 10, 64)
+			if err != nil {
+				break
+			}
+			vertices = append(vertices, vertexID)
+		}
+		_, weightErr := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if len(vertices) != 3 || weightErr != nil {
+			validator.addIssue(filename, line, ISSUE_MALFORMED_ROW, "shortcuts are prepared again", "can't parse shortcut '%s'", strings.Join(record, ";"))
+			continue
+		}
+		unknown := []string{}
+		for _, vertexID := range vertices {
+			if !validator.connected[vertexID] {
+				unknown = append(unknown, strconv.FormatInt(vertexID, 10))
+			}
+		}
+		if len(unknown) > 0 {
+			validator.addIssue(filename, line, ISSUE_UNKNOWN_SHORTCUT_VERTEX, "shortcuts are prepared again", "shortcut from %d to %d via %d references unknown vertices %s", vertices[0], vertices[1], vertices[2], strings.Join(unknown, ", "))
+			continue
+		}
+		validator.report.Shortcuts++
+	}
+	return nil
+}
+
+// evaluateComponents Evaluates statistics of connected components of valid edges and returns the components
+func (validator *graphValidator) evaluateComponents() (WeakComponentsResult, StrongComponentsResult) {
+	engine := newMapEngine()
+	for _, validated := range validator.edges {
+		engine.addEdge(validated.edge)
+	}
+	weak := engine.computeWeakConnectedComponents()
+	strong := engine.computeStrongConnectedComponents()
+	validator.report.WeakComponents = int(weak.TotalComponents)
+	validator.report.StrongComponents = int(strong.TotalComponents)
+	for _, size := range strong.ComponentSizes {
+		if size > validator.report.LargestStrongComponent {
+			validator.report.LargestStrongComponent = size
+		}
+		if size <= validator.options.TinyComponentSize {
+			validator.report.TinyStrongComponents++
+			validator.report.VerticesInTinyComponents += size
+		}
+	}
+	return weak, strong
+}
+
+// repair Builds graph of valid edges and writes it to CSV files
+func (validator *graphValidator) repair(engineOpts ...func(*MapEngine)) error {
+	builder := NewMapEngineBuilder(engineOpts...)
+	profiles := make(map[string]map[int64]float64, len(validator.settings.profileColumns))
+	for _, name := range validator.settings.profileColumns {
+		profiles[name] = make(map[int64]float64)
+	}
+	attributes := make(map[int64]spatial.EdgeAttributes)
+	for _, validated := range validator.edges {
+		edge := validated.edge
+		builder.AddEdges(edge)
+		for name := range profiles {
+			if weight, ok := validated.profiles[name]; ok {
+				profiles[name][edge.ID] = weight
+			} else if name == LENGTH_PROFILE {
+				profiles[name][edge.ID] = validator.settings.edgeLength(edge)
+			}
+		}
+		if validated.attributes != nil {
+			attributes[edge.ID] = validated.attributes
+		}
+	}
+	for vertexID, point := range validator.vertices {
+		point := point
+		builder.AddVertices(&spatial.Vertex{ID: vertexID, Point: &point})
+	}
+	for _, name := range validator.settings.profileColumns {
+		builder.AddWeightProfile(name, profiles[name])
+	}
+	builder.AddEdgeAttributes(attributes)
+	engine, err := builder.Build()
+	if err != nil {
+		return errors.Wrap(err, "Can't build repaired graph")
+	}
+	err = engine.ExportCSV(validator.options.RepairFilename)
+	if err != nil {
+		return errors.Wrap(err, "Can't write repaired graph")
+	}
+	return nil
+}
+
+// distance Returns distance between points: meters for spherical coordinates, units of coordinates for SRID = 0
+func (validator *graphValidator) distance(a, b s2.Point) float64 {
+	if validator.settings.isEuclidean() {
+		return math.Hypot(a.X-b.X, a.Y-b.Y)
+	}
+	return a.Distance(b).Radians() * spatial.EarthRadius
+}
+
+// pointLess Compares points by coordinates
+func pointLess(a, b s2.Point) bool {
+	if a.X != b.X {
+		return a.X < b.X
+	}
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	return a.Z < b.Z
+}
@@ -0,0 +1,162 @@
+package horizon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeBrokenGraph Writes edges, vertices and shortcuts files (SRID = 0) with every kind of problem
+func writeBrokenGraph(t *testing.T) string {
+	dir := t.TempDir()
+	edgesFilename, verticesFilename, shortcutsFilename := csvFilenames(filepath.Join(dir, "broken.csv"))
+	files := map[string]string{
+		edgesFilename: `from_vertex_id;to_vertex_id;weight;geom;was_one_way;edge_id
+0;1;5;LINESTRING(0 0,5 0);false;1
+1;0;5;LINESTRING(5 0,0 0);false;2
+1;2;5;LINESTRING(10 0,5 0);false;3
+2;1;5;LINESTRING(10 0,5 0);false;3
+2;3;5;LINESTRING(10 0,15 0);false;4
+3;2;-1;LINESTRING(15 0,10 0);false;6
+3;4;5;LINESTRING(15 0,15 0);false;7
+3;4;x;LINESTRING(15 0,15 5);false;9
+3;2;5;LINESTRING(15 0,10 0);false;8
+2;0;12;LINESTRING(10 0,0 7);true;10
+`,
+		verticesFilename: `vertex_id;order_pos;importance;geom
+0;0;0;POINT(0 0)
+1;1;0;POINT(5 0)
+2;2;0;POINT(10 0)
+2;2;0;POINT(10 0)
+99;3;0;POINT(50 50)
+`,
+		shortcutsFilename: `from_vertex_id;to_vertex_id;weight;via_vertex_id
+0;2;10;1
+0;3;15;42
+`,
+	}
+	for filename, content := range files {
+		err := os.WriteFile(filename, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return edgesFilename
+}
+
+func TestValidateGraph(t *testing.T) {
+	edgesFilename := writeBrokenGraph(t)
+	_, verticesFilename, shortcutsFilename := csvFilenames(edgesFilename)
+	options := DefaultValidationOptions()
+	options.RepairFilename = filepath.Join(filepath.Dir(edgesFilename), "repaired.csv")
+	report, err := ValidateGraph(edgesFilename, options, WithGraphSRID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ValidationIssue{
+		{File: edgesFilename, Line: 5, Kind: ISSUE_DUPLICATE_EDGE, Fix: "reassigned to 11"},
+		{File: edgesFilename, Line: 7, Kind: ISSUE_INVALID_WEIGHT, Fix: "dropped"},
+		{File: edgesFilename, Line: 8, Kind: ISSUE_DEGENERATE_GEOMETRY, Fix: "dropped"},
+		{File: edgesFilename, Line: 9, Kind: ISSUE_MALFORMED_ROW, Fix: "dropped"},
+		{File: verticesFilename, Line: 5, Kind: ISSUE_DUPLICATE_VERTEX, Fix: "dropped"},
+		{File: verticesFilename, Line: 6, Kind: ISSUE_ISOLATED_VERTEX, Fix: "dropped"},
+		{File: edgesFilename, Line: 6, Kind: ISSUE_MISSING_VERTEX, Fix: "geometry is taken from edges"},
+		{File: edgesFilename, Line: 4, Kind: ISSUE_REVERSED_GEOMETRY, Fix: "reversed"},
+		{File: edgesFilename, Line: 11, Kind: ISSUE_ENDPOINT_MISMATCH},
+		{File: shortcutsFilename, Line: 3, Kind: ISSUE_UNKNOWN_SHORTCUT_VERTEX, Fix: "shortcuts are prepared again"},
+	}
+	if len(report.Issues) != len(expected) {
+		t.Fatalf("Expected %d issues, but got %d: %v", len(expected), len(report.Issues), report.Issues)
+	}
+	for i, issue := range report.Issues {
+		if issue.File != expected[i].File || issue.Line != expected[i].Line || issue.Kind != expected[i].Kind || issue.Fix != expected[i].Fix {
+			t.Errorf("Issue %d should be %s:%d %s (%s), but got %s", i, expected[i].File, expected[i].Line, expected[i].Kind, expected[i].Fix, issue)
+		}
+	}
+	if report.Edges != 7 || report.Vertices != 4 || report.Shortcuts != 1 {
+		t.Errorf("Expected 7 edges, 4 vertices and 1 shortcut, but got %d, %d and %d", report.Edges, report.Vertices, report.Shortcuts)
+	}
+	if report.WeakComponents != 1 || report.StrongComponents != 1 || report.LargestStrongComponent != 4 || report.TinyStrongComponents != 1 {
+		t.Errorf("Wrong components statistics: %s", report)
+	}
+	if !report.Repaired {
+		t.Fatal("Repaired graph should be written")
+	}
+
+	// Only mismatch of endpoint is left in repaired graph
+	repaired, err := ValidateGraph(options.RepairFilename, DefaultValidationOptions(), WithGraphSRID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repaired.Issues) != 1 || repaired.Issues[0].Kind != ISSUE_ENDPOINT_MISMATCH {
+		t.Errorf("Repaired graph should have endpoint mismatch only, but got %v", repaired.Issues)
+	}
+	matcher, err := NewMapMatcherFromFiles(NewHmmProbabilities(50, 30), options.RepairFilename, WithGraphSRID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matcher.engine.edges) != 7 || len(matcher.engine.vertices) != 4 {
+		t.Errorf("Expected 7 edges and 4 vertices in repaired graph, but got %d and %d", len(matcher.engine.edges), len(matcher.engine.vertices))
+	}
+	if edge := matcher.engine.edges[3]; edge == nil || (*edge.Polyline)[0].X != 5 {
+		t.Errorf("Geometry of edge 3 should be reversed")
+	}
+}
+
+func TestValidateGraphErrors(t *testing.T) {
+	_, err := ValidateGraph(filepath.Join(t.TempDir(), "missing.csv"), DefaultValidationOptions())
+	if err == nil {
+		t.Error("Missing edges file should give error")
+	}
+	edgesFilename := writeBrokenGraph(t)
+	_, err = ValidateGraph(edgesFilename, DefaultValidationOptions(), WithGraphSRID(0), WithWeightProfiles("travel_time"))
+	if err == nil {
+		t.Error("Missing column of weight profile should give error")
+	}
+}
+
+func TestValidateGraphMalformedQuotes(t *testing.T) {
+	dir := t.TempDir()
+	edgesFilename, verticesFilename, shortcutsFilename := csvFilenames(filepath.Join(dir, "quotes.csv"))
+	files := map[string]string{
+		edgesFilename: `from_vertex_id;to_vertex_id;weight;geom;was_one_way;edge_id
+0;1;5;LINESTRING(0 0,5 0);false;1
+"2"x;3;5;LINESTRING(10 0,15 0);false;2
+1;0;5;LINESTRING(5 0,0 0);false;3
+`,
+		verticesFilename: `vertex_id;order_pos;importance;geom
+0;0;0;POINT(0 0)
+"1"x;1;0;POINT(5 0)
+1;1;0;POINT(5 0)
+`,
+		shortcutsFilename: `from_vertex_id;to_vertex_id;weight;via_vertex_id
+"0"x;1;5;1
+`,
+	}
+	for filename, content := range files {
+		err := os.WriteFile(filename, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	report, err := ValidateGraph(edgesFilename, DefaultValidationOptions(), WithGraphSRID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ValidationIssue{
+		{File: edgesFilename, Line: 3, Kind: ISSUE_MALFORMED_ROW, Fix: "dropped"},
+		{File: verticesFilename, Line: 3, Kind: ISSUE_MALFORMED_ROW, Fix: "dropped"},
+		{File: shortcutsFilename, Line: 2, Kind: ISSUE_MALFORMED_ROW, Fix: "shortcuts are prepared again"},
+	}
+	if len(report.Issues) != len(expected) {
+		t.Fatalf("Expected %d issues, but got %d: %v", len(expected), len(report.Issues), report.Issues)
+	}
+	for i, issue := range report.Issues {
+		if issue.File != expected[i].File || issue.Line != expected[i].Line || issue.Kind != expected[i].Kind || issue.Fix != expected[i].Fix {
+			t.Errorf("Issue %d should be %s:%d %s (%s), but got %s", i, expected[i].File, expected[i].Line, expected[i].Kind, expected[i].Fix, issue)
+		}
+	}
+	if report.Edges != 2 || report.Vertices != 2 {
+		t.Errorf("Expected 2 edges and 2 vertices, but got %d and %d", report.Edges, report.Vertices)
+	}
+}
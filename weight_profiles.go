@@ -0,0 +1,167 @@
+package horizon
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/LdDl/ch"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Name of the profile which corresponds to 'weight' column of edges file (and to spatial.Edge.Weight)
+	DEFAULT_PROFILE = "default"
+	// Name of the profile which is derived from edges geometry when there is no such column in edges file
+	LENGTH_PROFILE = "length"
+)
+
+// weightProfile Named set of edges weights with its own contraction hierarchy
+/*
+	name - name of the profile (usually it is name of the column in edges file)
+	graph - contraction hierarchy built for the profile
+	queryPool - thread-safe query pool for concurrent shortest path queries
+	weights - edge ID to weight. Nil map means that spatial.Edge.Weight is used (default profile).
+		Edges which are missing in non-nil map are not traversable for the profile
+*/
+type weightProfile struct {
+	name      string
+	graph     *ch.Graph
+	queryPool *ch.QueryPool
+	weights   map[int64]float64
+}
+
+// weight Returns weight of the edge for the profile. Second value is false when edge is not traversable for the profile
+func (profile *weightProfile) weight(edge *spatial.Edge) (float64, bool) {
+	if profile.weights == nil {
+		return edge.Weight, true
+	}
+	weight, ok := profile.weights[edge.ID]
+	return weight, ok
+}
+
+// WithWeightProfiles is an option which sets names of additional weight profiles to be loaded from edges file.
+// Every name must match column in header of edges file. The only exception is 'length' profile: if there is no such column then weights are derived from edges geometry.
+// Each profile gets its own contraction hierarchy which is prepared during loading.
+func WithWeightProfiles(names ...string) func(*MapEngine) {
+	return func(engine *MapEngine) {
+		engine.profileColumns = append(engine.profileColumns, names...)
+	}
+}
+
+// WithWeightProfile is an option which adds weight profile for edges.
+// Contraction hierarchy of the profile is prepared after every option is applied and edges are loaded, so options could be provided in any order.
+// Error of preparation is returned by loaders and MapEngineBuilder.Build, NewMapEngine keeps it for MapEngine.Err.
+// Edges missing in weights map are not traversable for the profile
+func WithWeightProfile(name string, weights map[int64]float64) func(*MapEngine) {
+	return func(engine *MapEngine) {
+		engine.pendingProfiles = append(engine.pendingProfiles, pendingProfile{name: name, weights: weights})
+	}
+}
+
+// preparePendingProfiles Prepares weight profiles added by WithWeightProfile. It should be called after edges are loaded
+func (engine *MapEngine) preparePendingProfiles() error {
+	pending := engine.pendingProfiles
+	engine.pendingProfiles = nil
+	for _, profile := range pending {
+		err := engine.AddWeightProfile(profile.name, profile.weights)
+		if err != nil {
+			return errors.Wrapf(err, "Can't prepare weight profile '%s'", profile.name)
+		}
+	}
+	return nil
+}
+
+// AddWeightProfile Builds contraction hierarchy for the given weights and registers it as named profile
+/*
+	name - name of the profile. Can't be equal to DEFAULT_PROFILE
+	weights - edge ID to weight. Edges missing in the map are not traversable for the profile
+*/
+func (engine *MapEngine) AddWeightProfile(name string, weights map[int64]float64) error {
+	if name == "" || name == DEFAULT_PROFILE {
+		return fmt.Errorf("profile name can't be empty or '%s'", DEFAULT_PROFILE)
+	}
+	graph := ch.NewGraph()
+	for _, edge := range engine.edges {
+		weight, ok := weights[edge.ID]
+		if !ok {
+			continue
+		}
+		if weight < 0 {
+			return fmt.Errorf("negative weight %f for edge %d", weight, edge.ID)
+		}
+		err := graph.CreateVertex(edge.Source)
+		if err != nil {
+			return errors.Wrapf(err, "Can't add source vertex %d", edge.Source)
+		}
+		err = graph.CreateVertex(edge.Target)
+		if err != nil {
+			return errors.Wrapf(err, "Can't add target vertex %d", edge.Target)
+		}
+		err = graph.AddEdge(edge.Source, edge.Target, weight)
+		if err != nil {
+			return errors.Wrapf(err, "Can't add edge: from_vertex_id = '%d' | to_vertex_id = '%d'", edge.Source, edge.Target)
+		}
+	}
+	graph.PrepareContractionHierarchies()
+	if engine.profiles == nil {
+		engine.profiles = make(map[string]*weightProfile)
+	}
+	engine.profiles[name] = &weightProfile{
+		name:      name,
+		graph:     graph,
+		queryPool: graph.NewQueryPool(),
+		weights:   weights,
+	}
+	return nil
+}
+
+// Profiles Returns names of available weight profiles (including default one)
+func (engine *MapEngine) Profiles() []string {
+	names := make([]string, 0, len(engine.profiles)+1)
+	names = append(names, DEFAULT_PROFILE)
+	for name := range engine.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// HasProfile Checks if weight profile with given name exists. Empty name stands for default profile
+func (engine *MapEngine) HasProfile(name string) bool {
+	if name == "" || name == DEFAULT_PROFILE {
+		return true
+	}
+	_, ok := engine.profiles[name]
+	return ok
+}
+
+// getProfile Returns weight profile by its name. Empty name stands for default profile
+func (engine *MapEngine) getProfile(name string) (*weightProfile, error) {
+	if name == "" || name == DEFAULT_PROFILE {
+		return &weightProfile{
+			name:      DEFAULT_PROFILE,
+			graph:     &engine.graph,
+			queryPool: engine.queryPool,
+		}, nil
+	}
+	profile, ok := engine.profiles[name]
+	if !ok {
+		return nil, errors.Wrapf(ErrProfileNotFound, "profile '%s'", name)
+	}
+	return profile, nil
+}
+
+// edgeLength Returns length of the edge: meters for spherical storage (on ellipsoid if WithEllipsoidalDistances is used), units of coordinates for Euclidean one
+func (engine *MapEngine) edgeLength(edge *spatial.Edge) float64 {
+	if edge == nil || edge.Polyline == nil {
+		return 0
+	}
+	if engine.isEuclidean() {
+		return spatial.PolylineLengthEuclidean(*edge.Polyline)
+	}
+	if engine.ellipsoidal {
+		return spatial.PolylineLengthGeodesic(*edge.Polyline)
+	}
+	return spatial.PolylineLength(*edge.Polyline)
+}
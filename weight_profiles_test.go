@@ -0,0 +1,230 @@
+package horizon
+
+import (
+	"math"
+	"testing"
+
+	"github.com/LdDl/ch"
+	"github.com/LdDl/horizon/spatial"
+	"github.com/golang/geo/s2"
+	"github.com/pkg/errors"
+)
+
+// prepareProfilesMatcher Returns matcher for planar graph with two alternative routes between vertices 1 and 3:
+// short one (1 -> 2 -> 3) and long one (1 -> 4 -> 3)
+func prepareProfilesMatcher(t *testing.T) *MapMatcher {
+	vertices := map[int64][2]float64{
+		0: {-5, 0},
+		1: {0, 0},
+		2: {5, 0},
+		3: {10, 0},
+		4: {5, 5},
+		5: {15, 0},
+	}
+	edgeDefs := []struct {
+		id     int64
+		source int64
+		target int64
+	}{
+		{1, 0, 1}, {2, 1, 2}, {3, 2, 3}, {4, 1, 4}, {5, 4, 3}, {6, 3, 5},
+	}
+	graph := ch.Graph{}
+	edgesSpatial := []*spatial.Edge{}
+	verticesSpatial := []*spatial.Vertex{}
+	for vertexID, coords := range vertices {
+		err := graph.CreateVertex(vertexID)
+		if err != nil {
+			t.Fatalf("Can't add vertex with id = '%d' to the graph: %v", vertexID, err)
+		}
+		s2Point := spatial.NewEuclideanS2Point(coords[0], coords[1])
+		verticesSpatial = append(verticesSpatial, &spatial.Vertex{
+			Point: &s2Point,
+			ID:    vertexID,
+		})
+	}
+	for _, edge := range edgeDefs {
+		source := vertices[edge.source]
+		target := vertices[edge.target]
+		weight := math.Hypot(target[0]-source[0], target[1]-source[1])
+		err := graph.AddEdge(edge.source, edge.target, weight)
+		if err != nil {
+			t.Fatalf("Can't add edge from '%d' to '%d' to the graph: %v", edge.source, edge.target, err)
+		}
+		s2Polyline := s2.Polyline{
+			spatial.NewEuclideanS2Point(source[0], source[1]),
+			spatial.NewEuclideanS2Point(target[0], target[1]),
+		}
+		edgesSpatial = append(edgesSpatial, &spatial.Edge{
+			ID:       edge.id,
+			Source:   edge.source,
+			Target:   edge.target,
+			Weight:   weight,
+			Polyline: &s2Polyline,
+		})
+	}
+	// Short route is slow
+	travelTime := map[int64]float64{1: 5, 2: 50, 3: 50, 4: 7.5, 5: 7.5, 6: 5}
+	// Long route is fast, but edge 4 is prohibited for trucks
+	truckTime := map[int64]float64{1: 5, 2: 50, 3: 50, 5: 7.5, 6: 5}
+	engine := NewMapEngine(
+		WithGraph(graph),
+		WithStorage(spatial.NewStorage(spatial.StorageTypeEuclidean)),
+		WithEdges(edgesSpatial),
+		WithVertices(verticesSpatial),
+		WithWeightProfile("travel_time", travelTime),
+		WithWeightProfile("truck_time", truckTime),
+	)
+	return NewMapMatcher(WithMapEngine(engine))
+}
+
+func TestWeightProfilesShortestPath(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	expectedProfiles := []string{DEFAULT_PROFILE, "travel_time", "truck_time"}
+	profiles := matcher.Profiles()
+	if len(profiles) != len(expectedProfiles) {
+		t.Fatalf("Expected profiles %v, but got %v", expectedProfiles, profiles)
+	}
+	for i := range expectedProfiles {
+		if profiles[i] != expectedProfiles[i] {
+			t.Errorf("Profile #%d should be '%s', but got '%s'", i, expectedProfiles[i], profiles[i])
+		}
+	}
+
+	source := NewGPSMeasurementFromID(1, -4.9, 0.1, 0)
+	target := NewGPSMeasurementFromID(2, 14.9, 0.1, 0)
+	cases := []struct {
+		profile string
+		edges   []int64
+		cost    float64
+		length  float64
+	}{
+		{profile: "", edges: []int64{2, 3}, cost: 10, length: 10},
+		{profile: "travel_time", edges: []int64{4, 5}, cost: 15, length: 2 * math.Hypot(5, 5)},
+		{profile: "truck_time", edges: []int64{2, 3}, cost: 100, length: 10},
+	}
+	eps := 1e-6
+	for _, c := range cases {
+		result, err := matcher.FindShortestPath(source, target, -1, WithProfile(c.profile))
+		if err != nil {
+			t.Fatalf("Profile '%s': %v", c.profile, err)
+		}
+		observations := result.SubMatches[0].Observations
+		edges := []EdgeResult{}
+		edges = append(edges, EdgeResult{ID: observations[0].MatchedEdge.ID, Weight: observations[0].MatchedEdge.Weight, Length: observations[0].MatchedEdgeLength})
+		edges = append(edges, observations[0].NextEdges...)
+		edges = append(edges, EdgeResult{ID: observations[1].MatchedEdge.ID, Weight: observations[1].MatchedEdge.Weight, Length: observations[1].MatchedEdgeLength})
+		if len(edges) != len(c.edges) {
+			t.Errorf("Profile '%s': expected %d edges, but got %d", c.profile, len(c.edges), len(edges))
+			continue
+		}
+		cost, length := 0.0, 0.0
+		for i := range edges {
+			if edges[i].ID != c.edges[i] {
+				t.Errorf("Profile '%s': edge #%d should be %d, but got %d", c.profile, i, c.edges[i], edges[i].ID)
+			}
+			cost += edges[i].Weight
+			length += edges[i].Length
+		}
+		if math.Abs(cost-c.cost) > eps {
+			t.Errorf("Profile '%s': cost should be %f, but got %f", c.profile, c.cost, cost)
+		}
+		if math.Abs(length-c.length) > eps {
+			t.Errorf("Profile '%s': length should be %f, but got %f", c.profile, c.length, length)
+		}
+	}
+
+	_, err := matcher.FindShortestPath(source, target, -1, WithProfile("bicycle"))
+	if errors.Cause(err) != ErrProfileNotFound {
+		t.Errorf("Expected error '%v', but got '%v'", ErrProfileNotFound, err)
+	}
+}
+
+func TestWeightProfilesIsochrones(t *testing.T) {
+	matcher := prepareProfilesMatcher(t)
+	// Near vertex 0: isochrones start from vertex 1
+	source := NewGPSMeasurementFromID(1, -4.9, 0.1, 0)
+	result, err := matcher.FindIsochrones(source, 20, -1, WithProfile("travel_time"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[int64]float64{1: 0, 4: 7.5, 3: 15, 5: 20}
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d vertices, but got %d", len(expected), len(result))
+	}
+	for _, isochrone := range result {
+		cost, ok := expected[isochrone.Vertex.ID]
+		if !ok {
+			t.Errorf("Vertex %d should not be reachable", isochrone.Vertex.ID)
+			continue
+		}
+		if math.Abs(cost-isochrone.Cost) > 1e-6 {
+			t.Errorf("Cost for vertex %d should be %f, but got %f", isochrone.Vertex.ID, cost, isochrone.Cost)
+		}
+	}
+	// Exclusions use profile's weights too
+	result, err = matcher.FindIsochrones(source, 20, -1, WithProfile("travel_time"), WithExcludedEdges(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Errorf("Expected 2 vertices, but got %d", len(result))
+	}
+}
+
+func TestWeightProfilesFromFile(t *testing.T) {
+	matcher, err := NewMapMatcherFromFiles(HmmProbabilitiesDefault(), "./test_data/matcher_4326_test.csv", WithWeightProfiles(LENGTH_PROFILE))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !matcher.HasProfile(LENGTH_PROFILE) {
+		t.Fatalf("Profile '%s' should be derived from geometry", LENGTH_PROFILE)
+	}
+	edge := matcher.engine.edgesBetween(102, 103)[0]
+	weight, ok := matcher.engine.profiles[LENGTH_PROFILE].weight(edge)
+	if !ok || math.Abs(weight-spatial.PolylineLength(*edge.Polyline)) > 1e-9 {
+		t.Errorf("Weight of edge %d for '%s' profile should be equal to its length", edge.ID, LENGTH_PROFILE)
+	}
+
+	_, err = NewMapMatcherFromFiles(HmmProbabilitiesDefault(), "./test_data/matcher_4326_test.csv", WithWeightProfiles("truck_time"))
+	if err == nil {
+		t.Errorf("Loading should fail for missing profile column")
+	}
+}
+
+func TestWeightProfileOptionOrder(t *testing.T) {
+	edges := gridEdges(2)
+	doubled := make(map[int64]float64, len(edges))
+	for _, edge := range edges {
+		doubled[edge.ID] = 2 * edge.Weight
+	}
+	// Profile is provided before edges
+	engine := NewMapEngine(
+		WithWeightProfile("doubled", doubled),
+		WithStorage(spatial.NewStorage(spatial.StorageTypeEuclidean)),
+		WithEdges(edges),
+	)
+	if err := engine.Err(); err != nil {
+		t.Fatal(err)
+	}
+	profile, err := engine.getProfile("doubled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost, _ := profile.queryPool.ShortestPath(0, 3); cost != 40 {
+		t.Errorf("Cost from 0 to 3 should be 40, but got %f", cost)
+	}
+
+	negative := map[int64]float64{1: -1}
+	engine = NewMapEngine(WithWeightProfile("negative", negative), WithEdges(edges))
+	if engine.Err() == nil {
+		t.Error("Profile with negative weight should give error")
+	}
+	_, err = NewMapEngineBuilder(WithGraphSRID(0), WithWeightProfile("negative", negative)).AddEdges(edges...).Build()
+	if err == nil {
+		t.Error("Builder should return error of profile with negative weight")
+	}
+	_, err = NewMapEngineBuilder(WithGraphSRID(0), WithWeightProfile("unknown", map[int64]float64{100: 1})).AddEdges(edges...).Build()
+	if !errors.Is(err, ErrInconsistentGraph) {
+		t.Errorf("Builder should reject profile with unknown edge, but got %v", err)
+	}
+}